@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclconvert"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	to          = flag.String("to", "", `the syntax to convert to, either "hcl" or "json"; by default, chosen based on the input filename`)
+	schemaFile  = flag.String("schema", "", "an HCL schema file describing which JSON properties represent blocks, when converting to native syntax")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	var filename string
+	var src []byte
+	var err error
+	switch flag.NArg() {
+	case 0:
+		filename = "<stdin>"
+		src, err = ioutil.ReadAll(os.Stdin)
+	case 1:
+		filename = flag.Arg(0)
+		src, err = ioutil.ReadFile(filename)
+	default:
+		return errors.New("error: only one file can be converted at a time")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", filename, err)
+	}
+
+	target := *to
+	if target == "" {
+		if filepath.Ext(filename) == ".json" {
+			target = "hcl"
+		} else {
+			target = "json"
+		}
+	}
+
+	var out []byte
+	var diags hcl.Diagnostics
+	switch target {
+	case "json":
+		out, diags = hclconvert.FromNativeSyntax(src, filename)
+	case "hcl":
+		var blocks hclconvert.BlockLabelsFunc
+		if *schemaFile != "" {
+			var schema *hclschema.Body
+			schema, diags = loadSchemaFile(*schemaFile)
+			if diags.HasErrors() {
+				break
+			}
+			blocks = hclconvert.SchemaBlockLabels(schema)
+		}
+		out, diags = hclconvert.ToNativeSyntax(src, filename, blocks)
+	default:
+		return fmt.Errorf(`error: invalid target syntax %q; must be either "hcl" or "json"`, target)
+	}
+
+	// The converters parse the source code themselves, so we must register
+	// it with our parser in order for diagnostics to include source snippets.
+	parser.AddFile(filename, &hcl.File{Bytes: src})
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return errors.New("conversion failed")
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+func loadSchemaFile(filename string) (*hclschema.Body, hcl.Diagnostics) {
+	file, diags := parser.ParseHCLFile(filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema, moreDiags := hclschema.DecodeSchema(file.Body)
+	diags = append(diags, moreDiags...)
+	return schema, diags
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclconvert [flags] [path]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldoc"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	title       = flag.String("title", "", "the title of the generated document; defaults to the schema file name")
+	outFile     = flag.String("o", "", "write the document to the given file rather than to stdout")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	if flag.NArg() != 1 {
+		return errors.New("error: exactly one schema file is required")
+	}
+	filename := flag.Arg(0)
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if filepath.Ext(filename) == ".json" {
+		file, diags = parser.ParseJSONFile(filename)
+	} else {
+		file, diags = parser.ParseHCLFile(filename)
+	}
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return fmt.Errorf("invalid schema file %s", filename)
+	}
+
+	schema, moreDiags := hcldoc.DecodeSchema(file)
+	diags = append(diags, moreDiags...)
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return fmt.Errorf("invalid schema file %s", filename)
+	}
+
+	docTitle := *title
+	if docTitle == "" {
+		base := filepath.Base(filename)
+		docTitle = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	doc := hcldoc.Markdown(docTitle, schema)
+
+	if *outFile != "" {
+		return os.WriteFile(*outFile, doc, 0644)
+	}
+	_, err := os.Stdout.Write(doc)
+	return err
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hcldoc [flags] schema-file\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -25,6 +25,7 @@ var (
 	reqNoChange = flag.Bool("require-no-change", false, "return a non-zero status if any files are changed during formatting")
 	overwrite   = flag.Bool("w", false, "overwrite source files instead of writing to stdout")
 	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+	stdinName   = flag.String("stdin-filename", "<stdin>", "the filename to use for standard input in diagnostics")
 )
 
 var parser = hclparse.NewParser()
@@ -83,11 +84,34 @@ func processFiles() error {
 			return errors.New("error: cannot use -w without source filenames")
 		}
 
-		return processFile("<stdin>", os.Stdin)
+		return processFile(*stdinName, os.Stdin)
+	}
+
+	// Standard input can be read only once, so a second "-" would format
+	// empty input. We check before processing anything so that no output
+	// is produced for a command line that is wrong.
+	stdinArgs := 0
+	for _, path := range flag.Args() {
+		if path == "-" {
+			stdinArgs++
+		}
+	}
+	if stdinArgs > 1 {
+		fmt.Fprintln(os.Stderr, `error: the path "-" can be given only once`)
+		usage()
 	}
 
 	for i := 0; i < flag.NArg(); i++ {
 		path := flag.Arg(i)
+		if path == "-" {
+			if *overwrite {
+				return errors.New("error: cannot use -w with standard input")
+			}
+			if err := processFile(*stdinName, os.Stdin); err != nil {
+				return err
+			}
+			continue
+		}
 		switch dir, err := os.Stat(path); {
 		case err != nil:
 			return err
@@ -122,7 +146,10 @@ func processFile(fn string, in *os.File) error {
 		return fmt.Errorf("failed to read %s: %s", fn, err)
 	}
 
-	if *check {
+	// Input from stdin is always checked, because the caller is probably an
+	// editor or a git filter that will replace the original with whatever
+	// we write, and formatting invalid input could garble it.
+	if *check || in == os.Stdin {
 		_, diags := parser.ParseHCL(inSrc, fn)
 		diagWr.WriteDiagnostics(diags)
 		if diags.HasErrors() {
@@ -152,6 +179,7 @@ func processFile(fn string, in *os.File) error {
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "usage: hclfmt [flags] [path ...]\n")
+	fmt.Fprintf(os.Stderr, "\nWith no paths, or with the path \"-\", hclfmt formats standard input\nto standard output, writing nothing if the input has syntax errors.\n\n")
 	flag.PrintDefaults()
 	os.Exit(2)
 }
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldoc"
+	"github.com/hashicorp/hcl/v2/hclgen"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	example     = flag.Bool("example", false, "infer the schema from the given example configuration files, rather than reading a schema file")
+	packageName = flag.String("package", "config", "the name of the package that the generated source belongs to")
+	typeName    = flag.String("type", "Config", "the name of the struct type for the top-level body")
+	outFile     = flag.String("o", "", "write the generated source to the given file rather than to stdout")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	var schema *hclschema.Body
+	var err error
+	if *example {
+		if flag.NArg() == 0 {
+			return errors.New("error: at least one example file is required")
+		}
+		schema, err = inferSchema(flag.Args())
+	} else {
+		if flag.NArg() != 1 {
+			return errors.New("error: exactly one schema file is required")
+		}
+		schema, err = loadSchemaFile(flag.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	src, err := hclgen.Generate(
+		schema,
+		hclgen.OptPackageName(*packageName),
+		hclgen.OptTypeName(*typeName),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate source: %w", err)
+	}
+
+	if *outFile != "" {
+		return os.WriteFile(*outFile, src, 0644)
+	}
+	_, err = os.Stdout.Write(src)
+	return err
+}
+
+func loadSchemaFile(filename string) (*hclschema.Body, error) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if filepath.Ext(filename) == ".json" {
+		file, diags = parser.ParseJSONFile(filename)
+	} else {
+		file, diags = parser.ParseHCLFile(filename)
+	}
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return nil, fmt.Errorf("invalid schema file %s", filename)
+	}
+
+	// The comments leading each declaration become doc comments in the
+	// generated source, just as they become descriptions for hcldoc.
+	schema, moreDiags := hcldoc.DecodeSchema(file)
+	diags = append(diags, moreDiags...)
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid schema file %s", filename)
+	}
+	return schema, nil
+}
+
+func inferSchema(filenames []string) (*hclschema.Body, error) {
+	var files []*hcl.File
+	var diags hcl.Diagnostics
+	for _, filename := range filenames {
+		file, moreDiags := parser.ParseHCLFile(filename)
+		diags = append(diags, moreDiags...)
+		files = append(files, file)
+	}
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return nil, errors.New("invalid example files")
+	}
+
+	schema, moreDiags := hclschema.InferSchema(files...)
+	diags = append(diags, moreDiags...)
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return nil, errors.New("invalid example files")
+	}
+	return schema, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclgen [flags] schema-file\n")
+	fmt.Fprintf(os.Stderr, "       hclgen [flags] -example file ...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
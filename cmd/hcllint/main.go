@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcllint"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclreport"
+	"github.com/hashicorp/hcl/v2/hclsarif"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	disable     = flag.String("disable", "", "comma-separated names of rules to disable")
+	deprecated  = flag.String("deprecated", "", "comma-separated deprecated argument names, each optionally followed by =replacement")
+	format      = flag.String("format", "text", `the format in which to print the problems found: "text", "json", or "sarif", "junit", or "checkstyle" for continuous integration systems`)
+	jsonOutput  = flag.Bool("json", false, "print the problems found as a JSON array; the same as -format=json")
+	listRules   = flag.Bool("rules", false, "list the available rules and immediately exit")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+	trailing    = flag.String("trailing-commas", "", "require (\"add\") or forbid (\"strip\") trailing commas in collection constructors")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+var problems = []jsonProblem{}
+var problemDiags hcl.Diagnostics
+var checked []string
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+	if *listRules {
+		for _, rule := range hcllint.Rules() {
+			fmt.Printf("%-22s %s\n", rule.Name, rule.Description)
+		}
+		return nil
+	}
+
+	if *jsonOutput {
+		*format = "json"
+	}
+	switch *format {
+	case "text", "json", "sarif", "junit", "checkstyle":
+	default:
+		return fmt.Errorf(`error: invalid format %q; must be "text", "json", "sarif", "junit", or "checkstyle"`, *format)
+	}
+
+	config, err := parseConfig()
+	if err != nil {
+		return err
+	}
+	if flag.NArg() == 0 {
+		return errors.New("error: at least one file is required")
+	}
+
+	for _, path := range flag.Args() {
+		switch dir, err := os.Stat(path); {
+		case err != nil:
+			return err
+		case dir.IsDir():
+			// As with hclfmt, we can't walk a whole directory because we
+			// don't know what file naming schemes will be used by different
+			// HCL-embedding applications.
+			return fmt.Errorf("can't lint directory %s", path)
+		}
+		checked = append(checked, path)
+		if err := processFile(path, config); err != nil {
+			return err
+		}
+	}
+
+	switch *format {
+	case "json":
+		buf, err := json.MarshalIndent(problems, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+	case "sarif":
+		buf, err := hclsarif.Marshal(problemDiags, hclsarif.OptTool("hcllint", versionStr, ""))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+	case "junit":
+		return hclreport.WriteJUnit(os.Stdout, "hcllint", checked, problemDiags)
+	case "checkstyle":
+		return hclreport.WriteCheckstyle(os.Stdout, checked, problemDiags)
+	}
+	return nil
+}
+
+func parseConfig() (*hcllint.Config, error) {
+	config := &hcllint.Config{
+		Disabled:   map[string]bool{},
+		Deprecated: map[string]string{},
+	}
+
+	known := map[string]bool{}
+	for _, rule := range hcllint.Rules() {
+		known[rule.Name] = true
+	}
+	for _, name := range splitList(*disable) {
+		if !known[name] {
+			return nil, fmt.Errorf("error: unknown rule %q; use -rules to list the available rules", name)
+		}
+		config.Disabled[name] = true
+	}
+
+	for _, item := range splitList(*deprecated) {
+		name, replacement := item, ""
+		if i := strings.Index(item, "="); i >= 0 {
+			name, replacement = item[:i], item[i+1:]
+		}
+		config.Deprecated[name] = replacement
+	}
+
+	switch *trailing {
+	case "":
+	case "add":
+		config.TrailingCommas = hclwrite.TrailingCommasAdd
+	case "strip":
+		config.TrailingCommas = hclwrite.TrailingCommasStrip
+	default:
+		return nil, fmt.Errorf("error: invalid -trailing-commas value %q; must be \"add\" or \"strip\"", *trailing)
+	}
+	return config, nil
+}
+
+func splitList(s string) []string {
+	var ret []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+func processFile(fn string, config *hcllint.Config) error {
+	src, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", fn, err)
+	}
+
+	f, diags := parser.ParseHCL(src, fn)
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return fmt.Errorf("failed to parse %s", fn)
+	}
+
+	diags = hcllint.Lint(f, config)
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return fmt.Errorf("failed to lint %s", fn)
+	}
+
+	for _, diag := range diags {
+		rule := hcllint.RuleName(diag)
+		problems = append(problems, jsonProblem{
+			Rule:    rule,
+			Summary: diag.Summary,
+			Detail:  diag.Detail,
+			Range:   newJSONRange(*diag.Subject),
+		})
+		problemDiags = append(problemDiags, diag)
+		if *format == "text" {
+			// Copy the diagnostic so that the rule name appears in the
+			// summary without modifying what Lint returned.
+			display := *diag
+			display.Summary = fmt.Sprintf("%s [%s]", diag.Summary, rule)
+			diagWr.WriteDiagnostic(&display)
+		}
+	}
+	return nil
+}
+
+// jsonProblem is the JSON representation of a problem found by the linter.
+type jsonProblem struct {
+	Rule    string    `json:"rule"`
+	Summary string    `json:"summary"`
+	Detail  string    `json:"detail"`
+	Range   jsonRange `json:"range"`
+}
+
+type jsonRange struct {
+	Filename string  `json:"filename"`
+	Start    jsonPos `json:"start"`
+	End      jsonPos `json:"end"`
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+func newJSONRange(rng hcl.Range) jsonRange {
+	return jsonRange{
+		Filename: rng.Filename,
+		Start:    jsonPos{rng.Start.Line, rng.Start.Column, rng.Start.Byte},
+		End:      jsonPos{rng.End.Line, rng.End.Column, rng.End.Byte},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hcllint [flags] path [path ...]\n\n")
+	fmt.Fprintf(os.Stderr, "Checks each of the given native syntax files for problems of style and\n")
+	fmt.Fprintf(os.Stderr, "correctness. The exit status is 1 if any problems were found and 2 if\n")
+	fmt.Fprintf(os.Stderr, "there was an error.\n\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
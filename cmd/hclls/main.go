@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command hclls is a language server for generic HCL files, which offers
+// diagnostics, document symbols, hover information, semantic highlighting
+// and formatting to any editor that supports the Language Server Protocol.
+//
+// The server communicates over its standard input and output, so an editor
+// should be configured to start it as "hclls" with no arguments.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hcled/lsp"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	server := lsp.NewServer()
+	server.Version = versionStr
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclls [flags]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
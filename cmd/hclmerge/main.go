@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	overwrite   = flag.Bool("w", false, "overwrite the base file instead of writing to stdout")
+	output      = flag.String("o", "", "write the result to the given file instead of stdout")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var files = map[string]*hcl.File{}
+var diagWr hcl.DiagnosticWriter // initialized in init
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, files, uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	if flag.NArg() < 2 {
+		return errors.New("error: a base file and at least one override file are required")
+	}
+	if *overwrite && *output != "" {
+		return errors.New("error: cannot use -w and -o together")
+	}
+
+	basePath := flag.Arg(0)
+	base, err := parseFile(basePath)
+	if err != nil {
+		return err
+	}
+	for _, path := range flag.Args()[1:] {
+		override, err := parseFile(path)
+		if err != nil {
+			return err
+		}
+		base.Merge(override)
+	}
+
+	result := base.Bytes()
+	switch {
+	case *overwrite:
+		return ioutil.WriteFile(basePath, result, 0644)
+	case *output != "":
+		return ioutil.WriteFile(*output, result, 0644)
+	default:
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+}
+
+func parseFile(fn string) (*hclwrite.File, error) {
+	src, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", fn, err)
+	}
+
+	// hclwrite doesn't retain the source for diagnostic snippets, so we
+	// record it ourselves.
+	files[fn] = &hcl.File{Bytes: src}
+
+	f, diags := hclwrite.ParseConfig(src, fn, hcl.InitialPos)
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s", fn)
+	}
+	return f, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclmerge [flags] base override [override ...]\n\n")
+	fmt.Fprintf(os.Stderr, "Merges each of the override files into the base file in turn, keeping the\n")
+	fmt.Fprintf(os.Stderr, "comments and formatting of the base file.\n\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclquery"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	jsonOutput   = flag.Bool("json", false, "print each matched value as JSON")
+	rawOutput    = flag.Bool("r", false, "print matched strings without quotes")
+	withFilename = flag.Bool("H", false, "print the filename and line of each match, even for a single file")
+	noFilename   = flag.Bool("h", false, "never print the filename and line of each match")
+	showVersion  = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+var matched = false
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+	if !matched {
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		matched = true
+		return nil
+	}
+
+	if flag.NArg() == 0 {
+		return errors.New("error: a query is required")
+	}
+	query, diags := hclquery.Parse(flag.Arg(0))
+	if diags.HasErrors() {
+		return fmt.Errorf("invalid query: %s", diags.Error())
+	}
+
+	paths := flag.Args()[1:]
+	showFilename := len(paths) > 1
+	if *withFilename {
+		showFilename = true
+	}
+	if *noFilename {
+		showFilename = false
+	}
+
+	if len(paths) == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read <stdin>: %s", err)
+		}
+		return processFile(query, "<stdin>", src, showFilename)
+	}
+
+	for _, path := range paths {
+		switch dir, err := os.Stat(path); {
+		case err != nil:
+			return err
+		case dir.IsDir():
+			// As with hclfmt, we can't walk a whole directory because we
+			// don't know what file naming schemes will be used by different
+			// HCL-embedding applications.
+			return fmt.Errorf("can't query directory %s", path)
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", path, err)
+		}
+		if err := processFile(query, path, src, showFilename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func processFile(query *hclquery.Query, fn string, src []byte, showFilename bool) error {
+	f, diags := parser.ParseHCL(src, fn)
+	if diags.HasErrors() {
+		diagWr.WriteDiagnostics(diags)
+		return fmt.Errorf("failed to parse %s", fn)
+	}
+
+	matches, diags := query.FindFile(f)
+	diagWr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to query %s", fn)
+	}
+
+	for _, m := range matches {
+		matched = true
+		if showFilename {
+			fmt.Printf("%s:%d: ", fn, m.Range.Start.Line)
+		}
+		out, err := formatMatch(m, src)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", fn, m.Range.Start.Line, err)
+		}
+		fmt.Println(out)
+	}
+	return nil
+}
+
+// formatMatch returns the text to print for the given match.
+//
+// Expressions that can be evaluated without any variables or functions are
+// printed as their values. Blocks and other expressions are printed as their
+// source code, or as a JSON string containing their source code in JSON mode.
+func formatMatch(m hclquery.Match, src []byte) (string, error) {
+	var val cty.Value
+	rng := m.Range
+	if m.Expr != nil {
+		rng = m.Expr.Range()
+		v, diags := m.Expr.Value(nil)
+		if !diags.HasErrors() && v.IsWhollyKnown() {
+			val = v
+		}
+	}
+	if val == cty.NilVal {
+		val = cty.StringVal(string(rng.SliceBytes(src)))
+		if !*jsonOutput {
+			return val.AsString(), nil
+		}
+	}
+
+	if *rawOutput && val.Type() == cty.String && !val.IsNull() {
+		return val.AsString(), nil
+	}
+	if *jsonOutput {
+		buf, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+	return strings.TrimSpace(string(hclwrite.TokensForValue(val).Bytes())), nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclq [flags] query [path ...]\n\n")
+	fmt.Fprintf(os.Stderr, "Prints the values of the attributes, blocks and nested expressions that\n")
+	fmt.Fprintf(os.Stderr, "match the query in each of the given files, or in stdin if no files are\n")
+	fmt.Fprintf(os.Stderr, "given. The exit status is 1 if nothing matched and 2 if there was an error.\n\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
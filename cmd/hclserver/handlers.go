@@ -0,0 +1,263 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclconvert"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+)
+
+// maxRequestSize limits the size of request bodies we're willing to read.
+const maxRequestSize = 8 << 20
+
+// maxNestingDepth limits how deeply the blocks and expressions in the source
+// code of a request may be nested, far below the parser's default, to bound
+// the work done on adversarial input.
+const maxNestingDepth = 100
+
+// handlerTimeout limits how long we will work on a single request before
+// abandoning it.
+const handlerTimeout = 10 * time.Second
+
+// parseOptions are the options for parsing native syntax source code from
+// requests.
+var parseOptions = []hclsyntax.ParseOption{
+	hclsyntax.OptMaxNestingDepth(maxNestingDepth),
+	hclsyntax.OptMaxFileSize(maxRequestSize),
+}
+
+// request is the JSON object accepted by all of the endpoints. Not all
+// endpoints use all of the fields.
+type request struct {
+	Filename string `json:"filename"`
+	Source   string `json:"source"`
+
+	// Schema is the source code of a schema file, in the language described
+	// for hclschema.DecodeSchema.
+	Schema string `json:"schema"`
+
+	// To is the target syntax for /convert, either "hcl" or "json".
+	To string `json:"to"`
+}
+
+// response is the JSON object returned by all of the endpoints. Result is
+// null whenever the diagnostics include errors.
+type response struct {
+	Result      interface{}      `json:"result"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+type jsonDiagnostic struct {
+	Severity string     `json:"severity"`
+	Summary  string     `json:"summary"`
+	Detail   string     `json:"detail"`
+	Range    *jsonRange `json:"range,omitempty"`
+}
+
+type jsonRange struct {
+	Filename string  `json:"filename"`
+	Start    jsonPos `json:"start"`
+	End      jsonPos `json:"end"`
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// newHandler returns the handler that serves all of the endpoints.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/format", handle(formatRequest))
+	mux.HandleFunc("/validate", handle(validateRequest))
+	mux.HandleFunc("/convert", handle(convertRequest))
+	return mux
+}
+
+// handle adapts the given request handler into an http.HandlerFunc that
+// takes care of decoding the request and encoding the response.
+func handle(fn func(ctx context.Context, req *request) (interface{}, hcl.Diagnostics)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req request
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err := dec.Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+			return
+		}
+		if req.Filename == "" {
+			req.Filename = "<request>.hcl"
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), handlerTimeout)
+		defer cancel()
+		ret, diags := fn(ctx, &req)
+		if diags.HasErrors() {
+			ret = nil
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response{
+			Result:      ret,
+			Diagnostics: jsonDiagnostics(diags),
+		})
+	}
+}
+
+func formatRequest(ctx context.Context, req *request) (interface{}, hcl.Diagnostics) {
+	if _, diags := parseSource(ctx, req); diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Format parses the source code again, but we now know that doing so is
+	// within our limits.
+	out, err := hclsimple.Format(req.Filename, []byte(req.Source))
+	if err != nil {
+		// hclsimple guarantees that its errors are diagnostics.
+		return nil, err.(hcl.Diagnostics)
+	}
+	return string(out), nil
+}
+
+func validateRequest(ctx context.Context, req *request) (interface{}, hcl.Diagnostics) {
+	file, diags := parseSource(ctx, req)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if req.Schema != "" {
+		schema, moreDiags := decodeSchema(ctx, req.Schema)
+		diags = append(diags, moreDiags...)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		diags = append(diags, hclschema.Validate(file.Body, schema)...)
+	}
+	return true, diags
+}
+
+func convertRequest(ctx context.Context, req *request) (interface{}, hcl.Diagnostics) {
+	target := req.To
+	if target == "" {
+		if filepath.Ext(req.Filename) == ".json" {
+			target = "hcl"
+		} else {
+			target = "json"
+		}
+	}
+
+	var out []byte
+	var diags hcl.Diagnostics
+	switch target {
+	case "json":
+		if _, diags := parseNative(ctx, req.Filename, req.Source); diags.HasErrors() {
+			return nil, diags
+		}
+		// FromNativeSyntax parses the source code again, but we now know
+		// that doing so is within our limits.
+		out, diags = hclconvert.FromNativeSyntax([]byte(req.Source), req.Filename)
+	case "hcl":
+		var blocks hclconvert.BlockLabelsFunc
+		if req.Schema != "" {
+			var schema *hclschema.Body
+			schema, diags = decodeSchema(ctx, req.Schema)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			blocks = hclconvert.SchemaBlockLabels(schema)
+		}
+		var moreDiags hcl.Diagnostics
+		out, moreDiags = hclconvert.ToNativeSyntax([]byte(req.Source), req.Filename, blocks)
+		diags = append(diags, moreDiags...)
+	default:
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid target syntax",
+				Detail:   fmt.Sprintf(`The target syntax %q is not supported; must be either "hcl" or "json".`, target),
+			},
+		}
+	}
+	return string(out), diags
+}
+
+// parseSource parses the source code of the given request, selecting the
+// native syntax or the JSON syntax based on the filename suffix as
+// hclsimple.Parse does.
+func parseSource(ctx context.Context, req *request) (*hcl.File, hcl.Diagnostics) {
+	switch suffix := strings.ToLower(filepath.Ext(req.Filename)); suffix {
+	case ".hcl":
+		return parseNative(ctx, req.Filename, req.Source)
+	case ".json":
+		// The JSON parser has no limits of its own, but its input is still
+		// limited by maxRequestSize.
+		return hcljson.Parse([]byte(req.Source), req.Filename)
+	default:
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported file format",
+				Detail:   fmt.Sprintf("Cannot read from %s: unrecognized file format suffix %q.", req.Filename, suffix),
+			},
+		}
+	}
+}
+
+// parseNative parses the given native syntax source code, abandoning the
+// parse if it exceeds our limits or if the given context is done.
+func parseNative(ctx context.Context, filename, src string) (*hcl.File, hcl.Diagnostics) {
+	return hclsyntax.ParseConfigWithContext(ctx, []byte(src), filename, hcl.InitialPos, parseOptions...)
+}
+
+func decodeSchema(ctx context.Context, src string) (*hclschema.Body, hcl.Diagnostics) {
+	file, diags := parseNative(ctx, "<schema>", src)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema, moreDiags := hclschema.DecodeSchema(file.Body)
+	diags = append(diags, moreDiags...)
+	return schema, diags
+}
+
+func jsonDiagnostics(diags hcl.Diagnostics) []jsonDiagnostic {
+	ret := make([]jsonDiagnostic, 0, len(diags))
+	for _, diag := range diags {
+		jd := jsonDiagnostic{
+			Severity: "error",
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+		}
+		if diag.Severity == hcl.DiagWarning {
+			jd.Severity = "warning"
+		}
+		if diag.Subject != nil {
+			jd.Range = &jsonRange{
+				Filename: diag.Subject.Filename,
+				Start:    jsonPos{diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Subject.Start.Byte},
+				End:      jsonPos{diag.Subject.End.Line, diag.Subject.End.Column, diag.Subject.End.Byte},
+			}
+		}
+		ret = append(ret, jd)
+	}
+	return ret
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := map[string]struct {
+		path          string
+		body          string
+		wantResult    interface{}
+		wantSummaries []string
+	}{
+		"format": {
+			"/format",
+			`{"source": "a=1\nbee=2\n"}`,
+			"a   = 1\nbee = 2\n",
+			nil,
+		},
+		"format json": {
+			"/format",
+			`{"filename": "test.json", "source": "{\"a\":1}"}`,
+			"{\n  \"a\": 1\n}\n",
+			nil,
+		},
+		"format invalid": {
+			"/format",
+			`{"source": "a = "}`,
+			nil,
+			[]string{"Missing expression"},
+		},
+		"format unsupported suffix": {
+			"/format",
+			`{"filename": "test.txt", "source": "a = 1"}`,
+			nil,
+			[]string{"Unsupported file format"},
+		},
+		"validate": {
+			"/validate",
+			`{"source": "a = 1\n", "schema": "attribute \"a\" {\n  type = number\n}\n"}`,
+			true,
+			nil,
+		},
+		"validate against schema": {
+			"/validate",
+			`{"source": "b = 1\n", "schema": "attribute \"a\" {\n  type = number\n}\n"}`,
+			nil,
+			[]string{"Unsupported argument"},
+		},
+		"validate too deep": {
+			"/validate",
+			`{"source": "a = ` + strings.Repeat("[", maxNestingDepth+1) + strings.Repeat("]", maxNestingDepth+1) + `"}`,
+			nil,
+			[]string{"Nesting too deep"},
+		},
+		"convert to json": {
+			"/convert",
+			`{"source": "a = 1\n"}`,
+			"{\n  \"a\": 1\n}\n",
+			nil,
+		},
+		"convert to hcl": {
+			"/convert",
+			`{"filename": "test.json", "source": "{\"thing\": {\"a\": 1}}", "schema": "block \"thing\" {\n  attribute \"a\" {\n    type = number\n  }\n}\n"}`,
+			"thing {\n  a = 1\n}\n",
+			nil,
+		},
+		"convert to other": {
+			"/convert",
+			`{"source": "a = 1\n", "to": "yaml"}`,
+			nil,
+			[]string{"Invalid target syntax"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+			newHandler().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("wrong status %d; want %d\n%s", rec.Code, http.StatusOK, rec.Body)
+			}
+			if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+				t.Errorf("wrong content type %q; want %q", got, want)
+			}
+
+			var resp struct {
+				Result      interface{}      `json:"result"`
+				Diagnostics []jsonDiagnostic `json:"diagnostics"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %s\n%s", err, rec.Body)
+			}
+			if !reflect.DeepEqual(resp.Result, test.wantResult) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", resp.Result, test.wantResult)
+			}
+			var summaries []string
+			for _, diag := range resp.Diagnostics {
+				summaries = append(summaries, diag.Summary)
+			}
+			if !reflect.DeepEqual(summaries, test.wantSummaries) {
+				t.Errorf("wrong diagnostics\ngot:  %q\nwant: %q", summaries, test.wantSummaries)
+			}
+		})
+	}
+}
+
+func TestHandlerBadRequests(t *testing.T) {
+	tests := map[string]struct {
+		method     string
+		body       string
+		wantStatus int
+	}{
+		"wrong method": {
+			http.MethodGet,
+			"",
+			http.StatusMethodNotAllowed,
+		},
+		"invalid json": {
+			http.MethodPost,
+			`{"source": `,
+			http.StatusBadRequest,
+		},
+		"too large": {
+			http.MethodPost,
+			`{"source": "` + strings.Repeat("a", maxRequestSize) + `"}`,
+			http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/format", strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+			newHandler().ServeHTTP(rec, req)
+
+			if rec.Code != test.wantStatus {
+				t.Errorf("wrong status %d; want %d\n%s", rec.Code, test.wantStatus, rec.Body)
+			}
+			if rec.Code == http.StatusMethodNotAllowed {
+				if got, want := rec.Header().Get("Allow"), http.MethodPost; got != want {
+					t.Errorf("wrong Allow header %q; want %q", got, want)
+				}
+			}
+		})
+	}
+}
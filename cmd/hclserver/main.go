@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	listen      = flag.String("listen", "localhost:8080", "the address to listen on for HTTP requests")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	if flag.NArg() != 0 {
+		usage()
+	}
+
+	// The timeouts stop slow clients from holding connections open
+	// indefinitely.
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           newHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	log.Printf("hclserver listening on %s", *listen)
+	return server.ListenAndServe()
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclserver [flags]\n")
+	fmt.Fprintf(os.Stderr, "\nServes the following endpoints, each accepting a JSON object via POST:\n")
+	fmt.Fprintf(os.Stderr, "  /format    {\"filename\": ..., \"source\": ...}\n")
+	fmt.Fprintf(os.Stderr, "  /validate  {\"filename\": ..., \"source\": ..., \"schema\": ...}\n")
+	fmt.Fprintf(os.Stderr, "  /convert   {\"filename\": ..., \"source\": ..., \"to\": \"hcl\"|\"json\", \"schema\": ...}\n\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclreport"
+	"github.com/hashicorp/hcl/v2/hclsarif"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	schemaFile  = flag.String("schema", "", "an HCL file describing the expected structure of the given files")
+	examples    = flag.String("examples", "", "comma-separated example files from which to infer the expected structure of the given files, instead of -schema")
+	format      = flag.String("format", "text", `the format in which to print the problems found: "text", or "sarif", "junit", or "checkstyle" for continuous integration systems`)
+	sarifOutput = flag.Bool("sarif", false, "print the problems found as a SARIF log; the same as -format=sarif")
+	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+var diagWr hcl.DiagnosticWriter // initialized in init
+var hasErrs = false
+var allDiags hcl.Diagnostics
+var checked []string
+
+func init() {
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+}
+
+func main() {
+	err := realmain()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		return nil
+	}
+
+	if flag.NArg() == 0 {
+		return errors.New("error: at least one file to validate is required")
+	}
+	if *sarifOutput {
+		*format = "sarif"
+	}
+	switch *format {
+	case "text", "sarif", "junit", "checkstyle":
+	default:
+		return fmt.Errorf(`error: invalid format %q; must be "text", "sarif", "junit", or "checkstyle"`, *format)
+	}
+
+	if *schemaFile != "" && *examples != "" {
+		return errors.New("error: -schema and -examples can't be used together")
+	}
+
+	var schema *hclschema.Body
+	switch {
+	case *schemaFile != "":
+		var diags hcl.Diagnostics
+		schema, diags = loadSchemaFile(*schemaFile)
+		writeDiagnostics(diags)
+		if diags.HasErrors() {
+			if err := writeReport(); err != nil {
+				return err
+			}
+			return fmt.Errorf("invalid schema file %s", *schemaFile)
+		}
+	case *examples != "":
+		var diags hcl.Diagnostics
+		schema, diags = inferSchema(strings.Split(*examples, ","))
+		writeDiagnostics(diags)
+		if diags.HasErrors() {
+			if err := writeReport(); err != nil {
+				return err
+			}
+			return errors.New("invalid example files")
+		}
+	}
+
+	for i := 0; i < flag.NArg(); i++ {
+		path := flag.Arg(i)
+		switch dir, err := os.Stat(path); {
+		case err != nil:
+			return err
+		case dir.IsDir():
+			// As with hclfmt, we can't walk a whole directory because we
+			// don't know what file naming schemes will be used by different
+			// HCL-embedding applications.
+			return fmt.Errorf("can't validate directory %s", path)
+		default:
+			checked = append(checked, path)
+			validateFile(path, schema)
+		}
+	}
+
+	if err := writeReport(); err != nil {
+		return err
+	}
+	if hasErrs {
+		return errors.New("one or more files contained errors")
+	}
+
+	return nil
+}
+
+// writeDiagnostics writes the given diagnostics as text, or saves them to be
+// written by writeReport if another format was selected.
+func writeDiagnostics(diags hcl.Diagnostics) {
+	if *format != "text" {
+		allDiags = append(allDiags, diags...)
+		return
+	}
+	diagWr.WriteDiagnostics(diags)
+}
+
+// writeReport writes all of the saved diagnostics in the selected format.
+func writeReport() error {
+	switch *format {
+	case "sarif":
+		buf, err := hclsarif.Marshal(allDiags, hclsarif.OptTool("hclvalidate", versionStr, ""))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+	case "junit":
+		return hclreport.WriteJUnit(os.Stdout, "hclvalidate", checked, allDiags)
+	case "checkstyle":
+		return hclreport.WriteCheckstyle(os.Stdout, checked, allDiags)
+	}
+	return nil
+}
+
+func loadSchemaFile(filename string) (*hclschema.Body, hcl.Diagnostics) {
+	file, diags := parser.ParseHCLFile(filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema, moreDiags := hclschema.DecodeSchema(file.Body)
+	diags = append(diags, moreDiags...)
+	return schema, diags
+}
+
+func inferSchema(filenames []string) (*hclschema.Body, hcl.Diagnostics) {
+	var files []*hcl.File
+	var diags hcl.Diagnostics
+	for _, filename := range filenames {
+		file, moreDiags := parser.ParseHCLFile(filename)
+		diags = append(diags, moreDiags...)
+		files = append(files, file)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema, moreDiags := hclschema.InferSchema(files...)
+	diags = append(diags, moreDiags...)
+	return schema, diags
+}
+
+func validateFile(filename string, schema *hclschema.Body) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if filepath.Ext(filename) == ".json" {
+		file, diags = parser.ParseJSONFile(filename)
+	} else {
+		file, diags = parser.ParseHCLFile(filename)
+	}
+
+	// Schema validation of a file with syntax errors tends to produce
+	// confusing follow-on errors, so we'll report only the syntax errors
+	// until they are fixed.
+	if schema != nil && !diags.HasErrors() {
+		diags = append(diags, hclschema.Validate(file.Body, schema)...)
+	}
+
+	writeDiagnostics(diags)
+	if diags.HasErrors() {
+		hasErrs = true
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclvalidate [flags] path ...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
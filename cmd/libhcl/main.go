@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command libhcl is a shared library that exposes parsing and formatting of
+// HCL over a C ABI, so that programs written in other languages, such as
+// Python or Rust, can use this implementation of HCL through their foreign
+// function interfaces. Build it with:
+//
+//	go build -buildmode=c-shared -o libhcl.so ./cmd/libhcl
+//
+// Go can export functions over the C ABI only by using cgo, so building the
+// library requires a C toolchain. The build also writes a C header,
+// libhcl.h, declaring these functions:
+//
+//	char *HclParseToJSON(char *src, int srcLen, char *filename);
+//	char *HclFormat(char *src, int srcLen, char *filename);
+//	void HclFree(char *result);
+//
+// Each function takes the source code and its length, along with a
+// NUL-terminated filename that is used in diagnostics, and returns a
+// NUL-terminated JSON object that the caller must release with HclFree:
+//
+//	{
+//	  "result": ...,
+//	  "diagnostics": [
+//	    {
+//	      "severity": "error",
+//	      "summary": "...",
+//	      "detail": "...",
+//	      "range": {"filename": "...", "start": {...}, "end": {...}}
+//	    }
+//	  ]
+//	}
+//
+// HclParseToJSON parses native syntax and gives the equivalent JSON syntax
+// document as its result, as hclconvert.FromNativeSyntax does. HclFormat
+// formats native or JSON syntax, chosen by the filename suffix as for
+// hclsimple.Format, and gives the formatted source code as a string. If
+// there are errors, the result is null. A NULL source pointer or a negative
+// length is reported as an error diagnostic, rather than being read.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+//export HclParseToJSON
+func HclParseToJSON(src *C.char, srcLen C.int, filename *C.char) *C.char {
+	if src == nil || srcLen < 0 {
+		return C.CString(string(invalidSourceResult()))
+	}
+	return C.CString(string(parseToJSON(C.GoBytes(unsafe.Pointer(src), srcLen), C.GoString(filename))))
+}
+
+//export HclFormat
+func HclFormat(src *C.char, srcLen C.int, filename *C.char) *C.char {
+	if src == nil || srcLen < 0 {
+		return C.CString(string(invalidSourceResult()))
+	}
+	return C.CString(string(format(C.GoBytes(unsafe.Pointer(src), srcLen), C.GoString(filename))))
+}
+
+//export HclFree
+func HclFree(result *C.char) {
+	C.free(unsafe.Pointer(result))
+}
+
+// A shared library must still have a main function, which is never called.
+func main() {}
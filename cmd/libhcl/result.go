@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build cgo
+// +build cgo
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclconvert"
+	"github.com/hashicorp/hcl/v2/hclsimple"
+)
+
+// result is the JSON object returned by each of the exported functions.
+type result struct {
+	Result      interface{}      `json:"result"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+type jsonDiagnostic struct {
+	Severity string     `json:"severity"`
+	Summary  string     `json:"summary"`
+	Detail   string     `json:"detail"`
+	Range    *jsonRange `json:"range,omitempty"`
+}
+
+type jsonRange struct {
+	Filename string  `json:"filename"`
+	Start    jsonPos `json:"start"`
+	End      jsonPos `json:"end"`
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+func parseToJSON(src []byte, filename string) []byte {
+	out, diags := hclconvert.FromNativeSyntax(src, filename)
+	var ret interface{}
+	if !diags.HasErrors() {
+		ret = json.RawMessage(out)
+	}
+	return marshalResult(ret, diags)
+}
+
+func format(src []byte, filename string) []byte {
+	out, err := hclsimple.Format(filename, src)
+	if err != nil {
+		// hclsimple guarantees that its errors are diagnostics.
+		return marshalResult(nil, err.(hcl.Diagnostics))
+	}
+	return marshalResult(string(out), nil)
+}
+
+// invalidSourceResult returns the result for a call whose source buffer is
+// NULL or has a negative length, which cannot be read.
+func invalidSourceResult() []byte {
+	return marshalResult(nil, hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid source buffer",
+			Detail:   "The source buffer must not be NULL, and its length must not be negative.",
+		},
+	})
+}
+
+func marshalResult(ret interface{}, diags hcl.Diagnostics) []byte {
+	r := result{
+		Result:      ret,
+		Diagnostics: make([]jsonDiagnostic, 0, len(diags)),
+	}
+	for _, diag := range diags {
+		jd := jsonDiagnostic{
+			Severity: "error",
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+		}
+		if diag.Severity == hcl.DiagWarning {
+			jd.Severity = "warning"
+		}
+		if diag.Subject != nil {
+			jd.Range = &jsonRange{
+				Filename: diag.Subject.Filename,
+				Start:    jsonPos{diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Subject.Start.Byte},
+				End:      jsonPos{diag.Subject.End.Line, diag.Subject.End.Column, diag.Subject.End.Byte},
+			}
+		}
+		r.Diagnostics = append(r.Diagnostics, jd)
+	}
+
+	buf, err := json.Marshal(r)
+	if err != nil {
+		// Can't happen, because everything we marshal is either valid
+		// JSON already or a plain Go value.
+		panic(err)
+	}
+	return buf
+}
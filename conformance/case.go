@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Case is a single conformance test case, loaded from a directory.
+type Case struct {
+	// Name is the path of the case directory relative to the directory
+	// the cases were loaded from, using forward slashes.
+	Name string
+
+	// Filename is the path of the input file, and Input is its content.
+	// JSON is true if the input is in the JSON syntax.
+	Filename string
+	Input    []byte
+	JSON     bool
+
+	// Errors are the error diagnostics expected when parsing the input.
+	Errors []ExpectedError
+
+	// Expected is the expected decoded value of the input, as raw JSON,
+	// or nil if the case does not specify a decoded value.
+	Expected json.RawMessage
+
+	// Formatted is the expected result of formatting the input, or nil if
+	// the case does not specify a formatted result.
+	Formatted []byte
+
+	// Dialect is the name of the configuration language that the input is
+	// written in, such as "nomad", and Features are the names of the
+	// features of that language that the input exercises. Both are empty
+	// if the case does not specify them.
+	Dialect  string
+	Features []string
+}
+
+// ExpectedError describes an error diagnostic that a case expects. Line and
+// Column are the start position of the diagnostic's subject, and are not
+// checked if zero.
+type ExpectedError struct {
+	Summary string `json:"summary"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// LoadCases loads all of the test cases found in the given directory or
+// any of its descendents, in lexical order of their names. Any directory
+// that contains an input.hcl or input.json file is a test case.
+func LoadCases(dir string) ([]*Case, error) {
+	var cases []*Case
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		c, err := loadCase(dir, path)
+		if err != nil {
+			return err
+		}
+		if c != nil {
+			cases = append(cases, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].Name < cases[j].Name
+	})
+	return cases, nil
+}
+
+// loadCase loads the case in the given directory, or returns nil if the
+// directory has no input file.
+func loadCase(root, dir string) (*Case, error) {
+	name, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Case{
+		Name: filepath.ToSlash(name),
+	}
+
+	for _, candidate := range []string{"input.hcl", "input.json"} {
+		filename := filepath.Join(dir, candidate)
+		src, err := readOptional(filename)
+		if err != nil {
+			return nil, err
+		}
+		if src == nil {
+			continue
+		}
+		if c.Input != nil {
+			return nil, fmt.Errorf("%s: a case may have only one of input.hcl and input.json", dir)
+		}
+		c.Filename = filename
+		c.Input = src
+		c.JSON = candidate == "input.json"
+	}
+	if c.Input == nil {
+		return nil, nil
+	}
+
+	errorsSrc, err := readOptional(filepath.Join(dir, "errors.json"))
+	if err != nil {
+		return nil, err
+	}
+	if errorsSrc != nil {
+		if err := json.Unmarshal(errorsSrc, &c.Errors); err != nil {
+			return nil, fmt.Errorf("%s: invalid errors.json: %s", dir, err)
+		}
+	}
+
+	expected, err := readOptional(filepath.Join(dir, "expected.json"))
+	if err != nil {
+		return nil, err
+	}
+	if expected != nil {
+		if !json.Valid(expected) {
+			return nil, fmt.Errorf("%s: expected.json is not valid JSON", dir)
+		}
+		c.Expected = expected
+	}
+
+	c.Formatted, err = readOptional(filepath.Join(dir, "formatted.hcl"))
+	if err != nil {
+		return nil, err
+	}
+
+	featuresSrc, err := readOptional(filepath.Join(dir, "features.json"))
+	if err != nil {
+		return nil, err
+	}
+	if featuresSrc != nil {
+		var features struct {
+			Dialect  string   `json:"dialect"`
+			Features []string `json:"features"`
+		}
+		if err := json.Unmarshal(featuresSrc, &features); err != nil {
+			return nil, fmt.Errorf("%s: invalid features.json: %s", dir, err)
+		}
+		c.Dialect = features.Dialect
+		c.Features = features.Features
+	}
+
+	return c, nil
+}
+
+// readOptional returns the content of the given file, or nil if it does
+// not exist.
+func readOptional(filename string) ([]byte, error) {
+	src, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return src, err
+}
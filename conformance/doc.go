@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package conformance runs the parser, decoder, and formatter in this
+// repository against a directory of externally-maintained test cases and
+// reports which of them pass, so that drift between this implementation and
+// other HCL implementations can be measured.
+//
+// Each test case is a directory containing an input file and any number of
+// files describing the expected results:
+//
+//	input.hcl or input.json  the configuration to parse, in the native or
+//	                         JSON syntax respectively (required)
+//	errors.json              the error diagnostics expected when parsing
+//	expected.json            the expected decoded value of the configuration
+//	formatted.hcl            the expected output of formatting input.hcl
+//	features.json            the dialect and features the input exercises
+//
+// errors.json contains a JSON array of objects with a "summary" property and
+// optional "line" and "column" properties giving the start position of the
+// error's subject. If errors.json is absent, parsing is expected to succeed
+// without errors.
+//
+// The decoded value of a configuration is a JSON object with a property for
+// each attribute, whose value is the attribute's value, and a property for
+// each block type. Blocks are nested under their labels, in the same way as
+// for the HCL JSON syntax, with a JSON array of the bodies of all of the
+// blocks of that type and labels at the innermost level. For example:
+//
+//	{
+//	  "name": "example",
+//	  "service": {
+//	    "http": [
+//	      {"port": 80}
+//	    ]
+//	  }
+//	}
+//
+// Because the JSON syntax cannot distinguish blocks from attributes without
+// a schema, JSON objects in JSON-syntax inputs are always decoded as
+// attribute values.
+//
+// features.json contains a JSON object with a "dialect" property naming the
+// configuration language that the input is written in, such as "nomad" or
+// "vault", and a "features" property listing the names of the features of
+// that language that the input exercises, such as "heredocs".
+//
+// Checks whose expected results are not present in a case are skipped. The
+// results of all of the checks for a set of cases are collected into a
+// Report, which can be written out as a compliance matrix, or summarized as
+// a compatibility report listing which features of each dialect are
+// supported.
+//
+// The testdata/corpus directory of this package contains cases drawn from
+// the configuration of several HashiCorp products, which are run by this
+// package's tests to guard compatibility with them.
+package conformance
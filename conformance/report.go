@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report is the collected results of running checks against a set of cases.
+type Report struct {
+	// Cases are the cases that the checks were run against.
+	Cases []*Case
+
+	// Results has one element for each check run against each case, in
+	// the order the cases were given and then the order of Checks.
+	Results []Result
+}
+
+// Counts returns the number of cases that passed, failed, and skipped the
+// given check.
+func (r *Report) Counts(check Check) (pass, fail, skip int) {
+	for _, result := range r.Results {
+		if result.Check != check {
+			continue
+		}
+		switch result.Status {
+		case Pass:
+			pass++
+		case Fail:
+			fail++
+		default:
+			skip++
+		}
+	}
+	return pass, fail, skip
+}
+
+// Failures returns just the results that failed.
+func (r *Report) Failures() []Result {
+	var ret []Result
+	for _, result := range r.Results {
+		if result.Status == Fail {
+			ret = append(ret, result)
+		}
+	}
+	return ret
+}
+
+// OK returns true if none of the checks failed.
+func (r *Report) OK() bool {
+	return len(r.Failures()) == 0
+}
+
+// WriteMatrix writes a table to the given writer with a row for each case
+// and a column for each check, showing the status of each, followed by a
+// row giving the proportion of the applicable cases that passed each check.
+func (r *Report) WriteMatrix(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := []string{"CASE"}
+	for _, check := range Checks {
+		header = append(header, string(check))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	row := make([]string, 0, len(Checks)+1)
+	for i, result := range r.Results {
+		if len(row) == 0 {
+			row = append(row, result.Case)
+		}
+		row = append(row, result.Status.String())
+		if i == len(r.Results)-1 || r.Results[i+1].Case != result.Case {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+			row = row[:0]
+		}
+	}
+
+	totals := []string{"TOTAL"}
+	for _, check := range Checks {
+		pass, fail, _ := r.Counts(check)
+		totals = append(totals, fmt.Sprintf("%d/%d", pass, pass+fail))
+	}
+	fmt.Fprintln(tw, strings.Join(totals, "\t"))
+
+	return tw.Flush()
+}
+
+// WriteFailures writes a description of each failed check to the given
+// writer.
+func (r *Report) WriteFailures(w io.Writer) error {
+	for _, result := range r.Failures() {
+		_, err := fmt.Fprintf(w, "--- %s (%s)\n%s\n\n", result.Case, result.Check, result.Message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FeatureSupport describes whether a feature of a configuration language
+// dialect is supported, as judged by the cases that exercise it.
+type FeatureSupport struct {
+	Dialect string
+	Feature string
+
+	// Cases are the names of the cases that exercise the feature, and
+	// Failing are those of them that failed at least one check. The feature
+	// is supported if none of them failed.
+	Cases   []string
+	Failing []string
+}
+
+// Supported returns true if none of the cases exercising the feature failed.
+func (s FeatureSupport) Supported() bool {
+	return len(s.Failing) == 0
+}
+
+// Compatibility returns the support for each of the features declared by the
+// report's cases, ordered by dialect and then by feature. Cases that do not
+// declare a dialect are not included.
+func (r *Report) Compatibility() []FeatureSupport {
+	failed := map[string]bool{}
+	for _, result := range r.Failures() {
+		failed[result.Case] = true
+	}
+
+	type key struct{ dialect, feature string }
+	index := map[key]int{}
+	var ret []FeatureSupport
+	for _, c := range r.Cases {
+		if c.Dialect == "" {
+			continue
+		}
+		for _, feature := range c.Features {
+			k := key{c.Dialect, feature}
+			i, exists := index[k]
+			if !exists {
+				i = len(ret)
+				index[k] = i
+				ret = append(ret, FeatureSupport{Dialect: c.Dialect, Feature: feature})
+			}
+			ret[i].Cases = append(ret[i].Cases, c.Name)
+			if failed[c.Name] {
+				ret[i].Failing = append(ret[i].Failing, c.Name)
+			}
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Dialect != ret[j].Dialect {
+			return ret[i].Dialect < ret[j].Dialect
+		}
+		return ret[i].Feature < ret[j].Feature
+	})
+	return ret
+}
+
+// WriteCompatibility writes a table to the given writer with a row for each
+// feature returned by Compatibility, showing whether it is supported and,
+// if not, which cases failed.
+func (r *Report) WriteCompatibility(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIALECT\tFEATURE\tSUPPORTED\tCASES")
+	for _, s := range r.Compatibility() {
+		supported := "yes"
+		if !s.Supported() {
+			supported = fmt.Sprintf("no (%s failed)", strings.Join(s.Failing, ", "))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", s.Dialect, s.Feature, supported, len(s.Cases))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclvalue"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Check identifies one of the checks that can be run against a case.
+type Check string
+
+const (
+	// CheckParse compares the errors produced by parsing the input with
+	// the case's expected errors.
+	CheckParse Check = "parse"
+
+	// CheckDecode compares the decoded value of the input with the case's
+	// expected value.
+	CheckDecode Check = "decode"
+
+	// CheckFormat compares the result of formatting the input with the
+	// case's expected formatted result.
+	CheckFormat Check = "format"
+)
+
+// Checks are all of the available checks, in the order they are run.
+var Checks = []Check{CheckParse, CheckDecode, CheckFormat}
+
+// Status is the outcome of running a check against a case.
+type Status int
+
+const (
+	// Skip indicates that the case does not specify an expected result for
+	// the check, or that the check does not apply to the case's input.
+	Skip Status = iota
+	Pass
+	Fail
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "pass"
+	case Fail:
+		return "FAIL"
+	default:
+		return "-"
+	}
+}
+
+// Result is the outcome of running a particular check against a case.
+type Result struct {
+	Case   string
+	Check  Check
+	Status Status
+
+	// Message describes the reason for a failure. It is empty for other
+	// statuses.
+	Message string
+}
+
+// Run runs all of the checks against each of the given cases and returns a
+// report of the results.
+func Run(cases []*Case) *Report {
+	report := &Report{
+		Cases: cases,
+	}
+	for _, c := range cases {
+		file, diags := parse(c)
+		for _, check := range Checks {
+			result := Result{
+				Case:  c.Name,
+				Check: check,
+			}
+			var msg string
+			switch check {
+			case CheckParse:
+				result.Status, msg = checkParse(c, diags)
+			case CheckDecode:
+				result.Status, msg = checkDecode(c, file, diags)
+			case CheckFormat:
+				result.Status, msg = checkFormat(c)
+			}
+			if result.Status == Fail {
+				result.Message = msg
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+	return report
+}
+
+func parse(c *Case) (*hcl.File, hcl.Diagnostics) {
+	if c.JSON {
+		return hcljson.Parse(c.Input, c.Filename)
+	}
+	return hclsyntax.ParseConfig(c.Input, c.Filename, hcl.InitialPos)
+}
+
+func checkParse(c *Case, diags hcl.Diagnostics) (Status, string) {
+	var unmatched []*hcl.Diagnostic
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagError {
+			unmatched = append(unmatched, diag)
+		}
+	}
+
+	var msg strings.Builder
+	for _, expected := range c.Errors {
+		found := false
+		for i, diag := range unmatched {
+			if expected.matches(diag) {
+				unmatched = append(unmatched[:i], unmatched[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(&msg, "missing error: %s\n", expected.describe())
+		}
+	}
+	for _, diag := range unmatched {
+		fmt.Fprintf(&msg, "unexpected error: %s\n", describeError(diag))
+	}
+
+	if msg.Len() != 0 {
+		return Fail, strings.TrimSpace(msg.String())
+	}
+	return Pass, ""
+}
+
+func (e ExpectedError) matches(diag *hcl.Diagnostic) bool {
+	if diag.Summary != e.Summary {
+		return false
+	}
+	if e.Line == 0 {
+		return true
+	}
+	if diag.Subject == nil || diag.Subject.Start.Line != e.Line {
+		return false
+	}
+	return e.Column == 0 || diag.Subject.Start.Column == e.Column
+}
+
+func (e ExpectedError) describe() string {
+	switch {
+	case e.Line == 0:
+		return e.Summary
+	case e.Column == 0:
+		return fmt.Sprintf("%d: %s", e.Line, e.Summary)
+	default:
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Summary)
+	}
+}
+
+func describeError(diag *hcl.Diagnostic) string {
+	if diag.Subject == nil {
+		return diag.Summary
+	}
+	return fmt.Sprintf("%d:%d: %s", diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Summary)
+}
+
+func checkDecode(c *Case, file *hcl.File, parseDiags hcl.Diagnostics) (Status, string) {
+	if c.Expected == nil {
+		return Skip, ""
+	}
+	if parseDiags.HasErrors() {
+		return Fail, "input has syntax errors"
+	}
+
+	doc, diags := hclvalue.Collect(file, nil)
+	if diags.HasErrors() {
+		return Fail, fmt.Sprintf("decoding failed: %s", diags.Error())
+	}
+	got, err := decodedJSON(doc.Values, doc.Blocks)
+	if err != nil {
+		return Fail, err.Error()
+	}
+
+	var want interface{}
+	if err := json.Unmarshal(c.Expected, &want); err != nil {
+		return Fail, fmt.Sprintf("invalid expected value: %s", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		return Fail, fmt.Sprintf("wrong decoded value\ngot:  %s\nwant: %s", gotJSON, bytes.TrimSpace(c.Expected))
+	}
+	return Pass, ""
+}
+
+// decodedJSON builds the generic JSON representation of a body's content,
+// as described in the package documentation.
+func decodedJSON(values []*hclvalue.DecodedValue, blocks []*hclvalue.DecodedBlock) (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	for _, v := range values {
+		if !v.Value.IsWhollyKnown() {
+			return nil, fmt.Errorf("value for %q is not known", v.Name)
+		}
+		raw, err := ctyjson.Marshal(v.Value, v.Type())
+		if err != nil {
+			return nil, fmt.Errorf("value for %q cannot be represented as JSON: %s", v.Name, err)
+		}
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		ret[v.Name] = generic
+	}
+
+	for _, b := range blocks {
+		body, err := decodedJSON(b.Values, b.Blocks)
+		if err != nil {
+			return nil, err
+		}
+
+		parent := ret
+		key := b.Type
+		for _, label := range b.Labels {
+			next, ok := parent[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				parent[key] = next
+			}
+			parent = next
+			key = label
+		}
+		list, _ := parent[key].([]interface{})
+		parent[key] = append(list, body)
+	}
+
+	return ret, nil
+}
+
+func checkFormat(c *Case) (Status, string) {
+	if c.Formatted == nil || c.JSON {
+		return Skip, ""
+	}
+
+	got := hclwrite.Format(c.Input)
+	if !bytes.Equal(got, c.Formatted) {
+		return Fail, fmt.Sprintf("wrong formatted result\ngot:\n%s\nwant:\n%s", got, c.Formatted)
+	}
+	return Pass, ""
+}
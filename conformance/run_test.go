@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package conformance
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	cases, err := LoadCases("testdata/cases")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(cases)
+
+	var buf bytes.Buffer
+	if err := report.WriteMatrix(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `CASE          parse  decode  format
+attributes    pass   pass    -
+blocks        pass   pass    -
+failing       pass   FAIL    -
+format        pass   -       pass
+json          pass   pass    -
+syntax-error  pass   -       -
+TOTAL         6/6    3/4     1/1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("wrong matrix\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	failures := report.Failures()
+	if len(failures) != 1 {
+		t.Fatalf("wrong number of failures %d; want 1", len(failures))
+	}
+	if got, want := failures[0].Case, "failing"; got != want {
+		t.Errorf("wrong failing case %q; want %q", got, want)
+	}
+	if !strings.Contains(failures[0].Message, `want: {"a": 2}`) {
+		t.Errorf("failure message does not describe the expected value:\n%s", failures[0].Message)
+	}
+	if report.OK() {
+		t.Errorf("report is OK despite failure")
+	}
+}
+
+func TestCheckParse(t *testing.T) {
+	c := &Case{
+		Name:     "test",
+		Filename: "input.hcl",
+		Input:    []byte("a = \nb = 1\nb = 2\n"),
+		Errors: []ExpectedError{
+			{Summary: "Invalid expression"},
+			{Summary: "Attribute redefined", Line: 4},
+		},
+	}
+	_, diags := parse(c)
+
+	status, msg := checkParse(c, diags)
+	if status != Fail {
+		t.Fatalf("wrong status %s; want FAIL", status)
+	}
+	want := "missing error: 4: Attribute redefined\nunexpected error: 3:1: Attribute redefined"
+	if msg != want {
+		t.Errorf("wrong message\ngot:  %s\nwant: %s", msg, want)
+	}
+
+	c.Errors[1].Line = 3
+	if status, msg := checkParse(c, diags); status != Pass {
+		t.Errorf("wrong status %s; want pass\n%s", status, msg)
+	}
+}
+
+func TestCompatibility(t *testing.T) {
+	cases, err := LoadCases("testdata/cases")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Run(cases).WriteCompatibility(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `DIALECT  FEATURE    SUPPORTED            CASES
+example  arguments  yes                  1
+example  numbers    no (failing failed)  2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("wrong compatibility report\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	cases, err := LoadCases("testdata/corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(cases)
+	if !report.OK() {
+		var buf bytes.Buffer
+		report.WriteFailures(&buf)
+		t.Errorf("corpus cases failed:\n%s", buf.String())
+	}
+	for _, c := range cases {
+		if c.Dialect == "" || len(c.Features) == 0 {
+			t.Errorf("corpus case %s does not declare its dialect and features", c.Name)
+		}
+	}
+	for _, s := range report.Compatibility() {
+		if !s.Supported() {
+			t.Errorf("%s feature %q is not supported", s.Dialect, s.Feature)
+		}
+	}
+}
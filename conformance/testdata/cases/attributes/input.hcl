@@ -0,0 +1,3 @@
+name = "example"
+count = 1 + 2
+tags = ["a", "b"]
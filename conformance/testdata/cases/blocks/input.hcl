@@ -0,0 +1,11 @@
+service "http" {
+  port = 80
+}
+
+service "http" {
+  port = 8080
+}
+
+logging {
+  level = "info"
+}
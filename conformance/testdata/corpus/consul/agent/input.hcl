@@ -0,0 +1,36 @@
+datacenter = "east-aws"
+data_dir   = "/opt/consul"
+log_level  = "INFO"
+node_name  = "consul-server-1"
+server     = true
+
+bootstrap_expect = 3
+retry_join       = ["provider=aws tag_key=consul tag_value=server"]
+
+addresses {
+  http = "0.0.0.0"
+}
+
+ports {
+  grpc  = 8502
+  https = 8501
+}
+
+acl {
+  enabled                  = true
+  default_policy           = "deny"
+  enable_token_persistence = true
+}
+
+telemetry {
+  prometheus_retention_time = "30s"
+  disable_hostname          = true
+}
+
+connect {
+  enabled = true
+}
+
+ui_config {
+  enabled = true
+}
@@ -0,0 +1,31 @@
+service {
+  name = "api"
+  id   = "api-1"
+  port = 9090
+  tags = ["v1", "primary"]
+  meta = {
+    version = "1.4.2"
+    "team"  = "payments"
+  }
+
+  connect {
+    sidecar_service {
+      proxy {
+        upstreams = [
+          {
+            destination_name = "db"
+            local_bind_port  = 5432
+          },
+        ]
+      }
+    }
+  }
+
+  checks = [
+    {
+      id       = "api-http"
+      http     = "http://localhost:9090/health"
+      interval = "10s"
+    },
+  ]
+}
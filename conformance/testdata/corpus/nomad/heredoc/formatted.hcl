@@ -0,0 +1,30 @@
+task "app" {
+  driver = "exec"
+
+  template {
+    destination = "local/app.env"
+    perms       = "0640"
+    data        = <<-EOT
+      APP_PORT=8080
+        INDENTED=true
+      LITERAL=$${HOME}
+      TEMPLATE={{ env "NOMAD_ALLOC_ID" }}
+    EOT
+  }
+
+  template {
+    destination = "local/policy.json"
+    data        = <<EOT
+{
+  "allow": ["read"]
+}
+EOT
+  }
+
+  artifact {
+    source = "https://example.com/app.tar.gz"
+    options {
+      checksum = "sha256:abd123"
+    }
+  }
+}
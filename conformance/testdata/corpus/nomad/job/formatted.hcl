@@ -0,0 +1,70 @@
+job "web" {
+  datacenters = ["dc1", "dc2"]
+  type        = "service"
+
+  constraint {
+    attribute = "${attr.kernel.name}"
+    value     = "linux"
+  }
+
+  update {
+    max_parallel     = 2
+    min_healthy_time = "30s"
+    auto_revert      = true
+  }
+
+  group "frontend" {
+    count = 3
+
+    network {
+      port "http" {
+        to = 8080
+      }
+    }
+
+    service {
+      name = "web"
+      port = "http"
+      tags = ["urlprefix-/"]
+
+      check {
+        type     = "http"
+        path     = "/health"
+        interval = "10s"
+        timeout  = "2s"
+      }
+    }
+
+    task "server" {
+      driver = "docker"
+
+      config {
+        image = "nginx:1.25"
+        ports = ["http"]
+        args  = ["-c", "/local/nginx.conf"]
+      }
+
+      template {
+        data        = <<-EOT
+          upstream backend {
+          {{ range service "api" }}
+            server {{ .Address }}:{{ .Port }};
+          {{ end }}
+          }
+        EOT
+        destination = "local/nginx.conf"
+        change_mode = "signal"
+      }
+
+      env {
+        NODE_NAME = "${node.unique.name}"
+        LITERAL   = "$${NOT_INTERPOLATED}"
+      }
+
+      resources {
+        cpu    = 500
+        memory = 256
+      }
+    }
+  }
+}
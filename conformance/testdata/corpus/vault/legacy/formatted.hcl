@@ -0,0 +1,15 @@
+path "secret/*" {
+  policy = "write"
+}
+
+path "sys/mounts" {
+  capabilities = [
+    "read",
+    "list"
+  ]
+}
+
+listener tcp {
+  address     = "127.0.0.1:8200"
+  tls_disable = 1
+}
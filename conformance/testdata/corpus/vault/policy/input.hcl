@@ -0,0 +1,24 @@
+# Allow reading application secrets.
+path "secret/data/app/*" {
+  capabilities = ["read", "list"]
+}
+
+path "secret/data/app/admin" {
+  capabilities = ["deny"]
+}
+
+// Allow managing tokens for this policy's holders.
+path "auth/token/renew-self" {
+  capabilities = ["update"]
+}
+
+/*
+  Allow the holder to see its own ACL capabilities.
+*/
+path "sys/capabilities-self" {
+  capabilities = ["update"]
+
+  allowed_parameters = {
+    "paths" = []
+  }
+}
@@ -187,3 +187,32 @@ type DiagnosticExtraUnwrapper interface {
 	// value returns a value that was also wrapping it.
 	UnwrapDiagnosticExtra() interface{}
 }
+
+// DuplicateDiagExtra is an interface implemented by values in the Extra field
+// of diagnostics that report an argument or attribute being defined more than
+// once in the same body or object. The Subject of such a diagnostic is the
+// range of the later definition, and this interface gives the range of the
+// earlier one so that callers can present both.
+//
+// Use NewDuplicateDiagExtra to construct a value implementing this interface.
+type DuplicateDiagExtra interface {
+	// PreviousDefinitionRange returns the range of the name of the earlier
+	// definition.
+	PreviousDefinitionRange() Range
+}
+
+// NewDuplicateDiagExtra returns a value implementing DuplicateDiagExtra for
+// an earlier definition with the given name range.
+func NewDuplicateDiagExtra(previous Range) DuplicateDiagExtra {
+	return duplicateDiagExtra{Previous: previous}
+}
+
+type duplicateDiagExtra struct {
+	// Previous is exported only so that go-cmp can compare diagnostics in
+	// tests; the type itself is unexported.
+	Previous Range
+}
+
+func (e duplicateDiagExtra) PreviousDefinitionRange() Range {
+	return e.Previous
+}
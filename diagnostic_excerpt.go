@@ -0,0 +1,298 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExcerptOption is implemented by values that can customize the behavior of
+// the writer returned by NewDiagnosticExcerptWriter. Use the functions in
+// this package whose names begin with "OptExcerpt" to produce excerpt
+// options.
+type ExcerptOption interface {
+	applyExcerptOption(*excerptOpts)
+}
+
+type excerptOpts struct {
+	contextLines int
+	color        *bool
+}
+
+type optExcerptContextLines struct {
+	lines int
+}
+
+// OptExcerptContextLines sets the number of lines of source code shown
+// before and after the lines that a diagnostic refers to. The default is
+// one line.
+func OptExcerptContextLines(lines int) ExcerptOption {
+	return optExcerptContextLines{lines}
+}
+
+// applyExcerptOption implements ExcerptOption.
+func (o optExcerptContextLines) applyExcerptOption(opts *excerptOpts) {
+	opts.contextLines = o.lines
+}
+
+type optExcerptColor struct {
+	color bool
+}
+
+// OptExcerptColor forces ANSI color escape sequences to be included in, or
+// omitted from, the output, overriding the default of including them only
+// when writing to a terminal.
+func OptExcerptColor(color bool) ExcerptOption {
+	return optExcerptColor{color}
+}
+
+// applyExcerptOption implements ExcerptOption.
+func (o optExcerptColor) applyExcerptOption(opts *excerptOpts) {
+	opts.color = &o.color
+}
+
+type diagnosticExcerptWriter struct {
+	files        map[string]*File
+	wr           io.Writer
+	contextLines int
+	color        bool
+}
+
+// NewDiagnosticExcerptWriter creates a DiagnosticWriter that writes
+// diagnostics to the given writer as formatted text, showing an excerpt of
+// the source code around each diagnostic's subject with the subject
+// underlined by carets, like this:
+//
+//	Error: Unsupported argument
+//	  --> main.hcl:3:3
+//	   |
+//	 2 | service "web" {
+//	 3 |   prot = 8080
+//	   |   ^^^^
+//	 4 | }
+//	   |
+//	   = An argument named "prot" is not expected here. Did you mean "port"?
+//
+// By default, the output is colored using ANSI escape sequences only if the
+// given writer is an *os.File connected to a terminal and the NO_COLOR
+// environment variable is not set. Use OptExcerptColor to override this.
+//
+// The writer looks up the source code for each diagnostic in the given
+// files, which are keyed by filename as returned by hclparse.Parser.Files.
+func NewDiagnosticExcerptWriter(wr io.Writer, files map[string]*File, opts ...ExcerptOption) DiagnosticWriter {
+	o := excerptOpts{contextLines: 1}
+	for _, opt := range opts {
+		opt.applyExcerptOption(&o)
+	}
+
+	var color bool
+	if o.color != nil {
+		color = *o.color
+	} else {
+		color = isColorTerminal(wr)
+	}
+	if o.contextLines < 0 {
+		o.contextLines = 0
+	}
+
+	return &diagnosticExcerptWriter{
+		files:        files,
+		wr:           wr,
+		contextLines: o.contextLines,
+		color:        color,
+	}
+}
+
+// excerptLine is a line of source code included in an excerpt.
+type excerptLine struct {
+	num        int
+	start, end int // byte offsets, excluding the newline
+}
+
+func (w *diagnosticExcerptWriter) WriteDiagnostic(diag *Diagnostic) error {
+	if diag == nil {
+		return errors.New("nil diagnostic")
+	}
+
+	var colorCode, gutterCode, boldCode, resetCode string
+	if w.color {
+		switch diag.Severity {
+		case DiagError:
+			colorCode = "\x1b[1;31m"
+		case DiagWarning:
+			colorCode = "\x1b[1;33m"
+		}
+		gutterCode = "\x1b[1;34m"
+		boldCode = "\x1b[1m"
+		resetCode = "\x1b[0m"
+	}
+
+	var severityStr string
+	switch diag.Severity {
+	case DiagError:
+		severityStr = "Error"
+	case DiagWarning:
+		severityStr = "Warning"
+	default:
+		// should never happen
+		severityStr = "???????"
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s%s%s: %s%s%s\n", colorCode, severityStr, resetCode, boldCode, diag.Summary, resetCode)
+
+	var lines []excerptLine
+	var src []byte
+	gutterWidth := 1
+	if diag.Subject != nil {
+		subject := *diag.Subject
+		fmt.Fprintf(&buf, "  %s-->%s %s:%d:%d\n", gutterCode, resetCode, subject.Filename, subject.Start.Line, subject.Start.Column)
+
+		file := w.files[subject.Filename]
+		if file == nil || file.Bytes == nil {
+			fmt.Fprintf(&buf, "  (source code not available)\n")
+		} else {
+			src = file.Bytes
+			snipRange := subject
+			if diag.Context != nil {
+				snipRange = RangeOver(snipRange, *diag.Context)
+			}
+			firstLine := snipRange.Start.Line - w.contextLines
+			lastLine := snipRange.End.Line + w.contextLines
+			if snipRange.End.Line > snipRange.Start.Line && snipRange.End.Column == 1 {
+				// A range ending at the start of a line doesn't really
+				// include anything on that line.
+				lastLine--
+			}
+
+			sc := NewRangeScanner(src, subject.Filename, bufio.ScanLines)
+			for sc.Scan() {
+				rng := sc.Range()
+				if rng.Start.Line < firstLine {
+					continue
+				}
+				if rng.Start.Line > lastLine {
+					break
+				}
+				lines = append(lines, excerptLine{
+					num:   rng.Start.Line,
+					start: rng.Start.Byte,
+					end:   rng.End.Byte,
+				})
+			}
+			if len(lines) > 0 {
+				gutterWidth = len(strconv.Itoa(lines[len(lines)-1].num))
+			}
+		}
+	}
+
+	gutter := strings.Repeat(" ", gutterWidth)
+	if len(lines) > 0 {
+		subject := *diag.Subject
+		fmt.Fprintf(&buf, "%s %s|%s\n", gutter, gutterCode, resetCode)
+		for _, line := range lines {
+			text := src[line.start:line.end]
+			fmt.Fprintf(&buf, "%s%*d |%s", gutterCode, gutterWidth, line.num, resetCode)
+			if len(text) > 0 {
+				fmt.Fprintf(&buf, " %s", text)
+			}
+			buf.WriteByte('\n')
+
+			if pad, carets := excerptUnderline(src, line, subject); carets > 0 {
+				fmt.Fprintf(
+					&buf, "%s %s|%s %s%s%s%s\n",
+					gutter, gutterCode, resetCode,
+					pad,
+					colorCode, strings.Repeat("^", carets), resetCode,
+				)
+			}
+		}
+		fmt.Fprintf(&buf, "%s %s|%s\n", gutter, gutterCode, resetCode)
+	}
+
+	if diag.Detail != "" {
+		if diag.Subject != nil {
+			fmt.Fprintf(&buf, "%s %s=%s ", gutter, gutterCode, resetCode)
+		}
+		buf.WriteString(diag.Detail)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	_, err := io.WriteString(w.wr, buf.String())
+	return err
+}
+
+func (w *diagnosticExcerptWriter) WriteDiagnostics(diags Diagnostics) error {
+	for _, diag := range diags {
+		err := w.WriteDiagnostic(diag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excerptUnderline returns the padding and the number of carets needed to
+// underline the part of the given line that is within the given subject
+// range. The number of carets is zero if the line should not be underlined.
+//
+// An empty subject, or one that begins at the end of the line, is marked
+// with a single caret.
+func excerptUnderline(src []byte, line excerptLine, subject Range) (string, int) {
+	start, end := subject.Start.Byte, subject.End.Byte
+	if start < line.start {
+		start = line.start
+	}
+	if end > line.end {
+		end = line.end
+	}
+
+	var carets int
+	switch {
+	case start < end:
+		carets = utf8.RuneCount(src[start:end])
+	case subject.Start.Byte >= line.start && subject.Start.Byte <= line.end:
+		start = subject.Start.Byte
+		carets = 1
+	default:
+		return "", 0
+	}
+
+	// We preserve tabs in the padding so that the carets line up with the
+	// source code however the terminal renders tabs.
+	var pad strings.Builder
+	for _, r := range string(src[line.start:start]) {
+		if r == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	return pad.String(), carets
+}
+
+// isColorTerminal returns true if the given writer seems to be a terminal
+// that can display ANSI color escape sequences.
+func isColorTerminal(wr io.Writer) bool {
+	f, ok := wr.(*os.File)
+	if !ok {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
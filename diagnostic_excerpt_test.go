@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiagnosticExcerptWriter(t *testing.T) {
+	src := []byte("service \"web\" {\n  prot = 8080\n\tname = \"é\"\n}\n")
+	files := map[string]*File{
+		"main.hcl": {Bytes: src},
+	}
+
+	tests := map[string]struct {
+		diag *Diagnostic
+		opts []ExcerptOption
+		want string
+	}{
+		"single line": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Unsupported argument",
+				Detail:   "An argument named \"prot\" is not expected here.",
+				Subject: &Range{
+					Filename: "main.hcl",
+					Start:    Pos{Line: 2, Column: 3, Byte: 18},
+					End:      Pos{Line: 2, Column: 7, Byte: 22},
+				},
+			},
+			nil,
+			`Error: Unsupported argument
+  --> main.hcl:2:3
+  |
+1 | service "web" {
+2 |   prot = 8080
+  |   ^^^^
+3 | 	name = "é"
+  |
+  = An argument named "prot" is not expected here.
+
+`,
+		},
+		"no context lines": {
+			&Diagnostic{
+				Severity: DiagWarning,
+				Summary:  "Odd value",
+				Subject: &Range{
+					Filename: "main.hcl",
+					Start:    Pos{Line: 3, Column: 9, Byte: 38},
+					End:      Pos{Line: 3, Column: 12, Byte: 42},
+				},
+			},
+			[]ExcerptOption{OptExcerptContextLines(0)},
+			"Warning: Odd value\n  --> main.hcl:3:9\n  |\n3 | \tname = \"é\"\n  | \t       ^^^\n  |\n\n",
+		},
+		"multiple lines": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Bad block",
+				Subject: &Range{
+					Filename: "main.hcl",
+					Start:    Pos{Line: 1, Column: 15, Byte: 14},
+					End:      Pos{Line: 2, Column: 7, Byte: 22},
+				},
+			},
+			[]ExcerptOption{OptExcerptContextLines(0)},
+			`Error: Bad block
+  --> main.hcl:1:15
+  |
+1 | service "web" {
+  |               ^
+2 |   prot = 8080
+  | ^^^^^^
+  |
+
+`,
+		},
+		"empty range at end of line": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Missing newline",
+				Subject: &Range{
+					Filename: "main.hcl",
+					Start:    Pos{Line: 2, Column: 14, Byte: 29},
+					End:      Pos{Line: 2, Column: 14, Byte: 29},
+				},
+			},
+			[]ExcerptOption{OptExcerptContextLines(0)},
+			`Error: Missing newline
+  --> main.hcl:2:14
+  |
+2 |   prot = 8080
+  |              ^
+  |
+
+`,
+		},
+		"no source": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Splines not reticulated",
+				Detail:   "All splines must be pre-reticulated.",
+				Subject: &Range{
+					Filename: "other.hcl",
+					Start:    Pos{Line: 1, Column: 1, Byte: 0},
+					End:      Pos{Line: 1, Column: 2, Byte: 1},
+				},
+			},
+			nil,
+			`Error: Splines not reticulated
+  --> other.hcl:1:1
+  (source code not available)
+  = All splines must be pre-reticulated.
+
+`,
+		},
+		"no subject": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Splines not reticulated",
+				Detail:   "All splines must be pre-reticulated.",
+			},
+			nil,
+			"Error: Splines not reticulated\nAll splines must be pre-reticulated.\n\n",
+		},
+		"color": {
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Unsupported argument",
+				Subject: &Range{
+					Filename: "main.hcl",
+					Start:    Pos{Line: 2, Column: 3, Byte: 18},
+					End:      Pos{Line: 2, Column: 7, Byte: 22},
+				},
+			},
+			[]ExcerptOption{OptExcerptContextLines(0), OptExcerptColor(true)},
+			"\x1b[1;31mError\x1b[0m: \x1b[1mUnsupported argument\x1b[0m\n" +
+				"  \x1b[1;34m-->\x1b[0m main.hcl:2:3\n" +
+				"  \x1b[1;34m|\x1b[0m\n" +
+				"\x1b[1;34m2 |\x1b[0m   prot = 8080\n" +
+				"  \x1b[1;34m|\x1b[0m   \x1b[1;31m^^^^\x1b[0m\n" +
+				"  \x1b[1;34m|\x1b[0m\n\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			wr := NewDiagnosticExcerptWriter(&buf, files, test.opts...)
+			if err := wr.WriteDiagnostic(test.diag); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("wrong output\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticExcerptWriterNoColor(t *testing.T) {
+	// A writer that isn't a terminal never gets color by default.
+	var buf bytes.Buffer
+	wr := NewDiagnosticExcerptWriter(&buf, nil)
+	wr.WriteDiagnostic(&Diagnostic{Severity: DiagError, Summary: "Oops"})
+	if got, want := buf.String(), "Error: Oops\n\n"; got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// The error types in this file describe common kinds of problem in a way
+// that programs can inspect, using errors.As on a *Diagnostic or on
+// Diagnostics, rather than by matching the text of diagnostic messages.
+//
+// A diagnostic of one of these kinds carries the corresponding error value
+// in its Extra field, which Diagnostic.Unwrap returns.
+
+// SyntaxError is the kind of the error diagnostics produced when source code
+// cannot be parsed.
+type SyntaxError struct {
+	// Summary is the summary of the diagnostic, describing the problem.
+	Summary string
+
+	// Range is the range of the source code where the problem was found.
+	Range Range
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Range, e.Summary)
+}
+
+// TypeMismatchError is the kind of the error diagnostics produced when a
+// value cannot be converted to the type that is required.
+type TypeMismatchError struct {
+	// Want is the type that was required, and Got is the type of the value
+	// that was given.
+	Want, Got cty.Type
+
+	// Range is the range of the expression that produced the value.
+	Range Range
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s required, but got %s", e.Range, e.Want.FriendlyName(), e.Got.FriendlyName())
+}
+
+// UnknownAttributeError is the kind of the error diagnostics produced when a
+// body or object has an argument or attribute that is not expected, or when
+// an expression refers to an attribute that an object does not have.
+type UnknownAttributeError struct {
+	// Name is the name of the unexpected attribute.
+	Name string
+
+	// Suggestion is the name of an expected attribute that the author might
+	// have meant instead, or an empty string if there is no likely one.
+	Suggestion string
+
+	// Range is the range of the name of the attribute.
+	Range Range
+}
+
+func (e *UnknownAttributeError) Error() string {
+	return fmt.Sprintf("%s: unknown attribute %q", e.Range, e.Name)
+}
+
+// Unwrap returns the error value in the diagnostic's Extra field, if any,
+// which describes the kind of problem it reports. This allows errors.As and
+// errors.Is to find values like *SyntaxError within a diagnostic.
+func (d *Diagnostic) Unwrap() error {
+	err, _ := DiagnosticExtra[error](d)
+	return err
+}
+
+// As implements the interface used by errors.As, reporting whether any of the
+// error diagnostics in the receiver matches the given target. If so, the
+// first one that matches is assigned to the target.
+func (d Diagnostics) As(target interface{}) bool {
+	for _, diag := range d {
+		if diag.Severity == DiagError && errors.As(diag, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is implements the interface used by errors.Is, reporting whether any of
+// the error diagnostics in the receiver matches the given target.
+func (d Diagnostics) Is(target error) bool {
+	for _, diag := range d {
+		if diag.Severity == DiagError && errors.Is(diag, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSyntaxErrors sets the Extra field of each of the given error
+// diagnostics that has none to a *SyntaxError describing it. Parsers call
+// this on the diagnostics they return, and so it is rarely needed elsewhere.
+func MarkSyntaxErrors(diags Diagnostics) Diagnostics {
+	for _, diag := range diags {
+		if diag.Severity != DiagError || diag.Extra != nil {
+			continue
+		}
+		err := &SyntaxError{Summary: diag.Summary}
+		if diag.Subject != nil {
+			err.Range = *diag.Subject
+		}
+		diag.Extra = err
+	}
+	return diags
+}
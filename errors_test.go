@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDiagnosticsAs(t *testing.T) {
+	rng := Range{Filename: "test.hcl", Start: InitialPos, End: Pos{Line: 1, Column: 4, Byte: 3}}
+	diags := Diagnostics{
+		{
+			Severity: DiagWarning,
+			Summary:  "Deprecated argument",
+			Extra:    &UnknownAttributeError{Name: "ignored"},
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Unsupported argument",
+			Subject:  &rng,
+			Extra:    &UnknownAttributeError{Name: "foo", Suggestion: "for", Range: rng},
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Unterminated template string",
+			Subject:  &rng,
+		},
+	}
+	MarkSyntaxErrors(diags)
+
+	// Wrapping the diagnostics, as a caller might, must not prevent them
+	// from being found.
+	var err error = fmt.Errorf("loading config: %w", diags)
+
+	var unknown *UnknownAttributeError
+	if !errors.As(err, &unknown) {
+		t.Fatal("UnknownAttributeError not found")
+	}
+	if unknown.Name != "foo" || unknown.Suggestion != "for" || unknown.Range != rng {
+		t.Errorf("wrong UnknownAttributeError %#v", unknown)
+	}
+
+	var syntax *SyntaxError
+	if !errors.As(err, &syntax) {
+		t.Fatal("SyntaxError not found")
+	}
+	if syntax.Summary != "Unterminated template string" || syntax.Range != rng {
+		t.Errorf("wrong SyntaxError %#v", syntax)
+	}
+	if diags[0].Extra.(*UnknownAttributeError).Name != "ignored" || diags[1].Extra != unknown {
+		t.Error("MarkSyntaxErrors replaced an existing Extra value")
+	}
+
+	var mismatch *TypeMismatchError
+	if errors.As(err, &mismatch) {
+		t.Errorf("unexpected TypeMismatchError %#v", mismatch)
+	}
+
+	var diag *Diagnostic
+	if !errors.As(err, &diag) || diag != diags[1] {
+		t.Errorf("wrong Diagnostic %#v", diag)
+	}
+}
+
+func TestDiagnosticsIs(t *testing.T) {
+	diags := Diagnostics{
+		{
+			Severity: DiagError,
+			Summary:  "Parsing canceled",
+			Extra:    context.Canceled,
+		},
+	}
+	if !errors.Is(diags, context.Canceled) {
+		t.Error("context.Canceled not found")
+	}
+	if errors.Is(diags, context.DeadlineExceeded) {
+		t.Error("unexpected context.DeadlineExceeded")
+	}
+}
+
+func TestTypedErrorMessages(t *testing.T) {
+	rng := Range{Filename: "test.hcl", Start: InitialPos, End: Pos{Line: 1, Column: 4, Byte: 3}}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&SyntaxError{Summary: "Invalid character", Range: rng}, "test.hcl:1,1-4: Invalid character"},
+		{&TypeMismatchError{Want: cty.Number, Got: cty.String, Range: rng}, "test.hcl:1,1-4: number required, but got string"},
+		{&UnknownAttributeError{Name: "foo", Range: rng}, `test.hcl:1,1-4: unknown attribute "foo"`},
+	}
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("wrong message\ngot:  %s\nwant: %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package constraint extends HCL with simple value constraints, such as a
+// minimum or maximum or a set of allowed values, written in a compact
+// syntax that suits struct tags and schema files alike.
+package constraint
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Constraints is a set of conditions that a value must meet. A nil
+// *Constraints places no conditions on a value.
+type Constraints struct {
+	// Min and Max, if non-nil, constrain numbers by their value and strings
+	// and collections by their length.
+	Min, Max *big.Float
+
+	// OneOf, if non-empty, lists the allowed values of a string, number or
+	// bool, each written as it would be converted to a string.
+	OneOf []string
+
+	// Regex, if non-nil, must match strings.
+	Regex *regexp.Regexp
+}
+
+// Parse parses constraints written as comma-separated key=value pairs, like
+// "min=1,max=65535". The following keys are supported:
+//
+//	min    the minimum number, or the minimum length of a string or collection
+//	max    the maximum number, or the maximum length of a string or collection
+//	oneof  the allowed values, separated by spaces, like "oneof=tcp udp"
+//	regex  a regular expression that strings must match
+//
+// Since regular expressions may themselves contain commas, regex must be
+// the last key given, and its value is the entire remainder of the string.
+func Parse(s string) (*Constraints, error) {
+	ret := &Constraints{}
+	for s != "" {
+		var item string
+		if strings.HasPrefix(s, "regex=") {
+			item, s = s, ""
+		} else if comma := strings.Index(s, ","); comma >= 0 {
+			item, s = s[:comma], s[comma+1:]
+		} else {
+			item, s = s, ""
+		}
+
+		eq := strings.Index(item, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("constraint %q has no value", item)
+		}
+		key, value := item[:eq], item[eq+1:]
+		switch key {
+		case "min", "max":
+			n, _, err := big.ParseFloat(value, 10, 512, big.ToNearestEven)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s constraint %q: must be a number", key, value)
+			}
+			if key == "min" {
+				ret.Min = n
+			} else {
+				ret.Max = n
+			}
+		case "oneof":
+			ret.OneOf = strings.Fields(value)
+			if len(ret.OneOf) == 0 {
+				return nil, fmt.Errorf("invalid oneof constraint: at least one value is required")
+			}
+		case "regex":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex constraint: %s", err)
+			}
+			ret.Regex = re
+		default:
+			return nil, fmt.Errorf("unsupported constraint %q", key)
+		}
+	}
+	return ret, nil
+}
+
+// Check returns an error describing the first condition that the given value
+// does not meet, or nil if it meets all of them. Null and unknown values
+// always meet the conditions, since they are not yet final.
+//
+// The error message completes a sentence beginning with the name of the
+// value, like "must be at least 1". Callers will usually prefer Validate,
+// which reports the error as a diagnostic.
+func (c *Constraints) Check(val cty.Value) error {
+	if c == nil {
+		return nil
+	}
+	val, _ = val.UnmarkDeep()
+	if val.IsNull() || !val.IsWhollyKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.Number:
+		n := val.AsBigFloat()
+		if c.Min != nil && n.Cmp(c.Min) < 0 {
+			return fmt.Errorf("must be at least %s", c.Min.Text('g', -1))
+		}
+		if c.Max != nil && n.Cmp(c.Max) > 0 {
+			return fmt.Errorf("must be at most %s", c.Max.Text('g', -1))
+		}
+	case ty == cty.String:
+		if err := c.checkLength(utf8.RuneCountInString(val.AsString()), "characters"); err != nil {
+			return err
+		}
+		if c.Regex != nil && !c.Regex.MatchString(val.AsString()) {
+			return fmt.Errorf("must match the regular expression %q", c.Regex.String())
+		}
+	case ty.IsCollectionType() || ty.IsTupleType():
+		if err := c.checkLength(val.LengthInt(), "elements"); err != nil {
+			return err
+		}
+	}
+
+	if len(c.OneOf) > 0 && ty.IsPrimitiveType() {
+		str, err := convert.Convert(val, cty.String)
+		if err != nil {
+			return nil
+		}
+		for _, allowed := range c.OneOf {
+			if str.AsString() == allowed {
+				return nil
+			}
+		}
+		quoted := make([]string, len(c.OneOf))
+		for i, allowed := range c.OneOf {
+			quoted[i] = fmt.Sprintf("%q", allowed)
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(quoted, ", "))
+	}
+	return nil
+}
+
+func (c *Constraints) checkLength(length int, units string) error {
+	n := big.NewFloat(float64(length))
+	if c.Min != nil && n.Cmp(c.Min) < 0 {
+		return fmt.Errorf("must have at least %s %s", c.Min.Text('g', -1), units)
+	}
+	if c.Max != nil && n.Cmp(c.Max) > 0 {
+		return fmt.Errorf("must have at most %s %s", c.Max.Text('g', -1), units)
+	}
+	return nil
+}
+
+// Validate checks the given value, which was produced by the given
+// expression for the attribute of the given name, returning an error
+// diagnostic that refers to the expression if the value does not meet the
+// constraints.
+func (c *Constraints) Validate(name string, val cty.Value, expr hcl.Expression) hcl.Diagnostics {
+	err := c.Check(val)
+	if err == nil {
+		return nil
+	}
+	return hcl.Diagnostics{
+		{
+			Severity:   hcl.DiagError,
+			Summary:    "Invalid attribute value",
+			Detail:     fmt.Sprintf("The value of %q %s.", name, err),
+			Subject:    expr.Range().Ptr(),
+			Expression: expr,
+		},
+	}
+}
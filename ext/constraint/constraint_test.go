@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package constraint
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		spec string
+		val  cty.Value
+		want string
+	}{
+		{"min=1,max=65535", cty.NumberIntVal(80), ""},
+		{"min=1,max=65535", cty.NumberIntVal(0), "must be at least 1"},
+		{"min=1,max=65535", cty.NumberIntVal(70000), "must be at most 65535"},
+		{"min=0.5", cty.NumberFloatVal(0.25), "must be at least 0.5"},
+		{"min=2", cty.StringVal("a"), "must have at least 2 characters"},
+		{"max=2", cty.StringVal("abc"), "must have at most 2 characters"},
+		{"max=1", cty.ListVal([]cty.Value{cty.True, cty.False}), "must have at most 1 elements"},
+		{"min=1", cty.EmptyTupleVal, "must have at least 1 elements"},
+		{"oneof=tcp udp", cty.StringVal("udp"), ""},
+		{"oneof=tcp udp", cty.StringVal("icmp"), `must be one of "tcp", "udp"`},
+		{"oneof=1 2 3", cty.NumberIntVal(2), ""},
+		{"regex=^[a-z]+(,[a-z]+)*$", cty.StringVal("a,b"), ""},
+		{"min=1,regex=^[a-z]+$", cty.StringVal("A"), `must match the regular expression "^[a-z]+$"`},
+		{"min=1", cty.NullVal(cty.Number), ""},
+		{"min=1", cty.UnknownVal(cty.String), ""},
+		{"min=1", cty.NumberIntVal(0).Mark("sensitive"), "must be at least 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec+" "+test.val.GoString(), func(t *testing.T) {
+			c, err := Parse(test.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got := ""
+			if err := c.Check(test.val); err != nil {
+				got = err.Error()
+			}
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"min":        `constraint "min" has no value`,
+		"max=lots":   `invalid max constraint "lots": must be a number`,
+		"oneof=":     `invalid oneof constraint: at least one value is required`,
+		"regex=(":    "invalid regex constraint: error parsing regexp: missing closing ): `(`",
+		"min=1,size": `constraint "size" has no value`,
+		"size=1":     `unsupported constraint "size"`,
+	}
+
+	for spec, want := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := Parse(spec)
+			if err == nil {
+				t.Fatal("unexpected success")
+			}
+			if got := err.Error(); got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
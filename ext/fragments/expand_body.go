@@ -0,0 +1,301 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fragments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// expandBody wraps another hcl.Body and merges in the contents of any
+// fragments referenced by its "use" argument whenever Content,
+// PartialContent, or JustAttributes is called.
+type expandBody struct {
+	original hcl.Body
+	defs     map[string]*hcl.Block
+
+	// using is the chain of fragment names that were expanded to reach this
+	// body, used to detect fragments that refer to themselves.
+	using []string
+
+	// fragments are the bodies of the fragments used by this body. They are
+	// populated only on bodies returned as the "remain" result of
+	// PartialContent, in which case resolved is also set to indicate that
+	// the "use" argument was already consumed from the original body.
+	fragments []hcl.Body
+	resolved  bool
+}
+
+func (b *expandBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, _, diags := b.content(schema, false)
+	return content, diags
+}
+
+func (b *expandBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	return b.content(schema, true)
+}
+
+func (b *expandBody) content(schema *hcl.BodySchema, partial bool) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	// Required arguments might be provided by either the body itself or
+	// by one of its fragments, so we can check for them only after merging.
+	fragSchema := relaxSchema(schema)
+	localSchema := fragSchema
+	if !b.resolved {
+		localSchema = &hcl.BodySchema{
+			Attributes: append(fragSchema.Attributes, hcl.AttributeSchema{Name: "use"}),
+			Blocks:     fragSchema.Blocks,
+		}
+	}
+
+	var local *hcl.BodyContent
+	var localRemain hcl.Body
+	var diags hcl.Diagnostics
+	if partial {
+		local, localRemain, diags = b.original.PartialContent(localSchema)
+	} else {
+		local, diags = b.original.Content(localSchema)
+	}
+
+	// incomplete is set if we fail to retrieve the content of any of the
+	// fragments, in which case we can't reliably check required arguments.
+	incomplete := false
+
+	fragments := b.fragments
+	if !b.resolved {
+		var useDiags hcl.Diagnostics
+		fragments, useDiags = b.resolveUse(local.Attributes["use"])
+		diags = append(diags, useDiags...)
+		incomplete = useDiags.HasErrors()
+	}
+
+	content := &hcl.BodyContent{
+		Attributes:       make(hcl.Attributes, len(local.Attributes)),
+		MissingItemRange: b.MissingItemRange(),
+	}
+	var fragRemains []hcl.Body
+	for _, frag := range fragments {
+		var fragContent *hcl.BodyContent
+		var fragDiags hcl.Diagnostics
+		if partial {
+			var fragRemain hcl.Body
+			fragContent, fragRemain, fragDiags = frag.PartialContent(fragSchema)
+			fragRemains = append(fragRemains, fragRemain)
+		} else {
+			fragContent, fragDiags = frag.Content(fragSchema)
+		}
+		diags = append(diags, fragDiags...)
+		incomplete = incomplete || fragDiags.HasErrors()
+
+		for name, attr := range fragContent.Attributes {
+			content.Attributes[name] = attr
+		}
+		content.Blocks = append(content.Blocks, fragContent.Blocks...)
+	}
+
+	for name, attr := range local.Attributes {
+		if name == "use" && !b.resolved {
+			continue
+		}
+		content.Attributes[name] = attr
+	}
+	for _, block := range local.Blocks {
+		block := *block // shallow copy so we can mutate it
+		block.Body = &expandBody{
+			original: block.Body,
+			defs:     b.defs,
+			using:    b.using,
+		}
+		content.Blocks = append(content.Blocks, &block)
+	}
+
+	for _, attrS := range schema.Attributes {
+		if incomplete {
+			// Any missing arguments may well have been defined in the
+			// fragments we couldn't use, so we'd just be generating noise.
+			break
+		}
+		if _, defined := content.Attributes[attrS.Name]; attrS.Required && !defined {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required argument",
+				Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
+				Subject:  b.MissingItemRange().Ptr(),
+			})
+		}
+	}
+
+	var remain hcl.Body
+	if partial {
+		remain = &expandBody{
+			original:  localRemain,
+			defs:      b.defs,
+			using:     b.using,
+			fragments: fragRemains,
+			resolved:  true,
+		}
+	}
+
+	return content, remain, diags
+}
+
+func (b *expandBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	local, diags := b.original.JustAttributes()
+
+	fragments := b.fragments
+	if !b.resolved {
+		var useDiags hcl.Diagnostics
+		fragments, useDiags = b.resolveUse(local["use"])
+		diags = append(diags, useDiags...)
+	}
+
+	attrs := make(hcl.Attributes, len(local))
+	for _, frag := range fragments {
+		fragAttrs, fragDiags := frag.JustAttributes()
+		diags = append(diags, fragDiags...)
+		for name, attr := range fragAttrs {
+			attrs[name] = attr
+		}
+	}
+	for name, attr := range local {
+		if name == "use" && !b.resolved {
+			continue
+		}
+		attrs[name] = attr
+	}
+
+	return attrs, diags
+}
+
+func (b *expandBody) MissingItemRange() hcl.Range {
+	return b.original.MissingItemRange()
+}
+
+// resolveUse interprets the given "use" argument, which may be nil if the
+// body has no such argument, and returns the bodies of the fragments it
+// refers to.
+func (b *expandBody) resolveUse(attr *hcl.Attribute) ([]hcl.Body, hcl.Diagnostics) {
+	if attr == nil {
+		return nil, nil
+	}
+
+	var diags hcl.Diagnostics
+	exprs := []hcl.Expression{attr.Expr}
+	if _, callDiags := hcl.ExprCall(attr.Expr); callDiags.HasErrors() {
+		var listDiags hcl.Diagnostics
+		exprs, listDiags = hcl.ExprList(attr.Expr)
+		if listDiags.HasErrors() {
+			return nil, diags.Append(invalidUseDiagnostic(attr.Expr))
+		}
+	}
+
+	ret := make([]hcl.Body, 0, len(exprs))
+	for _, expr := range exprs {
+		call, callDiags := hcl.ExprCall(expr)
+		if callDiags.HasErrors() || call.Name != "ref" || len(call.Arguments) != 1 {
+			diags = diags.Append(invalidUseDiagnostic(expr))
+			continue
+		}
+
+		nameExpr := call.Arguments[0]
+		nameVal, valDiags := nameExpr.Value(nil)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			continue
+		}
+		nameVal, err := convert.Convert(nameVal, cty.String)
+		if err != nil || nameVal.IsNull() || !nameVal.IsKnown() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid fragment name",
+				Detail:   "The argument to ref must be a string giving the name of a fragment defined in a \"def\" block.",
+				Subject:  nameExpr.Range().Ptr(),
+			})
+			continue
+		}
+		name := nameVal.AsString()
+
+		def, exists := b.defs[name]
+		if !exists {
+			var suggestion string
+			if found := nameSuggestion(name, b.defNames()); found != "" {
+				suggestion = fmt.Sprintf(" Did you mean %q?", found)
+			}
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undefined fragment",
+				Detail:   fmt.Sprintf("There is no fragment named %q.%s", name, suggestion),
+				Subject:  nameExpr.Range().Ptr(),
+			})
+			continue
+		}
+
+		if i := indexOf(b.using, name); i >= 0 {
+			chain := append(append([]string(nil), b.using[i:]...), name)
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Circular fragment reference",
+				Detail:   fmt.Sprintf("The fragment %q refers to itself: %s.", name, strings.Join(chain, " -> ")),
+				Subject:  nameExpr.Range().Ptr(),
+			})
+			continue
+		}
+
+		ret = append(ret, &expandBody{
+			original: def.Body,
+			defs:     b.defs,
+			using:    append(append([]string(nil), b.using...), name),
+		})
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return ret, diags
+}
+
+func (b *expandBody) defNames() []string {
+	names := make([]string, 0, len(b.defs))
+	for name := range b.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indexOf(names []string, name string) int {
+	for i, candidate := range names {
+		if candidate == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func invalidUseDiagnostic(expr hcl.Expression) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid \"use\" argument",
+		Detail:   "The \"use\" argument must be a call to ref, like ref(\"name\"), or a list of such calls.",
+		Subject:  expr.Range().Ptr(),
+	}
+}
+
+// relaxSchema returns a copy of the given schema with all of its attributes
+// marked as optional.
+func relaxSchema(schema *hcl.BodySchema) *hcl.BodySchema {
+	ret := &hcl.BodySchema{
+		Attributes: make([]hcl.AttributeSchema, len(schema.Attributes), len(schema.Attributes)+1),
+		Blocks:     schema.Blocks,
+	}
+	for i, attrS := range schema.Attributes {
+		attrS.Required = false
+		ret.Attributes[i] = attrS
+	}
+	return ret
+}
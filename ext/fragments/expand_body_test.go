@@ -0,0 +1,315 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package fragments
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var serverSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "server", LabelNames: []string{"name"}},
+	},
+}
+
+var serverBodySchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "owner", Required: true},
+		{Name: "env"},
+		{Name: "port"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "tag"},
+	},
+}
+
+func parseAndExpand(t *testing.T, src string) hcl.Body {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	body, diags := Expand(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	return body
+}
+
+// serverContent returns the content of the single "server" block in the
+// given body.
+func serverContent(t *testing.T, body hcl.Body) (*hcl.BodyContent, hcl.Diagnostics) {
+	t.Helper()
+	content, diags := body.Content(serverSchema)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if len(content.Blocks) != 1 {
+		t.Fatalf("wrong number of server blocks %d; want 1", len(content.Blocks))
+	}
+	return content.Blocks[0].Body.Content(serverBodySchema)
+}
+
+func attrValues(t *testing.T, attrs hcl.Attributes) map[string]cty.Value {
+	t.Helper()
+	ret := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		ret[name] = val
+	}
+	return ret
+}
+
+func TestExpand(t *testing.T) {
+	body := parseAndExpand(t, `
+def "common" {
+  owner = "platform"
+  env   = "prod"
+
+  tag {}
+}
+
+server "web" {
+  use  = ref("common")
+  env  = "staging"
+  port = 80
+
+  tag {}
+}
+`)
+
+	content, diags := serverContent(t, body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := attrValues(t, content.Attributes)
+	want := map[string]cty.Value{
+		"owner": cty.StringVal("platform"),
+		"env":   cty.StringVal("staging"),
+		"port":  cty.NumberIntVal(80),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong attributes %#v; want %#v", got, want)
+	}
+	for name, wantVal := range want {
+		if !got[name].RawEquals(wantVal) {
+			t.Errorf("wrong value for %s: %#v; want %#v", name, got[name], wantVal)
+		}
+	}
+
+	if len(content.Blocks) != 2 {
+		t.Fatalf("wrong number of tag blocks %d; want 2", len(content.Blocks))
+	}
+	if got, want := content.Blocks[0].DefRange.Start.Line, 6; got != want {
+		t.Errorf("first tag block from line %d; want %d (from the fragment)", got, want)
+	}
+}
+
+func TestExpandMultipleAndNested(t *testing.T) {
+	body := parseAndExpand(t, `
+def "base" {
+  owner = "base"
+  env   = "base"
+  port  = 1
+}
+
+def "team" {
+  use   = ref("base")
+  owner = "team"
+}
+
+def "prod" {
+  env = "prod"
+}
+
+server "web" {
+  use = [ref("team"), ref("prod")]
+}
+`)
+
+	content, diags := serverContent(t, body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := attrValues(t, content.Attributes)
+	want := map[string]cty.Value{
+		"owner": cty.StringVal("team"),
+		"env":   cty.StringVal("prod"),
+		"port":  cty.NumberIntVal(1),
+	}
+	for name, wantVal := range want {
+		if !got[name].RawEquals(wantVal) {
+			t.Errorf("wrong value for %s: %#v; want %#v", name, got[name], wantVal)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := map[string]struct {
+		src         string
+		wantSummary string
+		wantDetail  string
+	}{
+		"undefined": {
+			`
+def "common" {
+  owner = "a"
+}
+server "web" {
+  use = ref("comon")
+}
+`,
+			"Reference to undefined fragment",
+			`There is no fragment named "comon". Did you mean "common"?`,
+		},
+		"circular": {
+			`
+def "a" {
+  use = ref("b")
+}
+def "b" {
+  use = ref("a")
+}
+server "web" {
+  use = ref("a")
+}
+`,
+			"Circular fragment reference",
+			`The fragment "a" refers to itself: a -> b -> a.`,
+		},
+		"not a call": {
+			`
+server "web" {
+  use = "common"
+}
+`,
+			`Invalid "use" argument`,
+			`The "use" argument must be a call to ref, like ref("name"), or a list of such calls.`,
+		},
+		"missing required": {
+			`
+def "common" {
+  env = "prod"
+}
+server "web" {
+  use = ref("common")
+}
+`,
+			"Missing required argument",
+			`The argument "owner" is required, but no definition was found.`,
+		},
+		"unsupported in fragment": {
+			`
+def "common" {
+  owner = "a"
+  bogus = "b"
+}
+server "web" {
+  use = ref("common")
+}
+`,
+			"Unsupported argument",
+			`An argument named "bogus" is not expected here.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := parseAndExpand(t, test.src)
+			_, diags := serverContent(t, body)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Summary; got != test.wantSummary {
+				t.Errorf("wrong summary %q; want %q", got, test.wantSummary)
+			}
+			if got := diags[0].Detail; got != test.wantDetail {
+				t.Errorf("wrong detail %q; want %q", got, test.wantDetail)
+			}
+		})
+	}
+}
+
+func TestExpandDuplicateDef(t *testing.T) {
+	f, diags := hclsyntax.ParseConfig([]byte("def \"a\" {}\ndef \"a\" {}\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	_, diags = Expand(f.Body)
+	if len(diags) != 1 || diags[0].Summary != "Duplicate fragment definition" {
+		t.Fatalf("wrong diagnostics: %s", diags.Error())
+	}
+}
+
+func TestExpandPartialContent(t *testing.T) {
+	body := parseAndExpand(t, `
+def "common" {
+  owner = "platform"
+  env   = "prod"
+}
+
+server "web" {
+  use  = ref("common")
+  port = 80
+}
+`)
+
+	content, diags := body.Content(serverSchema)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	server := content.Blocks[0].Body
+
+	first, remain, diags := server.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "owner"}},
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if _, ok := first.Attributes["owner"]; !ok || len(first.Attributes) != 1 {
+		t.Errorf("wrong first attributes %#v", first.Attributes)
+	}
+
+	rest, diags := remain.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	got := attrValues(t, rest)
+	if len(got) != 2 || !got["env"].RawEquals(cty.StringVal("prod")) || !got["port"].RawEquals(cty.NumberIntVal(80)) {
+		t.Errorf("wrong remaining attributes %#v", got)
+	}
+}
+
+func TestExpandJSON(t *testing.T) {
+	f, diags := json.Parse([]byte(`{
+  "def": {"common": {"owner": "platform"}},
+  "server": {"web": {"use": "ref(\"common\")", "env": "staging"}}
+}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	body, diags := Expand(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	content, diags := serverContent(t, body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	got := attrValues(t, content.Attributes)
+	if !got["owner"].RawEquals(cty.StringVal("platform")) || !got["env"].RawEquals(cty.StringVal("staging")) {
+		t.Errorf("wrong attributes %#v", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package fragments provides an extension to HCL that allows reusable
+// fragments of configuration to be defined once, in "def" blocks, and then
+// included into other blocks by name using a "use" argument.
+package fragments
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Expand extracts the "def" blocks from the given body and returns a new
+// body that includes the contents of those definitions wherever they are
+// referenced by a "use" argument.
+//
+// "def" blocks are permitted only at the top level of the given body, and
+// each has a single label giving the name of the fragment. A "use" argument
+// may appear in the given body or any nested block, including within a
+// "def" block, and its value is either a single call to the "ref" function
+// or a tuple of such calls:
+//
+//	def "common_tags" {
+//	  owner = "platform"
+//	  env   = "prod"
+//	}
+//
+//	resource "server" {
+//	  use  = ref("common_tags")
+//	  env  = "staging"
+//	  name = "web"
+//	}
+//
+// The contents of the referenced fragments are merged into the block that
+// uses them before decoding. Arguments defined directly in the block take
+// precedence over those from fragments, and where several fragments are
+// used, arguments from later fragments take precedence over earlier ones.
+// Nested blocks from fragments are included before the block's own nested
+// blocks.
+//
+// The "use" argument and "def" block type are therefore reserved, and must
+// not be used in the application's own schema.
+//
+// Expand returns diagnostics for problems with the "def" blocks themselves.
+// Problems with "use" arguments are detected only when the contents of the
+// body that contains them are retrieved, in the same way as for other
+// decoding errors.
+func Expand(body hcl.Body) (hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := body.PartialContent(defsSchema)
+
+	defs := make(map[string]*hcl.Block, len(content.Blocks))
+	for _, block := range content.Blocks {
+		name := block.Labels[0]
+		if existing, exists := defs[name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate fragment definition",
+				Detail:   fmt.Sprintf("A fragment named %q was already defined at %s. Fragment names must be unique.", name, existing.DefRange),
+				Subject:  &block.LabelRanges[0],
+			})
+			continue
+		}
+		defs[name] = block
+	}
+
+	return &expandBody{
+		original: remain,
+		defs:     defs,
+	}, diags
+}
+
+var defsSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "def",
+			LabelNames: []string{"name"},
+		},
+	},
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package redact identifies sensitive attributes by their paths, so that
+// tools that print or compare configuration can hide their values.
+package redact
+
+import (
+	"strings"
+)
+
+// Placeholder is the text that replaces the value of a sensitive attribute.
+const Placeholder = "(sensitive)"
+
+// Paths is a set of patterns that match the paths of sensitive attributes.
+//
+// The path of an attribute is the sequence of block types and labels leading
+// to it, followed by the attribute name, like the path
+// ["database", "main", "password"] for the "password" attribute of a block
+// written as database "main" { ... }. A pattern is a path written with its
+// elements separated by periods, in which an element "*" matches any single
+// element and an element "**" matches any number of elements, including
+// none. For example:
+//
+//	database.main.password    only the attribute above
+//	database.*.password       the password of every database block
+//	**.password               every attribute named password
+//
+// Since periods separate the elements of a pattern, a label containing a
+// period can be matched only by a wildcard.
+type Paths []string
+
+// Match returns true if the given attribute path matches any of the
+// patterns.
+func (p Paths) Match(path []string) bool {
+	for _, pattern := range p {
+		if matchElems(strings.Split(pattern, "."), path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchElems(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			for i := 0; i <= len(path); i++ {
+				if matchElems(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || path[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathsMatch(t *testing.T) {
+	tests := []struct {
+		Pattern string
+		Path    string
+		Want    bool
+	}{
+		{"password", "password", true},
+		{"password", "database.password", false},
+		{"database.main.password", "database.main.password", true},
+		{"database.main.password", "database.other.password", false},
+		{"database.*.password", "database.other.password", true},
+		{"database.*.password", "database.password", false},
+		{"**.password", "password", true},
+		{"**.password", "database.main.password", true},
+		{"**.password", "database.main.password_file", false},
+		{"database.**", "database.main.password", true},
+		{"database.**", "server.port", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Pattern+" "+test.Path, func(t *testing.T) {
+			got := Paths{test.Pattern}.Match(strings.Split(test.Path, "."))
+			if got != test.Want {
+				t.Errorf("wrong result %t; want %t", got, test.Want)
+			}
+		})
+	}
+}
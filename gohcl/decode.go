@@ -6,6 +6,7 @@ package gohcl
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/zclconf/go-cty/cty"
 
@@ -13,47 +14,97 @@ import (
 	"github.com/zclconf/go-cty/cty/gocty"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
 )
 
 // DecodeBody extracts the configuration within the given body into the given
 // value. This value must be a non-nil pointer to either a struct or
 // a map, where in the former case the configuration will be decoded using
 // struct tags and in the latter case only attributes are allowed and their
-// values are decoded into the map.
+// values are decoded into the map. A pointer to an OrderedMap is accepted
+// as for a map, retaining the order of the attributes.
 //
 // The given EvalContext is used to resolve any variables or functions in
 // expressions encountered while decoding. This may be nil to require only
 // constant values, for simple applications that do not support variables or
 // functions.
 //
+// Decoding continues past any problems it finds, so that the returned
+// diagnostics describe all of the problems at once, such as unexpected
+// arguments, values of the wrong type and missing required arguments,
+// rather than just the first. They are sorted by their position in the
+// source, and, since hcl.Diagnostics implements error, may be returned as a
+// single error that describes them all.
+//
 // The returned diagnostics should be inspected with its HasErrors method to
 // determine if the populated value is valid and complete. If error diagnostics
 // are returned then the given value may have been partially-populated but
 // may still be accessed by a careful caller for static analysis and editor
 // integration use-cases.
-func DecodeBody(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.Diagnostics {
+//
+// Optional DecodeOption values may be given to customize the decoding
+// behavior. See the documentation of each option for details.
+func DecodeBody(body hcl.Body, ctx *hcl.EvalContext, val interface{}, opts ...DecodeOption) hcl.Diagnostics {
 	rv := reflect.ValueOf(val)
 	if rv.Kind() != reflect.Ptr {
 		panic(fmt.Sprintf("target value must be a pointer, not %s", rv.Type().String()))
 	}
 
-	return decodeBodyToValue(body, ctx, rv.Elem())
+	return sortDiagnostics(decodeBodyToValue(body, ctx, rv.Elem(), newDecodeOpts(opts)))
 }
 
-func decodeBodyToValue(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value) hcl.Diagnostics {
+// DecodePartial is like DecodeBody, except that the given value must be a
+// non-nil pointer to a struct and any attributes and blocks that don't
+// correspond to a field of that struct are returned as a body rather than
+// reported as errors.
+//
+// This allows for a two-phase decode where an application decodes the
+// settings it knows about and then passes the remaining body to some other
+// component, such as a plugin, that knows how to decode the rest, perhaps
+// using DecodeBody with a struct of its own. This is similar to a field
+// tagged as "remain", but doesn't require the struct to declare one.
+//
+// The returned body is never nil, though it may be empty.
+func DecodePartial(body hcl.Body, ctx *hcl.EvalContext, val interface{}, opts ...DecodeOption) (hcl.Body, hcl.Diagnostics) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("target value must be a pointer to a struct, not %T", val))
+	}
+
+	remain, diags := decodeBodyToStructPartial(body, ctx, rv.Elem(), true, newDecodeOpts(opts))
+	if remain == nil {
+		remain = hcl.EmptyBody()
+	}
+	return remain, sortDiagnostics(diags)
+}
+
+func decodeBodyToValue(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value, opts *decodeOpts) hcl.Diagnostics {
 	et := val.Type()
+	if isOrderedMap(et) {
+		return decodeBodyToOrderedMap(body, ctx, val.Addr().Interface().(orderedMapTarget), opts)
+	}
 	switch et.Kind() {
 	case reflect.Struct:
-		return decodeBodyToStruct(body, ctx, val)
+		return decodeBodyToStruct(body, ctx, val, opts)
 	case reflect.Map:
-		return decodeBodyToMap(body, ctx, val)
+		return decodeBodyToMap(body, ctx, val, opts)
 	default:
-		panic(fmt.Sprintf("target value must be pointer to struct or map, not %s", et.String()))
+		panic(fmt.Sprintf("target value must be pointer to struct, map or OrderedMap, not %s", et.String()))
 	}
 }
 
-func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value) hcl.Diagnostics {
+func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	_, diags := decodeBodyToStructPartial(body, ctx, val, false, opts)
+	return diags
+}
+
+// decodeBodyToStructPartial is like decodeBodyToStruct but also returns the
+// body content that was not decoded. If forcePartial is set then content
+// that doesn't match the struct is left in that body rather than reported
+// as an error, even if the struct has no "remain" field.
+func decodeBodyToStructPartial(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value, forcePartial bool, opts *decodeOpts) (hcl.Body, hcl.Diagnostics) {
 	schema, partial := ImpliedBodySchema(val.Interface())
+	partial = partial || forcePartial
 
 	var content *hcl.BodyContent
 	var leftovers hcl.Body
@@ -64,7 +115,7 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		content, diags = body.Content(schema)
 	}
 	if content == nil {
-		return diags
+		return leftovers, diags
 	}
 
 	tags := getFieldTags(val.Type())
@@ -78,7 +129,7 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 			fieldV.Set(reflect.ValueOf(body))
 
 		default:
-			diags = append(diags, decodeBodyToValue(body, ctx, fieldV)...)
+			diags = append(diags, decodeBodyToValue(body, ctx, fieldV, opts)...)
 		}
 	}
 
@@ -96,7 +147,7 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 			}
 			fieldV.Set(reflect.ValueOf(attrs))
 		default:
-			diags = append(diags, decodeBodyToValue(leftovers, ctx, fieldV)...)
+			diags = append(diags, decodeBodyToValue(leftovers, ctx, fieldV, opts)...)
 		}
 	}
 
@@ -105,7 +156,15 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		field := val.Type().Field(fieldIdx)
 		fieldV := val.Field(fieldIdx)
 
+		if attrPresent, exists := tags.AttributePresent[name]; exists {
+			val.Field(attrPresent).SetBool(attr != nil)
+		}
+
 		if attr == nil {
+			if def, exists := tags.Defaults[name]; exists {
+				diags = append(diags, decodeDefault(name, def, body, field, fieldV, opts)...)
+				continue
+			}
 			if !exprType.AssignableTo(field.Type) {
 				continue
 			}
@@ -120,15 +179,15 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		}
 
 		if attrRange, exists := tags.AttributeRange[name]; exists {
-			val.Field(attrRange).Set(reflect.ValueOf(attr.Range))
+			setRangeField(val.Field(attrRange), attr.Range)
 		}
 
 		if attrNameRange, exists := tags.AttributeNameRange[name]; exists {
-			val.Field(attrNameRange).Set(reflect.ValueOf(attr.NameRange))
+			setRangeField(val.Field(attrNameRange), attr.NameRange)
 		}
 
 		if attrValueRange, exists := tags.AttributeValueRange[name]; exists {
-			val.Field(attrValueRange).Set(reflect.ValueOf(attr.Expr.Range()))
+			setRangeField(val.Field(attrValueRange), attr.Expr.Range())
 		}
 
 		switch {
@@ -137,9 +196,11 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		case exprType.AssignableTo(field.Type):
 			fieldV.Set(reflect.ValueOf(attr.Expr))
 		default:
-			diags = append(diags, DecodeExpression(
-				attr.Expr, ctx, fieldV.Addr().Interface(),
-			)...)
+			decodeDiags := decodeExpression(attr.Expr, ctx, fieldV.Addr().Interface(), opts)
+			diags = append(diags, decodeDiags...)
+			if c := tags.Constraints[name]; c != nil && !decodeDiags.HasErrors() {
+				diags = append(diags, validateAttribute(c, attr, ctx, fieldV)...)
+			}
 		}
 	}
 
@@ -211,13 +272,13 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 					if v.IsNil() {
 						v = reflect.New(ty)
 					}
-					diags = append(diags, decodeBlockToValue(block, ctx, v.Elem())...)
+					diags = append(diags, decodeBlockToValue(block, ctx, v.Elem(), opts)...)
 					sli.Index(i).Set(v)
 				} else {
 					if i >= sli.Len() {
 						sli = reflect.Append(sli, reflect.Indirect(reflect.New(ty)))
 					}
-					diags = append(diags, decodeBlockToValue(block, ctx, sli.Index(i))...)
+					diags = append(diags, decodeBlockToValue(block, ctx, sli.Index(i), opts)...)
 				}
 			}
 
@@ -234,20 +295,20 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 				if v.IsNil() {
 					v = reflect.New(ty)
 				}
-				diags = append(diags, decodeBlockToValue(block, ctx, v.Elem())...)
+				diags = append(diags, decodeBlockToValue(block, ctx, v.Elem(), opts)...)
 				val.Field(fieldIdx).Set(v)
 			} else {
-				diags = append(diags, decodeBlockToValue(block, ctx, val.Field(fieldIdx))...)
+				diags = append(diags, decodeBlockToValue(block, ctx, val.Field(fieldIdx), opts)...)
 			}
 
 		}
 
 	}
 
-	return diags
+	return leftovers, diags
 }
 
-func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value, opts *decodeOpts) hcl.Diagnostics {
 	attrs, diags := body.JustAttributes()
 	if attrs == nil {
 		return diags
@@ -263,7 +324,7 @@ func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.D
 			mv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(attr.Expr))
 		default:
 			ev := reflect.New(v.Type().Elem())
-			diags = append(diags, DecodeExpression(attr.Expr, ctx, ev.Interface())...)
+			diags = append(diags, decodeExpression(attr.Expr, ctx, ev.Interface(), opts)...)
 			mv.SetMapIndex(reflect.ValueOf(k), ev.Elem())
 		}
 	}
@@ -273,8 +334,8 @@ func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.D
 	return diags
 }
 
-func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
-	diags := decodeBodyToValue(block.Body, ctx, v)
+func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	diags := decodeBodyToValue(block.Body, ctx, v, opts)
 
 	blockTags := getFieldTags(v.Type())
 	for li, lv := range block.Labels {
@@ -284,44 +345,94 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 		v.Field(lfieldIdx).Set(reflect.ValueOf(lv))
 
 		if ix, exists := blockTags.LabelRange[lfieldName]; exists {
-			v.Field(ix).Set(reflect.ValueOf(block.LabelRanges[li]))
+			setRangeField(v.Field(ix), block.LabelRanges[li])
 		}
 	}
 
 	if blockTags.TypeRange != nil {
-		v.Field(*blockTags.TypeRange).Set(reflect.ValueOf(block.TypeRange))
+		setRangeField(v.Field(*blockTags.TypeRange), block.TypeRange)
 	}
 
 	if blockTags.DefRange != nil {
-		v.Field(*blockTags.DefRange).Set(reflect.ValueOf(block.DefRange))
+		setRangeField(v.Field(*blockTags.DefRange), block.DefRange)
 	}
 
 	return diags
 }
 
+// setRangeField assigns the given range to a field tagged with one of the
+// range tag kinds, which may be of type hcl.Range or hcl.Pos, or a pointer to
+// either. A field of type hcl.Pos receives the start of the range.
+func setRangeField(field reflect.Value, rng hcl.Range) {
+	switch field.Type() {
+	case rangeType:
+		field.Set(reflect.ValueOf(rng))
+	case reflect.PtrTo(rangeType):
+		field.Set(reflect.ValueOf(&rng))
+	case posType:
+		field.Set(reflect.ValueOf(rng.Start))
+	case reflect.PtrTo(posType):
+		pos := rng.Start
+		field.Set(reflect.ValueOf(&pos))
+	default:
+		panic(fmt.Sprintf("range field must be of type hcl.Range or hcl.Pos, or a pointer to either, not %s", field.Type()))
+	}
+}
+
 // DecodeExpression extracts the value of the given expression into the given
 // value. This value must be something that gocty is able to decode into,
 // since the final decoding is delegated to that package.
 //
+// As an exception, an object value can also be decoded into a struct whose
+// fields have "hcl" tags, or a map or slice of such structs, in which case
+// the object's attributes are decoded as if they were the arguments of a
+// body. This allows object constructor syntax, as in
+// listener = { port = 80 }, to be used with the same structs as blocks. An
+// object-typed argument with the same name as a "block" field is decoded as
+// a single block, and a list of objects as a sequence of blocks.
+//
 // The given EvalContext is used to resolve any variables or functions in
 // expressions encountered while decoding. This may be nil to require only
 // constant values, for simple applications that do not support variables or
 // functions.
 //
+// As with DecodeBody, the returned diagnostics describe all of the problems
+// found rather than just the first, sorted by their position in the source.
+//
 // The returned diagnostics should be inspected with its HasErrors method to
 // determine if the populated value is valid and complete. If error diagnostics
 // are returned then the given value may have been partially-populated but
 // may still be accessed by a careful caller for static analysis and editor
 // integration use-cases.
-func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}) hcl.Diagnostics {
+//
+// Optional DecodeOption values may be given to customize the decoding
+// behavior. See the documentation of each option for details.
+func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}, opts ...DecodeOption) hcl.Diagnostics {
+	return sortDiagnostics(decodeExpression(expr, ctx, val, newDecodeOpts(opts)))
+}
+
+func decodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}, opts *decodeOpts) hcl.Diagnostics {
+	if target, ok := val.(orderedMapTarget); ok {
+		return decodeExpressionToOrderedMap(expr, ctx, target, opts)
+	}
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Ptr && isHCLStructTarget(rv.Type()) {
+		return decodeExpressionToStruct(expr, ctx, rv, opts)
+	}
+
 	srcVal, diags := expr.Value(ctx)
 
-	convTy, err := gocty.ImpliedType(val)
+	convTy, err := impliedType(val)
 	if err != nil {
 		panic(fmt.Sprintf("unsuitable DecodeExpression target: %s", err))
 	}
 
-	srcVal, err = convert.Convert(srcVal, convTy)
+	if opts.strict || opts.strictTypes {
+		// We continue with the conversion even if there are problems here,
+		// so that any other problems with the value are reported too.
+		diags = append(diags, checkStrict(srcVal, convTy, expr, opts)...)
+	}
+
+	convVal, err := convert.Convert(srcVal, convTy)
 	if err != nil {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -329,9 +440,29 @@ func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}
 			Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
 			Subject:  expr.StartRange().Ptr(),
 			Context:  expr.Range().Ptr(),
+			Extra: &hcl.TypeMismatchError{
+				Want:  convTy,
+				Got:   srcVal.Type(),
+				Range: expr.Range(),
+			},
 		})
 		return diags
 	}
+	srcVal = convVal
+
+	if opts.preciseNumbers {
+		err = checkPreciseNumbers(srcVal, reflect.TypeOf(val), nil)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsuitable value type",
+				Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
+				Subject:  expr.StartRange().Ptr(),
+				Context:  expr.Range().Ptr(),
+			})
+			return diags
+		}
+	}
 
 	err = gocty.FromCtyValue(srcVal, val)
 	if err != nil {
@@ -346,3 +477,78 @@ func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}
 
 	return diags
 }
+
+// decodeDefault assigns the default value given in the struct tag of the
+// given field, for an attribute that is absent from the given body. If the
+// options call for strict decoding, it also returns a warning noting that
+// the default was used.
+func decodeDefault(name, def string, body hcl.Body, field reflect.StructField, fieldV reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	rng := body.MissingItemRange()
+	expr := hcl.StaticExpr(cty.StringVal(def), rng)
+
+	switch {
+	case attrType.AssignableTo(field.Type):
+		fieldV.Set(reflect.ValueOf(&hcl.Attribute{
+			Name:      name,
+			Expr:      expr,
+			Range:     rng,
+			NameRange: rng,
+		}))
+	case exprType.AssignableTo(field.Type):
+		fieldV.Set(reflect.ValueOf(expr))
+	default:
+		// The default is always written as a string, so it must be
+		// converted to the field's type even under strict type checking.
+		if diags := decodeExpression(expr, nil, fieldV.Addr().Interface(), &decodeOpts{}); diags.HasErrors() {
+			panic(fmt.Sprintf("invalid default value %q for %s field %q: %s", def, field.Type.String(), field.Name, diags.Error()))
+		}
+	}
+
+	if !opts.strict {
+		return nil
+	}
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "Default value used",
+			Detail:   fmt.Sprintf("The argument %q is not set, so its default value %q is used.", name, def),
+			Subject:  rng.Ptr(),
+		},
+	}
+}
+
+// validateAttribute checks the value of the given attribute, which has
+// already been decoded successfully into the given field, against the
+// constraints from the field's validate tag.
+func validateAttribute(c *constraint.Constraints, attr *hcl.Attribute, ctx *hcl.EvalContext, fieldV reflect.Value) hcl.Diagnostics {
+	// The value was already evaluated successfully during decoding, so we
+	// can ignore any diagnostics here. We convert it to the field's type
+	// so that, for example, a string given for a number field is checked
+	// as a number.
+	val, _ := attr.Expr.Value(ctx)
+	if ty, err := impliedType(fieldV.Addr().Interface()); err == nil {
+		if conv, err := convert.Convert(val, ty); err == nil {
+			val = conv
+		}
+	}
+	return c.Validate(attr.Name, val, attr.Expr)
+}
+
+// sortDiagnostics sorts the given diagnostics in place by the position of
+// their subjects, with any that have no subject first, and returns them.
+// Diagnostics are otherwise produced in an order that depends on the
+// iteration order of Go maps.
+func sortDiagnostics(diags hcl.Diagnostics) hcl.Diagnostics {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Subject, diags[j].Subject
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		case a.Filename != b.Filename:
+			return a.Filename < b.Filename
+		default:
+			return a.Start.Byte < b.Start.Byte
+		}
+	})
+	return diags
+}
@@ -5,14 +5,19 @@ package gohcl
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hclJSON "github.com/hashicorp/hcl/v2/json"
 )
 
@@ -898,3 +903,637 @@ func makeRange(filename string, line int, start, end int) hcl.Range {
 		},
 	}
 }
+
+func TestDecodeBodyStrict(t *testing.T) {
+	type Settings struct {
+		Name        string `cty:"name"`
+		Description string `cty:"description"`
+	}
+	type Config struct {
+		Settings Settings `hcl:"settings"`
+	}
+
+	src := `{"settings": {"name": "foo", "description": "", "descripton": "bar"}}`
+	file, diags := hclJSON.Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		if got.Settings.Name != "foo" {
+			t.Errorf("wrong name %q; want %q", got.Settings.Name, "foo")
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got, OptStrict())
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		diag := diags[0]
+		if got, want := diag.Detail, `An attribute named "descripton" is not expected here. Did you mean "description"?`; got != want {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := diag.Subject.Start.Byte, strings.Index(src, `"descripton"`); got != want {
+			t.Errorf("wrong subject start byte %d; want %d", got, want)
+		}
+	})
+}
+
+func TestDecodeBodyStrictTypes(t *testing.T) {
+	type Config struct {
+		Count   int               `hcl:"count"`
+		Name    string            `hcl:"name"`
+		Enabled bool              `hcl:"enabled"`
+		Ports   []int             `hcl:"ports"`
+		Tags    map[string]string `hcl:"tags"`
+		Any     cty.Value         `hcl:"any"`
+	}
+
+	src := `
+count   = "5"
+name    = true
+enabled = "false"
+ports   = [80, "443"]
+tags    = { env = "prod", tier = 1 }
+any     = "anything"
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		want := Config{
+			Count:   5,
+			Name:    "true",
+			Enabled: false,
+			Ports:   []int{80, 443},
+			Tags:    map[string]string{"env": "prod", "tier": "1"},
+			Any:     cty.StringVal("anything"),
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got, OptStrictTypes())
+		var gotDiags []string
+		for _, diag := range diags {
+			gotDiags = append(gotDiags, fmt.Sprintf("%d:%d: %s", diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Detail))
+		}
+		sort.Strings(gotDiags)
+		wantDiags := []string{
+			"2:11: A number is required here, but the given value is a string.",
+			"3:11: A string is required here, but the given value is a bool.",
+			"4:11: A bool is required here, but the given value is a string.",
+			"5:16: A number is required here, but the given value is a string.",
+			"6:34: A string is required here, but the given value is a number.",
+		}
+		if !reflect.DeepEqual(gotDiags, wantDiags) {
+			t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, wantDiags)
+		}
+		if !got.Any.RawEquals(cty.StringVal("anything")) {
+			t.Errorf("wrong value for any: %#v", got.Any)
+		}
+	})
+}
+
+func TestDecodeBodyDefaults(t *testing.T) {
+	type Config struct {
+		Host     string         `hcl:"host,default=localhost"`
+		Port     int            `hcl:"port,default=8080"`
+		TLS      bool           `hcl:"tls,default=true"`
+		Greeting string         `hcl:"greeting,default=hello, world"`
+		Timeout  hcl.Expression `hcl:"timeout,default=30s"`
+	}
+
+	src := `
+host = "example.com"
+tls  = false
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	check := func(t *testing.T, got Config) {
+		t.Helper()
+		if got.Host != "example.com" || got.Port != 8080 || got.TLS || got.Greeting != "hello, world" {
+			t.Errorf("wrong result: %#v", got)
+		}
+		timeout, diags := got.Timeout.Value(nil)
+		if diags.HasErrors() || !timeout.RawEquals(cty.StringVal("30s")) {
+			t.Errorf("wrong timeout %#v", timeout)
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got)
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+		check(t, got)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got, OptStrict(), OptStrictTypes())
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		var gotDetails []string
+		for _, diag := range diags {
+			gotDetails = append(gotDetails, diag.Detail)
+		}
+		sort.Strings(gotDetails)
+		wantDetails := []string{
+			`The argument "greeting" is not set, so its default value "hello, world" is used.`,
+			`The argument "port" is not set, so its default value "8080" is used.`,
+			`The argument "timeout" is not set, so its default value "30s" is used.`,
+		}
+		if !reflect.DeepEqual(gotDetails, wantDetails) {
+			t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDetails, wantDetails)
+		}
+		check(t, got)
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		type Invalid struct {
+			Port int `hcl:"port,default=http"`
+		}
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		var got Invalid
+		DecodeBody(file.Body, nil, &got)
+	})
+}
+
+func TestDecodeBodyRequired(t *testing.T) {
+	type Listener struct {
+		Name string `hcl:"name,label"`
+		Port *int   `hcl:"port,required"`
+		Host string `hcl:"host,optional"`
+	}
+	type Config struct {
+		Listeners []Listener `hcl:"listener,block"`
+	}
+
+	src := `
+listener "a" {
+  port = 80
+}
+listener "b" {
+  host = "example.com"
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, nil, &got)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	diag := diags[0]
+	if got, want := diag.Summary, "Missing required argument"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diag.Subject.Start.Line, 5; got != want {
+		t.Errorf("wrong subject line %d; want %d", got, want)
+	}
+	if port := got.Listeners[0].Port; port == nil || *port != 80 {
+		t.Errorf("wrong port %v; want 80", port)
+	}
+}
+
+func TestDecodeBodyValidate(t *testing.T) {
+	type Listener struct {
+		Port     int      `hcl:"port" validate:"min=1,max=65535"`
+		Protocol string   `hcl:"protocol,optional" validate:"oneof=tcp udp"`
+		Hosts    []string `hcl:"hosts,optional" validate:"min=1"`
+	}
+	type Config struct {
+		Listener Listener `hcl:"listener,block"`
+	}
+
+	tests := map[string]struct {
+		src  string
+		want []string
+	}{
+		"valid": {
+			`listener {
+  port     = "8080"
+  protocol = "udp"
+}`,
+			nil,
+		},
+		"invalid": {
+			`listener {
+  port     = 0
+  protocol = "icmp"
+  hosts    = []
+}`,
+			[]string{
+				`test.hcl:2,14-15: Invalid attribute value; The value of "port" must be at least 1.`,
+				`test.hcl:3,14-20: Invalid attribute value; The value of "protocol" must be one of "tcp", "udp".`,
+				`test.hcl:4,14-16: Invalid attribute value; The value of "hosts" must have at least 1 elements.`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			var got Config
+			diags = DecodeBody(file.Body, nil, &got)
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Error())
+			}
+			sort.Strings(gotDiags)
+			if !reflect.DeepEqual(gotDiags, test.want) {
+				t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, test.want)
+			}
+		})
+	}
+
+	t.Run("invalid tag", func(t *testing.T) {
+		type Invalid struct {
+			Port int `hcl:"port" validate:"min=low"`
+		}
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		ImpliedBodySchema(Invalid{})
+	})
+}
+
+func TestDecodeBodyAllErrors(t *testing.T) {
+	type Settings struct {
+		Level int `hcl:"level"`
+	}
+	type Listener struct {
+		Name     string   `hcl:"name,label"`
+		Port     int      `hcl:"port"`
+		Host     string   `hcl:"host"`
+		Settings Settings `hcl:"settings,optional"`
+	}
+	type Config struct {
+		Count     int        `hcl:"count"`
+		Listeners []Listener `hcl:"listener,block"`
+	}
+
+	src := `count = "many"
+bogus = 1
+listener "a" {
+  port     = "http"
+  extra    = 1
+  settings = { level = "high", lvel = 1 }
+}
+listener "b" {
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	want := []string{
+		`test.hcl:1,10-14: Unsuitable value type; Unsuitable value: a number is required`,
+		`test.hcl:2,1-6: Unsupported argument; An argument named "bogus" is not expected here.`,
+		`test.hcl:3,14-14: Missing required argument; The argument "host" is required, but no definition was found.`,
+		`test.hcl:4,15-19: Unsuitable value type; Unsuitable value: a number is required`,
+		`test.hcl:5,3-8: Unsupported argument; An argument named "extra" is not expected here.`,
+		`test.hcl:6,25-29: Unsuitable value type; Unsuitable value: a number is required`,
+		`test.hcl:6,32-36: Unsupported argument; An argument named "lvel" is not expected here. Did you mean "level"?`,
+		`test.hcl:8,14-14: Missing required argument; The argument "host" is required, but no definition was found.`,
+		`test.hcl:8,14-14: Missing required argument; The argument "port" is required, but no definition was found.`,
+	}
+
+	// The order of the diagnostics must not depend on map iteration order.
+	for i := 0; i < 10; i++ {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got)
+		var gotDiags []string
+		for _, diag := range diags {
+			gotDiags = append(gotDiags, diag.Error())
+		}
+		if !reflect.DeepEqual(gotDiags, want) {
+			t.Fatalf("wrong diagnostics\ngot:  %#v\nwant: %#v", gotDiags, want)
+		}
+	}
+}
+
+func TestDecodeBodyTypedErrors(t *testing.T) {
+	type Config struct {
+		Name  string `hcl:"name"`
+		Count int    `hcl:"count,optional"`
+	}
+
+	src := `
+count = "many"
+nmae  = "typo"
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	var got Config
+	var err error = DecodeBody(file.Body, nil, &got)
+
+	var unknown *hcl.UnknownAttributeError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("no UnknownAttributeError in %s", err)
+	}
+	if unknown.Name != "nmae" || unknown.Suggestion != "name" || unknown.Range.Start.Line != 3 {
+		t.Errorf("wrong UnknownAttributeError %#v", unknown)
+	}
+
+	var mismatch *hcl.TypeMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("no TypeMismatchError in %s", err)
+	}
+	if !mismatch.Want.Equals(cty.Number) || !mismatch.Got.Equals(cty.String) || mismatch.Range.Start.Line != 2 {
+		t.Errorf("wrong TypeMismatchError %#v", mismatch)
+	}
+
+	_, diags = hclsyntax.ParseConfig([]byte("name = \"web\n"), "test.hcl", hcl.InitialPos)
+	var syntaxErr *hcl.SyntaxError
+	if !errors.As(diags, &syntaxErr) {
+		t.Fatalf("no SyntaxError in %s", diags)
+	}
+	if syntaxErr.Summary != "Invalid multi-line string" {
+		t.Errorf("wrong SyntaxError %#v", syntaxErr)
+	}
+}
+
+func TestDecodeBodyPreciseNumbers(t *testing.T) {
+	type Config struct {
+		ID      string     `hcl:"id"`
+		Amount  *big.Float `hcl:"amount"`
+		Ratio   float64    `hcl:"ratio"`
+		Weights []float64  `hcl:"weights,optional"`
+	}
+
+	src := `{"id": 9007199254740993, "amount": 12345678901234567890.01, "ratio": 0.1, "weights": [1.5, 9007199254740993]}`
+	file, diags := hclJSON.Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	t.Run("default", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		if got, want := got.ID, "9007199254740993"; got != want {
+			t.Errorf("wrong id %q; want %q", got, want)
+		}
+		if got, want := got.Weights[1], float64(9007199254740992); got != want {
+			t.Errorf("wrong weight %f; want %f", got, want)
+		}
+	})
+
+	t.Run("precise", func(t *testing.T) {
+		var got Config
+		diags := DecodeBody(file.Body, nil, &got, OptPreciseNumbers())
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		if got, want := diags[0].Detail, "Unsuitable value: value cannot be represented exactly as a 64-bit floating point number"; got != want {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := got.Amount.Text('f', 2), "12345678901234567890.01"; got != want {
+			t.Errorf("wrong amount %s; want %s", got, want)
+		}
+		if got, want := got.Ratio, 0.1; got != want {
+			t.Errorf("wrong ratio %f; want %f", got, want)
+		}
+	})
+}
+
+func TestDecodeBodyNull(t *testing.T) {
+	type Config struct {
+		Name        *string `hcl:"name,optional"`
+		NamePresent bool    `hcl:"name,attr_present"`
+		Size        *int    `hcl:"size,optional"`
+		SizePresent bool    `hcl:"size,attr_present"`
+		Tags        *string `hcl:"tags,optional"`
+		TagsPresent bool    `hcl:"tags,attr_present"`
+	}
+
+	src := `{"name": null, "size": 3}`
+	file, diags := hclJSON.Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	initial := "initial"
+	got := Config{Name: &initial}
+	diags = DecodeBody(file.Body, nil, &got)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	if got.Name != nil || !got.NamePresent {
+		t.Errorf("wrong name %v (present %t); want nil (present true)", got.Name, got.NamePresent)
+	}
+	if got.Size == nil || *got.Size != 3 || !got.SizePresent {
+		t.Errorf("wrong size %v (present %t); want 3 (present true)", got.Size, got.SizePresent)
+	}
+	if got.Tags != nil || got.TagsPresent {
+		t.Errorf("wrong tags %v (present %t); want nil (present false)", got.Tags, got.TagsPresent)
+	}
+}
+
+func TestDecodeBodyPositions(t *testing.T) {
+	type Listener struct {
+		Name      string     `hcl:"name,label"`
+		NamePos   hcl.Pos    `hcl:"name,label_range"`
+		DefPos    *hcl.Pos   `hcl:",def_range"`
+		Port      int        `hcl:"port"`
+		PortPos   hcl.Pos    `hcl:"port,attr_value_range"`
+		Host      string     `hcl:"host,optional"`
+		HostRange *hcl.Range `hcl:"host,attr_range"`
+	}
+	type Config struct {
+		Listeners []Listener `hcl:"listener,block"`
+	}
+
+	src := `
+listener "a" {
+  port = 80
+  host = "localhost"
+}
+listener "b" {
+  port = 8080
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(f.Body, nil, &got)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if len(got.Listeners) != 2 {
+		t.Fatalf("wrong number of listeners %d", len(got.Listeners))
+	}
+
+	a, b := got.Listeners[0], got.Listeners[1]
+	if want := (hcl.Pos{Line: 2, Column: 10, Byte: 10}); a.NamePos != want {
+		t.Errorf("wrong label position %#v; want %#v", a.NamePos, want)
+	}
+	if a.DefPos == nil || a.DefPos.Line != 2 || a.DefPos.Column != 1 {
+		t.Errorf("wrong definition position %#v", a.DefPos)
+	}
+	if want := (hcl.Pos{Line: 3, Column: 10, Byte: 25}); a.PortPos != want {
+		t.Errorf("wrong port position %#v; want %#v", a.PortPos, want)
+	}
+	if a.HostRange == nil || a.HostRange.Start.Line != 4 || a.HostRange.Filename != "test.hcl" {
+		t.Errorf("wrong host range %#v", a.HostRange)
+	}
+	if b.HostRange != nil {
+		t.Errorf("host range set for absent attribute: %#v", b.HostRange)
+	}
+	if b.PortPos.Line != 7 {
+		t.Errorf("wrong port line %d; want 7", b.PortPos.Line)
+	}
+}
+
+func TestDecodeBodyInvalidRangeField(t *testing.T) {
+	type Config struct {
+		Port      int    `hcl:"port"`
+		PortRange string `hcl:"port,attr_range"`
+	}
+	f, diags := hclsyntax.ParseConfig([]byte("port = 80\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("no panic for range field of the wrong type")
+		}
+		if msg := fmt.Sprint(r); !strings.Contains(msg, "hcl.Range or hcl.Pos") {
+			t.Errorf("wrong panic message %q", msg)
+		}
+	}()
+	var got Config
+	DecodeBody(f.Body, nil, &got)
+}
+
+func TestDecodePartial(t *testing.T) {
+	type Outer struct {
+		Name    string   `hcl:"name"`
+		Plugins []string `hcl:"plugins,optional"`
+	}
+	type Inner struct {
+		Level  int `hcl:"level"`
+		Output struct {
+			Path string `hcl:"path"`
+		} `hcl:"output,block"`
+	}
+
+	src := `
+name = "app"
+level = 3
+output {
+  path = "/tmp/out"
+}
+`
+	for _, syntax := range []string{"native", "json"} {
+		t.Run(syntax, func(t *testing.T) {
+			var file *hcl.File
+			var diags hcl.Diagnostics
+			if syntax == "native" {
+				file, diags = hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+			} else {
+				file, diags = hclJSON.Parse([]byte(`{"name": "app", "level": 3, "output": {"path": "/tmp/out"}}`), "test.json")
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			var outer Outer
+			remain, diags := DecodePartial(file.Body, nil, &outer)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if outer.Name != "app" {
+				t.Errorf("wrong name %q", outer.Name)
+			}
+
+			var inner Inner
+			diags = DecodeBody(remain, nil, &inner)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if inner.Level != 3 || inner.Output.Path != "/tmp/out" {
+				t.Errorf("wrong inner result %#v", inner)
+			}
+
+			// The outer settings are not part of the remaining body.
+			var again Outer
+			diags = DecodeBody(remain, nil, &again)
+			if !diags.HasErrors() {
+				t.Errorf("outer settings still present in remaining body")
+			}
+		})
+	}
+}
+
+func TestDecodePartialErrors(t *testing.T) {
+	type Outer struct {
+		Name string `hcl:"name"`
+	}
+	file, diags := hclsyntax.ParseConfig([]byte("other = 1\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var outer Outer
+	remain, diags := DecodePartial(file.Body, nil, &outer)
+	if !diags.HasErrors() {
+		t.Fatalf("missing error for absent required argument")
+	}
+	if remain == nil {
+		t.Fatalf("remaining body is nil")
+	}
+	attrs, _ := remain.JustAttributes()
+	if _, ok := attrs["other"]; !ok {
+		t.Errorf("unrecognized argument missing from remaining body")
+	}
+}
@@ -20,12 +20,21 @@
 //	attr (the default) indicates that the value is to be populated from an attribute
 //	block indicates that the value is to populated from a block
 //	label indicates that the value is to populated from a block label
+//	required is the same as attr, but the attribute is required even if the field is a pointer or expression
 //	optional is the same as attr, but the field is optional
+//	default=value is the same as optional, but the field receives the given value if the attribute is absent
 //	remain indicates that the value is to be populated from the remaining body after populating other fields
 //
 // "attr" fields may either be of type *hcl.Expression, in which case the raw
 // expression is assigned, or of any type accepted by gocty, in which case
-// gocty will be used to assign the value to a native Go type.
+// gocty will be used to assign the value to a native Go type. Fields of type
+// big.Float or big.Int, or pointers to them, receive numbers at their full
+// precision. Fields of type OrderedMap receive objects and maps with their
+// keys in the order they were written, rather than in the arbitrary order
+// of a Go map. Fields whose type is a struct that itself uses these tags, or
+// a map or slice of such structs, receive object values such as those written
+// with object constructor syntax, as in listener = { port = 80 }, decoded as
+// if each object were a block body.
 //
 // "block" fields may be a struct that recursively uses the same tags, or a
 // slice of such structs, in which case multiple blocks of the corresponding
@@ -44,9 +53,26 @@
 // an identifier for the label in diagnostic messages and (b) to match the
 // which with the equivalent "label_range" field (if it exists).
 //
+// "attr" fields are required unless they are pointers or of type
+// hcl.Expression, in which case a missing attribute is represented by nil or
+// by a null-valued expression respectively. "required" fields are always
+// required. A missing required attribute is reported as an error diagnostic
+// whose subject is the enclosing block, or the end of the file for
+// top-level attributes, rather than leaving the field with its zero value.
+//
 // "optional" fields behave like "attr" fields, but they are optional
 // and will not give parsing errors if they are missing.
 //
+// "default=" fields are optional too, but if the attribute is missing then
+// the text after the equals sign is decoded into the field instead, as if
+// it were a string value written in the configuration. The usual conversion
+// rules therefore apply, so that for example `hcl:"port,default=8080"`
+// suits a field of type int, and `hcl:"tls,default=true"` one of type bool.
+// Since the default is the remainder of the tag, it may itself contain
+// commas. A default that cannot be converted to the field's type is a bug
+// in the calling program and so causes a panic. When decoding with
+// OptStrict, each default that is used is reported as a warning.
+//
 // "remain" can be placed on a single field that may be either of type
 // hcl.Body or hcl.Attributes, in which case any remaining body content is
 // placed into this field for delayed processing. If no "remain" field is
@@ -82,6 +108,31 @@
 // attribute with the corresponding name. The name token is used to match with
 // the name of the attribute that this range will specify.
 //
+// Any of the range kinds above may instead be placed on a field of type
+// hcl.Pos, which receives only the start of the range, or on a pointer to
+// an hcl.Range or hcl.Pos. A pointer field is left unchanged, and so
+// typically nil, if the corresponding attribute or block is absent, which
+// allows an application to report its own errors against the position of
+// a value only when that value was actually written in the configuration.
+//
+// "attr_present" can be placed on multiple fields that must be of type bool.
+// This field will be set to true if the attribute with the corresponding name
+// is present in the body, and false otherwise. Together with a pointer-typed
+// field for the attribute itself, which is set to nil when the attribute's
+// value is null, this allows distinguishing an attribute that was omitted
+// from one that was explicitly set to null.
+//
+// Any field that receives an attribute value, other than one of type
+// hcl.Expression or *hcl.Attribute, may also have a "validate" tag giving
+// constraints on the value in the syntax understood by the "ext/constraint"
+// package, like the following:
+//
+//	Port int `hcl:"port" validate:"min=1,max=65535"`
+//
+// A value that does not meet its constraints is reported as an error
+// diagnostic referring to the attribute's expression. Constraints are not
+// applied to default values given in the "hcl" tag.
+//
 // Only a subset of this tagging/typing vocabulary is supported for the
 // "Encode" family of functions. See the EncodeIntoBody docs for full details
 // on the constraints there.
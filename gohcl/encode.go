@@ -8,7 +8,9 @@ import (
 	"reflect"
 	"sort"
 
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
 
@@ -23,6 +25,10 @@ import (
 // into hcl.Attributes values. This function does not have enough information
 // to complete the decoding of these types.
 //
+// Attribute fields whose type is a struct that itself uses the tags defined
+// in this package, or a map or slice of such structs, are written as object
+// constructors, or tuples of them, with the structs' fields as attributes.
+//
 // Any fields tagged as "label" are ignored by this function. Use EncodeAsBlock
 // to produce a whole hclwrite.Block including block labels.
 //
@@ -131,6 +137,20 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 				prevWasBlock = false
 			}
 
+			if isOrderedMap(fieldTy) {
+				elemTy := fieldTy.Elem().Field(1).Type // MapEntry.Value
+				if exprType.AssignableTo(elemTy) || attrType.AssignableTo(elemTy) {
+					continue // ignore undecoded fields
+				}
+				dst.SetAttributeRaw(name, tokensForOrderedMap(fieldVal))
+				continue
+			}
+
+			if isHCLStructTarget(fieldTy) {
+				dst.SetAttributeRaw(name, tokensForHCLStruct(fieldVal))
+				continue
+			}
+
 			valTy, err := gocty.ImpliedType(fieldVal.Interface())
 			if err != nil {
 				panic(fmt.Sprintf("cannot encode %T as HCL expression: %s", fieldVal.Interface(), err))
@@ -192,3 +212,143 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 		}
 	}
 }
+
+// tokensForOrderedMap returns tokens for an object constructor expression
+// containing the entries of the given OrderedMap value, in order.
+func tokensForOrderedMap(rv reflect.Value) hclwrite.Tokens {
+	attrs := make([]hclwrite.ObjectAttrTokens, rv.Len())
+	for i := range attrs {
+		entry := rv.Index(i)
+		key := entry.FieldByName("Key").String()
+		val := entry.FieldByName("Value").Interface()
+
+		valTy, err := gocty.ImpliedType(val)
+		if err != nil {
+			panic(fmt.Sprintf("cannot encode %T as HCL expression: %s", val, err))
+		}
+		ctyVal, err := gocty.ToCtyValue(val, valTy)
+		if err != nil {
+			panic(fmt.Sprintf("failed to encode %T as %#v: %s", val, valTy, err))
+		}
+
+		attrs[i] = hclwrite.ObjectAttrTokens{
+			Name:  tokensForObjectKey(key),
+			Value: hclwrite.TokensForValue(ctyVal),
+		}
+	}
+	return hclwrite.TokensForObject(attrs)
+}
+
+// tokensForHCLStruct returns tokens for an expression representing the given
+// value, whose type must satisfy isHCLStructTarget, as the object or objects
+// that would decode to it: a struct becomes an object constructor with its
+// arguments, and blocks without labels, in the order of its fields.
+func tokensForHCLStruct(rv reflect.Value) hclwrite.Tokens {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		attrs := make([]hclwrite.ObjectAttrTokens, len(keys))
+		for i, key := range keys {
+			attrs[i] = hclwrite.ObjectAttrTokens{
+				Name:  tokensForObjectKey(key.String()),
+				Value: tokensForHCLStruct(rv.MapIndex(key)),
+			}
+		}
+		return hclwrite.TokensForObject(attrs)
+
+	case reflect.Slice:
+		elems := make([]hclwrite.Tokens, rv.Len())
+		for i := range elems {
+			elems[i] = tokensForHCLStruct(rv.Index(i))
+		}
+		return hclwrite.TokensForTuple(elems)
+	}
+
+	ty := rv.Type()
+	tags := getFieldTags(ty)
+	fieldIdxs := make([]int, 0, len(tags.Attributes)+len(tags.Blocks))
+	names := make(map[int]string, cap(fieldIdxs))
+	for name, i := range tags.Attributes {
+		fieldIdxs = append(fieldIdxs, i)
+		names[i] = name
+	}
+	for name, i := range tags.Blocks {
+		fieldIdxs = append(fieldIdxs, i)
+		names[i] = name
+	}
+	sort.Ints(fieldIdxs)
+
+	var attrs []hclwrite.ObjectAttrTokens
+	for _, i := range fieldIdxs {
+		fieldTy := ty.Field(i).Type
+		fieldVal := rv.Field(i)
+		if fieldTy.Kind() == reflect.Ptr {
+			if fieldVal.IsNil() {
+				continue
+			}
+			fieldTy = fieldTy.Elem()
+			fieldVal = fieldVal.Elem()
+		}
+
+		var value hclwrite.Tokens
+		if _, isBlock := tags.Blocks[names[i]]; isBlock {
+			elemTy := fieldTy
+			if elemTy.Kind() == reflect.Slice {
+				if fieldVal.Len() == 0 {
+					continue
+				}
+				elemTy = elemTy.Elem()
+			}
+			for elemTy.Kind() == reflect.Ptr {
+				elemTy = elemTy.Elem()
+			}
+			if elemTy.Kind() != reflect.Struct || len(getFieldTags(elemTy).Labels) != 0 {
+				continue // can't be written as an object
+			}
+			value = tokensForHCLStruct(fieldVal)
+		} else {
+			switch {
+			case exprType.AssignableTo(fieldTy) || attrType.AssignableTo(fieldTy):
+				continue // ignore undecoded fields
+			case isOrderedMap(fieldTy):
+				value = tokensForOrderedMap(fieldVal)
+			case isHCLStructTarget(fieldTy):
+				value = tokensForHCLStruct(fieldVal)
+			default:
+				valTy, err := gocty.ImpliedType(fieldVal.Interface())
+				if err != nil {
+					panic(fmt.Sprintf("cannot encode %T as HCL expression: %s", fieldVal.Interface(), err))
+				}
+				val, err := gocty.ToCtyValue(fieldVal.Interface(), valTy)
+				if err != nil {
+					panic(fmt.Sprintf("failed to encode %T as %#v: %s", fieldVal.Interface(), valTy, err))
+				}
+				value = hclwrite.TokensForValue(val)
+			}
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  tokensForObjectKey(names[i]),
+			Value: value,
+		})
+	}
+	return hclwrite.TokensForObject(attrs)
+}
+
+// tokensForObjectKey returns tokens for the given object attribute name, as
+// an identifier if possible and otherwise as a quoted string.
+func tokensForObjectKey(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.TokensForIdentifier(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
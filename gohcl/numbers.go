@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var (
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigIntType   = reflect.TypeOf(big.Int{})
+)
+
+// impliedType is like gocty.ImpliedType except that it also accepts the
+// math/big number types, which gocty can populate from a number but does not
+// itself recognize as implying cty.Number.
+func impliedType(val interface{}) (cty.Type, error) {
+	rt := reflect.TypeOf(val)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == bigFloatType || rt == bigIntType {
+		return cty.Number, nil
+	}
+	return gocty.ImpliedType(val)
+}
+
+// checkPreciseNumbers walks the given value alongside the Go type it is about
+// to be assigned to, returning an error for the first number that would lose
+// precision when stored in a float field.
+//
+// The value must already have been converted to the type implied by the
+// target, so that its structure matches the target type.
+func checkPreciseNumbers(val cty.Value, target reflect.Type, path cty.Path) error {
+	val, _ = val.UnmarkDeep()
+	if !val.IsKnown() || val.IsNull() {
+		return nil
+	}
+
+	for target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if val.Type() != cty.Number {
+			return nil
+		}
+		bits := 64
+		if target.Kind() == reflect.Float32 {
+			bits = 32
+		}
+		if !floatIsExact(val.AsBigFloat(), bits) {
+			return path.NewErrorf("value cannot be represented exactly as a %d-bit floating point number", bits)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if !val.CanIterateElements() {
+			return nil
+		}
+		i := 0
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			elemPath := append(path, cty.IndexStep{Key: cty.NumberIntVal(int64(i))})
+			if err := checkPreciseNumbers(v, target.Elem(), elemPath); err != nil {
+				return err
+			}
+			i++
+		}
+
+	case reflect.Map:
+		if !val.CanIterateElements() {
+			return nil
+		}
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			elemPath := append(path, cty.IndexStep{Key: k})
+			if err := checkPreciseNumbers(v, target.Elem(), elemPath); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		if !val.Type().IsObjectType() {
+			return nil
+		}
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Field(i)
+			name := field.Tag.Get("cty")
+			if name == "" || !val.Type().HasAttribute(name) {
+				continue
+			}
+			attrPath := append(path, cty.GetAttrStep{Name: name})
+			if err := checkPreciseNumbers(val.GetAttr(name), field.Type, attrPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// floatIsExact returns true if the shortest decimal form of the float of the
+// given size nearest to f denotes the same number as f itself.
+func floatIsExact(f *big.Float, bits int) bool {
+	fv, _ := f.Float64()
+	rt, _, err := big.ParseFloat(strconv.FormatFloat(fv, 'g', -1, bits), 10, 512, big.ToNearestEven)
+	if err != nil {
+		return false
+	}
+	return rt.Cmp(f) == 0
+}
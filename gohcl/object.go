@@ -0,0 +1,372 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// isHCLStructTarget returns true if the given type, after removing any
+// pointers, is a struct that describes its fields with "hcl" tags rather than
+// the "cty" tags that gocty requires, or a map with string keys or a slice
+// whose elements are such structs.
+//
+// Object values, such as those written with object constructor syntax as in
+// settings = { port = 80 }, are decoded into such structs as if the object
+// were a body, with each object attribute an argument.
+func isHCLStructTarget(ty reflect.Type) bool {
+	for ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	switch ty.Kind() {
+	case reflect.Map:
+		return ty.Key().Kind() == reflect.String && isHCLStructTarget(ty.Elem())
+	case reflect.Slice:
+		return isHCLStructTarget(ty.Elem())
+	case reflect.Struct:
+		if ty == rangeType || ty == posType || isOrderedMap(ty) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	hasHCLTag := false
+	for i := 0; i < ty.NumField(); i++ {
+		field := ty.Field(i)
+		if _, exists := field.Tag.Lookup("cty"); exists {
+			return false
+		}
+		if _, exists := field.Tag.Lookup("hcl"); exists {
+			hasHCLTag = true
+		}
+	}
+	return hasHCLTag
+}
+
+// decodeExpressionToStruct decodes the given expression into the value that
+// the given pointer points to, whose type must satisfy isHCLStructTarget.
+func decodeExpressionToStruct(expr hcl.Expression, ctx *hcl.EvalContext, v reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	target := v.Elem()
+	ty := target.Type()
+	for ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+
+	switch ty.Kind() {
+	case reflect.Map:
+		return decodeExpressionToStructMap(expr, ctx, target, opts)
+	case reflect.Slice:
+		return decodeExpressionToStructSlice(expr, ctx, target, opts)
+	}
+
+	body, diags := newObjectBody(expr, ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+	target = allocTarget(target, body == nil)
+	if target.IsValid() {
+		diags = append(diags, decodeBodyToStruct(body, ctx, target, opts)...)
+	}
+	return diags
+}
+
+// allocTarget follows the pointers from the given value, allocating any that
+// are nil, and returns the value they lead to. If null is true, the first
+// pointer is instead set to nil, or the value to its zero value, and allocTarget
+// returns an invalid value.
+func allocTarget(v reflect.Value, null bool) reflect.Value {
+	if null {
+		v.Set(reflect.Zero(v.Type()))
+		return reflect.Value{}
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func decodeExpressionToStructMap(expr hcl.Expression, ctx *hcl.EvalContext, target reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	body, diags := newObjectBody(expr, ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+	target = allocTarget(target, body == nil)
+	if !target.IsValid() {
+		return diags
+	}
+
+	m := reflect.MakeMapWithSize(target.Type(), len(body.attrs))
+	for name, attr := range body.attrs {
+		elem := reflect.New(target.Type().Elem())
+		diags = append(diags, decodeExpressionToStruct(attr.Expr, ctx, elem, opts)...)
+		m.SetMapIndex(reflect.ValueOf(name).Convert(target.Type().Key()), elem.Elem())
+	}
+	target.Set(m)
+	return diags
+}
+
+func decodeExpressionToStructSlice(expr hcl.Expression, ctx *hcl.EvalContext, target reflect.Value, opts *decodeOpts) hcl.Diagnostics {
+	exprs, diags := hcl.ExprList(expr)
+	if diags.HasErrors() {
+		// For any other expression we can only work with the resulting value.
+		val, valDiags := expr.Value(ctx)
+		if valDiags.HasErrors() {
+			return valDiags
+		}
+		val, _ = val.Unmark()
+		ty := val.Type()
+		switch {
+		case !ty.IsListType() && !ty.IsTupleType() && !ty.IsSetType():
+			return append(valDiags, unsuitableObjectDiag(expr, "a list is required"))
+		case val.IsNull():
+			allocTarget(target, true)
+			return valDiags
+		case !val.IsKnown():
+			return append(valDiags, unsuitableObjectDiag(expr, "value must be known"))
+		}
+		diags = valDiags
+		exprs = nil
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			exprs = append(exprs, hcl.StaticExpr(v, expr.Range()))
+		}
+	}
+
+	target = allocTarget(target, false)
+	s := reflect.MakeSlice(target.Type(), len(exprs), len(exprs))
+	for i, elemExpr := range exprs {
+		diags = append(diags, decodeExpressionToStruct(elemExpr, ctx, s.Index(i).Addr(), opts)...)
+	}
+	target.Set(s)
+	return diags
+}
+
+// objectBody is an hcl.Body whose arguments are the attributes of an
+// object value, used for decoding objects into structs with "hcl" tags.
+//
+// An object-typed argument whose name is the type of a block with no labels
+// in the schema is also treated as a block with that type, and a tuple or
+// list of objects as a sequence of such blocks, in the same way as for the
+// JSON syntax.
+type objectBody struct {
+	attrs    hcl.Attributes
+	ctx      *hcl.EvalContext
+	srcRange hcl.Range
+}
+
+// newObjectBody returns a body representing the object value of the given
+// expression, or nil if the value is null.
+func newObjectBody(expr hcl.Expression, ctx *hcl.EvalContext) (*objectBody, hcl.Diagnostics) {
+	body := &objectBody{
+		attrs:    hcl.Attributes{},
+		ctx:      ctx,
+		srcRange: expr.Range(),
+	}
+
+	if pairs, pairDiags := hcl.ExprMap(expr); !pairDiags.HasErrors() {
+		var diags hcl.Diagnostics
+		for _, pair := range pairs {
+			key, keyDiags := pair.Key.Value(ctx)
+			diags = append(diags, keyDiags...)
+			if keyDiags.HasErrors() {
+				continue
+			}
+			key, err := convert.Convert(key, cty.String)
+			if err != nil || key.IsNull() || !key.IsKnown() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity:    hcl.DiagError,
+					Summary:     "Invalid object key",
+					Detail:      "An object key must be a known, non-null string.",
+					Subject:     pair.Key.Range().Ptr(),
+					Expression:  pair.Key,
+					EvalContext: ctx,
+				})
+				continue
+			}
+			key, _ = key.Unmark()
+			name := key.AsString()
+			body.attrs[name] = &hcl.Attribute{
+				Name:      name,
+				Expr:      pair.Value,
+				Range:     hcl.RangeBetween(pair.Key.Range(), pair.Value.Range()),
+				NameRange: pair.Key.Range(),
+			}
+		}
+		return body, diags
+	}
+
+	// For any other expression we can only work with the resulting value.
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	val, _ = val.Unmark()
+	ty := val.Type()
+	switch {
+	case !ty.IsObjectType() && !ty.IsMapType() && ty != cty.DynamicPseudoType:
+		return nil, append(diags, unsuitableObjectDiag(expr, "an object is required"))
+	case val.IsNull():
+		return nil, diags
+	case !val.IsKnown():
+		return nil, append(diags, unsuitableObjectDiag(expr, "value must be known"))
+	}
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		name := k.AsString()
+		body.attrs[name] = &hcl.Attribute{
+			Name:      name,
+			Expr:      hcl.StaticExpr(v, expr.Range()),
+			Range:     expr.Range(),
+			NameRange: expr.Range(),
+		}
+	}
+	return body, diags
+}
+
+func unsuitableObjectDiag(expr hcl.Expression, msg string) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsuitable value type",
+		Detail:   fmt.Sprintf("Unsuitable value: %s", msg),
+		Subject:  expr.StartRange().Ptr(),
+		Context:  expr.Range().Ptr(),
+	}
+}
+
+func (b *objectBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, remain, diags := b.PartialContent(schema)
+
+	var names []string
+	for name := range remain.(*objectBody).attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attr := remain.(*objectBody).attrs[name]
+		suggestions := make([]string, 0, len(schema.Attributes)+len(schema.Blocks))
+		for _, attrS := range schema.Attributes {
+			suggestions = append(suggestions, attrS.Name)
+		}
+		for _, blockS := range schema.Blocks {
+			suggestions = append(suggestions, blockS.Type)
+		}
+		var suggestion string
+		suggested := nameSuggestion(name, suggestions)
+		if suggested != "" {
+			suggestion = fmt.Sprintf(" Did you mean %q?", suggested)
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported argument",
+			Detail:   fmt.Sprintf("An argument named %q is not expected here.%s", name, suggestion),
+			Subject:  attr.NameRange.Ptr(),
+			Extra: &hcl.UnknownAttributeError{
+				Name:       name,
+				Suggestion: suggested,
+				Range:      attr.NameRange,
+			},
+		})
+	}
+	return content, diags
+}
+
+func (b *objectBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	content := &hcl.BodyContent{
+		Attributes:       hcl.Attributes{},
+		MissingItemRange: b.MissingItemRange(),
+	}
+	remain := &objectBody{
+		attrs:    hcl.Attributes{},
+		ctx:      b.ctx,
+		srcRange: b.srcRange,
+	}
+	used := map[string]bool{}
+
+	for _, attrS := range schema.Attributes {
+		attr, exists := b.attrs[attrS.Name]
+		if !exists {
+			if attrS.Required {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing required argument",
+					Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
+					Subject:  b.MissingItemRange().Ptr(),
+				})
+			}
+			continue
+		}
+		content.Attributes[attrS.Name] = attr
+		used[attrS.Name] = true
+	}
+
+	for _, blockS := range schema.Blocks {
+		attr, exists := b.attrs[blockS.Type]
+		if !exists || len(blockS.LabelNames) != 0 {
+			continue
+		}
+		used[blockS.Type] = true
+		blocks, moreDiags := objectBlocks(blockS.Type, attr, b.ctx)
+		diags = append(diags, moreDiags...)
+		content.Blocks = append(content.Blocks, blocks...)
+	}
+
+	for name, attr := range b.attrs {
+		if !used[name] {
+			remain.attrs[name] = attr
+		}
+	}
+	return content, remain, diags
+}
+
+// objectBlocks returns the blocks represented by an argument whose name is
+// a block type: one for an object, or one for each element of a tuple or
+// list of objects.
+func objectBlocks(typeName string, attr *hcl.Attribute, ctx *hcl.EvalContext) (hcl.Blocks, hcl.Diagnostics) {
+	var exprs []hcl.Expression
+	if elems, diags := hcl.ExprList(attr.Expr); !diags.HasErrors() {
+		exprs = elems
+	} else {
+		exprs = []hcl.Expression{attr.Expr}
+	}
+
+	var blocks hcl.Blocks
+	var diags hcl.Diagnostics
+	for _, expr := range exprs {
+		body, moreDiags := newObjectBody(expr, ctx)
+		diags = append(diags, moreDiags...)
+		if body == nil {
+			continue
+		}
+		blocks = append(blocks, &hcl.Block{
+			Type:      typeName,
+			Body:      body,
+			DefRange:  expr.StartRange(),
+			TypeRange: attr.NameRange,
+		})
+	}
+	return blocks, diags
+}
+
+func (b *objectBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	ret := make(hcl.Attributes, len(b.attrs))
+	for name, attr := range b.attrs {
+		ret[name] = attr
+	}
+	return ret, nil
+}
+
+func (b *objectBody) MissingItemRange() hcl.Range {
+	return b.srcRange
+}
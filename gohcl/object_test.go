@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeObjectToStruct(t *testing.T) {
+	type Listener struct {
+		Port     int    `hcl:"port"`
+		Protocol string `hcl:"protocol,optional"`
+	}
+	type Limits struct {
+		CPU    int `hcl:"cpu"`
+		Memory int `hcl:"memory,optional"`
+	}
+	type Settings struct {
+		Name      string            `hcl:"name"`
+		Tags      map[string]string `hcl:"tags,optional"`
+		Limits    *Limits           `hcl:"limits,optional"`
+		Listeners []Listener        `hcl:"listener,block"`
+		Extra     *Limits           `hcl:"extra,optional"`
+	}
+	type Config struct {
+		Settings Settings          `hcl:"settings"`
+		Inline   Listener          `hcl:"inline"`
+		ByRef    *Listener         `hcl:"by_ref"`
+		ByName   map[string]Limits `hcl:"by_name,optional"`
+	}
+
+	src := `
+settings = {
+  name = "web",
+  tags = { env = "prod", "team" = "platform", },
+  limits = { cpu = 2, memory = 512 },
+  listener = [
+    { port = 80 },
+    { port = 443, protocol = "https" },
+  ],
+  extra = null
+}
+inline = { port = 8080, protocol = "http", }
+by_ref = var.listener
+by_name = {
+  small = { cpu = 1, memory = 0 }
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"listener": cty.ObjectVal(map[string]cty.Value{
+					"port": cty.NumberIntVal(22),
+				}),
+			}),
+		},
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, ctx, &got)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	want := Config{
+		Settings: Settings{
+			Name:   "web",
+			Tags:   map[string]string{"env": "prod", "team": "platform"},
+			Limits: &Limits{CPU: 2, Memory: 512},
+			Listeners: []Listener{
+				{Port: 80},
+				{Port: 443, Protocol: "https"},
+			},
+		},
+		Inline: Listener{Port: 8080, Protocol: "http"},
+		ByRef:  &Listener{Port: 22},
+		ByName: map[string]Limits{"small": {CPU: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDecodeObjectToStructErrors(t *testing.T) {
+	type Listener struct {
+		Port     int    `hcl:"port"`
+		Protocol string `hcl:"protocol,optional"`
+	}
+
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"missing": {
+			`v = { protocol = "http" }`,
+			`test.hcl:1,5-26: Missing required argument; The argument "port" is required, but no definition was found.`,
+		},
+		"unsupported": {
+			`v = { port = 80, protocl = "http" }`,
+			`test.hcl:1,18-25: Unsupported argument; An argument named "protocl" is not expected here. Did you mean "protocol"?`,
+		},
+		"wrong type": {
+			`v = { port = "eighty" }`,
+			`test.hcl:1,15-21: Unsuitable value type; Unsuitable value: a number is required`,
+		},
+		"not an object": {
+			`v = [80]`,
+			`test.hcl:1,5-6: Unsuitable value type; Unsuitable value: an object is required`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			var got struct {
+				V Listener `hcl:"v"`
+			}
+			diags = DecodeBody(file.Body, nil, &got)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Error(); got != test.want {
+				t.Errorf("wrong diagnostic\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeListOfObjects(t *testing.T) {
+	type Rule struct {
+		Port     int      `hcl:"port"`
+		Protocol *string  `hcl:"protocol,optional"`
+		Sources  []string `hcl:"sources,optional"`
+	}
+	type Config struct {
+		Rules []Rule           `hcl:"rule"`
+		Maps  []map[string]int `hcl:"maps"`
+		Named map[string]Rule  `hcl:"named,optional"`
+	}
+
+	https := "https"
+	in := Config{
+		Rules: []Rule{
+			{Port: 80},
+			{Port: 443, Protocol: &https, Sources: []string{"10.0.0.0/8"}},
+		},
+		Maps: []map[string]int{{"a": 1}, {"b": 2}},
+		Named: map[string]Rule{
+			"ssh": {Port: 22},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	EncodeIntoBody(&in, f.Body())
+	got := string(f.Bytes())
+	want := `rule = [
+  {
+    port    = 80
+    sources = null
+  },
+  {
+    port     = 443
+    protocol = "https"
+    sources  = ["10.0.0.0/8"]
+  },
+]
+maps = [
+  {
+    a = 1
+  },
+  {
+    b = 2
+  },
+]
+named = {
+  ssh = {
+    port    = 22
+    sources = null
+  }
+}
+`
+	if got != want {
+		t.Errorf("wrong encoded result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	file, diags := hclsyntax.ParseConfig(f.Bytes(), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	var out Config
+	diags = DecodeBody(file.Body, nil, &out)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("wrong decoded result\ngot:  %#v\nwant: %#v", out, in)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+// DecodeOption is implemented by values that can be passed to DecodeBody and
+// DecodeExpression to customize how decoding is performed.
+type DecodeOption interface {
+	applyDecodeOption(*decodeOpts)
+}
+
+// decodeOpts is the settled form of a set of DecodeOption values, passed
+// down through the recursive decoding functions.
+type decodeOpts struct {
+	strict         bool
+	strictTypes    bool
+	preciseNumbers bool
+}
+
+func newDecodeOpts(opts []DecodeOption) *decodeOpts {
+	ret := &decodeOpts{}
+	for _, opt := range opts {
+		opt.applyDecodeOption(ret)
+	}
+	return ret
+}
+
+type optStrict struct{}
+
+// OptStrict enables strict decoding, where object attributes that have no
+// corresponding field in the target type are reported as errors instead of
+// being silently discarded.
+//
+// Arguments and nested blocks in a body are always checked against the
+// target struct, unless it has a "remain" field, but object values assigned
+// to attributes are normally converted to the target type using the usual
+// cty conversion rules, which quietly drop any attributes the target type
+// doesn't declare. Strict decoding catches typos such as "descripton" in
+// those nested objects, reporting each unexpected attribute along with the
+// position of its key in the source.
+func OptStrict() DecodeOption {
+	return optStrict{}
+}
+
+// applyDecodeOption implements DecodeOption.
+func (o optStrict) applyDecodeOption(opts *decodeOpts) {
+	opts.strict = true
+}
+
+type optStrictTypes struct{}
+
+// OptStrictTypes enables strict type checking, where a string, number or
+// bool value is accepted only for a field of the corresponding type.
+//
+// Decoding normally applies the same weak typing as the rest of HCL, so
+// that "5" decodes into an int field, true into a string field as "true",
+// and "false" into a bool field. Configuration formats that want to catch
+// such mismatches early can use this option to report each primitive value
+// of the wrong type as an error, including those within collections and
+// nested objects. Values of any type are still accepted by fields whose
+// type is cty.Value, and null values are accepted as usual.
+func OptStrictTypes() DecodeOption {
+	return optStrictTypes{}
+}
+
+// applyDecodeOption implements DecodeOption.
+func (o optStrictTypes) applyDecodeOption(opts *decodeOpts) {
+	opts.strictTypes = true
+}
+
+type optPreciseNumbers struct{}
+
+// OptPreciseNumbers enables precise number decoding, where a number that
+// cannot be represented by a float32 or float64 target field without losing
+// precision is reported as an error instead of being silently rounded.
+//
+// HCL numbers are arbitrary-precision, so values such as 64-bit identifiers
+// or currency amounts survive decoding unchanged into fields of type
+// big.Float, big.Int, string or json.Number, or into integer fields of
+// sufficient size. A float field, on the other hand, normally receives the
+// nearest representable value, so 9007199254740993 would quietly become
+// 9007199254740992. Precise decoding instead accepts a number for a float
+// field only if formatting the resulting float produces the same number
+// again, which allows values like 0.1 that have a shortest round-trip form
+// while rejecting those that would change.
+func OptPreciseNumbers() DecodeOption {
+	return optPreciseNumbers{}
+}
+
+// applyDecodeOption implements DecodeOption.
+func (o optPreciseNumbers) applyDecodeOption(opts *decodeOpts) {
+	opts.preciseNumbers = true
+}
@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// OrderedMap is a decoding target that, unlike a Go map, retains the order
+// in which keys were written in the configuration. It can be used wherever
+// a map is accepted: as the target of DecodeBody or DecodeExpression, or as
+// the type of an "attr" or "remain" field.
+//
+// When decoding an object constructor expression, or a body, the entries
+// are in the order they appear in the source. When decoding any other
+// expression that produces an object or map, such as a reference to a
+// variable, the order of the source isn't available and so the entries are
+// in lexical order of their keys, as with cty.
+//
+// If the same key is given more than once in an object constructor then
+// the last value is kept, as for a map, but at the position of the first.
+//
+// EncodeIntoBody writes an OrderedMap field as an object constructor with
+// its entries in order, so that tools which decode and re-encode a
+// configuration don't reorder them.
+type OrderedMap[V any] []MapEntry[V]
+
+// MapEntry is a single entry in an OrderedMap.
+type MapEntry[V any] struct {
+	Key   string
+	Value V
+
+	// KeyRange is the source range of the key, or of the whole expression
+	// for entries that were not written individually.
+	KeyRange hcl.Range
+}
+
+// Get returns the value for the given key, and false if there is no entry
+// for that key.
+func (m OrderedMap[V]) Get(key string) (V, bool) {
+	for _, entry := range m {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Keys returns the keys of the map, in order.
+func (m OrderedMap[V]) Keys() []string {
+	ret := make([]string, len(m))
+	for i, entry := range m {
+		ret[i] = entry.Key
+	}
+	return ret
+}
+
+// Map returns a Go map containing the same entries.
+func (m OrderedMap[V]) Map() map[string]V {
+	ret := make(map[string]V, len(m))
+	for _, entry := range m {
+		ret[entry.Key] = entry.Value
+	}
+	return ret
+}
+
+// orderedMapTarget is implemented by pointers to all instances of
+// OrderedMap, so that the reflection-based decoder can populate them
+// without knowing their value type.
+type orderedMapTarget interface {
+	resetOrderedMap()
+	newOrderedMapValue() interface{}
+	setOrderedMapEntry(key string, rng hcl.Range, val interface{})
+}
+
+var orderedMapTargetType = reflect.TypeOf((*orderedMapTarget)(nil)).Elem()
+
+func (m *OrderedMap[V]) resetOrderedMap() {
+	*m = nil
+}
+
+func (m *OrderedMap[V]) newOrderedMapValue() interface{} {
+	return new(V)
+}
+
+func (m *OrderedMap[V]) setOrderedMapEntry(key string, rng hcl.Range, val interface{}) {
+	v := *val.(*V)
+	for i := range *m {
+		if (*m)[i].Key == key {
+			(*m)[i].Value = v
+			return
+		}
+	}
+	*m = append(*m, MapEntry[V]{Key: key, Value: v, KeyRange: rng})
+}
+
+// isOrderedMap returns true if the given type is an instance of OrderedMap.
+func isOrderedMap(ty reflect.Type) bool {
+	return reflect.PtrTo(ty).Implements(orderedMapTargetType)
+}
+
+func decodeBodyToOrderedMap(body hcl.Body, ctx *hcl.EvalContext, target orderedMapTarget, opts *decodeOpts) hcl.Diagnostics {
+	attrs, diags := body.JustAttributes()
+	if attrs == nil {
+		return diags
+	}
+
+	sorted := make([]*hcl.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		sorted = append(sorted, attr)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ri, rj := sorted[i].Range, sorted[j].Range
+		if ri.Filename != rj.Filename {
+			return ri.Filename < rj.Filename
+		}
+		return ri.Start.Byte < rj.Start.Byte
+	})
+
+	target.resetOrderedMap()
+	for _, attr := range sorted {
+		ptr := target.newOrderedMapValue()
+		switch ptr := ptr.(type) {
+		case **hcl.Attribute:
+			*ptr = attr
+		case *hcl.Expression:
+			*ptr = attr.Expr
+		default:
+			diags = append(diags, decodeExpression(attr.Expr, ctx, ptr, opts)...)
+		}
+		target.setOrderedMapEntry(attr.Name, attr.NameRange, ptr)
+	}
+	return diags
+}
+
+func decodeExpressionToOrderedMap(expr hcl.Expression, ctx *hcl.EvalContext, target orderedMapTarget, opts *decodeOpts) hcl.Diagnostics {
+	target.resetOrderedMap()
+
+	if pairs, pairDiags := hcl.ExprMap(expr); !pairDiags.HasErrors() {
+		var diags hcl.Diagnostics
+		for _, pair := range pairs {
+			key, keyDiags := pair.Key.Value(ctx)
+			diags = append(diags, keyDiags...)
+			if keyDiags.HasErrors() {
+				continue
+			}
+			key, err := convert.Convert(key, cty.String)
+			if err != nil || key.IsNull() || !key.IsKnown() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity:    hcl.DiagError,
+					Summary:     "Invalid map key",
+					Detail:      "A map key must be a known, non-null string.",
+					Subject:     pair.Key.Range().Ptr(),
+					Expression:  pair.Key,
+					EvalContext: ctx,
+				})
+				continue
+			}
+			key, _ = key.Unmark()
+			diags = append(diags, decodeOrderedMapEntry(key.AsString(), pair.Key.Range(), pair.Value, ctx, target, opts)...)
+		}
+		return diags
+	}
+
+	// For any other expression we can only work with the resulting value.
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+	val, _ = val.Unmark()
+	ty := val.Type()
+	switch {
+	case !ty.IsObjectType() && !ty.IsMapType():
+		return append(diags, unsuitableOrderedMapDiag(expr, "an object or map is required"))
+	case val.IsNull():
+		return diags
+	case !val.IsKnown():
+		return append(diags, unsuitableOrderedMapDiag(expr, "value must be known"))
+	}
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		elemExpr := hcl.StaticExpr(v, expr.Range())
+		diags = append(diags, decodeOrderedMapEntry(k.AsString(), expr.Range(), elemExpr, ctx, target, opts)...)
+	}
+	return diags
+}
+
+func decodeOrderedMapEntry(key string, rng hcl.Range, expr hcl.Expression, ctx *hcl.EvalContext, target orderedMapTarget, opts *decodeOpts) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	ptr := target.newOrderedMapValue()
+	if exprPtr, ok := ptr.(*hcl.Expression); ok {
+		*exprPtr = expr
+	} else {
+		diags = decodeExpression(expr, ctx, ptr, opts)
+	}
+	target.setOrderedMapEntry(key, rng, ptr)
+	return diags
+}
+
+func unsuitableOrderedMapDiag(expr hcl.Expression, msg string) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsuitable value type",
+		Detail:   fmt.Sprintf("Unsuitable value: %s", msg),
+		Subject:  expr.StartRange().Ptr(),
+		Context:  expr.Range().Ptr(),
+	}
+}
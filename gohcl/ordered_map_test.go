@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeOrderedMap(t *testing.T) {
+	type Config struct {
+		Name   string             `hcl:"name"`
+		Tags   OrderedMap[string] `hcl:"tags,optional"`
+		Limits OrderedMap[int]    `hcl:"limits,optional"`
+		Other  OrderedMap[string] `hcl:",remain"`
+	}
+
+	src := `
+name = "a"
+tags = {
+  zebra     = "z"
+  "apple"   = "a"
+  (var.key) = "m"
+  apple     = "b"
+}
+limits = var.limits
+yak = "y"
+bat = "b"
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"key": cty.StringVal("mango"),
+				"limits": cty.ObjectVal(map[string]cty.Value{
+					"mem": cty.NumberIntVal(2),
+					"cpu": cty.NumberIntVal(1),
+				}),
+			}),
+		},
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, ctx, &got)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if want := []string{"zebra", "apple", "mango"}; !reflect.DeepEqual(got.Tags.Keys(), want) {
+		t.Errorf("wrong tags keys %#v; want %#v", got.Tags.Keys(), want)
+	}
+	if v, _ := got.Tags.Get("apple"); v != "b" {
+		t.Errorf("wrong value %q for repeated key; want %q", v, "b")
+	}
+	if got, want := got.Tags[0].KeyRange.Start.Line, 4; got != want {
+		t.Errorf("wrong key line %d; want %d", got, want)
+	}
+	if want := []string{"cpu", "mem"}; !reflect.DeepEqual(got.Limits.Keys(), want) {
+		t.Errorf("wrong limits keys %#v; want %#v", got.Limits.Keys(), want)
+	}
+	if want := map[string]int{"cpu": 1, "mem": 2}; !reflect.DeepEqual(got.Limits.Map(), want) {
+		t.Errorf("wrong limits %#v; want %#v", got.Limits.Map(), want)
+	}
+	if want := []string{"yak", "bat"}; !reflect.DeepEqual(got.Other.Keys(), want) {
+		t.Errorf("wrong remaining keys %#v; want %#v", got.Other.Keys(), want)
+	}
+	if _, ok := got.Tags.Get("missing"); ok {
+		t.Errorf("Get succeeded for missing key")
+	}
+}
+
+func TestDecodeOrderedMapBody(t *testing.T) {
+	file, diags := json.Parse([]byte(`{"zebra": 1, "apple": 2, "mango": 3}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got OrderedMap[hcl.Expression]
+	diags = DecodeBody(file.Body, nil, &got)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if want := []string{"zebra", "apple", "mango"}; !reflect.DeepEqual(got.Keys(), want) {
+		t.Errorf("wrong keys %#v; want %#v", got.Keys(), want)
+	}
+	if v, _ := got[1].Value.Value(nil); !v.RawEquals(cty.NumberIntVal(2)) {
+		t.Errorf("wrong value %#v", v)
+	}
+}
+
+func TestDecodeOrderedMapErrors(t *testing.T) {
+	tests := map[string]string{
+		"not an object": `[1, 2]`,
+		"wrong type":    `{ a = "not a number" }`,
+		"null key":      `{ (null) = 1 }`,
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			var got OrderedMap[int]
+			diags = DecodeExpression(expr, nil, &got)
+			if !diags.HasErrors() {
+				t.Errorf("unexpected success: %#v", got)
+			}
+		})
+	}
+}
+
+func TestEncodeOrderedMap(t *testing.T) {
+	type Config struct {
+		Tags OrderedMap[string] `hcl:"tags"`
+	}
+
+	val := Config{
+		Tags: OrderedMap[string]{
+			{Key: "zebra", Value: "z"},
+			{Key: "apple pie", Value: "a"},
+		},
+	}
+	f := hclwrite.NewEmptyFile()
+	EncodeIntoBody(&val, f.Body())
+
+	got := string(f.Bytes())
+	want := `tags = {
+  zebra       = "z"
+  "apple pie" = "a"
+}
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Decoding the result must give the same entries in the same order.
+	file, diags := hclsyntax.ParseConfig(f.Bytes(), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	var decoded Config
+	diags = DecodeBody(file.Body, nil, &decoded)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if !reflect.DeepEqual(decoded.Tags.Keys(), val.Tags.Keys()) {
+		t.Errorf("wrong keys after round trip %#v", decoded.Tags.Keys())
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
 )
 
 // ImpliedBodySchema produces a hcl.BodySchema derived from the type of the
@@ -51,6 +52,8 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 		var required bool
 
 		switch {
+		case tags.Required[n]:
+			required = true
 		case field.Type.AssignableTo(exprType):
 			// If we're decoding to hcl.Expression then absense can be
 			// indicated via a null value, so we don't specify that
@@ -118,10 +121,15 @@ type fieldTags struct {
 	Remain     *int
 	Body       *int
 	Optional   map[string]bool
+	Required   map[string]bool
+	Defaults   map[string]string
+
+	Constraints map[string]*constraint.Constraints
 
 	AttributeRange      map[string]int
 	AttributeNameRange  map[string]int
 	AttributeValueRange map[string]int
+	AttributePresent    map[string]int
 
 	DefRange   *int
 	TypeRange  *int
@@ -139,9 +147,13 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 		Attributes:          map[string]int{},
 		Blocks:              map[string]int{},
 		Optional:            map[string]bool{},
+		Required:            map[string]bool{},
+		Defaults:            map[string]string{},
+		Constraints:         map[string]*constraint.Constraints{},
 		AttributeRange:      map[string]int{},
 		AttributeNameRange:  map[string]int{},
 		AttributeValueRange: map[string]int{},
+		AttributePresent:    map[string]int{},
 		LabelRange:          map[string]int{},
 	}
 
@@ -163,9 +175,27 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 			kind = "attr"
 		}
 
+		if spec := field.Tag.Get("validate"); spec != "" {
+			c, err := constraint.Parse(spec)
+			if err != nil {
+				panic(fmt.Sprintf("invalid validate tag on %s %q: %s", field.Type.String(), field.Name, err))
+			}
+			ret.Constraints[name] = c
+		}
+
+		if strings.HasPrefix(kind, "default=") {
+			ret.Attributes[name] = i
+			ret.Optional[name] = true
+			ret.Defaults[name] = kind[len("default="):]
+			continue
+		}
+
 		switch kind {
 		case "attr":
 			ret.Attributes[name] = i
+		case "required":
+			ret.Attributes[name] = i
+			ret.Required[name] = true
 		case "block":
 			ret.Blocks[name] = i
 		case "label":
@@ -208,6 +238,8 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 			ret.AttributeNameRange[name] = i
 		case "attr_value_range":
 			ret.AttributeValueRange[name] = i
+		case "attr_present":
+			ret.AttributePresent[name] = i
 		default:
 			panic(fmt.Sprintf("invalid hcl field tag kind %q on %s %q", kind, field.Type.String(), field.Name))
 		}
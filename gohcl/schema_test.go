@@ -63,6 +63,25 @@ func TestImpliedBodySchema(t *testing.T) {
 			},
 			false,
 		},
+		{
+			struct {
+				Attr *bool          `hcl:"attr,required"`
+				Expr hcl.Expression `hcl:"expr,required"`
+			}{},
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{
+					{
+						Name:     "attr",
+						Required: true,
+					},
+					{
+						Name:     "expr",
+						Required: true,
+					},
+				},
+			},
+			false,
+		},
 		{
 			struct {
 				Thing struct{} `hcl:"thing,block"`
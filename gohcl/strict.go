@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gohcl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// checkStrict walks the given value alongside the type it is about to be
+// converted to, returning an error diagnostic for each object attribute that
+// the conversion would otherwise silently discard, if the options call for
+// strict decoding, and for each primitive value that the conversion would
+// otherwise coerce to a different primitive type, if they call for strict
+// types.
+//
+// The given expression is the one the value was produced from. Where
+// possible, the diagnostics refer to the key of the offending attribute
+// within an object constructor expression, falling back to the range of
+// the whole expression when the value was produced some other way.
+func checkStrict(val cty.Value, ty cty.Type, expr hcl.Expression, opts *decodeOpts) hcl.Diagnostics {
+	val, _ = val.UnmarkDeep()
+	if !val.IsKnown() || val.IsNull() {
+		return nil
+	}
+
+	var diags hcl.Diagnostics
+	vty := val.Type()
+	switch {
+	case ty.IsPrimitiveType():
+		if opts.strictTypes && vty.IsPrimitiveType() && !vty.Equals(ty) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect value type",
+				Detail:   fmt.Sprintf("A %s is required here, but the given value is a %s.", ty.FriendlyName(), vty.FriendlyName()),
+				Subject:  expr.Range().Ptr(),
+				Extra: &hcl.TypeMismatchError{
+					Want:  ty,
+					Got:   vty,
+					Range: expr.Range(),
+				},
+			})
+		}
+
+	case ty.IsObjectType():
+		if !vty.IsObjectType() && !vty.IsMapType() {
+			// Conversion will report a more appropriate error for this.
+			return nil
+		}
+
+		var names []string
+		for name := range ty.AttributeTypes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		pairs := exprMapByKey(expr)
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			name := k.AsString()
+			pair, hasPair := pairs[name]
+
+			if !ty.HasAttribute(name) {
+				if !opts.strict {
+					continue
+				}
+				subject := expr.Range()
+				if hasPair {
+					subject = pair.Key.Range()
+				}
+				suggestion := ""
+				suggested := nameSuggestion(name, names)
+				if suggested != "" {
+					suggestion = fmt.Sprintf(" Did you mean %q?", suggested)
+				}
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unsupported attribute",
+					Detail:   fmt.Sprintf("An attribute named %q is not expected here.%s", name, suggestion),
+					Subject:  &subject,
+					Context:  expr.Range().Ptr(),
+					Extra: &hcl.UnknownAttributeError{
+						Name:       name,
+						Suggestion: suggested,
+						Range:      subject,
+					},
+				})
+				continue
+			}
+
+			elemExpr := expr
+			if hasPair {
+				elemExpr = pair.Value
+			}
+			diags = append(diags, checkStrict(v, ty.AttributeType(name), elemExpr, opts)...)
+		}
+
+	case ty.IsMapType():
+		if !vty.IsObjectType() && !vty.IsMapType() {
+			return nil
+		}
+
+		pairs := exprMapByKey(expr)
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			elemExpr := expr
+			if pair, ok := pairs[k.AsString()]; ok {
+				elemExpr = pair.Value
+			}
+			diags = append(diags, checkStrict(v, ty.ElementType(), elemExpr, opts)...)
+		}
+
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		if !vty.IsListType() && !vty.IsSetType() && !vty.IsTupleType() {
+			return nil
+		}
+
+		// Set elements have no meaningful correlation with the expressions
+		// that produced them, so we can only use element expressions for
+		// sequence types.
+		var elemExprs []hcl.Expression
+		if !vty.IsSetType() {
+			if exprs, listDiags := hcl.ExprList(expr); !listDiags.HasErrors() && len(exprs) == val.LengthInt() {
+				elemExprs = exprs
+			}
+		}
+
+		i := 0
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			elemExpr := expr
+			if elemExprs != nil {
+				elemExpr = elemExprs[i]
+			}
+
+			var elemTy cty.Type
+			switch {
+			case ty.IsTupleType():
+				if i >= len(ty.TupleElementTypes()) {
+					return diags // conversion will report the length mismatch
+				}
+				elemTy = ty.TupleElementType(i)
+			default:
+				elemTy = ty.ElementType()
+			}
+			diags = append(diags, checkStrict(v, elemTy, elemExpr, opts)...)
+			i++
+		}
+	}
+
+	return diags
+}
+
+// exprMapByKey returns the items of the given expression indexed by their
+// static key, if the expression is a syntax-level mapping construct such as
+// an object constructor. Returns nil if the expression is not a mapping
+// construct, and omits any items whose keys cannot be determined statically.
+func exprMapByKey(expr hcl.Expression) map[string]hcl.KeyValuePair {
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	ret := make(map[string]hcl.KeyValuePair, len(pairs))
+	for _, pair := range pairs {
+		key, keyDiags := pair.Key.Value(nil)
+		if keyDiags.HasErrors() || !key.IsWhollyKnown() || key.IsNull() {
+			continue
+		}
+		key, err := convert.Convert(key, cty.String)
+		if err != nil {
+			continue
+		}
+		ret[key.AsString()] = pair
+	}
+	return ret
+}
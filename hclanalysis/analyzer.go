@@ -0,0 +1,214 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclanalysis
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+// Analyzer describes a check that can be made on the configuration files in
+// a directory.
+type Analyzer struct {
+	// Name identifies the analyzer in diagnostics and on the command line.
+	// It must be unique among the analyzers that are run together, and
+	// among all registered analyzers.
+	Name string
+
+	// Doc is the documentation for the analyzer. Its first sentence should
+	// summarize what the analyzer checks, for display to users.
+	Doc string
+
+	// Requires is the set of analyzers that must run before this one,
+	// whose results are available to this one's Run function through
+	// Pass.ResultOf.
+	Requires []*Analyzer
+
+	// RunDespiteErrors allows the analyzer to run on directories that
+	// contain files with syntax errors. By default an analyzer runs only if
+	// all of the files parsed without errors, which spares most analyzers
+	// from having to deal with incomplete syntax trees.
+	RunDespiteErrors bool
+
+	// Run applies the analyzer to a directory of files. It reports problems
+	// through the given pass and may return a result for use by the
+	// analyzers that require this one.
+	//
+	// Run returns an error only if the analysis itself fails, not to report
+	// problems in the configuration. When an analyzer fails, the analyzers
+	// that require it are not run for the same directory.
+	Run func(pass *Pass) (interface{}, error)
+}
+
+func (a *Analyzer) String() string {
+	return a.Name
+}
+
+// Pass provides information to an analyzer's Run function about the
+// directory of files being analyzed, and a means to report problems in them.
+type Pass struct {
+	// Analyzer is the analyzer being run.
+	Analyzer *Analyzer
+
+	// Dir is the directory containing the files being analyzed.
+	Dir string
+
+	// Files are the files being analyzed, in lexical order of their names.
+	// Files that were parsed from native syntax have bodies of type
+	// *hclsyntax.Body, which analyzers can use to inspect the details of
+	// the syntax.
+	Files []*hcl.File
+
+	// Filenames are the names of the files in Files, in the same order.
+	Filenames []string
+
+	// Schema is the structure that the application expects the files to
+	// conform to, or nil if the driver was not given one.
+	Schema *hclschema.Body
+
+	// ResultOf maps each of the analyzers in the analyzer's Requires to the
+	// result of running it on the same directory.
+	ResultOf map[*Analyzer]interface{}
+
+	// Report reports a problem found by the analyzer. The driver records
+	// the name of the analyzer in the diagnostic, for retrieval with
+	// AnalyzerName, replacing any existing value of its Extra field.
+	Report func(diag *hcl.Diagnostic)
+}
+
+// Reportf reports a problem with the given summary at the given range as a
+// warning, with a detail message built from the given format and arguments
+// in the manner of fmt.Sprintf.
+func (p *Pass) Reportf(rng hcl.Range, summary string, format string, args ...interface{}) {
+	p.Report(&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  summary,
+		Detail:   fmt.Sprintf(format, args...),
+		Subject:  rng.Ptr(),
+	})
+}
+
+// AnalyzerName returns the name of the analyzer that reported the given
+// diagnostic, or an empty string if it was not reported by an analyzer.
+func AnalyzerName(diag *hcl.Diagnostic) string {
+	extra, ok := hcl.DiagnosticExtra[analyzerExtra](diag)
+	if !ok {
+		return ""
+	}
+	return extra.analyzer
+}
+
+// analyzerExtra is the type of the Extra field of diagnostics reported by
+// analyzers.
+type analyzerExtra struct {
+	analyzer string
+}
+
+// Validate returns an error if any of the given analyzers, or the analyzers
+// they require, is invalid: if it has no name or Run function, if two
+// different analyzers have the same name, or if the requirements form a
+// cycle.
+func Validate(analyzers []*Analyzer) error {
+	names := map[string]*Analyzer{}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*Analyzer]int{}
+
+	var visit func(a *Analyzer) error
+	visit = func(a *Analyzer) error {
+		switch state[a] {
+		case visiting:
+			return fmt.Errorf("analyzer %q requires itself, directly or indirectly", a.Name)
+		case done:
+			return nil
+		}
+		if a.Name == "" {
+			return fmt.Errorf("analyzer has no name")
+		}
+		if a.Run == nil {
+			return fmt.Errorf("analyzer %q has no Run function", a.Name)
+		}
+		if other, exists := names[a.Name]; exists && other != a {
+			return fmt.Errorf("more than one analyzer is named %q", a.Name)
+		}
+		names[a.Name] = a
+
+		state[a] = visiting
+		for _, req := range a.Requires {
+			if err := visit(req); err != nil {
+				return err
+			}
+		}
+		state[a] = done
+		return nil
+	}
+
+	for _, a := range analyzers {
+		if err := visit(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var registry struct {
+	sync.Mutex
+	analyzers map[string]*Analyzer
+}
+
+// Register makes the given analyzers available by name through Lookup and
+// Registered, so that drivers can offer them without importing the packages
+// that define them directly.
+//
+// Register panics if an analyzer is invalid, as described for Validate, or
+// if a different analyzer with the same name is already registered.
+// Registering the same analyzer more than once has no effect.
+func Register(analyzers ...*Analyzer) {
+	if err := Validate(analyzers); err != nil {
+		panic(fmt.Sprintf("hclanalysis: %s", err))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.analyzers == nil {
+		registry.analyzers = map[string]*Analyzer{}
+	}
+	for _, a := range analyzers {
+		if existing, exists := registry.analyzers[a.Name]; exists && existing != a {
+			panic(fmt.Sprintf("hclanalysis: an analyzer named %q is already registered", a.Name))
+		}
+	}
+	for _, a := range analyzers {
+		registry.analyzers[a.Name] = a
+	}
+}
+
+// Lookup returns the registered analyzer with the given name, or nil if
+// there is none.
+func Lookup(name string) *Analyzer {
+	registry.Lock()
+	defer registry.Unlock()
+	return registry.analyzers[name]
+}
+
+// Registered returns all of the registered analyzers, in lexical order of
+// their names.
+func Registered() []*Analyzer {
+	registry.Lock()
+	defer registry.Unlock()
+	ret := make([]*Analyzer, 0, len(registry.analyzers))
+	for _, a := range registry.analyzers {
+		ret = append(ret, a)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
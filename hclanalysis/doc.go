@@ -0,0 +1,28 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclanalysis defines a framework for checks that examine HCL
+// configuration files, so that applications and third parties can write
+// their own checks -- such as organizational naming conventions or bans on
+// particular settings -- and run them all with a single driver.
+//
+// Each check is an Analyzer, which is run once for each unit of analysis: a
+// directory of configuration files. Its Run function receives a Pass giving
+// access to the parsed files of that directory, the schema that the
+// application expects those files to conform to, if any, and the results of
+// the analyzers it requires, and it reports the problems it finds as
+// diagnostics.
+//
+// Packages that provide analyzers can make them available by name with
+// Register, typically from an init function. A Driver runs a set of
+// analyzers over one or more directories, and Main wraps a Driver in a
+// command-line interface so that a tool built from a set of analyzers needs
+// only a trivial main function:
+//
+//	func main() {
+//		hclanalysis.Main(naming.Analyzer, tagging.Analyzer)
+//	}
+//
+// The design follows that of the "golang.org/x/tools/go/analysis" package,
+// which will be familiar to authors of checks for Go source code.
+package hclanalysis
@@ -0,0 +1,272 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclanalysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+// DefaultExtensions are the filename suffixes of the files that a Driver
+// analyzes if its Extensions field is empty.
+var DefaultExtensions = []string{".hcl", ".hcl.json"}
+
+// Driver runs analyzers over directories of configuration files.
+//
+// The zero value is ready to use, analyzing files whose names end with one
+// of the DefaultExtensions without any schema.
+type Driver struct {
+	// Parser is used to parse the files, and afterwards holds them so that
+	// the caller can use it to display the resulting diagnostics with
+	// source code snippets. If it is nil, the driver creates a parser when
+	// it first needs one.
+	Parser *hclparse.Parser
+
+	// Schema is given to the analyzers through Pass.Schema.
+	Schema *hclschema.Body
+
+	// Extensions are the filename suffixes of the files to analyze. Files
+	// whose names end with ".json" are parsed as JSON, and all others as
+	// native syntax.
+	Extensions []string
+
+	// Recursive causes the driver to also analyze each subdirectory of the
+	// given directories, as a separate unit, except for those whose names
+	// begin with a period.
+	Recursive bool
+}
+
+// Run runs the given analyzers, and the analyzers they require, over the
+// files in each of the given directories, returning the diagnostics from
+// parsing the files along with the problems reported by the given analyzers.
+// Problems reported by analyzers that run only because they are required by
+// others are not returned.
+//
+// Directories that contain no files to analyze are skipped. The returned
+// diagnostics are ordered by filename and then by position, with any
+// diagnostics that have no subject first.
+func (d *Driver) Run(analyzers []*Analyzer, dirs ...string) hcl.Diagnostics {
+	if err := Validate(analyzers); err != nil {
+		return hcl.Diagnostics{invalidAnalyzersDiagnostic(err)}
+	}
+
+	var diags hcl.Diagnostics
+	for _, dir := range dirs {
+		units, err := d.units(dir)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read directory",
+				Detail:   fmt.Sprintf("Could not read the files in %s: %s.", dir, err),
+			})
+			continue
+		}
+		for _, unit := range units {
+			files, parseDiags := d.parseFiles(unit.filenames)
+			diags = append(diags, parseDiags...)
+			diags = append(diags, d.analyze(analyzers, unit.dir, files, parseDiags.HasErrors())...)
+		}
+	}
+	sortDiagnostics(diags)
+	return diags
+}
+
+// RunFiles runs the given analyzers, and the analyzers they require, over
+// the given files, which belong to the given directory and must have been
+// parsed without errors. The files are keyed by filename, as returned by
+// hclparse.Parser.Files.
+//
+// RunFiles allows applications that have already parsed their configuration
+// to analyze it without reading it from disk again. The returned diagnostics
+// are ordered as for Run.
+func (d *Driver) RunFiles(analyzers []*Analyzer, dir string, files map[string]*hcl.File) hcl.Diagnostics {
+	if err := Validate(analyzers); err != nil {
+		return hcl.Diagnostics{invalidAnalyzersDiagnostic(err)}
+	}
+	diags := d.analyze(analyzers, dir, files, false)
+	sortDiagnostics(diags)
+	return diags
+}
+
+func invalidAnalyzersDiagnostic(err error) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid analyzers",
+		Detail:   fmt.Sprintf("The analyzers cannot be run: %s.", err),
+	}
+}
+
+// unit is a directory and the files within it to analyze.
+type unit struct {
+	dir       string
+	filenames []string
+}
+
+// units returns the units of analysis rooted at the given directory, in
+// lexical order of their directory names.
+func (d *Driver) units(root string) ([]unit, error) {
+	var ret []unit
+	index := map[string]int{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && (!d.Recursive || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.analyzable(info.Name()) {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		i, exists := index[dir]
+		if !exists {
+			i = len(ret)
+			index[dir] = i
+			ret = append(ret, unit{dir: dir})
+		}
+		ret[i].filenames = append(ret[i].filenames, path)
+		return nil
+	})
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].dir < ret[j].dir
+	})
+	return ret, err
+}
+
+func (d *Driver) analyzable(name string) bool {
+	exts := d.Extensions
+	if len(exts) == 0 {
+		exts = DefaultExtensions
+	}
+	for _, ext := range exts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Driver) parseFiles(filenames []string) (map[string]*hcl.File, hcl.Diagnostics) {
+	if d.Parser == nil {
+		d.Parser = hclparse.NewParser()
+	}
+
+	var diags hcl.Diagnostics
+	files := make(map[string]*hcl.File, len(filenames))
+	for _, filename := range filenames {
+		var file *hcl.File
+		var fileDiags hcl.Diagnostics
+		if strings.HasSuffix(filename, ".json") {
+			file, fileDiags = d.Parser.ParseJSONFile(filename)
+		} else {
+			file, fileDiags = d.Parser.ParseHCLFile(filename)
+		}
+		diags = append(diags, fileDiags...)
+		if file != nil {
+			files[filename] = file
+		}
+	}
+	return files, diags
+}
+
+// analyze runs the given analyzers over the given files, returning the
+// problems reported by the given analyzers and any failures.
+func (d *Driver) analyze(analyzers []*Analyzer, dir string, files map[string]*hcl.File, hasErrors bool) hcl.Diagnostics {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	fileList := make([]*hcl.File, len(filenames))
+	for i, filename := range filenames {
+		fileList[i] = files[filename]
+	}
+
+	roots := make(map[*Analyzer]bool, len(analyzers))
+	for _, a := range analyzers {
+		roots[a] = true
+	}
+
+	var diags hcl.Diagnostics
+	results := map[*Analyzer]interface{}{}
+	ran := map[*Analyzer]bool{}
+	visited := map[*Analyzer]bool{}
+
+	var run func(a *Analyzer)
+	run = func(a *Analyzer) {
+		if visited[a] {
+			return
+		}
+		visited[a] = true
+
+		resultOf := make(map[*Analyzer]interface{}, len(a.Requires))
+		for _, req := range a.Requires {
+			run(req)
+			if !ran[req] {
+				return // already reported, if necessary
+			}
+			resultOf[req] = results[req]
+		}
+		if hasErrors && !a.RunDespiteErrors {
+			return
+		}
+
+		pass := &Pass{
+			Analyzer:  a,
+			Dir:       dir,
+			Files:     fileList,
+			Filenames: filenames,
+			Schema:    d.Schema,
+			ResultOf:  resultOf,
+			Report: func(diag *hcl.Diagnostic) {
+				if !roots[a] {
+					return
+				}
+				diag.Extra = analyzerExtra{a.Name}
+				diags = append(diags, diag)
+			},
+		}
+		result, err := a.Run(pass)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Analyzer failed",
+				Detail:   fmt.Sprintf("The %q analyzer failed while checking the files in %s: %s.", a.Name, dir, err),
+				Extra:    analyzerExtra{a.Name},
+			})
+			return
+		}
+		results[a] = result
+		ran[a] = true
+	}
+
+	for _, a := range analyzers {
+		run(a)
+	}
+	return diags
+}
+
+func sortDiagnostics(diags hcl.Diagnostics) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Subject, diags[j].Subject
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		case a.Filename != b.Filename:
+			return a.Filename < b.Filename
+		default:
+			return a.Start.Byte < b.Start.Byte
+		}
+	})
+}
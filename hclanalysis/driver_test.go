@@ -0,0 +1,260 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclanalysis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// blockCount counts the top-level blocks in each directory, as a result for
+// other analyzers to use.
+var blockCount = &Analyzer{
+	Name: "blockcount",
+	Doc:  "Counts top-level blocks.",
+	Run: func(pass *Pass) (interface{}, error) {
+		n := 0
+		for _, file := range pass.Files {
+			content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"name"}}},
+			})
+			n += len(content.Blocks)
+			pass.Reportf(file.Body.MissingItemRange(), "Counted", "This problem is never returned.")
+		}
+		return n, nil
+	},
+}
+
+var tooManyBlocks = &Analyzer{
+	Name:     "toomany",
+	Doc:      "Reports directories with more than two resources. Only a test.",
+	Requires: []*Analyzer{blockCount},
+	Run: func(pass *Pass) (interface{}, error) {
+		if n := pass.ResultOf[blockCount].(int); n > 2 {
+			pass.Reportf(pass.Files[0].Body.MissingItemRange(), "Too many resources", "There are %d resources in %s.", n, filepath.Base(pass.Dir))
+		}
+		return nil, nil
+	},
+}
+
+var snakeCase = &Analyzer{
+	Name: "snakecase",
+	Doc:  "Reports argument names that are not in snake case.",
+	Run: func(pass *Pass) (interface{}, error) {
+		for _, file := range pass.Files {
+			body, ok := file.Body.(*hclsyntax.Body)
+			if !ok {
+				continue
+			}
+			hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+				if attr, ok := node.(*hclsyntax.Attribute); ok && strings.ToLower(attr.Name) != attr.Name {
+					pass.Reportf(attr.NameRange, "Argument name not in snake case", "Use %q instead.", strings.ToLower(attr.Name))
+				}
+				return nil
+			})
+		}
+		return nil, nil
+	},
+}
+
+var schemaCheck = &Analyzer{
+	Name:             "schema",
+	Doc:              "Validates against the schema.",
+	RunDespiteErrors: true,
+	Run: func(pass *Pass) (interface{}, error) {
+		if pass.Schema == nil {
+			return nil, errors.New("no schema")
+		}
+		for _, file := range pass.Files {
+			for _, diag := range hclschema.Validate(file.Body, pass.Schema) {
+				pass.Report(diag)
+			}
+		}
+		return nil, nil
+	},
+}
+
+func TestDriverRun(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.hcl":              "resource \"x\" {\n  Name = 1\n}\n",
+		"b.hcl":              "resource \"y\" {}\nresource \"z\" {}\n",
+		"notes.txt":          "Not = configuration",
+		"sub/c.hcl.json":     `{"resource": {"w": {"fooBar": 1}}}`,
+		"sub/deeper/d.hcl":   "badName = 1\n",
+		".hidden/e.hcl":      "hiddenName = 1\n",
+		"broken/f.hcl":       "resource \"v\" {\n",
+		"broken/g.hcl":       "okName = 1\n",
+		"sub/deeper/x.other": "otherName = 1\n",
+	})
+
+	tests := map[string]struct {
+		driver    Driver
+		analyzers []*Analyzer
+		want      []string
+	}{
+		"top level only": {
+			Driver{},
+			[]*Analyzer{tooManyBlocks, snakeCase},
+			[]string{
+				"toomany a.hcl:1,1: Too many resources",
+				"snakecase a.hcl:2,3: Argument name not in snake case",
+			},
+		},
+		"recursive": {
+			Driver{Recursive: true},
+			[]*Analyzer{snakeCase},
+			[]string{
+				"snakecase a.hcl:2,3: Argument name not in snake case",
+				// Analyzers don't run on directories with syntax errors by
+				// default, so broken/g.hcl is not checked.
+				" broken/f.hcl:1,14: Unclosed configuration block",
+				"snakecase sub/deeper/d.hcl:1,1: Argument name not in snake case",
+			},
+		},
+		"extensions": {
+			Driver{Recursive: true, Extensions: []string{".other"}},
+			[]*Analyzer{snakeCase},
+			[]string{
+				"snakecase sub/deeper/x.other:1,1: Argument name not in snake case",
+			},
+		},
+		"despite errors": {
+			Driver{
+				Recursive: true,
+				Schema: &hclschema.Body{
+					Attributes: []*hclschema.Attribute{{Name: "okName"}},
+				},
+			},
+			[]*Analyzer{schemaCheck},
+			[]string{
+				"schema a.hcl:1,1: Unsupported block type",
+				"schema b.hcl:1,1: Unsupported block type",
+				"schema b.hcl:2,1: Unsupported block type",
+				"schema broken/f.hcl:1,1: Unsupported block type",
+				" broken/f.hcl:1,14: Unclosed configuration block",
+				"schema sub/c.hcl.json:1,2: Extraneous JSON object property",
+				"schema sub/deeper/d.hcl:1,1: Unsupported argument",
+			},
+		},
+		"failure": {
+			Driver{},
+			[]*Analyzer{schemaCheck},
+			[]string{
+				fmt.Sprintf("schema : Analyzer failed: The \"schema\" analyzer failed while checking the files in %s: no schema.", root),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := test.driver.Run(test.analyzers, root)
+			var got []string
+			for _, diag := range diags {
+				if diag.Subject == nil {
+					got = append(got, fmt.Sprintf("%s : %s: %s", AnalyzerName(diag), diag.Summary, diag.Detail))
+					continue
+				}
+				rel, err := filepath.Rel(root, diag.Subject.Filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, fmt.Sprintf("%s %s:%d,%d: %s", AnalyzerName(diag), filepath.ToSlash(rel), diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Summary))
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	run := func(*Pass) (interface{}, error) { return nil, nil }
+	cycleA := &Analyzer{Name: "a", Run: run}
+	cycleB := &Analyzer{Name: "b", Run: run, Requires: []*Analyzer{cycleA}}
+	cycleA.Requires = []*Analyzer{cycleB}
+
+	tests := map[string]struct {
+		analyzers []*Analyzer
+		want      string
+	}{
+		"valid": {
+			[]*Analyzer{tooManyBlocks, snakeCase, blockCount},
+			"",
+		},
+		"no name": {
+			[]*Analyzer{{Run: run}},
+			"analyzer has no name",
+		},
+		"no run": {
+			[]*Analyzer{{Name: "x"}},
+			`analyzer "x" has no Run function`,
+		},
+		"duplicate name": {
+			[]*Analyzer{tooManyBlocks, {Name: "blockcount", Run: run}},
+			`more than one analyzer is named "blockcount"`,
+		},
+		"cycle": {
+			[]*Analyzer{cycleA},
+			`analyzer "a" requires itself, directly or indirectly`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Validate(test.analyzers)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != test.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(snakeCase, tooManyBlocks)
+	Register(snakeCase) // registering again is harmless
+
+	if got := Lookup("snakecase"); got != snakeCase {
+		t.Errorf("wrong result from Lookup: %v", got)
+	}
+	if got := Lookup("nonexist"); got != nil {
+		t.Errorf("wrong result from Lookup: %v", got)
+	}
+	if got := Registered(); len(got) != 2 || got[0] != snakeCase || got[1] != tooManyBlocks {
+		t.Errorf("wrong registered analyzers: %v", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("registering a conflicting analyzer did not panic")
+		}
+	}()
+	Register(&Analyzer{Name: "snakecase", Run: snakeCase.Run})
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, src := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclanalysis
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Main is the main function of a command-line tool that runs the given
+// analyzers over the directories named by its arguments. If no analyzers are
+// given, it runs all of the registered analyzers.
+//
+// Main defines its flags on flag.CommandLine, allowing the tool to define
+// additional flags of its own before calling it. Main does not return: it
+// exits with status 1 if any problems were found, 2 if the tool was used
+// incorrectly or the analysis could not be completed, and 0 otherwise.
+func Main(analyzers ...*Analyzer) {
+	if len(analyzers) == 0 {
+		analyzers = Registered()
+	}
+	progname := filepath.Base(os.Args[0])
+
+	var (
+		schemaFile = flag.String("schema", "", "an HCL file describing the expected structure of the files, as understood by the hclschema package")
+		exts       = flag.String("ext", strings.Join(DefaultExtensions, ","), "comma-separated filename suffixes of the files to analyze")
+		recursive  = flag.Bool("r", false, "also analyze the subdirectories of the given directories")
+		only       = flag.String("only", "", "comma-separated names of the analyzers to run, instead of all of them")
+		list       = flag.Bool("analyzers", false, "list the available analyzers and immediately exit")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] directory ...\n", progname)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, a := range analyzers {
+			fmt.Printf("%-22s %s\n", a.Name, firstSentence(a.Doc))
+		}
+		os.Exit(0)
+	}
+	fail := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", progname, fmt.Sprintf(format, args...))
+		os.Exit(2)
+	}
+	if err := Validate(analyzers); err != nil {
+		fail("%s", err)
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *only != "" {
+		byName := map[string]*Analyzer{}
+		for _, a := range analyzers {
+			byName[a.Name] = a
+		}
+		analyzers = nil
+		for _, name := range strings.Split(*only, ",") {
+			a, ok := byName[strings.TrimSpace(name)]
+			if !ok {
+				fail("unknown analyzer %q; use -analyzers to list the available analyzers", name)
+			}
+			analyzers = append(analyzers, a)
+		}
+	}
+
+	driver := &Driver{
+		Parser:    hclparse.NewParser(),
+		Recursive: *recursive,
+	}
+	for _, ext := range strings.Split(*exts, ",") {
+		if ext = strings.TrimSpace(ext); ext != "" {
+			driver.Extensions = append(driver.Extensions, ext)
+		}
+	}
+
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width = 80
+	}
+	diagWr := hcl.NewDiagnosticTextWriter(os.Stderr, driver.Parser.Files(), uint(width), color)
+
+	if *schemaFile != "" {
+		file, diags := driver.Parser.ParseHCLFile(*schemaFile)
+		if !diags.HasErrors() {
+			var moreDiags hcl.Diagnostics
+			driver.Schema, moreDiags = hclschema.DecodeSchema(file.Body)
+			diags = append(diags, moreDiags...)
+		}
+		diagWr.WriteDiagnostics(diags)
+		if diags.HasErrors() {
+			fail("invalid schema file %s", *schemaFile)
+		}
+	}
+
+	diags := driver.Run(analyzers, flag.Args()...)
+	diagWr.WriteDiagnostics(diags)
+	for _, diag := range diags {
+		// Errors that aren't about a particular part of a file are failures
+		// of the analysis itself, such as a directory that can't be read.
+		if diag.Severity == hcl.DiagError && diag.Subject == nil {
+			os.Exit(2)
+		}
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// firstSentence returns the first sentence of the given documentation.
+func firstSentence(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		return doc[:i+1]
+	}
+	return doc
+}
@@ -0,0 +1,657 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclconvert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+)
+
+// BlockLabelsFunc is the signature of a function that decides whether a
+// property represents a block when converting from the JSON syntax.
+//
+// The given path contains the type names of the blocks that contain the
+// property, if any, followed by the name of the property itself. If the
+// property represents blocks then the function returns the number of labels
+// those blocks have and true. Otherwise, it returns false.
+type BlockLabelsFunc func(path []string) (labels int, isBlock bool)
+
+// SchemaBlockLabels returns a BlockLabelsFunc that treats as blocks the
+// properties that the given schema declares as block types, with the
+// number of labels that the schema gives them.
+func SchemaBlockLabels(schema *hclschema.Body) BlockLabelsFunc {
+	return func(path []string) (int, bool) {
+		body := schema
+		var blockS *hclschema.Block
+		for _, name := range path {
+			blockS = body.Block(name)
+			if blockS == nil {
+				return 0, false
+			}
+			body = blockS.Body
+		}
+		return len(blockS.LabelNames), true
+	}
+}
+
+// FromNativeSyntax converts the given configuration source code written in
+// HCL native syntax to the equivalent configuration in the JSON syntax,
+// preserving the order of the attributes and blocks in each body.
+//
+// Blocks are represented as properties named after the block type, with a
+// level of nested objects for each label. Where there are multiple blocks
+// with the same type and labels, their bodies are given as a JSON array.
+//
+// Literal values are converted to the corresponding JSON values, and
+// templates to JSON strings. Any other expression is converted to a JSON
+// string containing a single interpolation sequence wrapping the source
+// code of that expression.
+//
+// Comments in the native syntax source code are not preserved.
+func FromNativeSyntax(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	c := &nativeConverter{src: src}
+	obj, moreDiags := c.body(file.Body.(*hclsyntax.Body))
+	diags = append(diags, moreDiags...)
+
+	var buf bytes.Buffer
+	writeJSONValue(&buf, obj, 0)
+	buf.WriteByte('\n')
+	return buf.Bytes(), diags
+}
+
+// ToNativeSyntax converts the given configuration source code written in
+// the JSON syntax to the equivalent configuration in HCL native syntax,
+// preserving the order of the properties in each object and converting
+// comment properties, named "//", into comments.
+//
+// Because the JSON syntax cannot distinguish blocks from attributes without
+// a schema, the given function is used to decide which properties represent
+// blocks. If it is nil then all properties are converted to attributes.
+//
+// JSON strings are interpreted as templates, and so a string consisting
+// only of a single interpolation sequence is converted to the expression
+// it wraps.
+func ToNativeSyntax(src []byte, filename string, blocks BlockLabelsFunc) ([]byte, hcl.Diagnostics) {
+	// As when parsing a JSON configuration file, a leading byte order mark
+	// is ignored but still counted in the byte offsets of positions.
+	start := hcl.InitialPos
+	if bytes.HasPrefix(src, utf8BOM) {
+		src = src[len(utf8BOM):]
+		start.Byte += len(utf8BOM)
+	}
+
+	// We use the JSON syntax's expression API, rather than the body API,
+	// because it preserves the order of each object's properties.
+	root, diags := hcljson.ParseExpressionWithStartPos(src, filename, start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var buf bytes.Buffer
+	if props, ok := jsonProperties(root); ok {
+		diags = append(diags, writeNativeBody(&buf, props, nil, blocks)...)
+	} else if elems, ok := jsonElements(root); ok {
+		for _, elem := range elems {
+			props, ok := jsonProperties(elem)
+			if !ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect JSON value type",
+					Detail:   "A JSON object is required here, to represent the content of the root body.",
+					Subject:  elem.StartRange().Ptr(),
+				})
+				continue
+			}
+			diags = append(diags, writeNativeBody(&buf, props, nil, blocks)...)
+		}
+	} else {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Root value must be object",
+			Detail:   "The root value in a JSON-based configuration must be either a JSON object or a JSON array of objects.",
+			Subject:  root.StartRange().Ptr(),
+		})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return hclwrite.Format(buf.Bytes()), diags
+}
+
+// jsonObject is an object whose properties are kept in the order they were
+// added, for producing JSON output.
+type jsonObject struct {
+	names []string
+	props map[string]interface{}
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{
+		props: make(map[string]interface{}),
+	}
+}
+
+func (o *jsonObject) get(name string) (interface{}, bool) {
+	v, ok := o.props[name]
+	return v, ok
+}
+
+func (o *jsonObject) set(name string, v interface{}) {
+	if _, exists := o.props[name]; !exists {
+		o.names = append(o.names, name)
+	}
+	o.props[name] = v
+}
+
+// jsonBlocks is a sequence of block bodies that share a type and labels,
+// written as a single object if there's only one and an array otherwise.
+type jsonBlocks []*jsonObject
+
+// jsonRaw is a value that has already been encoded as JSON.
+type jsonRaw string
+
+type nativeConverter struct {
+	src []byte
+}
+
+func (c *nativeConverter) body(body *hclsyntax.Body) (*jsonObject, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	items := make([]hclsyntax.Node, 0, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range body.Attributes {
+		items = append(items, attr)
+	}
+	for _, block := range body.Blocks {
+		items = append(items, block)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Range().Start.Byte < items[j].Range().Start.Byte
+	})
+
+	ret := newJSONObject()
+	for _, item := range items {
+		switch item := item.(type) {
+		case *hclsyntax.Attribute:
+			if _, exists := ret.get(item.Name); exists {
+				diags = append(diags, conflictingNameDiag(item.Name, item.NameRange))
+				continue
+			}
+			ret.set(item.Name, c.expr(item.Expr))
+
+		case *hclsyntax.Block:
+			content, moreDiags := c.body(item.Body)
+			diags = append(diags, moreDiags...)
+
+			// Each label adds a level of object nesting, with the block
+			// bodies themselves collected at the innermost level.
+			obj := ret
+			name, nameRange := item.Type, item.TypeRange
+			for i, label := range item.Labels {
+				next, exists := obj.get(name)
+				if !exists {
+					next = newJSONObject()
+					obj.set(name, next)
+				}
+				nextObj, ok := next.(*jsonObject)
+				if !ok {
+					diags = append(diags, conflictingNameDiag(name, nameRange))
+					obj = nil
+					break
+				}
+				obj = nextObj
+				name, nameRange = label, item.LabelRanges[i]
+			}
+			if obj == nil {
+				continue
+			}
+
+			existing, exists := obj.get(name)
+			if !exists {
+				existing = jsonBlocks(nil)
+			}
+			blocks, ok := existing.(jsonBlocks)
+			if !ok {
+				diags = append(diags, conflictingNameDiag(name, nameRange))
+				continue
+			}
+			obj.set(name, append(blocks, content))
+		}
+	}
+
+	return ret, diags
+}
+
+func conflictingNameDiag(name string, rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Conflicting property name",
+		Detail:   fmt.Sprintf("The name %q is already used by another argument or block in this body, so this definition cannot be represented in JSON.", name),
+		Subject:  rng.Ptr(),
+	}
+}
+
+func (c *nativeConverter) expr(expr hclsyntax.Expression) interface{} {
+	switch expr := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		if raw, ok := literalJSON(expr.Val); ok {
+			return raw
+		}
+
+	case *hclsyntax.TemplateExpr:
+		var buf strings.Builder
+		for _, part := range expr.Parts {
+			if lit, ok := part.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type() == cty.String && lit.Val.IsKnown() && !lit.Val.IsNull() {
+				buf.WriteString(escapeTemplateLiteral(lit.Val.AsString()))
+				continue
+			}
+			if !c.isInterpolation(part) {
+				// Template directives have no simpler representation,
+				// so we'll use the general form for the whole template.
+				return c.wrappedSource(expr)
+			}
+			buf.WriteString(c.wrappedSource(part))
+		}
+		return buf.String()
+
+	case *hclsyntax.TemplateWrapExpr:
+		return c.wrappedSource(expr.Wrapped)
+
+	case *hclsyntax.TupleConsExpr:
+		ret := make([]interface{}, len(expr.Exprs))
+		for i, elem := range expr.Exprs {
+			ret[i] = c.expr(elem)
+		}
+		return ret
+
+	case *hclsyntax.ObjectConsExpr:
+		ret := newJSONObject()
+		for _, item := range expr.Items {
+			ret.set(c.objectKey(item.KeyExpr), c.expr(item.ValueExpr))
+		}
+		return ret
+	}
+
+	return c.wrappedSource(expr)
+}
+
+// objectKey returns the JSON property name for the given object constructor
+// key expression.
+func (c *nativeConverter) objectKey(expr hclsyntax.Expression) string {
+	if keyExpr, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if !keyExpr.ForceNonLiteral {
+			if name := hcl.ExprAsKeyword(keyExpr.Wrapped); name != "" {
+				return name
+			}
+		}
+		expr = keyExpr.Wrapped
+	}
+	if parens, ok := expr.(*hclsyntax.ParenthesesExpr); ok {
+		expr = parens.Expression
+	}
+	if s, ok := c.expr(expr).(string); ok {
+		return s
+	}
+	return c.wrappedSource(expr)
+}
+
+// isInterpolation returns true if the given template part was written as an
+// interpolation sequence, rather than as a template directive.
+func (c *nativeConverter) isInterpolation(part hclsyntax.Expression) bool {
+	before := bytes.TrimRight(c.src[:part.Range().Start.Byte], " \t~")
+	return bytes.HasSuffix(before, []byte("${"))
+}
+
+// wrappedSource returns a template string that contains only a single
+// interpolation sequence wrapping the source code of the given expression.
+func (c *nativeConverter) wrappedSource(expr hclsyntax.Expression) string {
+	rng := expr.Range()
+	return "${" + string(rng.SliceBytes(c.src)) + "}"
+}
+
+func literalJSON(val cty.Value) (jsonRaw, bool) {
+	if !val.IsKnown() {
+		return "", false
+	}
+	if val.IsNull() {
+		return "null", true
+	}
+	switch val.Type() {
+	case cty.Bool:
+		if val.True() {
+			return "true", true
+		}
+		return "false", true
+	case cty.Number:
+		return jsonRaw(val.AsBigFloat().Text('f', -1)), true
+	case cty.String:
+		return jsonRaw(jsonString(escapeTemplateLiteral(val.AsString()))), true
+	}
+	return "", false
+}
+
+// escapeTemplateLiteral escapes the template introducers in the given
+// literal string, so that the JSON syntax won't interpret them as the
+// start of template sequences.
+func escapeTemplateLiteral(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+	return s
+}
+
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // can't fail for a string
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch v := v.(type) {
+	case jsonRaw:
+		buf.WriteString(string(v))
+	case string:
+		buf.WriteString(jsonString(v))
+	case jsonBlocks:
+		if len(v) == 1 {
+			writeJSONValue(buf, v[0], indent)
+			return
+		}
+		elems := make([]interface{}, len(v))
+		for i, obj := range v {
+			elems[i] = obj
+		}
+		writeJSONValue(buf, elems, indent)
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, elem := range v {
+			writeJSONIndent(buf, indent+1)
+			writeJSONValue(buf, elem, indent+1)
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		writeJSONIndent(buf, indent)
+		buf.WriteByte(']')
+	case *jsonObject:
+		if len(v.names) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, name := range v.names {
+			writeJSONIndent(buf, indent+1)
+			buf.WriteString(jsonString(name))
+			buf.WriteString(": ")
+			writeJSONValue(buf, v.props[name], indent+1)
+			if i < len(v.names)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		writeJSONIndent(buf, indent)
+		buf.WriteByte('}')
+	default:
+		// should never happen, since we construct all of the values above
+		panic(fmt.Sprintf("unsupported JSON value type %T", v))
+	}
+}
+
+func writeJSONIndent(buf *bytes.Buffer, indent int) {
+	for i := 0; i < indent; i++ {
+		buf.WriteString("  ")
+	}
+}
+
+// utf8BOM is the byte order mark that the JSON syntax permits at the start
+// of a configuration file.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// jsonProperties returns the properties of the given JSON expression, in
+// the order they appear in the source, and true if it is an object.
+func jsonProperties(expr hcl.Expression) ([]hcl.KeyValuePair, bool) {
+	props, diags := hcl.ExprMap(expr)
+	return props, !diags.HasErrors()
+}
+
+// jsonElements returns the elements of the given JSON expression and true
+// if it is an array.
+func jsonElements(expr hcl.Expression) ([]hcl.Expression, bool) {
+	elems, diags := hcl.ExprList(expr)
+	return elems, !diags.HasErrors()
+}
+
+// jsonPropertyName returns the name of the property with the given key
+// expression.
+func jsonPropertyName(prop hcl.KeyValuePair) string {
+	// Without an evaluation context, a JSON string is taken literally.
+	name, _ := prop.Key.Value(nil)
+	return name.AsString()
+}
+
+func writeNativeBody(buf *bytes.Buffer, props []hcl.KeyValuePair, path []string, blocks BlockLabelsFunc) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, prop := range props {
+		name := jsonPropertyName(prop)
+		if name == "//" {
+			// The JSON syntax treats properties named "//" as comments,
+			// so we'll write them as comments in the native syntax too.
+			if v, _ := prop.Value.Value(nil); v.Type() == cty.String {
+				for _, line := range strings.Split(v.AsString(), "\n") {
+					buf.WriteString(strings.TrimRight("# "+line, " "))
+					buf.WriteByte('\n')
+				}
+				continue
+			}
+		}
+
+		if blocks != nil {
+			blockPath := append(path[:len(path):len(path)], name)
+			if labelCount, isBlock := blocks(blockPath); isBlock {
+				diags = append(diags, writeNativeBlocks(buf, blockPath, nil, labelCount, prop.Value, blocks)...)
+				continue
+			}
+		}
+
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid argument name",
+				Detail:   fmt.Sprintf("The property name %q is not a valid argument name in HCL native syntax.", name),
+				Subject:  prop.Key.Range().Ptr(),
+			})
+			continue
+		}
+		buf.WriteString(name)
+		buf.WriteString(" = ")
+		writeNativeExpr(buf, prop.Value)
+		buf.WriteByte('\n')
+	}
+	return diags
+}
+
+func writeNativeBlocks(buf *bytes.Buffer, path []string, labels []string, labelCount int, v hcl.Expression, blocks BlockLabelsFunc) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	typeName := path[len(path)-1]
+
+	if elems, ok := jsonElements(v); ok {
+		for _, elem := range elems {
+			diags = append(diags, writeNativeBlocks(buf, path, labels, labelCount, elem, blocks)...)
+		}
+		return diags
+	}
+
+	props, ok := jsonProperties(v)
+	if !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Incorrect JSON value type",
+			Detail:   fmt.Sprintf("A JSON object is required here, to represent the content of a %q block.", typeName),
+			Subject:  v.StartRange().Ptr(),
+		})
+	}
+
+	if len(labels) < labelCount {
+		for _, prop := range props {
+			labels := append(labels[:len(labels):len(labels)], jsonPropertyName(prop))
+			diags = append(diags, writeNativeBlocks(buf, path, labels, labelCount, prop.Value, blocks)...)
+		}
+		return diags
+	}
+
+	buf.WriteString(typeName)
+	for _, label := range labels {
+		buf.WriteByte(' ')
+		buf.WriteString(jsonString(label))
+	}
+	buf.WriteString(" {\n")
+	diags = append(diags, writeNativeBody(buf, props, path, blocks)...)
+	buf.WriteString("}\n")
+	return diags
+}
+
+func writeNativeExpr(buf *bytes.Buffer, v hcl.Expression) {
+	if elems, ok := jsonElements(v); ok {
+		buf.WriteString("[")
+		multiLine := false
+		for _, elem := range elems {
+			if _, ok := jsonProperties(elem); ok {
+				multiLine = true
+			} else if _, ok := jsonElements(elem); ok {
+				multiLine = true
+			}
+		}
+		for i, elem := range elems {
+			if multiLine {
+				buf.WriteByte('\n')
+			} else if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writeNativeExpr(buf, elem)
+			if multiLine || i < len(elems)-1 {
+				buf.WriteByte(',')
+			}
+		}
+		if multiLine {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("]")
+		return
+	}
+
+	if props, ok := jsonProperties(v); ok {
+		if len(props) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for _, prop := range props {
+			name := jsonPropertyName(prop)
+			switch {
+			case hclsyntax.ValidIdentifier(name):
+				buf.WriteString(name)
+			case isSingleInterpolation(name):
+				buf.WriteByte('(')
+				buf.WriteString(name[2 : len(name)-1])
+				buf.WriteByte(')')
+			default:
+				writeNativeTemplate(buf, name)
+			}
+			buf.WriteString(" = ")
+			writeNativeExpr(buf, prop.Value)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("}")
+		return
+	}
+
+	// Without an evaluation context, a JSON string is taken literally
+	// rather than as a template, so any other value is a primitive.
+	val, _ := v.Value(nil)
+	switch {
+	case val.IsNull():
+		buf.WriteString("null")
+	case val.Type() == cty.String:
+		writeNativeTemplate(buf, val.AsString())
+	case val.Type() == cty.Number:
+		buf.WriteString(val.AsBigFloat().Text('f', -1))
+	case val.Type() == cty.Bool:
+		if val.True() {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	}
+}
+
+// writeNativeTemplate writes the given JSON string, which is a template, as
+// a native syntax expression.
+func writeNativeTemplate(buf *bytes.Buffer, s string) {
+	if isSingleInterpolation(s) {
+		buf.WriteString(s[2 : len(s)-1])
+		return
+	}
+
+	// The template syntax is the same in both cases, so we need only to
+	// escape the characters that are special in native syntax quoted
+	// strings but not in JSON strings.
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// isSingleInterpolation returns true if the given template consists only of
+// a single interpolation sequence, like "${foo}".
+func isSingleInterpolation(s string) bool {
+	if !strings.HasPrefix(s, "${") || strings.HasPrefix(s, "${~") {
+		return false
+	}
+	expr, diags := hclsyntax.ParseTemplate([]byte(s), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+	_, ok := expr.(*hclsyntax.TemplateWrapExpr)
+	return ok && strings.HasSuffix(s, "}") && !strings.HasSuffix(s, "~}")
+}
@@ -0,0 +1,318 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclconvert
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+func TestFromNativeSyntax(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"attributes": {
+			`
+name    = "foo"
+count   = 2.5
+enabled = true
+nothing = null
+list    = [1, "a"]
+obj     = { b = 1, "c d" = 2, (var.e) = 3 }
+`,
+			`{
+  "name": "foo",
+  "count": 2.5,
+  "enabled": true,
+  "nothing": null,
+  "list": [
+    1,
+    "a"
+  ],
+  "obj": {
+    "b": 1,
+    "c d": 2,
+    "${var.e}": 3
+  }
+}
+`,
+		},
+		"expressions": {
+			`
+ref      = var.foo
+call     = upper("a")
+template = "Hello, ${var.name}!"
+literal  = "$${not} <html>"
+cond     = "%{ if var.x }yes%{ endif }"
+`,
+			`{
+  "ref": "${var.foo}",
+  "call": "${upper(\"a\")}",
+  "template": "Hello, ${var.name}!",
+  "literal": "$${not} <html>",
+  "cond": "${\"%{ if var.x }yes%{ endif }\"}"
+}
+`,
+		},
+		"blocks": {
+			`
+z = 1
+service "web" {
+  port = 80
+}
+empty {}
+service "db" {
+  port = 5432
+}
+listener {
+  port = 1
+}
+listener {
+  port = 2
+}
+`,
+			`{
+  "z": 1,
+  "service": {
+    "web": {
+      "port": 80
+    },
+    "db": {
+      "port": 5432
+    }
+  },
+  "empty": {},
+  "listener": [
+    {
+      "port": 1
+    },
+    {
+      "port": 2
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := FromNativeSyntax([]byte(test.src), "test.hcl")
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			if string(got) != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFromNativeSyntaxConflict(t *testing.T) {
+	_, diags := FromNativeSyntax([]byte("a = 1\na {}\n"), "test.hcl")
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Conflicting property name"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
+
+func TestToNativeSyntax(t *testing.T) {
+	blocks := func(path []string) (int, bool) {
+		switch path[len(path)-1] {
+		case "service":
+			return 1, len(path) == 1
+		case "listener", "check":
+			return 0, true
+		}
+		return 0, false
+	}
+
+	tests := map[string]struct {
+		src    string
+		blocks BlockLabelsFunc
+		want   string
+	}{
+		"attributes": {
+			`{
+  "//": "A comment.",
+  "name": "foo",
+  "count": 2.5,
+  "ref": "${var.foo}",
+  "template": "Hello, ${var.name}!\n",
+  "list": [1, "a"],
+  "objs": [{"a": 1}],
+  "obj": {"b": 1, "c d": 2, "${var.e}": 3}
+}`,
+			nil,
+			`# A comment.
+name     = "foo"
+count    = 2.5
+ref      = var.foo
+template = "Hello, ${var.name}!\n"
+list     = [1, "a"]
+objs = [
+  {
+    a = 1
+  },
+]
+obj = {
+  b       = 1
+  "c d"   = 2
+  (var.e) = 3
+}
+`,
+		},
+		"blocks": {
+			`{
+  "service": {
+    "web": {"port": 80, "check": {"path": "/"}},
+    "db": {"port": 5432}
+  },
+  "listener": [{"port": 1}, {"port": 2}]
+}`,
+			blocks,
+			`service "web" {
+  port = 80
+  check {
+    path = "/"
+  }
+}
+service "db" {
+  port = 5432
+}
+listener {
+  port = 1
+}
+listener {
+  port = 2
+}
+`,
+		},
+		"no schema": {
+			`{"service": {"web": {"port": 80}}}`,
+			nil,
+			`service = {
+  web = {
+    port = 80
+  }
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ToNativeSyntax([]byte(test.src), "test.json", test.blocks)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			if string(got) != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSchemaBlockLabels(t *testing.T) {
+	blocks := SchemaBlockLabels(&hclschema.Body{
+		Attributes: []*hclschema.Attribute{
+			{Name: "name"},
+		},
+		Blocks: []*hclschema.Block{
+			{
+				Type:       "service",
+				LabelNames: []string{"name"},
+				Body: &hclschema.Body{
+					Blocks: []*hclschema.Block{
+						{Type: "check"},
+					},
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		path    []string
+		labels  int
+		isBlock bool
+	}{
+		{[]string{"name"}, 0, false},
+		{[]string{"service"}, 1, true},
+		{[]string{"service", "check"}, 0, true},
+		{[]string{"service", "port"}, 0, false},
+		{[]string{"check"}, 0, false},
+	}
+	for _, test := range tests {
+		labels, isBlock := blocks(test.path)
+		if labels != test.labels || isBlock != test.isBlock {
+			t.Errorf("wrong result for %q: %d, %t; want %d, %t", test.path, labels, isBlock, test.labels, test.isBlock)
+		}
+	}
+}
+
+func TestToNativeSyntaxErrors(t *testing.T) {
+	blocks := func(path []string) (int, bool) {
+		return 1, path[len(path)-1] == "service"
+	}
+
+	tests := map[string]struct {
+		src     string
+		summary string
+		subject hcl.Pos
+	}{
+		"root not object": {
+			`"foo"`,
+			"Root value must be object",
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+		},
+		"root array element not object": {
+			`[{"a": 1}, 2]`,
+			"Incorrect JSON value type",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+		},
+		"block not object": {
+			`{"service": {"web": true}}`,
+			"Incorrect JSON value type",
+			hcl.Pos{Line: 1, Column: 21, Byte: 20},
+		},
+		"invalid argument name": {
+			`{"a": 1, "b c": 2}`,
+			"Invalid argument name",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+		},
+		"byte order mark": {
+			"\xef\xbb\xbf{\"b c\": 2}",
+			"Invalid argument name",
+			hcl.Pos{Line: 1, Column: 2, Byte: 4},
+		},
+		"syntax error": {
+			`{"a": }`,
+			"Missing JSON value",
+			hcl.Pos{Line: 1, Column: 7, Byte: 6},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ToNativeSyntax([]byte(test.src), "test.json", blocks)
+			if got != nil {
+				t.Errorf("unexpected result %q", got)
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if diags[0].Summary != test.summary {
+				t.Errorf("wrong summary %q; want %q", diags[0].Summary, test.summary)
+			}
+			if got := diags[0].Subject.Start; got != test.subject {
+				t.Errorf("wrong subject start %#v; want %#v", got, test.subject)
+			}
+		})
+	}
+}
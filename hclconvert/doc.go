@@ -0,0 +1,14 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclconvert converts configuration between the HCL native syntax
+// and the JSON syntax, preserving the order of the attributes and blocks in
+// each body.
+//
+// The conversion is kept out of the "json" package so that the JSON parser
+// does not depend on the "hclwrite" package, which is used to format the
+// native syntax output.
+//
+// The cmd/hclconvert program wraps this package for use from the command
+// line.
+package hclconvert
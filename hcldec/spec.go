@@ -232,6 +232,11 @@ func (s *AttrSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ct
 			Context:     hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
 			Expression:  attr.Expr,
 			EvalContext: ctx,
+			Extra: &hcl.TypeMismatchError{
+				Want:  s.Type,
+				Got:   val.Type(),
+				Range: attr.Expr.Range(),
+			},
 		})
 		// We'll return an unknown value of the _correct_ type so that the
 		// incomplete result can still be used for some analysis use-cases.
@@ -0,0 +1,287 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldiff
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// ChangeKind describes how an attribute or block differs between the two
+// files given to Diff.
+type ChangeKind int
+
+const (
+	// Added means that the item exists only in the new file.
+	Added ChangeKind = iota
+
+	// Removed means that the item exists only in the old file.
+	Removed
+
+	// Changed means that an attribute's expression differs between the
+	// two files.
+	Changed
+
+	// Moved means that a block appears in a different position relative to
+	// the other blocks in the same body. The contents of a moved block are
+	// compared as usual, and so may be reported as other changes.
+	Moved
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case Moved:
+		return "moved"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so that change kinds are
+// represented by their names in JSON.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change is a single difference between two files.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+
+	// Path identifies the item that changed, as a sequence of block types
+	// and labels leading to it, ending with the name of the attribute or the
+	// type and labels of the block.
+	Path []string `json:"path"`
+
+	// Block is true if the item is a block, or false if it is an attribute.
+	Block bool `json:"block"`
+
+	// Old and New are the source ranges of the item in the old and new
+	// files respectively. Old is nil for an added item and New is nil for
+	// a removed item.
+	Old *hcl.Range `json:"old,omitempty"`
+	New *hcl.Range `json:"new,omitempty"`
+
+	// OldSource and NewSource are the source code of the attribute's
+	// expression in the old and new files, for attribute changes only.
+	OldSource string `json:"old_source,omitempty"`
+	NewSource string `json:"new_source,omitempty"`
+}
+
+// Diff compares the bodies of the given files, which must both have been
+// parsed from native syntax, and returns the differences between them.
+//
+// Within each body, changes to attributes are returned before changes to
+// blocks, with removed items first in the order they appear in the old file
+// and then other items in the order they appear in the new file. Changes
+// inside a block immediately follow any change to the block itself.
+//
+// Blocks are matched between the two files by their type and labels. Where
+// a body contains more than one block with the same type and labels, the
+// blocks are matched in the order they appear.
+func Diff(old, new *hcl.File) ([]Change, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	oldBody, ok := old.Body.(*hclsyntax.Body)
+	if !ok {
+		diags = append(diags, unsupportedBodyDiag(old.Body))
+	}
+	newBody, ok := new.Body.(*hclsyntax.Body)
+	if !ok {
+		diags = append(diags, unsupportedBodyDiag(new.Body))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	d := &differ{old: old.Bytes, new: new.Bytes}
+	d.body(oldBody, newBody, nil)
+	return d.changes, diags
+}
+
+func unsupportedBodyDiag(body hcl.Body) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported body",
+		Detail:   "Only files in the native syntax can be compared.",
+		Subject:  body.MissingItemRange().Ptr(),
+	}
+}
+
+type differ struct {
+	old, new []byte
+	changes  []Change
+}
+
+func (d *differ) body(old, new *hclsyntax.Body, path []string) {
+	for _, attr := range hclsyntaxutil.SortedAttributes(old) {
+		if _, exists := new.Attributes[attr.Name]; exists {
+			continue
+		}
+		d.changes = append(d.changes, Change{
+			Kind:      Removed,
+			Path:      appendPath(path, attr.Name),
+			Old:       attr.SrcRange.Ptr(),
+			OldSource: string(attr.Expr.Range().SliceBytes(d.old)),
+		})
+	}
+	for _, attr := range hclsyntaxutil.SortedAttributes(new) {
+		oldAttr, exists := old.Attributes[attr.Name]
+		if !exists {
+			d.changes = append(d.changes, Change{
+				Kind:      Added,
+				Path:      appendPath(path, attr.Name),
+				New:       attr.SrcRange.Ptr(),
+				NewSource: string(attr.Expr.Range().SliceBytes(d.new)),
+			})
+			continue
+		}
+		if !equalExprs(oldAttr.Expr, d.old, attr.Expr, d.new) {
+			d.changes = append(d.changes, Change{
+				Kind:      Changed,
+				Path:      appendPath(path, attr.Name),
+				Old:       oldAttr.SrcRange.Ptr(),
+				New:       attr.SrcRange.Ptr(),
+				OldSource: string(oldAttr.Expr.Range().SliceBytes(d.old)),
+				NewSource: string(attr.Expr.Range().SliceBytes(d.new)),
+			})
+		}
+	}
+
+	// oldMatch[i] is the index in new.Blocks of the block matching
+	// old.Blocks[i], or -1 if it was removed.
+	oldMatch := matchBlocks(old.Blocks, new.Blocks)
+	newMatch := make([]int, len(new.Blocks))
+	for i := range newMatch {
+		newMatch[i] = -1
+	}
+	for i, j := range oldMatch {
+		if j >= 0 {
+			newMatch[j] = i
+		}
+	}
+	moved := movedBlocks(oldMatch)
+
+	for i, block := range old.Blocks {
+		if oldMatch[i] >= 0 {
+			continue
+		}
+		d.changes = append(d.changes, Change{
+			Kind:  Removed,
+			Path:  appendPath(path, blockKey(block)...),
+			Block: true,
+			Old:   block.Range().Ptr(),
+		})
+	}
+	for j, block := range new.Blocks {
+		blockPath := appendPath(path, blockKey(block)...)
+		i := newMatch[j]
+		if i < 0 {
+			d.changes = append(d.changes, Change{
+				Kind:  Added,
+				Path:  blockPath,
+				Block: true,
+				New:   block.Range().Ptr(),
+			})
+			continue
+		}
+		oldBlock := old.Blocks[i]
+		if moved[i] {
+			d.changes = append(d.changes, Change{
+				Kind:  Moved,
+				Path:  blockPath,
+				Block: true,
+				Old:   oldBlock.Range().Ptr(),
+				New:   block.Range().Ptr(),
+			})
+		}
+		d.body(oldBlock.Body, block.Body, blockPath)
+	}
+}
+
+// matchBlocks pairs each of the old blocks with a new block of the same type
+// and labels, returning the index of the new block for each old block or -1
+// if there is no corresponding new block.
+func matchBlocks(old, new hclsyntax.Blocks) []int {
+	available := map[string][]int{}
+	for j, block := range new {
+		key := blockKeyString(block)
+		available[key] = append(available[key], j)
+	}
+	ret := make([]int, len(old))
+	for i, block := range old {
+		key := blockKeyString(block)
+		if candidates := available[key]; len(candidates) > 0 {
+			ret[i] = candidates[0]
+			available[key] = candidates[1:]
+		} else {
+			ret[i] = -1
+		}
+	}
+	return ret
+}
+
+// movedBlocks returns which of the matched old blocks have moved, given the
+// result of matchBlocks. The blocks that stayed in place are the longest
+// sequence whose new positions are in the same order as their old
+// positions, and all other matched blocks are considered to have moved.
+func movedBlocks(match []int) []bool {
+	// Quadratic longest increasing subsequence, since bodies rarely have
+	// more than a handful of blocks.
+	n := len(match)
+	length := make([]int, n)
+	prev := make([]int, n)
+	best := -1
+	for i := 0; i < n; i++ {
+		prev[i] = -1
+		if match[i] < 0 {
+			continue
+		}
+		length[i] = 1
+		for k := 0; k < i; k++ {
+			if match[k] >= 0 && match[k] < match[i] && length[k]+1 > length[i] {
+				length[i] = length[k] + 1
+				prev[i] = k
+			}
+		}
+		if best < 0 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	moved := make([]bool, n)
+	for i := range match {
+		moved[i] = match[i] >= 0
+	}
+	for i := best; i >= 0; i = prev[i] {
+		moved[i] = false
+	}
+	return moved
+}
+
+func blockKey(block *hclsyntax.Block) []string {
+	return append([]string{block.Type}, block.Labels...)
+}
+
+func blockKeyString(block *hclsyntax.Block) string {
+	// The null character can't appear in a label, so it can't make two
+	// different keys look the same.
+	var buf []byte
+	for _, s := range blockKey(block) {
+		buf = append(buf, s...)
+		buf = append(buf, 0)
+	}
+	return string(buf)
+}
+
+func appendPath(path []string, names ...string) []string {
+	ret := make([]string, 0, len(path)+len(names))
+	ret = append(ret, path...)
+	return append(ret, names...)
+}
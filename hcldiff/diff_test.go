@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldiff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+func TestDiff(t *testing.T) {
+	tests := map[string]struct {
+		old, new string
+		want     string
+	}{
+		"identical": {
+			"a = 1\n",
+			"a = 1\n",
+			"",
+		},
+		"insignificant": {
+			"a = 1.0 # one\nb = [\n  var.x, # x\n]\nc = \"x\"\n",
+			"# leading\nc = \"x\"\nb = [var.x]\na   = 1\n",
+			"",
+		},
+		"attributes": {
+			"a = 1\nb = var.x\nc = true\n",
+			"a = 2\nb = var.y\nd = \"new\"\n",
+			`- attribute c
+    - true
+~ attribute a
+    - 1
+    + 2
+~ attribute b
+    - var.x
+    + var.y
++ attribute d
+    + "new"
+`,
+		},
+		"type change": {
+			"a = 1\n",
+			"a = \"1\"\n",
+			`~ attribute a
+    - 1
+    + "1"
+`,
+		},
+		"blocks": {
+			`
+resource "a" "x" {
+  v = 1
+}
+resource "a" "y" {
+}
+`,
+			`
+resource "a" "x" {
+  v = 2
+}
+resource "a" "z" {
+}
+`,
+			`- block resource.a.y
+~ attribute resource.a.x.v
+    - 1
+    + 2
++ block resource.a.z
+`,
+		},
+		"moved": {
+			`
+a {}
+b {}
+c {
+  nested = 1
+}
+`,
+			`
+c {
+  nested = 1
+}
+a {}
+b {}
+`,
+			`> block c (line 4 to 2)
+`,
+		},
+		"repeated blocks": {
+			`
+rule { n = 1 }
+rule { n = 2 }
+`,
+			`
+rule { n = 1 }
+rule { n = 3 }
+rule { n = 4 }
+`,
+			`~ attribute rule.n
+    - 2
+    + 3
++ block rule
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			old, diags := hclsyntax.ParseConfig([]byte(test.old), "old.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			new, diags := hclsyntax.ParseConfig([]byte(test.new), "new.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			changes, diags := Diff(old, new)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			var buf bytes.Buffer
+			if err := Render(&buf, changes); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffChangeFields(t *testing.T) {
+	old, _ := hclsyntax.ParseConfig([]byte("a = 1\n"), "old.hcl", hcl.InitialPos)
+	new, _ := hclsyntax.ParseConfig([]byte("\na = 2\n"), "new.hcl", hcl.InitialPos)
+	changes, _ := Diff(old, new)
+	if len(changes) != 1 {
+		t.Fatalf("wrong number of changes %d; want 1", len(changes))
+	}
+	got := changes[0]
+	if got.Kind != Changed || got.Block {
+		t.Errorf("wrong change %#v", got)
+	}
+	if got.Old.Filename != "old.hcl" || got.Old.Start.Line != 1 {
+		t.Errorf("wrong old range %s", got.Old)
+	}
+	if got.New.Filename != "new.hcl" || got.New.Start.Line != 2 {
+		t.Errorf("wrong new range %s", got.New)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	old, _ := hclsyntax.ParseConfig([]byte("a = 1\n"), "old.hcl", hcl.InitialPos)
+	new, _ := json.Parse([]byte(`{"a": 1}`), "new.json")
+	_, diags := Diff(old, new)
+	if !diags.HasErrors() {
+		t.Fatalf("succeeded; want error")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	old, _ := hclsyntax.ParseConfig([]byte("user = \"admin\"\npassword = \"hunter2\"\ndatabase \"main\" {\n  password = \"y\"\n}\n"), "old.hcl", hcl.InitialPos)
+	new, _ := hclsyntax.ParseConfig([]byte("user = \"root\"\npassword = \"swordfish\"\ndatabase \"main\" {\n  password = \"x\"\n}\n"), "new.hcl", hcl.InitialPos)
+	changes, diags := Diff(old, new)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, Redact(changes, "**.password")); err != nil {
+		t.Fatal(err)
+	}
+	want := `~ attribute user
+    - "admin"
+    + "root"
+~ attribute password
+    - (sensitive)
+    + (sensitive)
+~ attribute database.main.password
+    - (sensitive)
+    + (sensitive)
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+	if changes[1].NewSource == "(sensitive)" {
+		t.Errorf("Redact modified the given changes")
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hcldiff compares two native syntax configuration files
+// structurally, reporting the attributes and blocks that were added, removed,
+// changed or moved rather than the lines of text that differ.
+//
+// Differences that don't affect the meaning of the configuration, such as
+// whitespace, comments, the order of attributes and the way a literal value
+// is written, are not reported.
+package hcldiff
@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldiff
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// equalExprs returns true if the two given expressions, from the given old
+// and new source buffers respectively, have the same meaning.
+//
+// Expressions that can be evaluated without any variables or functions are
+// compared by value, so that for example 1.0 and 1 are equal. Otherwise the
+// expressions are compared token by token, ignoring whitespace, newlines
+// and comments.
+func equalExprs(old hclsyntax.Expression, oldSrc []byte, new hclsyntax.Expression, newSrc []byte) bool {
+	oldVal, oldDiags := old.Value(nil)
+	newVal, newDiags := new.Value(nil)
+	if !oldDiags.HasErrors() && !newDiags.HasErrors() && oldVal.IsWhollyKnown() && newVal.IsWhollyKnown() {
+		if !oldVal.Type().Equals(newVal.Type()) {
+			return false
+		}
+		eq := oldVal.Equals(newVal)
+		return eq.IsKnown() && eq.True()
+	}
+
+	oldToks := hclsyntaxutil.SignificantTokens(old.Range().SliceBytes(oldSrc))
+	newToks := hclsyntaxutil.SignificantTokens(new.Range().SliceBytes(newSrc))
+	if len(oldToks) != len(newToks) {
+		return false
+	}
+	for i := range oldToks {
+		if oldToks[i].Type != newToks[i].Type || !bytes.Equal(oldToks[i].Bytes, newToks[i].Bytes) {
+			return false
+		}
+	}
+	return true
+}
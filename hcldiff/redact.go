@@ -0,0 +1,33 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldiff
+
+import (
+	"github.com/hashicorp/hcl/v2/ext/redact"
+)
+
+// Redact returns a copy of the given changes in which the old and new source
+// code of each attribute whose path matches one of the given patterns is
+// replaced by redact.Placeholder, so that the changes can be rendered or
+// logged without revealing secrets. The patterns are as described for
+// redact.Paths, such as "database.*.password" or "**.token".
+//
+// The changes themselves are kept, so the result still shows that a
+// sensitive attribute was added, removed or changed.
+func Redact(changes []Change, patterns ...string) []Change {
+	paths := redact.Paths(patterns)
+	ret := make([]Change, len(changes))
+	for i, change := range changes {
+		if !change.Block && paths.Match(change.Path) {
+			if change.OldSource != "" {
+				change.OldSource = redact.Placeholder
+			}
+			if change.NewSource != "" {
+				change.NewSource = redact.Placeholder
+			}
+		}
+		ret[i] = change
+	}
+	return ret
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var changeSymbols = map[ChangeKind]string{
+	Added:   "+",
+	Removed: "-",
+	Changed: "~",
+	Moved:   ">",
+}
+
+// Render writes a human-readable description of the given changes to the
+// given writer, with one line for each change followed by indented lines
+// showing the old and new expressions of attributes.
+//
+// The format is intended for human consumption and may change in future
+// versions, so programs should use the Change values directly instead.
+func Render(w io.Writer, changes []Change) error {
+	for _, change := range changes {
+		symbol := changeSymbols[change.Kind]
+		what := "attribute"
+		if change.Block {
+			what = "block"
+		}
+		line := fmt.Sprintf("%s %s %s", symbol, what, strings.Join(change.Path, "."))
+		if change.Kind == Moved {
+			line += fmt.Sprintf(" (line %d to %d)", change.Old.Start.Line, change.New.Start.Line)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if change.Block {
+			continue
+		}
+		if change.OldSource != "" {
+			if err := writeSource(w, "-", change.OldSource); err != nil {
+				return err
+			}
+		}
+		if change.NewSource != "" {
+			if err := writeSource(w, "+", change.NewSource); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeSource(w io.Writer, symbol string, src string) error {
+	for _, line := range strings.Split(src, "\n") {
+		if _, err := fmt.Fprintf(w, "    %s %s\n", symbol, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
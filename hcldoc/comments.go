@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldoc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// DecodeSchema decodes a schema from the given file in the same way as
+// hclschema.DecodeSchema, and then uses the comments leading each attribute
+// and block declaration as the description of any that has no description
+// argument.
+//
+// A leading comment is a sequence of comments, each on its own line, that
+// ends on the line just before the declaration. A blank line or any other
+// token ends the sequence. Comments are recognized only in files written in
+// the native syntax; for other files the result is the same as for
+// hclschema.DecodeSchema.
+func DecodeSchema(file *hcl.File) (*hclschema.Body, hcl.Diagnostics) {
+	schema, diags := hclschema.DecodeSchema(file.Body)
+	if schema == nil {
+		return nil, diags
+	}
+
+	if body, ok := file.Body.(*hclsyntax.Body); ok {
+		tokens, lexDiags := hclsyntax.LexConfig(file.Bytes, body.SrcRange.Filename, hcl.InitialPos)
+		if !lexDiags.HasErrors() {
+			applyComments(tokens, body, schema)
+		}
+	}
+	return schema, diags
+}
+
+// applyComments sets the descriptions of the declarations in the given
+// schema that have none from the leading comments of the corresponding
+// blocks in the given body, recursing into nested block declarations.
+func applyComments(tokens hclsyntax.Tokens, body *hclsyntax.Body, schema *hclschema.Body) {
+	seenAttrs := make(map[string]bool)
+	seenBlocks := make(map[string]bool)
+	for _, block := range body.Blocks {
+		if len(block.Labels) != 1 {
+			continue
+		}
+		name := block.Labels[0]
+		switch block.Type {
+		case "attribute":
+			attrS := schema.Attribute(name)
+			if attrS == nil || seenAttrs[name] {
+				continue
+			}
+			seenAttrs[name] = true
+			if attrS.Description == "" {
+				attrS.Description = leadingComment(tokens, block.TypeRange.Start.Byte)
+			}
+		case "block":
+			blockS := schema.Block(name)
+			if blockS == nil || seenBlocks[name] {
+				continue
+			}
+			seenBlocks[name] = true
+			if blockS.Description == "" {
+				blockS.Description = leadingComment(tokens, block.TypeRange.Start.Byte)
+			}
+			if blockS.Body != nil {
+				applyComments(tokens, block.Body, blockS.Body)
+			}
+		}
+	}
+}
+
+// leadingComment returns the text of the comments leading the token that
+// starts at the given byte offset, with each comment on its own line, or
+// an empty string if there are none.
+func leadingComment(tokens hclsyntax.Tokens, byteOfs int) string {
+	i := sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].Range.Start.Byte >= byteOfs
+	})
+	if i == len(tokens) || tokens[i].Range.Start.Byte != byteOfs {
+		return ""
+	}
+
+	var texts []string
+	line := tokens[i].Range.Start.Line
+	for j := i - 1; j >= 0; j-- {
+		tok := tokens[j]
+		endLine := tok.Range.End.Line
+
+		// A line comment includes its terminating newline, but a block
+		// comment is followed by a separate newline token.
+		if tok.Type == hclsyntax.TokenNewline && j > 0 && tokens[j-1].CommentStyle() == hclsyntax.CommentBlock {
+			j--
+			tok = tokens[j]
+		} else if style := tok.CommentStyle(); style != hclsyntax.CommentHash && style != hclsyntax.CommentSlashes {
+			break
+		}
+		if endLine != line || !startsLine(tokens, j) {
+			break
+		}
+		texts = append(texts, tok.CommentText())
+		line = tok.Range.Start.Line
+	}
+
+	for l, r := 0, len(texts)-1; l < r; l, r = l+1, r-1 {
+		texts[l], texts[r] = texts[r], texts[l]
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// startsLine returns true if the token at the given index is the first on
+// its line.
+func startsLine(tokens hclsyntax.Tokens, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := tokens[i-1].Range.End
+	return prev.Line < tokens[i].Range.Start.Line || prev.Column == 1
+}
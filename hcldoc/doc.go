@@ -0,0 +1,21 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hcldoc generates Markdown documentation from schemas written in
+// HCL, as understood by the "hclschema" package.
+//
+// Teams that publish the configuration language of their application can
+// keep its reference documentation alongside the schema itself, either in
+// the "description" argument of each declaration or, more conveniently for
+// longer text, in the comments that lead each "attribute" and "block"
+// block:
+//
+//	# The name of the service, which must be unique within the
+//	# deployment.
+//	attribute "name" {
+//	  type     = string
+//	  required = true
+//	}
+//
+// The cmd/hcldoc program wraps this package for use from the command line.
+package hcldoc
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldoc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestMarkdown(t *testing.T) {
+	src := `# The name of the service, which must be
+# unique within the deployment.
+attribute "name" {
+  type     = string
+  required = true
+  validate = "min=1,max=64"
+}
+
+attribute "tags" { # not a leading comment
+  type        = map(string)
+  description = "Arbitrary labels."
+}
+
+# Detached comment.
+
+attribute "debug" {
+  type = bool
+}
+
+/*
+ * A network listener.
+ *
+ * Each listener accepts connections on one port.
+ */
+block "listener" {
+  labels    = ["protocol"]
+  min_items = 1
+  max_items = 4
+
+  // The port to listen on.
+  attribute "port" {
+    type     = number
+    required = true
+  }
+
+  block "tls" {
+    max_items = 1
+
+    attribute "cert" { // trailing
+      type = string
+    }
+  }
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "schema.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	schema, diags := DecodeSchema(file)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := string(Markdown("Service", schema))
+	want := "# Service\n" +
+		"\n" +
+		"## Attributes\n" +
+		"\n" +
+		"- `name` (string, required): The name of the service, which must be\n" +
+		"  unique within the deployment.\n" +
+		"\n" +
+		"  Constraints: minimum 1; maximum 64.\n" +
+		"- `tags` (map(string)): Arbitrary labels.\n" +
+		"- `debug` (bool)\n" +
+		"\n" +
+		"## Blocks\n" +
+		"\n" +
+		"### `listener`\n" +
+		"\n" +
+		"Labels: `protocol`.\n" +
+		"\n" +
+		"Must appear between 1 and 4 times.\n" +
+		"\n" +
+		"A network listener.\n" +
+		"\n" +
+		"Each listener accepts connections on one port.\n" +
+		"\n" +
+		"- `port` (number, required): The port to listen on.\n" +
+		"\n" +
+		"### `listener.tls`\n" +
+		"\n" +
+		"May appear at most once.\n" +
+		"\n" +
+		"- `cert` (string)\n"
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcldoc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+// Markdown returns Markdown documentation for the given schema, under a
+// top-level heading with the given title. If the title is empty then the
+// heading is omitted.
+//
+// The attributes of the top-level body are listed first, followed by a
+// section for each block type. Nested block types have sections of their
+// own, following that of the block type that contains them, with headings
+// that give the full path to the block type like "service.listener".
+func Markdown(title string, schema *hclschema.Body) []byte {
+	var buf bytes.Buffer
+	if title != "" {
+		fmt.Fprintf(&buf, "# %s\n\n", title)
+	}
+
+	if schema != nil && len(schema.Attributes) > 0 {
+		buf.WriteString("## Attributes\n\n")
+		writeAttributes(&buf, schema.Attributes)
+	}
+	if schema != nil && len(schema.Blocks) > 0 {
+		buf.WriteString("## Blocks\n\n")
+		writeBlocks(&buf, "", schema.Blocks)
+	}
+
+	ret := bytes.TrimRight(buf.Bytes(), "\n")
+	if len(ret) == 0 {
+		return nil
+	}
+	return append(ret, '\n')
+}
+
+func writeAttributes(buf *bytes.Buffer, attrs []*hclschema.Attribute) {
+	for _, attrS := range attrs {
+		details := []string{typeexpr.TypeString(attrS.Type)}
+		if attrS.Required {
+			details = append(details, "required")
+		}
+		fmt.Fprintf(buf, "- `%s` (%s)", attrS.Name, strings.Join(details, ", "))
+		if attrS.Description != "" {
+			buf.WriteString(": ")
+			writeIndented(buf, attrS.Description)
+		}
+		buf.WriteString("\n")
+		if text := constraintsText(attrS.Constraints); text != "" {
+			fmt.Fprintf(buf, "\n  %s\n", text)
+		}
+	}
+	buf.WriteString("\n")
+}
+
+func writeBlocks(buf *bytes.Buffer, prefix string, blocks []*hclschema.Block) {
+	for _, blockS := range blocks {
+		path := prefix + blockS.Type
+		fmt.Fprintf(buf, "### `%s`\n\n", path)
+
+		if len(blockS.LabelNames) > 0 {
+			labels := make([]string, len(blockS.LabelNames))
+			for i, name := range blockS.LabelNames {
+				labels[i] = "`" + name + "`"
+			}
+			fmt.Fprintf(buf, "Labels: %s.\n\n", strings.Join(labels, ", "))
+		}
+		if count := countText(blockS.MinItems, blockS.MaxItems); count != "" {
+			fmt.Fprintf(buf, "%s\n\n", count)
+		}
+		if blockS.Description != "" {
+			fmt.Fprintf(buf, "%s\n\n", blockS.Description)
+		}
+		if blockS.Body != nil && len(blockS.Body.Attributes) > 0 {
+			writeAttributes(buf, blockS.Body.Attributes)
+		}
+		if blockS.Body != nil {
+			writeBlocks(buf, path+".", blockS.Body.Blocks)
+		}
+	}
+}
+
+// writeIndented writes the given text, indenting all lines but the first so
+// that they continue a list item.
+func writeIndented(buf *bytes.Buffer, text string) {
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			buf.WriteString("\n")
+			if line != "" {
+				buf.WriteString("  ")
+			}
+		}
+		buf.WriteString(line)
+	}
+}
+
+// countText describes the given limits on the number of blocks of a type.
+func countText(min, max int) string {
+	switch {
+	case min > 0 && min == max:
+		return fmt.Sprintf("Must appear exactly %s.", times(min))
+	case min > 0 && max > 0:
+		return fmt.Sprintf("Must appear between %d and %d times.", min, max)
+	case min > 0:
+		return fmt.Sprintf("Must appear at least %s.", times(min))
+	case max > 0:
+		return fmt.Sprintf("May appear at most %s.", times(max))
+	default:
+		return ""
+	}
+}
+
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+	return fmt.Sprintf("%d times", n)
+}
+
+// constraintsText describes the given constraints on an attribute value, or
+// returns an empty string if there are none.
+func constraintsText(c *constraint.Constraints) string {
+	if c == nil {
+		return ""
+	}
+	var parts []string
+	if c.Min != nil {
+		parts = append(parts, "minimum "+c.Min.Text('g', -1))
+	}
+	if c.Max != nil {
+		parts = append(parts, "maximum "+c.Max.Text('g', -1))
+	}
+	if len(c.OneOf) > 0 {
+		quoted := make([]string, len(c.OneOf))
+		for i, v := range c.OneOf {
+			quoted[i] = "`" + v + "`"
+		}
+		parts = append(parts, "one of "+strings.Join(quoted, ", "))
+	}
+	if c.Regex != nil {
+		parts = append(parts, "matching `"+c.Regex.String()+"`")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Constraints: " + strings.Join(parts, "; ") + "."
+}
@@ -0,0 +1,443 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// CompletionKind is the kind of item that a Completion inserts.
+type CompletionKind int
+
+const (
+	CompletionAttribute CompletionKind = iota
+	CompletionBlock
+	CompletionValue
+)
+
+func (k CompletionKind) String() string {
+	switch k {
+	case CompletionAttribute:
+		return "attribute"
+	case CompletionBlock:
+		return "block"
+	case CompletionValue:
+		return "value"
+	default:
+		return "unknown"
+	}
+}
+
+// Completion is a candidate for the text to insert at a cursor position.
+type Completion struct {
+	Kind CompletionKind
+
+	// Label is the text to show for the candidate in a list of completions,
+	// such as the name of an attribute or block type.
+	Label string
+
+	// Detail is a short description of the candidate, such as the type of
+	// an attribute, and Description is the description given in the schema.
+	// Either may be empty.
+	Detail      string
+	Description string
+
+	// InsertText is the text that replaces the source text in Range if the
+	// candidate is chosen. Range covers any partial identifier or string
+	// that the cursor is within, or is empty at the cursor if there is none.
+	InsertText string
+	Range      hcl.Range
+}
+
+// Completions scans the given native syntax source buffer and returns the
+// candidate completions at the given byte offset, according to the given
+// schema.
+//
+// At the start of an item in a body, the candidates are the attributes and
+// block types that the body's schema allows and that have not already
+// reached their limit in the body. In the value of an attribute, the
+// candidates are the values listed by a "oneof" constraint on the attribute,
+// or true and false for an attribute of type bool. Only the candidates that
+// start with any partial identifier or string before the cursor are
+// returned, in the order they are declared in the schema.
+//
+// The source is only scanned, not parsed, so that completions are available
+// even in the incomplete source that an editor typically holds while the
+// user is typing. No completions are returned for positions within bodies of
+// block types that the schema doesn't describe, nor for offsets outside of
+// the source buffer.
+func Completions(src []byte, filename string, offset int, schema *hclschema.Body) []Completion {
+	if offset < 0 || offset > len(src) {
+		return nil
+	}
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.InitialPos)
+
+	sc := newCompletionScanner(src, filename, offset)
+	for _, tok := range tokens {
+		sc.token(tok)
+	}
+	cur := sc.cursor
+	if cur == nil || len(cur.frames) == 0 {
+		return nil
+	}
+
+	// Find the schema for the body that the cursor is within.
+	var body *completionFrame
+	for _, frame := range cur.frames {
+		if !frame.body {
+			continue
+		}
+		if frame.blockType != "" {
+			blockS := schema.Block(frame.blockType)
+			if blockS == nil {
+				return nil
+			}
+			schema = blockS.Body
+		}
+		body = frame
+	}
+	if schema == nil {
+		return nil
+	}
+
+	top := cur.frames[len(cur.frames)-1]
+	switch {
+	case top.body && len(cur.item) == 0:
+		return nameCompletions(schema, body, cur)
+	case top.body && len(cur.item) == 2 && isAttrStart(cur.item):
+		attrS := schema.Attribute(string(cur.item[0].Bytes))
+		return valueCompletions(attrS, false, cur)
+	case top.closer == hclsyntax.TokenCQuote && len(cur.item) == 0 &&
+		len(cur.parentItem) == 3 && isAttrStart(cur.parentItem):
+		attrS := schema.Attribute(string(cur.parentItem[0].Bytes))
+		return valueCompletions(attrS, true, cur)
+	default:
+		return nil
+	}
+}
+
+func nameCompletions(schema *hclschema.Body, body *completionFrame, cur *completionCursor) []Completion {
+	if cur.partial != nil && cur.partial.Type != hclsyntax.TokenIdent {
+		return nil
+	}
+	indent := lineIndent(cur.src, cur.rng.Start.Byte)
+
+	var ret []Completion
+	for _, attrS := range schema.Attributes {
+		if !strings.HasPrefix(attrS.Name, cur.prefix) || body.hasOther(attrS.Name, cur.partial) {
+			continue
+		}
+		details := []string{typeexpr.TypeString(attrS.Type)}
+		if attrS.Required {
+			details = append(details, "required")
+		}
+		ret = append(ret, Completion{
+			Kind:        CompletionAttribute,
+			Label:       attrS.Name,
+			Detail:      strings.Join(details, ", "),
+			Description: attrS.Description,
+			InsertText:  attrS.Name + " = ",
+			Range:       cur.rng,
+		})
+	}
+	for _, blockS := range schema.Blocks {
+		if !strings.HasPrefix(blockS.Type, cur.prefix) {
+			continue
+		}
+		if blockS.MaxItems > 0 && body.countOther(blockS.Type, cur.partial) >= blockS.MaxItems {
+			continue
+		}
+		var buf strings.Builder
+		buf.WriteString(blockS.Type)
+		for range blockS.LabelNames {
+			buf.WriteString(` ""`)
+		}
+		fmt.Fprintf(&buf, " {\n%s}", indent)
+		ret = append(ret, Completion{
+			Kind:        CompletionBlock,
+			Label:       blockS.Type,
+			Detail:      "block",
+			Description: blockS.Description,
+			InsertText:  buf.String(),
+			Range:       cur.rng,
+		})
+	}
+	return ret
+}
+
+func valueCompletions(attrS *hclschema.Attribute, quoted bool, cur *completionCursor) []Completion {
+	if attrS == nil {
+		return nil
+	}
+	wantPartial := hclsyntax.TokenIdent
+	if quoted {
+		wantPartial = hclsyntax.TokenQuotedLit
+	}
+	if cur.partial != nil && cur.partial.Type != wantPartial {
+		return nil
+	}
+
+	var values []string
+	switch {
+	case attrS.Constraints != nil && len(attrS.Constraints.OneOf) > 0:
+		values = attrS.Constraints.OneOf
+	case attrS.Type == cty.Bool:
+		values = []string{"true", "false"}
+	}
+
+	var ret []Completion
+	for _, value := range values {
+		if !strings.HasPrefix(value, cur.prefix) {
+			continue
+		}
+		insert := value
+		if !quoted && attrS.Type != cty.Number && attrS.Type != cty.Bool {
+			insert = fmt.Sprintf("%q", value)
+		}
+		ret = append(ret, Completion{
+			Kind:        CompletionValue,
+			Label:       value,
+			Detail:      typeexpr.TypeString(attrS.Type),
+			Description: attrS.Description,
+			InsertText:  insert,
+			Range:       cur.rng,
+		})
+	}
+	return ret
+}
+
+// isAttrStart returns true if the given tokens begin with an attribute name
+// and its equals sign.
+func isAttrStart(item []hclsyntax.Token) bool {
+	return item[0].Type == hclsyntax.TokenIdent && item[1].Type == hclsyntax.TokenEqual
+}
+
+// lineIndent returns the leading whitespace of the line containing the given
+// byte offset.
+func lineIndent(src []byte, ofs int) string {
+	start := ofs
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+// completionFrame is a bracketed construct that the scanner is within: a
+// body, or some other construct that is closed by a particular token.
+type completionFrame struct {
+	body      bool
+	blockType string // empty for the top-level body and non-body frames
+	closer    hclsyntax.TokenType
+
+	// item is the tokens of the current item in the frame.
+	item []hclsyntax.Token
+
+	// For bodies, the names of the attributes and blocks in the body and the
+	// ranges of the tokens that name them.
+	attrs  map[string][]hcl.Range
+	blocks map[string][]hcl.Range
+}
+
+// hasOther returns true if the body has an attribute with the given name
+// other than one named by the given token.
+func (f *completionFrame) hasOther(name string, tok *hclsyntax.Token) bool {
+	return countOther(f.attrs[name], tok) > 0
+}
+
+// countOther returns the number of blocks of the given type in the body,
+// other than one whose type is given by the given token.
+func (f *completionFrame) countOther(typeName string, tok *hclsyntax.Token) int {
+	return countOther(f.blocks[typeName], tok)
+}
+
+func countOther(rngs []hcl.Range, tok *hclsyntax.Token) int {
+	n := len(rngs)
+	if tok != nil {
+		for _, rng := range rngs {
+			if rng == tok.Range {
+				n--
+			}
+		}
+	}
+	return n
+}
+
+// completionCursor is the state of the scanner at the cursor.
+type completionCursor struct {
+	src        []byte
+	frames     []*completionFrame
+	item       []hclsyntax.Token
+	parentItem []hclsyntax.Token
+
+	// partial is the identifier or string literal token that the cursor is
+	// within or at the end of, if any, and prefix is the part of it before
+	// the cursor. rng is the range to replace with a completion.
+	partial *hclsyntax.Token
+	prefix  string
+	rng     hcl.Range
+}
+
+// completionScanner tracks the nesting of bodies and other bracketed
+// constructs through a sequence of tokens, recording the state at the
+// cursor.
+type completionScanner struct {
+	src      []byte
+	filename string
+	offset   int
+	frames   []*completionFrame
+	prev     *hclsyntax.Token
+	cursor   *completionCursor
+}
+
+// completionClosers maps the tokens that open non-body frames to the tokens
+// that close them.
+var completionClosers = map[hclsyntax.TokenType]hclsyntax.TokenType{
+	hclsyntax.TokenOBrace:          hclsyntax.TokenCBrace,
+	hclsyntax.TokenOBrack:          hclsyntax.TokenCBrack,
+	hclsyntax.TokenOParen:          hclsyntax.TokenCParen,
+	hclsyntax.TokenOQuote:          hclsyntax.TokenCQuote,
+	hclsyntax.TokenOHeredoc:        hclsyntax.TokenCHeredoc,
+	hclsyntax.TokenTemplateInterp:  hclsyntax.TokenTemplateSeqEnd,
+	hclsyntax.TokenTemplateControl: hclsyntax.TokenTemplateSeqEnd,
+}
+
+func newCompletionScanner(src []byte, filename string, offset int) *completionScanner {
+	return &completionScanner{
+		src:      src,
+		filename: filename,
+		offset:   offset,
+		frames:   []*completionFrame{newBodyFrame("")},
+	}
+}
+
+func newBodyFrame(blockType string) *completionFrame {
+	return &completionFrame{
+		body:      true,
+		blockType: blockType,
+		closer:    hclsyntax.TokenCBrace,
+		attrs:     make(map[string][]hcl.Range),
+		blocks:    make(map[string][]hcl.Range),
+	}
+}
+
+func (s *completionScanner) token(tok hclsyntax.Token) {
+	if s.cursor == nil {
+		start, end := tok.Range.Start.Byte, tok.Range.End.Byte
+		switch {
+		case start >= s.offset || tok.Type == hclsyntax.TokenEOF:
+			s.saveCursor(nil)
+		case end >= s.offset && (tok.Type == hclsyntax.TokenIdent || tok.Type == hclsyntax.TokenQuotedLit):
+			s.saveCursor(&tok)
+		case end > s.offset:
+			// The cursor is within some other token, such as a comment or a
+			// number, where there is nothing to complete. A cursor with no
+			// frames records that.
+			s.cursor = &completionCursor{}
+		}
+	}
+	s.prev = &tok
+
+	top := s.frames[len(s.frames)-1]
+	switch {
+	case tok.Type == hclsyntax.TokenNewline || isLineComment(tok):
+		if top.body {
+			top.item = nil
+		}
+	case tok.Type == hclsyntax.TokenQuotedNewline:
+		// A newline ends an unterminated string.
+		if top.closer == hclsyntax.TokenCQuote && len(s.frames) > 1 {
+			s.frames = s.frames[:len(s.frames)-1]
+			if parent := s.frames[len(s.frames)-1]; parent.body {
+				parent.item = nil
+			}
+		}
+	case tok.Type == hclsyntax.TokenOBrace && top.body && isBlockHeader(top.item):
+		typeTok := top.item[0]
+		top.blocks[string(typeTok.Bytes)] = append(top.blocks[string(typeTok.Bytes)], typeTok.Range)
+		s.frames = append(s.frames, newBodyFrame(string(typeTok.Bytes)))
+	case completionClosers[tok.Type] != hclsyntax.TokenNil:
+		top.item = append(top.item, tok)
+		s.frames = append(s.frames, &completionFrame{closer: completionClosers[tok.Type]})
+	case tok.Type == top.closer && len(s.frames) > 1:
+		s.frames = s.frames[:len(s.frames)-1]
+		parent := s.frames[len(s.frames)-1]
+		if top.body {
+			parent.item = nil
+		} else {
+			parent.item = append(parent.item, tok)
+		}
+	default:
+		top.item = append(top.item, tok)
+		if top.body && len(top.item) == 2 && isAttrStart(top.item) {
+			nameTok := top.item[0]
+			top.attrs[string(nameTok.Bytes)] = append(top.attrs[string(nameTok.Bytes)], nameTok.Range)
+		}
+	}
+}
+
+// saveCursor records the state of the scanner at the cursor, which is within
+// or at the end of the given token if it is not nil.
+func (s *completionScanner) saveCursor(partial *hclsyntax.Token) {
+	top := s.frames[len(s.frames)-1]
+	cur := &completionCursor{
+		src:    s.src,
+		frames: append([]*completionFrame(nil), s.frames...),
+		item:   append([]hclsyntax.Token(nil), top.item...),
+	}
+	if len(s.frames) > 1 {
+		cur.parentItem = append([]hclsyntax.Token(nil), s.frames[len(s.frames)-2].item...)
+	}
+
+	if partial != nil {
+		cur.partial = partial
+		cur.prefix = string(s.src[partial.Range.Start.Byte:s.offset])
+		cur.rng = partial.Range
+	} else {
+		// The range is empty, at the cursor. Only spaces and tabs separate
+		// the cursor from the end of the previous token.
+		pos := hcl.InitialPos
+		if s.prev != nil {
+			pos = s.prev.Range.End
+		}
+		pos.Column += utf8.RuneCount(s.src[pos.Byte:s.offset])
+		pos.Byte = s.offset
+		cur.rng = hcl.Range{Filename: s.filename, Start: pos, End: pos}
+	}
+	s.cursor = cur
+}
+
+// isBlockHeader returns true if the given tokens are a block type followed
+// by any number of labels.
+func isBlockHeader(item []hclsyntax.Token) bool {
+	if len(item) == 0 || item[0].Type != hclsyntax.TokenIdent {
+		return false
+	}
+	for _, tok := range item[1:] {
+		switch tok.Type {
+		case hclsyntax.TokenIdent, hclsyntax.TokenOQuote, hclsyntax.TokenQuotedLit, hclsyntax.TokenCQuote:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isLineComment returns true if the given token is a comment that ends its
+// line, which the lexer includes in the comment token.
+func isLineComment(tok hclsyntax.Token) bool {
+	return tok.Type == hclsyntax.TokenComment && len(tok.Bytes) > 0 && tok.Bytes[len(tok.Bytes)-1] == '\n'
+}
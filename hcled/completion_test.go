@@ -0,0 +1,175 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+func TestCompletions(t *testing.T) {
+	schema := &hclschema.Body{
+		Attributes: []*hclschema.Attribute{
+			{Name: "name", Type: cty.String, Required: true},
+			{Name: "namespace", Type: cty.String},
+			{Name: "debug", Type: cty.Bool},
+		},
+		Blocks: []*hclschema.Block{
+			{
+				Type:       "listener",
+				LabelNames: []string{"name"},
+				Body: &hclschema.Body{
+					Attributes: []*hclschema.Attribute{
+						{
+							Name:        "protocol",
+							Type:        cty.String,
+							Constraints: &constraint.Constraints{OneOf: []string{"tcp", "udp", "tls"}},
+						},
+						{Name: "port", Type: cty.Number, Required: true},
+					},
+				},
+			},
+			{Type: "logging", MaxItems: 1},
+		},
+	}
+
+	tests := map[string]struct {
+		src  string // the cursor is at the "|"
+		want []string
+	}{
+		"empty file": {
+			"|",
+			[]string{
+				`attribute "name" (string, required) "name = " @1:1-1:1`,
+				`attribute "namespace" (string) "namespace = " @1:1-1:1`,
+				`attribute "debug" (bool) "debug = " @1:1-1:1`,
+				`block "listener" (block) "listener \"\" {\n}" @1:1-1:1`,
+				`block "logging" (block) "logging {\n}" @1:1-1:1`,
+			},
+		},
+		"partial name": {
+			"name = \"a\"\nna|",
+			[]string{
+				`attribute "namespace" (string) "namespace = " @2:1-2:3`,
+			},
+		},
+		"within partial name": {
+			"de|bu\n",
+			[]string{
+				`attribute "debug" (bool) "debug = " @1:1-1:5`,
+			},
+		},
+		"limited block": {
+			"logging {}\nl|\n",
+			[]string{
+				`block "listener" (block) "listener \"\" {\n}" @2:1-2:2`,
+			},
+		},
+		"nested body": {
+			"listener \"a\" {\n  port = 80\n  |\n}\n",
+			[]string{
+				`attribute "protocol" (string) "protocol = " @3:3-3:3`,
+			},
+		},
+		"indented block": {
+			"  lo|",
+			[]string{
+				`block "logging" (block) "logging {\n  }" @1:3-1:5`,
+			},
+		},
+		"enum value": {
+			"listener \"a\" {\n  protocol = t|\n}\n",
+			[]string{
+				`value "tcp" (string) "\"tcp\"" @2:14-2:15`,
+				`value "tls" (string) "\"tls\"" @2:14-2:15`,
+			},
+		},
+		"quoted enum value": {
+			"listener \"a\" {\n  protocol = \"u|\n}\n",
+			[]string{
+				`value "udp" (string) "udp" @2:15-2:16`,
+			},
+		},
+		"empty quoted enum value": {
+			"listener \"a\" {\n  protocol = \"|\"\n}\n",
+			[]string{
+				`value "tcp" (string) "tcp" @2:15-2:15`,
+				`value "udp" (string) "udp" @2:15-2:15`,
+				`value "tls" (string) "tls" @2:15-2:15`,
+			},
+		},
+		"bool value": {
+			"debug = |",
+			[]string{
+				`value "true" (bool) "true" @1:9-1:9`,
+				`value "false" (bool) "false" @1:9-1:9`,
+			},
+		},
+		"after value": {
+			"debug = true |",
+			nil,
+		},
+		"within comment": {
+			"# na|\n",
+			nil,
+		},
+		"within object": {
+			"debug = {\n  |\n}\n",
+			nil,
+		},
+		"unknown block type": {
+			"other {\n  |\n}\n",
+			nil,
+		},
+		"after closed block": {
+			"logging {\n}\n|",
+			[]string{
+				`attribute "name" (string, required) "name = " @3:1-3:1`,
+				`attribute "namespace" (string) "namespace = " @3:1-3:1`,
+				`attribute "debug" (bool) "debug = " @3:1-3:1`,
+				`block "listener" (block) "listener \"\" {\n}" @3:1-3:1`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			offset := strings.Index(test.src, "|")
+			src := test.src[:offset] + test.src[offset+1:]
+
+			var got []string
+			for _, c := range Completions([]byte(src), "test.hcl", offset, schema) {
+				got = append(got, fmt.Sprintf("%s %q (%s) %q @%d:%d-%d:%d",
+					c.Kind, c.Label, c.Detail, c.InsertText,
+					c.Range.Start.Line, c.Range.Start.Column, c.Range.End.Line, c.Range.End.Column,
+				))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong completions\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompletionsOutOfRange(t *testing.T) {
+	schema := &hclschema.Body{
+		Attributes: []*hclschema.Attribute{
+			{Name: "name", Type: cty.String},
+		},
+	}
+	for _, offset := range []int{-1, 3, 5} {
+		t.Run(fmt.Sprint(offset), func(t *testing.T) {
+			if got := Completions([]byte("na"), "test.hcl", offset, schema); got != nil {
+				t.Errorf("unexpected completions %#v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// DefinitionRule describes a kind of definition that references in
+// expressions can refer to, in the language of a particular application.
+type DefinitionRule struct {
+	// Root is the name of the root variable of references to definitions of
+	// this kind, such as "var". If Root is empty, references instead start
+	// with the first label of the defining block, as with Terraform's
+	// references to resources like aws_instance.web.
+	Root string
+
+	// BlockType is the type of the top-level blocks that make definitions
+	// of this kind, such as "variable". Each block is a definition named by
+	// its labels, so that a block variable "region" {} is referred to as
+	// var.region.
+	BlockType string
+
+	// Attributes, if set, causes each attribute within the blocks of
+	// BlockType to be a definition named by the attribute's name, rather
+	// than the blocks themselves, as for blocks like "locals".
+	Attributes bool
+}
+
+// Definition is a named item that references can refer to.
+type Definition struct {
+	// Name is the name of the definition as written in references, such as
+	// "var.region".
+	Name string
+
+	// Range is the range of the whole definition, and NameRange is the range
+	// of the labels or attribute name that name it.
+	Range     hcl.Range
+	NameRange hcl.Range
+}
+
+// Reference is a reference to a definition from an expression.
+type Reference struct {
+	// Name is the name of the definition referred to.
+	Name string
+
+	// Range is the range of the part of the reference that gives the name
+	// of the definition, excluding any further attributes or indices.
+	Range hcl.Range
+
+	// Definition is the definition referred to, or nil if there is no such
+	// definition.
+	Definition *Definition
+}
+
+// DefinitionIndex maps the references in a set of files to the definitions
+// that they refer to, as a basis for features like jump-to-definition.
+type DefinitionIndex struct {
+	rules []DefinitionRule
+	defs  map[string]*Definition
+
+	// refs are in order of filename and then position.
+	refs []*Reference
+
+	// depths records the number of names in the definitions with each root
+	// of a rule, for describing references to undefined items.
+	depths map[string]int
+}
+
+// NewDefinitionIndex returns an index of the definitions made, according to
+// the given rules, by the top-level blocks in the given files, and of the
+// references to them from all of the expressions in the files.
+//
+// Only files in the native syntax are indexed, since the structure of JSON
+// files can't be determined without a schema. If more than one item makes a
+// definition with the same name, the first in order of filename and then
+// position is used.
+func NewDefinitionIndex(files map[string]*hcl.File, rules []DefinitionRule) *DefinitionIndex {
+	idx := &DefinitionIndex{
+		rules:  rules,
+		defs:   make(map[string]*Definition),
+		depths: make(map[string]int),
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var bodies []*hclsyntax.Body
+	for _, filename := range filenames {
+		if body, ok := files[filename].Body.(*hclsyntax.Body); ok {
+			bodies = append(bodies, body)
+		}
+	}
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			idx.define(block)
+		}
+	}
+	for _, body := range bodies {
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if attr, ok := node.(*hclsyntax.Attribute); ok {
+				for _, traversal := range attr.Expr.Variables() {
+					if ref := idx.resolve(traversal); ref != nil {
+						idx.refs = append(idx.refs, ref)
+					}
+				}
+			}
+			return nil
+		})
+	}
+	sort.SliceStable(idx.refs, func(i, j int) bool {
+		a, b := idx.refs[i].Range, idx.refs[j].Range
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Start.Byte < b.Start.Byte
+	})
+	return idx
+}
+
+// define adds the definitions made by the given top-level block.
+func (idx *DefinitionIndex) define(block *hclsyntax.Block) {
+	for _, rule := range idx.rules {
+		if block.Type != rule.BlockType {
+			continue
+		}
+
+		var prefix []string
+		if rule.Root != "" {
+			prefix = []string{rule.Root}
+		}
+		if rule.Attributes {
+			for _, attr := range hclsyntaxutil.SortedAttributes(block.Body) {
+				idx.add(append(prefix, attr.Name), &Definition{
+					Range:     attr.SrcRange,
+					NameRange: attr.NameRange,
+				})
+			}
+			continue
+		}
+		if len(block.Labels) == 0 {
+			continue
+		}
+		idx.add(append(prefix, block.Labels...), &Definition{
+			Range:     block.Range(),
+			NameRange: hcl.RangeBetween(block.LabelRanges[0], block.LabelRanges[len(block.LabelRanges)-1]),
+		})
+	}
+}
+
+func (idx *DefinitionIndex) add(names []string, def *Definition) {
+	def.Name = strings.Join(names, ".")
+	if _, exists := idx.defs[def.Name]; exists {
+		return
+	}
+	idx.defs[def.Name] = def
+	if len(names) > idx.depths[names[0]] {
+		idx.depths[names[0]] = len(names)
+	}
+}
+
+// resolve returns the reference made by the given traversal, or nil if it
+// does not refer to a definition of any kind described by the rules.
+func (idx *DefinitionIndex) resolve(traversal hcl.Traversal) *Reference {
+	names := traversalNames(traversal)
+
+	// A reference may continue beyond the name of the definition, to
+	// attributes of the defined item, and so we look for the longest
+	// prefix that is a definition.
+	for n := len(names); n > 0; n-- {
+		name := strings.Join(names[:n], ".")
+		if def, exists := idx.defs[name]; exists {
+			return &Reference{
+				Name:       name,
+				Range:      hcl.RangeBetween(traversal[0].SourceRange(), traversal[n-1].SourceRange()),
+				Definition: def,
+			}
+		}
+	}
+
+	// References with the root of a rule must refer to something, and so
+	// we record them as references to undefined items.
+	if !idx.hasRoot(names[0]) {
+		return nil
+	}
+	n := idx.depths[names[0]]
+	if n == 0 {
+		n = 2
+	}
+	if n > len(names) {
+		n = len(names)
+	}
+	return &Reference{
+		Name:  strings.Join(names[:n], "."),
+		Range: hcl.RangeBetween(traversal[0].SourceRange(), traversal[n-1].SourceRange()),
+	}
+}
+
+func (idx *DefinitionIndex) hasRoot(root string) bool {
+	for _, rule := range idx.rules {
+		if rule.Root != "" && rule.Root == root {
+			return true
+		}
+	}
+	return false
+}
+
+// Definition returns the definition with the given name, or nil if there is
+// no such definition.
+func (idx *DefinitionIndex) Definition(name string) *Definition {
+	return idx.defs[name]
+}
+
+// ReferenceAt returns the reference at the given byte offset in the file
+// with the given name, or nil if there is none.
+func (idx *DefinitionIndex) ReferenceAt(filename string, offset int) *Reference {
+	for _, ref := range idx.refs {
+		if ref.Range.Filename == filename && ref.Range.ContainsOffset(offset) {
+			return ref
+		}
+	}
+	return nil
+}
+
+// DefinitionAt returns the definition referred to by the reference at the
+// given byte offset in the file with the given name, or nil if there is no
+// reference there or if it refers to an undefined item.
+func (idx *DefinitionIndex) DefinitionAt(filename string, offset int) *Definition {
+	if ref := idx.ReferenceAt(filename, offset); ref != nil {
+		return ref.Definition
+	}
+	return nil
+}
+
+// References returns all of the references to the given definition, in
+// order of filename and then position.
+func (idx *DefinitionIndex) References(def *Definition) []*Reference {
+	var ret []*Reference
+	for _, ref := range idx.refs {
+		if ref.Definition == def {
+			ret = append(ret, ref)
+		}
+	}
+	return ret
+}
+
+// UndefinedReferences returns an error diagnostic for each reference that
+// has the root of one of the index's rules but refers to an item that is
+// not defined, suggesting a similar name that is defined where there is one.
+func (idx *DefinitionIndex) UndefinedReferences() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, ref := range idx.refs {
+		if ref.Definition != nil {
+			continue
+		}
+
+		detail := fmt.Sprintf("There is no definition named %q.", ref.Name)
+		if suggestion := nameSuggestion(ref.Name, idx.names(ref.Name)); suggestion != "" {
+			detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Reference to undefined item",
+			Detail:   detail,
+			Subject:  ref.Range.Ptr(),
+		})
+	}
+	return diags
+}
+
+// names returns the sorted names of the definitions with the same root as
+// the given name.
+func (idx *DefinitionIndex) names(name string) []string {
+	prefix := strings.SplitN(name, ".", 2)[0] + "."
+	var ret []string
+	for defName := range idx.defs {
+		if strings.HasPrefix(defName, prefix) {
+			ret = append(ret, defName)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// traversalNames returns the names of the root and the attributes that
+// follow it in the given absolute traversal, stopping at the first step that
+// is not an attribute name or a string index.
+func traversalNames(traversal hcl.Traversal) []string {
+	ret := []string{traversal.RootName()}
+	for _, step := range traversal[1:] {
+		switch step := step.(type) {
+		case hcl.TraverseAttr:
+			ret = append(ret, step.Name)
+		case hcl.TraverseIndex:
+			if step.Key.Type() != cty.String || !step.Key.IsKnown() || step.Key.IsNull() {
+				return ret
+			}
+			ret = append(ret, step.Key.AsString())
+		default:
+			return ret
+		}
+	}
+	return ret
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDefinitionIndex(t *testing.T) {
+	srcs := map[string]string{
+		"main.hcl": `
+variable "region" {}
+
+locals {
+  name = "web-${var.region}"
+}
+
+resource "instance" "web" {
+  name   = local.name
+  zone   = "${var.regoin}-a"
+  image  = data.image.base.id
+  parent = instance.web.id
+  items  = [for v in var.items : v]
+}
+`,
+		"vars.hcl": `
+variable "count" {}
+
+data "image" "base" {}
+
+output "size" {
+  value = var.count * 2
+  old   = data.image.old.id
+}
+`,
+	}
+	files := make(map[string]*hcl.File)
+	for filename, src := range srcs {
+		file, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		files[filename] = file
+	}
+
+	idx := NewDefinitionIndex(files, []DefinitionRule{
+		{Root: "var", BlockType: "variable"},
+		{Root: "local", BlockType: "locals", Attributes: true},
+		{Root: "data", BlockType: "data"},
+		{BlockType: "resource"},
+	})
+
+	t.Run("definitions", func(t *testing.T) {
+		def := idx.Definition("local.name")
+		if def == nil {
+			t.Fatal("no definition of local.name")
+		}
+		if got, want := def.NameRange.Start.Line, 5; got != want {
+			t.Errorf("wrong line %d for local.name; want %d", got, want)
+		}
+		if def := idx.Definition("instance.web"); def == nil || def.Range.Start.Line != 8 {
+			t.Errorf("wrong definition of instance.web: %#v", def)
+		}
+		if def := idx.Definition("var.nope"); def != nil {
+			t.Errorf("unexpected definition of var.nope: %#v", def)
+		}
+	})
+
+	t.Run("definition at", func(t *testing.T) {
+		src := srcs["main.hcl"]
+		offset := strings.Index(src, "local.name") + 7
+		def := idx.DefinitionAt("main.hcl", offset)
+		if def == nil || def.Name != "local.name" {
+			t.Fatalf("wrong definition %#v", def)
+		}
+
+		offset = strings.Index(src, "var.region}")
+		def = idx.DefinitionAt("main.hcl", offset)
+		if def == nil || def.Name != "var.region" || def.Range.Filename != "main.hcl" {
+			t.Fatalf("wrong definition %#v", def)
+		}
+
+		offset = strings.Index(srcs["vars.hcl"], "var.count")
+		def = idx.DefinitionAt("vars.hcl", offset)
+		if def == nil || def.Name != "var.count" {
+			t.Fatalf("wrong definition %#v", def)
+		}
+
+		offset = strings.Index(src, "data.image.base.id")
+		def = idx.DefinitionAt("main.hcl", offset)
+		if def == nil || def.Name != "data.image.base" || def.Range.Filename != "vars.hcl" {
+			t.Fatalf("wrong definition %#v", def)
+		}
+
+		if def := idx.DefinitionAt("main.hcl", strings.Index(src, "[for")); def != nil {
+			t.Errorf("unexpected definition %#v", def)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		ref := idx.ReferenceAt("main.hcl", strings.Index(srcs["main.hcl"], "instance.web.id"))
+		if ref == nil {
+			t.Fatal("no reference")
+		}
+		if got, want := ref.Range.End.Column-ref.Range.Start.Column, len("instance.web"); got != want {
+			t.Errorf("wrong reference length %d; want %d", got, want)
+		}
+
+		var got []string
+		for _, ref := range idx.References(idx.Definition("var.region")) {
+			got = append(got, ref.Range.String())
+		}
+		want := []string{"main.hcl:5,17-27"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong references\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		var got []string
+		for _, diag := range idx.UndefinedReferences() {
+			got = append(got, diag.Error())
+		}
+		want := []string{
+			`main.hcl:10,15-25: Reference to undefined item; There is no definition named "var.regoin". Did you mean "var.region"?`,
+			`main.hcl:13,22-31: Reference to undefined item; There is no definition named "var.items".`,
+			`vars.hcl:8,11-25: Reference to undefined item; There is no definition named "data.image.old".`,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lsp implements a Language Server Protocol server for generic HCL
+// files, suitable for use by any text editor that supports the protocol.
+//
+// The server has no knowledge of any particular application's schema, so it
+// offers only those features that can be derived from the syntax alone:
+// diagnostics for syntax errors, document symbols for blocks and attributes,
+// hover information, semantic tokens for highlighting, and formatting of
+// native syntax files. Files whose names end in ".json" are parsed as HCL
+// JSON, for which only diagnostics and document symbols are offered.
+//
+// The server communicates over a pair of streams using the base protocol's
+// Content-Length framing, as with most language servers. The cmd/hclls
+// program runs a server over its standard input and output.
+package lsp
@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+// document is an open text document along with the result of parsing it.
+type document struct {
+	URI      string
+	Filename string
+	Src      []byte
+	File     *hcl.File
+	Diags    hcl.Diagnostics
+
+	// lineStarts is the byte offset of the start of each line in Src, used
+	// to convert between byte offsets and protocol positions.
+	lineStarts []int
+}
+
+func newDocument(uri string, text string) *document {
+	doc := &document{
+		URI:      uri,
+		Filename: uriFilename(uri),
+		Src:      []byte(text),
+	}
+
+	if doc.IsJSON() {
+		doc.File, doc.Diags = json.Parse(doc.Src, doc.Filename)
+	} else {
+		doc.File, doc.Diags = hclsyntax.ParseConfig(doc.Src, doc.Filename, hcl.InitialPos)
+	}
+
+	doc.lineStarts = []int{0}
+	for i, b := range doc.Src {
+		if b == '\n' {
+			doc.lineStarts = append(doc.lineStarts, i+1)
+		}
+	}
+	return doc
+}
+
+// uriFilename returns the filename to use in diagnostics for the document
+// with the given URI, which is its path for a file URI.
+func uriFilename(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return u.Path
+}
+
+// IsJSON returns true if the document is to be parsed as HCL JSON.
+func (d *document) IsJSON() bool {
+	return strings.HasSuffix(d.Filename, ".json")
+}
+
+// Body returns the native syntax body of the document, or nil if it is not
+// a native syntax document.
+func (d *document) Body() *hclsyntax.Body {
+	if d.File == nil {
+		return nil
+	}
+	body, _ := d.File.Body.(*hclsyntax.Body)
+	return body
+}
+
+// Position converts the given byte offset into a protocol position, whose
+// character offset is measured in UTF-16 code units.
+func (d *document) Position(ofs int) position {
+	if ofs > len(d.Src) {
+		ofs = len(d.Src)
+	}
+	line := sort.Search(len(d.lineStarts), func(i int) bool {
+		return d.lineStarts[i] > ofs
+	}) - 1
+	if line < 0 {
+		line = 0
+	}
+
+	char := 0
+	for b := d.Src[d.lineStarts[line]:ofs]; len(b) > 0; {
+		r, size := utf8.DecodeRune(b)
+		char += utf16Len(r)
+		b = b[size:]
+	}
+	return position{Line: line, Character: char}
+}
+
+// Offset converts the given protocol position into a byte offset, clamping
+// it to the bounds of the document and of the given line.
+func (d *document) Offset(pos position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.Src)
+	}
+
+	ofs := d.lineStarts[pos.Line]
+	for char := 0; char < pos.Character && ofs < len(d.Src) && d.Src[ofs] != '\n'; {
+		r, size := utf8.DecodeRune(d.Src[ofs:])
+		char += utf16Len(r)
+		ofs += size
+	}
+	return ofs
+}
+
+// Range converts the given source range into a protocol range.
+func (d *document) Range(rng hcl.Range) lspRange {
+	return lspRange{
+		Start: d.Position(rng.Start.Byte),
+		End:   d.Position(rng.End.Byte),
+	}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,208 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// documentDiagnostics converts the diagnostics from parsing the given
+// document into protocol diagnostics.
+func documentDiagnostics(doc *document) []diagnostic {
+	ret := make([]diagnostic, 0, len(doc.Diags))
+	for _, diag := range doc.Diags {
+		severity := severityError
+		if diag.Severity == hcl.DiagWarning {
+			severity = severityWarning
+		}
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += ": " + diag.Detail
+		}
+
+		var rng lspRange
+		if diag.Subject != nil {
+			rng = doc.Range(*diag.Subject)
+		}
+		ret = append(ret, diagnostic{
+			Range:    rng,
+			Severity: severity,
+			Source:   "hcl",
+			Message:  message,
+		})
+	}
+	return ret
+}
+
+// documentSymbols returns the hierarchy of blocks and attributes in the
+// given document.
+func documentSymbols(doc *document) []documentSymbol {
+	if body := doc.Body(); body != nil {
+		return bodySymbols(doc, body)
+	}
+
+	// Without a schema we can't tell which JSON properties represent
+	// blocks, so we just report the top-level properties.
+	ret := []documentSymbol{}
+	if doc.File == nil {
+		return ret
+	}
+	attrs, _ := doc.File.Body.JustAttributes()
+	for _, attr := range attrs {
+		ret = append(ret, documentSymbol{
+			Name:           attr.Name,
+			Kind:           symbolKindProperty,
+			Range:          doc.Range(attr.Range),
+			SelectionRange: doc.Range(attr.NameRange),
+		})
+	}
+	sortSymbols(ret)
+	return ret
+}
+
+func bodySymbols(doc *document, body *hclsyntax.Body) []documentSymbol {
+	ret := make([]documentSymbol, 0, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range body.Attributes {
+		ret = append(ret, documentSymbol{
+			Name:           attr.Name,
+			Kind:           symbolKindProperty,
+			Range:          doc.Range(attr.SrcRange),
+			SelectionRange: doc.Range(attr.NameRange),
+		})
+	}
+	for _, block := range body.Blocks {
+		ret = append(ret, documentSymbol{
+			Name:           blockHeader(block),
+			Kind:           symbolKindStruct,
+			Range:          doc.Range(block.Range()),
+			SelectionRange: doc.Range(block.TypeRange),
+			Children:       bodySymbols(doc, block.Body),
+		})
+	}
+	sortSymbols(ret)
+	return ret
+}
+
+func sortSymbols(symbols []documentSymbol) {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		a, b := symbols[i].Range.Start, symbols[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+}
+
+// blockHeader returns a representation of the type and labels of the given
+// block, as they might be written in the source.
+func blockHeader(block *hclsyntax.Block) string {
+	var buf strings.Builder
+	buf.WriteString(block.Type)
+	for _, label := range block.Labels {
+		fmt.Fprintf(&buf, " %q", label)
+	}
+	return buf.String()
+}
+
+// hoverAt returns hover information for the attribute or block header at the
+// given byte offset in the given document, or nil if there is none.
+func hoverAt(doc *document, ofs int) *hover {
+	body := doc.Body()
+	if body == nil {
+		return nil
+	}
+
+	var path []string
+	for {
+		var next *hclsyntax.Block
+		for _, attr := range body.Attributes {
+			if !attr.SrcRange.ContainsOffset(ofs) {
+				continue
+			}
+			var buf strings.Builder
+			fmt.Fprintf(&buf, "**%s** attribute", attr.Name)
+			if val, diags := attr.Expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+				fmt.Fprintf(&buf, " of type `%s`", val.Type().FriendlyName())
+			}
+			writeHoverPath(&buf, path)
+			rng := doc.Range(attr.NameRange)
+			return &hover{
+				Contents: markupContent{Kind: "markdown", Value: buf.String()},
+				Range:    &rng,
+			}
+		}
+		for _, block := range body.Blocks {
+			if block.DefRange().ContainsOffset(ofs) || block.TypeRange.ContainsOffset(ofs) {
+				var buf strings.Builder
+				fmt.Fprintf(&buf, "**%s** block with %s and %s",
+					block.Type,
+					pluralize(len(block.Body.Attributes), "attribute"),
+					pluralize(len(block.Body.Blocks), "nested block"),
+				)
+				writeHoverPath(&buf, path)
+				rng := doc.Range(hcl.RangeBetween(block.TypeRange, block.DefRange()))
+				return &hover{
+					Contents: markupContent{Kind: "markdown", Value: buf.String()},
+					Range:    &rng,
+				}
+			}
+			if block.Body.SrcRange.ContainsOffset(ofs) {
+				next = block
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		path = append(path, blockHeader(next))
+		body = next.Body
+	}
+}
+
+func writeHoverPath(buf *strings.Builder, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	buf.WriteString("\n\nin `")
+	buf.WriteString(strings.Join(path, " › "))
+	buf.WriteString("`")
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatDocument returns the edits needed to put the given document into the
+// canonical format, or nil if the document can't be formatted.
+func formatDocument(doc *document) []textEdit {
+	if doc.IsJSON() || doc.Diags.HasErrors() {
+		// The formatter can't safely format a file with syntax errors,
+		// since it can't tell which tokens belong together.
+		return nil
+	}
+
+	formatted := hclwrite.Format(doc.Src)
+	if bytes.Equal(formatted, doc.Src) {
+		return []textEdit{}
+	}
+	return []textEdit{
+		{
+			Range: lspRange{
+				Start: position{},
+				End:   doc.Position(len(doc.Src)),
+			},
+			NewText: string(formatted),
+		},
+	}
+}
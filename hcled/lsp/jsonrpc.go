@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+	codeNotInitialized = -32002
+)
+
+// maxContentLength is the largest message body that readMessage accepts, to
+// protect the server from clients that send a corrupt or hostile header.
+const maxContentLength = 64 << 20
+
+// message is a JSON-RPC 2.0 message, which may be a request, a response or
+// a notification depending on which fields are set.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads a single message from the given reader, which must be
+// positioned at the start of the message's header.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+	if length < 0 || length > maxContentLength {
+		return nil, fmt.Errorf("invalid Content-Length header: %d is not between 0 and %d", length, maxContentLength)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return &msg, nil
+}
+
+// writeMessage writes a single message to the given writer, with its header.
+func writeMessage(w io.Writer, msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
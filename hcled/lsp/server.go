@@ -0,0 +1,304 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/hcl/v2/hcled"
+)
+
+// Server is a language server for generic HCL files.
+//
+// A Server holds the documents that the client has opened, and so should be
+// used for only one client connection.
+type Server struct {
+	// Name and Version are reported to the client during initialization.
+	Name    string
+	Version string
+
+	docs        map[string]*document
+	w           io.Writer
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a new server with no open documents.
+func NewServer() *Server {
+	return &Server{
+		Name: "hclls",
+		docs: map[string]*document{},
+	}
+}
+
+// errExitBeforeShutdown is returned by Serve when the client sends the exit
+// notification without first requesting a shutdown, which the protocol
+// treats as an abnormal termination.
+var errExitBeforeShutdown = errors.New("exit notification received before shutdown request")
+
+// Serve reads requests and notifications from r and writes responses and
+// notifications to w until the client sends the exit notification or r
+// reaches end of file.
+//
+// Serve returns nil if the client requested a shutdown before exiting, or an
+// error describing why the connection ended otherwise.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(br)
+		if err == io.EOF {
+			if s.shutdown {
+				return nil
+			}
+			return io.ErrUnexpectedEOF
+		}
+		if rerr, ok := err.(*responseError); ok {
+			if err := s.reply(nil, nil, rerr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errExitBeforeShutdown
+			}
+			return nil
+		}
+
+		result, err := s.handle(msg.Method, msg.Params)
+		var werr *writeError
+		if errors.As(err, &werr) {
+			// The connection is unusable once a write has failed.
+			return werr.err
+		}
+		if msg.ID == nil {
+			// Notifications have no response, so we report any failure to
+			// the client as a log message instead.
+			if err != nil {
+				if err := s.logError(fmt.Sprintf("%s: %s", msg.Method, err)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var rerr *responseError
+		if err != nil && !errors.As(err, &rerr) {
+			rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		if err := s.reply(msg.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	msg := &message{ID: id}
+	if rerr != nil {
+		msg.Error = rerr
+	} else {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = raw
+	}
+	return writeMessage(s.w, msg)
+}
+
+// writeError is returned by notify when the notification could not be
+// written, to distinguish that from the failure of a request.
+type writeError struct {
+	err error
+}
+
+func (e *writeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *writeError) Unwrap() error {
+	return e.err
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := writeMessage(s.w, &message{Method: method, Params: raw}); err != nil {
+		return &writeError{err}
+	}
+	return nil
+}
+
+// logError sends the given message to the client for display in its log.
+func (s *Server) logError(text string) error {
+	err := s.notify("window/logMessage", logMessageParams{Type: messageTypeError, Message: text})
+	var werr *writeError
+	if errors.As(err, &werr) {
+		return werr.err
+	}
+	return err
+}
+
+// handle dispatches a single request or notification to the corresponding
+// method of the server.
+func (s *Server) handle(method string, rawParams json.RawMessage) (interface{}, error) {
+	if !s.initialized && method != "initialize" {
+		return nil, &responseError{Code: codeNotInitialized, Message: "server not initialized"}
+	}
+
+	// decode populates the given params value from the raw parameters.
+	decode := func(params interface{}) error {
+		if err := json.Unmarshal(rawParams, params); err != nil {
+			return &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch method {
+	case "initialize":
+		s.initialized = true
+		return s.initialize(), nil
+
+	case "initialized":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		// We request full synchronization, so the last change always
+		// contains the whole of the new text.
+		if n := len(params.ContentChanges); n > 0 {
+			return nil, s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []diagnostic{},
+		})
+
+	case "textDocument/documentSymbol":
+		var params textDocumentParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return documentSymbols(doc), nil
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return hoverAt(doc, doc.Offset(params.Position)), nil
+
+	case "textDocument/formatting":
+		var params formattingParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return formatDocument(doc), nil
+
+	case "textDocument/semanticTokens/full":
+		var params textDocumentParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		if doc.IsJSON() {
+			return semanticTokens{Data: []uint32{}}, nil
+		}
+		tokens, _ := hcled.SemanticTokens(doc.Src, doc.Filename)
+		return semanticTokens{Data: hcled.EncodeSemanticTokens(doc.Src, tokens)}, nil
+
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q is not supported", method)}
+	}
+}
+
+func (s *Server) initialize() interface{} {
+	type serverInfo struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":           1, // full document synchronization
+			"documentSymbolProvider":     true,
+			"hoverProvider":              true,
+			"documentFormattingProvider": true,
+			"semanticTokensProvider": map[string]interface{}{
+				"legend": map[string]interface{}{
+					"tokenTypes":     hcled.SemanticTokenTypes,
+					"tokenModifiers": []string{},
+				},
+				"full": true,
+			},
+		},
+		"serverInfo": serverInfo{Name: s.Name, Version: s.Version},
+	}
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document %s is not open", uri)}
+	}
+	return doc, nil
+}
+
+// update replaces the content of the document with the given URI and
+// publishes its diagnostics.
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: documentDiagnostics(doc),
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// runSession sends the given messages to a new server and returns all of
+// the messages it writes in response, indexed by request id for responses
+// and collected in order for notifications.
+func runSession(t *testing.T, msgs ...map[string]interface{}) (map[int]*message, []*message) {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, msg := range msgs {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in.WriteString("Content-Length: ")
+		in.WriteString(strconv.Itoa(len(body)))
+		in.WriteString("\r\n\r\n")
+		in.Write(body)
+	}
+
+	var out bytes.Buffer
+	if err := NewServer().Serve(&in, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	responses := map[int]*message{}
+	var notifications []*message
+	r := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.ID == nil {
+			notifications = append(notifications, msg)
+			continue
+		}
+		var id int
+		if err := json.Unmarshal(*msg.ID, &id); err != nil {
+			t.Fatal(err)
+		}
+		responses[id] = msg
+	}
+	return responses, notifications
+}
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"id": id, "method": method, "params": params}
+}
+
+func notification(method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"method": method, "params": params}
+}
+
+func TestServer(t *testing.T) {
+	const uri = "file:///tmp/test.hcl"
+	src := "name = \"foo\"\nservice \"web\" {\n  port=8080\n}\n"
+	doc := map[string]interface{}{"uri": uri}
+
+	responses, notifications := runSession(t,
+		request(1, "initialize", map[string]interface{}{}),
+		notification("initialized", map[string]interface{}{}),
+		notification("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": src},
+		}),
+		request(2, "textDocument/documentSymbol", map[string]interface{}{"textDocument": doc}),
+		request(3, "textDocument/hover", map[string]interface{}{
+			"textDocument": doc,
+			"position":     map[string]interface{}{"line": 2, "character": 3},
+		}),
+		request(4, "textDocument/formatting", map[string]interface{}{"textDocument": doc}),
+		notification("textDocument/didChange", map[string]interface{}{
+			"textDocument":   doc,
+			"contentChanges": []interface{}{map[string]interface{}{"text": "name = \n"}},
+		}),
+		request(5, "textDocument/unknown", map[string]interface{}{}),
+		request(6, "shutdown", nil),
+		notification("exit", nil),
+	)
+
+	for id := 1; id <= 6; id++ {
+		if responses[id] == nil {
+			t.Fatalf("no response for request %d", id)
+		}
+	}
+
+	var symbols []documentSymbol
+	if err := json.Unmarshal(responses[2].Result, &symbols); err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 2 || symbols[0].Name != "name" || symbols[1].Name != `service "web"` {
+		t.Fatalf("wrong symbols %#v", symbols)
+	}
+	if children := symbols[1].Children; len(children) != 1 || children[0].Name != "port" {
+		t.Errorf("wrong children %#v", children)
+	}
+
+	var h hover
+	if err := json.Unmarshal(responses[3].Result, &h); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.Contents.Value, "**port** attribute of type `number`\n\nin `service \"web\"`"; got != want {
+		t.Errorf("wrong hover\ngot:  %q\nwant: %q", got, want)
+	}
+
+	var edits []textEdit
+	if err := json.Unmarshal(responses[4].Result, &edits); err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 1 || !strings.Contains(edits[0].NewText, "  port = 8080\n") {
+		t.Errorf("wrong edits %#v", edits)
+	}
+
+	if responses[5].Error == nil || responses[5].Error.Code != codeMethodNotFound {
+		t.Errorf("wrong response for unknown method %#v", responses[5])
+	}
+
+	if len(notifications) != 2 {
+		t.Fatalf("wrong number of notifications %d; want 2", len(notifications))
+	}
+	var diags publishDiagnosticsParams
+	if err := json.Unmarshal(notifications[0].Params, &diags); err != nil {
+		t.Fatal(err)
+	}
+	if len(diags.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics for valid document %#v", diags.Diagnostics)
+	}
+	if err := json.Unmarshal(notifications[1].Params, &diags); err != nil {
+		t.Fatal(err)
+	}
+	if len(diags.Diagnostics) != 1 || diags.Diagnostics[0].Range.Start.Line != 0 {
+		t.Errorf("wrong diagnostics for invalid document %#v", diags.Diagnostics)
+	}
+}
+
+func TestDocumentPosition(t *testing.T) {
+	doc := newDocument("file:///test.hcl", "a = \"😀\"\nb = 1\n")
+
+	tests := []struct {
+		Offset int
+		Pos    position
+	}{
+		{0, position{0, 0}},
+		{5, position{0, 5}},
+		{9, position{0, 7}},
+		{11, position{1, 0}},
+		{14, position{1, 3}},
+	}
+	for _, test := range tests {
+		if got := doc.Position(test.Offset); got != test.Pos {
+			t.Errorf("wrong position for offset %d: %#v; want %#v", test.Offset, got, test.Pos)
+		}
+		if got := doc.Offset(test.Pos); got != test.Offset {
+			t.Errorf("wrong offset for %#v: %d; want %d", test.Pos, got, test.Offset)
+		}
+	}
+}
+
+func TestReadMessageContentLength(t *testing.T) {
+	tests := []string{
+		"-1",
+		strconv.Itoa(maxContentLength + 1),
+	}
+	for _, length := range tests {
+		t.Run(length, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader("Content-Length: " + length + "\r\n\r\n{}"))
+			if _, err := readMessage(r); err == nil {
+				t.Fatal("unexpected success")
+			}
+		})
+	}
+}
+
+func TestServerNotificationError(t *testing.T) {
+	_, notifications := runSession(t,
+		request(1, "initialize", map[string]interface{}{}),
+		notification("textDocument/didOpen", "not an object"),
+		request(2, "shutdown", nil),
+		notification("exit", nil),
+	)
+	if len(notifications) != 1 {
+		t.Fatalf("wrong number of notifications %d; want 1", len(notifications))
+	}
+	if got, want := notifications[0].Method, "window/logMessage"; got != want {
+		t.Fatalf("wrong method %q; want %q", got, want)
+	}
+	var params logMessageParams
+	if err := json.Unmarshal(notifications[0].Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Type != messageTypeError || !strings.HasPrefix(params.Message, "textDocument/didOpen: ") {
+		t.Fatalf("wrong log message %#v", params)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestServerWriteError(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.hcl","text":"a = 1\n"}}}`
+	in := strings.NewReader("Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body)
+	if err := NewServer().Serve(in, failingWriter{}); err != io.ErrClosedPipe {
+		t.Fatalf("wrong error %v; want %v", err, io.ErrClosedPipe)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SemanticTokenType is a category of source text for the purpose of syntax
+// highlighting, corresponding to one of the standard token types of the
+// Language Server Protocol's semantic tokens feature.
+type SemanticTokenType int
+
+const (
+	SemanticKeyword SemanticTokenType = iota
+	SemanticString
+	SemanticNumber
+	SemanticComment
+	SemanticType
+	SemanticProperty
+	SemanticVariable
+	SemanticFunction
+	SemanticOperator
+)
+
+// SemanticTokenTypes is the legend for SemanticTokenType, giving the Language
+// Server Protocol name for each type at the index of its value. A language
+// server should announce this slice as the tokenTypes of its legend, so that
+// the values produced by EncodeSemanticTokens are interpreted correctly.
+var SemanticTokenTypes = []string{
+	SemanticKeyword:  "keyword",
+	SemanticString:   "string",
+	SemanticNumber:   "number",
+	SemanticComment:  "comment",
+	SemanticType:     "type",
+	SemanticProperty: "property",
+	SemanticVariable: "variable",
+	SemanticFunction: "function",
+	SemanticOperator: "operator",
+}
+
+func (t SemanticTokenType) String() string {
+	if int(t) < 0 || int(t) >= len(SemanticTokenTypes) {
+		return "unknown"
+	}
+	return SemanticTokenTypes[t]
+}
+
+// SemanticToken is a range of source text along with its semantic category.
+type SemanticToken struct {
+	Type  SemanticTokenType
+	Range hcl.Range
+}
+
+var semanticKeywords = map[string]bool{
+	"true": true, "false": true, "null": true,
+	"for": true, "in": true, "if": true,
+	"else": true, "endif": true, "endfor": true,
+}
+
+// SemanticTokens scans and parses the given native syntax source buffer and
+// returns the semantic tokens it contains, in source order.
+//
+// Block types are returned as SemanticType, attribute names, object keys and
+// attribute accesses as SemanticProperty, and block labels as SemanticString
+// regardless of whether they are quoted. Punctuation such as braces and
+// commas is not included in the result.
+//
+// The result is useful even when the source contains syntax errors, in which
+// case the returned diagnostics describe those errors and any constructs the
+// parser could not recognize are classified by their tokens alone.
+func SemanticTokens(src []byte, filename string) ([]SemanticToken, hcl.Diagnostics) {
+	// The parser reports the lexer's diagnostics along with its own, so we
+	// return only those from parsing.
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.InitialPos)
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+
+	// The parser tells us the role of identifiers that can't be determined
+	// from the tokens alone, which we record by the byte offset at which
+	// each identifier starts.
+	roles := map[int]SemanticTokenType{}
+	if body, ok := file.Body.(*hclsyntax.Body); ok {
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			switch node := node.(type) {
+			case *hclsyntax.Attribute:
+				roles[node.NameRange.Start.Byte] = SemanticProperty
+			case *hclsyntax.Block:
+				roles[node.TypeRange.Start.Byte] = SemanticType
+				for _, rng := range node.LabelRanges {
+					roles[rng.Start.Byte] = SemanticString
+				}
+			case *hclsyntax.FunctionCallExpr:
+				roles[node.NameRange.Start.Byte] = SemanticFunction
+			case *hclsyntax.ObjectConsKeyExpr:
+				if !node.ForceNonLiteral && hcl.ExprAsKeyword(node.Wrapped) != "" {
+					roles[node.Wrapped.Range().Start.Byte] = SemanticProperty
+				}
+			}
+			return nil
+		})
+	}
+
+	var ret []SemanticToken
+	for i, tok := range tokens {
+		ty, ok := semanticTokenType(tok)
+		if !ok {
+			continue
+		}
+		if tok.Type == hclsyntax.TokenIdent {
+			switch role, hasRole := roles[tok.Range.Start.Byte]; {
+			case hasRole:
+				ty = role
+			case i > 0 && tokens[i-1].Type == hclsyntax.TokenDot:
+				ty = SemanticProperty
+			case semanticKeywords[string(tok.Bytes)]:
+				ty = SemanticKeyword
+			}
+		}
+		ret = append(ret, SemanticToken{
+			Type:  ty,
+			Range: tok.Range,
+		})
+	}
+	return ret, diags
+}
+
+// semanticTokenType returns the semantic type implied by a token alone, or
+// false if the token is not of interest for highlighting.
+func semanticTokenType(tok hclsyntax.Token) (SemanticTokenType, bool) {
+	switch tok.Type {
+	case hclsyntax.TokenIdent:
+		return SemanticVariable, true
+	case hclsyntax.TokenComment:
+		return SemanticComment, true
+	case hclsyntax.TokenNumberLit:
+		return SemanticNumber, true
+	case hclsyntax.TokenOQuote, hclsyntax.TokenCQuote, hclsyntax.TokenQuotedLit,
+		hclsyntax.TokenOHeredoc, hclsyntax.TokenCHeredoc, hclsyntax.TokenStringLit:
+		return SemanticString, true
+	case hclsyntax.TokenEqual, hclsyntax.TokenPlus, hclsyntax.TokenMinus,
+		hclsyntax.TokenStar, hclsyntax.TokenSlash, hclsyntax.TokenPercent,
+		hclsyntax.TokenEqualOp, hclsyntax.TokenNotEqual,
+		hclsyntax.TokenLessThan, hclsyntax.TokenLessThanEq,
+		hclsyntax.TokenGreaterThan, hclsyntax.TokenGreaterThanEq,
+		hclsyntax.TokenAnd, hclsyntax.TokenOr, hclsyntax.TokenBang,
+		hclsyntax.TokenQuestion, hclsyntax.TokenColon,
+		hclsyntax.TokenFatArrow, hclsyntax.TokenEllipsis,
+		hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl,
+		hclsyntax.TokenTemplateSeqEnd:
+		return SemanticOperator, true
+	default:
+		return 0, false
+	}
+}
+
+// EncodeSemanticTokens encodes the given tokens, which must be in source
+// order and must have been produced from the given source buffer, in the
+// relative integer format of the Language Server Protocol's semantic tokens
+// response data.
+//
+// Positions and lengths are measured in UTF-16 code units, as the protocol
+// requires by default. Tokens that span multiple lines, such as comments and
+// heredoc strings, are split into one token per line, and any newline
+// characters are excluded. The token modifiers are always zero.
+func EncodeSemanticTokens(src []byte, tokens []SemanticToken) []uint32 {
+	ret := make([]uint32, 0, len(tokens)*5)
+
+	// We track the line and UTF-16 column of a byte offset as we walk
+	// forward through the buffer, since the tokens are in order.
+	line, char, ofs := 0, 0, 0
+	advance := func(to int) {
+		for ofs < to && ofs < len(src) {
+			if src[ofs] == '\n' {
+				line++
+				char = 0
+				ofs++
+				continue
+			}
+			r, size := utf8.DecodeRune(src[ofs:])
+			char += utf16Len(r)
+			ofs += size
+		}
+	}
+
+	prevLine, prevChar := 0, 0
+	emit := func(length int, ty SemanticTokenType) {
+		deltaLine := line - prevLine
+		deltaChar := char
+		if deltaLine == 0 {
+			deltaChar = char - prevChar
+		}
+		ret = append(ret, uint32(deltaLine), uint32(deltaChar), uint32(length), uint32(ty), 0)
+		prevLine, prevChar = line, char
+	}
+
+	for _, tok := range tokens {
+		start, end := tok.Range.Start.Byte, tok.Range.End.Byte
+		if start < ofs || end > len(src) {
+			continue // out of order or not from this buffer
+		}
+		advance(start)
+		for ofs < end {
+			// Measure the part of the token on the current line.
+			length, i := 0, ofs
+			for i < end && src[i] != '\n' && src[i] != '\r' {
+				r, size := utf8.DecodeRune(src[i:])
+				length += utf16Len(r)
+				i += size
+			}
+			if length > 0 {
+				emit(length, tok.Type)
+			}
+			advance(i)
+			for ofs < end && (src[ofs] == '\r' || src[ofs] == '\n') {
+				advance(ofs + 1)
+			}
+		}
+	}
+	return ret
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
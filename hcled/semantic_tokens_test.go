@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcled
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	src := `# comment
+resource "thing" foo {
+  count = length(var.names) + 1
+  tags  = { for k, v in x : k => true }
+}
+`
+	tokens, diags := SemanticTokens([]byte(src), "test.hcl")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []string
+	for _, tok := range tokens {
+		text := src[tok.Range.Start.Byte:tok.Range.End.Byte]
+		got = append(got, fmt.Sprintf("%s %q", tok.Type, text))
+	}
+	want := []string{
+		`comment "# comment\n"`,
+		`type "resource"`,
+		`string "\""`,
+		`string "thing"`,
+		`string "\""`,
+		`string "foo"`,
+		`property "count"`,
+		`operator "="`,
+		`function "length"`,
+		`variable "var"`,
+		`property "names"`,
+		`operator "+"`,
+		`number "1"`,
+		`property "tags"`,
+		`operator "="`,
+		`keyword "for"`,
+		`variable "k"`,
+		`variable "v"`,
+		`keyword "in"`,
+		`variable "x"`,
+		`operator ":"`,
+		`variable "k"`,
+		`operator "=>"`,
+		`keyword "true"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong tokens\ngot:\n%#v\nwant:\n%#v", got, want)
+	}
+}
+
+func TestSemanticTokensDiagnostics(t *testing.T) {
+	_, diags := SemanticTokens([]byte("a = 1 @ 2\n"), "test.hcl")
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Error())
+	}
+	seen := map[string]bool{}
+	for _, msg := range got {
+		if seen[msg] {
+			t.Errorf("duplicate diagnostic %s", msg)
+		}
+		seen[msg] = true
+	}
+	if len(got) == 0 {
+		t.Error("no diagnostics for invalid character")
+	}
+}
+
+func TestEncodeSemanticTokens(t *testing.T) {
+	src := "a = \"😀x\" # c\nb = <<EOT\nhi\nEOT\n"
+	tokens, diags := SemanticTokens([]byte(src), "test.hcl")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	got := EncodeSemanticTokens([]byte(src), tokens)
+	want := []uint32{
+		0, 0, 1, uint32(SemanticProperty), 0,
+		0, 2, 1, uint32(SemanticOperator), 0,
+		0, 2, 1, uint32(SemanticString), 0,
+		0, 1, 3, uint32(SemanticString), 0, // the emoji is two UTF-16 code units
+		0, 3, 1, uint32(SemanticString), 0,
+		0, 2, 3, uint32(SemanticComment), 0,
+		1, 0, 1, uint32(SemanticProperty), 0,
+		0, 2, 1, uint32(SemanticOperator), 0,
+		0, 2, 5, uint32(SemanticString), 0,
+		1, 0, 2, uint32(SemanticString), 0,
+		1, 0, 3, uint32(SemanticString), 0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hcleval provides a simple evaluation scope for applications that
+// want their configuration files to refer to variables, without having to
+// construct an hcl.EvalContext themselves.
+//
+// An application registers values with a Scope, after which expressions and
+// templates such as "${var.name}" can refer to them as attributes of the
+// "var" object. References to variables that have not been registered are
+// reported as error diagnostics that give the position of the reference.
+//
+// Expressions and templates are evaluated with the full native syntax, so
+// interpolation sequences may contain any expression: conditional
+// expressions like "${var.env == "prod" ? "large" : "small"}", the
+// arithmetic, comparison and logical operators, for expressions and splat
+// expressions. An operand of the wrong type produces an "Invalid operand"
+// error, along with an unknown value of the operator's result type so that
+// callers can continue type checking.
+//
+// A Scope can also hold functions for expressions to call, including the
+// standard set returned by StandardFunctions.
+//
+// Applications that want configuration to refer to environment variables
+// can allow particular variables with Scope.AllowEnvironment, after which
+// expressions can read them as attributes of the "env" object, like
+// "${env.HOME}", or by calling the "env" function, like env("HOME"). This
+// avoids substituting environment variables into the raw text of the
+// configuration, and keeps the set of variables that it may read under the
+// control of the application.
+package hcleval
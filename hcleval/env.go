@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcleval
+
+import (
+	"os"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// EnvironmentRoot is the name of the object through which expressions refer
+// to environment variables, once they are allowed by AllowEnvironment. It
+// is also the name of the function that does the same.
+const EnvironmentRoot = "env"
+
+// AllowEnvironment allows expressions evaluated in the scope to read the
+// process environment variables with the given names, either as attributes
+// of the "env" object, like env.HOME, or by calling the "env" function,
+// like env("HOME"). A name ending with * allows all of the variables whose
+// names begin with the text before it, so "APP_*" allows APP_PORT and
+// APP_HOST.
+//
+// References to variables that are not allowed, or that are not set, are
+// reported as errors. Each call adds to the names allowed by earlier calls.
+// The environment is read each time EvalContext is called.
+func (s *Scope) AllowEnvironment(names ...string) {
+	s.envEnabled = true
+	s.envAllowed = append(s.envAllowed, names...)
+}
+
+// environment returns the values of the environment variables that the
+// scope allows, keyed by name.
+func (s *Scope) environment() map[string]cty.Value {
+	ret := make(map[string]cty.Value)
+	for _, kv := range os.Environ() {
+		eq := strings.Index(kv, "=")
+		if eq <= 0 {
+			continue
+		}
+		if name := kv[:eq]; envAllowed(s.envAllowed, name) {
+			ret[name] = cty.StringVal(kv[eq+1:])
+		}
+	}
+	return ret
+}
+
+// EnvFunc returns a function that returns the value of the process
+// environment variable with the given name, which must be one of the given
+// allowed names, using the same patterns as Scope.AllowEnvironment.
+func EnvFunc(allowed ...string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "name",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			name := args[0].AsString()
+			if !envAllowed(allowed, name) {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "environment variable %q is not allowed", name)
+			}
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "environment variable %q is not set", name)
+			}
+			return cty.StringVal(val), nil
+		},
+	})
+}
+
+// envAllowed returns true if the given environment variable name matches
+// one of the given patterns.
+func envAllowed(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if pattern == name {
+			return true
+		}
+	}
+	return false
+}
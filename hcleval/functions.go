@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcleval
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// StandardFunctions returns a new map of the standard functions that
+// applications can make available to expressions, keyed by the names that
+// expressions use to call them.
+//
+// The "file" function reads files relative to the given base directory. An
+// application that doesn't want configuration to be able to read files
+// should delete it from the result before registering the functions.
+func StandardFunctions(baseDir string) map[string]function.Function {
+	return map[string]function.Function{
+		"concat":  stdlib.ConcatFunc,
+		"element": stdlib.ElementFunc,
+		"file":    FileFunc(baseDir),
+		"format":  stdlib.FormatFunc,
+		"join":    stdlib.JoinFunc,
+		"lookup":  stdlib.LookupFunc,
+		"lower":   stdlib.LowerFunc,
+		"split":   stdlib.SplitFunc,
+		"upper":   stdlib.UpperFunc,
+	}
+}
+
+// FileFunc returns a function that reads the contents of the file at the
+// given path, which is interpreted relative to the given base directory
+// unless it is absolute. The file must contain valid UTF-8 text.
+func FileFunc(baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+			src, err := ioutil.ReadFile(path)
+			if err != nil {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "failed to read file: %s", err)
+			}
+			if !utf8.Valid(src) {
+				return cty.UnknownVal(cty.String), function.NewArgError(0, fmt.Errorf("contents of %s are not valid UTF-8", path))
+			}
+			return cty.StringVal(string(src)), nil
+		},
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcleval
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestStandardFunctions(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "greeting.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScope()
+	s.SetFunctions(StandardFunctions(dir))
+	s.SetVariable("names", cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}))
+	s.SetVariable("sizes", cty.MapVal(map[string]cty.Value{"small": cty.NumberIntVal(1)}))
+
+	tests := []struct {
+		src  string
+		want cty.Value
+	}{
+		{`upper("abc")`, cty.StringVal("ABC")},
+		{`lower("ABC")`, cty.StringVal("abc")},
+		{`concat(var.names, ["c"])`, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")})},
+		{`join("-", var.names)`, cty.StringVal("a-b")},
+		{`split(",", "x,y")`, cty.ListVal([]cty.Value{cty.StringVal("x"), cty.StringVal("y")})},
+		{`element(var.names, 3)`, cty.StringVal("b")},
+		{`lookup(var.sizes, "large", 0)`, cty.NumberIntVal(0)},
+		{`format("%s-%03d", "id", 7)`, cty.StringVal("id-007")},
+		{`file("greeting.txt")`, cty.StringVal("hello")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := s.Evaluate(expr)
+			assertDiags(t, diags, nil)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScopeFunctionRegistry(t *testing.T) {
+	s := NewScope()
+	s.SetFunctions(StandardFunctions("."))
+	s.SetFunction("upper", stdlib.LowerFunc)
+	s.SetFunction("reverse", stdlib.ReverseFunc)
+	s.RemoveFunction("file")
+
+	if _, ok := s.Function("file"); ok {
+		t.Errorf("file function is still registered")
+	}
+	if fn, ok := s.Function("reverse"); !ok || fn.Params()[0].Name != "str" {
+		t.Errorf("reverse function is not registered")
+	}
+
+	eval := func(src string) (cty.Value, hcl.Diagnostics) {
+		expr, _ := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+		return s.Evaluate(expr)
+	}
+	got, diags := eval(`upper(reverse("ABC"))`)
+	assertDiags(t, diags, nil)
+	if want := cty.StringVal("cba"); !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	_, diags = eval(`file("x")`)
+	if len(diags) != 1 || diags[0].Summary != "Call to unknown function" {
+		t.Errorf("wrong diagnostics %s", diags.Error())
+	}
+}
+
+func TestFileFuncErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "binary"), []byte{0xff, 0xfe}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fn := FileFunc(dir)
+	for _, name := range []string{"binary", "missing"} {
+		_, err := fn.Call([]cty.Value{cty.StringVal(name)})
+		if _, ok := err.(function.ArgError); !ok {
+			t.Errorf("%s: wrong error %#v", name, err)
+		}
+	}
+}
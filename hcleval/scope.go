@@ -0,0 +1,312 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcleval
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// VariablesRoot is the name of the object through which expressions refer
+// to the variables of a Scope.
+const VariablesRoot = "var"
+
+// Scope is a set of variables and functions that expressions can refer to.
+//
+// The zero value of Scope is not valid. Use NewScope to create a scope.
+type Scope struct {
+	variables map[string]cty.Value
+	functions map[string]function.Function
+
+	// envEnabled is set by AllowEnvironment, which also adds the patterns
+	// of the allowed environment variable names to envAllowed.
+	envEnabled bool
+	envAllowed []string
+}
+
+// NewScope creates a new scope with no variables or functions. Use
+// SetFunctions with the result of StandardFunctions to make the standard
+// functions available.
+func NewScope() *Scope {
+	return &Scope{
+		variables: map[string]cty.Value{},
+		functions: map[string]function.Function{},
+	}
+}
+
+// SetVariable registers a variable with the given name and value, replacing
+// any existing variable of the same name.
+func (s *Scope) SetVariable(name string, val cty.Value) {
+	s.variables[name] = val
+}
+
+// SetVariables registers each of the variables in the given map, as if
+// SetVariable were called for each one.
+func (s *Scope) SetVariables(vars map[string]cty.Value) {
+	for name, val := range vars {
+		s.SetVariable(name, val)
+	}
+}
+
+// Variable returns the value of the variable with the given name, and false
+// if there is no such variable.
+func (s *Scope) Variable(name string) (cty.Value, bool) {
+	val, ok := s.variables[name]
+	return val, ok
+}
+
+// SetFunction registers a function with the given name, replacing any
+// existing function of the same name, including a standard function.
+func (s *Scope) SetFunction(name string, fn function.Function) {
+	s.functions[name] = fn
+}
+
+// SetFunctions registers each of the functions in the given map, as if
+// SetFunction were called for each one.
+func (s *Scope) SetFunctions(funcs map[string]function.Function) {
+	for name, fn := range funcs {
+		s.SetFunction(name, fn)
+	}
+}
+
+// RemoveFunction removes the function with the given name, if any.
+func (s *Scope) RemoveFunction(name string) {
+	delete(s.functions, name)
+}
+
+// Function returns the function with the given name, and false if there is
+// no such function.
+func (s *Scope) Function(name string) (function.Function, bool) {
+	fn, ok := s.functions[name]
+	return fn, ok
+}
+
+// EvalContext returns an evaluation context through which expressions can
+// refer to the variables and functions of the scope, and to the environment
+// variables allowed by AllowEnvironment.
+//
+// The result is a snapshot, so variables and functions set after calling
+// EvalContext are not visible through it.
+func (s *Scope) EvalContext() *hcl.EvalContext {
+	vars := make(map[string]cty.Value, len(s.variables))
+	for name, val := range s.variables {
+		vars[name] = val
+	}
+	funcs := make(map[string]function.Function, len(s.functions))
+	for name, fn := range s.functions {
+		funcs[name] = fn
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			VariablesRoot: cty.ObjectVal(vars),
+		},
+		Functions: funcs,
+	}
+	if s.envEnabled {
+		ctx.Variables[EnvironmentRoot] = cty.ObjectVal(s.environment())
+		if _, exists := funcs[EnvironmentRoot]; !exists {
+			funcs[EnvironmentRoot] = EnvFunc(s.envAllowed...)
+		}
+	}
+	return ctx
+}
+
+// Evaluate evaluates the given expression in the scope.
+//
+// If the expression refers to any variables that are not defined in the
+// scope then Evaluate returns error diagnostics for each of them without
+// evaluating the expression. Calls to functions that are not registered
+// with the scope are reported by the usual error for an unknown function.
+func (s *Scope) Evaluate(expr hcl.Expression) (cty.Value, hcl.Diagnostics) {
+	diags := s.CheckTraversals(expr.Variables())
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	val, moreDiags := expr.Value(s.EvalContext())
+	return val, append(diags, moreDiags...)
+}
+
+// DecodeBody decodes the given body into the value pointed to by val, as
+// with gohcl.DecodeBody, using the variables of the scope.
+//
+// For a native syntax body, any references to variables that are not
+// defined in the scope are reported before decoding, with one diagnostic
+// for each. For other bodies the references can only be found while
+// decoding, and so are reported by the usual error for an unsupported
+// attribute of the "var" object.
+func (s *Scope) DecodeBody(body hcl.Body, val interface{}) hcl.Diagnostics {
+	if synBody, ok := body.(*hclsyntax.Body); ok {
+		var traversals []hcl.Traversal
+		hclsyntax.VisitAll(synBody, func(node hclsyntax.Node) hcl.Diagnostics {
+			if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok {
+				traversals = append(traversals, expr.Traversal)
+			}
+			return nil
+		})
+		// The attributes of a body are visited in no particular order, so
+		// we sort the traversals to report them in source order.
+		sort.Slice(traversals, func(i, j int) bool {
+			return traversals[i].SourceRange().Start.Byte < traversals[j].SourceRange().Start.Byte
+		})
+		if diags := s.CheckTraversals(traversals); diags.HasErrors() {
+			return diags
+		}
+	}
+	return gohcl.DecodeBody(body, s.EvalContext(), val)
+}
+
+// Substitute parses the given string as a template, such as one obtained
+// from a configuration value that was decoded without an evaluation context,
+// and evaluates it in the scope to produce a string.
+//
+// The given range is the location of the string in its source file, which
+// is used as the starting position of the template when reporting errors.
+// A string with no interpolation sequences is returned unchanged, aside from
+// the processing of any escape sequences for literal "${" and "%{".
+func (s *Scope) Substitute(src string, rng hcl.Range) (string, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseTemplate([]byte(src), rng.Filename, rng.Start)
+	if diags.HasErrors() {
+		return src, diags
+	}
+	val, moreDiags := s.Evaluate(expr)
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return src, diags
+	}
+
+	val, err := convert.Convert(val, cty.String)
+	if err != nil || val.IsNull() || !val.IsKnown() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid template result",
+			Detail:   "The template must produce a string.",
+			Subject:  expr.Range().Ptr(),
+		})
+		return src, diags
+	}
+	return val.AsString(), diags
+}
+
+// SubstituteValue is like Substitute, but substitutes each of the strings
+// within the given value, which may be of any type, returning a value of the
+// same type with the results. All of the strings are assumed to have come
+// from the given source range.
+func (s *Scope) SubstituteValue(val cty.Value, rng hcl.Range) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret, _ := cty.Transform(val, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		if v.Type() != cty.String || v.IsNull() || !v.IsKnown() {
+			return v, nil
+		}
+		v, marks := v.Unmark()
+		str, moreDiags := s.Substitute(v.AsString(), rng)
+		diags = append(diags, moreDiags...)
+		return cty.StringVal(str).WithMarks(marks), nil
+	})
+	return ret, diags
+}
+
+// CheckTraversals returns error diagnostics for each of the given traversals
+// that refers to a variable that is not defined in the scope, or that refers
+// to the variables object without selecting a particular variable. If
+// AllowEnvironment has been called then it also returns error diagnostics
+// for references to environment variables that are not allowed or not set.
+// Traversals with any other root are ignored.
+func (s *Scope) CheckTraversals(traversals []hcl.Traversal) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	var env map[string]cty.Value
+	for _, traversal := range traversals {
+		if traversal.IsRelative() {
+			continue
+		}
+		root := traversal.RootName()
+		if root != VariablesRoot && (root != EnvironmentRoot || !s.envEnabled) {
+			continue
+		}
+		if len(traversal) < 2 {
+			example := "example"
+			if root == EnvironmentRoot {
+				example = "HOME"
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable reference",
+				Detail:   fmt.Sprintf("The %q object must be followed by the name of a variable, like %s.%s.", root, root, example),
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+			continue
+		}
+
+		var name string
+		switch step := traversal[1].(type) {
+		case hcl.TraverseAttr:
+			name = step.Name
+		case hcl.TraverseIndex:
+			if step.Key.Type() == cty.String && step.Key.IsKnown() && !step.Key.IsNull() {
+				name = step.Key.AsString()
+			}
+		}
+		if name == "" {
+			// Other kinds of step will fail during evaluation with a more
+			// specific error message.
+			continue
+		}
+		rng := hcl.RangeBetween(traversal[0].SourceRange(), traversal[1].SourceRange())
+
+		if root == EnvironmentRoot {
+			if env == nil {
+				env = s.environment()
+			}
+			_, set := env[name]
+			switch {
+			case !envAllowed(s.envAllowed, name):
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Environment variable not allowed",
+					Detail:   fmt.Sprintf("The environment variable %q is not available to this configuration.", name),
+					Subject:  &rng,
+				})
+			case !set:
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Undefined environment variable",
+					Detail:   fmt.Sprintf("The environment variable %q is not set.", name),
+					Subject:  &rng,
+				})
+			}
+			continue
+		}
+
+		if _, exists := s.variables[name]; exists {
+			continue
+		}
+
+		detail := fmt.Sprintf("There is no variable named %q.", name)
+		if suggestion := nameSuggestion(name, s.variableNames()); suggestion != "" {
+			detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Undefined variable",
+			Detail:   detail,
+			Subject:  &rng,
+		})
+	}
+	return diags
+}
+
+func (s *Scope) variableNames() []string {
+	names := make([]string, 0, len(s.variables))
+	for name := range s.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
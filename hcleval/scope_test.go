@@ -0,0 +1,382 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcleval
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestScopeEvaluate(t *testing.T) {
+	s := NewScope()
+	s.SetVariable("name", cty.StringVal("web"))
+	s.SetVariables(map[string]cty.Value{
+		"count": cty.NumberIntVal(3),
+	})
+
+	tests := []struct {
+		src       string
+		want      cty.Value
+		wantDiags []string
+	}{
+		{
+			`"${var.name}-${var.count}"`,
+			cty.StringVal("web-3"),
+			nil,
+		},
+		{
+			`var["name"]`,
+			cty.StringVal("web"),
+			nil,
+		},
+		{
+			`local.foo`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,1-6: Unknown variable; There is no variable named "local".`},
+		},
+		{
+			`"${var.nmae}"`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,4-12: Undefined variable; There is no variable named "nmae". Did you mean "name"?`},
+		},
+		{
+			`[var.a, var.b]`,
+			cty.DynamicVal,
+			[]string{
+				`test.hcl:1,2-7: Undefined variable; There is no variable named "a".`,
+				`test.hcl:1,9-14: Undefined variable; There is no variable named "b".`,
+			},
+		},
+		{
+			`var`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,1-4: Invalid variable reference; The "var" object must be followed by the name of a variable, like var.example.`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := s.Evaluate(expr)
+			assertDiags(t, diags, test.wantDiags)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScopeConditionals(t *testing.T) {
+	s := NewScope()
+	s.SetVariable("env", cty.StringVal("prod"))
+	s.SetVariable("replicas", cty.NumberIntVal(3))
+	rng := hcl.Range{Filename: "values.json", Start: hcl.InitialPos}
+
+	tests := []struct {
+		src       string
+		want      string
+		wantDiags []string
+	}{
+		{
+			`${var.env == "prod" ? "large" : "small"}`,
+			"large",
+			nil,
+		},
+		{
+			`size-${var.replicas > 5 ? "big" : var.env != "prod" ? "tiny" : "medium"}`,
+			"size-medium",
+			nil,
+		},
+		{
+			`%{ if var.env == "prod" }live%{ else }test%{ endif }`,
+			"live",
+			nil,
+		},
+		{
+			`${var.replicas ? "a" : "b"}`,
+			"${var.replicas ? \"a\" : \"b\"}",
+			[]string{`values.json:1,3-15: Incorrect condition type; The condition expression must be of type bool.`},
+		},
+		{
+			`${var.env == "prod" ? var.missing : "x"}`,
+			"${var.env == \"prod\" ? var.missing : \"x\"}",
+			[]string{`values.json:1,23-34: Undefined variable; There is no variable named "missing".`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			got, diags := s.Substitute(test.src, rng)
+			assertDiags(t, diags, test.wantDiags)
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScopeOperators(t *testing.T) {
+	s := NewScope()
+	s.SetVariable("n", cty.NumberIntVal(7))
+	s.SetVariable("enabled", cty.True)
+
+	tests := []struct {
+		src       string
+		want      cty.Value
+		wantDiags []string
+	}{
+		{`1 + 2 * 3`, cty.NumberIntVal(7), nil},
+		{`(1 + 2) * 3`, cty.NumberIntVal(9), nil},
+		{`var.n % 4 - 10 / 4`, cty.NumberFloatVal(0.5), nil},
+		{`-var.n + 1`, cty.NumberIntVal(-6), nil},
+		{`var.n > 5 && var.n <= 7`, cty.True, nil},
+		{`var.n == 7 || 1 / 0 > 0`, cty.True, nil},
+		{`!var.enabled || var.n != 7`, cty.False, nil},
+		{`1 + 2 < 4 == true`, cty.True, nil},
+		{
+			`var.n + "x"`,
+			cty.UnknownVal(cty.Number),
+			[]string{`test.hcl:1,9-12: Invalid operand; Unsuitable value for right operand: a number is required.`},
+		},
+		{
+			`var.enabled && 1`,
+			cty.UnknownVal(cty.Bool),
+			[]string{`test.hcl:1,16-17: Invalid operand; Unsuitable value for right operand: bool required.`},
+		},
+		{
+			`!var.n`,
+			cty.UnknownVal(cty.Bool),
+			[]string{`test.hcl:1,2-7: Invalid operand; Unsuitable value for unary operand: bool required.`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := s.Evaluate(expr)
+			assertDiags(t, diags, test.wantDiags)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScopeForAndSplat(t *testing.T) {
+	s := NewScope()
+	s.SetVariable("servers", cty.ListVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("a"), "port": cty.NumberIntVal(80)}),
+		cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("b"), "port": cty.NumberIntVal(443)}),
+	}))
+
+	tests := []struct {
+		src       string
+		want      cty.Value
+		wantDiags []string
+	}{
+		{
+			`var.servers.*.name`,
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			nil,
+		},
+		{
+			`var.servers[*].port`,
+			cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+			nil,
+		},
+		{
+			`[for s in var.servers : upper(s.name) if s.port > 100]`,
+			cty.TupleVal([]cty.Value{cty.StringVal("B")}),
+			nil,
+		},
+		{
+			`{for s in var.servers : s.name => s.port}`,
+			cty.ObjectVal(map[string]cty.Value{"a": cty.NumberIntVal(80), "b": cty.NumberIntVal(443)}),
+			nil,
+		},
+		{
+			`[for s in var.hosts : s]`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,11-20: Undefined variable; There is no variable named "hosts".`},
+		},
+	}
+
+	s.SetFunctions(StandardFunctions("."))
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := s.Evaluate(expr)
+			assertDiags(t, diags, test.wantDiags)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScopeDecodeBody(t *testing.T) {
+	type config struct {
+		Name string `hcl:"name"`
+		Port int    `hcl:"port"`
+	}
+
+	s := NewScope()
+	s.SetVariable("env", cty.StringVal("prod"))
+	s.SetVariable("port", cty.NumberIntVal(8080))
+
+	f, diags := hclsyntax.ParseConfig([]byte("name = \"app-${var.env}\"\nport = var.port\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+	var got config
+	diags = s.DecodeBody(f.Body, &got)
+	assertDiags(t, diags, nil)
+	if want := (config{Name: "app-prod", Port: 8080}); got != want {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	f, _ = hclsyntax.ParseConfig([]byte("name = var.region\nport = var.prot\n"), "test.hcl", hcl.InitialPos)
+	diags = s.DecodeBody(f.Body, &got)
+	assertDiags(t, diags, []string{
+		`test.hcl:1,8-18: Undefined variable; There is no variable named "region".`,
+		`test.hcl:2,8-16: Undefined variable; There is no variable named "prot". Did you mean "port"?`,
+	})
+
+	jf, diags := json.Parse([]byte(`{"name": "app-${var.env}", "port": 1}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+	diags = s.DecodeBody(jf.Body, &got)
+	assertDiags(t, diags, nil)
+	if want := (config{Name: "app-prod", Port: 1}); got != want {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestScopeSubstitute(t *testing.T) {
+	s := NewScope()
+	s.SetVariable("name", cty.StringVal("web"))
+	rng := hcl.Range{Filename: "values.json", Start: hcl.Pos{Line: 3, Column: 10, Byte: 30}}
+
+	got, diags := s.Substitute("host-${var.name}", rng)
+	assertDiags(t, diags, nil)
+	if got != "host-web" {
+		t.Errorf("wrong result %q", got)
+	}
+
+	got, diags = s.Substitute("$${literal}", rng)
+	assertDiags(t, diags, nil)
+	if got != "${literal}" {
+		t.Errorf("wrong result %q", got)
+	}
+
+	_, diags = s.Substitute("host-${var.host}", rng)
+	assertDiags(t, diags, []string{
+		`values.json:3,17-25: Undefined variable; There is no variable named "host".`,
+	})
+
+	val, diags := s.SubstituteValue(cty.ObjectVal(map[string]cty.Value{
+		"a": cty.StringVal("${var.name}"),
+		"b": cty.ListVal([]cty.Value{cty.StringVal("x-${var.name}")}),
+		"c": cty.NumberIntVal(1),
+	}), rng)
+	assertDiags(t, diags, nil)
+	want := cty.ObjectVal(map[string]cty.Value{
+		"a": cty.StringVal("web"),
+		"b": cty.ListVal([]cty.Value{cty.StringVal("x-web")}),
+		"c": cty.NumberIntVal(1),
+	})
+	if !val.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", val, want)
+	}
+}
+
+func assertDiags(t *testing.T, diags hcl.Diagnostics, want []string) {
+	t.Helper()
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Error())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong diagnostics\ngot:  %q\nwant: %q", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("wrong diagnostic %d\ngot:  %s\nwant: %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScopeEnvironment(t *testing.T) {
+	t.Setenv("HCLEVAL_TEST_HOME", "/home/test")
+	t.Setenv("HCLEVAL_APP_PORT", "8080")
+	t.Setenv("HCLEVAL_SECRET", "hunter2")
+
+	s := NewScope()
+	s.AllowEnvironment("HCLEVAL_TEST_HOME", "HCLEVAL_APP_*", "HCLEVAL_UNSET")
+
+	tests := []struct {
+		src       string
+		want      cty.Value
+		wantDiags []string
+	}{
+		{
+			`"${env.HCLEVAL_TEST_HOME}:${env.HCLEVAL_APP_PORT}"`,
+			cty.StringVal("/home/test:8080"),
+			nil,
+		},
+		{
+			`env("HCLEVAL_APP_PORT")`,
+			cty.StringVal("8080"),
+			nil,
+		},
+		{
+			`env.HCLEVAL_SECRET`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,1-19: Environment variable not allowed; The environment variable "HCLEVAL_SECRET" is not available to this configuration.`},
+		},
+		{
+			`env.HCLEVAL_UNSET`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,1-18: Undefined environment variable; The environment variable "HCLEVAL_UNSET" is not set.`},
+		},
+		{
+			`env("HCLEVAL_SECRET")`,
+			cty.DynamicVal,
+			[]string{`test.hcl:1,6-20: Invalid function argument; Invalid value for "name" parameter: environment variable "HCLEVAL_SECRET" is not allowed.`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got, diags := s.Evaluate(expr)
+			assertDiags(t, diags, test.wantDiags)
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	// Without AllowEnvironment, "env" is just an unknown variable.
+	expr, _ := hclsyntax.ParseExpression([]byte(`env.HCLEVAL_TEST_HOME`), "test.hcl", hcl.InitialPos)
+	_, diags := NewScope().Evaluate(expr)
+	assertDiags(t, diags, []string{`test.hcl:1,1-4: Unknown variable; There is no variable named "env".`})
+}
@@ -0,0 +1,16 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclgen generates Go source code declaring struct types that
+// correspond to a schema, as understood by the "hclschema" package, so that
+// applications can bootstrap their typed configuration code rather than
+// writing it by hand.
+//
+// The generated structs carry the "hcl" field tags understood by package
+// "gohcl", and so a configuration body that is valid per the schema can be
+// decoded directly into them. The output is intended as a starting point to
+// be edited and maintained alongside the application, rather than to be
+// regenerated on each build.
+//
+// The cmd/hclgen program wraps this package for use from the command line.
+package hclgen
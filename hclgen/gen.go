@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2/hclschema"
+)
+
+// Generate returns Go source code for a file declaring a struct type for the
+// top-level body of the given schema and for each block type that it
+// describes, at any level of nesting.
+//
+// Each attribute becomes a field of a type corresponding to the attribute's
+// type, tagged as optional unless the attribute is required. Attributes of
+// the "any" type, or of tuple types, become fields of type cty.Value. Each
+// block type becomes a field whose type is a value if exactly one block is
+// required, a pointer if at most one block is allowed, or a slice of pointers
+// otherwise. The labels of a block become fields of its own struct type,
+// ahead of those for its body.
+//
+// The descriptions in the schema become the doc comments of the
+// corresponding types and fields. The result is formatted in the same way as
+// by gofmt.
+func Generate(schema *hclschema.Body, opts ...GenOption) ([]byte, error) {
+	o := newGenOpts(opts)
+	if !token.IsIdentifier(o.packageName) {
+		return nil, fmt.Errorf("invalid package name %q", o.packageName)
+	}
+	if !token.IsIdentifier(o.typeName) {
+		return nil, fmt.Errorf("invalid type name %q", o.typeName)
+	}
+
+	g := &generator{
+		typeNames: map[string]bool{o.typeName: true},
+	}
+	g.writeStruct(o.typeName, "is the top-level configuration body.", "", nil, schema)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", o.packageName)
+	if g.usesCty {
+		buf.WriteString("import \"github.com/zclconf/go-cty/cty\"\n\n")
+	}
+	buf.Write(g.buf.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+type generator struct {
+	buf       bytes.Buffer
+	typeNames map[string]bool
+	usesCty   bool
+}
+
+// writeStruct writes the declaration of a struct type with the given name
+// for a body with the given labels and content, followed by those of the
+// types for its nested block types.
+func (g *generator) writeStruct(name, summary, description string, labels []string, body *hclschema.Body) {
+	var blocks []*hclschema.Block
+	if body != nil {
+		blocks = body.Blocks
+	}
+	// The names of the nested types are chosen before writing any of them,
+	// so that those for block types at shallower levels take precedence.
+	blockTypeNames := make([]string, len(blocks))
+	for i, blockS := range blocks {
+		blockTypeNames[i] = g.typeName(name, blockS.Type)
+	}
+
+	fmt.Fprintf(&g.buf, "// %s %s\n", name, summary)
+	if description != "" {
+		g.buf.WriteString("//\n")
+		writeComment(&g.buf, description)
+	}
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+
+	fieldNames := make(map[string]bool)
+	for _, label := range labels {
+		fmt.Fprintf(&g.buf, "%s string `hcl:%q`\n", uniqueName(fieldNames, goName(label)), label+",label")
+	}
+	if body != nil {
+		for _, attrS := range body.Attributes {
+			writeComment(&g.buf, attrS.Description)
+			tag := attrS.Name
+			if !attrS.Required {
+				tag += ",optional"
+			}
+			fmt.Fprintf(&g.buf, "%s %s `hcl:%q`\n", uniqueName(fieldNames, goName(attrS.Name)), g.goType(attrS.Type), tag)
+		}
+	}
+	for i, blockS := range blocks {
+		writeComment(&g.buf, blockS.Description)
+		var typeStr string
+		switch {
+		case blockS.MaxItems == 1 && blockS.MinItems >= 1:
+			typeStr = blockTypeNames[i]
+		case blockS.MaxItems == 1:
+			typeStr = "*" + blockTypeNames[i]
+		default:
+			typeStr = "[]*" + blockTypeNames[i]
+		}
+		fmt.Fprintf(&g.buf, "%s %s `hcl:%q`\n", uniqueName(fieldNames, goName(blockS.Type)), typeStr, blockS.Type+",block")
+	}
+	g.buf.WriteString("}\n\n")
+
+	for i, blockS := range blocks {
+		summary := fmt.Sprintf("represents a %q block.", blockS.Type)
+		g.writeStruct(blockTypeNames[i], summary, blockS.Description, blockS.LabelNames, blockS.Body)
+	}
+}
+
+// typeName returns an unused name for the type corresponding to the given
+// block type nested within the type with the given name. The name is based
+// on that of the block type alone where possible, and otherwise is prefixed
+// by the name of the containing type.
+func (g *generator) typeName(parent, blockType string) string {
+	name := goName(blockType)
+	if g.typeNames[name] {
+		name = parent + name
+	}
+	return uniqueName(g.typeNames, name)
+}
+
+// goType returns the Go type that values of the given type decode into.
+func (g *generator) goType(ty cty.Type) string {
+	switch {
+	case ty == cty.String:
+		return "string"
+	case ty == cty.Number:
+		return "float64"
+	case ty == cty.Bool:
+		return "bool"
+	case ty.IsListType() || ty.IsSetType():
+		return "[]" + g.goType(ty.ElementType())
+	case ty.IsMapType():
+		return "map[string]" + g.goType(ty.ElementType())
+	case ty.IsObjectType():
+		var buf strings.Builder
+		buf.WriteString("struct {\n")
+		fieldNames := make(map[string]bool)
+		for _, attrName := range sortedAttributeNames(ty) {
+			fmt.Fprintf(&buf, "%s %s `cty:%q`\n", uniqueName(fieldNames, goName(attrName)), g.goType(ty.AttributeType(attrName)), attrName)
+		}
+		buf.WriteString("}")
+		return buf.String()
+	default:
+		g.usesCty = true
+		return "cty.Value"
+	}
+}
+
+// sortedAttributeNames returns the attribute names of the given object type
+// in lexical order.
+func sortedAttributeNames(ty cty.Type) []string {
+	var ret []string
+	for name := range ty.AttributeTypes() {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// writeComment writes the given text as a line comment, or writes nothing if
+// the text is empty.
+func writeComment(buf *bytes.Buffer, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			buf.WriteString("//\n")
+			continue
+		}
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+}
+
+// uniqueName returns the given name, or the name followed by the smallest
+// number of two or greater that makes it unique among the given names, and
+// records the result as used.
+func uniqueName(used map[string]bool, name string) string {
+	ret := name
+	for i := 2; used[ret]; i++ {
+		ret = name + strconv.Itoa(i)
+	}
+	used[ret] = true
+	return ret
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclgen
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclschema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestGenerate(t *testing.T) {
+	src := `
+attribute "name" {
+  type        = string
+  required    = true
+  description = "The name of the service."
+}
+
+attribute "max_ttl" {
+  type = number
+}
+
+attribute "tags" {
+  type = map(string)
+}
+
+attribute "extra" {}
+
+attribute "owner" {
+  type = object({ email = string, team_id = number })
+}
+
+block "listener" {
+  labels = ["protocol"]
+
+  attribute "port" {
+    type     = number
+    required = true
+  }
+
+  block "tls" {
+    max_items = 1
+
+    attribute "cert_file" {
+      type = string
+    }
+  }
+}
+
+block "server" {
+  min_items = 1
+  max_items = 1
+
+  block "listener" {
+    description = "An internal listener."
+  }
+}
+`
+	want := `package config
+
+import "github.com/zclconf/go-cty/cty"
+
+// Config is the top-level configuration body.
+type Config struct {
+	// The name of the service.
+	Name   string            ` + "`" + `hcl:"name"` + "`" + `
+	MaxTTL float64           ` + "`" + `hcl:"max_ttl,optional"` + "`" + `
+	Tags   map[string]string ` + "`" + `hcl:"tags,optional"` + "`" + `
+	Extra  cty.Value         ` + "`" + `hcl:"extra,optional"` + "`" + `
+	Owner  struct {
+		Email  string  ` + "`" + `cty:"email"` + "`" + `
+		TeamID float64 ` + "`" + `cty:"team_id"` + "`" + `
+	} ` + "`" + `hcl:"owner,optional"` + "`" + `
+	Listener []*Listener ` + "`" + `hcl:"listener,block"` + "`" + `
+	Server   Server      ` + "`" + `hcl:"server,block"` + "`" + `
+}
+
+// Listener represents a "listener" block.
+type Listener struct {
+	Protocol string  ` + "`" + `hcl:"protocol,label"` + "`" + `
+	Port     float64 ` + "`" + `hcl:"port"` + "`" + `
+	TLS      *TLS    ` + "`" + `hcl:"tls,block"` + "`" + `
+}
+
+// TLS represents a "tls" block.
+type TLS struct {
+	CertFile string ` + "`" + `hcl:"cert_file,optional"` + "`" + `
+}
+
+// Server represents a "server" block.
+type Server struct {
+	// An internal listener.
+	Listener []*ServerListener ` + "`" + `hcl:"listener,block"` + "`" + `
+}
+
+// ServerListener represents a "listener" block.
+//
+// An internal listener.
+type ServerListener struct {
+}
+`
+
+	file, diags := hclsyntax.ParseConfig([]byte(src), "schema.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	schema, diags := hclschema.DecodeSchema(file.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got, err := Generate(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestGenerateOptions(t *testing.T) {
+	got, err := Generate(nil, OptPackageName("app"), OptTypeName("Settings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package app\n\n// Settings is the top-level configuration body.\ntype Settings struct {\n}\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	if _, err := Generate(nil, OptTypeName("not valid")); err == nil {
+		t.Error("no error for invalid type name")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"name":         "Name",
+		"max_ttl":      "MaxTTL",
+		"instance-id":  "InstanceID",
+		"http_url":     "HTTPURL",
+		"alreadyCamel": "AlreadyCamel",
+		"2fa":          "X2fa",
+	}
+	for input, want := range tests {
+		if got := goName(input); got != want {
+			t.Errorf("wrong name for %q: got %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclgen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words that are written entirely in upper case when
+// they appear in Go names, following the conventions of the Go standard
+// library.
+var initialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"CIDR":  true,
+	"CPU":   true,
+	"DB":    true,
+	"DNS":   true,
+	"HCL":   true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"URI":   true,
+	"URL":   true,
+	"UUID":  true,
+	"XML":   true,
+}
+
+// goName returns an exported Go name corresponding to the given HCL
+// identifier, by capitalizing each of the words that are separated by
+// underscores or dashes and joining them together, so that "max_ttl"
+// becomes "MaxTTL".
+func goName(name string) string {
+	var buf strings.Builder
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+
+	ret := buf.String()
+	if ret == "" || !unicode.IsUpper([]rune(ret)[0]) {
+		// Identifiers that begin with a digit or with a letter that has no
+		// upper case can't be exported as they are.
+		ret = "X" + ret
+	}
+	return ret
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclgen
+
+// GenOption is implemented by values that can customize the behavior of
+// Generate. Use the functions in this package whose names begin with "Opt" to
+// produce generator options.
+type GenOption interface {
+	applyGenOption(*genOpts)
+}
+
+type genOpts struct {
+	packageName string
+	typeName    string
+}
+
+func newGenOpts(opts []GenOption) *genOpts {
+	ret := &genOpts{
+		packageName: "config",
+		typeName:    "Config",
+	}
+	for _, opt := range opts {
+		opt.applyGenOption(ret)
+	}
+	return ret
+}
+
+type optPackageName struct {
+	name string
+}
+
+// OptPackageName sets the name of the package that the generated source
+// belongs to. Without this option, the package is named "config".
+func OptPackageName(name string) GenOption {
+	return optPackageName{name}
+}
+
+// applyGenOption implements GenOption.
+func (o optPackageName) applyGenOption(opts *genOpts) {
+	opts.packageName = o.name
+}
+
+type optTypeName struct {
+	name string
+}
+
+// OptTypeName sets the name of the struct type that corresponds to the
+// top-level body of the schema. Without this option, the type is named
+// "Config".
+//
+// The types corresponding to nested block types are named after the block
+// types themselves, prefixed by the name of the type that contains them if
+// necessary to make their names unique.
+func OptTypeName(name string) GenOption {
+	return optTypeName{name}
+}
+
+// applyGenOption implements GenOption.
+func (o optTypeName) applyGenOption(opts *genOpts) {
+	opts.typeName = o.name
+}
@@ -0,0 +1,14 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclhash computes hashes of configuration that change only when
+// its meaning changes, so that applications can cheaply detect whether a
+// configuration file was changed in a way that matters, such as to decide
+// whether to reload it.
+//
+// File hashes the syntax tree of a native syntax file, ignoring whitespace,
+// comments, the order of attributes and the way each literal value is
+// written, and optionally the order of blocks. Value hashes a value after
+// it has been decoded, for applications that would rather detect changes
+// in the values that configuration produces.
+package hclhash
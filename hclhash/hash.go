@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// Sum is a hash computed by this package.
+type Sum [sha256.Size]byte
+
+// String returns the hash in hexadecimal.
+func (s Sum) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// File returns a hash of the given native syntax file that changes only
+// when its meaning changes.
+//
+// Whitespace, comments, the order of attributes within a body and trailing
+// commas never affect the hash. Expressions that can be evaluated without
+// any variables or functions are hashed by value, so that for example 1.0
+// and 1 have the same hash. Other expressions are hashed by their tokens.
+// The order of blocks affects the hash unless OptIgnoreBlockOrder is used.
+//
+// Files in other syntaxes are not supported, and produce an error
+// diagnostic.
+func File(file *hcl.File, opts ...HashOption) (Sum, hcl.Diagnostics) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return Sum{}, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported body",
+				Detail:   "Only files in the native syntax can be hashed.",
+				Subject:  file.Body.MissingItemRange().Ptr(),
+			},
+		}
+	}
+
+	h := &hasher{src: file.Bytes, opts: newHashOpts(opts)}
+	return h.body(body), nil
+}
+
+// Value returns a hash of the given value, which includes its type. Values
+// that are equal have the same hash, regardless of how they were written in
+// the configuration that produced them.
+//
+// The value must be wholly known and have no marks, or Value returns an
+// error.
+func Value(val cty.Value) (Sum, error) {
+	h := sha256.New()
+	if err := writeValue(h, val); err != nil {
+		return Sum{}, err
+	}
+	var ret Sum
+	h.Sum(ret[:0])
+	return ret, nil
+}
+
+type hasher struct {
+	src  []byte
+	opts *hashOpts
+}
+
+func (h *hasher) body(body *hclsyntax.Body) Sum {
+	w := sha256.New()
+
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeBytes(w, []byte{'A'})
+		writeBytes(w, []byte(name))
+		h.expr(w, body.Attributes[name].Expr)
+	}
+
+	// Each block is hashed separately, so that we can sort the results if
+	// the order of blocks is to be ignored.
+	blocks := make([]Sum, len(body.Blocks))
+	for i, block := range body.Blocks {
+		bw := sha256.New()
+		writeBytes(bw, []byte(block.Type))
+		for _, label := range block.Labels {
+			writeBytes(bw, []byte(label))
+		}
+		sum := h.body(block.Body)
+		writeBytes(bw, sum[:])
+		bw.Sum(blocks[i][:0])
+	}
+	if h.opts.ignoreBlockOrder {
+		sort.Slice(blocks, func(i, j int) bool {
+			return bytes.Compare(blocks[i][:], blocks[j][:]) < 0
+		})
+	}
+	for _, sum := range blocks {
+		writeBytes(w, []byte{'B'})
+		writeBytes(w, sum[:])
+	}
+
+	var ret Sum
+	w.Sum(ret[:0])
+	return ret
+}
+
+func (h *hasher) expr(w hash.Hash, expr hclsyntax.Expression) {
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+		var buf bytes.Buffer
+		if err := writeValue(&buf, val); err == nil {
+			writeBytes(w, []byte{'V'})
+			writeBytes(w, buf.Bytes())
+			return
+		}
+	}
+
+	writeBytes(w, []byte{'T'})
+	for _, tok := range hclsyntaxutil.SignificantTokens(expr.Range().SliceBytes(h.src)) {
+		var typ [binary.MaxVarintLen32]byte
+		writeBytes(w, typ[:binary.PutUvarint(typ[:], uint64(tok.Type))])
+		writeBytes(w, tok.Bytes)
+	}
+}
+
+// writeValue writes a canonical encoding of the given value and its type.
+func writeValue(w io.Writer, val cty.Value) error {
+	if val.ContainsMarked() {
+		return errors.New("value has marks")
+	}
+	ty, err := ctyjson.MarshalType(val.Type())
+	if err != nil {
+		return err
+	}
+	v, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return err
+	}
+	writeBytes(w, ty)
+	writeBytes(w, v)
+	return nil
+}
+
+// writeBytes writes the given bytes prefixed by their length, so that the
+// boundaries between the items written are unambiguous.
+func writeBytes(w io.Writer, b []byte) {
+	var l [binary.MaxVarintLen64]byte
+	w.Write(l[:binary.PutUvarint(l[:], uint64(len(b)))])
+	w.Write(b)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclhash
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+func TestFile(t *testing.T) {
+	base := `
+a = 1
+b = [var.x, var.y]
+
+server "web" {
+  port = 80
+}
+
+server "api" {
+  port = 8080
+}
+`
+	tests := map[string]struct {
+		src       string
+		opts      []HashOption
+		wantEqual bool
+	}{
+		"identical": {
+			base,
+			nil,
+			true,
+		},
+		"insignificant": {
+			`# header
+b = [
+  var.x, # first
+  var.y,
+]
+a = 1.0
+server "web" { port = 80 }
+server "api" {
+  port = 8080 // api
+}
+`,
+			nil,
+			true,
+		},
+		"changed value": {
+			`a = 2
+b = [var.x, var.y]
+server "web" { port = 80 }
+server "api" { port = 8080 }
+`,
+			nil,
+			false,
+		},
+		"changed expression": {
+			`a = 1
+b = [var.y, var.x]
+server "web" { port = 80 }
+server "api" { port = 8080 }
+`,
+			nil,
+			false,
+		},
+		"block order": {
+			`a = 1
+b = [var.x, var.y]
+server "api" { port = 8080 }
+server "web" { port = 80 }
+`,
+			nil,
+			false,
+		},
+		"block order ignored": {
+			`a = 1
+b = [var.x, var.y]
+server "api" { port = 8080 }
+server "web" { port = 80 }
+`,
+			[]HashOption{OptIgnoreBlockOrder()},
+			true,
+		},
+		"changed label": {
+			`a = 1
+b = [var.x, var.y]
+server "www" { port = 80 }
+server "api" { port = 8080 }
+`,
+			[]HashOption{OptIgnoreBlockOrder()},
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := hashSource(t, base, test.opts...)
+			got := hashSource(t, test.src, test.opts...)
+			if (got == want) != test.wantEqual {
+				t.Errorf("wrong result: hash equality is %t; want %t", got == want, test.wantEqual)
+			}
+		})
+	}
+}
+
+func TestFileJSON(t *testing.T) {
+	file, _ := json.Parse([]byte(`{"a": 1}`), "test.json")
+	_, diags := File(file)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+}
+
+func TestValue(t *testing.T) {
+	a, err := Value(cty.ObjectVal(map[string]cty.Value{
+		"port": cty.NumberFloatVal(80.0),
+		"tags": cty.SetVal([]cty.Value{cty.StringVal("b"), cty.StringVal("a")}),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Value(cty.ObjectVal(map[string]cty.Value{
+		"tags": cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		"port": cty.NumberIntVal(80),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("equal values have different hashes %s and %s", a, b)
+	}
+
+	// The type is part of the hash.
+	c, _ := Value(cty.ListVal([]cty.Value{cty.StringVal("a")}))
+	d, _ := Value(cty.TupleVal([]cty.Value{cty.StringVal("a")}))
+	if c == d {
+		t.Errorf("values of different types have the same hash %s", c)
+	}
+
+	if _, err := Value(cty.UnknownVal(cty.String)); err == nil {
+		t.Errorf("unknown value succeeded; want error")
+	}
+	if _, err := Value(cty.StringVal("x").Mark("sensitive")); err == nil {
+		t.Errorf("marked value succeeded; want error")
+	}
+}
+
+func hashSource(t *testing.T, src string, opts ...HashOption) Sum {
+	t.Helper()
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	sum, diags := File(file, opts...)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	return sum
+}
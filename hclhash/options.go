@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclhash
+
+// HashOption is implemented by values that can customize the behavior of
+// File. Use the functions in this package whose names begin with "Opt" to
+// produce hash options.
+type HashOption interface {
+	applyHashOption(*hashOpts)
+}
+
+type hashOpts struct {
+	ignoreBlockOrder bool
+}
+
+func newHashOpts(opts []HashOption) *hashOpts {
+	ret := &hashOpts{}
+	for _, opt := range opts {
+		opt.applyHashOption(ret)
+	}
+	return ret
+}
+
+type optIgnoreBlockOrder struct{}
+
+// OptIgnoreBlockOrder causes File to ignore the order of the blocks within
+// each body, for applications in which the order of blocks has no meaning.
+// Blocks with the same type and labels are still hashed individually, so
+// duplicating a block does change the hash.
+func OptIgnoreBlockOrder() HashOption {
+	return optIgnoreBlockOrder{}
+}
+
+// applyHashOption implements HashOption.
+func (o optIgnoreBlockOrder) applyHashOption(opts *hashOpts) {
+	opts.ignoreBlockOrder = true
+}
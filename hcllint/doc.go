@@ -0,0 +1,17 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hcllint checks native syntax configuration files for problems of
+// style and correctness that the parser accepts, such as duplicate keys in
+// object constructors and empty blocks.
+//
+// Each problem is reported as a warning diagnostic, from which RuleName
+// returns the name of the rule that produced it. Rules can be disabled for
+// all files using Config, or for parts of a file using a comment directive
+// like the following, which applies to the attribute or block it precedes or
+// follows on the same line, or to the whole file if it stands alone:
+//
+//	# hcllint:disable empty-block
+//
+// A directive with no rule names disables all of the rules.
+package hcllint
@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcllint
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// DirectivePrefix is the prefix of the comment directives that disable rules
+// for part of a file. See the package documentation for details.
+const DirectivePrefix = "hcllint:"
+
+// Config customizes the behavior of Lint. The zero value enables all of the
+// rules, deprecates no attributes and enforces no trailing comma policy.
+type Config struct {
+	// Disabled is the set of names of rules that Lint should not check.
+	Disabled map[string]bool
+
+	// Deprecated maps the names of deprecated arguments to the names of the
+	// arguments that replace them, for the "deprecated-attribute" rule. The
+	// replacement may be empty if there is none.
+	Deprecated map[string]string
+
+	// TrailingCommas is the policy that the "trailing-comma" rule enforces
+	// for the commas after the last elements of tuple and object
+	// constructors, with the same meaning as for hclwrite.OptTrailingCommas.
+	// The rule reports nothing under hclwrite.TrailingCommasPreserve.
+	TrailingCommas hclwrite.TrailingCommas
+}
+
+// Lint checks the given file, which must have been parsed from native
+// syntax, against the rules enabled by the given configuration. The
+// configuration may be nil to enable all of the rules.
+//
+// The problems found are returned as warning diagnostics in the order they
+// appear in the file. Lint returns an error diagnostic only if the file is
+// not a native syntax file.
+func Lint(file *hcl.File, config *Config) hcl.Diagnostics {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported body",
+				Detail:   "Only files in the native syntax can be checked.",
+				Subject:  file.Body.MissingItemRange().Ptr(),
+			},
+		}
+	}
+	if config == nil {
+		config = &Config{}
+	}
+
+	l := &linter{
+		config:     config,
+		src:        file.Bytes,
+		srcRange:   sourceRange(body, file.Bytes),
+		directives: hclsyntax.Directives(file, DirectivePrefix),
+	}
+	for _, rule := range rules {
+		if config.Disabled[rule.Name] {
+			continue
+		}
+		rule.check(l, body)
+	}
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		return l.diags[i].Subject.Start.Byte < l.diags[j].Subject.Start.Byte
+	})
+	return l.diags
+}
+
+// RuleName returns the name of the rule that produced the given diagnostic,
+// or an empty string if the diagnostic was not produced by Lint.
+func RuleName(diag *hcl.Diagnostic) string {
+	extra, ok := hcl.DiagnosticExtra[ruleExtra](diag)
+	if !ok {
+		return ""
+	}
+	return extra.rule
+}
+
+// ruleExtra is the type of the Extra field of the diagnostics that Lint
+// returns.
+type ruleExtra struct {
+	rule string
+}
+
+type linter struct {
+	config     *Config
+	src        []byte
+	srcRange   hcl.Range // the range of the whole of src
+	directives []hclsyntax.Directive
+	tokens     hclsyntax.Tokens // lexed on demand by lexedTokens
+	diags      hcl.Diagnostics
+}
+
+// sourceRange returns the range of the whole of the given source code, from
+// which the given body was parsed.
+//
+// The body's range ends at the end of the source code but begins at its
+// first token, which may follow whitespace or a comment, so we must work
+// back from there to find the start position the parser was given.
+func sourceRange(body *hclsyntax.Body, src []byte) hcl.Range {
+	rng := body.Range()
+	start := rng.End
+	start.Byte -= len(src)
+	skipped := src[:rng.Start.Byte-start.Byte]
+	start.Line = rng.Start.Line - bytes.Count(skipped, []byte{'\n'})
+	start.Column = 1
+	if !bytes.Contains(skipped, []byte{'\n'}) {
+		start.Column = rng.Start.Column
+		for len(skipped) > 0 {
+			adv, _, _ := textseg.ScanGraphemeClusters(skipped, true)
+			start.Column--
+			skipped = skipped[adv:]
+		}
+	}
+	return hcl.Range{Filename: rng.Filename, Start: start, End: rng.End}
+}
+
+// lexedTokens returns the tokens of the source code, lexing it on first use
+// with the same filename and start position that it was parsed with, so
+// that the tokens' ranges agree with those of the syntax tree.
+func (l *linter) lexedTokens() hclsyntax.Tokens {
+	if l.tokens == nil {
+		l.tokens, _ = hclsyntax.LexConfig(l.src, l.srcRange.Filename, l.srcRange.Start)
+	}
+	return l.tokens
+}
+
+func (l *linter) report(rule, summary, detail string, rng hcl.Range) {
+	if l.suppressed(rule, rng) {
+		return
+	}
+	l.diags = append(l.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  rng.Ptr(),
+		Extra:    ruleExtra{rule},
+	})
+}
+
+// suppressed returns true if a "disable" directive applies the given rule
+// to a node that contains the given range, or to the whole file.
+func (l *linter) suppressed(rule string, rng hcl.Range) bool {
+	for _, d := range l.directives {
+		if d.Name != "disable" {
+			continue
+		}
+		if d.Node != nil {
+			nodeRng := d.Node.Range()
+			if rng.Start.Byte < nodeRng.Start.Byte || rng.End.Byte > nodeRng.End.Byte {
+				continue
+			}
+		}
+		if len(d.Args) == 0 {
+			return true
+		}
+		for _, arg := range d.Args {
+			if arg == rule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// visitAttributes calls the given function for each attribute in the given
+// body and its nested blocks.
+func (l *linter) visitAttributes(body *hclsyntax.Body, f func(*hclsyntax.Attribute)) {
+	for _, attr := range body.Attributes {
+		f(attr)
+	}
+	for _, block := range body.Blocks {
+		l.visitAttributes(block.Body, f)
+	}
+}
+
+// visitBlocks calls the given function for each block nested within the
+// given body, in the order they appear.
+func (l *linter) visitBlocks(body *hclsyntax.Body, f func(*hclsyntax.Block)) {
+	for _, block := range body.Blocks {
+		f(block)
+		l.visitBlocks(block.Body, f)
+	}
+}
+
+// visitExprs calls the given function for each expression within the
+// attributes of the given body and its nested blocks, including nested
+// expressions.
+func (l *linter) visitExprs(body *hclsyntax.Body, f func(hclsyntax.Expression)) {
+	l.visitAttributes(body, func(attr *hclsyntax.Attribute) {
+		hclsyntax.VisitAll(attr.Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			if expr, ok := node.(hclsyntax.Expression); ok {
+				f(expr)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcllint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+func TestLint(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		config *Config
+		want   []string
+	}{
+		"clean": {
+			`
+a = { b = 1, c = 2 }
+thing "x" {
+  d = 3
+}
+`,
+			nil,
+			nil,
+		},
+		"duplicate key": {
+			`
+a = {
+  b = 1
+  "b" = 2
+  c = { d = 1, d = 2 }
+}
+`,
+			nil,
+			[]string{
+				"duplicate-key 4:3: Duplicate object key",
+				"inconsistent-quoting 4:3: Inconsistent key quoting",
+				"duplicate-key 5:16: Duplicate object key",
+			},
+		},
+		"empty block": {
+			`
+thing "x" {
+}
+other {
+  inner {}
+}
+`,
+			nil,
+			[]string{
+				"empty-block 2:1: Empty block",
+				"empty-block 5:3: Empty block",
+			},
+		},
+		"deprecated attribute": {
+			`
+old = 1
+thing {
+  older = 2
+  new = 3
+}
+`,
+			&Config{Deprecated: map[string]string{"old": "new", "older": ""}},
+			[]string{
+				"deprecated-attribute 2:1: Deprecated argument",
+				"deprecated-attribute 4:3: Deprecated argument",
+			},
+		},
+		"inconsistent label quoting": {
+			`
+thing "a" { x = 1 }
+thing b { x = 1 }
+thing "c" "d" { x = 1 }
+`,
+			nil,
+			[]string{
+				"inconsistent-quoting 3:7: Inconsistent label quoting",
+			},
+		},
+		"trailing comma required": {
+			`
+a = [
+  1,
+  2
+]
+b = [1, 2]
+c = {
+  x = 1,
+  y = 2
+}
+d = {
+  x = 1
+  y = 2
+}
+`,
+			&Config{TrailingCommas: hclwrite.TrailingCommasAdd},
+			[]string{
+				"trailing-comma 4:3: Missing trailing comma",
+				"trailing-comma 9:7: Missing trailing comma",
+			},
+		},
+		"trailing comma forbidden": {
+			`
+a = [
+  1,
+  2, # last
+]
+b = { x = 1, }
+c = [1, 2]
+`,
+			&Config{TrailingCommas: hclwrite.TrailingCommasStrip},
+			[]string{
+				"trailing-comma 4:4: Unnecessary trailing comma",
+				"trailing-comma 6:12: Unnecessary trailing comma",
+			},
+		},
+		"disabled by config": {
+			`
+a = { b = 1, "c" = 2 }
+thing {}
+`,
+			&Config{Disabled: map[string]bool{"empty-block": true, "inconsistent-quoting": true}},
+			nil,
+		},
+		"disabled by directive": {
+			`
+# hcllint:disable inconsistent-quoting
+a = { b = 1, "c" = 2 }
+
+b = { b = 1, "c" = 2 } # hcllint:disable
+
+thing {} # hcllint:disable duplicate-key
+`,
+			nil,
+			[]string{
+				"empty-block 7:1: Empty block",
+			},
+		},
+		"disabled by indented directive": {
+			"  # hcllint:disable empty-block\n  thing {}\n",
+			nil,
+			nil,
+		},
+		"disabled by file directive": {
+			`
+# hcllint:disable empty-block
+
+thing {}
+other {}
+`,
+			nil,
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			diags = Lint(file, test.config)
+
+			var got []string
+			for _, diag := range diags {
+				if diag.Severity != hcl.DiagWarning {
+					t.Errorf("wrong severity for %s", diag.Error())
+				}
+				got = append(got, fmt.Sprintf("%s %d:%d: %s", RuleName(diag), diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Summary))
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("wrong diagnostics\ngot:  %q\nwant: %q", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("wrong diagnostics\ngot:  %q\nwant: %q", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestLintSourcePos(t *testing.T) {
+	// The trailing comma check lexes the source again, so the positions it
+	// reports must agree with those the file was parsed with.
+	src := "  /* x */ a = [1,]\nb = { \"c\" = 1, d = 2 }\n"
+	start := hcl.Pos{Line: 5, Column: 3, Byte: 100}
+	file, diags := hclsyntax.ParseConfig([]byte(src), "main.hcl", start)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	diags = Lint(file, &Config{TrailingCommas: hclwrite.TrailingCommasStrip})
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, fmt.Sprintf("%s: %s", diag.Subject, diag.Summary))
+	}
+	want := []string{
+		"main.hcl:5,19-20: Unnecessary trailing comma",
+		"main.hcl:6,16-17: Inconsistent key quoting",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("wrong diagnostics\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestLintNonNative(t *testing.T) {
+	file, diags := json.Parse([]byte(`{"a": 1}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	diags = Lint(file, nil)
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success")
+	}
+	if got, want := diags[0].Summary, "Unsupported body"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
+
+func TestRuleName(t *testing.T) {
+	if got := RuleName(&hcl.Diagnostic{Summary: "other"}); got != "" {
+		t.Errorf("wrong rule name %q for other diagnostic", got)
+	}
+	for _, rule := range Rules() {
+		if rule.Name == "" || rule.Description == "" {
+			t.Errorf("incomplete rule %#v", rule)
+		}
+	}
+}
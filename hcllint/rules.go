@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcllint
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Rule describes one of the checks that Lint can make.
+type Rule struct {
+	// Name is the name used to refer to the rule in a Config and in
+	// comment directives.
+	Name string
+
+	// Description is a short sentence describing the problem that the rule
+	// detects, for display to users.
+	Description string
+
+	check func(l *linter, body *hclsyntax.Body)
+}
+
+// Rules returns all of the rules that Lint can check, in the order that
+// they are checked.
+func Rules() []Rule {
+	ret := make([]Rule, len(rules))
+	copy(ret, rules)
+	return ret
+}
+
+var rules = []Rule{
+	{
+		Name:        "duplicate-key",
+		Description: "An object constructor defines the same key more than once.",
+		check:       checkDuplicateKeys,
+	},
+	{
+		Name:        "empty-block",
+		Description: "A block has no arguments or nested blocks.",
+		check:       checkEmptyBlocks,
+	},
+	{
+		Name:        "deprecated-attribute",
+		Description: "An argument has a name that the application has deprecated.",
+		check:       checkDeprecatedAttributes,
+	},
+	{
+		Name:        "inconsistent-quoting",
+		Description: "Object keys or block labels are quoted in some places but not others.",
+		check:       checkInconsistentQuoting,
+	},
+	{
+		Name:        "trailing-comma",
+		Description: "A tuple or object constructor has or lacks a trailing comma, contrary to the configured policy.",
+		check:       checkTrailingCommas,
+	},
+}
+
+func checkDuplicateKeys(l *linter, body *hclsyntax.Body) {
+	l.visitExprs(body, func(expr hclsyntax.Expression) {
+		obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return
+		}
+		seen := map[string]hcl.Range{}
+		for _, item := range obj.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || key.Type() != cty.String || !key.IsKnown() || key.IsNull() {
+				continue
+			}
+			name := key.AsString()
+			rng := item.KeyExpr.Range()
+			if prev, exists := seen[name]; exists {
+				l.report("duplicate-key", "Duplicate object key",
+					fmt.Sprintf("The key %q was already defined at %s. Only the last definition takes effect.", name, prev),
+					rng)
+				continue
+			}
+			seen[name] = rng
+		}
+	})
+}
+
+func checkEmptyBlocks(l *linter, body *hclsyntax.Body) {
+	l.visitBlocks(body, func(block *hclsyntax.Block) {
+		if len(block.Body.Attributes) > 0 || len(block.Body.Blocks) > 0 {
+			return
+		}
+		l.report("empty-block", "Empty block",
+			fmt.Sprintf("This %q block has no arguments or nested blocks. Remove it if it isn't needed.", block.Type),
+			block.DefRange())
+	})
+}
+
+func checkDeprecatedAttributes(l *linter, body *hclsyntax.Body) {
+	if len(l.config.Deprecated) == 0 {
+		return
+	}
+	l.visitAttributes(body, func(attr *hclsyntax.Attribute) {
+		replacement, deprecated := l.config.Deprecated[attr.Name]
+		if !deprecated {
+			return
+		}
+		detail := fmt.Sprintf("The argument %q is deprecated.", attr.Name)
+		if replacement != "" {
+			detail += fmt.Sprintf(" Use %q instead.", replacement)
+		}
+		l.report("deprecated-attribute", "Deprecated argument", detail, attr.NameRange)
+	})
+}
+
+func checkInconsistentQuoting(l *linter, body *hclsyntax.Body) {
+	l.visitExprs(body, func(expr hclsyntax.Expression) {
+		obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+		if !ok || len(obj.Items) == 0 {
+			return
+		}
+		quoted := isQuotedKey(obj.Items[0].KeyExpr)
+		for _, item := range obj.Items[1:] {
+			if isQuotedKey(item.KeyExpr) != quoted {
+				l.report("inconsistent-quoting", "Inconsistent key quoting",
+					fmt.Sprintf("Keys in this object should all be %s, like the first key.", quotingDesc(quoted)),
+					item.KeyExpr.Range())
+			}
+		}
+	})
+
+	// Block labels are compared with the first label in the whole file,
+	// since a file usually declares several blocks of similar types.
+	var first *bool
+	l.visitBlocks(body, func(block *hclsyntax.Block) {
+		for _, rng := range block.LabelRanges {
+			quoted := l.isQuotedLabel(rng)
+			if first == nil {
+				first = &quoted
+				continue
+			}
+			if quoted != *first {
+				l.report("inconsistent-quoting", "Inconsistent label quoting",
+					fmt.Sprintf("Block labels in this file should all be %s, like the first label.", quotingDesc(*first)),
+					rng)
+			}
+		}
+	})
+}
+
+// isQuotedKey returns true if the given object key is written as a quoted
+// string rather than as a bare identifier or other expression.
+func isQuotedKey(expr hclsyntax.Expression) bool {
+	if key, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		expr = key.Wrapped
+	}
+	_, ok := expr.(*hclsyntax.TemplateExpr)
+	return ok
+}
+
+func (l *linter) isQuotedLabel(rng hcl.Range) bool {
+	ofs := rng.Start.Byte - l.srcRange.Start.Byte
+	return ofs >= 0 && ofs < len(l.src) && l.src[ofs] == '"'
+}
+
+func quotingDesc(quoted bool) string {
+	if quoted {
+		return "quoted"
+	}
+	return "unquoted"
+}
+
+func checkTrailingCommas(l *linter, body *hclsyntax.Body) {
+	policy := l.config.TrailingCommas
+	if policy == hclwrite.TrailingCommasPreserve {
+		return
+	}
+	l.visitExprs(body, func(expr hclsyntax.Expression) {
+		tc, ok := hclsyntaxutil.FindTrailingComma(l.lexedTokens(), expr)
+		if !ok {
+			return
+		}
+		switch {
+		case policy == hclwrite.TrailingCommasStrip && tc.Comma != nil:
+			l.report("trailing-comma", "Unnecessary trailing comma",
+				"Remove the comma after the last element.",
+				tc.Comma.Range)
+		case policy == hclwrite.TrailingCommasAdd && tc.Comma == nil && tc.Separated && tc.OwnLine:
+			l.report("trailing-comma", "Missing trailing comma",
+				"Add a comma after the last element, since the closing bracket is on a line of its own.",
+				tc.Last)
+		}
+	})
+}
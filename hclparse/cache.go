@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+// Cache remembers the results of parsing files so that a long-running
+// program, such as a language server or a program that watches for changes
+// to its configuration, can cheaply re-read the same files many times.
+//
+// Each result is kept until the content of its file changes. To avoid
+// reading files unnecessarily, a file whose modification time and size are
+// unchanged since it was last parsed is assumed to be unchanged. Otherwise
+// the file is read and a hash of its content is compared with the hash of
+// the content that was last parsed, and the file is parsed again only if
+// the hashes differ.
+//
+// Unlike Parser, a Cache returns the diagnostics for a file each time the
+// file is requested, because callers of a cache typically process the
+// result of each request separately.
+//
+// A Cache is safe for concurrent use by multiple goroutines.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	hash  [sha256.Size]byte
+	file  *hcl.File
+	diags hcl.Diagnostics
+
+	// modTime and size are the file's attributes when it was last read, or
+	// zero if the entry was created from a buffer given by the caller.
+	modTime time.Time
+	size    int64
+}
+
+// NewCache creates a new, empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// ParseFile returns the result of parsing the given file, parsing it only if
+// it has changed since the cache last parsed it. Files whose names end in
+// ".json" are parsed as JSON and all others are parsed as native syntax, as
+// with ParseFilesParallel.
+//
+// An error diagnostic is returned if the file cannot be read, in which case
+// the cache also forgets any earlier result for it.
+func (c *Cache) ParseFile(filename string) (*hcl.File, hcl.Diagnostics) {
+	info, err := os.Stat(filename)
+	if err == nil {
+		c.mu.Lock()
+		entry := c.entries[filename]
+		unchanged := entry != nil && !entry.modTime.IsZero() && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size()
+		c.mu.Unlock()
+		if unchanged {
+			return entry.file, entry.diags
+		}
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		c.Forget(filename)
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			},
+		}
+	}
+
+	entry := c.parse(src, filename)
+	if info != nil {
+		c.mu.Lock()
+		entry.modTime = info.ModTime()
+		entry.size = info.Size()
+		c.mu.Unlock()
+	}
+	return entry.file, entry.diags
+}
+
+// Parse returns the result of parsing the given source code, which is
+// assumed to have been loaded from the given filename, parsing it only if
+// it differs from the source code last given for the same filename. This
+// is useful for sources that aren't saved to disk, such as the buffers of
+// an editor. The syntax is chosen from the filename as for ParseFile.
+func (c *Cache) Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	entry := c.parse(src, filename)
+	return entry.file, entry.diags
+}
+
+// Forget removes any result for the given filename from the cache.
+func (c *Cache) Forget(filename string) {
+	c.mu.Lock()
+	delete(c.entries, filename)
+	c.mu.Unlock()
+}
+
+// Files returns a map from filenames to the File objects currently in the
+// cache, which can be used to print diagnostics with contextual
+// information.
+//
+// The returned map belongs to the caller, but the objects it refers to
+// must not be modified.
+func (c *Cache) Files() map[string]*hcl.File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := make(map[string]*hcl.File, len(c.entries))
+	for filename, entry := range c.entries {
+		if entry.file != nil {
+			ret[filename] = entry.file
+		}
+	}
+	return ret
+}
+
+// parse returns the entry for the given source code, parsing it and
+// replacing the existing entry for the filename if the source code has
+// changed. The file is parsed without holding the lock, so that different
+// files can be parsed concurrently.
+func (c *Cache) parse(src []byte, filename string) *cacheEntry {
+	hash := sha256.Sum256(src)
+
+	c.mu.Lock()
+	entry := c.entries[filename]
+	c.mu.Unlock()
+	if entry != nil && entry.hash == hash {
+		return entry
+	}
+
+	entry = &cacheEntry{hash: hash}
+	if strings.HasSuffix(filename, ".json") {
+		entry.file, entry.diags = json.Parse(src, filename)
+	} else {
+		entry.file, entry.diags = hclsyntax.ParseConfig(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	}
+
+	c.mu.Lock()
+	c.entries[filename] = entry
+	c.mu.Unlock()
+	return entry
+}
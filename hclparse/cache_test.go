@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.hcl")
+	write := func(src string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+
+	c := NewCache()
+	write("a = 1\n", start)
+	first, diags := c.ParseFile(filename)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	// Unchanged file
+	if got, _ := c.ParseFile(filename); got != first {
+		t.Errorf("unchanged file was parsed again")
+	}
+
+	// Touched, but with the same content
+	write("a = 1\n", start.Add(time.Minute))
+	if got, _ := c.ParseFile(filename); got != first {
+		t.Errorf("file with unchanged content was parsed again")
+	}
+
+	// Changed content
+	write("a = 2\n", start.Add(2*time.Minute))
+	second, _ := c.ParseFile(filename)
+	if second == first {
+		t.Fatalf("changed file was not parsed again")
+	}
+	if got := string(second.Bytes); got != "a = 2\n" {
+		t.Errorf("wrong content %q", got)
+	}
+
+	// Diagnostics are returned every time.
+	write("a = \n", start.Add(3*time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, diags := c.ParseFile(filename); !diags.HasErrors() {
+			t.Errorf("no errors for invalid file on request %d", i)
+		}
+	}
+
+	// Buffers are cached by content.
+	buf, _ := c.Parse([]byte(`{"a": 1}`), "b.json")
+	if got, _ := c.Parse([]byte(`{"a": 1}`), "b.json"); got != buf {
+		t.Errorf("unchanged buffer was parsed again")
+	}
+	if got := len(c.Files()); got != 2 {
+		t.Errorf("cache has %d files; want 2", got)
+	}
+
+	os.Remove(filename)
+	if _, diags := c.ParseFile(filename); !diags.HasErrors() {
+		t.Errorf("no errors for missing file")
+	}
+	if _, ok := c.Files()[filename]; ok {
+		t.Errorf("missing file is still in the cache")
+	}
+}
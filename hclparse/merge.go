@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Source is one of several buffers given to ParseMerged.
+type Source struct {
+	Filename string
+	Bytes    []byte
+}
+
+// ParseMerged parses each of the given sources and merges the resulting
+// bodies, as with hcl.MergeFiles, to treat them as a single logical
+// document. This suits configuration that is split across several files,
+// such as all of the files in a "config.d" directory.
+//
+// Sources whose filenames end in ".json" are parsed as JSON and all others
+// are parsed as native syntax. Each file is recorded in the parser's
+// registry, and so the source ranges in the merged body and in any
+// diagnostics still refer to the file each item came from.
+//
+// Duplicate top-level arguments across the files are reported when the
+// content of the merged body is retrieved. Because whether a block may
+// appear more than once depends on the application, duplicate blocks are
+// reported by ParseMerged only for the given unique block types: it is an
+// error for two top-level blocks of one of these types in native syntax
+// files to have the same labels.
+func (p *Parser) ParseMerged(sources []Source, uniqueBlockTypes ...string) (hcl.Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	files := make([]*hcl.File, 0, len(sources))
+	for _, src := range sources {
+		var file *hcl.File
+		var moreDiags hcl.Diagnostics
+		if strings.HasSuffix(src.Filename, ".json") {
+			file, moreDiags = p.ParseJSON(src.Bytes, src.Filename)
+		} else {
+			file, moreDiags = p.ParseHCL(src.Bytes, src.Filename)
+		}
+		diags = append(diags, moreDiags...)
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+
+	diags = append(diags, duplicateBlocks(files, uniqueBlockTypes)...)
+	return hcl.MergeFiles(files), diags
+}
+
+// ParseMergedFiles is like ParseMerged, but reads the sources from the
+// files with the given names. An error diagnostic is returned for each file
+// that cannot be read, and the body merges the remaining files.
+func (p *Parser) ParseMergedFiles(filenames []string, uniqueBlockTypes ...string) (hcl.Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	files := make([]*hcl.File, 0, len(filenames))
+	for _, filename := range filenames {
+		var file *hcl.File
+		var moreDiags hcl.Diagnostics
+		if strings.HasSuffix(filename, ".json") {
+			file, moreDiags = p.ParseJSONFile(filename)
+		} else {
+			file, moreDiags = p.ParseHCLFile(filename)
+		}
+		diags = append(diags, moreDiags...)
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+
+	diags = append(diags, duplicateBlocks(files, uniqueBlockTypes)...)
+	return hcl.MergeFiles(files), diags
+}
+
+// duplicateBlocks returns an error diagnostic for each top-level block of
+// one of the given types whose labels are the same as those of an earlier
+// block of the same type.
+func duplicateBlocks(files []*hcl.File, types []string) hcl.Diagnostics {
+	if len(types) == 0 {
+		return nil
+	}
+	unique := make(map[string]bool, len(types))
+	for _, typeName := range types {
+		unique[typeName] = true
+	}
+
+	var diags hcl.Diagnostics
+	seen := make(map[string]*hclsyntax.Block)
+	for _, file := range files {
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if !unique[block.Type] {
+				continue
+			}
+			key := block.Type + " " + quotedLabels(block.Labels)
+			existing, exists := seen[key]
+			if !exists {
+				seen[key] = block
+				continue
+			}
+
+			desc := fmt.Sprintf("%q block", block.Type)
+			if len(block.Labels) != 0 {
+				desc = fmt.Sprintf("%q block labeled %s", block.Type, quotedLabels(block.Labels))
+			}
+			defRange := block.DefRange()
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate %s block", block.Type),
+				Detail: fmt.Sprintf(
+					"A %s was already defined at %s.",
+					desc, existing.DefRange().String(),
+				),
+				Subject: &defRange,
+				Extra:   hcl.NewDuplicateDiagExtra(existing.DefRange()),
+			})
+		}
+	}
+	return diags
+}
+
+func quotedLabels(labels []string) string {
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = fmt.Sprintf("%q", label)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestParseMerged(t *testing.T) {
+	p := NewParser()
+	body, diags := p.ParseMerged([]Source{
+		{Filename: "config.d/a.hcl", Bytes: []byte("name = \"a\"\nservice \"web\" {}\n")},
+		{Filename: "config.d/b.json", Bytes: []byte(`{"port": 80}`)},
+		{Filename: "config.d/c.hcl", Bytes: []byte("\nservice \"db\" {}\nservice \"web\" {}\n")},
+	}, "service")
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	diag := diags[0]
+	if got, want := diag.Summary, "Duplicate service block"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diag.Detail, `A "service" block labeled "web" was already defined at config.d/a.hcl:2,1-14.`; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := diag.Subject.String(), "config.d/c.hcl:3,1-14"; got != want {
+		t.Errorf("wrong subject %s; want %s", got, want)
+	}
+
+	content, diags := body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "name"}, {Name: "port"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: "service", LabelNames: []string{"name"}}},
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if got := len(content.Blocks); got != 3 {
+		t.Errorf("wrong number of blocks %d; want 3", got)
+	}
+	if got, want := content.Attributes["port"].Range.Filename, "config.d/b.json"; got != want {
+		t.Errorf("port is from %s; want %s", got, want)
+	}
+	if got := len(p.Files()); got != 3 {
+		t.Errorf("parser registry has %d files; want 3", got)
+	}
+}
+
+func TestParseMergedDuplicateArgument(t *testing.T) {
+	p := NewParser()
+	body, diags := p.ParseMerged([]Source{
+		{Filename: "a.hcl", Bytes: []byte("name = \"a\"\n")},
+		{Filename: "b.hcl", Bytes: []byte("name = \"b\"\n")},
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	_, diags = body.JustAttributes()
+	if len(diags) != 1 || diags[0].Summary != "Duplicate argument" {
+		t.Fatalf("wrong diagnostics\n%s", diags.Error())
+	}
+	if got, want := diags[0].Subject.Filename, "b.hcl"; got != want {
+		t.Errorf("duplicate reported in %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+// FileResult is the result of parsing one of the files given to
+// ParseFilesParallel.
+type FileResult struct {
+	Filename    string
+	File        *hcl.File
+	Diagnostics hcl.Diagnostics
+}
+
+// ParseFilesParallel reads and parses each of the given files, using up to
+// the given number of goroutines at once. If workers is zero or negative
+// then it defaults to the value of runtime.GOMAXPROCS.
+//
+// Files whose names end in ".json" are parsed as JSON, as with
+// ParseJSONFile, and all others are parsed as native syntax, as with
+// ParseHCLFile. Each of the resulting files is recorded in the parser's
+// registry, and as with the other parse methods a file that was already
+// parsed is returned without parsing it again and without diagnostics.
+//
+// The results are in the same order as the given filenames. The returned
+// diagnostics are all of the diagnostics from the individual results,
+// again in the order of the given filenames.
+//
+// The parser itself is not safe for concurrent use, so the caller must not
+// use it in other goroutines while ParseFilesParallel is running. The work
+// of parsing the files is distributed among the goroutines, and the results
+// are recorded in the registry only after all of them have completed.
+func (p *Parser) ParseFilesParallel(filenames []string, workers int) ([]FileResult, hcl.Diagnostics) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]FileResult, len(filenames))
+	var todo []int
+	first := make(map[string]int, len(filenames))
+	for i, filename := range filenames {
+		results[i].Filename = filename
+		if _, dup := first[filename]; dup || p.files[filename] != nil {
+			continue
+		}
+		first[filename] = i
+		todo = append(todo, i)
+	}
+
+	if workers > len(todo) {
+		workers = len(todo)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].File, results[i].Diagnostics = parseFileByName(results[i].Filename)
+			}
+		}()
+	}
+	for _, i := range todo {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var diags hcl.Diagnostics
+	for i := range results {
+		filename := results[i].Filename
+		if first[filename] == i && results[i].File != nil {
+			p.files[filename] = results[i].File
+		}
+		if results[i].File == nil {
+			// Already in the registry, or a repeat of an earlier filename.
+			results[i].File = p.files[filename]
+		}
+		diags = append(diags, results[i].Diagnostics...)
+	}
+	return results, diags
+}
+
+// parseFileByName reads and parses the given file without consulting or
+// updating any parser's registry, choosing the syntax from the filename.
+func parseFileByName(filename string) (*hcl.File, hcl.Diagnostics) {
+	if strings.HasSuffix(filename, ".json") {
+		return json.ParseFile(filename)
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			},
+		}
+	}
+	return hclsyntax.ParseConfig(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
+}
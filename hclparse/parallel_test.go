@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestParseFilesParallel(t *testing.T) {
+	dir := t.TempDir()
+	var filenames []string
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("f%02d.hcl", i))
+		src := fmt.Sprintf("a = %d\n", i)
+		if i%2 == 1 {
+			name = filepath.Join(dir, fmt.Sprintf("f%02d.json", i))
+			src = fmt.Sprintf(`{"a": %d}`, i)
+		}
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, name)
+	}
+	missing := filepath.Join(dir, "missing.hcl")
+	broken := filepath.Join(dir, "broken.hcl")
+	if err := os.WriteFile(broken, []byte("a = \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filenames = append(filenames, missing, broken, filenames[0])
+
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			p := NewParser()
+			results, diags := p.ParseFilesParallel(filenames, workers)
+
+			if got, want := len(results), len(filenames); got != want {
+				t.Fatalf("wrong number of results %d; want %d", got, want)
+			}
+			for i, result := range results {
+				if result.Filename != filenames[i] {
+					t.Errorf("result %d is for %q; want %q", i, result.Filename, filenames[i])
+				}
+			}
+			for i := 0; i < 20; i++ {
+				if results[i].File == nil || len(results[i].Diagnostics) != 0 {
+					t.Errorf("unexpected result for %s: %#v", filenames[i], results[i])
+					continue
+				}
+				attrs, _ := results[i].File.Body.JustAttributes()
+				v, _ := attrs["a"].Expr.Value(nil)
+				if bf := v.AsBigFloat(); !bf.IsInt() {
+					t.Errorf("wrong value for %s: %#v", filenames[i], v)
+				} else if got, _ := bf.Int64(); got != int64(i) {
+					t.Errorf("wrong value for %s: %d; want %d", filenames[i], got, i)
+				}
+			}
+			if results[20].File != nil || !results[20].Diagnostics.HasErrors() {
+				t.Errorf("missing file should fail to read: %#v", results[20])
+			}
+			if !results[21].Diagnostics.HasErrors() {
+				t.Errorf("broken file should produce errors")
+			}
+			if results[22].File != results[0].File || len(results[22].Diagnostics) != 0 {
+				t.Errorf("repeated filename should reuse the first result")
+			}
+
+			want := len(results[20].Diagnostics) + len(results[21].Diagnostics)
+			if len(diags) != want {
+				t.Errorf("wrong number of aggregated diagnostics %d; want %d", len(diags), want)
+			}
+
+			files := p.Files()
+			if _, ok := files[missing]; ok {
+				t.Errorf("unreadable file should not be registered")
+			}
+			if len(files) != 21 {
+				t.Errorf("wrong number of registered files %d; want 21", len(files))
+			}
+
+			// Parsing again must reuse the registered files.
+			again, diags := p.ParseFilesParallel(filenames[:1], workers)
+			if again[0].File != results[0].File || len(diags) != 0 {
+				t.Errorf("second parse did not reuse the registered file")
+			}
+		})
+	}
+}
+
+func TestSeparateParsersConcurrently(t *testing.T) {
+	const goroutines = 8
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			p := NewParser()
+			for i := 0; i < 50; i++ {
+				filename := fmt.Sprintf("f%d.hcl", i)
+				if _, diags := p.ParseHCL([]byte(fmt.Sprintf("a = %d\n", i)), filename); diags.HasErrors() {
+					errs <- fmt.Errorf("goroutine %d: %s", g, diags.Error())
+					return
+				}
+				if _, diags := p.ParseJSON([]byte(fmt.Sprintf(`{"a": %d}`, i)), filename+".json"); diags.HasErrors() {
+					errs <- fmt.Errorf("goroutine %d: %s", g, diags.Error())
+					return
+				}
+			}
+			if got := len(p.Files()); got != 100 {
+				errs <- fmt.Errorf("goroutine %d: parser has %d files; want 100", g, got)
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -16,6 +16,7 @@
 package hclparse
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 
@@ -38,6 +39,10 @@ import (
 // call to parse that file. Callers are expected to collect up diagnostics
 // and present them together, so returning diagnostics for the same file
 // multiple times would create a confusing result.
+//
+// A Parser is not safe for concurrent use, because of its registry, but
+// separate parsers may be used concurrently from different goroutines. To
+// parse many files concurrently into one registry, use ParseFilesParallel.
 type Parser struct {
 	files map[string]*hcl.File
 }
@@ -62,6 +67,23 @@ func (p *Parser) ParseHCL(src []byte, filename string) (*hcl.File, hcl.Diagnosti
 	return file, diags
 }
 
+// ParseHCLWithContext is like ParseHCL, but stops parsing if the given
+// context is canceled or passes its deadline before parsing is complete, as
+// described for hclsyntax.ParseConfigWithContext. A file whose parsing was
+// stopped in this way is not recorded in the parser's registry, so a later
+// call can parse it again.
+func (p *Parser) ParseHCLWithContext(ctx context.Context, src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.files[filename]; existing != nil {
+		return existing, nil
+	}
+
+	file, diags := hclsyntax.ParseConfigWithContext(ctx, src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	if ctx.Err() == nil {
+		p.files[filename] = file
+	}
+	return file, diags
+}
+
 // ParseHCLFile reads the given filename and parses it as a native-syntax HCL
 // configuration file. An error diagnostic is returned if the given file
 // cannot be read.
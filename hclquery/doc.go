@@ -0,0 +1,30 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclquery implements a small path expression language for finding
+// blocks, attributes and nested expressions in native syntax configuration,
+// or nested values within a decoded cty.Value.
+//
+// A query is a sequence of steps separated by periods, such as
+// resource.aws_instance.*.ami. When searching a body, each step matches
+// either an attribute name or a block type, and a block's labels are then
+// matched by the steps that follow it, in order. Once the labels are
+// exhausted, the remaining steps match the contents of the block's body.
+// Steps after an attribute name match the keys of object constructor
+// expressions and the indices of tuple constructor expressions.
+//
+// A step is one of the following:
+//
+//   - An identifier, which matches a name exactly.
+//   - A quoted string, which matches its content exactly and so can match
+//     names and labels that are not valid identifiers.
+//   - A decimal integer, which matches a tuple or list index as well as
+//     a name consisting of the same digits.
+//   - A single asterisk, which matches any one name, label or index.
+//   - Two asterisks, which match any number of nested blocks or values,
+//     including none at all.
+//
+// A query that ends before all of a block's labels are consumed matches the
+// block itself, so resource.aws_instance matches every block of type
+// "resource" whose first label is "aws_instance".
+package hclquery
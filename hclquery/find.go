@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclquery
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Match is a block, attribute or nested expression that matched a query.
+type Match struct {
+	// Path is the sequence of concrete names, labels and indices that led to
+	// the match, with one element for each block type, label, attribute
+	// name, object key or tuple index that was traversed.
+	Path []string
+
+	// Block is the matched block, or nil if the match is an attribute or
+	// expression.
+	Block *hclsyntax.Block
+
+	// Attribute is the matched attribute, or nil if the match is a block or
+	// an expression nested inside an attribute's expression.
+	Attribute *hclsyntax.Attribute
+
+	// Expr is the matched expression, which for a matched attribute is its
+	// whole expression. Expr is nil if the match is a block.
+	Expr hclsyntax.Expression
+
+	// Range is the source range of the matched block, attribute or
+	// expression.
+	Range hcl.Range
+}
+
+// Find returns the blocks, attributes and nested expressions in the given
+// body that match the query, in source order.
+//
+// Only native syntax bodies can be searched, because the structure of a JSON
+// body can't be determined without a schema. If the given body is of any
+// other type then Find returns error diagnostics.
+func (q *Query) Find(body hcl.Body) ([]Match, hcl.Diagnostics) {
+	synBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported body",
+				Detail:   "Queries can only be run against bodies in the native syntax.",
+				Subject:  body.MissingItemRange().Ptr(),
+			},
+		}
+	}
+
+	f := &finder{seen: map[hcl.Range]bool{}}
+	f.body(synBody, q.steps, nil)
+
+	// A query containing "**" may visit nested blocks before the items
+	// that follow them in their parent bodies.
+	sort.SliceStable(f.matches, func(i, j int) bool {
+		return f.matches[i].Range.Start.Byte < f.matches[j].Range.Start.Byte
+	})
+	return f.matches, nil
+}
+
+// FindFile is a convenience wrapper around Find that searches the body of
+// the given file.
+func (q *Query) FindFile(file *hcl.File) ([]Match, hcl.Diagnostics) {
+	return q.Find(file.Body)
+}
+
+type finder struct {
+	matches []Match
+
+	// seen tracks the ranges already matched, since a query containing "**"
+	// can reach the same node by more than one route.
+	seen map[hcl.Range]bool
+}
+
+func (f *finder) add(m Match) {
+	if f.seen[m.Range] {
+		return
+	}
+	f.seen[m.Range] = true
+	m.Path = append([]string(nil), m.Path...)
+	f.matches = append(f.matches, m)
+}
+
+// body matches the given steps against the attributes and blocks of the
+// given body, visiting them in source order.
+func (f *finder) body(body *hclsyntax.Body, steps []step, path []string) {
+	if len(steps) == 0 {
+		return
+	}
+	s := steps[0]
+	if s.kind == stepRecursive {
+		// "**" matches no blocks at all, or any nested block and then
+		// the same steps again inside it. It can also reach into the
+		// expressions of attributes.
+		f.body(body, steps[1:], path)
+		for _, attr := range body.Attributes {
+			f.expr(attr.Expr, steps, append(path, attr.Name))
+		}
+		for _, block := range body.Blocks {
+			blockPath := append(path, block.Type)
+			blockPath = append(blockPath, block.Labels...)
+			f.body(block.Body, steps, blockPath)
+		}
+		return
+	}
+
+	for _, item := range bodyItems(body) {
+		switch item := item.(type) {
+		case *hclsyntax.Attribute:
+			if !s.matches(item.Name) {
+				continue
+			}
+			attrPath := append(path, item.Name)
+			if len(steps) == 1 {
+				f.add(Match{
+					Path:      attrPath,
+					Attribute: item,
+					Expr:      item.Expr,
+					Range:     item.SrcRange,
+				})
+				continue
+			}
+			f.expr(item.Expr, steps[1:], attrPath)
+		case *hclsyntax.Block:
+			if !s.matches(item.Type) {
+				continue
+			}
+			f.block(item, steps[1:], append(path, item.Type), 0)
+		}
+	}
+}
+
+// block matches the given steps against the labels of the given block,
+// starting at the given label index, and then against its body.
+func (f *finder) block(block *hclsyntax.Block, steps []step, path []string, label int) {
+	if len(steps) == 0 {
+		f.add(Match{
+			Path:  append(path, block.Labels[label:]...),
+			Block: block,
+			Range: block.Range(),
+		})
+		return
+	}
+	if label == len(block.Labels) {
+		f.body(block.Body, steps, path)
+		return
+	}
+
+	s := steps[0]
+	if s.kind == stepRecursive {
+		// Here "**" can consume any number of the remaining labels before
+		// continuing into the body.
+		for i := label; i <= len(block.Labels); i++ {
+			f.block(block, steps[1:], append(path, block.Labels[label:i]...), i)
+		}
+		f.body(block.Body, steps, append(path, block.Labels[label:]...))
+		return
+	}
+	if s.matches(block.Labels[label]) {
+		f.block(block, steps[1:], append(path, block.Labels[label]), label+1)
+	}
+}
+
+// expr matches the given steps against the items of the given expression,
+// which must be an object or tuple constructor for anything to match.
+func (f *finder) expr(expr hclsyntax.Expression, steps []step, path []string) {
+	if len(steps) == 0 {
+		f.add(Match{
+			Path:  path,
+			Expr:  expr,
+			Range: expr.Range(),
+		})
+		return
+	}
+
+	s := steps[0]
+	if s.kind == stepRecursive {
+		f.expr(expr, steps[1:], path)
+	}
+
+	switch expr := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range expr.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				continue
+			}
+			if s.kind == stepRecursive {
+				f.expr(item.ValueExpr, steps, append(path, key))
+			} else if s.matches(key) {
+				f.expr(item.ValueExpr, steps[1:], append(path, key))
+			}
+		}
+	case *hclsyntax.TupleConsExpr:
+		for i, elem := range expr.Exprs {
+			if s.kind == stepRecursive {
+				f.expr(elem, steps, append(path, strconv.Itoa(i)))
+			} else if s.matchesIndex(i) {
+				f.expr(elem, steps[1:], append(path, strconv.Itoa(i)))
+			}
+		}
+	case *hclsyntax.ParenthesesExpr:
+		f.expr(expr.Expression, steps, path)
+	}
+}
+
+// objectKey returns the static key of an object constructor item, or false
+// if the key can't be determined without evaluation.
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	if keyExpr, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if !keyExpr.ForceNonLiteral {
+			if name := hcl.ExprAsKeyword(keyExpr.Wrapped); name != "" {
+				return name, true
+			}
+		}
+		expr = keyExpr.Wrapped
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return "", false
+	}
+	val, err := convert.Convert(val, cty.String)
+	if err != nil {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// bodyItems returns the attributes and blocks of the given body together in
+// source order.
+func bodyItems(body *hclsyntax.Body) []hclsyntax.Node {
+	items := make([]hclsyntax.Node, 0, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range body.Attributes {
+		items = append(items, attr)
+	}
+	for _, block := range body.Blocks {
+		items = append(items, block)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Range().Start.Byte < items[j].Range().Start.Byte
+	})
+	return items
+}
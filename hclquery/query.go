@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Query is a parsed path expression, ready to be matched against a body or a
+// value.
+type Query struct {
+	steps []step
+	src   string
+}
+
+type stepKind int
+
+const (
+	stepName stepKind = iota
+	stepAny
+	stepRecursive
+)
+
+type step struct {
+	kind stepKind
+	name string
+
+	// index is the decimal integer value of name, or -1 if name is not
+	// a non-negative decimal integer.
+	index int
+}
+
+// matches returns true if the step matches the given name, label or
+// object key.
+func (s step) matches(name string) bool {
+	return s.kind == stepAny || (s.kind == stepName && s.name == name)
+}
+
+// matchesIndex returns true if the step matches the given tuple or list
+// index.
+func (s step) matchesIndex(idx int) bool {
+	return s.kind == stepAny || (s.kind == stepName && s.index == idx)
+}
+
+// Parse parses the given path expression. The returned diagnostics describe
+// any syntax errors, with source ranges given relative to the start of the
+// string.
+func Parse(src string) (*Query, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	q := &Query{src: src}
+
+	pos := 0
+	rangeAt := func(start, end int) *hcl.Range {
+		return &hcl.Range{
+			Filename: "<query>",
+			Start:    hcl.Pos{Line: 1, Column: utf8.RuneCountInString(src[:start]) + 1, Byte: start},
+			End:      hcl.Pos{Line: 1, Column: utf8.RuneCountInString(src[:end]) + 1, Byte: end},
+		}
+	}
+
+	if strings.TrimSpace(src) == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Empty query",
+			Detail:   "A query must have at least one step.",
+			Subject:  rangeAt(0, len(src)),
+		})
+		return nil, diags
+	}
+
+	for {
+		start := pos
+		switch {
+		case pos < len(src) && src[pos] == '"':
+			end := quotedEnd(src, pos)
+			if end < 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unterminated string",
+					Detail:   "This quoted step has no closing quote.",
+					Subject:  rangeAt(pos, len(src)),
+				})
+				return nil, diags
+			}
+			name, err := strconv.Unquote(src[pos:end])
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid string",
+					Detail:   fmt.Sprintf("This quoted step is not valid: %s.", err),
+					Subject:  rangeAt(pos, end),
+				})
+				return nil, diags
+			}
+			q.steps = append(q.steps, step{kind: stepName, name: name, index: -1})
+			pos = end
+
+		case strings.HasPrefix(src[pos:], "**"):
+			q.steps = append(q.steps, step{kind: stepRecursive})
+			pos += 2
+
+		case strings.HasPrefix(src[pos:], "*"):
+			q.steps = append(q.steps, step{kind: stepAny})
+			pos++
+
+		default:
+			end := pos
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if !isNameRune(r) {
+					break
+				}
+				end += size
+			}
+			if end == pos {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid query step",
+					Detail:   "Each step must be a name, a quoted string, a number, \"*\" or \"**\".",
+					Subject:  rangeAt(pos, stepEnd(src, pos)),
+				})
+				return nil, diags
+			}
+			name := src[pos:end]
+			index := -1
+			if n, err := strconv.Atoi(name); err == nil && n >= 0 && name[0] != '+' {
+				index = n
+			}
+			q.steps = append(q.steps, step{kind: stepName, name: name, index: index})
+			pos = end
+		}
+
+		if pos == len(src) {
+			break
+		}
+		if src[pos] != '.' {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid query step",
+				Detail:   "Steps must be separated by periods.",
+				Subject:  rangeAt(start, stepEnd(src, pos)),
+			})
+			return nil, diags
+		}
+		pos++
+		if pos == len(src) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid query step",
+				Detail:   "A query must not end with a period.",
+				Subject:  rangeAt(pos-1, pos),
+			})
+			return nil, diags
+		}
+	}
+
+	return q, diags
+}
+
+// MustParse is like Parse but panics if the query is invalid. It is intended
+// for queries that are constant in the calling program.
+func MustParse(src string) *Query {
+	q, diags := Parse(src)
+	if diags.HasErrors() {
+		panic(diags.Error())
+	}
+	return q
+}
+
+// String returns the source of the query, as given to Parse.
+func (q *Query) String() string {
+	return q.src
+}
+
+// quotedEnd returns the offset just after the closing quote of the quoted
+// string starting at the given offset, or -1 if it is unterminated.
+func quotedEnd(src string, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// stepEnd returns the offset of the next period at or after the given
+// offset, or the end of the string, for use in diagnostic ranges.
+func stepEnd(src string, from int) int {
+	if i := strings.IndexByte(src[from:], '.'); i > 0 {
+		return from + i
+	}
+	return len(src)
+}
+
+// isNameRune returns true if the given rune may appear in an unquoted step,
+// which permits the same characters as an HCL identifier.
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,259 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclquery
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const testConfig = `
+region = "us-east-1"
+
+resource "aws_instance" "web" {
+  ami  = "ami-123"
+  tags = {
+    Name  = "web"
+    "env" = "prod"
+  }
+  ports = [80, 443]
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+
+  ebs "root" {
+    size = 10
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+
+func TestFind(t *testing.T) {
+	f, diags := hclsyntax.ParseConfig([]byte(testConfig), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{
+			"region",
+			[]string{"region @ 2"},
+		},
+		{
+			"resource.aws_instance.*.ami",
+			[]string{
+				"resource.aws_instance.web.ami @ 5",
+				"resource.aws_instance.db.ami @ 14",
+			},
+		},
+		{
+			"resource.aws_instance",
+			[]string{
+				"resource.aws_instance.web @ 4",
+				"resource.aws_instance.db @ 13",
+			},
+		},
+		{
+			"resource.*.logs",
+			[]string{"resource.aws_s3_bucket.logs @ 21"},
+		},
+		{
+			`resource.aws_instance.web.tags."env"`,
+			[]string{"resource.aws_instance.web.tags.env @ 8"},
+		},
+		{
+			"resource.aws_instance.web.tags.*",
+			[]string{
+				"resource.aws_instance.web.tags.Name @ 7",
+				"resource.aws_instance.web.tags.env @ 8",
+			},
+		},
+		{
+			"resource.aws_instance.web.ports.1",
+			[]string{"resource.aws_instance.web.ports.1 @ 10"},
+		},
+		{
+			"**.size",
+			[]string{"resource.aws_instance.db.ebs.root.size @ 17"},
+		},
+		{
+			"**.Name",
+			[]string{"resource.aws_instance.web.tags.Name @ 7"},
+		},
+		{
+			"resource.**.ebs",
+			[]string{"resource.aws_instance.db.ebs.root @ 16"},
+		},
+		{
+			"nonexist",
+			nil,
+		},
+		{
+			"region.foo",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			q, diags := Parse(test.query)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			matches, diags := q.FindFile(f)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			var got []string
+			for _, m := range matches {
+				got = append(got, fmt.Sprintf("%s @ %d", strings.Join(m.Path, "."), m.Range.Start.Line))
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong matches\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindMatchFields(t *testing.T) {
+	f, _ := hclsyntax.ParseConfig([]byte(testConfig), "test.hcl", hcl.InitialPos)
+	body := f.Body.(*hclsyntax.Body)
+
+	matches, _ := MustParse("resource.aws_s3_bucket.logs").Find(body)
+	if len(matches) != 1 || matches[0].Block != body.Blocks[2] || matches[0].Expr != nil {
+		t.Errorf("wrong block match: %#v", matches)
+	}
+
+	matches, _ = MustParse("region").Find(body)
+	attr := body.Attributes["region"]
+	if len(matches) != 1 || matches[0].Attribute != attr || matches[0].Expr != attr.Expr {
+		t.Errorf("wrong attribute match: %#v", matches)
+	}
+	val, _ := matches[0].Expr.Value(nil)
+	if !val.RawEquals(cty.StringVal("us-east-1")) {
+		t.Errorf("wrong value %#v", val)
+	}
+}
+
+func TestFindJSON(t *testing.T) {
+	f, _ := json.Parse([]byte(`{"region": "us-east-1"}`), "test.json")
+	_, diags := MustParse("region").FindFile(f)
+	if !diags.HasErrors() {
+		t.Fatalf("succeeded; want error")
+	}
+}
+
+func TestFindValue(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"resource": cty.ObjectVal(map[string]cty.Value{
+			"aws_instance": cty.MapVal(map[string]cty.Value{
+				"web": cty.ObjectVal(map[string]cty.Value{
+					"ami":   cty.StringVal("ami-123"),
+					"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+				}),
+				"db": cty.ObjectVal(map[string]cty.Value{
+					"ami":   cty.StringVal("ami-456"),
+					"ports": cty.ListValEmpty(cty.Number),
+				}),
+			}),
+		}),
+		"tags": cty.SetVal([]cty.Value{cty.StringVal("a")}),
+	})
+
+	tests := []struct {
+		query string
+		want  []cty.Value
+	}{
+		{
+			"resource.aws_instance.*.ami",
+			[]cty.Value{cty.StringVal("ami-456"), cty.StringVal("ami-123")},
+		},
+		{
+			"resource.aws_instance.web.ports.1",
+			[]cty.Value{cty.NumberIntVal(443)},
+		},
+		{
+			"**.ports.0",
+			[]cty.Value{cty.NumberIntVal(80)},
+		},
+		{
+			"tags.0",
+			nil,
+		},
+		{
+			"tags.*",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			matches := MustParse(test.query).FindValue(val)
+			var got []cty.Value
+			for _, m := range matches {
+				applied, err := m.Path.Apply(val)
+				if err != nil {
+					t.Fatalf("path does not apply: %s", err)
+				}
+				if !applied.RawEquals(m.Value) {
+					t.Errorf("path %#v gives %#v, but match value is %#v", m.Path, applied, m.Value)
+				}
+				got = append(got, m.Value)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("wrong number of matches %d; want %d", len(got), len(test.want))
+			}
+			for i := range got {
+				if !got[i].RawEquals(test.want[i]) {
+					t.Errorf("wrong match %d: %#v; want %#v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		summary string
+		column  int
+	}{
+		{"", "Empty query", 1},
+		{"a..b", "Invalid query step", 3},
+		{"a.", "Invalid query step", 2},
+		{`a."b`, "Unterminated string", 3},
+		{"a[0]", "Invalid query step", 1},
+		{"a.b c", "Invalid query step", 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, diags := Parse(test.query)
+			if len(diags) != 1 {
+				t.Fatalf("got %d diagnostics; want 1", len(diags))
+			}
+			if got := diags[0].Summary; got != test.summary {
+				t.Errorf("wrong summary %q; want %q", got, test.summary)
+			}
+			if got := diags[0].Subject.Start.Column; got != test.column {
+				t.Errorf("wrong column %d; want %d", got, test.column)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclquery
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueMatch is a value nested within a cty.Value that matched a query.
+type ValueMatch struct {
+	// Path is the path from the value given to FindValue to the matched
+	// value, which can be used with cty.Path.Apply.
+	Path  cty.Path
+	Value cty.Value
+}
+
+// FindValue returns the values nested within the given value that match the
+// query, such as a value produced by decoding a body with a schema.
+//
+// Each step matches an attribute of an object, a key of a map or an index of
+// a list or tuple. Sets have no way to address their elements, and null and
+// unknown values have no nested values, so nothing inside any of these can
+// match.
+//
+// Object attributes and map elements are visited in lexical order of their
+// names, and sequence elements in order of their indices.
+func (q *Query) FindValue(val cty.Value) []ValueMatch {
+	var ret []ValueMatch
+	seen := map[string]bool{}
+	var find func(val cty.Value, steps []step, path cty.Path)
+	find = func(val cty.Value, steps []step, path cty.Path) {
+		if len(steps) == 0 {
+			key := pathKey(path)
+			if !seen[key] {
+				seen[key] = true
+				ret = append(ret, ValueMatch{
+					Path:  path.Copy(),
+					Value: val,
+				})
+			}
+			return
+		}
+		s := steps[0]
+		if s.kind == stepRecursive {
+			find(val, steps[1:], path)
+		}
+		if val.IsNull() || !val.IsKnown() {
+			return
+		}
+
+		ty := val.Type()
+		switch {
+		case ty.IsObjectType() || ty.IsMapType():
+			for it := val.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				name := k.AsString()
+				var elemPath cty.Path
+				if ty.IsObjectType() {
+					elemPath = path.GetAttr(name)
+				} else {
+					elemPath = path.Index(k)
+				}
+				if s.kind == stepRecursive {
+					find(v, steps, elemPath)
+				} else if s.matches(name) {
+					find(v, steps[1:], elemPath)
+				}
+			}
+		case ty.IsListType() || ty.IsTupleType():
+			i := 0
+			for it := val.ElementIterator(); it.Next(); i++ {
+				k, v := it.Element()
+				elemPath := path.Index(k)
+				if s.kind == stepRecursive {
+					find(v, steps, elemPath)
+				} else if s.matchesIndex(i) {
+					find(v, steps[1:], elemPath)
+				}
+			}
+		}
+	}
+	find(val, q.steps, nil)
+	return ret
+}
+
+// pathKey returns a string that uniquely identifies the given path, for
+// detecting values that were reached more than once.
+func pathKey(path cty.Path) string {
+	var buf []byte
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			buf = append(buf, '.')
+			buf = append(buf, step.Name...)
+		case cty.IndexStep:
+			buf = append(buf, '[')
+			buf = append(buf, step.Key.GoString()...)
+			buf = append(buf, ']')
+		}
+	}
+	return string(buf)
+}
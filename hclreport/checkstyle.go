@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclreport
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsarif"
+	"github.com/hashicorp/hcl/v2/internal/diagutil"
+)
+
+// checkstyleVersion is the version of Checkstyle whose output format we
+// produce.
+const checkstyleVersion = "4.3"
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr,omitempty"`
+}
+
+// WriteCheckstyle writes the given diagnostics to the given writer as a
+// Checkstyle report, with an element for each of the given files and for
+// each other file that a diagnostic refers to.
+//
+// The source of each problem is the rule that the diagnostic violates. A
+// diagnostic with no subject is reported at line zero of a file with an
+// empty name.
+func WriteCheckstyle(w io.Writer, filenames []string, diags hcl.Diagnostics) error {
+	report := checkstyleReport{Version: checkstyleVersion}
+	for _, group := range groupByFile(filenames, diags) {
+		file := checkstyleFile{Name: group.filename}
+		for _, diag := range group.diags {
+			cerr := checkstyleError{
+				Severity: severityString(diag),
+				Message:  diagutil.Message(diag),
+				Source:   hclsarif.RuleID(diag),
+			}
+			if diag.Subject != nil {
+				cerr.Line = diag.Subject.Start.Line
+				cerr.Column = diag.Subject.Start.Column
+			}
+			file.Errors = append(file.Errors, cerr)
+		}
+		report.Files = append(report.Files, file)
+	}
+	return writeXML(w, report)
+}
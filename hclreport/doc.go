@@ -0,0 +1,12 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclreport writes diagnostics as XML reports in the formats that
+// continuous integration systems commonly ingest: the JUnit test report
+// format and the Checkstyle format.
+//
+// Both formats group problems by file, and so the writers are given the
+// names of all of the files that were checked, in order that files with no
+// problems are reported too. Diagnostics are identified using the same rule
+// identifiers as in the SARIF logs written by package "hclsarif".
+package hclreport
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclreport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsarif"
+	"github.com/hashicorp/hcl/v2/internal/diagutil"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the given diagnostics to the given writer as a JUnit
+// test report, naming the collection of test suites after the given tool.
+//
+// Each of the given files becomes a test suite. A file with no diagnostics
+// has a single passing test case, while each diagnostic for a file becomes
+// a failing test case named after the rule that it violates.
+func WriteJUnit(w io.Writer, tool string, filenames []string, diags hcl.Diagnostics) error {
+	report := junitTestSuites{Name: tool}
+	for _, group := range groupByFile(filenames, diags) {
+		suite := junitTestSuite{Name: group.filename}
+		if len(group.diags) == 0 {
+			suite.Cases = []junitTestCase{{Name: group.filename, ClassName: group.filename}}
+		}
+		for _, diag := range group.diags {
+			name := hclsarif.RuleID(diag)
+			if name == "" {
+				name = diag.Summary
+			}
+			text := diag.Detail
+			if loc := diagLocation(diag); loc != "" {
+				text = fmt.Sprintf("%s at %s\n%s", severityString(diag), loc, diag.Detail)
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      name,
+				ClassName: group.filename,
+				Failure: &junitFailure{
+					Message: diagutil.Message(diag),
+					Type:    severityString(diag),
+					Text:    text,
+				},
+			})
+			suite.Failures++
+		}
+		suite.Tests = len(suite.Cases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+	return writeXML(w, report)
+}
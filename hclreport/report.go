@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclreport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// fileDiags is the diagnostics relating to one file.
+type fileDiags struct {
+	filename string
+	diags    hcl.Diagnostics
+}
+
+// groupByFile returns the given diagnostics grouped by the file that their
+// subjects are in. The result includes a group for each of the given
+// filenames, in the given order, followed by a group for each other file
+// that a diagnostic refers to, in order of appearance. Diagnostics with no
+// subject are grouped under an empty filename.
+func groupByFile(filenames []string, diags hcl.Diagnostics) []fileDiags {
+	var ret []fileDiags
+	index := make(map[string]int)
+	add := func(filename string) int {
+		i, ok := index[filename]
+		if !ok {
+			i = len(ret)
+			index[filename] = i
+			ret = append(ret, fileDiags{filename: filename})
+		}
+		return i
+	}
+
+	for _, filename := range filenames {
+		add(filename)
+	}
+	for _, diag := range diags {
+		var filename string
+		if diag.Subject != nil {
+			filename = diag.Subject.Filename
+		}
+		i := add(filename)
+		ret[i].diags = append(ret[i].diags, diag)
+	}
+	return ret
+}
+
+// severityString returns the name of the severity of the given diagnostic,
+// as used in both formats.
+func severityString(diag *hcl.Diagnostic) string {
+	if diag.Severity == hcl.DiagWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// diagLocation returns a description of the location of the given
+// diagnostic, or an empty string if it has no subject.
+func diagLocation(diag *hcl.Diagnostic) string {
+	if diag.Subject == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", diag.Subject.Filename, diag.Subject.Start.Line, diag.Subject.Start.Column)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
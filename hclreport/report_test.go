@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclreport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+var testDiags = hcl.Diagnostics{
+	{
+		Severity: hcl.DiagWarning,
+		Summary:  "Empty block",
+		Detail:   "The \"b\" block has no contents.",
+		Subject: &hcl.Range{
+			Filename: "b.hcl",
+			Start:    hcl.Pos{Line: 2, Column: 1, Byte: 6},
+			End:      hcl.Pos{Line: 2, Column: 2, Byte: 7},
+		},
+	},
+	{
+		Severity: hcl.DiagError,
+		Summary:  "Something went <wrong>",
+	},
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "hclvalidate", []string{"a.hcl", "b.hcl"}, testDiags); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="hclvalidate" tests="3" failures="2">
+  <testsuite name="a.hcl" tests="1" failures="0">
+    <testcase name="a.hcl" classname="a.hcl"></testcase>
+  </testsuite>
+  <testsuite name="b.hcl" tests="1" failures="1">
+    <testcase name="Empty block" classname="b.hcl">
+      <failure message="Empty block: The &#34;b&#34; block has no contents." type="warning">warning at b.hcl:2:1&#xA;The &#34;b&#34; block has no contents.</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="" tests="1" failures="1">
+    <testcase name="Something went &lt;wrong&gt;" classname="">
+      <failure message="Something went &lt;wrong&gt;" type="error"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("wrong report\n%s", diff)
+	}
+}
+
+func TestWriteCheckstyle(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCheckstyle(&buf, []string{"a.hcl", "b.hcl"}, testDiags); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a.hcl"></file>
+  <file name="b.hcl">
+    <error line="2" column="1" severity="warning" message="Empty block: The &#34;b&#34; block has no contents."></error>
+  </file>
+  <file name="">
+    <error line="0" severity="error" message="Something went &lt;wrong&gt;"></error>
+  </file>
+</checkstyle>
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("wrong report\n%s", diff)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclsarif converts diagnostics into the Static Analysis Results
+// Interchange Format (SARIF) version 2.1.0, so that problems found in
+// configuration files can be uploaded to code scanning services such as
+// GitHub code scanning.
+//
+// Each diagnostic becomes a result in a single run. Results are grouped into
+// rules using an identifier derived from the diagnostic: the rule name for
+// diagnostics from the "hcllint" package, the error code for lexical
+// problems reported by the "hclsyntax" package, or the kind of error for
+// diagnostics carrying one of the error types in the "hcl" package. Use
+// OptRuleID to derive identifiers differently.
+//
+// HCL source positions count columns in grapheme clusters, which SARIF
+// cannot represent, so the log declares that columns are counted in Unicode
+// code points. The two are the same except where characters are combined.
+package hclsarif
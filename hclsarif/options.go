@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsarif
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// LogOption is implemented by values that can customize the behavior of
+// Marshal. Use the functions in this package whose names begin with "Opt" to
+// produce log options.
+type LogOption interface {
+	applyLogOption(*logOpts)
+}
+
+type logOpts struct {
+	toolName    string
+	toolVersion string
+	toolURI     string
+	ruleID      func(*hcl.Diagnostic) string
+}
+
+func newLogOpts(opts []LogOption) *logOpts {
+	ret := &logOpts{
+		toolName: "hcl",
+		ruleID:   RuleID,
+	}
+	for _, opt := range opts {
+		opt.applyLogOption(ret)
+	}
+	return ret
+}
+
+type optTool struct {
+	name, version, uri string
+}
+
+// OptTool sets the name, version, and information URI of the tool that
+// produced the diagnostics, which code scanning services display alongside
+// the results. The version and URI may be empty. Without this option, the
+// tool is named "hcl".
+func OptTool(name, version, uri string) LogOption {
+	return optTool{name, version, uri}
+}
+
+// applyLogOption implements LogOption.
+func (o optTool) applyLogOption(opts *logOpts) {
+	opts.toolName = o.name
+	opts.toolVersion = o.version
+	opts.toolURI = o.uri
+}
+
+type optRuleID struct {
+	f func(*hcl.Diagnostic) string
+}
+
+// OptRuleID sets the function that returns the identifier of the rule that
+// each diagnostic violates, or an empty string if it violates no particular
+// rule. Without this option, RuleID is used.
+func OptRuleID(f func(*hcl.Diagnostic) string) LogOption {
+	return optRuleID{f}
+}
+
+// applyLogOption implements LogOption.
+func (o optRuleID) applyLogOption(opts *logOpts) {
+	opts.ruleID = o.f
+}
@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsarif
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcllint"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/diagutil"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// Marshal returns a SARIF log describing the given diagnostics, encoded as
+// JSON.
+func Marshal(diags hcl.Diagnostics, opts ...LogOption) ([]byte, error) {
+	return json.MarshalIndent(newLog(diags, newLogOpts(opts)), "", "  ")
+}
+
+// RuleID returns the identifier of the rule that the given diagnostic
+// violates, as described in the package documentation, or an empty string
+// if it violates no particular rule.
+func RuleID(diag *hcl.Diagnostic) string {
+	if rule := hcllint.RuleName(diag); rule != "" {
+		return rule
+	}
+	if code := hclsyntax.DiagnosticErrorCode(diag); code != hclsyntax.ErrNone {
+		return code.String()
+	}
+
+	var syntaxErr *hcl.SyntaxError
+	var typeErr *hcl.TypeMismatchError
+	var attrErr *hcl.UnknownAttributeError
+	switch {
+	case errors.As(diag, &syntaxErr):
+		return "syntax-error"
+	case errors.As(diag, &typeErr):
+		return "type-mismatch"
+	case errors.As(diag, &attrErr):
+		return "unknown-attribute"
+	}
+	return ""
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func newLog(diags hcl.Diagnostics, opts *logOpts) *sarifLog {
+	descriptions := map[string]string{
+		"syntax-error":      "The source code cannot be parsed.",
+		"type-mismatch":     "A value does not have the required type.",
+		"unknown-attribute": "An argument or attribute is not expected.",
+	}
+	for _, rule := range hcllint.Rules() {
+		descriptions[rule.Name] = rule.Description
+	}
+
+	driver := sarifDriver{
+		Name:           opts.toolName,
+		Version:        opts.toolVersion,
+		InformationURI: opts.toolURI,
+	}
+	ruleIndex := make(map[string]int)
+	results := make([]sarifResult, 0, len(diags))
+	for _, diag := range diags {
+		result := sarifResult{
+			Level:   "error",
+			Message: sarifMessage{Text: diagutil.Message(diag)},
+		}
+		if diag.Severity == hcl.DiagWarning {
+			result.Level = "warning"
+		}
+
+		if id := opts.ruleID(diag); id != "" {
+			idx, ok := ruleIndex[id]
+			if !ok {
+				// Error codes have no description of their own, so we
+				// describe them using the first diagnostic's summary, which
+				// is generic enough for all diagnostics with the same code.
+				desc, ok := descriptions[id]
+				if !ok {
+					desc = diag.Summary
+				}
+				idx = len(driver.Rules)
+				ruleIndex[id] = idx
+				driver.Rules = append(driver.Rules, sarifRule{
+					ID:               id,
+					ShortDescription: sarifMessage{Text: desc},
+				})
+			}
+			result.RuleID = id
+			result.RuleIndex = &idx
+		}
+
+		if diag.Subject != nil {
+			rng := *diag.Subject
+			result.Locations = []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: fileURI(rng.Filename)},
+						Region: sarifRegion{
+							StartLine:   rng.Start.Line,
+							StartColumn: rng.Start.Column,
+							EndLine:     rng.End.Line,
+							EndColumn:   rng.End.Column,
+						},
+					},
+				},
+			}
+		}
+		results = append(results, result)
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool:       sarifTool{Driver: driver},
+				ColumnKind: "unicodeCodePoints",
+				Results:    results,
+			},
+		},
+	}
+}
+
+// fileURI returns a URI referring to the file with the given name. Relative
+// filenames become relative references, which code scanning services
+// resolve against the root of the repository being scanned.
+func fileURI(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !filepath.IsAbs(filename) {
+		return (&url.URL{Path: path}).String()
+	}
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths like C:/foo need a leading slash in a file URI.
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
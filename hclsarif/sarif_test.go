@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsarif
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcllint"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestMarshal(t *testing.T) {
+	src := []byte("a = 1;\n")
+	_, diags := hclsyntax.ParseConfig(src, "dir/main.hcl", hcl.InitialPos)
+	if len(diags) == 0 {
+		t.Fatalf("no parse diagnostics")
+	}
+	diags = diags[:1] // the parser also reports a follow-on error
+
+	file, _ := hclsyntax.ParseConfig([]byte("b {}\n"), "dir/main.hcl", hcl.InitialPos)
+	diags = append(diags, hcllint.Lint(file, nil)...)
+	diags = append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Something went wrong",
+	})
+
+	buf, err := Marshal(diags, OptTool("hcltest", "1.0.0", "https://example.com/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got interface{}
+	if err := json.Unmarshal(buf, &got); err != nil {
+		t.Fatal(err)
+	}
+	var want interface{}
+	wantJSON := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "hcltest",
+          "version": "1.0.0",
+          "informationUri": "https://example.com/",
+          "rules": [
+            {
+              "id": "ErrIllegalSemicolon",
+              "shortDescription": {"text": "Invalid character"}
+            },
+            {
+              "id": "empty-block",
+              "shortDescription": {"text": "A block has no arguments or nested blocks."}
+            }
+          ]
+        }
+      },
+      "columnKind": "unicodeCodePoints",
+      "results": [
+        {
+          "ruleId": "ErrIllegalSemicolon",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {"text": "Invalid character: The \";\" character is not valid. Use newlines to separate arguments and blocks, and commas to separate items in collection values."},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "dir/main.hcl"},
+                "region": {"startLine": 1, "startColumn": 6, "endLine": 1, "endColumn": 7}
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "empty-block",
+          "ruleIndex": 1,
+          "level": "warning",
+          "message": {"text": ` + mustJSON(t, diags[1].Summary+": "+diags[1].Detail) + `},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "dir/main.hcl"},
+                "region": ` + mustJSON(t, regionOf(diags[1])) + `
+              }
+            }
+          ]
+        },
+        {
+          "level": "error",
+          "message": {"text": "Something went wrong"}
+        }
+      ]
+    }
+  ]
+}`
+	if err := json.Unmarshal([]byte(wantJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong log\n%s", diff)
+	}
+}
+
+func TestRuleID(t *testing.T) {
+	tests := map[string]struct {
+		diag *hcl.Diagnostic
+		want string
+	}{
+		"syntax error": {
+			hcl.MarkSyntaxErrors(hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "Oops"}})[0],
+			"syntax-error",
+		},
+		"type mismatch": {
+			&hcl.Diagnostic{Severity: hcl.DiagError, Extra: &hcl.TypeMismatchError{}},
+			"type-mismatch",
+		},
+		"unknown attribute": {
+			&hcl.Diagnostic{Severity: hcl.DiagError, Extra: &hcl.UnknownAttributeError{}},
+			"unknown-attribute",
+		},
+		"none": {
+			&hcl.Diagnostic{Severity: hcl.DiagError},
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := RuleID(test.diag); got != test.want {
+				t.Errorf("wrong rule %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOptRuleID(t *testing.T) {
+	diags := hcl.Diagnostics{{Severity: hcl.DiagWarning, Summary: "Hmm"}}
+	buf, err := Marshal(diags, OptRuleID(func(*hcl.Diagnostic) string { return "custom" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf, &log); err != nil {
+		t.Fatal(err)
+	}
+	if got := log.Runs[0].Results[0].RuleID; got != "custom" {
+		t.Errorf("wrong rule %q; want %q", got, "custom")
+	}
+	if got := log.Runs[0].Tool.Driver.Name; got != "hcl" {
+		t.Errorf("wrong tool name %q; want %q", got, "hcl")
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	tests := map[string]string{
+		"main.hcl":          "main.hcl",
+		"dir/my config.hcl": "dir/my%20config.hcl",
+		"/etc/app.hcl":      "file:///etc/app.hcl",
+	}
+	for filename, want := range tests {
+		if got := fileURI(filename); got != want {
+			t.Errorf("wrong URI for %q: %q; want %q", filename, got, want)
+		}
+	}
+}
+
+func regionOf(diag *hcl.Diagnostic) sarifRegion {
+	return sarifRegion{
+		StartLine:   diag.Subject.Start.Line,
+		StartColumn: diag.Subject.Start.Column,
+		EndLine:     diag.Subject.End.Line,
+		EndColumn:   diag.Subject.End.Column,
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
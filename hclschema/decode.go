@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// DecodeSchema decodes a schema written in HCL from the given body, allowing
+// applications and tools to load schemas from files.
+//
+// The body may contain "attribute" blocks, each labelled with an attribute
+// name, and "block" blocks, each labelled with a block type name:
+//
+//	attribute "name" {
+//	  type        = string
+//	  required    = true
+//	  validate    = "min=1,max=64"
+//	  description = "The name of the service."
+//	}
+//
+//	block "listener" {
+//	  labels    = ["protocol"]
+//	  min_items = 1
+//	  max_items = 4
+//
+//	  attribute "port" {
+//	    type     = number
+//	    required = true
+//	  }
+//	}
+//
+// The type of an attribute is given as a type constraint expression, as
+// understood by the "ext/typeexpr" package. If the type is omitted, any
+// value is accepted. The optional constraints on an attribute's value are
+// written in the syntax understood by the "ext/constraint" package. Each
+// "block" block may itself contain "attribute" and "block" blocks
+// describing the content of the blocks of that type.
+func DecodeSchema(body hcl.Body) (*Body, hcl.Diagnostics) {
+	var raw rawBody
+	diags := gohcl.DecodeBody(body, nil, &raw)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret, moreDiags := raw.decode()
+	diags = append(diags, moreDiags...)
+	return ret, diags
+}
+
+type rawBody struct {
+	Attributes []*rawAttribute `hcl:"attribute,block"`
+	Blocks     []*rawBlock     `hcl:"block,block"`
+}
+
+type rawAttribute struct {
+	Name        string         `hcl:"name,label"`
+	Type        hcl.Expression `hcl:"type,optional"`
+	Required    bool           `hcl:"required,optional"`
+	Validate    string         `hcl:"validate,optional"`
+	Description string         `hcl:"description,optional"`
+	DefRange    hcl.Range      `hcl:",def_range"`
+
+	ValidateRange *hcl.Range `hcl:"validate,attr_value_range"`
+}
+
+type rawBlock struct {
+	Type        string    `hcl:"type,label"`
+	Labels      []string  `hcl:"labels,optional"`
+	MinItems    int       `hcl:"min_items,optional"`
+	MaxItems    int       `hcl:"max_items,optional"`
+	Description string    `hcl:"description,optional"`
+	Body        rawBody   `hcl:",remain"`
+	DefRange    hcl.Range `hcl:",def_range"`
+}
+
+func (raw *rawBody) decode() (*Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &Body{}
+
+	attrRanges := make(map[string]hcl.Range)
+	for _, rawAttr := range raw.Attributes {
+		if prev, exists := attrRanges[rawAttr.Name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate attribute schema",
+				Detail:   fmt.Sprintf("The attribute %q was already declared at %s.", rawAttr.Name, prev),
+				Subject:  rawAttr.DefRange.Ptr(),
+			})
+			continue
+		}
+		attrRanges[rawAttr.Name] = rawAttr.DefRange
+
+		ty := cty.DynamicPseudoType
+		if val, valDiags := rawAttr.Type.Value(nil); valDiags.HasErrors() || !val.IsNull() {
+			var tyDiags hcl.Diagnostics
+			ty, tyDiags = typeexpr.TypeConstraint(rawAttr.Type)
+			diags = append(diags, tyDiags...)
+		}
+
+		var constraints *constraint.Constraints
+		if rawAttr.Validate != "" {
+			var err error
+			constraints, err = constraint.Parse(rawAttr.Validate)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute constraints",
+					Detail:   fmt.Sprintf("Invalid constraints for attribute %q: %s.", rawAttr.Name, err),
+					Subject:  rawAttr.ValidateRange,
+				})
+			}
+		}
+
+		ret.Attributes = append(ret.Attributes, &Attribute{
+			Name:        rawAttr.Name,
+			Type:        ty,
+			Required:    rawAttr.Required,
+			Constraints: constraints,
+			Description: rawAttr.Description,
+		})
+	}
+
+	blockRanges := make(map[string]hcl.Range)
+	for _, rawBlock := range raw.Blocks {
+		if prev, exists := blockRanges[rawBlock.Type]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate block schema",
+				Detail:   fmt.Sprintf("The block type %q was already declared at %s.", rawBlock.Type, prev),
+				Subject:  rawBlock.DefRange.Ptr(),
+			})
+			continue
+		}
+		blockRanges[rawBlock.Type] = rawBlock.DefRange
+
+		if rawBlock.MaxItems > 0 && rawBlock.MaxItems < rawBlock.MinItems {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid block schema",
+				Detail:   fmt.Sprintf("The max_items for block type %q must not be less than its min_items.", rawBlock.Type),
+				Subject:  rawBlock.DefRange.Ptr(),
+			})
+		}
+
+		body, bodyDiags := rawBlock.Body.decode()
+		diags = append(diags, bodyDiags...)
+
+		ret.Blocks = append(ret.Blocks, &Block{
+			Type:        rawBlock.Type,
+			LabelNames:  rawBlock.Labels,
+			Body:        body,
+			MinItems:    rawBlock.MinItems,
+			MaxItems:    rawBlock.MaxItems,
+			Description: rawBlock.Description,
+		})
+	}
+
+	return ret, diags
+}
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecodeSchema(t *testing.T) {
+	src := `
+attribute "name" {
+  type        = string
+  required    = true
+  description = "The name."
+}
+attribute "anything" {}
+
+block "service" {
+  labels    = ["name"]
+  max_items = 2
+
+  attribute "ports" {
+    type     = list(number)
+    validate = "min=1"
+  }
+  block "check" {}
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "schema.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	got, diags := DecodeSchema(file.Body)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	want := &Body{
+		Attributes: []*Attribute{
+			{Name: "name", Type: cty.String, Required: true, Description: "The name."},
+			{Name: "anything", Type: cty.DynamicPseudoType},
+		},
+		Blocks: []*Block{
+			{
+				Type:       "service",
+				LabelNames: []string{"name"},
+				MaxItems:   2,
+				Body: &Body{
+					Attributes: []*Attribute{
+						{
+							Name:        "ports",
+							Type:        cty.List(cty.Number),
+							Constraints: &constraint.Constraints{Min: big.NewFloat(1)},
+						},
+					},
+					Blocks: []*Block{
+						{Type: "check", Body: &Body{}},
+					},
+				},
+			},
+		},
+	}
+	bigFloatEquals := func(a, b *big.Float) bool {
+		return (a == nil) == (b == nil) && (a == nil || a.Cmp(b) == 0)
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(cty.Type.Equals), cmp.Comparer(bigFloatEquals)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestDecodeSchemaErrors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"invalid type": {
+			`
+attribute "a" {
+  type = strang
+}
+`,
+			`schema.hcl:3,10-16: Invalid type specification; The keyword "strang" is not a valid type specification.`,
+		},
+		"duplicate attribute": {
+			`
+attribute "a" {}
+attribute "a" {}
+`,
+			`schema.hcl:3,1-14: Duplicate attribute schema; The attribute "a" was already declared at schema.hcl:2,1-14.`,
+		},
+		"invalid constraints": {
+			`
+attribute "a" {
+  validate = "min=one"
+}
+`,
+			`schema.hcl:3,14-23: Invalid attribute constraints; Invalid constraints for attribute "a": invalid min constraint "one": must be a number.`,
+		},
+		"invalid block counts": {
+			`
+block "a" {
+  min_items = 2
+  max_items = 1
+}
+`,
+			`schema.hcl:2,1-10: Invalid block schema; The max_items for block type "a" must not be less than its min_items.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "schema.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			_, diags = DecodeSchema(file.Body)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Error(); got != test.want {
+				t.Errorf("wrong diagnostic\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
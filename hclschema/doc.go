@@ -0,0 +1,15 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclschema allows applications to declare the expected structure of
+// an HCL configuration -- which attributes and blocks may appear, the types
+// of attribute values, which items are required, and how blocks nest -- and
+// to validate configuration bodies against that declaration.
+//
+// Validation produces position-annotated diagnostics without decoding the
+// configuration into any particular representation, so it can be used by
+// configuration-driven tools to give early feedback on problems before
+// the application's own decoding logic runs. Applications that need the
+// decoded result as well may prefer the "hcldec" or "gohcl" packages, which
+// perform similar checks as a side-effect of decoding.
+package hclschema
@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// InferSchema returns a best-guess schema for configuration like that in the
+// given example files, which must have been parsed from native syntax.
+//
+// The top-level bodies of the files, and the bodies of all of the blocks of
+// each type within them, are each treated as samples of the same body. An
+// attribute or block type that appears in every sample of a body is
+// required, while one that appears in only some of them is optional. A block
+// type is limited to a single block per body if no sample contains more than
+// one.
+//
+// The type of each attribute is inferred from the values given for it
+// across all of the samples: tuple values are taken to be lists, and object
+// values whose attributes all have the same type are taken to be maps. An
+// attribute whose value can't be determined without an evaluation context,
+// or whose values have no common type, accepts values of any type. Block
+// labels can't be named from examples alone, and so are named "name" for
+// a block type with a single label or "label1", "label2", and so on
+// otherwise.
+//
+// The result is intended as a starting point for a schema that is then
+// refined by hand. Warnings are returned for blocks whose number of labels
+// differs from that of the first block of the same type.
+func InferSchema(files ...*hcl.File) (*Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var bodies []*hclsyntax.Body
+	for _, file := range files {
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported body",
+				Detail:   "A schema can be inferred only from files in the native syntax.",
+				Subject:  file.Body.MissingItemRange().Ptr(),
+			})
+			continue
+		}
+		bodies = append(bodies, body)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret, moreDiags := inferBody(bodies)
+	diags = append(diags, moreDiags...)
+	return ret, diags
+}
+
+// inferBody returns a schema for the given sample bodies.
+func inferBody(samples []*hclsyntax.Body) (*Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &Body{}
+
+	attrIndex := make(map[string]int)
+	var attrExprs [][]hclsyntax.Expression
+	blockIndex := make(map[string]int)
+	var blockSamples [][]*hclsyntax.Body
+	var blockMaxCounts []int
+	var blockPresence []int
+
+	for _, body := range samples {
+		for _, attr := range hclsyntaxutil.SortedAttributes(body) {
+			i, ok := attrIndex[attr.Name]
+			if !ok {
+				i = len(ret.Attributes)
+				attrIndex[attr.Name] = i
+				ret.Attributes = append(ret.Attributes, &Attribute{Name: attr.Name})
+				attrExprs = append(attrExprs, nil)
+			}
+			attrExprs[i] = append(attrExprs[i], attr.Expr)
+		}
+
+		counts := make(map[int]int)
+		for _, block := range body.Blocks {
+			i, ok := blockIndex[block.Type]
+			if !ok {
+				i = len(ret.Blocks)
+				blockIndex[block.Type] = i
+				ret.Blocks = append(ret.Blocks, &Block{
+					Type:       block.Type,
+					LabelNames: inferLabelNames(len(block.Labels)),
+				})
+				blockSamples = append(blockSamples, nil)
+				blockMaxCounts = append(blockMaxCounts, 0)
+				blockPresence = append(blockPresence, 0)
+			}
+			if want := len(ret.Blocks[i].LabelNames); len(block.Labels) != want {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Inconsistent block labels",
+					Detail: fmt.Sprintf(
+						"The first %q block has %d label(s), so this block is not consistent with the inferred schema.",
+						block.Type, want,
+					),
+					Subject: hcl.RangeBetween(block.TypeRange, block.OpenBraceRange).Ptr(),
+				})
+			}
+			blockSamples[i] = append(blockSamples[i], block.Body)
+			counts[i]++
+		}
+		for i, count := range counts {
+			blockPresence[i]++
+			if count > blockMaxCounts[i] {
+				blockMaxCounts[i] = count
+			}
+		}
+	}
+
+	for i, attrS := range ret.Attributes {
+		attrS.Required = len(attrExprs[i]) == len(samples)
+		attrS.Type = inferType(attrExprs[i])
+	}
+	for i, blockS := range ret.Blocks {
+		if blockPresence[i] == len(samples) {
+			blockS.MinItems = 1
+		}
+		if blockMaxCounts[i] == 1 {
+			blockS.MaxItems = 1
+		}
+		body, moreDiags := inferBody(blockSamples[i])
+		diags = append(diags, moreDiags...)
+		blockS.Body = body
+	}
+
+	return ret, diags
+}
+
+// inferLabelNames returns the names used for the given number of labels.
+func inferLabelNames(n int) []string {
+	switch n {
+	case 0:
+		return nil
+	case 1:
+		return []string{"name"}
+	}
+	ret := make([]string, n)
+	for i := range ret {
+		ret[i] = fmt.Sprintf("label%d", i+1)
+	}
+	return ret
+}
+
+// inferType returns the type of attribute that accepts the values of all of
+// the given expressions.
+func inferType(exprs []hclsyntax.Expression) cty.Type {
+	types := make([]cty.Type, 0, len(exprs))
+	for _, expr := range exprs {
+		types = append(types, exprType(expr))
+	}
+	return unifyTypes(types)
+}
+
+// exprType returns the inferred type of the given expression, or
+// cty.DynamicPseudoType if it gives no indication of its type.
+func exprType(expr hclsyntax.Expression) cty.Type {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		// A template that refers to variables or calls functions still
+		// produces a string.
+		if _, ok := expr.(*hclsyntax.TemplateExpr); ok {
+			return cty.String
+		}
+		return cty.DynamicPseudoType
+	}
+	if val.IsNull() {
+		return cty.DynamicPseudoType
+	}
+	return generalizeType(val.Type())
+}
+
+// generalizeType returns the collection type corresponding to the given
+// structural type, where there is one. The types of empty tuples and objects
+// give no indication of the type of collection, and so are generalized to
+// cty.DynamicPseudoType.
+func generalizeType(ty cty.Type) cty.Type {
+	switch {
+	case ty.Equals(cty.EmptyTuple) || ty.Equals(cty.EmptyObject):
+		return cty.DynamicPseudoType
+	case ty.IsTupleType():
+		elemTypes := make([]cty.Type, 0, ty.Length())
+		for _, elemType := range ty.TupleElementTypes() {
+			elemTypes = append(elemTypes, generalizeType(elemType))
+		}
+		return cty.List(unifyTypes(elemTypes))
+	case ty.IsObjectType():
+		attrTypes := make(map[string]cty.Type, len(ty.AttributeTypes()))
+		for name, attrType := range ty.AttributeTypes() {
+			attrTypes[name] = generalizeType(attrType)
+		}
+		if elemType, ok := commonType(attrTypes); ok {
+			return cty.Map(elemType)
+		}
+		return cty.Object(attrTypes)
+	default:
+		return ty
+	}
+}
+
+// unifyTypes returns the type that values of all of the given types convert
+// to, or cty.DynamicPseudoType if there is none. Any cty.DynamicPseudoType
+// among the given types is ignored.
+func unifyTypes(types []cty.Type) cty.Type {
+	var known []cty.Type
+	for _, ty := range types {
+		if ty != cty.DynamicPseudoType {
+			known = append(known, ty)
+		}
+	}
+	types = known
+	if len(types) == 0 {
+		return cty.DynamicPseudoType
+	}
+	ty, _ := convert.Unify(types)
+	if ty == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return ty
+}
+
+// commonType returns the type of all of the given attributes, if they all
+// have exactly the same type.
+func commonType(attrTypes map[string]cty.Type) (cty.Type, bool) {
+	var ret cty.Type
+	for _, ty := range attrTypes {
+		if ret == cty.NilType {
+			ret = ty
+			continue
+		}
+		if !ty.Equals(ret) {
+			return cty.NilType, false
+		}
+	}
+	return ret, true
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+func TestInferSchema(t *testing.T) {
+	srcs := []string{
+		`
+name  = "a"
+count = 1
+tags  = { env = "prod" }
+ports = [80, 443]
+owner = { name = "x", id = 1 }
+extra = null
+
+service "web" {
+  image = "nginx:${var.version}"
+
+  tls {
+    cert = "a.pem"
+  }
+}
+
+service "api" {
+  image = var.image
+}
+`,
+		`
+name  = "b"
+count = "2"
+tags  = {}
+
+service "db" {
+  image    = "postgres"
+  replicas = 2
+}
+
+logging {}
+`,
+	}
+	var files []*hcl.File
+	for _, src := range srcs {
+		file, diags := hclsyntax.ParseConfig([]byte(src), "example.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		files = append(files, file)
+	}
+
+	got, diags := InferSchema(files...)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	want := &Body{
+		Attributes: []*Attribute{
+			{Name: "name", Type: cty.String, Required: true},
+			{Name: "count", Type: cty.String, Required: true},
+			{Name: "tags", Type: cty.Map(cty.String), Required: true},
+			{Name: "ports", Type: cty.List(cty.Number)},
+			{Name: "owner", Type: cty.Object(map[string]cty.Type{"name": cty.String, "id": cty.Number})},
+			{Name: "extra", Type: cty.DynamicPseudoType},
+		},
+		Blocks: []*Block{
+			{
+				Type:       "service",
+				LabelNames: []string{"name"},
+				MinItems:   1,
+				Body: &Body{
+					Attributes: []*Attribute{
+						{Name: "image", Type: cty.String, Required: true},
+						{Name: "replicas", Type: cty.Number},
+					},
+					Blocks: []*Block{
+						{
+							Type:     "tls",
+							MaxItems: 1,
+							Body: &Body{
+								Attributes: []*Attribute{
+									{Name: "cert", Type: cty.String, Required: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:     "logging",
+				MaxItems: 1,
+				Body:     &Body{},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(cty.Type.Equals)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestInferSchemaErrors(t *testing.T) {
+	t.Run("inconsistent labels", func(t *testing.T) {
+		src := `
+service "a" {}
+service "b" "c" {}
+`
+		file, diags := hclsyntax.ParseConfig([]byte(src), "example.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		got, diags := InferSchema(file)
+		if len(diags) != 1 || diags[0].Severity != hcl.DiagWarning || diags[0].Summary != "Inconsistent block labels" {
+			t.Fatalf("wrong diagnostics: %s", diags.Error())
+		}
+		if got := diags[0].Subject.Start.Line; got != 3 {
+			t.Errorf("wrong diagnostic line %d; want 3", got)
+		}
+		if labels := got.Blocks[0].LabelNames; len(labels) != 1 {
+			t.Errorf("wrong label names %q", labels)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		file, diags := json.Parse([]byte(`{"name": "a"}`), "example.json")
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		got, diags := InferSchema(file)
+		if got != nil {
+			t.Errorf("unexpected schema")
+		}
+		if !diags.HasErrors() || diags[0].Summary != "Unsupported body" {
+			t.Errorf("wrong diagnostics: %s", diags.Error())
+		}
+	})
+}
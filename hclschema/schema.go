@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+)
+
+// Body describes the expected content of an HCL body.
+//
+// A nil *Body is equivalent to an empty one, accepting no attributes or
+// blocks at all.
+type Body struct {
+	Attributes []*Attribute
+	Blocks     []*Block
+}
+
+// Attribute describes an attribute that may appear in a body.
+type Attribute struct {
+	Name string
+
+	// Type is the type that the attribute's value must be convertible to.
+	// Use cty.DynamicPseudoType to accept a value of any type.
+	Type cty.Type
+
+	// Required, if set, causes validation to fail if the attribute is absent.
+	Required bool
+
+	// Constraints, if non-nil, places further conditions on the attribute's
+	// value after it is converted to Type.
+	Constraints *constraint.Constraints
+
+	// Description is a human-oriented description of the attribute's
+	// purpose, for use in documentation and tooling. It is not used during
+	// validation.
+	Description string
+}
+
+// Block describes a block type that may appear in a body.
+type Block struct {
+	Type string
+
+	// LabelNames gives a name to each of the labels that a block of this
+	// type must have. The number of labels in a block must exactly match
+	// the number of label names given here.
+	LabelNames []string
+
+	// Body describes the expected content of each block of this type.
+	Body *Body
+
+	// MinItems and MaxItems constrain the number of blocks of this type that
+	// may appear in the containing body. A MaxItems of zero means that there
+	// is no upper limit.
+	MinItems int
+	MaxItems int
+
+	// Description is a human-oriented description of the block type's
+	// purpose, for use in documentation and tooling. It is not used during
+	// validation.
+	Description string
+}
+
+// HCLSchema returns the low-level body schema corresponding to the receiver,
+// suitable for passing to the Content method of hcl.Body.
+//
+// The result describes only the direct content of the body. Nested block
+// bodies and the additional constraints described by this package, such as
+// attribute types and block counts, are not represented.
+func (b *Body) HCLSchema() *hcl.BodySchema {
+	ret := &hcl.BodySchema{}
+	if b == nil {
+		return ret
+	}
+
+	for _, attrS := range b.Attributes {
+		ret.Attributes = append(ret.Attributes, hcl.AttributeSchema{
+			Name:     attrS.Name,
+			Required: attrS.Required,
+		})
+	}
+	for _, blockS := range b.Blocks {
+		ret.Blocks = append(ret.Blocks, hcl.BlockHeaderSchema{
+			Type:       blockS.Type,
+			LabelNames: blockS.LabelNames,
+		})
+	}
+
+	return ret
+}
+
+// Attribute returns the attribute schema with the given name, or nil if
+// there is no such attribute.
+func (b *Body) Attribute(name string) *Attribute {
+	if b == nil {
+		return nil
+	}
+	for _, attrS := range b.Attributes {
+		if attrS.Name == name {
+			return attrS
+		}
+	}
+	return nil
+}
+
+// Block returns the block schema with the given type name, or nil if
+// there is no such block type.
+func (b *Body) Block(typeName string) *Block {
+	if b == nil {
+		return nil
+	}
+	for _, blockS := range b.Blocks {
+		if blockS.Type == typeName {
+			return blockS
+		}
+	}
+	return nil
+}
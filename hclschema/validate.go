@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Validate checks the given body against the given schema, returning
+// diagnostics describing any problems found.
+//
+// Validation checks for unexpected and missing attributes and blocks,
+// the number of labels on each block, the number of blocks of each type,
+// the types of attribute values and any further constraints on them,
+// recursing into the bodies of any nested blocks.
+//
+// Attribute values are evaluated without an evaluation context, and so
+// type and constraint checking is possible only for attributes whose values
+// are constant.
+// Attributes whose expressions refer to variables or call functions are
+// not checked, and do not cause any errors during validation.
+func Validate(body hcl.Body, schema *Body) hcl.Diagnostics {
+	content, diags := body.Content(schema.HCLSchema())
+
+	for _, attrS := range schema.attributes() {
+		attr, exists := content.Attributes[attrS.Name]
+		if !exists {
+			// Missing required attributes are already reported by Content.
+			continue
+		}
+		diags = append(diags, validateAttribute(attr, attrS)...)
+	}
+
+	blocksByType := content.Blocks.ByType()
+	for _, blockS := range schema.blocks() {
+		blocks := blocksByType[blockS.Type]
+
+		if len(blocks) < blockS.MinItems {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Insufficient %s blocks", blockS.Type),
+				Detail:   fmt.Sprintf("At least %d %q blocks are required.", blockS.MinItems, blockS.Type),
+				Subject:  content.MissingItemRange.Ptr(),
+			})
+		} else if blockS.MaxItems > 0 && len(blocks) > blockS.MaxItems {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Too many %s blocks", blockS.Type),
+				Detail:   fmt.Sprintf("No more than %d %q blocks are allowed.", blockS.MaxItems, blockS.Type),
+				Subject:  blocks[blockS.MaxItems].DefRange.Ptr(),
+			})
+		}
+
+		for _, block := range blocks {
+			diags = append(diags, Validate(block.Body, blockS.Body)...)
+		}
+	}
+
+	return diags
+}
+
+func validateAttribute(attr *hcl.Attribute, attrS *Attribute) hcl.Diagnostics {
+	if (attrS.Type == cty.NilType || attrS.Type == cty.DynamicPseudoType) && attrS.Constraints == nil {
+		return nil
+	}
+
+	val, valDiags := attr.Expr.Value(nil)
+	if valDiags.HasErrors() || !val.IsWhollyKnown() {
+		// We can't type-check an expression that needs an evaluation
+		// context, so we'll leave that to the application.
+		return nil
+	}
+
+	if attrS.Type != cty.NilType {
+		convVal, err := convert.Convert(val, attrS.Type)
+		if err != nil {
+			return hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect attribute value type",
+					Detail: fmt.Sprintf(
+						"Inappropriate value for attribute %q: %s.",
+						attrS.Name, err.Error(),
+					),
+					Subject:    attr.Expr.Range().Ptr(),
+					Context:    hcl.RangeBetween(attr.NameRange, attr.Expr.Range()).Ptr(),
+					Expression: attr.Expr,
+					Extra: &hcl.TypeMismatchError{
+						Want:  attrS.Type,
+						Got:   val.Type(),
+						Range: attr.Expr.Range(),
+					},
+				},
+			}
+		}
+		val = convVal
+	}
+
+	return attrS.Constraints.Validate(attrS.Name, val, attr.Expr)
+}
+
+func (b *Body) attributes() []*Attribute {
+	if b == nil {
+		return nil
+	}
+	return b.Attributes
+}
+
+func (b *Body) blocks() []*Block {
+	if b == nil {
+		return nil
+	}
+	return b.Blocks
+}
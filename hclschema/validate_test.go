@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclschema
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/constraint"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestValidate(t *testing.T) {
+	schema := &Body{
+		Attributes: []*Attribute{
+			{Name: "name", Type: cty.String, Required: true},
+			{Name: "count", Type: cty.Number},
+			{Name: "tags", Type: cty.Map(cty.String)},
+			{Name: "anything", Type: cty.DynamicPseudoType},
+		},
+		Blocks: []*Block{
+			{
+				Type:       "service",
+				LabelNames: []string{"name"},
+				Body: &Body{
+					Attributes: []*Attribute{
+						{
+							Name:        "port",
+							Type:        cty.Number,
+							Required:    true,
+							Constraints: &constraint.Constraints{Min: big.NewFloat(1), Max: big.NewFloat(65535)},
+						},
+					},
+				},
+				MaxItems: 2,
+			},
+			{
+				Type:     "logging",
+				MinItems: 1,
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		src       string
+		wantDiags []string
+	}{
+		"valid": {
+			`
+name = "foo"
+count = 2
+tags = { env = "prod" }
+anything = [1, "a"]
+service "a" {
+  port = 80
+}
+logging {}
+`,
+			nil,
+		},
+		"variables are not type-checked": {
+			`
+name = var.name
+count = var.count
+logging {}
+`,
+			nil,
+		},
+		"missing required attribute": {
+			`
+logging {}
+`,
+			[]string{
+				`test.hcl:1,1-1: Missing required argument; The argument "name" is required, but no definition was found.`,
+			},
+		},
+		"unsupported attribute": {
+			`
+name = "foo"
+nmae = "foo"
+logging {}
+`,
+			[]string{
+				`test.hcl:3,1-5: Unsupported argument; An argument named "nmae" is not expected here.`,
+			},
+		},
+		"wrong attribute type": {
+			`
+name = "foo"
+count = "many"
+logging {}
+`,
+			[]string{
+				`test.hcl:3,9-15: Incorrect attribute value type; Inappropriate value for attribute "count": a number is required.`,
+			},
+		},
+		"nested attribute": {
+			`
+name = "foo"
+service "a" {
+  port = "http"
+}
+logging {}
+`,
+			[]string{
+				`test.hcl:4,10-16: Incorrect attribute value type; Inappropriate value for attribute "port": a number is required.`,
+			},
+		},
+		"constraint violation": {
+			`
+name = "foo"
+service "a" {
+  port = 70000
+}
+logging {}
+`,
+			[]string{
+				`test.hcl:4,10-15: Invalid attribute value; The value of "port" must be at most 65535.`,
+			},
+		},
+		"nested missing attribute": {
+			`
+name = "foo"
+service "a" {
+}
+logging {}
+`,
+			[]string{
+				`test.hcl:3,13-13: Missing required argument; The argument "port" is required, but no definition was found.`,
+			},
+		},
+		"wrong number of labels": {
+			`
+name = "foo"
+service {
+  port = 80
+}
+logging {}
+`,
+			[]string{
+				`test.hcl:3,9-10: Missing name for service; All service blocks must have 1 labels (name).`,
+			},
+		},
+		"too few blocks": {
+			`
+name = "foo"
+`,
+			[]string{
+				`test.hcl:1,1-1: Insufficient logging blocks; At least 1 "logging" blocks are required.`,
+			},
+		},
+		"too many blocks": {
+			`
+name = "foo"
+service "a" {
+  port = 1
+}
+service "b" {
+  port = 2
+}
+service "c" {
+  port = 3
+}
+logging {}
+`,
+			[]string{
+				`test.hcl:9,1-12: Too many service blocks; No more than 2 "service" blocks are allowed.`,
+			},
+		},
+		"content in empty block": {
+			`
+name = "foo"
+logging {
+  level = "debug"
+}
+`,
+			[]string{
+				`test.hcl:4,3-8: Unsupported argument; An argument named "level" is not expected here.`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			diags = Validate(file.Body, schema)
+
+			var got []string
+			for _, diag := range diags {
+				got = append(got, diag.Error())
+			}
+			if len(got) != len(test.wantDiags) {
+				t.Fatalf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, test.wantDiags)
+			}
+			for i := range got {
+				if got[i] != test.wantDiags[i] {
+					t.Errorf("wrong diagnostic %d\ngot:  %s\nwant: %s", i, got[i], test.wantDiags[i])
+				}
+			}
+		})
+	}
+}
@@ -6,10 +6,20 @@
 //
 // This package is more opinionated than the rest of the HCL API. See the
 // documentation for function Decode for more information.
+//
+// The package also provides similarly-opinionated wrappers for the other
+// common tasks of parsing, formatting, and validating configuration files,
+// so that applications with simple needs can work with HCL without learning
+// the APIs of the various packages that implement those tasks. In each case
+// the syntax of the input is selected by the filename suffix, as described
+// for Decode, and any non-nil error returned is of type hcl.Diagnostics.
 package hclsimple
 
 import (
+	"bytes"
+	stdjson "encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,7 +27,9 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclschema"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/hcl/v2/json"
 )
 
@@ -57,6 +69,36 @@ import (
 // just wrapping functionality elsewhere, if it doesn't meet your needs then
 // please consider copying it into your program and adapting it as needed.
 func Decode(filename string, src []byte, ctx *hcl.EvalContext, target interface{}) error {
+	file, err := Parse(filename, src)
+	if err != nil {
+		return err
+	}
+
+	diags := gohcl.DecodeBody(file.Body, ctx, target)
+	if diags.HasErrors() {
+		return diags
+	}
+	return nil
+}
+
+// DecodeFile is a wrapper around Decode that first reads the given filename
+// from disk. See the Decode documentation for more information.
+func DecodeFile(filename string, ctx *hcl.EvalContext, target interface{}) error {
+	src, err := readFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return Decode(filename, src, ctx, target)
+}
+
+// Parse parses the given HCL source code, selecting the native syntax or the
+// JSON syntax based on the filename suffix as described for Decode.
+//
+// The result can be decoded using the functions in the sibling packages
+// "gohcl" or "hcldec" for applications that need more control over decoding
+// than Decode provides.
+func Parse(filename string, src []byte) (*hcl.File, error) {
 	var file *hcl.File
 	var diags hcl.Diagnostics
 
@@ -71,26 +113,125 @@ func Decode(filename string, src []byte, ctx *hcl.EvalContext, target interface{
 			Summary:  "Unsupported file format",
 			Detail:   fmt.Sprintf("Cannot read from %s: unrecognized file format suffix %q.", filename, suffix),
 		})
-		return diags
+		return nil, diags
 	}
 	if diags.HasErrors() {
-		return diags
+		return file, diags
+	}
+	return file, nil
+}
+
+// ParseFile is a wrapper around Parse that first reads the given filename
+// from disk.
+func ParseFile(filename string) (*hcl.File, error) {
+	src, err := readFile(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	diags = gohcl.DecodeBody(file.Body, ctx, target)
+	return Parse(filename, src)
+}
+
+// Format returns the given HCL source code rewritten in the canonical
+// layout for its syntax.
+//
+// Native syntax is formatted using the "hclwrite" package, while JSON syntax
+// is re-indented with two spaces per level. Formatting is refused if the
+// source code contains syntax errors, in which case the errors are returned.
+func Format(filename string, src []byte) ([]byte, error) {
+	if _, err := Parse(filename, src); err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		var buf bytes.Buffer
+		if err := stdjson.Indent(&buf, src, "", "  "); err != nil {
+			// Should never happen, since we already parsed it successfully.
+			return nil, hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Failed to format JSON",
+					Detail:   fmt.Sprintf("Can't format %s: %s.", filename, err),
+				},
+			}
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	return hclwrite.Format(src), nil
+}
+
+// Validate parses the given HCL source code and checks that it conforms to
+// the given schema, returning errors describing any problems.
+//
+// A schema can be declared in Go using the types in the sibling package
+// "hclschema", or loaded from a schema file using LoadSchemaFile.
+func Validate(filename string, src []byte, schema *hclschema.Body) error {
+	file, err := Parse(filename, src)
+	if err != nil {
+		return err
+	}
+
+	diags := hclschema.Validate(file.Body, schema)
 	if diags.HasErrors() {
 		return diags
 	}
 	return nil
 }
 
-// DecodeFile is a wrapper around Decode that first reads the given filename
-// from disk. See the Decode documentation for more information.
-func DecodeFile(filename string, ctx *hcl.EvalContext, target interface{}) error {
+// ValidateFile is a wrapper around Validate that first reads the given
+// filename from disk.
+func ValidateFile(filename string, schema *hclschema.Body) error {
+	src, err := readFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return Validate(filename, src, schema)
+}
+
+// LoadSchemaFile reads a schema from the given file, written in the schema
+// language described in the documentation for hclschema.DecodeSchema.
+func LoadSchemaFile(filename string) (*hclschema.Body, error) {
+	file, err := ParseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, diags := hclschema.DecodeSchema(file.Body)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return schema, nil
+}
+
+// WriteError writes a human-readable description of the given error to the
+// given writer, including a snippet of the given source code for each
+// diagnostic if the error is of type hcl.Diagnostics, as returned by the
+// other functions in this package.
+//
+// The filename and src arguments should be the same as were passed to the
+// function that returned the error. Diagnostics referring to other files are
+// written without source snippets.
+func WriteError(w io.Writer, filename string, src []byte, err error) error {
+	diags, ok := err.(hcl.Diagnostics)
+	if !ok {
+		_, err := fmt.Fprintf(w, "Error: %s\n", err)
+		return err
+	}
+
+	files := map[string]*hcl.File{
+		filename: {Bytes: src},
+	}
+	return hcl.NewDiagnosticTextWriter(w, files, 0, false).WriteDiagnostics(diags)
+}
+
+func readFile(filename string) ([]byte, error) {
 	src, err := ioutil.ReadFile(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return hcl.Diagnostics{
+			return nil, hcl.Diagnostics{
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Configuration file not found",
@@ -98,7 +239,7 @@ func DecodeFile(filename string, ctx *hcl.EvalContext, target interface{}) error
 				},
 			}
 		}
-		return hcl.Diagnostics{
+		return nil, hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Failed to read configuration",
@@ -106,6 +247,5 @@ func DecodeFile(filename string, ctx *hcl.EvalContext, target interface{}) error
 			},
 		}
 	}
-
-	return Decode(filename, src, ctx, target)
+	return src, nil
 }
@@ -6,10 +6,13 @@ package hclsimple_test
 import (
 	"fmt"
 	"log"
+	"os"
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2/hclschema"
 	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func Example_nativeSyntax() {
@@ -83,3 +86,114 @@ func TestDecodeFile(t *testing.T) {
 		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
 	}
 }
+
+func Example_format() {
+	src := []byte("foo=\"bar\"\nbaz   = \"boop\"\n")
+
+	result, err := hclsimple.Format("example.hcl", src)
+	if err != nil {
+		log.Fatalf("Failed to format configuration: %s", err)
+	}
+	fmt.Printf("%s", result)
+
+	// Output:
+	// foo = "bar"
+	// baz = "boop"
+}
+
+func Example_validate() {
+	schema := &hclschema.Body{
+		Attributes: []*hclschema.Attribute{
+			{Name: "foo", Type: cty.String, Required: true},
+		},
+	}
+
+	src := []byte("foo = [\"bar\"]\n")
+	err := hclsimple.Validate("example.hcl", src, schema)
+	if err != nil {
+		hclsimple.WriteError(os.Stdout, "example.hcl", src, err)
+	}
+
+	// Output:
+	// Error: Incorrect attribute value type
+	//
+	//   on example.hcl line 1:
+	//    1: foo = ["bar"]
+	//
+	// Inappropriate value for attribute "foo": string required.
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		filename string
+		src      string
+		wantErr  string
+	}{
+		"native": {
+			"test.hcl", "foo = \"bar\"\n", "",
+		},
+		"json": {
+			"test.JSON", `{"foo": "bar"}`, "",
+		},
+		"syntax error": {
+			"test.hcl", "foo = \n", "test.hcl:1,7-2,1: Invalid expression; Expected the start of an expression, but found an invalid expression token.",
+		},
+		"unsupported": {
+			"test.yaml", "foo: bar\n", `<nil>: Unsupported file format; Cannot read from test.yaml: unrecognized file format suffix ".yaml".`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, err := hclsimple.Parse(test.filename, []byte(test.src))
+			if test.wantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success; want error %q", test.wantErr)
+				}
+				if got := err.Error(); got != test.wantErr {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			attrs, diags := file.Body.JustAttributes()
+			if diags.HasErrors() || len(attrs) != 1 || attrs["foo"] == nil {
+				t.Errorf("wrong attributes %#v (%s)", attrs, diags.Error())
+			}
+		})
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	got, err := hclsimple.Format("test.json", []byte(`{"foo":"bar","baz":{"a":1}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "{\n  \"foo\": \"bar\",\n  \"baz\": {\n    \"a\": 1\n  }\n}\n"
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := hclsimple.Format("test.hcl", []byte("foo = {\n")); err == nil {
+		t.Errorf("unexpected success formatting invalid input")
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	schema := &hclschema.Body{
+		Attributes: []*hclschema.Attribute{
+			{Name: "foo", Type: cty.String, Required: true},
+			{Name: "baz", Type: cty.String},
+		},
+	}
+	if err := hclsimple.ValidateFile("testdata/test.hcl", schema); err != nil {
+		t.Errorf("unexpected error(s): %s", err)
+	}
+
+	schema.Attributes[1].Type = cty.Bool
+	if err := hclsimple.ValidateFile("testdata/test.hcl", schema); err == nil {
+		t.Errorf("unexpected success with wrong attribute type")
+	}
+}
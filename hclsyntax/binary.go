@@ -0,0 +1,849 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// binaryMagic begins every buffer produced by MarshalFile. Its final byte is
+// the version of the format, which must change whenever the format does so
+// that cached results from older versions are rejected.
+var binaryMagic = []byte("HCLB\x01")
+
+// Tags identifying each kind of node in the binary format.
+const (
+	binNil byte = iota
+	binParentheses
+	binLiteralValue
+	binScopeTraversal
+	binRelativeTraversal
+	binFunctionCall
+	binConditional
+	binIndex
+	binTupleCons
+	binObjectCons
+	binObjectConsKey
+	binFor
+	binSplat
+	binAnonSymbol
+	binExprSyntaxError
+	binBinaryOp
+	binUnaryOp
+	binTemplate
+	binTemplateJoin
+	binTemplateWrap
+
+	binTraverseRoot
+	binTraverseAttr
+	binTraverseIndex
+	binTraverseSplat
+)
+
+// binaryOperations gives each of the operations that the parser produces a
+// number, so that the binary format can refer to them.
+var binaryOperations = []*Operation{
+	OpLogicalOr,
+	OpLogicalAnd,
+	OpLogicalNot,
+	OpEqual,
+	OpNotEqual,
+	OpGreaterThan,
+	OpGreaterThanOrEqual,
+	OpLessThan,
+	OpLessThanOrEqual,
+	OpAdd,
+	OpSubtract,
+	OpMultiply,
+	OpDivide,
+	OpModulo,
+	OpNegate,
+}
+
+// MarshalFile encodes the given file, which must have been produced by
+// ParseConfig, in a compact binary format that UnmarshalFile can decode
+// much faster than the source code can be parsed. This allows tools that
+// process the same files repeatedly to cache the result of parsing each
+// one, keyed for example by a hash of its source code.
+//
+// The result includes the source code of the file and the complete syntax
+// tree, including all source ranges. It does not include the diagnostics
+// returned when the file was parsed, so callers that cache files with
+// errors must cache the diagnostics separately.
+//
+// The format may change in future versions of this package, in which case
+// UnmarshalFile returns an error for data produced by older versions. The
+// data should therefore be used only as a cache.
+func MarshalFile(file *hcl.File) ([]byte, error) {
+	body, ok := file.Body.(*Body)
+	if !ok {
+		return nil, fmt.Errorf("body has unsupported type %T", file.Body)
+	}
+
+	e := &binaryEncoder{
+		strs:    make(map[string]int),
+		symbols: make(map[*AnonSymbolExpr]int),
+	}
+	e.buf.Write(binaryMagic)
+	e.bytes(file.Bytes)
+	e.body(body)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalFile decodes a file previously encoded by MarshalFile, returning
+// an error if the data is invalid or was produced by an incompatible
+// version of this package.
+func UnmarshalFile(data []byte) (*hcl.File, error) {
+	if !bytes.HasPrefix(data, binaryMagic) {
+		return nil, errors.New("data is not an HCL file in a supported binary format")
+	}
+	d := &binaryDecoder{data: data[len(binaryMagic):]}
+	src := d.bytes()
+	body := d.body()
+	if d.err == nil && len(d.data) != 0 {
+		d.err = errors.New("unexpected data after end of file")
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid binary HCL file: %w", d.err)
+	}
+	return &hcl.File{
+		Body:  body,
+		Bytes: src,
+		Nav: navigation{
+			root: body,
+		},
+	}, nil
+}
+
+type binaryEncoder struct {
+	buf bytes.Buffer
+	err error
+
+	// strs and symbols assign numbers to the strings and anonymous symbols
+	// written so far, so that each is written in full only once.
+	strs    map[string]int
+	symbols map[*AnonSymbolExpr]int
+}
+
+func (e *binaryEncoder) uint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (e *binaryEncoder) int(v int) {
+	e.uint(uint64(v))
+}
+
+func (e *binaryEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *binaryEncoder) bytes(b []byte) {
+	e.int(len(b))
+	e.buf.Write(b)
+}
+
+func (e *binaryEncoder) string(s string) {
+	if idx, ok := e.strs[s]; ok {
+		e.int(idx)
+		return
+	}
+	idx := len(e.strs)
+	e.strs[s] = idx
+	e.int(idx)
+	e.bytes([]byte(s))
+}
+
+func (e *binaryEncoder) strings(ss []string) {
+	e.int(len(ss))
+	for _, s := range ss {
+		e.string(s)
+	}
+}
+
+func (e *binaryEncoder) pos(pos hcl.Pos) {
+	e.int(pos.Line)
+	e.int(pos.Column)
+	e.int(pos.Byte)
+}
+
+func (e *binaryEncoder) rng(rng hcl.Range) {
+	e.string(rng.Filename)
+	e.pos(rng.Start)
+	e.pos(rng.End)
+}
+
+func (e *binaryEncoder) ranges(rngs []hcl.Range) {
+	e.int(len(rngs))
+	for _, rng := range rngs {
+		e.rng(rng)
+	}
+}
+
+// value writes the given value along with its type. The JSON encoding can't
+// represent unknown values, but the parser only produces them as wholly
+// unknown placeholders, so for those we write just the type.
+func (e *binaryEncoder) value(val cty.Value) {
+	known := val.IsKnown()
+	e.bool(known)
+	var b []byte
+	var err error
+	if known {
+		b, err = ctyjson.Marshal(val, cty.DynamicPseudoType)
+	} else {
+		b, err = ctyjson.MarshalType(val.Type())
+	}
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	e.bytes(b)
+}
+
+func (e *binaryEncoder) body(body *Body) {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	e.int(len(names))
+	for _, name := range names {
+		attr := body.Attributes[name]
+		e.string(attr.Name)
+		e.expr(attr.Expr)
+		e.rng(attr.SrcRange)
+		e.rng(attr.NameRange)
+		e.rng(attr.EqualsRange)
+	}
+
+	e.int(len(body.Blocks))
+	for _, block := range body.Blocks {
+		e.string(block.Type)
+		e.strings(block.Labels)
+		e.body(block.Body)
+		e.rng(block.TypeRange)
+		e.ranges(block.LabelRanges)
+		e.rng(block.OpenBraceRange)
+		e.rng(block.CloseBraceRange)
+	}
+
+	e.rng(body.SrcRange)
+	e.rng(body.EndRange)
+}
+
+func (e *binaryEncoder) exprs(exprs []Expression) {
+	e.int(len(exprs))
+	for _, expr := range exprs {
+		e.expr(expr)
+	}
+}
+
+func (e *binaryEncoder) op(op *Operation) {
+	for i, known := range binaryOperations {
+		if op == known {
+			e.int(i)
+			return
+		}
+	}
+	if e.err == nil {
+		e.err = errors.New("expression uses an unsupported operation")
+	}
+	e.int(0)
+}
+
+func (e *binaryEncoder) expr(expr Expression) {
+	switch expr := expr.(type) {
+	case nil:
+		e.buf.WriteByte(binNil)
+	case *ParenthesesExpr:
+		e.buf.WriteByte(binParentheses)
+		e.expr(expr.Expression)
+		e.rng(expr.SrcRange)
+	case *LiteralValueExpr:
+		e.buf.WriteByte(binLiteralValue)
+		e.value(expr.Val)
+		e.rng(expr.SrcRange)
+	case *ScopeTraversalExpr:
+		e.buf.WriteByte(binScopeTraversal)
+		e.traversal(expr.Traversal)
+		e.rng(expr.SrcRange)
+	case *RelativeTraversalExpr:
+		e.buf.WriteByte(binRelativeTraversal)
+		e.expr(expr.Source)
+		e.traversal(expr.Traversal)
+		e.rng(expr.SrcRange)
+	case *FunctionCallExpr:
+		e.buf.WriteByte(binFunctionCall)
+		e.string(expr.Name)
+		e.exprs(expr.Args)
+		e.bool(expr.ExpandFinal)
+		e.rng(expr.NameRange)
+		e.rng(expr.OpenParenRange)
+		e.rng(expr.CloseParenRange)
+	case *ConditionalExpr:
+		e.buf.WriteByte(binConditional)
+		e.expr(expr.Condition)
+		e.expr(expr.TrueResult)
+		e.expr(expr.FalseResult)
+		e.rng(expr.SrcRange)
+	case *IndexExpr:
+		e.buf.WriteByte(binIndex)
+		e.expr(expr.Collection)
+		e.expr(expr.Key)
+		e.rng(expr.SrcRange)
+		e.rng(expr.OpenRange)
+		e.rng(expr.BracketRange)
+	case *TupleConsExpr:
+		e.buf.WriteByte(binTupleCons)
+		e.exprs(expr.Exprs)
+		e.rng(expr.SrcRange)
+		e.rng(expr.OpenRange)
+	case *ObjectConsExpr:
+		e.buf.WriteByte(binObjectCons)
+		e.int(len(expr.Items))
+		for _, item := range expr.Items {
+			e.expr(item.KeyExpr)
+			e.expr(item.ValueExpr)
+		}
+		e.rng(expr.SrcRange)
+		e.rng(expr.OpenRange)
+	case *ObjectConsKeyExpr:
+		e.buf.WriteByte(binObjectConsKey)
+		e.expr(expr.Wrapped)
+		e.bool(expr.ForceNonLiteral)
+	case *ForExpr:
+		e.buf.WriteByte(binFor)
+		e.string(expr.KeyVar)
+		e.string(expr.ValVar)
+		e.expr(expr.CollExpr)
+		e.expr(expr.KeyExpr)
+		e.expr(expr.ValExpr)
+		e.expr(expr.CondExpr)
+		e.bool(expr.Group)
+		e.rng(expr.SrcRange)
+		e.rng(expr.OpenRange)
+		e.rng(expr.CloseRange)
+	case *SplatExpr:
+		e.buf.WriteByte(binSplat)
+		e.expr(expr.Source)
+		e.expr(expr.Each)
+		e.expr(expr.Item)
+		e.rng(expr.SrcRange)
+		e.rng(expr.MarkerRange)
+	case *AnonSymbolExpr:
+		// The item of a splat expression is referred to from within its
+		// Each expression, so we must preserve its identity.
+		e.buf.WriteByte(binAnonSymbol)
+		if idx, ok := e.symbols[expr]; ok {
+			e.int(idx)
+			return
+		}
+		idx := len(e.symbols)
+		e.symbols[expr] = idx
+		e.int(idx)
+		e.rng(expr.SrcRange)
+	case *ExprSyntaxError:
+		e.buf.WriteByte(binExprSyntaxError)
+		e.value(expr.Placeholder)
+		e.int(len(expr.ParseDiags))
+		for _, diag := range expr.ParseDiags {
+			e.int(int(diag.Severity))
+			e.string(diag.Summary)
+			e.string(diag.Detail)
+			e.optRange(diag.Subject)
+			e.optRange(diag.Context)
+		}
+		e.rng(expr.SrcRange)
+	case *BinaryOpExpr:
+		e.buf.WriteByte(binBinaryOp)
+		e.expr(expr.LHS)
+		e.op(expr.Op)
+		e.expr(expr.RHS)
+		e.rng(expr.SrcRange)
+	case *UnaryOpExpr:
+		e.buf.WriteByte(binUnaryOp)
+		e.op(expr.Op)
+		e.expr(expr.Val)
+		e.rng(expr.SrcRange)
+		e.rng(expr.SymbolRange)
+	case *TemplateExpr:
+		e.buf.WriteByte(binTemplate)
+		e.exprs(expr.Parts)
+		e.rng(expr.SrcRange)
+	case *TemplateJoinExpr:
+		e.buf.WriteByte(binTemplateJoin)
+		e.expr(expr.Tuple)
+	case *TemplateWrapExpr:
+		e.buf.WriteByte(binTemplateWrap)
+		e.expr(expr.Wrapped)
+		e.rng(expr.SrcRange)
+	default:
+		if e.err == nil {
+			e.err = fmt.Errorf("unsupported expression type %T", expr)
+		}
+		e.buf.WriteByte(binNil)
+	}
+}
+
+func (e *binaryEncoder) optRange(rng *hcl.Range) {
+	e.bool(rng != nil)
+	if rng != nil {
+		e.rng(*rng)
+	}
+}
+
+func (e *binaryEncoder) traversal(traversal hcl.Traversal) {
+	e.int(len(traversal))
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			e.buf.WriteByte(binTraverseRoot)
+			e.string(step.Name)
+			e.rng(step.SrcRange)
+		case hcl.TraverseAttr:
+			e.buf.WriteByte(binTraverseAttr)
+			e.string(step.Name)
+			e.rng(step.SrcRange)
+		case hcl.TraverseIndex:
+			e.buf.WriteByte(binTraverseIndex)
+			e.value(step.Key)
+			e.rng(step.SrcRange)
+		case hcl.TraverseSplat:
+			e.buf.WriteByte(binTraverseSplat)
+			e.traversal(step.Each)
+			e.rng(step.SrcRange)
+		default:
+			if e.err == nil {
+				e.err = fmt.Errorf("unsupported traversal step type %T", step)
+			}
+			e.buf.WriteByte(binNil)
+		}
+	}
+}
+
+// binaryDecoder reads the format written by binaryEncoder. After the first
+// error, recorded in err, its methods return zero values.
+type binaryDecoder struct {
+	data []byte
+	err  error
+
+	strs    []string
+	symbols []*AnonSymbolExpr
+}
+
+func (d *binaryDecoder) fail(msg string) {
+	if d.err == nil {
+		d.err = errors.New(msg)
+	}
+	d.data = nil
+}
+
+func (d *binaryDecoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail("unexpected end of data")
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *binaryDecoder) uint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail("invalid integer")
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *binaryDecoder) int() int {
+	v := d.uint()
+	if v > uint64(len(d.data))+1<<32 {
+		// No length or position in a valid file can be this large
+		// relative to the data remaining.
+		d.fail("integer out of range")
+		return 0
+	}
+	return int(v)
+}
+
+// count reads a number of items to follow, each of which must occupy at
+// least one byte, so that corrupt data can't cause huge allocations.
+func (d *binaryDecoder) count() int {
+	n := d.int()
+	if n > len(d.data) {
+		d.fail("invalid count")
+		return 0
+	}
+	return n
+}
+
+func (d *binaryDecoder) bool() bool {
+	return d.byte() != 0
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	n := d.int()
+	if n > len(d.data) {
+		d.fail("unexpected end of data")
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *binaryDecoder) string() string {
+	idx := d.int()
+	switch {
+	case idx < len(d.strs):
+		return d.strs[idx]
+	case idx == len(d.strs):
+		s := string(d.bytes())
+		d.strs = append(d.strs, s)
+		return s
+	default:
+		d.fail("invalid string reference")
+		return ""
+	}
+}
+
+func (d *binaryDecoder) strings() []string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ret := make([]string, n)
+	for i := range ret {
+		ret[i] = d.string()
+	}
+	return ret
+}
+
+func (d *binaryDecoder) pos() hcl.Pos {
+	return hcl.Pos{
+		Line:   d.int(),
+		Column: d.int(),
+		Byte:   d.int(),
+	}
+}
+
+func (d *binaryDecoder) rng() hcl.Range {
+	return hcl.Range{
+		Filename: d.string(),
+		Start:    d.pos(),
+		End:      d.pos(),
+	}
+}
+
+func (d *binaryDecoder) ranges() []hcl.Range {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ret := make([]hcl.Range, n)
+	for i := range ret {
+		ret[i] = d.rng()
+	}
+	return ret
+}
+
+func (d *binaryDecoder) value() cty.Value {
+	known := d.bool()
+	b := d.bytes()
+	if d.err != nil {
+		return cty.DynamicVal
+	}
+	if !known {
+		ty, err := ctyjson.UnmarshalType(b)
+		if err != nil {
+			d.fail(fmt.Sprintf("invalid type: %s", err))
+			return cty.DynamicVal
+		}
+		return cty.UnknownVal(ty)
+	}
+	val, err := ctyjson.Unmarshal(b, cty.DynamicPseudoType)
+	if err != nil {
+		d.fail(fmt.Sprintf("invalid value: %s", err))
+		return cty.DynamicVal
+	}
+	return val
+}
+
+func (d *binaryDecoder) body() *Body {
+	body := &Body{}
+
+	n := d.count()
+	body.Attributes = make(Attributes, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		attr := &Attribute{
+			Name:        d.string(),
+			Expr:        d.expr(),
+			SrcRange:    d.rng(),
+			NameRange:   d.rng(),
+			EqualsRange: d.rng(),
+		}
+		body.Attributes[attr.Name] = attr
+	}
+
+	n = d.count()
+	for i := 0; i < n && d.err == nil; i++ {
+		body.Blocks = append(body.Blocks, &Block{
+			Type:            d.string(),
+			Labels:          d.strings(),
+			Body:            d.body(),
+			TypeRange:       d.rng(),
+			LabelRanges:     d.ranges(),
+			OpenBraceRange:  d.rng(),
+			CloseBraceRange: d.rng(),
+		})
+	}
+
+	body.SrcRange = d.rng()
+	body.EndRange = d.rng()
+	return body
+}
+
+func (d *binaryDecoder) exprs() []Expression {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ret := make([]Expression, n)
+	for i := range ret {
+		ret[i] = d.expr()
+	}
+	return ret
+}
+
+func (d *binaryDecoder) op() *Operation {
+	idx := d.int()
+	if idx >= len(binaryOperations) {
+		d.fail("invalid operation")
+		return binaryOperations[0]
+	}
+	return binaryOperations[idx]
+}
+
+func (d *binaryDecoder) expr() Expression {
+	switch tag := d.byte(); tag {
+	case binNil:
+		return nil
+	case binParentheses:
+		return &ParenthesesExpr{
+			Expression: d.expr(),
+			SrcRange:   d.rng(),
+		}
+	case binLiteralValue:
+		return &LiteralValueExpr{
+			Val:      d.value(),
+			SrcRange: d.rng(),
+		}
+	case binScopeTraversal:
+		return &ScopeTraversalExpr{
+			Traversal: d.traversal(),
+			SrcRange:  d.rng(),
+		}
+	case binRelativeTraversal:
+		return &RelativeTraversalExpr{
+			Source:    d.expr(),
+			Traversal: d.traversal(),
+			SrcRange:  d.rng(),
+		}
+	case binFunctionCall:
+		return &FunctionCallExpr{
+			Name:            d.string(),
+			Args:            d.exprs(),
+			ExpandFinal:     d.bool(),
+			NameRange:       d.rng(),
+			OpenParenRange:  d.rng(),
+			CloseParenRange: d.rng(),
+		}
+	case binConditional:
+		return &ConditionalExpr{
+			Condition:   d.expr(),
+			TrueResult:  d.expr(),
+			FalseResult: d.expr(),
+			SrcRange:    d.rng(),
+		}
+	case binIndex:
+		return &IndexExpr{
+			Collection:   d.expr(),
+			Key:          d.expr(),
+			SrcRange:     d.rng(),
+			OpenRange:    d.rng(),
+			BracketRange: d.rng(),
+		}
+	case binTupleCons:
+		return &TupleConsExpr{
+			Exprs:     d.exprs(),
+			SrcRange:  d.rng(),
+			OpenRange: d.rng(),
+		}
+	case binObjectCons:
+		expr := &ObjectConsExpr{}
+		n := d.count()
+		for i := 0; i < n && d.err == nil; i++ {
+			expr.Items = append(expr.Items, ObjectConsItem{
+				KeyExpr:   d.expr(),
+				ValueExpr: d.expr(),
+			})
+		}
+		expr.SrcRange = d.rng()
+		expr.OpenRange = d.rng()
+		return expr
+	case binObjectConsKey:
+		return &ObjectConsKeyExpr{
+			Wrapped:         d.expr(),
+			ForceNonLiteral: d.bool(),
+		}
+	case binFor:
+		return &ForExpr{
+			KeyVar:     d.string(),
+			ValVar:     d.string(),
+			CollExpr:   d.expr(),
+			KeyExpr:    d.expr(),
+			ValExpr:    d.expr(),
+			CondExpr:   d.expr(),
+			Group:      d.bool(),
+			SrcRange:   d.rng(),
+			OpenRange:  d.rng(),
+			CloseRange: d.rng(),
+		}
+	case binSplat:
+		expr := &SplatExpr{
+			Source: d.expr(),
+			Each:   d.expr(),
+		}
+		item, ok := d.expr().(*AnonSymbolExpr)
+		if !ok {
+			d.fail("invalid splat expression")
+			item = &AnonSymbolExpr{}
+		}
+		expr.Item = item
+		expr.SrcRange = d.rng()
+		expr.MarkerRange = d.rng()
+		return expr
+	case binAnonSymbol:
+		idx := d.int()
+		switch {
+		case idx < len(d.symbols):
+			return d.symbols[idx]
+		case idx == len(d.symbols):
+			expr := &AnonSymbolExpr{SrcRange: d.rng()}
+			d.symbols = append(d.symbols, expr)
+			return expr
+		default:
+			d.fail("invalid symbol reference")
+			return &AnonSymbolExpr{}
+		}
+	case binExprSyntaxError:
+		expr := &ExprSyntaxError{
+			Placeholder: d.value(),
+		}
+		n := d.count()
+		for i := 0; i < n && d.err == nil; i++ {
+			expr.ParseDiags = append(expr.ParseDiags, &hcl.Diagnostic{
+				Severity: hcl.DiagnosticSeverity(d.int()),
+				Summary:  d.string(),
+				Detail:   d.string(),
+				Subject:  d.optRange(),
+				Context:  d.optRange(),
+			})
+		}
+		expr.SrcRange = d.rng()
+		return expr
+	case binBinaryOp:
+		return &BinaryOpExpr{
+			LHS:      d.expr(),
+			Op:       d.op(),
+			RHS:      d.expr(),
+			SrcRange: d.rng(),
+		}
+	case binUnaryOp:
+		return &UnaryOpExpr{
+			Op:          d.op(),
+			Val:         d.expr(),
+			SrcRange:    d.rng(),
+			SymbolRange: d.rng(),
+		}
+	case binTemplate:
+		return &TemplateExpr{
+			Parts:    d.exprs(),
+			SrcRange: d.rng(),
+		}
+	case binTemplateJoin:
+		return &TemplateJoinExpr{
+			Tuple: d.expr(),
+		}
+	case binTemplateWrap:
+		return &TemplateWrapExpr{
+			Wrapped:  d.expr(),
+			SrcRange: d.rng(),
+		}
+	default:
+		d.fail(fmt.Sprintf("invalid expression tag %d", tag))
+		return nil
+	}
+}
+
+func (d *binaryDecoder) optRange() *hcl.Range {
+	if !d.bool() {
+		return nil
+	}
+	rng := d.rng()
+	return &rng
+}
+
+func (d *binaryDecoder) traversal() hcl.Traversal {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ret := make(hcl.Traversal, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		switch tag := d.byte(); tag {
+		case binTraverseRoot:
+			ret = append(ret, hcl.TraverseRoot{
+				Name:     d.string(),
+				SrcRange: d.rng(),
+			})
+		case binTraverseAttr:
+			ret = append(ret, hcl.TraverseAttr{
+				Name:     d.string(),
+				SrcRange: d.rng(),
+			})
+		case binTraverseIndex:
+			ret = append(ret, hcl.TraverseIndex{
+				Key:      d.value(),
+				SrcRange: d.rng(),
+			})
+		case binTraverseSplat:
+			ret = append(ret, hcl.TraverseSplat{
+				Each:     d.traversal(),
+				SrcRange: d.rng(),
+			})
+		default:
+			d.fail(fmt.Sprintf("invalid traversal step tag %d", tag))
+		}
+	}
+	return ret
+}
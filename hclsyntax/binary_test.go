@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestMarshalFile(t *testing.T) {
+	src := `a = 1 + 2 * -3
+b = "hello ${name}!"
+c = [for i, v in list : v if i > 0]
+d = {for k, v in map : k => v...}
+e = list[*].id
+f = list.*.id
+g = map["x"].y
+h = upper(name)
+i = cond ? null : !cond
+j = <<EOT
+%{ for v in list }${v}%{ endfor }
+EOT
+k = (1.5)
+
+block "label" {
+  nested {
+    m = { key = true, (name) = "v" }
+  }
+}
+`
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	data, err := MarshalFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got.Bytes, file.Bytes) {
+		t.Errorf("wrong source bytes\ngot:  %s\nwant: %s", got.Bytes, file.Bytes)
+	}
+
+	// Encoding the decoded file must produce the same data, which shows
+	// that nothing was lost in the round trip.
+	again, err := MarshalFile(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("decoded file encodes differently")
+	}
+
+	ranges := func(file *hcl.File) []hcl.Range {
+		var ret []hcl.Range
+		VisitAll(file.Body.(*Body), func(node Node) hcl.Diagnostics {
+			switch node.(type) {
+			case Attributes, Blocks:
+				// The ranges of these collections aren't meaningful.
+			default:
+				ret = append(ret, node.Range())
+			}
+			return nil
+		})
+		// Attributes are visited in no particular order.
+		sort.Slice(ret, func(i, j int) bool {
+			if ret[i].Start.Byte != ret[j].Start.Byte {
+				return ret[i].Start.Byte < ret[j].Start.Byte
+			}
+			return ret[i].End.Byte < ret[j].End.Byte
+		})
+		return ret
+	}
+	gotRanges, wantRanges := ranges(got), ranges(file)
+	if len(gotRanges) != len(wantRanges) {
+		t.Fatalf("wrong number of nodes %d; want %d", len(gotRanges), len(wantRanges))
+	}
+	for i := range gotRanges {
+		if gotRanges[i] != wantRanges[i] {
+			t.Errorf("node %d has range %s; want %s", i, gotRanges[i], wantRanges[i])
+		}
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+			"cond": cty.True,
+			"list": cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("a")}),
+				cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("b")}),
+			}),
+			"map": cty.ObjectVal(map[string]cty.Value{
+				"x": cty.ObjectVal(map[string]cty.Value{"y": cty.NumberIntVal(1)}),
+			}),
+		},
+	}
+	for name, attr := range file.Body.(*Body).Attributes {
+		if name == "h" || name == "j" {
+			// These need functions or string values from the list.
+			continue
+		}
+		want, _ := attr.Expr.Value(ctx)
+		gotVal, _ := got.Body.(*Body).Attributes[name].Expr.Value(ctx)
+		if !gotVal.RawEquals(want) {
+			t.Errorf("attribute %s has value %#v; want %#v", name, gotVal, want)
+		}
+	}
+
+	pos := hcl.Pos{Byte: bytes.Index(got.Bytes, []byte("nested"))}
+	if block := got.OutermostBlockAtPos(pos); block == nil || block.Type != "block" {
+		t.Errorf("decoded file has no navigation")
+	}
+}
+
+func TestUnmarshalFileInvalid(t *testing.T) {
+	file, diags := ParseConfig([]byte("a = b.c[0]\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	data, err := MarshalFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if _, err := UnmarshalFile(data[:i]); err == nil {
+			t.Errorf("no error for data truncated to %d bytes", i)
+		}
+	}
+	if _, err := UnmarshalFile([]byte("not a file")); err == nil {
+		t.Errorf("no error for data without header")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"unicode/utf8"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ColumnUnit is the unit in which the Column field of source positions is
+// measured, selected with OptColumnUnit.
+type ColumnUnit int
+
+const (
+	// ColumnGraphemes counts each grapheme cluster as one column, so that a
+	// character made of several code points, such as an emoji with a skin
+	// tone modifier, counts only once. This is the default.
+	ColumnGraphemes ColumnUnit = iota
+
+	// ColumnRunes counts each Unicode code point as one column.
+	ColumnRunes
+
+	// ColumnUTF16 counts UTF-16 code units, so that code points outside the
+	// Basic Multilingual Plane count as two columns. This is the unit used
+	// by the Language Server Protocol and many editors written in
+	// JavaScript.
+	ColumnUTF16
+
+	// ColumnBytes counts bytes of UTF-8 encoding.
+	ColumnBytes
+)
+
+// recomputeColumns updates the start and end columns of each of the given
+// tokens, which must have been produced from the given source buffer, to
+// measure them in the given unit. If tabWidth is greater than zero then each
+// tab character advances the column to the next multiple of the tab width,
+// as a text editor would display it.
+//
+// The scanner counts grapheme clusters and treats a tab as a single column,
+// like any other character, and that remains the default behavior.
+func recomputeColumns(src []byte, tokens Tokens, start hcl.Pos, tabWidth int, unit ColumnUnit) {
+	// We walk forward through the buffer once, since the tokens are in
+	// source order, tracking the display column at each offset.
+	ofs, col := 0, start.Column
+	advance := func(to int) {
+		for ofs < to && ofs < len(src) {
+			switch src[ofs] {
+			case '\n':
+				col = 1
+				ofs++
+				continue
+			case '\t':
+				if tabWidth > 0 {
+					col = ((col-1)/tabWidth+1)*tabWidth + 1
+					ofs++
+					continue
+				}
+			}
+
+			switch unit {
+			case ColumnRunes, ColumnUTF16:
+				r, size := utf8.DecodeRune(src[ofs:])
+				col++
+				if unit == ColumnUTF16 && r >= 0x10000 {
+					col++
+				}
+				ofs += size
+			case ColumnBytes:
+				col++
+				ofs++
+			default:
+				adv, seq, _ := textseg.ScanGraphemeClusters(src[ofs:], true)
+				if len(seq) > 0 && seq[len(seq)-1] == '\n' {
+					// A CRLF sequence is a single grapheme cluster.
+					col = 1
+				} else {
+					col++
+				}
+				ofs += adv
+			}
+		}
+	}
+
+	for i := range tokens {
+		rng := &tokens[i].Range
+		startOfs, endOfs := rng.Start.Byte-start.Byte, rng.End.Byte-start.Byte
+		if startOfs < ofs {
+			// Should never happen, since tokens don't overlap, but we'll
+			// leave any such token alone rather than walking backwards.
+			continue
+		}
+		advance(startOfs)
+		rng.Start.Column = col
+		advance(endOfs)
+		rng.End.Column = col
+	}
+}
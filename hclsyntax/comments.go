@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// CommentStyle identifies which of the comment syntaxes was used to write a
+// comment token, as returned by Token.CommentStyle.
+type CommentStyle int
+
+const (
+	// NotComment is returned for tokens that are not comments.
+	NotComment CommentStyle = iota
+
+	// CommentHash is a line comment introduced by #.
+	CommentHash
+
+	// CommentSlashes is a line comment introduced by //.
+	CommentSlashes
+
+	// CommentBlock is a comment delimited by /* and */, which may span
+	// several lines.
+	CommentBlock
+)
+
+// CommentStyle returns the syntax used to write the receiving token if it
+// is a TokenComment, or NotComment otherwise.
+func (t Token) CommentStyle() CommentStyle {
+	if t.Type != TokenComment {
+		return NotComment
+	}
+	switch {
+	case bytes.HasPrefix(t.Bytes, []byte("#")):
+		return CommentHash
+	case bytes.HasPrefix(t.Bytes, []byte("//")):
+		return CommentSlashes
+	case bytes.HasPrefix(t.Bytes, []byte("/*")):
+		return CommentBlock
+	default:
+		return NotComment
+	}
+}
+
+// CommentText returns the text of the receiving comment token with its
+// comment markers and terminating newline removed, for use by tools that
+// extract documentation from comments. It returns an empty string if the
+// token is not a comment.
+//
+// The text of a line comment has leading and trailing whitespace removed.
+// For a block comment, blank lines at the start and end are removed, a
+// leading * on every other line is removed as for comments written in the
+// style of this one, and then the indentation that the remaining lines have
+// in common is removed so that any deeper indentation is preserved.
+func (t Token) CommentText() string {
+	switch t.CommentStyle() {
+	case CommentHash:
+		return strings.TrimSpace(string(t.Bytes[1:]))
+	case CommentSlashes:
+		return strings.TrimSpace(string(t.Bytes[2:]))
+	case CommentBlock:
+		text := strings.TrimPrefix(string(t.Bytes), "/*")
+		text = strings.TrimSuffix(text, "*/")
+		return blockCommentText(text)
+	default:
+		return ""
+	}
+}
+
+// blockCommentText implements Token.CommentText for the content of a block
+// comment between its delimiters.
+func blockCommentText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	// The first line begins right after the opening delimiter, so if it
+	// has any text then its indentation is not comparable with that of the
+	// lines that follow. A line of only asterisks, as in the /** that opens
+	// some documentation comments, has no text.
+	lines[0] = strings.TrimLeft(lines[0], " \t")
+	if strings.Trim(lines[0], "*") == "" {
+		lines[0] = ""
+	}
+	first := 1
+	if lines[0] == "" {
+		lines = lines[1:]
+		first = 0
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for first == 0 && len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	starred := len(lines) > first
+	for _, line := range lines[first:] {
+		if !strings.HasPrefix(strings.TrimLeft(line, " \t"), "*") {
+			starred = false
+			break
+		}
+	}
+	if starred {
+		for i := first; i < len(lines); i++ {
+			line := strings.TrimLeft(lines[i], " \t")
+			lines[i] = strings.TrimPrefix(line[1:], " ")
+		}
+		for len(lines) > first && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+	}
+
+	indent := -1
+	for _, line := range lines[first:] {
+		if line == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := first; i < len(lines) && indent > 0; i++ {
+		if lines[i] != "" {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// discardComments returns a copy of the given tokens with any comment tokens
+// removed.
+//
+// A line comment includes the newline that terminates it, which is
+// significant to the parser, so each such comment is replaced by a
+// TokenNewline token covering just its newline sequence.
+func discardComments(tokens Tokens) Tokens {
+	ret := make(Tokens, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Type != TokenComment {
+			ret = append(ret, tok)
+			continue
+		}
+		if !bytes.HasSuffix(tok.Bytes, []byte{'\n'}) {
+			continue
+		}
+		nl := []byte{'\n'}
+		if bytes.HasSuffix(tok.Bytes, []byte{'\r', '\n'}) {
+			nl = []byte{'\r', '\n'}
+		}
+		startByte := tok.Range.End.Byte - len(nl)
+		ret = append(ret, Token{
+			Type:  TokenNewline,
+			Bytes: tok.Bytes[len(tok.Bytes)-len(nl):],
+			Range: hcl.Range{
+				Filename: tok.Range.Filename,
+				Start: hcl.Pos{
+					Byte:   startByte,
+					Line:   tok.Range.End.Line - 1,
+					Column: lineCommentNewlineColumn(tok),
+				},
+				End: tok.Range.End,
+			},
+		})
+	}
+	return ret
+}
+
+// lineCommentNewlineColumn returns the column at which the newline that
+// terminates the given line comment token begins.
+func lineCommentNewlineColumn(tok Token) int {
+	// The scanner never produces a multi-line comment token that ends with
+	// a newline, so the newline is on the comment's starting line.
+	content := bytes.TrimRight(tok.Bytes, "\r\n")
+	return posAfter(tok.Range.Start, content).Column
+}
@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+)
+
+func TestTokenCommentText(t *testing.T) {
+	tests := []struct {
+		Src       string
+		WantStyle CommentStyle
+		Want      string
+	}{
+		{"# hello\n", CommentHash, "hello"},
+		{"//  hello world \r\n", CommentSlashes, "hello world"},
+		{"// no newline", CommentSlashes, "no newline"},
+		{"/* inline */", CommentBlock, "inline"},
+		{"/**/", CommentBlock, ""},
+		{
+			"/* first\n     second\n       indented\n   */",
+			CommentBlock,
+			"first\nsecond\n  indented",
+		},
+		{
+			"/*\n    Summary.\n\n      Detail.\n  */",
+			CommentBlock,
+			"Summary.\n\n  Detail.",
+		},
+		{
+			"/**\n   * Summary.\n   *\n   *   Detail.\n   */",
+			CommentBlock,
+			"Summary.\n\n  Detail.",
+		},
+		{
+			"/* a\r\n * b\r\n */",
+			CommentBlock,
+			"a\nb",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			tok := Token{Type: TokenComment, Bytes: []byte(test.Src)}
+			if got := tok.CommentStyle(); got != test.WantStyle {
+				t.Errorf("wrong style %d; want %d", got, test.WantStyle)
+			}
+			if got := tok.CommentText(); got != test.Want {
+				t.Errorf("wrong text\ngot:  %q\nwant: %q", got, test.Want)
+			}
+		})
+	}
+
+	t.Run("not a comment", func(t *testing.T) {
+		tok := Token{Type: TokenQuotedLit, Bytes: []byte("# hello")}
+		if got := tok.CommentStyle(); got != NotComment {
+			t.Errorf("wrong style %d; want NotComment", got)
+		}
+		if got := tok.CommentText(); got != "" {
+			t.Errorf("wrong text %q; want empty", got)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// TestConcurrentParse parses and evaluates configuration from many
+// goroutines at once. It is most useful when run with the race detector.
+func TestConcurrentParse(t *testing.T) {
+	const goroutines = 16
+	const iterations = 20
+
+	src := []byte(`
+name    = "${prefix}-web"
+count   = 2 * 3 + 1
+ids     = items[*].id
+doubled = [for i in items : i.id if i.id != ""]
+
+block "label" {
+  nested = <<EOT
+  ${prefix}
+  EOT
+}
+`)
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"prefix": cty.StringVal("test"),
+			"items": cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("a")}),
+				cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("b")}),
+			}),
+		},
+	}
+
+	// A syntax tree shared by all of the goroutines, to check that
+	// evaluating it concurrently is safe.
+	shared, diags := ParseConfig(src, "shared.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	var interner Interner
+
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				filename := fmt.Sprintf("file%d.hcl", g)
+				file, diags := ParseConfig(src, filename, hcl.InitialPos, OptInternTokens(&interner))
+				if diags.HasErrors() {
+					errs <- fmt.Errorf("goroutine %d: %s", g, diags.Error())
+					return
+				}
+				if _, diags := LexConfig(src, filename, hcl.InitialPos); diags.HasErrors() {
+					errs <- fmt.Errorf("goroutine %d: %s", g, diags.Error())
+					return
+				}
+
+				for _, body := range []*Body{file.Body.(*Body), shared.Body.(*Body)} {
+					val, diags := body.Attributes["ids"].Expr.Value(ctx)
+					if diags.HasErrors() {
+						errs <- fmt.Errorf("goroutine %d: %s", g, diags.Error())
+						return
+					}
+					want := cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})
+					if !val.RawEquals(want) {
+						errs <- fmt.Errorf("goroutine %d: wrong ids %#v", g, val)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Directive is an instruction to a tool that has been written in a line
+// comment, such as "# lint:disable unused-variable".
+//
+// The parser ignores comments, and so directives have no effect on the
+// meaning of a configuration. They are intended for tools such as linters
+// that need a way for users to annotate a specific attribute or block, for
+// example to suppress a warning.
+type Directive struct {
+	// Name is the first word after the prefix, such as "disable", and Args
+	// are any further words, separated by whitespace.
+	Name string
+	Args []string
+
+	// Range is the range of the comment containing the directive, excluding
+	// its terminating newline.
+	Range hcl.Range
+
+	// Node is the attribute or block that the directive applies to, or nil
+	// if the directive is not attached to any item.
+	//
+	// A directive at the end of a line applies to the innermost attribute
+	// or block that includes that line. A directive on a line of its own
+	// applies to the item that begins immediately after it, ignoring any
+	// other comments but not blank lines. A directive that is separated
+	// from the next item by a blank line, or that is not followed by an
+	// item at all, applies to the file as a whole.
+	Node Node
+}
+
+// Directives returns the directives in the line comments of the given file,
+// which must have been parsed from native syntax, in the order that they
+// appear.
+//
+// A directive is a line comment whose text, after the comment marker and any
+// leading whitespace, begins with the given prefix. For example, with the
+// prefix "lint:" the comment "# lint:disable foo" is a directive with the
+// name "disable" and the argument "foo". A comment that consists only of the
+// prefix is a directive with an empty name.
+//
+// Directives returns nil if the file body is not a native syntax body.
+func Directives(file *hcl.File, prefix string) []Directive {
+	body, ok := file.Body.(*Body)
+	if !ok {
+		return nil
+	}
+	// The body's range starts at its first item rather than at the start of
+	// the file, so we must lex from the start of the file ourselves.
+	tokens, _ := LexConfig(file.Bytes, body.SrcRange.Filename, hcl.InitialPos)
+
+	var items []Node
+	collectDirectiveItems(body, &items)
+
+	var ret []Directive
+	for i, tok := range tokens {
+		if tok.Type != TokenComment {
+			continue
+		}
+		text, ok := lineCommentText(tok)
+		if !ok {
+			continue
+		}
+		text = strings.TrimLeft(text, " \t")
+		if !strings.HasPrefix(text, prefix) {
+			continue
+		}
+		words := strings.Fields(text[len(prefix):])
+
+		d := Directive{
+			Range: hcl.Range{
+				Filename: tok.Range.Filename,
+				Start:    tok.Range.Start,
+				End:      posAfter(tok.Range.Start, bytes.TrimRight(tok.Bytes, "\r\n")),
+			},
+		}
+		if len(words) > 0 {
+			d.Name = words[0]
+			d.Args = words[1:]
+		}
+
+		trailing := i > 0 && tokens[i-1].Type != TokenNewline && tokens[i-1].Type != TokenComment &&
+			tokens[i-1].Range.End.Line == tok.Range.Start.Line
+		if trailing {
+			d.Node = innermostItemOnLine(items, tok.Range.Start.Line)
+		} else {
+			j := i + 1
+			for j < len(tokens) && tokens[j].Type == TokenComment {
+				j++
+			}
+			if j < len(tokens) {
+				d.Node = itemStartingAt(items, tokens[j].Range.Start.Byte)
+			}
+		}
+		ret = append(ret, d)
+	}
+	return ret
+}
+
+// lineCommentText returns the text of the given comment token without its
+// comment marker or terminating newline, or false if it is not a line
+// comment.
+func lineCommentText(tok Token) (string, bool) {
+	var text []byte
+	switch tok.CommentStyle() {
+	case CommentHash:
+		text = tok.Bytes[1:]
+	case CommentSlashes:
+		text = tok.Bytes[2:]
+	default:
+		return "", false
+	}
+	return string(bytes.TrimRight(text, "\r\n")), true
+}
+
+// collectDirectiveItems appends all of the attributes and blocks within the
+// given body to items, with each block before the items in its body.
+func collectDirectiveItems(body *Body, items *[]Node) {
+	for _, attr := range body.Attributes {
+		*items = append(*items, attr)
+	}
+	for _, block := range body.Blocks {
+		*items = append(*items, block)
+		collectDirectiveItems(block.Body, items)
+	}
+}
+
+func innermostItemOnLine(items []Node, line int) Node {
+	var ret Node
+	var retRange hcl.Range
+	for _, item := range items {
+		rng := item.Range()
+		if rng.Start.Line > line || rng.End.Line < line {
+			continue
+		}
+		if ret == nil || rng.Start.Byte >= retRange.Start.Byte && rng.End.Byte <= retRange.End.Byte {
+			ret, retRange = item, rng
+		}
+	}
+	return ret
+}
+
+func itemStartingAt(items []Node, byteOfs int) Node {
+	for _, item := range items {
+		if item.Range().Start.Byte == byteOfs {
+			return item
+		}
+	}
+	return nil
+}
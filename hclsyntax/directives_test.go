@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDirectives(t *testing.T) {
+	src := `# lint:file-wide
+
+# lint:disable a
+# an ordinary comment
+a = 1
+
+b = 2 // lint:disable b x y
+#lint:
+c = [
+  1, # lint:inside c
+]
+
+// not a lint:directive
+/* lint:block comments are not directives */
+thing "x" { # lint:disable thing
+  # lint:disable d
+  d = 3
+  e = 4 # lint:disable e
+}
+# lint:last
+`
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := Directives(file, "lint:")
+	want := []struct {
+		Name string
+		Args []string
+		Line int
+		Node string
+	}{
+		{"file-wide", []string{}, 1, ""},
+		{"disable", []string{"a"}, 3, "a"},
+		{"disable", []string{"b", "x", "y"}, 7, "b"},
+		{"", nil, 8, "c"},
+		{"inside", []string{"c"}, 10, "c"},
+		{"disable", []string{"thing"}, 15, "thing"},
+		{"disable", []string{"d"}, 16, "d"},
+		{"disable", []string{"e"}, 18, "e"},
+		{"last", []string{}, 20, ""},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of directives %d; want %d\n%#v", len(got), len(want), got)
+	}
+	for i, d := range got {
+		w := want[i]
+		if d.Name != w.Name || !reflect.DeepEqual(d.Args, w.Args) {
+			t.Errorf("%d: wrong directive %q %#v; want %q %#v", i, d.Name, d.Args, w.Name, w.Args)
+		}
+		if d.Range.Start.Line != w.Line || d.Range.End.Line != w.Line {
+			t.Errorf("%d: wrong range %s; want line %d", i, d.Range, w.Line)
+		}
+		if got := directiveNodeName(d.Node); got != w.Node {
+			t.Errorf("%d: wrong node %q; want %q", i, got, w.Node)
+		}
+	}
+}
+
+func TestDirectivesLeadingIndent(t *testing.T) {
+	src := "  # lint:disable x\n  a = 1\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := Directives(file, "lint:")
+	if len(got) != 1 {
+		t.Fatalf("wrong number of directives %d; want 1", len(got))
+	}
+	if got, want := got[0].Range.String(), "test.hcl:1,3-19"; got != want {
+		t.Errorf("wrong range %s; want %s", got, want)
+	}
+	if got, want := directiveNodeName(got[0].Node), "a"; got != want {
+		t.Errorf("wrong node %q; want %q", got, want)
+	}
+}
+
+func TestDirectivesNonNative(t *testing.T) {
+	file := &hcl.File{Body: hcl.EmptyBody()}
+	if got := Directives(file, "lint:"); got != nil {
+		t.Errorf("unexpected directives %#v", got)
+	}
+}
+
+func directiveNodeName(node Node) string {
+	switch node := node.(type) {
+	case nil:
+		return ""
+	case *Attribute:
+		return node.Name
+	case *Block:
+		return node.Type
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
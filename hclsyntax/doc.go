@@ -7,4 +7,13 @@
 // In normal use applications should rarely depend on this package directly,
 // instead preferring the higher-level interface of the main hcl package and
 // its companion package hclparse.
+//
+// All of the functions in this package that scan or parse source code may be
+// called concurrently from multiple goroutines. They keep no state between
+// calls, except for any Interner given with OptInternTokens, which is itself
+// safe for concurrent use. The syntax trees they return are not modified by
+// evaluation, so a single tree may be evaluated concurrently, but a caller
+// that modifies a tree must not use it concurrently with any other access.
+// The bytes of the returned tokens must never be modified, because they may
+// be shared with the source buffer or with other tokens.
 package hclsyntax
@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Fprint writes an indented representation of the given syntax tree node and
+// all of its descendents to the given writer, for debugging.
+//
+// Each node is shown with its type and source range, followed by its
+// exported fields. The exact format is intended for human consumption and
+// may change in future versions, so it should not be parsed by programs.
+func Fprint(w io.Writer, node Node) error {
+	p := &dumper{w: w}
+	p.value(0, "", reflect.ValueOf(node))
+	return p.err
+}
+
+// Print is like Fprint but writes to the standard output.
+func Print(node Node) error {
+	return Fprint(os.Stdout, node)
+}
+
+// Dump is like Fprint but returns the representation as a string.
+func Dump(node Node) string {
+	var buf bytes.Buffer
+	Fprint(&buf, node) // writing to a buffer can't fail
+	return buf.String()
+}
+
+var (
+	nodeType      = reflect.TypeOf((*Node)(nil)).Elem()
+	rangeType     = reflect.TypeOf(hcl.Range{})
+	posType       = reflect.TypeOf(hcl.Pos{})
+	ctyValueType  = reflect.TypeOf(cty.Value{})
+	ctyTypeType   = reflect.TypeOf(cty.Type{})
+	operationType = reflect.TypeOf((*Operation)(nil))
+)
+
+var operationNames = map[*Operation]string{
+	OpLogicalOr:          "OpLogicalOr",
+	OpLogicalAnd:         "OpLogicalAnd",
+	OpLogicalNot:         "OpLogicalNot",
+	OpEqual:              "OpEqual",
+	OpNotEqual:           "OpNotEqual",
+	OpGreaterThan:        "OpGreaterThan",
+	OpGreaterThanOrEqual: "OpGreaterThanOrEqual",
+	OpLessThan:           "OpLessThan",
+	OpLessThanOrEqual:    "OpLessThanOrEqual",
+	OpAdd:                "OpAdd",
+	OpSubtract:           "OpSubtract",
+	OpMultiply:           "OpMultiply",
+	OpDivide:             "OpDivide",
+	OpModulo:             "OpModulo",
+	OpNegate:             "OpNegate",
+}
+
+type dumper struct {
+	w   io.Writer
+	err error
+}
+
+func (p *dumper) printf(indent int, label string, format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	prefix := strings.Repeat("  ", indent)
+	if label != "" {
+		prefix += label + ": "
+	}
+	_, p.err = fmt.Fprintf(p.w, prefix+format+"\n", args...)
+}
+
+func (p *dumper) value(indent int, label string, v reflect.Value) {
+	if !v.IsValid() {
+		p.printf(indent, label, "nil")
+		return
+	}
+
+	switch v.Type() {
+	case rangeType:
+		p.printf(indent, label, "%s", dumpRange(v.Interface().(hcl.Range)))
+		return
+	case posType:
+		pos := v.Interface().(hcl.Pos)
+		p.printf(indent, label, "%d,%d (byte %d)", pos.Line, pos.Column, pos.Byte)
+		return
+	case ctyValueType, ctyTypeType:
+		p.printf(indent, label, "%#v", v.Interface())
+		return
+	case operationType:
+		if name, ok := operationNames[v.Interface().(*Operation)]; ok {
+			p.printf(indent, label, "%s", name)
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			p.printf(indent, label, "nil")
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		p.value(indent, label, v.Elem())
+
+	case reflect.Ptr:
+		if v.Elem().Kind() != reflect.Struct {
+			p.value(indent, label, v.Elem())
+			return
+		}
+		p.structValue(indent, label, v.Type().String(), v, v.Elem())
+
+	case reflect.Struct:
+		p.structValue(indent, label, v.Type().String(), v, v)
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			p.printf(indent, label, "%q", v.Bytes())
+			return
+		}
+		p.printf(indent, label, "%s (len = %d)", v.Type(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			p.value(indent+1, fmt.Sprintf("[%d]", i), v.Index(i))
+		}
+
+	case reflect.Map:
+		p.printf(indent, label, "%s (len = %d)", v.Type(), v.Len())
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			p.value(indent+1, fmt.Sprintf("%#v", k.Interface()), v.MapIndex(k))
+		}
+
+	case reflect.String:
+		p.printf(indent, label, "%q", v.String())
+
+	default:
+		if v.CanInterface() {
+			p.printf(indent, label, "%#v", v.Interface())
+		} else {
+			p.printf(indent, label, "%s", v.Kind())
+		}
+	}
+}
+
+// structValue prints a struct, or a pointer to a struct, with one line for
+// each of its exported fields. Syntax tree nodes are shown with their
+// source range, and their SrcRange fields are then omitted as redundant.
+func (p *dumper) structValue(indent int, label string, typeName string, v, sv reflect.Value) {
+	isNode := v.Type().Implements(nodeType)
+	if isNode {
+		p.printf(indent, label, "%s (%s)", typeName, dumpRange(v.Interface().(Node).Range()))
+	} else {
+		p.printf(indent, label, "%s", typeName)
+	}
+
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if isNode && field.Name == "SrcRange" {
+			continue
+		}
+		p.value(indent+1, field.Name, sv.Field(i))
+	}
+}
+
+// dumpRange formats a source range without its filename, which would be
+// the same for every node in a tree.
+func dumpRange(rng hcl.Range) string {
+	if rng.Start.Line == rng.End.Line {
+		return fmt.Sprintf("%d,%d-%d", rng.Start.Line, rng.Start.Column, rng.End.Column)
+	}
+	return fmt.Sprintf("%d,%d-%d,%d", rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
+}
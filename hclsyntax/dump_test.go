@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDump(t *testing.T) {
+	src := "a = b.c + 1\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := Dump(file.Body.(*Body))
+	want := `*hclsyntax.Body (1,1-2,1)
+  Attributes: hclsyntax.Attributes (len = 1)
+    "a": *hclsyntax.Attribute (1,1-12)
+      Name: "a"
+      Expr: *hclsyntax.BinaryOpExpr (1,5-12)
+        LHS: *hclsyntax.ScopeTraversalExpr (1,5-8)
+          Traversal: hcl.Traversal (len = 2)
+            [0]: hcl.TraverseRoot
+              Name: "b"
+              SrcRange: 1,5-6
+            [1]: hcl.TraverseAttr
+              Name: "c"
+              SrcRange: 1,6-8
+        Op: OpAdd
+        RHS: *hclsyntax.LiteralValueExpr (1,11-12)
+          Val: cty.NumberIntVal(1)
+      NameRange: 1,1-2
+      EqualsRange: 1,3-4
+  Blocks: hclsyntax.Blocks (len = 0)
+  EndRange: 2,1-1
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
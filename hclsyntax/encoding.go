@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+var (
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// hasUTF16BOM returns true if the given buffer begins with a UTF-16 byte
+// order mark in either byte order.
+func hasUTF16BOM(src []byte) bool {
+	return bytes.HasPrefix(src, utf16LEBOM) || bytes.HasPrefix(src, utf16BEBOM)
+}
+
+// decodeUTF16 transcodes the given buffer to UTF-8 if it begins with a
+// UTF-16 byte order mark, removing the byte order mark. Otherwise it returns
+// the buffer unchanged.
+//
+// Unpaired surrogates, and a trailing odd byte, are replaced with the
+// Unicode replacement character U+FFFD.
+func decodeUTF16(src []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(src, utf16LEBOM):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(src, utf16BEBOM):
+		order = binary.BigEndian
+	default:
+		return src
+	}
+
+	src = src[2:]
+	units := make([]uint16, len(src)/2)
+	for i := range units {
+		units[i] = order.Uint16(src[i*2:])
+	}
+	runes := utf16.Decode(units)
+	if len(src)%2 != 0 {
+		runes = append(runes, utf8.RuneError)
+	}
+
+	ret := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		ret = utf8.AppendRune(ret, r)
+	}
+	return ret
+}
+
+// utf16Diagnostics returns an error explaining that UTF-16 input isn't
+// supported without OptDecodeUTF16, which is more helpful than the errors
+// the scanner would otherwise produce for each character.
+func utf16Diagnostics(filename string, start hcl.Pos) hcl.Diagnostics {
+	end := start
+	end.Byte += 2
+	end.Column++
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported character encoding",
+			Detail:   "This file appears to be encoded as UTF-16, but all input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
+			Subject: &hcl.Range{
+				Filename: filename,
+				Start:    start,
+				End:      end,
+			},
+		},
+	}
+}
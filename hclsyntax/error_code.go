@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ErrorCode is a machine-readable identifier for a particular kind of problem
+// detected while scanning the native syntax.
+//
+// Diagnostics reporting lexical problems, and some common mistakes found by
+// the parser, carry an ErrorCode in their Extra field, retrievable using
+// DiagnosticErrorCode. Callers can use it to select
+// documentation links or localized messages without matching against the
+// English text of the diagnostic summary or detail, which may change between
+// releases. The codes themselves, and their String results, will not change.
+type ErrorCode int
+
+const (
+	// ErrNone is the zero value of ErrorCode, returned by DiagnosticErrorCode
+	// for diagnostics that have no code.
+	ErrNone ErrorCode = iota
+
+	// ErrIllegalUTF8 indicates that the input is not valid UTF-8.
+	ErrIllegalUTF8
+
+	// ErrUnsupportedOperator indicates the use of an operator from other
+	// languages that HCL doesn't support, such as bitwise operators.
+	ErrUnsupportedOperator
+
+	// ErrIllegalBacktick indicates a backtick used as if to quote a string.
+	ErrIllegalBacktick
+
+	// ErrIllegalSingleQuote indicates single quotes used as if to quote a
+	// string.
+	ErrIllegalSingleQuote
+
+	// ErrIllegalSemicolon indicates a semicolon used as a separator.
+	ErrIllegalSemicolon
+
+	// ErrIllegalTab indicates a tab character used for indentation.
+	ErrIllegalTab
+
+	// ErrIllegalCurlyQuote indicates a typographic quote mark used in place
+	// of a straight quote.
+	ErrIllegalCurlyQuote
+
+	// ErrIllegalCharacter indicates some other character that is not used
+	// within the language.
+	ErrIllegalCharacter
+
+	// ErrQuotedNewline indicates a quoted string that spans multiple lines.
+	ErrQuotedNewline
+
+	// ErrUnterminatedString indicates a quoted string with no closing quote.
+	ErrUnterminatedString
+
+	// ErrUnterminatedRawString indicates a raw string with no closing
+	// delimiter.
+	ErrUnterminatedRawString
+
+	// ErrUnterminatedTemplate indicates a template with no closing marker.
+	ErrUnterminatedTemplate
+
+	// ErrInvalidEscape indicates a malformed backslash escape sequence in a
+	// quoted string.
+	ErrInvalidEscape
+
+	// ErrInvalidNumber indicates a number literal whose value cannot be
+	// recognized.
+	ErrInvalidNumber
+
+	// ErrMissingEquals indicates an argument whose name is followed by a
+	// colon, or directly by its value, rather than by an equals sign.
+	ErrMissingEquals
+
+	// ErrUnclosedBlock indicates a block with no closing brace.
+	ErrUnclosedBlock
+)
+
+// ErrorCodeDiagExtra is an interface implemented by the value in the "Extra"
+// field of diagnostics that report lexical problems.
+type ErrorCodeDiagExtra interface {
+	// ErrorCode returns the code identifying the kind of problem.
+	ErrorCode() ErrorCode
+}
+
+type errorCodeDiagExtra struct {
+	// The fields are exported only so that go-cmp can compare diagnostics in
+	// tests; the type itself is unexported.
+	Code ErrorCode
+
+	// Edits are the suggested edits returned by SuggestedEdits, if any.
+	Edits []SuggestedEdit
+
+	// Err is the *hcl.SyntaxError describing an error diagnostic, which
+	// markSyntaxErrors populates because hcl.MarkSyntaxErrors won't replace
+	// an existing Extra value.
+	Err *hcl.SyntaxError
+}
+
+func errorCodeExtra(code ErrorCode) *errorCodeDiagExtra {
+	return &errorCodeDiagExtra{Code: code}
+}
+
+func (e *errorCodeDiagExtra) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+func (e *errorCodeDiagExtra) UnwrapDiagnosticExtra() interface{} {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// DiagnosticErrorCode returns the ErrorCode carried by the given diagnostic,
+// or ErrNone if it doesn't have one.
+func DiagnosticErrorCode(diag *hcl.Diagnostic) ErrorCode {
+	extra, ok := hcl.DiagnosticExtra[ErrorCodeDiagExtra](diag)
+	if !ok {
+		return ErrNone
+	}
+	return extra.ErrorCode()
+}
+
+// markSyntaxErrors is like hcl.MarkSyntaxErrors, but also marks the error
+// diagnostics that carry an ErrorCode.
+func markSyntaxErrors(diags hcl.Diagnostics) hcl.Diagnostics {
+	for _, diag := range diags {
+		extra, ok := diag.Extra.(*errorCodeDiagExtra)
+		if diag.Severity != hcl.DiagError || !ok || extra.Err != nil {
+			continue
+		}
+		extra.Err = &hcl.SyntaxError{Summary: diag.Summary}
+		if diag.Subject != nil {
+			extra.Err.Range = *diag.Subject
+		}
+	}
+	return hcl.MarkSyntaxErrors(diags)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDiagnosticErrorCode(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want ErrorCode
+	}{
+		"bad utf-8": {
+			"a = 1\n\xfe\n",
+			ErrIllegalUTF8,
+		},
+		"bitwise operator": {
+			"a = 1 & 2\n",
+			ErrUnsupportedOperator,
+		},
+		"backtick": {
+			"a = `b`\n",
+			ErrIllegalBacktick,
+		},
+		"single quote": {
+			"a = 'b'\n",
+			ErrIllegalSingleQuote,
+		},
+		"semicolon": {
+			"a = 1;\n",
+			ErrIllegalSemicolon,
+		},
+		"curly quote": {
+			"a = “b”\n",
+			ErrIllegalCurlyQuote,
+		},
+		"other character": {
+			"a = @\n",
+			ErrIllegalCharacter,
+		},
+		"quoted newline": {
+			"a = \"b\nc\"\n",
+			ErrQuotedNewline,
+		},
+		"unterminated template": {
+			"a = \"${b}",
+			ErrUnterminatedTemplate,
+		},
+		"unterminated label": {
+			"a \"b",
+			ErrUnterminatedString,
+		},
+		"invalid escape": {
+			"a = \"\\q\"\n",
+			ErrInvalidEscape,
+		},
+		"no code": {
+			"a = \n",
+			ErrNone,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if len(diags) == 0 {
+				t.Fatalf("no diagnostics")
+			}
+
+			// The lexical problem might be reported alongside other
+			// diagnostics from the parser, so we look for the first that has
+			// a code.
+			diag := diags[0]
+			for _, d := range diags {
+				if DiagnosticErrorCode(d) != ErrNone {
+					diag = d
+					break
+				}
+			}
+			if got := DiagnosticErrorCode(diag); got != test.want {
+				t.Errorf("wrong code %s for %q; want %s", got, diag.Summary, test.want)
+			}
+
+			// Carrying a code must not prevent error diagnostics from being
+			// recognized as syntax errors.
+			if diag.Severity == hcl.DiagError {
+				var syntaxErr *hcl.SyntaxError
+				if !errors.As(diag, &syntaxErr) {
+					t.Errorf("no SyntaxError in %s", diag)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorCodeString(t *testing.T) {
+	if got, want := ErrUnterminatedString.String(), "ErrUnterminatedString"; got != want {
+		t.Errorf("wrong string %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Event is implemented by the values that ParseEvents passes to its handler:
+// *StartBlockEvent, *AttributeEvent and *EndBlockEvent.
+type Event interface {
+	// Range returns the source range of the construct that the event
+	// describes.
+	Range() hcl.Range
+
+	isEvent()
+}
+
+// StartBlockEvent reports the header of a block, up to and including its
+// opening brace. The events for the block's content follow it, and then a
+// matching EndBlockEvent.
+type StartBlockEvent struct {
+	Type   string
+	Labels []string
+
+	TypeRange      hcl.Range
+	LabelRanges    []hcl.Range
+	OpenBraceRange hcl.Range
+
+	// Depth is the number of blocks that enclose this one, so zero for a
+	// block at the top level of the file.
+	Depth int
+}
+
+// AttributeEvent reports an attribute definition.
+//
+// The attribute's expression is not parsed unless the handler calls Expr,
+// so a handler that is interested in only some attributes avoids the cost
+// of building expressions for the others.
+type AttributeEvent struct {
+	Name      string
+	NameRange hcl.Range
+	ExprRange hcl.Range
+
+	// Depth is the number of blocks that enclose the attribute.
+	Depth int
+
+	tokens Tokens
+	opts   parseOpts
+}
+
+// EndBlockEvent reports the closing brace of a block.
+type EndBlockEvent struct {
+	// Type is the type of the block being closed, for convenience.
+	Type string
+
+	CloseBraceRange hcl.Range
+
+	// Depth is the number of blocks that enclose the block being closed.
+	Depth int
+}
+
+func (e *StartBlockEvent) Range() hcl.Range {
+	return hcl.RangeBetween(e.TypeRange, e.OpenBraceRange)
+}
+
+func (e *AttributeEvent) Range() hcl.Range {
+	return hcl.RangeBetween(e.NameRange, e.ExprRange)
+}
+
+func (e *EndBlockEvent) Range() hcl.Range {
+	return e.CloseBraceRange
+}
+
+func (e *StartBlockEvent) isEvent() {}
+func (e *AttributeEvent) isEvent()  {}
+func (e *EndBlockEvent) isEvent()   {}
+
+// Expr parses and returns the attribute's expression. The result is not
+// cached, so each call parses the expression again.
+func (e *AttributeEvent) Expr() (Expression, hcl.Diagnostics) {
+	tokens := make(Tokens, 0, len(e.tokens)+1)
+	tokens = append(tokens, e.tokens...)
+	tokens = append(tokens, Token{
+		Type:  TokenEOF,
+		Range: hcl.Range{Filename: e.ExprRange.Filename, Start: e.ExprRange.End, End: e.ExprRange.End},
+	})
+
+	peeker := newPeeker(tokens, false)
+	parser := newParser(peeker, e.opts)
+	parser.PushIncludeNewlines(false)
+	expr, diags := parser.ParseExpression()
+	if next := parser.Peek(); next.Type != TokenEOF && !parser.recovery {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Extra characters after expression",
+			Detail:   "An expression was successfully parsed, but extra characters were found after it.",
+			Subject:  &next.Range,
+		})
+	}
+	parser.PopIncludeNewlines()
+	return expr, diags
+}
+
+// ParseEvents scans the given native syntax configuration source code and
+// calls the given handler for each block and attribute in the order they
+// appear, without building an abstract syntax tree. This is intended for
+// consumers that scan very large configuration files, such as those
+// generated by other programs, and need only some of their content.
+//
+// ParseEvents stops early if the handler returns false. The events and the
+// ranges within them remain valid after the handler returns.
+//
+// ParseEvents checks only the structure of blocks and attributes, and
+// reports errors for malformed block headers and unbalanced braces. It
+// doesn't check the syntax of attribute expressions, which is done only
+// when the handler calls AttributeEvent.Expr. Unlike ParseConfig, it also
+// doesn't report duplicate attributes. If the source code contains
+// characters that are not valid tokens at all, ParseEvents reports them
+// without calling the handler.
+func ParseEvents(src []byte, filename string, start hcl.Pos, handler func(Event) bool, opts ...ParseOption) hcl.Diagnostics {
+	o := newParseOpts(opts)
+	o.whitespaceTokens = false // the event parser walks the tokens directly
+	tokens, diags := lexTokens(src, filename, start, scanNormal, o)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	p := &eventParser{
+		tokens:  tokens,
+		opts:    o,
+		handler: handler,
+	}
+	p.run()
+	return append(diags, p.diags...)
+}
+
+type eventParser struct {
+	tokens  Tokens
+	pos     int
+	opts    parseOpts
+	handler func(Event) bool
+	diags   hcl.Diagnostics
+
+	// open is the stack of blocks that have been started but not ended.
+	open []*StartBlockEvent
+}
+
+func (p *eventParser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *eventParser) read() Token {
+	tok := p.tokens[p.pos]
+	if tok.Type != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *eventParser) run() {
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case TokenNewline, TokenComment:
+			p.read()
+
+		case TokenComma:
+			// Commas separate the attributes of single-line blocks, if
+			// OptMultiArgumentBlocks allows more than one.
+			p.read()
+			if !p.opts.multiArgBlocks {
+				p.diags = append(p.diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid single-argument block definition",
+					Detail:   "Single-line block syntax can include only one argument definition. To define multiple arguments, use the multi-line block syntax with one argument definition per line.",
+					Subject:  &tok.Range,
+				})
+			}
+
+		case TokenEOF:
+			if len(p.open) > 0 {
+				block := p.open[len(p.open)-1]
+				p.diags = append(p.diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unclosed configuration block",
+					Detail:   unclosedBlockDetail(block.OpenBraceRange, nil),
+					Subject:  block.OpenBraceRange.Ptr(),
+					Context:  block.Range().Ptr(),
+				})
+			}
+			return
+
+		case TokenCBrace:
+			p.read()
+			if len(p.open) == 0 {
+				p.diags = append(p.diags, argumentOrBlockRequired(tok.Range))
+				continue
+			}
+			block := p.open[len(p.open)-1]
+			p.open = p.open[:len(p.open)-1]
+			if !p.handler(&EndBlockEvent{
+				Type:            block.Type,
+				CloseBraceRange: tok.Range,
+				Depth:           len(p.open),
+			}) {
+				return
+			}
+
+		case TokenIdent:
+			if !p.item() {
+				return
+			}
+
+		default:
+			p.read()
+			p.diags = append(p.diags, argumentOrBlockRequired(tok.Range))
+			p.recover()
+		}
+	}
+}
+
+// item parses a single attribute or block header, returning false if the
+// handler asked to stop.
+func (p *eventParser) item() bool {
+	ident := p.read()
+	name := string(ident.Bytes)
+
+	if p.peek().Type == TokenEqual {
+		p.read()
+		return p.handler(p.attribute(ident, name))
+	}
+
+	block := &StartBlockEvent{
+		Type:      name,
+		TypeRange: ident.Range,
+		Depth:     len(p.open),
+	}
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case TokenOBrace:
+			if limit := p.opts.nestingLimit(); len(p.open) >= limit {
+				p.diags = append(p.diags, nestingTooDeepDiagnostic(tok.Range, limit))
+				return false
+			}
+			p.read()
+			block.OpenBraceRange = tok.Range
+			p.open = append(p.open, block)
+			return p.handler(block)
+
+		case TokenIdent:
+			p.read()
+			block.Labels = append(block.Labels, string(tok.Bytes))
+			block.LabelRanges = append(block.LabelRanges, tok.Range)
+
+		case TokenOQuote:
+			label, rng, ok := p.quotedLabel()
+			if !ok {
+				p.recover()
+				return true
+			}
+			block.Labels = append(block.Labels, label)
+			block.LabelRanges = append(block.LabelRanges, rng)
+
+		default:
+			if len(block.Labels) == 0 {
+				p.diags = append(p.diags, argumentOrBlockRequired(ident.Range))
+			} else {
+				p.diags = append(p.diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid block definition",
+					Detail:   "Either a quoted string block label or an opening brace (\"{\") is expected here.",
+					Subject:  &tok.Range,
+					Context:  hcl.RangeBetween(ident.Range, tok.Range).Ptr(),
+				})
+			}
+			p.recover()
+			return true
+		}
+	}
+}
+
+// attribute collects the tokens of an attribute's expression, which begins
+// at the current position, and returns the resulting event.
+func (p *eventParser) attribute(ident Token, name string) *AttributeEvent {
+	startPos := p.pos
+	depth := 0
+Tokens:
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case TokenEOF:
+			break Tokens
+		case TokenOBrace, TokenOBrack, TokenOParen, TokenTemplateInterp, TokenTemplateControl, TokenOQuote, TokenOHeredoc:
+			depth++
+		case TokenCBrace, TokenCBrack, TokenCParen, TokenTemplateSeqEnd, TokenCQuote, TokenCHeredoc:
+			if depth == 0 {
+				// The closing brace of a single-line block.
+				break Tokens
+			}
+			depth--
+		case TokenNewline, TokenComma:
+			if depth == 0 {
+				break Tokens
+			}
+		case TokenComment:
+			if depth == 0 && bytes.HasSuffix(tok.Bytes, []byte{'\n'}) {
+				break Tokens
+			}
+		}
+		p.read()
+	}
+
+	tokens := p.tokens[startPos:p.pos]
+	exprRange := hcl.Range{Filename: ident.Range.Filename, Start: p.tokens[startPos-1].Range.End, End: p.tokens[startPos-1].Range.End}
+	var first, last *Token
+	for i := range tokens {
+		if tokens[i].Type == TokenComment {
+			continue
+		}
+		if first == nil {
+			first = &tokens[i]
+		}
+		last = &tokens[i]
+	}
+	if first != nil {
+		exprRange = hcl.RangeBetween(first.Range, last.Range)
+	}
+
+	return &AttributeEvent{
+		Name:      name,
+		NameRange: ident.Range,
+		ExprRange: exprRange,
+		Depth:     len(p.open),
+		tokens:    tokens,
+		opts:      p.opts,
+	}
+}
+
+// quotedLabel parses a quoted block label starting at the current position.
+func (p *eventParser) quotedLabel() (string, hcl.Range, bool) {
+	open := p.read()
+	var buf bytes.Buffer
+	for {
+		tok := p.read()
+		switch tok.Type {
+		case TokenCQuote:
+			return buf.String(), hcl.RangeBetween(open.Range, tok.Range), true
+		case TokenQuotedLit:
+			s, diags := ParseStringLiteralToken(tok)
+			p.diags = append(p.diags, diags...)
+			buf.WriteString(s)
+		case TokenTemplateInterp, TokenTemplateControl:
+			p.diags = append(p.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid block label",
+				Detail:   "Template sequences are not allowed in block labels.",
+				Subject:  &tok.Range,
+			})
+			return "", hcl.Range{}, false
+		default:
+			p.diags = append(p.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unterminated block label",
+				Detail:   "There is no closing quote for this block label.",
+				Subject:  &open.Range,
+			})
+			return "", hcl.Range{}, false
+		}
+	}
+}
+
+// recover skips tokens up to the end of the current line, ignoring any
+// newlines within brackets, to resume parsing at the next item.
+func (p *eventParser) recover() {
+	depth := 0
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case TokenEOF:
+			return
+		case TokenOBrace, TokenOBrack, TokenOParen:
+			depth++
+		case TokenCBrace, TokenCBrack, TokenCParen:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case TokenNewline:
+			if depth == 0 {
+				return
+			}
+		}
+		p.read()
+	}
+}
+
+func argumentOrBlockRequired(rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Argument or block definition required",
+		Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
+		Subject:  &rng,
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseEvents(t *testing.T) {
+	src := `
+a = 1 # comment
+service "web" "primary" {
+  port = 8080
+  tags = [
+    "x", # first
+    "y",
+  ]
+  limits { cpu = 1, mem = "${2 + 2}" }
+  script = <<EOT
+  echo }
+EOT
+}
+b = { c = 1 }
+`
+	var got []string
+	diags := ParseEvents([]byte(src), "test.hcl", hcl.InitialPos, func(ev Event) bool {
+		rng := ev.Range()
+		switch ev := ev.(type) {
+		case *StartBlockEvent:
+			got = append(got, fmt.Sprintf("%d start %s %q %d:%d", ev.Depth, ev.Type, ev.Labels, rng.Start.Line, rng.End.Line))
+		case *AttributeEvent:
+			expr, diags := ev.Expr()
+			if diags.HasErrors() {
+				t.Errorf("%s: %s", ev.Name, diags.Error())
+				return true
+			}
+			val, _ := expr.Value(nil)
+			got = append(got, fmt.Sprintf("%d attr %s %#v %d:%d", ev.Depth, ev.Name, val, rng.Start.Line, rng.End.Line))
+		case *EndBlockEvent:
+			got = append(got, fmt.Sprintf("%d end %s %d", ev.Depth, ev.Type, rng.Start.Line))
+		}
+		return true
+	}, OptMultiArgumentBlocks())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	want := []string{
+		`0 attr a cty.NumberIntVal(1) 2:2`,
+		`0 start service ["web" "primary"] 3:3`,
+		`1 attr port cty.NumberIntVal(8080) 4:4`,
+		`1 attr tags cty.TupleVal([]cty.Value{cty.StringVal("x"), cty.StringVal("y")}) 5:8`,
+		`1 start limits [] 9:9`,
+		`2 attr cpu cty.NumberIntVal(1) 9:9`,
+		`2 attr mem cty.NumberIntVal(4) 9:9`,
+		`1 end limits 9`,
+		`1 attr script cty.StringVal("  echo }\n") 10:12`,
+		`0 end service 13`,
+		`0 attr b cty.ObjectVal(map[string]cty.Value{"c":cty.NumberIntVal(1)}) 14:14`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("wrong events\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseEventsStop(t *testing.T) {
+	src := "a = 1\nb = 2\nc = 3\n"
+	var names []string
+	diags := ParseEvents([]byte(src), "test.hcl", hcl.InitialPos, func(ev Event) bool {
+		names = append(names, ev.(*AttributeEvent).Name)
+		return len(names) < 2
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if got, want := strings.Join(names, ","), "a,b"; got != want {
+		t.Errorf("wrong attributes %q; want %q", got, want)
+	}
+}
+
+func TestParseEventsErrors(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		summary string
+		events  int
+	}{
+		"unclosed block": {
+			"a {\n  b = 1\n",
+			"Unclosed configuration block",
+			2,
+		},
+		"extra closing brace": {
+			"a = 1\n}\nb = 2\n",
+			"Argument or block definition required",
+			2,
+		},
+		"missing brace": {
+			"a \"b\" c = 1\nd = 2\n",
+			"Invalid block definition",
+			1,
+		},
+		"multiple single-line arguments": {
+			"a { b = 1, c = 2 }\n",
+			"Invalid single-argument block definition",
+			4,
+		},
+		"template label": {
+			"a \"${b}\" {\n}\n",
+			"Invalid block label",
+			0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			events := 0
+			diags := ParseEvents([]byte(test.src), "test.hcl", hcl.InitialPos, func(ev Event) bool {
+				events++
+				return true
+			})
+			if !diags.HasErrors() {
+				t.Fatalf("unexpected success")
+			}
+			if got := diags[0].Summary; got != test.summary {
+				t.Errorf("wrong summary %q; want %q", got, test.summary)
+			}
+			if events < test.events {
+				t.Errorf("wrong number of events %d; want at least %d", events, test.events)
+			}
+		})
+	}
+}
+
+func TestParseEventsExprError(t *testing.T) {
+	var diags hcl.Diagnostics
+	parseDiags := ParseEvents([]byte("a = 1 +\nb = 2\n"), "test.hcl", hcl.InitialPos, func(ev Event) bool {
+		if attr, ok := ev.(*AttributeEvent); ok {
+			_, exprDiags := attr.Expr()
+			diags = append(diags, exprDiags...)
+		}
+		return true
+	})
+	if parseDiags.HasErrors() {
+		t.Fatal(parseDiags.Error())
+	}
+	if !diags.HasErrors() {
+		t.Fatal("missing error for invalid expression")
+	}
+	if got := diags[0].Subject.Start.Line; got != 1 {
+		t.Errorf("wrong error line %d; want 1", got)
+	}
+
+	// A valid expression must evaluate as it would in a full parse.
+	ParseEvents([]byte("x = 2 * 3\n"), "test.hcl", hcl.InitialPos, func(ev Event) bool {
+		expr, _ := ev.(*AttributeEvent).Expr()
+		if val, _ := expr.Value(nil); !val.RawEquals(cty.NumberIntVal(6)) {
+			t.Errorf("wrong value %#v", val)
+		}
+		return true
+	})
+}
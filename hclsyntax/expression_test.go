@@ -555,6 +555,45 @@ upper(
 			}),
 			0,
 		},
+		{
+			`{hello = "world",}`,
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"hello": cty.StringVal("world"),
+			}),
+			0,
+		},
+		{
+			`{a = 1, b = {c = [1, 2,], d = {e = true,},},}`,
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.NumberIntVal(1),
+				"b": cty.ObjectVal(map[string]cty.Value{
+					"c": cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+					"d": cty.ObjectVal(map[string]cty.Value{
+						"e": cty.True,
+					}),
+				}),
+			}),
+			0,
+		},
+		{
+			"[\n  { port = 80 },\n  {\n    port = 443\n  },\n]",
+			nil,
+			cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(80)}),
+				cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(443)}),
+			}),
+			0,
+		},
+		{
+			`{a = 1, , b = 2}`,
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.NumberIntVal(1),
+			}), // (due to parser recovery behavior)
+			1, // Invalid expression
+		},
 		{
 			`{true: "yes"}`,
 			nil,
@@ -61,3 +61,53 @@ func FuzzParseConfig(f *testing.F) {
 		}
 	})
 }
+
+func FuzzLexConfig(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tokens, _ := hclsyntax.LexConfig(data, "<fuzz-conf>", hcl.Pos{Line: 1, Column: 1})
+		checkTokens(t, data, tokens)
+	})
+}
+
+func FuzzLexExpression(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tokens, _ := hclsyntax.LexExpression(data, "<fuzz-expr>", hcl.Pos{Line: 1, Column: 1})
+		checkTokens(t, data, tokens)
+	})
+}
+
+func FuzzLexTemplate(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tokens, _ := hclsyntax.LexTemplate(data, "<fuzz-tmpl>", hcl.Pos{Line: 1, Column: 1})
+		checkTokens(t, data, tokens)
+	})
+}
+
+// checkTokens verifies the invariants that the parser relies on: the token
+// stream ends with exactly one EOF token and the tokens cover the input in
+// order, with each token's bytes matching the source it claims to span.
+func checkTokens(t *testing.T, data []byte, tokens hclsyntax.Tokens) {
+	t.Helper()
+
+	if len(tokens) == 0 {
+		t.Fatalf("no tokens returned for %q", data)
+	}
+	if last := tokens[len(tokens)-1]; last.Type != hclsyntax.TokenEOF {
+		t.Fatalf("final token is %s, not EOF, for %q", last.Type, data)
+	}
+
+	prevEnd := 0
+	for i, tok := range tokens {
+		if tok.Type == hclsyntax.TokenEOF && i != len(tokens)-1 {
+			t.Fatalf("EOF token at position %d of %d for %q", i, len(tokens), data)
+		}
+		start, end := tok.Range.Start.Byte, tok.Range.End.Byte
+		if start < prevEnd || end < start || end > len(data) {
+			t.Fatalf("token %d (%s) has invalid byte range %d-%d (previous ended at %d) for %q", i, tok.Type, start, end, prevEnd, data)
+		}
+		if string(tok.Bytes) != string(data[start:end]) {
+			t.Fatalf("token %d (%s) bytes %q do not match source %q", i, tok.Type, tok.Bytes, data[start:end])
+		}
+		prevEnd = end
+	}
+}
@@ -0,0 +1,2 @@
+go test fuzz v1
+[]byte("block {\n  another_block {\n    foo = bar\n  }\n}\n")
\ No newline at end of file
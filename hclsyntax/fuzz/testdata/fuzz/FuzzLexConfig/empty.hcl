@@ -0,0 +1,2 @@
+go test fuzz v1
+[]byte("")
\ No newline at end of file
@@ -0,0 +1,2 @@
+go test fuzz v1
+[]byte("a = x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : x ? y : z\n")
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sync"
+)
+
+// Interner is a table of strings that can be shared between many parsing
+// operations, using OptInternTokens, so that identifiers and block labels
+// that repeat across many files -- such as "resource" and "variable" in a
+// large Terraform codebase -- are stored in memory only once.
+//
+// The zero value of Interner is an empty table ready to use. An Interner is
+// safe for concurrent use by multiple goroutines. It only ever grows, so
+// callers should discard it once they have finished parsing a related set of
+// files.
+type Interner struct {
+	mu   sync.Mutex
+	strs map[string]string
+}
+
+// Intern returns a string equal to the given bytes, returning the same
+// string that was returned for any previous call with equal bytes.
+func (in *Interner) Intern(b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if s, ok := in.strs[string(b)]; ok {
+		return s
+	}
+	return in.add(string(b))
+}
+
+// InternString is like Intern but takes a string.
+func (in *Interner) InternString(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if existing, ok := in.strs[s]; ok {
+		return existing
+	}
+	return in.add(s)
+}
+
+// Len returns the number of distinct strings in the table.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strs)
+}
+
+func (in *Interner) add(s string) string {
+	if in.strs == nil {
+		in.strs = make(map[string]string)
+	}
+	in.strs[s] = s
+	return s
+}
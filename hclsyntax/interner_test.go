@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestOptInternTokens(t *testing.T) {
+	var in Interner
+
+	parse := func(src string) *Body {
+		t.Helper()
+		f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptInternTokens(&in))
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		return f.Body.(*Body)
+	}
+
+	a := parse("resource \"aws_instance\" \"a\" {\n  ami = var.ami\n}\n")
+	b := parse("resource \"aws_instance\" \"b\" {\n  ami = var.ami\n}\n")
+
+	// resource, aws_instance, a, b, ami, var
+	if got, want := in.Len(), 6; got != want {
+		t.Errorf("wrong number of interned strings %d; want %d", got, want)
+	}
+
+	blockA, blockB := a.Blocks[0], b.Blocks[0]
+	if !sameString(blockA.Type, blockB.Type) {
+		t.Errorf("block types are not shared")
+	}
+	if !sameString(blockA.Labels[0], blockB.Labels[0]) {
+		t.Errorf("block labels are not shared")
+	}
+	if !sameString(blockA.Body.Attributes["ami"].Name, blockB.Body.Attributes["ami"].Name) {
+		t.Errorf("attribute names are not shared")
+	}
+}
+
+// sameString returns true if the two strings share the same backing memory.
+func sameString(a, b string) bool {
+	ha := (*reflect.StringHeader)(unsafe.Pointer(&a))
+	hb := (*reflect.StringHeader)(unsafe.Pointer(&b))
+	return ha.Data == hb.Data && ha.Len == hb.Len
+}
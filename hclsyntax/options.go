@@ -0,0 +1,384 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"context"
+)
+
+// ParseOption is an optional argument to the parsing and lexing functions in
+// this package, which modifies their default behavior.
+type ParseOption interface {
+	applyParseOption(*parseOpts)
+}
+
+type parseOpts struct {
+	replaceInvalidUTF8  bool
+	interner            *Interner
+	foldNegativeNumbers bool
+	rawStrings          bool
+	lineContinuation    bool
+	multiArgBlocks      bool
+	discardComments     bool
+	whitespaceTokens    bool
+	textTemplate        *optTextTemplate
+	tabWidth            int
+	columnUnit          ColumnUnit
+	decodeUTF16         bool
+	maxNestingDepth     int
+	maxTokenLength      int
+	maxFileSize         int
+	translator          Translator
+	ctx                 context.Context
+}
+
+func newParseOpts(opts []ParseOption) parseOpts {
+	var ret parseOpts
+	for _, opt := range opts {
+		opt.applyParseOption(&ret)
+	}
+	return ret
+}
+
+// nestingLimit returns the maximum nesting depth for the parser, taking
+// into account the default.
+func (o parseOpts) nestingLimit() int {
+	if o.maxNestingDepth > 0 {
+		return o.maxNestingDepth
+	}
+	return maxNestingDepth
+}
+
+// fileTooLarge returns true if the given input exceeds the maximum file
+// size, if any.
+func (o parseOpts) fileTooLarge(src []byte) bool {
+	return o.maxFileSize > 0 && len(src) > o.maxFileSize
+}
+
+type optReplaceInvalidUTF8 struct{}
+
+// OptReplaceInvalidUTF8 returns a ParseOption that causes byte sequences that
+// are not valid UTF-8 to be tolerated rather than treated as errors, for use
+// by tools that must process legacy files with unknown encoding and still
+// produce a syntax tree.
+//
+// Invalid bytes within string literals, heredocs, and templates are replaced
+// with the Unicode replacement character U+FFFD, while invalid bytes
+// elsewhere are ignored. In either case a warning diagnostic is returned
+// instead of an error. The source ranges of the affected tokens still refer
+// to the original invalid bytes.
+func OptReplaceInvalidUTF8() ParseOption {
+	return optReplaceInvalidUTF8{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optReplaceInvalidUTF8) applyParseOption(opts *parseOpts) {
+	opts.replaceInvalidUTF8 = true
+}
+
+type optInternTokens struct {
+	interner *Interner
+}
+
+// OptInternTokens returns a ParseOption that causes the text of identifiers
+// and block labels in the resulting syntax tree to be interned in the given
+// table, so that the memory for repeated names is shared not only within a
+// single file but across all files parsed with the same Interner.
+//
+// This is worthwhile for applications that parse and retain many files at
+// once. When parsing a single file the parser already shares the strings for
+// repeated identifiers within that file. This option has no effect on the
+// Lex functions, whose tokens always refer directly to the source buffer.
+func OptInternTokens(interner *Interner) ParseOption {
+	return optInternTokens{interner}
+}
+
+// applyParseOption implements ParseOption.
+func (o optInternTokens) applyParseOption(opts *parseOpts) {
+	opts.interner = o.interner
+}
+
+type optFoldNegativeNumbers struct{}
+
+// OptFoldNegativeNumbers returns a ParseOption that causes a minus sign
+// immediately followed by a number literal, such as -42, to be scanned as a
+// single TokenNumberLit token whose bytes include the sign, rather than as a
+// TokenMinus token followed by a TokenNumberLit token.
+//
+// The sign is folded only where the minus appears in a position where a
+// binary subtraction operator is not possible, so that a-1 is still a
+// subtraction. Consequently, the parser produces a LiteralValueExpr with a
+// negative value for such numbers, rather than a UnaryOpExpr wrapping a
+// positive literal.
+func OptFoldNegativeNumbers() ParseOption {
+	return optFoldNegativeNumbers{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optFoldNegativeNumbers) applyParseOption(opts *parseOpts) {
+	opts.foldNegativeNumbers = true
+}
+
+type optRawStrings struct{}
+
+// OptRawStrings returns a ParseOption that enables raw string literals,
+// delimited either by single quotes or by backticks, in which backslashes
+// and template sequences like ${ are not interpreted. This is convenient for
+// values such as regular expressions and Windows paths, which would
+// otherwise need many escapes.
+//
+//	pattern = '^\d+\.\d+$'
+//	path    = `C:\Program Files\${app}`
+//
+// A raw string delimited by single quotes must end on the same line, while
+// one delimited by backticks may span multiple lines. There is no way to
+// include the delimiter character itself, so a string containing single
+// quotes must use backticks and vice-versa.
+//
+// Raw strings are not part of the HCL native syntax specification, so this
+// option should be used only by applications that have chosen to extend
+// their configuration language in this way. Each raw string is scanned as a
+// single TokenRawStringLit token, whose bytes include its delimiters, and is
+// parsed as a TemplateExpr with a single literal part.
+func OptRawStrings() ParseOption {
+	return optRawStrings{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optRawStrings) applyParseOption(opts *parseOpts) {
+	opts.rawStrings = true
+}
+
+type optLineContinuation struct{}
+
+// OptLineContinuation returns a ParseOption that allows a long quoted string
+// to be split over several lines by ending each line but the last with a
+// backslash:
+//
+//	description = "This is a long description that is easier to \
+//	               read when wrapped over several lines."
+//
+// The backslash, the newline, and any spaces, tabs and further newlines that
+// follow are all removed from the string, so the example above produces a
+// single line of text with one space between "to" and "read". To include a
+// line break in the result, use the "\n" escape sequence before the
+// backslash.
+//
+// Without this option a newline inside a quoted string is an error, and the
+// heredoc syntax must be used for multi-line strings. Continuations are not
+// part of the HCL native syntax specification, so this option should be used
+// only by applications that have chosen to extend their configuration
+// language in this way.
+func OptLineContinuation() ParseOption {
+	return optLineContinuation{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optLineContinuation) applyParseOption(opts *parseOpts) {
+	opts.lineContinuation = true
+}
+
+type optMultiArgumentBlocks struct{}
+
+// OptMultiArgumentBlocks returns a ParseOption that allows a single-line
+// block to define more than one argument, separated by commas:
+//
+//	limits { cpu = 1, mem = 2 }
+//
+// Without this option a single-line block may define only one argument, and
+// a comma after it is an error. Multi-argument single-line blocks are not
+// part of the HCL native syntax specification, so this option should be used
+// only by applications that have chosen to extend their configuration
+// language in this way.
+func OptMultiArgumentBlocks() ParseOption {
+	return optMultiArgumentBlocks{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optMultiArgumentBlocks) applyParseOption(opts *parseOpts) {
+	opts.multiArgBlocks = true
+}
+
+type optDiscardComments struct{}
+
+// OptDiscardComments returns a ParseOption that causes the Lex functions to
+// omit comment tokens from their results, for callers that are interested
+// only in the tokens that affect the meaning of the source.
+//
+// A comment introduced by # or // includes the newline that ends it, so in
+// that case a TokenNewline token for just the newline is returned in place
+// of the comment. The Parse functions already ignore comments, so this
+// option does not change the resulting syntax tree.
+func OptDiscardComments() ParseOption {
+	return optDiscardComments{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optDiscardComments) applyParseOption(opts *parseOpts) {
+	opts.discardComments = true
+}
+
+type optWhitespaceTokens struct{}
+
+// OptWhitespaceTokens returns a ParseOption that causes the Lex functions to
+// return a TokenWhitespace token for each run of spaces and tabs between
+// other tokens, and for any byte order mark at the start of the input,
+// rather than skipping over them. Concatenating the bytes of the resulting
+// tokens then reproduces the source exactly, which is useful for tools such
+// as formatters and syntax highlighters that must preserve every character.
+//
+// Whitespace inside string literals and heredocs is already part of the
+// literal tokens, and newlines are already returned as TokenNewline, so
+// those are unaffected. If this is combined with OptDiscardComments then
+// the comments are removed after the whitespace tokens are added, and so
+// the result is no longer an exact copy of the source. The Parse functions
+// ignore whitespace tokens, so this option does not change the resulting
+// syntax tree.
+func OptWhitespaceTokens() ParseOption {
+	return optWhitespaceTokens{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optWhitespaceTokens) applyParseOption(opts *parseOpts) {
+	opts.whitespaceTokens = true
+}
+
+type optTabWidth struct {
+	width int
+}
+
+// OptTabWidth returns a ParseOption that causes each tab character to
+// advance the column numbers of the source positions that follow it to the
+// next multiple of the given width, plus one, in the same way that text
+// editors display tabs. Without this option, or with a width less than one,
+// a tab counts as a single column like any other character.
+//
+// This affects only the Column field of each position. Byte offsets and
+// line numbers are unchanged.
+func OptTabWidth(width int) ParseOption {
+	return optTabWidth{width}
+}
+
+// applyParseOption implements ParseOption.
+func (o optTabWidth) applyParseOption(opts *parseOpts) {
+	opts.tabWidth = o.width
+}
+
+type optColumnUnit struct {
+	unit ColumnUnit
+}
+
+// OptColumnUnit returns a ParseOption that selects the unit in which the
+// Column field of source positions is measured, so that positions can match
+// those used by a particular text editor or protocol. The default is
+// ColumnGraphemes.
+//
+// This can be combined with OptTabWidth, in which case a tab advances to the
+// next tab stop and other characters are measured in the given unit.
+func OptColumnUnit(unit ColumnUnit) ParseOption {
+	return optColumnUnit{unit}
+}
+
+// applyParseOption implements ParseOption.
+func (o optColumnUnit) applyParseOption(opts *parseOpts) {
+	opts.columnUnit = o.unit
+}
+
+type optDecodeUTF16 struct{}
+
+// OptDecodeUTF16 returns a ParseOption that causes input that begins with a
+// UTF-16 byte order mark, as produced by some Windows text editors, to be
+// transcoded to UTF-8 before it is scanned. Without this option such input
+// is rejected with a single error diagnostic explaining the problem.
+//
+// Source positions then refer to the transcoded text rather than to the
+// original buffer, so ParseConfig returns the transcoded text as the Bytes
+// of the resulting file, for use in diagnostic snippets. Callers of the Lex
+// functions and the other Parse functions that need the source text must
+// transcode it themselves in the same way.
+//
+// A UTF-8 byte order mark is always skipped, with or without this option.
+func OptDecodeUTF16() ParseOption {
+	return optDecodeUTF16{}
+}
+
+// applyParseOption implements ParseOption.
+func (o optDecodeUTF16) applyParseOption(opts *parseOpts) {
+	opts.decodeUTF16 = true
+}
+
+type optMaxNestingDepth struct {
+	depth int
+}
+
+// OptMaxNestingDepth returns a ParseOption that sets the deepest the parser
+// will descend into nested blocks and expressions before giving up on the
+// input with a "Nesting too deep" error. Without this option, or with a
+// depth less than one, the limit is 1000, which is far deeper than any
+// real-world configuration.
+//
+// Applications that parse untrusted input may wish to choose a lower limit
+// to bound the work done on adversarial input.
+func OptMaxNestingDepth(depth int) ParseOption {
+	return optMaxNestingDepth{depth}
+}
+
+// applyParseOption implements ParseOption.
+func (o optMaxNestingDepth) applyParseOption(opts *parseOpts) {
+	opts.maxNestingDepth = o.depth
+}
+
+type optMaxTokenLength struct {
+	length int
+}
+
+// OptMaxTokenLength returns a ParseOption that causes input containing any
+// single token longer than the given number of bytes, such as an enormous
+// string literal or comment, to be rejected with a "Token too long" error.
+// Scanning stops at the first such token.
+// Without this option, or with a length less than one, tokens may be of any
+// length.
+//
+// When the limit is exceeded the Lex functions return only an EOF token,
+// and so the Parse functions return an empty result.
+func OptMaxTokenLength(length int) ParseOption {
+	return optMaxTokenLength{length}
+}
+
+// applyParseOption implements ParseOption.
+func (o optMaxTokenLength) applyParseOption(opts *parseOpts) {
+	opts.maxTokenLength = o.length
+}
+
+type optMaxFileSize struct {
+	size int
+}
+
+// OptMaxFileSize returns a ParseOption that causes input longer than the
+// given number of bytes to be rejected with a "File too large" error before
+// any of it is transcoded or scanned. Without this option, or with a size less than one,
+// input may be of any size.
+//
+// When the limit is exceeded the Lex functions return only an EOF token,
+// and so the Parse functions return an empty result.
+func OptMaxFileSize(size int) ParseOption {
+	return optMaxFileSize{size}
+}
+
+// applyParseOption implements ParseOption.
+func (o optMaxFileSize) applyParseOption(opts *parseOpts) {
+	opts.maxFileSize = o.size
+}
+
+// optContext is used by the WithContext variants of the Parse functions,
+// rather than being exported, so that the context is always passed as the
+// first argument in the usual way.
+type optContext struct {
+	ctx context.Context
+}
+
+// applyParseOption implements ParseOption.
+func (o optContext) applyParseOption(opts *parseOpts) {
+	opts.ctx = o.ctx
+}
@@ -5,8 +5,10 @@ package hclsyntax
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/apparentlymart/go-textseg/v15/textseg"
@@ -22,11 +24,65 @@ type parser struct {
 	// in recovery mode, assuming that the recovery heuristics have failed
 	// in this case and left the peeker in a wrong place.
 	recovery bool
+
+	// multiArgBlocks allows single-line blocks to contain more than one
+	// argument, separated by commas, as set by OptMultiArgumentBlocks.
+	multiArgBlocks bool
+
+	// depth is the number of nested bodies and expression terms the parser
+	// is currently inside, used to bail out of pathologically-deep input
+	// before it exhausts the stack. maxDepth is the limit, as set by
+	// OptMaxNestingDepth.
+	depth    int
+	maxDepth int
+
+	// ctx, if set, is checked periodically as the parser descends into
+	// nested constructs so that a long parse can be abandoned. checks
+	// counts the descents so that the check need not be made every time,
+	// and canceled records that the parser has already given up.
+	ctx      context.Context
+	checks   int
+	canceled bool
+
+	// idents interns the names of identifiers seen so far, so that
+	// repeated uses of the same name share a single string. It is
+	// populated lazily by identName, and is not used if the caller
+	// provided its own interner using OptInternTokens.
+	idents   map[string]string
+	interner *Interner
+}
+
+func newParser(peeker *peeker, opts parseOpts) *parser {
+	return &parser{
+		peeker:         peeker,
+		multiArgBlocks: opts.multiArgBlocks,
+		maxDepth:       opts.nestingLimit(),
+		ctx:            opts.ctx,
+		interner:       opts.interner,
+	}
 }
 
+// maxNestingDepth is the default for the deepest the parser will descend
+// into nested blocks and expressions before giving up on the input.
+// Real-world configuration is nowhere near this deep; the limit exists only
+// to keep adversarial input from crashing the calling program.
+const maxNestingDepth = 1000
+
 func (p *parser) ParseBody(end TokenType) (*Body, hcl.Diagnostics) {
+	if diags := p.enterNesting(); diags != nil {
+		rng := p.PrevRange()
+		return &Body{
+			Attributes: Attributes{},
+			Blocks:     Blocks{},
+			SrcRange:   rng,
+			EndRange:   rng,
+		}, diags
+	}
+	defer p.exitNesting()
+
 	attrs := Attributes{}
 	blocks := Blocks{}
+	var items []Node // all items in source order, for diagnostics
 	var diags hcl.Diagnostics
 
 	startRange := p.PrevRange()
@@ -51,7 +107,9 @@ Token:
 			switch titem := item.(type) {
 			case *Block:
 				blocks = append(blocks, titem)
+				items = append(items, titem)
 			case *Attribute:
+				items = append(items, titem)
 				if existing, exists := attrs[titem.Name]; exists {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
@@ -61,6 +119,7 @@ Token:
 							titem.Name, existing.NameRange.String(),
 						),
 						Subject: &titem.NameRange,
+						Extra:   hcl.NewDuplicateDiagExtra(existing.NameRange),
 					})
 				} else {
 					attrs[titem.Name] = titem
@@ -91,11 +150,13 @@ Token:
 					switch end {
 					case TokenCBrace:
 						// If we're looking for a closing brace then we're parsing a block
+						misplaced := p.misplacedBlockItem(startRange, items)
 						diags = append(diags, &hcl.Diagnostic{
 							Severity: hcl.DiagError,
 							Summary:  "Unclosed configuration block",
-							Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+							Detail:   unclosedBlockDetail(startRange, misplaced),
 							Subject:  &startRange,
+							Extra:    quickFixExtra(ErrUnclosedBlock, closeBlockEdit(bad.Range, misplaced)),
 						})
 					default:
 						// The only other "end" should itself be TokenEOF (for
@@ -138,6 +199,100 @@ Token:
 	}, diags
 }
 
+// missingEqualsDiagnostic returns the diagnostic for a body item whose
+// name is followed by something other than an equals sign or a block
+// header. Where the following token suggests that an argument was intended,
+// the diagnostic points at that token and says how to fix it.
+func missingEqualsDiagnostic(ident, next Token) *hcl.Diagnostic {
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Argument or block definition required",
+		Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
+		Subject:  ident.Range.Ptr(),
+	}
+
+	switch next.Type {
+	case TokenColon:
+		diag.Detail = fmt.Sprintf("An argument or block definition is required here. To set the argument %q, use the equals sign \"=\" instead of a colon.", ident.Bytes)
+		replacement := "="
+		if next.Range.Start.Byte == ident.Range.End.Byte {
+			replacement = " ="
+		}
+		diag.Extra = quickFixExtra(ErrMissingEquals, SuggestedEdit{Range: next.Range, Replacement: replacement})
+	case TokenNumberLit, TokenOBrack, TokenOHeredoc, TokenMinus, TokenBang:
+		diag.Detail = fmt.Sprintf("An argument or block definition is required here. To set the argument %q, add the equals sign \"=\" before its value.", ident.Bytes)
+		diag.Extra = quickFixExtra(ErrMissingEquals, insertAt(next.Range.Filename, next.Range.Start, "= "))
+	default:
+		return diag
+	}
+	diag.Subject = next.Range.Ptr()
+	diag.Context = hcl.RangeBetween(ident.Range, next.Range).Ptr()
+	return diag
+}
+
+// unclosedBlockDetail returns the detail message for a block whose opening
+// brace has no matching closing brace. If misplaced is not nil then it is
+// an item that seems from its indentation to belong after the block, and so
+// the message suggests that the closing brace is missing before it.
+func unclosedBlockDetail(oBrace hcl.Range, misplaced Node) string {
+	detail := fmt.Sprintf(
+		"There is no closing brace for the block opened at line %d before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+		oBrace.Start.Line,
+	)
+	switch item := misplaced.(type) {
+	case *Attribute:
+		detail += fmt.Sprintf(" Based on indentation, the closing brace may be missing before the argument %q at line %d.", item.Name, item.SrcRange.Start.Line)
+	case *Block:
+		detail += fmt.Sprintf(" Based on indentation, the closing brace may be missing before the %q block at line %d.", item.Type, item.TypeRange.Start.Line)
+	}
+	return detail
+}
+
+// closeBlockEdit returns an edit that inserts the missing closing brace of
+// a block, either at the end of the file or, if misplaced is not nil, before
+// the item that seems from its indentation to belong after the block.
+func closeBlockEdit(eof hcl.Range, misplaced Node) SuggestedEdit {
+	var pos hcl.Pos
+	switch item := misplaced.(type) {
+	case *Attribute:
+		pos = item.SrcRange.Start
+	case *Block:
+		pos = item.TypeRange.Start
+	default:
+		if eof.Start.Column > 1 {
+			return insertAt(eof.Filename, eof.Start, "\n}\n")
+		}
+		return insertAt(eof.Filename, eof.Start, "}\n")
+	}
+
+	// We insert the brace in front of the misplaced item, repeating its
+	// indentation so that the item keeps its position on its own line.
+	return insertAt(eof.Filename, pos, "}\n"+strings.Repeat(" ", pos.Column-1))
+}
+
+// misplacedBlockItem returns the first of the given items from the body of
+// a block that is indented no deeper than the line where the block was
+// opened, or nil if there is no such item.
+func (p *parser) misplacedBlockItem(oBrace hcl.Range, items []Node) Node {
+	headerColumn := 0
+	for _, tok := range p.Tokens {
+		if tok.Range.Start.Line == oBrace.Start.Line {
+			headerColumn = tok.Range.Start.Column
+			break
+		}
+	}
+	if headerColumn == 0 {
+		return nil
+	}
+	for _, item := range items {
+		start := item.Range().Start
+		if start.Line > oBrace.Start.Line && start.Column <= headerColumn {
+			return item
+		}
+	}
+	return nil
+}
+
 func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 	ident := p.Read()
 	if ident.Type != TokenIdent {
@@ -161,14 +316,7 @@ func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 		return p.finishParsingBodyBlock(ident)
 	default:
 		p.recoverAfterBodyItem()
-		return nil, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Argument or block definition required",
-				Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
-				Subject:  &ident.Range,
-			},
-		}
+		return nil, hcl.Diagnostics{missingEqualsDiagnostic(ident, next)}
 	}
 }
 
@@ -176,7 +324,70 @@ func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 // body of a nested block containing only one attribute value all on a single
 // line, like foo { bar = baz } . It expects to find a single attribute item
 // immediately followed by the end token type with no intervening newlines.
+//
+// If OptMultiArgumentBlocks is in effect then it instead accepts one or more
+// attribute items separated by commas, like foo { bar = baz, boop = beep },
+// with the last immediately followed by the end token type.
 func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
+	attrs := Attributes{}
+	var first, last *Attribute
+	var diags hcl.Diagnostics
+
+	for {
+		attr, attrDiags := p.parseSingleAttrBodyItem()
+		diags = append(diags, attrDiags...)
+		if attr == nil {
+			return nil, diags
+		}
+
+		if existing, exists := attrs[attr.Name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Attribute redefined",
+				Detail: fmt.Sprintf(
+					"The argument %q was already set at %s. Each argument may be set only once.",
+					attr.Name, existing.NameRange.String(),
+				),
+				Subject: &attr.NameRange,
+				Extra:   hcl.NewDuplicateDiagExtra(existing.NameRange),
+			})
+		} else {
+			attrs[attr.Name] = attr
+		}
+		if first == nil {
+			first = attr
+		}
+		last = attr
+
+		// A comma followed by another argument name continues the body.
+		// Any other comma is left for the caller to report.
+		if !p.multiArgBlocks || p.recovery || p.Peek().Type != TokenComma {
+			break
+		}
+		mark := p.Mark()
+		p.Read() // eat comma
+		if p.Peek().Type != TokenIdent {
+			p.Reset(mark)
+			break
+		}
+	}
+
+	return &Body{
+		Attributes: attrs,
+
+		SrcRange: hcl.RangeBetween(first.SrcRange, last.SrcRange),
+		EndRange: hcl.Range{
+			Filename: last.SrcRange.Filename,
+			Start:    last.SrcRange.End,
+			End:      last.SrcRange.End,
+		},
+	}, diags
+}
+
+// parseSingleAttrBodyItem parses one of the attribute definitions within
+// a single-line block body. If it returns a nil attribute then the parser
+// has already recovered past the end of the body item.
+func (p *parser) parseSingleAttrBodyItem() (*Attribute, hcl.Diagnostics) {
 	ident := p.Read()
 	if ident.Type != TokenIdent {
 		p.recoverAfterBodyItem()
@@ -190,51 +401,30 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 		}
 	}
 
-	var attr *Attribute
-	var diags hcl.Diagnostics
-
 	next := p.Peek()
 
 	switch next.Type {
 	case TokenEqual:
-		node, attrDiags := p.finishParsingBodyAttribute(ident, true)
-		diags = append(diags, attrDiags...)
-		attr = node.(*Attribute)
+		node, diags := p.finishParsingBodyAttribute(ident, true)
+		return node.(*Attribute), diags
 	case TokenOQuote, TokenOBrace, TokenIdent:
 		p.recoverAfterBodyItem()
+		only := "a single argument"
+		if p.multiArgBlocks {
+			only = "arguments"
+		}
 		return nil, hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument definition required",
-				Detail:   fmt.Sprintf("A single-line block definition can contain only a single argument. If you meant to define argument %q, use an equals sign to assign it a value. To define a nested block, place it on a line of its own within its parent block.", ident.Bytes),
+				Detail:   fmt.Sprintf("A single-line block definition can contain only %s. If you meant to define argument %q, use an equals sign to assign it a value. To define a nested block, place it on a line of its own within its parent block.", only, ident.Bytes),
 				Subject:  hcl.RangeBetween(ident.Range, next.Range).Ptr(),
 			},
 		}
 	default:
 		p.recoverAfterBodyItem()
-		return nil, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Argument or block definition required",
-				Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
-				Subject:  &ident.Range,
-			},
-		}
+		return nil, hcl.Diagnostics{missingEqualsDiagnostic(ident, next)}
 	}
-
-	return &Body{
-		Attributes: Attributes{
-			string(ident.Bytes): attr,
-		},
-
-		SrcRange: attr.SrcRange,
-		EndRange: hcl.Range{
-			Filename: attr.SrcRange.Filename,
-			Start:    attr.SrcRange.End,
-			End:      attr.SrcRange.End,
-		},
-	}, diags
-
 }
 
 func (p *parser) finishParsingBodyAttribute(ident Token, singleLine bool) (Node, hcl.Diagnostics) {
@@ -285,7 +475,7 @@ func (p *parser) finishParsingBodyAttribute(ident Token, singleLine bool) (Node,
 	}
 
 	return &Attribute{
-		Name: string(ident.Bytes),
+		Name: p.identName(ident),
 		Expr: expr,
 
 		SrcRange:    hcl.RangeBetween(ident.Range, endRange),
@@ -295,7 +485,7 @@ func (p *parser) finishParsingBodyAttribute(ident Token, singleLine bool) (Node,
 }
 
 func (p *parser) finishParsingBodyBlock(ident Token) (Node, hcl.Diagnostics) {
-	var blockType = string(ident.Bytes)
+	var blockType = p.identName(ident)
 	var diags hcl.Diagnostics
 	var labels []string
 	var labelRanges []hcl.Range
@@ -315,6 +505,9 @@ Token:
 		case TokenOQuote:
 			label, labelRange, labelDiags := p.parseQuotedStringLiteral()
 			diags = append(diags, labelDiags...)
+			if p.interner != nil {
+				label = p.interner.InternString(label)
+			}
 			labels = append(labels, label)
 			labelRanges = append(labelRanges, labelRange)
 			// parseQuoteStringLiteral recovers up to the closing quote
@@ -323,7 +516,7 @@ Token:
 
 		case TokenIdent:
 			tok = p.Read() // eat token
-			label, labelRange := string(tok.Bytes), tok.Range
+			label, labelRange := p.identName(tok), tok.Range
 			labels = append(labels, label)
 			labelRanges = append(labelRanges, labelRange)
 
@@ -383,20 +576,32 @@ Token:
 	case TokenNewline, TokenEOF, TokenCBrace:
 		body, bodyDiags = p.ParseBody(TokenCBrace)
 	default:
-		// Special one-line, single-attribute block parsing mode.
+		// Special one-line block parsing mode, for arguments only.
 		body, bodyDiags = p.parseSingleAttrBody(TokenCBrace)
 		switch p.Peek().Type {
 		case TokenCBrace:
 			p.Read() // the happy path - just consume the closing brace
 		case TokenComma:
-			// User seems to be trying to use the object-constructor
-			// comma-separated style, which isn't permitted for blocks.
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Invalid single-argument block definition",
-				Detail:   "Single-line block syntax can include only one argument definition. To define multiple arguments, use the multi-line block syntax with one argument definition per line.",
-				Subject:  p.Peek().Range.Ptr(),
-			})
+			if p.multiArgBlocks {
+				// The single-line body parser consumes commas that separate
+				// arguments, so a comma here isn't followed by another
+				// argument.
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid single-line block definition",
+					Detail:   "In single-line block syntax, each comma must be followed by another argument definition. To define nested blocks, use the multi-line block syntax.",
+					Subject:  p.Peek().Range.Ptr(),
+				})
+			} else {
+				// User seems to be trying to use the object-constructor
+				// comma-separated style, which isn't permitted for blocks.
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid single-argument block definition",
+					Detail:   "Single-line block syntax can include only one argument definition. To define multiple arguments, use the multi-line block syntax with one argument definition per line.",
+					Subject:  p.Peek().Range.Ptr(),
+				})
+			}
 			p.recover(TokenCBrace)
 		case TokenNewline:
 			// We don't allow weird mixtures of single and multi-line syntax.
@@ -417,15 +622,19 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Unclosed configuration block",
-						Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+						Detail:   unclosedBlockDetail(oBrace.Range, nil),
 						Subject:  oBrace.Range.Ptr(),
 						Context:  hcl.RangeBetween(ident.Range, oBrace.Range).Ptr(),
 					})
 				default:
+					which := "single"
+					if p.multiArgBlocks {
+						which = "last"
+					}
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid single-argument block definition",
-						Detail:   "A single-line block definition must end with a closing brace immediately after its single argument definition.",
+						Detail:   fmt.Sprintf("A single-line block definition must end with a closing brace immediately after its %s argument definition.", which),
 						Subject:  p.Peek().Range.Ptr(),
 					})
 				}
@@ -505,6 +714,13 @@ func (p *parser) parseTernaryConditional() (Expression, hcl.Diagnostics) {
 
 	p.Read() // eat question mark
 
+	// The result expressions are parsed recursively, so a chain of
+	// conditionals nests just as parentheses do.
+	if nestDiags := p.enterNesting(); nestDiags != nil {
+		return condExpr, append(diags, nestDiags...)
+	}
+	defer p.exitNesting()
+
 	trueExpr, trueDiags := p.ParseExpression()
 	diags = append(diags, trueDiags...)
 	if p.recovery && trueDiags.HasErrors() {
@@ -639,7 +855,7 @@ Traversal:
 			switch attrTok.Type {
 			case TokenIdent:
 				attrTok = p.Read() // eat token
-				name := string(attrTok.Bytes)
+				name := p.identName(attrTok)
 				rng := hcl.RangeBetween(dot.Range, attrTok.Range)
 				step := hcl.TraverseAttr{
 					Name:     name,
@@ -771,7 +987,7 @@ Traversal:
 
 					attrTok := p.Read()
 					trav = append(trav, hcl.TraverseAttr{
-						Name:     string(attrTok.Bytes),
+						Name:     p.identName(attrTok),
 						SrcRange: hcl.RangeBetween(dot.Range, attrTok.Range),
 					})
 					lastRange = attrTok.Range
@@ -949,6 +1165,14 @@ func makeRelativeTraversal(expr Expression, next hcl.Traverser, rng hcl.Range) E
 }
 
 func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
+	if diags := p.enterNesting(); diags != nil {
+		return &LiteralValueExpr{
+			Val:      cty.DynamicVal,
+			SrcRange: p.PrevRange(),
+		}, diags
+	}
+	defer p.exitNesting()
+
 	start := p.Peek()
 
 	switch start.Type {
@@ -1010,34 +1234,41 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 			return p.finishParsingFunctionCall(tok)
 		}
 
-		name := string(tok.Bytes)
-		switch name {
-		case "true":
-			return &LiteralValueExpr{
-				Val:      cty.True,
-				SrcRange: tok.Range,
-			}, nil
-		case "false":
+		name := p.identName(tok)
+		if val, ok := LookupKeyword(name); ok {
 			return &LiteralValueExpr{
-				Val:      cty.False,
+				Val:      val,
 				SrcRange: tok.Range,
 			}, nil
-		case "null":
-			return &LiteralValueExpr{
-				Val:      cty.NullVal(cty.DynamicPseudoType),
-				SrcRange: tok.Range,
-			}, nil
-		default:
-			return &ScopeTraversalExpr{
-				Traversal: hcl.Traversal{
-					hcl.TraverseRoot{
-						Name:     name,
-						SrcRange: tok.Range,
-					},
+		}
+		return &ScopeTraversalExpr{
+			Traversal: hcl.Traversal{
+				hcl.TraverseRoot{
+					Name:     name,
+					SrcRange: tok.Range,
 				},
-				SrcRange: tok.Range,
-			}, nil
+			},
+			SrcRange: tok.Range,
+		}, nil
+
+	case TokenRawStringLit:
+		tok := p.Read()
+		litRange := tok.Range
+		litRange.Start.Byte++
+		litRange.Start.Column++
+		if content := rawStringContent(tok); len(content) == len(tok.Bytes)-2 {
+			litRange.End.Byte--
+			litRange.End.Column--
 		}
+		return &TemplateExpr{
+			Parts: []Expression{
+				&LiteralValueExpr{
+					Val:      cty.StringVal(string(rawStringContent(tok))),
+					SrcRange: litRange,
+				},
+			},
+			SrcRange: tok.Range,
+		}, nil
 
 	case TokenOQuote, TokenOHeredoc:
 		open := p.Read() // eat opening marker
@@ -1144,6 +1375,7 @@ func (p *parser) numberLitValue(tok Token) (cty.Value, hcl.Diagnostics) {
 				// gives us "a number is required", so not much help either.
 				Detail:  "Failed to recognize the value of this number literal.",
 				Subject: &tok.Range,
+				Extra:   errorCodeExtra(ErrInvalidNumber),
 			},
 		}
 	}
@@ -1163,7 +1395,7 @@ func (p *parser) finishParsingFunctionCall(name Token) (Expression, hcl.Diagnost
 		panic("finishParsingFunctionCall called with unsupported next token")
 	}
 
-	nameStr := string(name.Bytes)
+	nameStr := p.identName(name)
 	nameEndPos := name.Range.End
 	for openTok.Type == TokenDoubleColon {
 		nextName := p.Read()
@@ -1872,9 +2104,10 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unterminated string literal",
-				Detail:   "Unable to find the closing quote mark before the end of the file.",
+				Detail:   fmt.Sprintf("Unable to find the closing quote mark for the string that begins at line %d, column %d before the end of the file.", oQuote.Range.Start.Line, oQuote.Range.Start.Column),
 				Subject:  &tok.Range,
 				Context:  hcl.RangeBetween(oQuote.Range, tok.Range).Ptr(),
+				Extra:    quickFixExtra(ErrUnterminatedString, insertAt(tok.Range.Filename, tok.Range.Start, `"`)),
 			})
 			endRange = tok.Range
 			break Token
@@ -1965,6 +2198,7 @@ Slices:
 					Summary:  "Invalid escape sequence",
 					Detail:   "Backslash must be followed by an escape sequence selector character.",
 					Subject:  rng.Ptr(),
+					Extra:    errorCodeExtra(ErrInvalidEscape),
 				})
 				break TokenType
 			}
@@ -1993,6 +2227,7 @@ Slices:
 						Summary:  "Invalid escape sequence",
 						Detail:   "The \\u escape sequence must be followed by four hexadecimal digits.",
 						Subject:  rng.Ptr(),
+						Extra:    errorCodeExtra(ErrInvalidEscape),
 					})
 					break TokenType
 				} else if slice[1] == 'U' && len(slice) != 10 {
@@ -2001,6 +2236,7 @@ Slices:
 						Summary:  "Invalid escape sequence",
 						Detail:   "The \\U escape sequence must be followed by eight hexadecimal digits.",
 						Subject:  rng.Ptr(),
+						Extra:    errorCodeExtra(ErrInvalidEscape),
 					})
 					break TokenType
 				}
@@ -2021,6 +2257,7 @@ Slices:
 						Summary:  "Invalid escape sequence",
 						Detail:   fmt.Sprintf("Cannot encode character U+%04x in UTF-8.", num),
 						Subject:  rng.Ptr(),
+						Extra:    errorCodeExtra(ErrInvalidEscape),
 					})
 					break TokenType
 				}
@@ -2038,6 +2275,7 @@ Slices:
 					Summary:  "Invalid escape sequence",
 					Detail:   fmt.Sprintf("The symbol %q is not a valid escape sequence selector.", slice[1:]),
 					Subject:  rng.Ptr(),
+					Extra:    errorCodeExtra(ErrInvalidEscape),
 				})
 				ret = append(ret, slice[1:]...)
 				continue Slices
@@ -2073,6 +2311,139 @@ func (p *parser) setRecovery() {
 	p.recovery = true
 }
 
+// identName returns the name of the given identifier token as a string.
+//
+// Identifiers tend to be heavily repeated within a file, so the strings are
+// interned for the lifetime of the parser to avoid allocating a new copy for
+// each occurrence, or in the caller's Interner if one was provided.
+func (p *parser) identName(tok Token) string {
+	if p.interner != nil {
+		return p.interner.Intern(tok.Bytes)
+	}
+	if name, ok := p.idents[string(tok.Bytes)]; ok {
+		return name
+	}
+	if p.idents == nil {
+		p.idents = make(map[string]string)
+	}
+	name := string(tok.Bytes)
+	p.idents[name] = name
+	return name
+}
+
+// enterNesting records that the parser is descending into a nested
+// construct, returning diagnostics if doing so would exceed
+// the parser's maximum depth. Each successful call must be balanced by a
+// call to exitNesting.
+//
+// When the limit is exceeded the rest of the input is skipped and the
+// parser is put into recovery mode, so that the enclosing constructs can
+// unwind immediately without each attempting its own recovery scan.
+func (p *parser) enterNesting() hcl.Diagnostics {
+	if diags := p.checkCanceled(); diags != nil {
+		return diags
+	}
+	if p.depth >= p.maxDepth {
+		rng := p.NextRange()
+		p.NextIndex = len(p.Tokens) - 1 // the final token is always EOF
+		p.setRecovery()
+		return hcl.Diagnostics{nestingTooDeepDiagnostic(rng, p.maxDepth)}
+	}
+	p.depth++
+	return nil
+}
+
+// contextCheckInterval is the number of calls to enterNesting between
+// checks of whether the parser's context has been canceled.
+const contextCheckInterval = 256
+
+// checkCanceled returns diagnostics if the parser's context has been
+// canceled, in which case the rest of the input is skipped in the same way
+// as for input that is nested too deeply. Once the parser has given up it
+// returns empty but non-nil diagnostics, so that the cancellation is
+// reported only once.
+func (p *parser) checkCanceled() hcl.Diagnostics {
+	if p.canceled {
+		return hcl.Diagnostics{}
+	}
+	if p.ctx == nil {
+		return nil
+	}
+	p.checks++
+	if p.checks%contextCheckInterval != 0 {
+		return nil
+	}
+	if err := p.ctx.Err(); err != nil {
+		rng := p.NextRange()
+		p.NextIndex = len(p.Tokens) - 1 // the final token is always EOF
+		p.setRecovery()
+		p.canceled = true
+		return hcl.Diagnostics{canceledDiagnostic(rng, err)}
+	}
+	return nil
+}
+
+// canceledDiagnostic returns the error for parsing that was abandoned at
+// the given range because of the given context error.
+func canceledDiagnostic(rng hcl.Range, err error) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Parsing canceled",
+		Detail:   fmt.Sprintf("Parsing stopped before the end of the input: %s.", err),
+		Subject:  &rng,
+
+		// The context error is retained so that callers can recognize it
+		// using errors.Is, as with other context-aware functions.
+		Extra: err,
+	}
+}
+
+// nestingTooDeepDiagnostic returns the error for a construct at the given
+// range that would exceed the given maximum nesting depth.
+func nestingTooDeepDiagnostic(rng hcl.Range, limit int) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Nesting too deep",
+		Detail:   fmt.Sprintf("This construct is nested more than %d levels deep, which exceeds the maximum nesting depth supported by the parser.", limit),
+		Subject:  &rng,
+	}
+}
+
+// exitNesting records that the parser has finished with a nested construct
+// previously entered with enterNesting.
+func (p *parser) exitNesting() {
+	p.depth--
+}
+
+// parserMark is a checkpoint of the state of a parser, returned by Mark and
+// accepted by Reset.
+type parserMark struct {
+	peeker   peekerMark
+	recovery bool
+}
+
+// Mark returns a checkpoint of the parser's current state, for use with
+// Reset when speculatively parsing a production that may need to be
+// abandoned.
+//
+// This shadows the peeker method of the same name so that the recovery
+// flag is also captured, since a failed speculative parse will often have
+// enabled recovery mode.
+func (p *parser) Mark() parserMark {
+	return parserMark{
+		peeker:   p.peeker.Mark(),
+		recovery: p.recovery,
+	}
+}
+
+// Reset rolls the parser back to a checkpoint previously returned by Mark.
+// Any diagnostics produced since the mark was created are the caller's
+// responsibility to discard.
+func (p *parser) Reset(mark parserMark) {
+	p.peeker.Reset(mark.peeker)
+	p.recovery = mark.recovery
+}
+
 // recover seeks forward in the token stream until it finds TokenType "end",
 // then returns with the peeker pointed at the following token.
 //
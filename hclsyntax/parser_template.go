@@ -647,9 +647,10 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unterminated template string",
-					Detail:   "No closing marker was found for the string.",
+					Detail:   fmt.Sprintf("No closing marker was found for the string that begins at line %d, column %d.", startRange.Start.Line, startRange.Start.Column),
 					Subject:  &next.Range,
 					Context:  hcl.RangeBetween(startRange, next.Range).Ptr(),
+					Extra:    errorCodeExtra(ErrUnterminatedTemplate),
 				})
 			}
 			final := p.recover(end)
@@ -5,6 +5,7 @@ package hclsyntax
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 
@@ -2567,7 +2568,7 @@ block "valid" {}
 						Name: "a",
 						Expr: &ExprSyntaxError{
 							Placeholder: cty.DynamicVal,
-							ParseDiags: hcl.Diagnostics{
+							ParseDiags: hcl.MarkSyntaxErrors(hcl.Diagnostics{
 								{
 									Severity: hcl.DiagError,
 									Summary:  "Missing open parenthesis",
@@ -2581,7 +2582,7 @@ block "valid" {}
 										End:   hcl.Pos{Line: 2, Column: 1, Byte: 24},
 									},
 								},
-							},
+							}),
 							SrcRange: hcl.Range{
 								Start: hcl.Pos{Line: 1, Column: 5, Byte: 4},
 								End:   hcl.Pos{Line: 2, Column: 1, Byte: 24},
@@ -2624,7 +2625,7 @@ block "valid" {}
 						Name: "a",
 						Expr: &ExprSyntaxError{
 							Placeholder: cty.DynamicVal,
-							ParseDiags: hcl.Diagnostics{
+							ParseDiags: hcl.MarkSyntaxErrors(hcl.Diagnostics{
 								{
 									Severity: hcl.DiagError,
 									Summary:  "Missing function name",
@@ -2638,7 +2639,7 @@ block "valid" {}
 										End:   hcl.Pos{Line: 2, Column: 1, Byte: 14},
 									},
 								},
-							},
+							}),
 							SrcRange: hcl.Range{
 								Start: hcl.Pos{Line: 1, Column: 5, Byte: 4},
 								End:   hcl.Pos{Line: 2, Column: 1, Byte: 14},
@@ -2705,7 +2706,7 @@ block "valid" {}
 											Start: hcl.Pos{Line: 1, Column: 11, Byte: 10},
 											End:   hcl.Pos{Line: 1, Column: 13, Byte: 12},
 										},
-										ParseDiags: hcl.Diagnostics{
+										ParseDiags: hcl.MarkSyntaxErrors(hcl.Diagnostics{
 											{
 												Severity: hcl.DiagError,
 												Summary:  "Invalid attribute name",
@@ -2715,7 +2716,7 @@ block "valid" {}
 													End:   hcl.Pos{Line: 1, Column: 15, Byte: 14},
 												},
 											},
-										},
+										}),
 									},
 								},
 							},
@@ -3853,12 +3854,20 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Unclosed configuration block",
-					Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+					Detail:   "There is no closing brace for the block opened at line 1 before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
 					Subject: &hcl.Range{
 						Filename: "test.hcl",
 						Start:    hcl.Pos{Line: 1, Column: 6, Byte: 5},
 						End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
 					},
+					Extra: quickFixExtra(ErrUnclosedBlock, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 2, Column: 1, Byte: 7},
+							End:      hcl.Pos{Line: 2, Column: 1, Byte: 7},
+						},
+						Replacement: "}\n",
+					}),
 				},
 			},
 		},
@@ -3868,12 +3877,20 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Unclosed configuration block",
-					Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+					Detail:   "There is no closing brace for the block opened at line 1 before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
 					Subject: &hcl.Range{
 						Filename: "test.hcl",
 						Start:    hcl.Pos{Line: 1, Column: 6, Byte: 5},
 						End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
 					},
+					Extra: quickFixExtra(ErrUnclosedBlock, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 3, Column: 1, Byte: 15},
+							End:      hcl.Pos{Line: 3, Column: 1, Byte: 15},
+						},
+						Replacement: "}\n",
+					}),
 				},
 			},
 		},
@@ -3883,12 +3900,20 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Unclosed configuration block",
-					Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+					Detail:   "There is no closing brace for the block opened at line 1 before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
 					Subject: &hcl.Range{
 						Filename: "test.hcl",
 						Start:    hcl.Pos{Line: 1, Column: 6, Byte: 5},
 						End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
 					},
+					Extra: quickFixExtra(ErrUnclosedBlock, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 1, Column: 7, Byte: 6},
+							End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+						},
+						Replacement: "\n}\n",
+					}),
 				},
 			},
 		},
@@ -3898,7 +3923,7 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Unclosed configuration block",
-					Detail:   "There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+					Detail:   "There is no closing brace for the block opened at line 1 before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
 					Subject: &hcl.Range{
 						Filename: "test.hcl",
 						Start:    hcl.Pos{Line: 1, Column: 6, Byte: 5},
@@ -3912,6 +3937,21 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				},
 			},
 		},
+		"single-line block with multiple arguments": {
+			"blah { a = 1, b = 2 }\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid single-argument block definition",
+					Detail:   "Single-line block syntax can include only one argument definition. To define multiple arguments, use the multi-line block syntax with one argument definition per line.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 13, Byte: 12},
+						End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+					},
+				},
+			},
+		},
 		"unclosed object constructor (before element separator)": {
 			`foo = { a = 1`,
 			hcl.Diagnostics{
@@ -4028,7 +4068,7 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				{
 					Severity: hcl.DiagError,
 					Summary:  "Unterminated template string",
-					Detail:   "No closing marker was found for the string.",
+					Detail:   "No closing marker was found for the string that begins at line 1, column 8.",
 					Subject: &hcl.Range{
 						Filename: "test.hcl",
 						Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
@@ -4039,6 +4079,7 @@ func TestParseConfigDiagnostics(t *testing.T) {
 						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
 						End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
 					},
+					Extra: errorCodeExtra(ErrUnterminatedTemplate),
 				},
 			},
 		},
@@ -4091,6 +4132,85 @@ func TestParseConfigDiagnostics(t *testing.T) {
 				},
 			},
 		},
+		"unclosed multi-line block (before a less-indented block)": {
+			"outer {\n  a = 1\nnext {\n}\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unclosed configuration block",
+					Detail:   "There is no closing brace for the block opened at line 1 before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file. Based on indentation, the closing brace may be missing before the \"next\" block at line 3.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 7, Byte: 6},
+						End:      hcl.Pos{Line: 1, Column: 8, Byte: 7},
+					},
+					Extra: quickFixExtra(ErrUnclosedBlock, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 3, Column: 1, Byte: 16},
+							End:      hcl.Pos{Line: 3, Column: 1, Byte: 16},
+						},
+						Replacement: "}\n",
+					}),
+				},
+			},
+		},
+		"argument with colon instead of equals": {
+			"foo: 1\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Argument or block definition required",
+					Detail:   "An argument or block definition is required here. To set the argument \"foo\", use the equals sign \"=\" instead of a colon.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 4, Byte: 3},
+						End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+					},
+					Context: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+					},
+					Extra: quickFixExtra(ErrMissingEquals, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 1, Column: 4, Byte: 3},
+							End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+						},
+						Replacement: " =",
+					}),
+				},
+			},
+		},
+		"argument without equals": {
+			"foo [1]\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Argument or block definition required",
+					Detail:   "An argument or block definition is required here. To set the argument \"foo\", add the equals sign \"=\" before its value.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 5, Byte: 4},
+						End:      hcl.Pos{Line: 1, Column: 6, Byte: 5},
+					},
+					Context: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 6, Byte: 5},
+					},
+					Extra: quickFixExtra(ErrMissingEquals, SuggestedEdit{
+						Range: hcl.Range{
+							Filename: "test.hcl",
+							Start:    hcl.Pos{Line: 1, Column: 5, Byte: 4},
+							End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+						},
+						Replacement: "= ",
+					}),
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -4098,9 +4218,226 @@ func TestParseConfigDiagnostics(t *testing.T) {
 			t.Logf("\n%s", test.input)
 			_, diags := ParseConfig([]byte(test.input), "test.hcl", hcl.InitialPos)
 
-			if diff := cmp.Diff(test.want, diags); diff != "" {
+			// All of these diagnostics describe syntax errors.
+			want := markSyntaxErrors(test.want)
+			if diff := cmp.Diff(want, diags); diff != "" {
 				t.Errorf("wrong diagnostics\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestParseConfigMultiArgumentBlocks(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		f, diags := ParseConfig([]byte("blah { a = 1, b = 2 }\n"), "test.hcl", hcl.InitialPos, OptMultiArgumentBlocks())
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics\n%s", diags.Error())
+		}
+		blocks := f.Body.(*Body).Blocks
+		if len(blocks) != 1 {
+			t.Fatalf("wrong number of blocks %d; want 1", len(blocks))
+		}
+		body := blocks[0].Body
+		if got, want := len(body.Attributes), 2; got != want {
+			t.Fatalf("wrong number of attributes %d; want %d", got, want)
+		}
+		if got, want := body.SrcRange, (hcl.Range{
+			Filename: "test.hcl",
+			Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			End:      hcl.Pos{Line: 1, Column: 20, Byte: 19},
+		}); got != want {
+			t.Errorf("wrong body range %#v; want %#v", got, want)
+		}
+	})
+
+	tests := map[string]struct {
+		input string
+		want  hcl.Diagnostics
+	}{
+		"single-line block with trailing comma": {
+			"blah { a = 1, }\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid single-line block definition",
+					Detail:   "In single-line block syntax, each comma must be followed by another argument definition. To define nested blocks, use the multi-line block syntax.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 13, Byte: 12},
+						End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+					},
+				},
+			},
+		},
+		"single-line block with duplicate argument": {
+			"blah { a = 1, a = 2 }\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Attribute redefined",
+					Detail:   "The argument \"a\" was already set at test.hcl:1,8-9. Each argument may be set only once.",
+					Subject: &hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+					},
+					Extra: hcl.NewDuplicateDiagExtra(hcl.Range{
+						Filename: "test.hcl",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					}),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Logf("\n%s", test.input)
+			_, diags := ParseConfig([]byte(test.input), "test.hcl", hcl.InitialPos, OptMultiArgumentBlocks())
+
+			want := markSyntaxErrors(test.want)
+			if diff := cmp.Diff(want, diags); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseConfigNestingDepth(t *testing.T) {
+	tests := map[string]string{
+		"tuples":       "a = " + strings.Repeat("[", maxNestingDepth+10) + strings.Repeat("]", maxNestingDepth+10) + "\n",
+		"parens":       "a = " + strings.Repeat("(", maxNestingDepth+10) + "1" + strings.Repeat(")", maxNestingDepth+10) + "\n",
+		"unary":        "a = " + strings.Repeat("-", maxNestingDepth+10) + "1\n",
+		"objects":      "a = " + strings.Repeat("{b = ", maxNestingDepth+10) + "1" + strings.Repeat("}", maxNestingDepth+10) + "\n",
+		"blocks":       strings.Repeat("b {\n", maxNestingDepth+10) + strings.Repeat("}\n", maxNestingDepth+10),
+		"templates":    "a = " + strings.Repeat(`"${`, maxNestingDepth+10) + "1" + strings.Repeat(`}"`, maxNestingDepth+10) + "\n",
+		"unclosed":     "a = " + strings.Repeat("[", 100*maxNestingDepth),
+		"conditionals": "a = " + strings.Repeat("x ? y : ", maxNestingDepth+10) + "z\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(input), "test.hcl", hcl.InitialPos)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, "Nesting too deep"; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		n := maxNestingDepth / 4
+		input := "a = " + strings.Repeat("[", n) + strings.Repeat("]", n) + "\n"
+		_, diags := ParseConfig([]byte(input), "test.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+	})
+}
+
+func TestParseConfigLimits(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		opts    []ParseOption
+		summary string
+	}{
+		"nesting depth of expressions": {
+			"a = [[[[1]]]]\n",
+			[]ParseOption{OptMaxNestingDepth(3)},
+			"Nesting too deep",
+		},
+		"nesting depth of conditionals": {
+			"a = " + strings.Repeat("x ? y : ", 50) + "z\n",
+			[]ParseOption{OptMaxNestingDepth(5)},
+			"Nesting too deep",
+		},
+		"nesting depth of blocks": {
+			"a {\n  b {\n    c {\n    }\n  }\n}\n",
+			[]ParseOption{OptMaxNestingDepth(3)},
+			"Nesting too deep",
+		},
+		"token length": {
+			"a = \"" + strings.Repeat("x", 100) + "\"\n",
+			[]ParseOption{OptMaxTokenLength(50)},
+			"Token too long",
+		},
+		"file size": {
+			"a = 1\nb = 2\n",
+			[]ParseOption{OptMaxFileSize(10)},
+			"File too large",
+		},
+		"file size before transcoding": {
+			"\xff\xfe" + strings.Repeat("a\x00 \x00=\x00 \x001\x00\n\x00", 3),
+			[]ParseOption{OptMaxFileSize(30), OptDecodeUTF16()},
+			"File too large",
+		},
+		"within limits": {
+			"a {\n  b = [1, \"xyz\"]\n}\n",
+			[]ParseOption{OptMaxNestingDepth(10), OptMaxTokenLength(5), OptMaxFileSize(100)},
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := ParseConfig([]byte(test.input), "test.hcl", hcl.InitialPos, test.opts...)
+			if test.summary == "" {
+				if diags.HasErrors() {
+					t.Fatalf("unexpected errors: %s", diags.Error())
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, test.summary; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+			if file == nil || file.Body == nil {
+				t.Errorf("no body returned")
+			}
+		})
+	}
+
+	t.Run("events", func(t *testing.T) {
+		src := []byte("a {\n  b {\n    c {\n    }\n  }\n}\n")
+		var starts int
+		diags := ParseEvents(src, "test.hcl", hcl.InitialPos, func(ev Event) bool {
+			if _, ok := ev.(*StartBlockEvent); ok {
+				starts++
+			}
+			return true
+		}, OptMaxNestingDepth(2))
+		if !diags.HasErrors() || diags[0].Summary != "Nesting too deep" {
+			t.Fatalf("wrong diagnostics: %s", diags.Error())
+		}
+		if starts != 2 {
+			t.Errorf("wrong number of blocks started %d; want 2", starts)
+		}
+	})
+}
+
+func TestParseConfigUnclosedString(t *testing.T) {
+	src := "a = \"hello\nb = 2\nc = 3\n"
+	_, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+
+	var multiLine hcl.Diagnostics
+	for _, diag := range diags {
+		if diag.Summary == "Invalid multi-line string" {
+			multiLine = append(multiLine, diag)
+		}
+	}
+	if len(multiLine) != 1 {
+		t.Fatalf("wrong number of multi-line string errors %d; want 1\n%s", len(multiLine), diags.Error())
+	}
+	diag := multiLine[0]
+	if want := "The quoted string that begins at line 1, column 5 has no closing quote mark on that line."; !strings.HasPrefix(diag.Detail, want) {
+		t.Errorf("wrong detail\ngot:  %s\nwant prefix: %s", diag.Detail, want)
+	}
+	if got, want := diag.Context.Start.Byte, 4; got != want {
+		t.Errorf("wrong context start byte %d; want %d", got, want)
+	}
+}
@@ -49,7 +49,7 @@ func (p *parser) parseTraversal(allowSplats bool) (hcl.Traversal, hcl.Diagnostic
 		return ret, diags
 	}
 
-	varName := string(varTok.Bytes)
+	varName := p.identName(varTok)
 	ret = append(ret, hcl.TraverseRoot{
 		Name:     varName,
 		SrcRange: varTok.Range,
@@ -88,7 +88,7 @@ func (p *parser) parseTraversal(allowSplats bool) (hcl.Traversal, hcl.Diagnostic
 				return ret, diags
 			}
 
-			attrName := string(nameTok.Bytes)
+			attrName := p.identName(nameTok)
 			ret = append(ret, hcl.TraverseAttr{
 				Name:     attrName,
 				SrcRange: hcl.RangeBetween(dot.Range, nameTok.Range),
@@ -58,6 +58,39 @@ func (p *peeker) Read() Token {
 	return ret
 }
 
+// peekerMark is a checkpoint of the state of a peeker, returned by Mark and
+// accepted by Reset.
+type peekerMark struct {
+	nextIndex       int
+	includeNewlines []bool
+}
+
+// Mark returns a checkpoint of the peeker's current position, which can
+// later be passed to Reset to roll back to that position. This allows for
+// speculative parsing: try one production, and if it doesn't work out then
+// reset and try another.
+//
+// The checkpoint also captures the current state of the newlines stack, so
+// a speculative parse that pushes without a corresponding pop before
+// failing will not disturb the stack discipline of the caller.
+func (p *peeker) Mark() peekerMark {
+	stack := make([]bool, len(p.IncludeNewlinesStack))
+	copy(stack, p.IncludeNewlinesStack)
+	return peekerMark{
+		nextIndex:       p.NextIndex,
+		includeNewlines: stack,
+	}
+}
+
+// Reset rolls the peeker back to a checkpoint previously returned by Mark.
+//
+// A mark may be used for any number of calls to Reset, but only with the
+// same peeker that created it.
+func (p *peeker) Reset(mark peekerMark) {
+	p.NextIndex = mark.nextIndex
+	p.IncludeNewlinesStack = append(p.IncludeNewlinesStack[:0], mark.includeNewlines...)
+}
+
 func (p *peeker) NextRange() hcl.Range {
 	return p.Peek().Range
 }
@@ -74,6 +107,8 @@ func (p *peeker) nextToken() (Token, int) {
 	for i := p.NextIndex; i < len(p.Tokens); i++ {
 		tok := p.Tokens[i]
 		switch tok.Type {
+		case TokenWhitespace:
+			continue
 		case TokenComment:
 			if !p.IncludeComments {
 				// Single-line comment tokens, starting with # or //, absorb
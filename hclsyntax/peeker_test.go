@@ -6,6 +6,8 @@ package hclsyntax
 import (
 	"reflect"
 	"testing"
+
+	"github.com/hashicorp/hcl/v2"
 )
 
 func init() {
@@ -164,3 +166,87 @@ func TestPeeker(t *testing.T) {
 		}
 	}
 }
+
+func TestPeekerMarkReset(t *testing.T) {
+	tokens := Tokens{
+		{
+			Type: TokenIdent,
+		},
+		{
+			Type: TokenNewline,
+		},
+		{
+			Type: TokenIdent,
+		},
+		{
+			Type: TokenNewline,
+		},
+		{
+			Type: TokenIdent,
+		},
+		{
+			Type: TokenEOF,
+		},
+	}
+
+	peeker := newPeeker(tokens, false)
+
+	peeker.Read() // ident
+	mark := peeker.Mark()
+
+	// A speculative parse that pushes without popping and then fails
+	// should not disturb the newlines stack once we reset.
+	peeker.PushIncludeNewlines(false)
+	var gotTypes []TokenType
+	for i := 0; i < 2; i++ {
+		gotTypes = append(gotTypes, peeker.Read().Type)
+	}
+	if want := []TokenType{TokenIdent, TokenIdent}; !reflect.DeepEqual(gotTypes, want) {
+		t.Fatalf("wrong types before reset\ngot:  %#v\nwant: %#v", gotTypes, want)
+	}
+
+	peeker.Reset(mark)
+
+	gotTypes = nil
+	for {
+		read := peeker.Read()
+		gotTypes = append(gotTypes, read.Type)
+		if read.Type == TokenEOF {
+			break
+		}
+	}
+	if want := []TokenType{TokenNewline, TokenIdent, TokenNewline, TokenIdent, TokenEOF}; !reflect.DeepEqual(gotTypes, want) {
+		t.Errorf("wrong types after reset\ngot:  %#v\nwant: %#v", gotTypes, want)
+	}
+
+	// The same mark can be used again.
+	peeker.Reset(mark)
+	if got, want := peeker.Peek().Type, TokenNewline; got != want {
+		t.Errorf("wrong type after second reset %s; want %s", got, want)
+	}
+
+	// Must not panic, because the reset discarded the unbalanced push.
+	peeker.AssertEmptyIncludeNewlinesStack()
+}
+
+func TestParserMarkReset(t *testing.T) {
+	tokens, diags := LexConfig([]byte("a b\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected lex errors: %s", diags.Error())
+	}
+	p := &parser{peeker: newPeeker(tokens, false)}
+
+	mark := p.Mark()
+	p.recover(TokenNewline)
+	if !p.recovery {
+		t.Fatalf("recover did not enable recovery mode")
+	}
+
+	p.Reset(mark)
+	if p.recovery {
+		t.Errorf("recovery mode still enabled after reset")
+	}
+	if got, want := string(p.Peek().Bytes), "a"; got != want {
+		t.Errorf("wrong next token %q after reset; want %q", got, want)
+	}
+}
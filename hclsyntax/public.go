@@ -4,6 +4,9 @@
 package hclsyntax
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -17,10 +20,18 @@ import (
 // situations where detailed access is required. However, most common use-cases
 // should be served using the hcl.Body interface to ensure compatibility with
 // other configurationg syntaxes, such as JSON.
-func ParseConfig(src []byte, filename string, start hcl.Pos) (*hcl.File, hcl.Diagnostics) {
-	tokens, diags := LexConfig(src, filename, start)
+func ParseConfig(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (*hcl.File, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	if options.decodeUTF16 && !options.fileTooLarge(src) {
+		// We transcode here, rather than leaving it to the lexer, so that
+		// the file's bytes match the source ranges in its syntax tree. The
+		// size limit applies to the bytes we were given, so input that
+		// exceeds it is left for the lexer to reject.
+		src = decodeUTF16(src)
+	}
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := newParser(peeker, options)
 	body, parseDiags := parser.ParseBody(TokenEOF)
 	diags = append(diags, parseDiags...)
 
@@ -36,15 +47,25 @@ func ParseConfig(src []byte, filename string, start hcl.Pos) (*hcl.File, hcl.Dia
 		Nav: navigation{
 			root: body,
 		},
-	}, diags
+	}, options.finishDiagnostics(diags)
+}
+
+// ParseConfigWithContext is like ParseConfig, but checks periodically whether
+// the given context has been canceled or has passed its deadline, in which
+// case it stops parsing and returns an error diagnostic along with whatever
+// it had parsed so far. This allows servers that parse large or untrusted
+// input to abandon a parse that is taking too long.
+func ParseConfigWithContext(ctx context.Context, src []byte, filename string, start hcl.Pos, opts ...ParseOption) (*hcl.File, hcl.Diagnostics) {
+	return ParseConfig(src, filename, start, append(opts[:len(opts):len(opts)], optContext{ctx})...)
 }
 
 // ParseExpression parses the given buffer as a standalone HCL expression,
 // returning it as an instance of Expression.
-func ParseExpression(src []byte, filename string, start hcl.Pos) (Expression, hcl.Diagnostics) {
-	tokens, diags := LexExpression(src, filename, start)
+func ParseExpression(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Expression, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := newParser(peeker, options)
 
 	// Bare expressions are always parsed in  "ignore newlines" mode, as if
 	// they were wrapped in parentheses.
@@ -70,15 +91,22 @@ func ParseExpression(src []byte, filename string, start hcl.Pos) (Expression, hc
 	// errors.
 	peeker.AssertEmptyIncludeNewlinesStack()
 
-	return expr, diags
+	return expr, options.finishDiagnostics(diags)
+}
+
+// ParseExpressionWithContext is like ParseExpression, but stops parsing if
+// the given context is canceled, as with ParseConfigWithContext.
+func ParseExpressionWithContext(ctx context.Context, src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Expression, hcl.Diagnostics) {
+	return ParseExpression(src, filename, start, append(opts[:len(opts):len(opts)], optContext{ctx})...)
 }
 
 // ParseTemplate parses the given buffer as a standalone HCL template,
 // returning it as an instance of Expression.
-func ParseTemplate(src []byte, filename string, start hcl.Pos) (Expression, hcl.Diagnostics) {
-	tokens, diags := LexTemplate(src, filename, start)
+func ParseTemplate(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Expression, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanTemplate, options)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := newParser(peeker, options)
 	expr, parseDiags := parser.ParseTemplate()
 	diags = append(diags, parseDiags...)
 
@@ -87,7 +115,13 @@ func ParseTemplate(src []byte, filename string, start hcl.Pos) (Expression, hcl.
 	// errors.
 	peeker.AssertEmptyIncludeNewlinesStack()
 
-	return expr, diags
+	return expr, options.finishDiagnostics(diags)
+}
+
+// ParseTemplateWithContext is like ParseTemplate, but stops parsing if the
+// given context is canceled, as with ParseConfigWithContext.
+func ParseTemplateWithContext(ctx context.Context, src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Expression, hcl.Diagnostics) {
+	return ParseTemplate(src, filename, start, append(opts[:len(opts):len(opts)], optContext{ctx})...)
 }
 
 // ParseTraversalAbs parses the given buffer as a standalone absolute traversal.
@@ -96,10 +130,11 @@ func ParseTemplate(src []byte, filename string, start hcl.Pos) (Expression, hcl.
 // it allows only attribute and indexing operations on variables. Traverals
 // are useful as a syntax for referring to objects without necessarily
 // evaluating them.
-func ParseTraversalAbs(src []byte, filename string, start hcl.Pos) (hcl.Traversal, hcl.Diagnostics) {
-	tokens, diags := LexExpression(src, filename, start)
+func ParseTraversalAbs(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (hcl.Traversal, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := newParser(peeker, options)
 
 	// Bare traverals are always parsed in  "ignore newlines" mode, as if
 	// they were wrapped in parentheses.
@@ -115,7 +150,7 @@ func ParseTraversalAbs(src []byte, filename string, start hcl.Pos) (hcl.Traversa
 	// errors.
 	peeker.AssertEmptyIncludeNewlinesStack()
 
-	return expr, diags
+	return expr, options.finishDiagnostics(diags)
 }
 
 // ParseTraversalPartial matches the behavior of ParseTraversalAbs except
@@ -127,10 +162,11 @@ func ParseTraversalAbs(src []byte, filename string, start hcl.Pos) (hcl.Traversa
 // Traversals that include splats cannot be automatically traversed by HCL using
 // the TraversalAbs or TraversalRel methods. Instead, the caller must handle
 // the traversals manually.
-func ParseTraversalPartial(src []byte, filename string, start hcl.Pos) (hcl.Traversal, hcl.Diagnostics) {
-	tokens, diags := LexExpression(src, filename, start)
+func ParseTraversalPartial(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (hcl.Traversal, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
 	peeker := newPeeker(tokens, false)
-	parser := &parser{peeker: peeker}
+	parser := newParser(peeker, options)
 
 	// Bare traverals are always parsed in  "ignore newlines" mode, as if
 	// they were wrapped in parentheses.
@@ -146,7 +182,7 @@ func ParseTraversalPartial(src []byte, filename string, start hcl.Pos) (hcl.Trav
 	// errors.
 	peeker.AssertEmptyIncludeNewlinesStack()
 
-	return expr, diags
+	return expr, options.finishDiagnostics(diags)
 }
 
 // LexConfig performs lexical analysis on the given buffer, treating it as a
@@ -156,10 +192,10 @@ func ParseTraversalPartial(src []byte, filename string, start hcl.Pos) (hcl.Trav
 // diagnostics may include errors about lexical issues such as bad character
 // encodings or unrecognized characters, but full parsing is required to
 // detect _all_ syntax errors.
-func LexConfig(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
-	tokens := scanTokens(src, filename, start, scanNormal)
-	diags := checkInvalidTokens(tokens)
-	return tokens, diags
+func LexConfig(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Tokens, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
+	return tokens, options.finishDiagnostics(diags)
 }
 
 // LexExpression performs lexical analysis on the given buffer, treating it as
@@ -169,12 +205,12 @@ func LexConfig(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnost
 // diagnostics may include errors about lexical issues such as bad character
 // encodings or unrecognized characters, but full parsing is required to
 // detect _all_ syntax errors.
-func LexExpression(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
+func LexExpression(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Tokens, hcl.Diagnostics) {
 	// This is actually just the same thing as LexConfig, since configs
 	// and expressions lex in the same way.
-	tokens := scanTokens(src, filename, start, scanNormal)
-	diags := checkInvalidTokens(tokens)
-	return tokens, diags
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanNormal, options)
+	return tokens, options.finishDiagnostics(diags)
 }
 
 // LexTemplate performs lexical analysis on the given buffer, treating it as a
@@ -184,12 +220,153 @@ func LexExpression(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diag
 // diagnostics may include errors about lexical issues such as bad character
 // encodings or unrecognized characters, but full parsing is required to
 // detect _all_ syntax errors.
-func LexTemplate(src []byte, filename string, start hcl.Pos) (Tokens, hcl.Diagnostics) {
-	tokens := scanTokens(src, filename, start, scanTemplate)
-	diags := checkInvalidTokens(tokens)
+func LexTemplate(src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Tokens, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := lexTokens(src, filename, start, scanTemplate, options)
+	return tokens, options.finishDiagnostics(diags)
+}
+
+// AppendLexConfig is like LexConfig, but appends the resulting tokens to
+// the given slice and returns the extended slice, in the same way as the
+// built-in append function.
+//
+// The lexer accumulates tokens in the spare capacity of the given slice
+// when there is enough of it, so a program that lexes many small documents
+// can avoid allocating a new slice for each one by passing the result of
+// its previous call truncated to zero length. The bytes of the tokens still
+// refer to the source buffer, as described for Token, so a token slice must
+// not be reused while the tokens from an earlier call are still needed.
+func AppendLexConfig(dst Tokens, src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Tokens, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := appendLexTokens(dst, src, filename, start, scanNormal, options)
+	return tokens, options.finishDiagnostics(diags)
+}
+
+// AppendLexTemplate is like LexTemplate, but appends the resulting tokens to
+// the given slice in the same way as AppendLexConfig.
+func AppendLexTemplate(dst Tokens, src []byte, filename string, start hcl.Pos, opts ...ParseOption) (Tokens, hcl.Diagnostics) {
+	options := newParseOpts(opts)
+	tokens, diags := appendLexTokens(dst, src, filename, start, scanTemplate, options)
+	return tokens, options.finishDiagnostics(diags)
+}
+
+func lexTokens(src []byte, filename string, start hcl.Pos, mode scanMode, opts parseOpts) (Tokens, hcl.Diagnostics) {
+	return appendLexTokens(nil, src, filename, start, mode, opts)
+}
+
+// appendLexTokens appends the tokens lexed from the given source to dst,
+// scanning into the spare capacity of dst if possible.
+func appendLexTokens(dst Tokens, src []byte, filename string, start hcl.Pos, mode scanMode, opts parseOpts) (Tokens, hcl.Diagnostics) {
+	tokens, diags := lexTokensInto(dst[len(dst):], src, filename, start, mode, opts)
+	if len(dst) == 0 {
+		return tokens, diags
+	}
+	return append(dst, tokens...), diags
+}
+
+// lexTokensInto lexes the given source, accumulating the tokens in the
+// capacity of buf if there is likely to be enough, or else in a new slice.
+func lexTokensInto(buf Tokens, src []byte, filename string, start hcl.Pos, mode scanMode, opts parseOpts) (Tokens, hcl.Diagnostics) {
+	if opts.ctx != nil {
+		if err := opts.ctx.Err(); err != nil {
+			rng := hcl.Range{Filename: filename, Start: start, End: start}
+			return Tokens{eofAt(filename, start)}, hcl.Diagnostics{canceledDiagnostic(rng, err)}
+		}
+	}
+	if opts.fileTooLarge(src) {
+		return Tokens{eofAt(filename, start)}, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "File too large",
+				Detail:   fmt.Sprintf("This file is %d bytes long, which exceeds the maximum size of %d bytes.", len(src), opts.maxFileSize),
+				Subject:  &hcl.Range{Filename: filename, Start: start, End: start},
+			},
+		}
+	}
+	if hasUTF16BOM(src) {
+		if !opts.decodeUTF16 {
+			return Tokens{eofAt(filename, start)}, utf16Diagnostics(filename, start)
+		}
+		src = decodeUTF16(src)
+	}
+	var tmap *templateMap
+	if opts.textTemplate != nil {
+		var tmplDiags hcl.Diagnostics
+		src, tmap, tmplDiags = opts.textTemplate.render(src, filename, start)
+		if tmplDiags.HasErrors() {
+			return Tokens{eofAt(filename, start)}, tmplDiags
+		}
+	}
+
+	tokens := scanTokens(src, filename, start, mode, scanOpts{
+		maxTokenLength: opts.maxTokenLength,
+		rawStrings:     opts.rawStrings,
+		buf:            buf,
+	})
+	var diags hcl.Diagnostics
+	if opts.rawStrings {
+		diags = rawStringDiagnostics(tokens)
+	}
+	if opts.maxTokenLength > 0 {
+		for _, tok := range tokens {
+			if len(tok.Bytes) > opts.maxTokenLength {
+				rng := tok.Range
+				if tmap != nil {
+					remapped := Tokens{tok}
+					tmap.remapTokens(remapped)
+					rng = remapped[0].Range
+				}
+				return Tokens{eofAt(filename, start)}, hcl.Diagnostics{
+					{
+						Severity: hcl.DiagError,
+						Summary:  "Token too long",
+						Detail:   fmt.Sprintf("This token is %d bytes long, which exceeds the maximum length of %d bytes.", len(tok.Bytes), opts.maxTokenLength),
+						Subject:  &rng,
+					},
+				}
+			}
+		}
+	}
+	if opts.replaceInvalidUTF8 {
+		var utf8Diags hcl.Diagnostics
+		tokens, utf8Diags = replaceBadUTF8(tokens, mode)
+		diags = append(diags, utf8Diags...)
+	}
+	if opts.foldNegativeNumbers {
+		tokens = foldNegativeNumbers(tokens)
+	}
+	if opts.lineContinuation {
+		tokens = foldLineContinuations(tokens)
+	}
+	if opts.whitespaceTokens {
+		tokens = insertWhitespace(src, tokens, start)
+	}
+	if opts.discardComments {
+		tokens = discardComments(tokens)
+	}
+	if tmap != nil {
+		tmap.remapTokens(tokens)
+	}
+	if opts.tabWidth > 0 || opts.columnUnit != ColumnGraphemes {
+		if tmap != nil {
+			tmap.recomputeColumns(tokens, opts.tabWidth, opts.columnUnit)
+		} else {
+			recomputeColumns(src, tokens, start, opts.tabWidth, opts.columnUnit)
+		}
+	}
+	diags = append(diags, checkInvalidTokens(tokens)...)
 	return tokens, diags
 }
 
+// eofAt returns an EOF token at the given position, for use as the sole
+// token when the input is rejected without being scanned.
+func eofAt(filename string, pos hcl.Pos) Token {
+	return Token{
+		Type:  TokenEOF,
+		Range: hcl.Range{Filename: filename, Start: pos, End: pos},
+	}
+}
+
 // ValidIdentifier tests if the given string could be a valid identifier in
 // a native syntax expression.
 //
@@ -200,6 +377,6 @@ func ValidIdentifier(s string) bool {
 	// This is a kinda-expensive way to do something pretty simple, but it
 	// is easiest to do with our existing scanner-related infrastructure here
 	// and nobody should be validating identifiers in a tight loop.
-	tokens := scanTokens([]byte(s), "", hcl.Pos{}, scanIdentOnly)
+	tokens := scanTokens([]byte(s), "", hcl.Pos{}, scanIdentOnly, scanOpts{})
 	return len(tokens) == 2 && tokens[0].Type == TokenIdent && tokens[1].Type == TokenEOF
 }
@@ -4,9 +4,14 @@
 package hclsyntax
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestValidIdentifier(t *testing.T) {
@@ -65,3 +70,683 @@ func BenchmarkLexConfig(b *testing.B) {
 
 	T = tokens
 }
+
+func BenchmarkAppendLexConfig(b *testing.B) {
+	src := []byte("module \"once\" {\n  source = \"../modules/foo\"\n}\n\nmodule \"twice\" {\n  source = \"../modules/foo\"\n}\n")
+	filename := "testdata/dave/main.tf"
+	start := hcl.Pos{Line: 1, Column: 1, Byte: 0}
+
+	var tokens Tokens
+
+	for i := 0; i < b.N; i++ {
+		tokens, _ = AppendLexConfig(tokens[:0], src, filename, start)
+	}
+
+	T = tokens
+}
+
+func TestAppendLexConfig(t *testing.T) {
+	src := []byte("a = [1, \"b\"]\n")
+	want, diags := LexConfig(src, "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	prefix := Token{Type: TokenIdent, Bytes: []byte("prefix")}
+	got, diags := AppendLexConfig(Tokens{prefix}, src, "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if len(got) != len(want)+1 || string(got[0].Bytes) != "prefix" {
+		t.Fatalf("wrong tokens\ngot:  %#v\nwant: %#v after the prefix", got, want)
+	}
+	for i := range want {
+		if got[i+1].Type != want[i].Type || got[i+1].Range != want[i].Range {
+			t.Errorf("wrong token %d %#v; want %#v", i+1, got[i+1], want[i])
+		}
+	}
+
+	// Lexing again into the same buffer must reuse its memory.
+	buf := got[:0]
+	again, _ := AppendLexConfig(buf, src, "test.hcl", hcl.InitialPos)
+	if &again[0] != &buf[:1][0] {
+		t.Error("buffer was not reused")
+	}
+	fresh := testing.AllocsPerRun(10, func() {
+		LexConfig(src, "test.hcl", hcl.InitialPos)
+	})
+	reused := testing.AllocsPerRun(10, func() {
+		buf, _ = AppendLexConfig(buf[:0], src, "test.hcl", hcl.InitialPos)
+	})
+	if reused >= fresh {
+		t.Errorf("lexing into a reused buffer made %v allocations; want fewer than the %v made by LexConfig", reused, fresh)
+	}
+}
+
+func TestParseConfigReplaceInvalidUTF8(t *testing.T) {
+	tests := map[string]struct {
+		Src  string
+		Want cty.Value
+	}{
+		"quoted": {
+			"a = \"x\xffy\"\n",
+			cty.StringVal("x�y"),
+		},
+		"heredoc": {
+			"a = <<EOT\nx\xffy\nEOT\n",
+			cty.StringVal("x�y\n"),
+		},
+		"after interpolation": {
+			"a = \"${\"b\"}\xfe\xff\"\n",
+			cty.StringVal("b��"),
+		},
+		"outside literal": {
+			"a = 1 \xfe\xff\n",
+			cty.NumberIntVal(1),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(test.Src), "test.hcl", hcl.InitialPos)
+			if !diags.HasErrors() {
+				t.Fatalf("unexpected success without OptReplaceInvalidUTF8")
+			}
+
+			f, diags := ParseConfig([]byte(test.Src), "test.hcl", hcl.InitialPos, OptReplaceInvalidUTF8())
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			if len(diags) != 1 || diags[0].Severity != hcl.DiagWarning {
+				t.Fatalf("wrong diagnostics; want one warning\n%s", diags.Error())
+			}
+
+			attrs, diags := f.Body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			got, diags := attrs["a"].Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestParseConfigReplaceInvalidUTF8Warnings(t *testing.T) {
+	// Each kind of fix is reported once, however many times it was made.
+	src := "a = \"x\xffy\"\nb = 1 \xfe\nc = \"\xff\"\nd = 2 \xff\n"
+	_, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptReplaceInvalidUTF8())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Error())
+	}
+	for i, wantLine := range []int{1, 2} {
+		diag := diags[i]
+		if diag.Severity != hcl.DiagWarning {
+			t.Errorf("diagnostic %d is not a warning: %s", i, diag.Error())
+		}
+		if got := diag.Subject.Start.Line; got != wantLine {
+			t.Errorf("diagnostic %d is on line %d; want %d", i, got, wantLine)
+		}
+	}
+	if diags[0].Detail == diags[1].Detail {
+		t.Errorf("both diagnostics have the same detail %q", diags[0].Detail)
+	}
+}
+
+func TestLexConfigFoldNegativeNumbers(t *testing.T) {
+	tests := map[string][]string{
+		"a = -42\n":        {"a", "=", "-42", "\n"},
+		"a = [-1, 2 -3]\n": {"a", "=", "[", "-1", ",", "2", "-", "3", "]", "\n"},
+		"a = b -1\n":       {"a", "=", "b", "-", "1", "\n"},
+		"a = f(x) - -1\n":  {"a", "=", "f", "(", "x", ")", "-", "-1", "\n"},
+		"a = - 1\n":        {"a", "=", "-", "1", "\n"},
+		"a = -b\n":         {"a", "=", "-", "b", "\n"},
+		"a = 2 * -1.5\n":   {"a", "=", "2", "*", "-1.5", "\n"},
+	}
+
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptFoldNegativeNumbers())
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			var got []string
+			for _, tok := range tokens {
+				if tok.Type != TokenEOF {
+					got = append(got, string(tok.Bytes))
+				}
+			}
+			if len(got) != len(want) {
+				t.Fatalf("wrong tokens %q; want %q", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("wrong tokens %q; want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseExpressionFoldNegativeNumbers(t *testing.T) {
+	expr, diags := ParseExpression([]byte("-46+5"), "test.hcl", hcl.InitialPos, OptFoldNegativeNumbers())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	binOp, ok := expr.(*BinaryOpExpr)
+	if !ok {
+		t.Fatalf("wrong expression type %T; want *BinaryOpExpr", expr)
+	}
+	lhs, ok := binOp.LHS.(*LiteralValueExpr)
+	if !ok {
+		t.Fatalf("wrong LHS type %T; want *LiteralValueExpr", binOp.LHS)
+	}
+	if want := cty.NumberIntVal(-46); !lhs.Val.RawEquals(want) {
+		t.Errorf("wrong LHS value %#v; want %#v", lhs.Val, want)
+	}
+	if got, want := lhs.SrcRange, (hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+		End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+	}); got != want {
+		t.Errorf("wrong LHS range %#v; want %#v", got, want)
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if want := cty.NumberIntVal(-41); !val.RawEquals(want) {
+		t.Errorf("wrong result %#v; want %#v", val, want)
+	}
+}
+
+func TestParseExpressionLineContinuation(t *testing.T) {
+	tests := map[string]cty.Value{
+		"\"abc \\\n   def\"":   cty.StringVal("abc def"),
+		"\"abc\\\r\n\tdef\"":   cty.StringVal("abcdef"),
+		"\"a\\n\\\n\n  b\"":    cty.StringVal("a\nb"),
+		"\"${x} \\\n  ${x}\"":  cty.StringVal("y y"),
+		"\"escaped \\\\\"":     cty.StringVal("escaped \\"),
+		"\"trailing \\\n   \"": cty.StringVal("trailing "),
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"x": cty.StringVal("y")},
+	}
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(src), "test.hcl", hcl.InitialPos, OptLineContinuation())
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			got, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if !got.RawEquals(want) {
+				t.Errorf("wrong result %#v; want %#v", got, want)
+			}
+		})
+	}
+
+	_, diags := ParseExpression([]byte("\"abc \\\n def\""), "test.hcl", hcl.InitialPos)
+	if !diags.HasErrors() {
+		t.Error("succeeded without OptLineContinuation; want error")
+	}
+}
+
+func TestLexConfigDiscardComments(t *testing.T) {
+	src := "# header\na = 1 // one\nb = /* two */ 2\n/* trailing */"
+	tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptDiscardComments())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got []string
+	for _, tok := range tokens {
+		if tok.Type == TokenComment {
+			t.Errorf("comment token %q was not discarded", tok.Bytes)
+		}
+		if tok.Type != TokenEOF {
+			got = append(got, string(tok.Bytes))
+		}
+	}
+	want := []string{"\n", "a", "=", "1", "\n", "b", "=", "2", "\n"}
+	if len(got) != len(want) {
+		t.Fatalf("wrong tokens %q; want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong tokens %q; want %q", got, want)
+		}
+	}
+
+	// The newline replacing the comment after "1" must be positioned where
+	// the newline is in the source.
+	nl := tokens[4]
+	if nl.Range.Start != (hcl.Pos{Line: 2, Column: 13, Byte: 21}) || nl.Range.End != (hcl.Pos{Line: 3, Column: 1, Byte: 22}) {
+		t.Errorf("wrong newline range %s", nl.Range)
+	}
+
+	// The parser must still see each attribute on its own line.
+	_, diags = ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptDiscardComments())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+}
+
+func TestLexConfigWhitespaceTokens(t *testing.T) {
+	src := "\xef\xbb\xbfa\t=  1 # one\r\nb = \"x ${y}  \"\n  c = <<EOT\n  hi\n  EOT\n"
+	tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptWhitespaceTokens())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got []byte
+	for i, tok := range tokens {
+		got = append(got, tok.Bytes...)
+		if i > 0 && tok.Range.Start != tokens[i-1].Range.End {
+			t.Errorf("token %d %q starts at %#v, but previous token ends at %#v", i, tok.Bytes, tok.Range.Start, tokens[i-1].Range.End)
+		}
+	}
+	if string(got) != src {
+		t.Errorf("wrong concatenated bytes\ngot:  %q\nwant: %q", got, src)
+	}
+
+	// The spaces between "=" and "1" must be a single whitespace token.
+	ws := tokens[4]
+	if ws.Type != TokenWhitespace || string(ws.Bytes) != "  " {
+		t.Errorf("wrong token %s %q; want TokenWhitespace \"  \"", ws.Type, ws.Bytes)
+	}
+	if ws.Range.Start != (hcl.Pos{Line: 1, Column: 4, Byte: 6}) || ws.Range.End != (hcl.Pos{Line: 1, Column: 6, Byte: 8}) {
+		t.Errorf("wrong whitespace range %s", ws.Range)
+	}
+
+	// The parser must ignore the whitespace tokens.
+	_, diags = ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptWhitespaceTokens())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+}
+
+func TestLexConfigTabWidth(t *testing.T) {
+	src := "a\t= 1\n\tb = \"\t\"\nc = 2"
+	tests := []struct {
+		opts []ParseOption
+		want []int // start column of each non-newline token
+	}{
+		{
+			nil,
+			[]int{1, 3, 5, 2, 4, 6, 7, 8, 1, 3, 5},
+		},
+		{
+			[]ParseOption{OptTabWidth(4)},
+			[]int{1, 5, 7, 5, 7, 9, 10, 13, 1, 3, 5},
+		},
+		{
+			[]ParseOption{OptTabWidth(8)},
+			[]int{1, 9, 11, 9, 11, 13, 14, 17, 1, 3, 5},
+		},
+	}
+
+	for _, test := range tests {
+		tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, test.opts...)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		var got []int
+		for _, tok := range tokens {
+			if tok.Type != TokenNewline && tok.Type != TokenEOF {
+				got = append(got, tok.Range.Start.Column)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Fatalf("wrong columns %v; want %v", got, test.want)
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("wrong columns %v; want %v", got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseConfigFilenames(t *testing.T) {
+	// Every source range in the tokens and syntax tree must carry the
+	// filename given to the parser, so that diagnostics can say which file
+	// they relate to.
+	src := `
+a = 1 + var.b * -2
+c = [for k, v in d : "${k}=${v}" if v != null]
+e = { f = g.h[0].*.i, "j" = (k ? l : m) }
+n = <<EOT
+  %{ for x in o ~}
+  ${x}
+  %{ endfor }
+EOT
+block "label" {
+  p = q(r...)
+  nested {
+    s = t[*].u
+  }
+}
+`
+	tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	for _, tok := range tokens {
+		if tok.Range.Filename != "test.hcl" {
+			t.Errorf("token %s %q has filename %q", tok.Type, tok.Bytes, tok.Range.Filename)
+		}
+	}
+
+	f, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	VisitAll(f.Body.(*Body), func(node Node) hcl.Diagnostics {
+		switch node.(type) {
+		case Attributes, Blocks:
+			return nil // these collections have no source range of their own
+		}
+		if got := node.Range().Filename; got != "test.hcl" {
+			t.Errorf("%T at %s has filename %q", node, node.Range(), got)
+		}
+		switch node := node.(type) {
+		case *Block:
+			for _, rng := range append([]hcl.Range{node.TypeRange, node.OpenBraceRange, node.CloseBraceRange}, node.LabelRanges...) {
+				if rng.Filename != "test.hcl" {
+					t.Errorf("block range %s has filename %q", rng, rng.Filename)
+				}
+			}
+		case *Attribute:
+			if node.NameRange.Filename != "test.hcl" || node.EqualsRange.Filename != "test.hcl" {
+				t.Errorf("attribute %s ranges lack filename", node.Name)
+			}
+		case *ScopeTraversalExpr:
+			for _, step := range node.Traversal {
+				if got := step.SourceRange().Filename; got != "test.hcl" {
+					t.Errorf("traversal step at %s has filename %q", step.SourceRange(), got)
+				}
+			}
+		}
+		return nil
+	})
+
+	// Diagnostics must also carry the filename.
+	_, diags = ParseConfig([]byte("a = \n"), "bad.hcl", hcl.InitialPos)
+	for _, diag := range diags {
+		if diag.Subject == nil || diag.Subject.Filename != "bad.hcl" {
+			t.Errorf("diagnostic %q lacks filename", diag.Summary)
+		}
+	}
+}
+
+func TestLexConfigColumnUnit(t *testing.T) {
+	// "👍🏽" is one grapheme cluster made of two code points, each of which
+	// needs two UTF-16 code units and four bytes.
+	src := "a = \"é👍🏽\" # c\n"
+	tests := map[ColumnUnit][]int{ // start column of each token until the newline
+		ColumnGraphemes: {1, 3, 5, 6, 8, 10},
+		ColumnRunes:     {1, 3, 5, 6, 9, 11},
+		ColumnUTF16:     {1, 3, 5, 6, 11, 13},
+		ColumnBytes:     {1, 3, 5, 6, 16, 18},
+	}
+
+	for unit, want := range tests {
+		tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptColumnUnit(unit))
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		var got []int
+		for _, tok := range tokens {
+			if tok.Type == TokenNewline || tok.Type == TokenEOF {
+				break
+			}
+			got = append(got, tok.Range.Start.Column)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("unit %d: wrong columns %v; want %v", unit, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("unit %d: wrong columns %v; want %v", unit, got, want)
+				break
+			}
+		}
+	}
+
+	// Tab stops are measured in the chosen unit too.
+	tokens, _ := LexConfig([]byte("\"👍🏽\"\tx"), "test.hcl", hcl.InitialPos, OptColumnUnit(ColumnUTF16), OptTabWidth(4))
+	if got := tokens[3].Range.Start.Column; got != 9 {
+		t.Errorf("wrong column %d after tab; want 9", got)
+	}
+}
+
+func TestLexConfigCRLF(t *testing.T) {
+	// A CRLF sequence is a single line ending, in every column unit, and
+	// so must not leave the following token at column 2 or count the
+	// carriage return as part of the previous line.
+	src := "a = 1\r\n# c\r\nb = <<EOT\r\nhi\r\nEOT\r\n"
+	for _, unit := range []ColumnUnit{ColumnGraphemes, ColumnRunes, ColumnUTF16, ColumnBytes} {
+		tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptColumnUnit(unit))
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		var got []hcl.Pos
+		for _, tok := range tokens {
+			if tok.Type == TokenIdent {
+				got = append(got, tok.Range.Start)
+			}
+		}
+		want := []hcl.Pos{
+			{Line: 1, Column: 1, Byte: 0},
+			{Line: 3, Column: 1, Byte: 12},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("unit %d: wrong positions %#v; want %#v", unit, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("unit %d: wrong position %#v; want %#v", unit, got[i], want[i])
+			}
+		}
+		if got, want := tokens[len(tokens)-1].Range.Start, (hcl.Pos{Line: 6, Column: 1, Byte: len(src)}); got != want {
+			t.Errorf("unit %d: wrong EOF position %#v; want %#v", unit, got, want)
+		}
+	}
+}
+
+func TestParseConfigByteOrderMarks(t *testing.T) {
+	utf16LE := func(s string) []byte {
+		ret := []byte{0xff, 0xfe}
+		for _, r := range s {
+			ret = append(ret, byte(r), byte(r>>8))
+		}
+		return ret
+	}
+	utf16BE := func(s string) []byte {
+		ret := []byte{0xfe, 0xff}
+		for _, r := range s {
+			ret = append(ret, byte(r>>8), byte(r))
+		}
+		return ret
+	}
+
+	tests := map[string]struct {
+		src       []byte
+		opts      []ParseOption
+		wantBytes string
+		wantDiag  string
+	}{
+		"UTF-8 BOM": {
+			src:       []byte("\xef\xbb\xbfa = \"é\"\n"),
+			wantBytes: "\xef\xbb\xbfa = \"é\"\n",
+		},
+		"UTF-16LE": {
+			src:       utf16LE("a = \"é\"\n"),
+			opts:      []ParseOption{OptDecodeUTF16()},
+			wantBytes: "a = \"é\"\n",
+		},
+		"UTF-16BE": {
+			src:       utf16BE("a = \"é\"\n"),
+			opts:      []ParseOption{OptDecodeUTF16()},
+			wantBytes: "a = \"é\"\n",
+		},
+		"UTF-16 without option": {
+			src:      utf16LE("a = \"é\"\n"),
+			wantDiag: "test.hcl:1,1-2: Unsupported character encoding; This file appears to be encoded as UTF-16, but all input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig(test.src, "test.hcl", hcl.InitialPos, test.opts...)
+			if test.wantDiag != "" {
+				if len(diags) != 1 || diags[0].Error() != test.wantDiag {
+					t.Fatalf("wrong diagnostics %s", diags.Error())
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if got := string(f.Bytes); got != test.wantBytes {
+				t.Errorf("wrong file bytes %q; want %q", got, test.wantBytes)
+			}
+			attrs, _ := f.Body.JustAttributes()
+			val, diags := attrs["a"].Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if !val.RawEquals(cty.StringVal("é")) {
+				t.Errorf("wrong value %#v", val)
+			}
+			if got := string(attrs["a"].Range.SliceBytes(f.Bytes)); got != "a = \"é\"" {
+				t.Errorf("attribute range covers %q", got)
+			}
+		})
+	}
+}
+
+func TestParseConfigDuplicateAttributeRanges(t *testing.T) {
+	tests := map[string]struct {
+		src         string
+		wantPrev    hcl.Pos
+		wantSubject hcl.Pos
+	}{
+		"multi-line": {
+			"a = 1\nb = 2\na = 3\n",
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			hcl.Pos{Line: 3, Column: 1, Byte: 12},
+		},
+		"single-line block": {
+			"blk { x = 1, x = 2 }\n",
+			hcl.Pos{Line: 1, Column: 7, Byte: 6},
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos, OptMultiArgumentBlocks())
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			extra, ok := hcl.DiagnosticExtra[hcl.DuplicateDiagExtra](diags[0])
+			if !ok {
+				t.Fatalf("diagnostic has no DuplicateDiagExtra")
+			}
+			if got := extra.PreviousDefinitionRange().Start; got != test.wantPrev {
+				t.Errorf("wrong previous definition %#v; want %#v", got, test.wantPrev)
+			}
+			if got := diags[0].Subject.Start; got != test.wantSubject {
+				t.Errorf("wrong subject %#v; want %#v", got, test.wantSubject)
+			}
+		})
+	}
+}
+
+func TestParseConfigWithContext(t *testing.T) {
+	var buf strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&buf, "a%d = [%d, %d]\n", i, i, i+1)
+	}
+	src := []byte(buf.String())
+
+	t.Run("not canceled", func(t *testing.T) {
+		file, diags := ParseConfigWithContext(context.Background(), src, "test.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		if got, want := len(file.Body.(*Body).Attributes), 10000; got != want {
+			t.Errorf("wrong number of attributes %d; want %d", got, want)
+		}
+	})
+
+	t.Run("canceled before parsing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		file, diags := ParseConfigWithContext(ctx, src, "test.hcl", hcl.InitialPos)
+		if len(diags) != 1 || diags[0].Summary != "Parsing canceled" {
+			t.Fatalf("wrong diagnostics: %s", diags.Error())
+		}
+		if !errors.Is(diags, context.Canceled) {
+			t.Error("diagnostics do not match context.Canceled")
+		}
+		var syntaxErr *hcl.SyntaxError
+		if errors.As(diags, &syntaxErr) {
+			t.Errorf("cancellation reported as a syntax error: %s", syntaxErr)
+		}
+		if got := len(file.Body.(*Body).Attributes); got != 0 {
+			t.Errorf("wrong number of attributes %d; want 0", got)
+		}
+	})
+
+	t.Run("canceled while parsing", func(t *testing.T) {
+		ctx := &cancelAfterContext{Context: context.Background(), remaining: 20}
+		file, diags := ParseConfigWithContext(ctx, src, "test.hcl", hcl.InitialPos)
+		if len(diags) != 1 || diags[0].Summary != "Parsing canceled" {
+			t.Fatalf("wrong diagnostics: %s", diags.Error())
+		}
+		if got := len(file.Body.(*Body).Attributes); got == 0 || got == 10000 {
+			t.Errorf("wrong number of attributes %d; want a partial result", got)
+		}
+	})
+
+	t.Run("expression", func(t *testing.T) {
+		ctx := &cancelAfterContext{Context: context.Background(), remaining: 1}
+		expr := "[" + strings.Repeat("1, ", 10000) + "]"
+		_, diags := ParseExpressionWithContext(ctx, []byte(expr), "test.hcl", hcl.InitialPos)
+		if !diags.HasErrors() || diags[0].Summary != "Parsing canceled" {
+			t.Fatalf("wrong diagnostics: %s", diags.Error())
+		}
+	})
+}
+
+// cancelAfterContext is a context that reports itself as canceled after its
+// Err method has been called a given number of times, so that cancellation
+// part way through parsing can be tested deterministically.
+type cancelAfterContext struct {
+	context.Context
+	remaining int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.remaining <= 0 {
+		return context.Canceled
+	}
+	c.remaining--
+	return nil
+}
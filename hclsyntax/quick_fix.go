@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// SuggestedEdit describes a change to the source code that would fix the
+// problem reported by a diagnostic: the text in Range is replaced with
+// Replacement. An empty range indicates an insertion.
+type SuggestedEdit struct {
+	Range       hcl.Range
+	Replacement string
+}
+
+// TextEdit returns the equivalent TextEdit, for use with TextEdit.Apply or
+// ReparseConfig.
+func (e SuggestedEdit) TextEdit() TextEdit {
+	return TextEdit{
+		Offset:    e.Range.Start.Byte,
+		OldLength: e.Range.End.Byte - e.Range.Start.Byte,
+		NewText:   []byte(e.Replacement),
+	}
+}
+
+// QuickFixDiagExtra is an interface implemented by the value in the "Extra"
+// field of diagnostics reporting some common mistakes, such as a missing
+// closing quote or brace, or a colon used in place of an equals sign, for
+// which an editor integration can offer a fix.
+type QuickFixDiagExtra interface {
+	// SuggestedEdits returns the edits that together would fix the problem,
+	// or nil if there is no fix. The edits do not overlap.
+	SuggestedEdits() []SuggestedEdit
+}
+
+func (e *errorCodeDiagExtra) SuggestedEdits() []SuggestedEdit {
+	return e.Edits
+}
+
+// DiagnosticSuggestedEdits returns the edits suggested to fix the problem
+// reported by the given diagnostic, or nil if there are none.
+func DiagnosticSuggestedEdits(diag *hcl.Diagnostic) []SuggestedEdit {
+	extra, ok := hcl.DiagnosticExtra[QuickFixDiagExtra](diag)
+	if !ok {
+		return nil
+	}
+	return extra.SuggestedEdits()
+}
+
+// quickFixExtra returns an Extra value for a diagnostic with the given code
+// and suggested edits.
+func quickFixExtra(code ErrorCode, edits ...SuggestedEdit) *errorCodeDiagExtra {
+	return &errorCodeDiagExtra{Code: code, Edits: edits}
+}
+
+// insertAt returns a SuggestedEdit that inserts the given text at the given
+// position.
+func insertAt(filename string, pos hcl.Pos, text string) SuggestedEdit {
+	return SuggestedEdit{
+		Range:       hcl.Range{Filename: filename, Start: pos, End: pos},
+		Replacement: text,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDiagnosticSuggestedEdits(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"missing closing quote": {
+			"a = \"foo\nb = 1\n",
+			"a = \"foo\"\nb = 1\n",
+		},
+		"colon instead of equals": {
+			"a: 1\n",
+			"a = 1\n",
+		},
+		"spaced colon instead of equals": {
+			"a : 1\n",
+			"a = 1\n",
+		},
+		"missing equals": {
+			"a [1]\n",
+			"a = [1]\n",
+		},
+		"missing closing brace": {
+			"a {\n  b = 1\n",
+			"a {\n  b = 1\n}\n",
+		},
+		"missing closing brace without newline": {
+			"a {",
+			"a {\n}\n",
+		},
+		"missing closing brace before misplaced item": {
+			"a {\n  b = 1\nc = 2\n",
+			"a {\n  b = 1\n}\nc = 2\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(test.src), "test.hcl", hcl.InitialPos)
+			if !diags.HasErrors() {
+				t.Fatalf("no errors")
+			}
+
+			var edits []TextEdit
+			for _, diag := range diags {
+				for _, edit := range DiagnosticSuggestedEdits(diag) {
+					edits = append(edits, edit.TextEdit())
+				}
+			}
+			if len(edits) == 0 {
+				t.Fatalf("no suggested edits in %s", diags)
+			}
+
+			// Apply the edits from last to first so that earlier offsets
+			// remain valid.
+			sort.Slice(edits, func(i, j int) bool {
+				return edits[i].Offset > edits[j].Offset
+			})
+			got := []byte(test.src)
+			for _, edit := range edits {
+				got = edit.Apply(got)
+			}
+			if string(got) != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+
+			_, diags = ParseConfig(got, "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Errorf("fixed source still has errors: %s", diags)
+			}
+		})
+	}
+}
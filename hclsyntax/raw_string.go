@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// rawStringLen returns the length of the raw string at the start of the given
+// buffer, including its delimiters. A raw string delimited by single quotes
+// must end on the same line, so an unterminated one ends before the newline,
+// while an unterminated backtick string runs to the end of the buffer.
+func rawStringLen(b []byte) int {
+	delim := b[0]
+	for i := 1; i < len(b); i++ {
+		switch {
+		case b[i] == delim:
+			return i + 1
+		case b[i] == '\n' && delim == '\'':
+			return i
+		}
+	}
+	return len(b)
+}
+
+// rawStringDiagnostics returns an error diagnostic for each of the raw
+// strings in the given tokens that has no closing delimiter.
+func rawStringDiagnostics(tokens Tokens) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, tok := range tokens {
+		if tok.Type != TokenRawStringLit || isTerminatedRawString(tok.Bytes) {
+			continue
+		}
+		rng := tok.Range
+		rng.End = posAfter(rng.Start, tok.Bytes[:1])
+		detail := "This raw string has no closing quote. A raw string delimited by single quotes must end on the same line."
+		if tok.Bytes[0] == '`' {
+			detail = "This raw string has no closing backtick."
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unterminated raw string",
+			Detail:   detail,
+			Subject:  &rng,
+			Extra:    errorCodeExtra(ErrUnterminatedRawString),
+		})
+	}
+	return diags
+}
+
+// isTerminatedRawString returns true if the given raw string, including its
+// delimiters, has a closing delimiter.
+func isTerminatedRawString(b []byte) bool {
+	return len(b) >= 2 && b[len(b)-1] == b[0]
+}
+
+// posAfter returns the position after the given bytes, which are assumed to
+// begin at the given position, counting columns in the same way as the
+// scanner does.
+func posAfter(pos hcl.Pos, b []byte) hcl.Pos {
+	for len(b) > 0 {
+		adv, seq, _ := textseg.ScanGraphemeClusters(b, true)
+		pos.Byte += adv
+		if seq[0] == '\n' || (len(seq) > 1 && seq[0] == '\r' && seq[1] == '\n') {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+		b = b[adv:]
+	}
+	return pos
+}
+
+// rawStringContent returns the content of the given TokenRawStringLit token,
+// without its delimiters.
+func rawStringContent(tok Token) []byte {
+	b := tok.Bytes
+	if len(b) >= 2 && b[len(b)-1] == b[0] {
+		return b[1 : len(b)-1]
+	}
+	return b[1:]
+}
@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestParseConfigRawStrings(t *testing.T) {
+	src := "pattern = '^\\d+\"${x}'\n" +
+		"path = `C:\\Temp\\é`\n" +
+		"multi = `a\n'b'\n# c`\n" +
+		"list = ['a', `b`, '`']\n" +
+		"tmpl = \"${'$'}{x}\"\n"
+
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptRawStrings())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	want := map[string]cty.Value{
+		"pattern": cty.StringVal(`^\d+"${x}`),
+		"path":    cty.StringVal(`C:\Temp\é`),
+		"multi":   cty.StringVal("a\n'b'\n# c"),
+		"list":    cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("`")}),
+		"tmpl":    cty.StringVal("${x}"),
+	}
+	if len(attrs) != len(want) {
+		t.Fatalf("wrong number of attributes %d; want %d", len(attrs), len(want))
+	}
+	for name, wantVal := range want {
+		got, diags := attrs[name].Expr.Value(nil)
+		if diags.HasErrors() {
+			t.Errorf("unexpected diagnostics for %s: %s", name, diags.Error())
+			continue
+		}
+		if !got.RawEquals(wantVal) {
+			t.Errorf("wrong value for %s\ngot:  %#v\nwant: %#v", name, got, wantVal)
+		}
+	}
+
+	// The attribute after the multi-byte character must still have the
+	// correct column, even though the scanner saw only ASCII spaces there.
+	if got, want := attrs["path"].Expr.Range().End, (hcl.Pos{Line: 2, Column: 19, Byte: 41}); got != want {
+		t.Errorf("wrong end for path\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := attrs["list"].Expr.Range().End, (hcl.Pos{Line: 6, Column: 23, Byte: 84}); got != want {
+		t.Errorf("wrong end for list\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestLexConfigRawStrings(t *testing.T) {
+	src := "a = 'é' + `b`"
+	tokens, diags := LexConfig([]byte(src), "test.hcl", hcl.InitialPos, OptRawStrings())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []TokenType
+	for _, tok := range tokens {
+		got = append(got, tok.Type)
+	}
+	want := []TokenType{TokenIdent, TokenEqual, TokenRawStringLit, TokenPlus, TokenRawStringLit, TokenEOF}
+	if len(got) != len(want) {
+		t.Fatalf("wrong tokens\ngot:  %s\nwant: %s", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong tokens\ngot:  %s\nwant: %s", got, want)
+		}
+	}
+
+	if got, want := string(tokens[4].Bytes), "`b`"; got != want {
+		t.Errorf("wrong bytes %q; want %q", got, want)
+	}
+	if got, want := tokens[4].Range.Start.Column, 11; got != want {
+		t.Errorf("wrong start column %d; want %d", got, want)
+	}
+	if got, want := tokens[3].Range.Start.Column, 9; got != want {
+		t.Errorf("wrong start column for plus %d; want %d", got, want)
+	}
+}
+
+func TestParseConfigRawStringsMany(t *testing.T) {
+	// Each raw string contains a quote, which would otherwise begin a
+	// quoted string that consumes the rest of the file, and each is
+	// followed by a comment spanning several lines.
+	var buf strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&buf, "a%d = 'say \"%d' /* a\n\n*/ # '\n", i, i)
+	}
+	file, diags := ParseConfig([]byte(buf.String()), "test.hcl", hcl.InitialPos, OptRawStrings())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if got, want := len(attrs), 200; got != want {
+		t.Fatalf("wrong number of attributes %d; want %d", got, want)
+	}
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("a%d", i)
+		got, _ := attrs[name].Expr.Value(nil)
+		if want := cty.StringVal(fmt.Sprintf(`say "%d`, i)); !got.RawEquals(want) {
+			t.Errorf("wrong value for %s\ngot:  %#v\nwant: %#v", name, got, want)
+		}
+		if got, want := attrs[name].Range.Start.Line, 3*i+1; got != want {
+			t.Errorf("wrong line for %s %d; want %d", name, got, want)
+		}
+	}
+}
+
+func TestParseConfigRawStringsUnterminated(t *testing.T) {
+	src := "a = 'foo\nb = 1\n"
+	_, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptRawStrings())
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if got, want := diags[0].Summary, "Unterminated raw string"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diags[0].Subject.Start.Byte, 4; got != want {
+		t.Errorf("wrong subject start byte %d; want %d", got, want)
+	}
+}
+
+func TestParseConfigRawStringsDisabled(t *testing.T) {
+	_, diags := ParseConfig([]byte("a = 'foo'\n"), "test.hcl", hcl.InitialPos)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags[0].Summary, "Invalid character"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Reference is a reference to a variable in the root scope, as returned by
+// References.
+type Reference struct {
+	// Traversal is the reference itself, such as "var.name" or
+	// "aws_instance.web.id". Its source range is the position of the
+	// reference.
+	Traversal hcl.Traversal
+
+	// Attribute is the attribute whose expression contains the reference.
+	Attribute *Attribute
+
+	// Blocks are the blocks that contain the attribute, starting with the
+	// outermost. It is empty for attributes of the given body itself.
+	Blocks []*Block
+}
+
+// Range returns the source range of the reference.
+func (r Reference) Range() hcl.Range {
+	return r.Traversal.SourceRange()
+}
+
+// References returns all of the references to variables in the root scope
+// from the expressions of the attributes within the given body, including
+// those within nested blocks, in the order they appear in the source code.
+// As with Variables, references to the local symbols of for expressions and
+// splat expressions are not included.
+//
+// This allows applications to analyze the dependencies between the parts
+// of a configuration, such as to build a dependency graph, without
+// evaluating any expressions. References finds only the references that
+// appear in the source code, so it's up to the application to decide how
+// the references relate to the blocks that they refer to.
+func References(body *Body) []Reference {
+	var refs []Reference
+	collectReferences(body, nil, &refs)
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].Range().Start.Byte < refs[j].Range().Start.Byte
+	})
+	return refs
+}
+
+func collectReferences(body *Body, blocks []*Block, refs *[]Reference) {
+	for _, attr := range body.Attributes {
+		for _, traversal := range Variables(attr.Expr) {
+			*refs = append(*refs, Reference{
+				Traversal: traversal,
+				Attribute: attr,
+				Blocks:    blocks,
+			})
+		}
+	}
+	for _, block := range body.Blocks {
+		// The nested blocks are given a slice of their own, so that the
+		// references in sibling blocks can't share an underlying array.
+		nested := make([]*Block, len(blocks), len(blocks)+1)
+		copy(nested, blocks)
+		collectReferences(block.Body, append(nested, block), refs)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestReferences(t *testing.T) {
+	src := `name = "${var.prefix}-web"
+
+resource "instance" "web" {
+  count = length(var.zones)
+  zone  = var.zones[count.index]
+
+  network {
+    id = resource.network.main.id
+  }
+  tags = [for t in local.tags : t.name]
+}
+`
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	type ref struct {
+		name   string
+		attr   string
+		blocks int
+		start  hcl.Pos
+	}
+	want := []ref{
+		{"var.prefix", "name", 0, hcl.Pos{Line: 1, Column: 11, Byte: 10}},
+		{"var.zones", "count", 1, hcl.Pos{Line: 4, Column: 18, Byte: 73}},
+		{"var.zones", "zone", 1, hcl.Pos{Line: 5, Column: 11, Byte: 94}},
+		{"count.index", "zone", 1, hcl.Pos{Line: 5, Column: 21, Byte: 104}},
+		{"resource.network.main.id", "id", 2, hcl.Pos{Line: 8, Column: 10, Byte: 139}},
+		{"local.tags", "tags", 1, hcl.Pos{Line: 10, Column: 20, Byte: 187}},
+	}
+
+	refs := References(file.Body.(*Body))
+	if len(refs) != len(want) {
+		t.Fatalf("wrong number of references %d; want %d", len(refs), len(want))
+	}
+	for i, r := range refs {
+		got := ref{
+			name:   traversalString(r.Traversal),
+			attr:   r.Attribute.Name,
+			blocks: len(r.Blocks),
+			start:  r.Range().Start,
+		}
+		if got != want[i] {
+			t.Errorf("wrong reference %d\ngot:  %#v\nwant: %#v", i, got, want[i])
+		}
+	}
+	if got := refs[4].Blocks[1].Type; got != "network" {
+		t.Errorf("innermost block of reference 4 is %q; want network", got)
+	}
+}
+
+func traversalString(traversal hcl.Traversal) string {
+	var ret string
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			ret += step.Name
+		case hcl.TraverseAttr:
+			ret += "." + step.Name
+		}
+	}
+	return ret
+}
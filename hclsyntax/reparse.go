@@ -0,0 +1,308 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// TextEdit describes a change to a source buffer: the OldLength bytes
+// starting at byte offset Offset are replaced with NewText.
+type TextEdit struct {
+	Offset    int
+	OldLength int
+	NewText   []byte
+}
+
+// Apply returns a new buffer containing the result of applying the edit to
+// the given source buffer, which is not modified. The edit must be within
+// the bounds of the buffer.
+func (e TextEdit) Apply(src []byte) []byte {
+	ret := make([]byte, 0, len(src)-e.OldLength+len(e.NewText))
+	ret = append(ret, src[:e.Offset]...)
+	ret = append(ret, e.NewText...)
+	ret = append(ret, src[e.Offset+e.OldLength:]...)
+	return ret
+}
+
+// ReparseConfig applies the given edit to the source of a file previously
+// returned by ParseConfig or ReparseConfig, and returns a file representing
+// the edited source.
+//
+// Rather than parsing the whole edited source again, ReparseConfig finds the
+// innermost attribute or block that contains the edit and that is alone on
+// its lines, re-lexes and re-parses only the lines it occupies, and patches
+// the result into the existing syntax tree, adjusting the source ranges of
+// everything that follows. This keeps editor integrations responsive on
+// large files, where most edits touch only a small part of the file. If
+// the edit cannot be handled in that way, such as when it falls between
+// items or changes the structure of the surrounding body, then the whole
+// edited source is parsed again with ParseConfig.
+//
+// The previous file must have been parsed without errors, from the start of
+// its buffer, and with the same options that are given here. Its syntax
+// tree is modified in place and shared with the returned file, so the
+// previous file must not be used after calling ReparseConfig. The edit
+// offsets are in terms of the previous file's Bytes.
+//
+// When only part of the file is parsed again, the returned diagnostics
+// describe only that part. Since that part must parse without errors for
+// the result to be used, any errors in the returned diagnostics always
+// relate to the file as a whole.
+func ReparseConfig(prev *hcl.File, edit TextEdit, opts ...ParseOption) (*hcl.File, hcl.Diagnostics) {
+	body, ok := prev.Body.(*Body)
+	if !ok {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported file",
+				Detail:   "Only files produced by the native syntax parser can be incrementally reparsed.",
+			},
+		}
+	}
+	filename := body.SrcRange.Filename
+	if edit.Offset < 0 || edit.OldLength < 0 || edit.Offset+edit.OldLength > len(prev.Bytes) {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid edit",
+				Detail: fmt.Sprintf(
+					"Cannot replace %d bytes at offset %d in %s, which is only %d bytes long.",
+					edit.OldLength, edit.Offset, filename, len(prev.Bytes),
+				),
+			},
+		}
+	}
+
+	src := edit.Apply(prev.Bytes)
+	for _, c := range reparseCandidates(body, edit) {
+		if diags, ok := reparseItem(prev.Bytes, src, body, c, edit, opts); ok {
+			return &hcl.File{
+				Body:  body,
+				Bytes: src,
+
+				Nav: navigation{
+					root: body,
+				},
+			}, diags
+		}
+	}
+
+	return ParseConfig(src, filename, hcl.InitialPos, opts...)
+}
+
+// reparseCandidate is an attribute or block that contains an edit, along
+// with the body it belongs to.
+type reparseCandidate struct {
+	parent *Body
+	item   Node
+}
+
+// reparseCandidates returns the items that contain the given edit, from
+// innermost to outermost.
+func reparseCandidates(body *Body, edit TextEdit) []reparseCandidate {
+	var ret []reparseCandidate
+	editEnd := edit.Offset + edit.OldLength
+	for {
+		var next *Body
+		for _, attr := range body.Attributes {
+			rng := attr.SrcRange
+			if rng.Start.Byte <= edit.Offset && editEnd <= rng.End.Byte {
+				ret = append(ret, reparseCandidate{body, attr})
+			}
+		}
+		for _, block := range body.Blocks {
+			rng := block.Range()
+			if rng.Start.Byte <= edit.Offset && editEnd <= rng.End.Byte {
+				ret = append(ret, reparseCandidate{body, block})
+				if block.OpenBraceRange.End.Byte <= edit.Offset && editEnd <= block.CloseBraceRange.Start.Byte {
+					next = block.Body
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		body = next
+	}
+
+	// Reverse, so that the innermost item is tried first.
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
+
+// reparseItem attempts to parse again the lines occupied by the given
+// candidate, returning false if the result cannot be patched into the
+// existing syntax tree. The tree is modified only if it returns true.
+func reparseItem(oldSrc, newSrc []byte, root *Body, c reparseCandidate, edit TextEdit, opts []ParseOption) (hcl.Diagnostics, bool) {
+	rng := c.item.Range()
+
+	// The item must be alone on its lines, so that the lines can be parsed
+	// as a body in their own right.
+	lineStart := rng.Start.Byte
+	for lineStart > 0 && oldSrc[lineStart-1] != '\n' {
+		lineStart--
+		if ch := oldSrc[lineStart]; ch != ' ' && ch != '\t' {
+			return nil, false
+		}
+	}
+	oldEnd := len(oldSrc)
+	if i := bytes.IndexByte(oldSrc[rng.End.Byte:], '\n'); i >= 0 {
+		oldEnd = rng.End.Byte + i + 1
+	}
+	delta := len(edit.NewText) - edit.OldLength
+	newEnd := oldEnd + delta
+
+	start := hcl.Pos{Byte: lineStart, Line: rng.Start.Line, Column: 1}
+	file, diags := ParseConfig(newSrc[lineStart:newEnd], rng.Filename, start, opts...)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	segment := file.Body.(*Body)
+
+	var replacement Node
+	switch old := c.item.(type) {
+	case *Attribute:
+		if len(segment.Attributes) != 1 || len(segment.Blocks) != 0 {
+			return nil, false
+		}
+		for _, attr := range segment.Attributes {
+			if existing, exists := c.parent.Attributes[attr.Name]; exists && existing != old {
+				// Would be a duplicate, which needs the full parser's error.
+				return nil, false
+			}
+			replacement = attr
+		}
+	case *Block:
+		if len(segment.Attributes) != 0 || len(segment.Blocks) != 1 {
+			return nil, false
+		}
+		replacement = segment.Blocks[0]
+	}
+
+	shift := rangeShift{
+		oldEnd:       oldEnd,
+		delta:        delta,
+		deltaLines:   bytes.Count(edit.NewText, []byte{'\n'}) - bytes.Count(oldSrc[edit.Offset:edit.Offset+edit.OldLength], []byte{'\n'}),
+		endsWithLine: oldSrc[oldEnd-1] == '\n',
+		newEndPos:    segment.SrcRange.End,
+		seen:         make(map[uintptr]bool),
+	}
+	shift.value(reflect.ValueOf(root))
+
+	switch old := c.item.(type) {
+	case *Attribute:
+		attr := replacement.(*Attribute)
+		delete(c.parent.Attributes, old.Name)
+		c.parent.Attributes[attr.Name] = attr
+	case *Block:
+		for i, block := range c.parent.Blocks {
+			if block == old {
+				c.parent.Blocks[i] = replacement.(*Block)
+			}
+		}
+	}
+	return diags, true
+}
+
+// rangeShift adjusts the source ranges in a syntax tree that follow the
+// region of the source that was parsed again.
+type rangeShift struct {
+	oldEnd       int
+	delta        int
+	deltaLines   int
+	endsWithLine bool
+	newEndPos    hcl.Pos
+	seen         map[uintptr]bool
+}
+
+func (s *rangeShift) pos(pos hcl.Pos) hcl.Pos {
+	if pos.Byte < s.oldEnd {
+		return pos
+	}
+	if !s.endsWithLine {
+		// The region ran to the end of the source without a final newline,
+		// so the only positions after it are at the very end.
+		return s.newEndPos
+	}
+	pos.Byte += s.delta
+	pos.Line += s.deltaLines
+	return pos
+}
+
+// value adjusts all of the ranges reachable through the exported fields
+// of the given value, which must be settable unless it is a pointer, map,
+// slice or interface.
+func (s *rangeShift) value(v reflect.Value) {
+	switch v.Type() {
+	case rangeType:
+		rng := v.Interface().(hcl.Range)
+		rng.Start = s.pos(rng.Start)
+		rng.End = s.pos(rng.End)
+		v.Set(reflect.ValueOf(rng))
+		return
+	case posType:
+		v.Set(reflect.ValueOf(s.pos(v.Interface().(hcl.Pos))))
+		return
+	case ctyValueType, ctyTypeType, operationType:
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || s.seen[v.Pointer()] {
+			return
+		}
+		s.seen[v.Pointer()] = true
+		s.value(v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Ptr {
+			s.value(elem)
+			return
+		}
+		// Values held directly in interfaces, such as the steps of a
+		// traversal, can't be modified in place.
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		s.value(cp)
+		v.Set(cp)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue // unexported
+			}
+			s.value(v.Field(i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			s.value(v.Index(i))
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := iter.Value()
+			cp := reflect.New(elem.Type()).Elem()
+			cp.Set(elem)
+			s.value(cp)
+			v.SetMapIndex(iter.Key(), cp)
+		}
+	}
+}
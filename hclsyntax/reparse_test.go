@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestReparseConfig(t *testing.T) {
+	const src = `a = 1
+b = "hello ${name}"
+
+block "label" {
+  inner = [1, 2, 3]
+
+  nested {
+    x = foo.bar[0]
+  }
+  single { y = 2 }
+}
+
+other {
+  z = <<EOT
+heredoc
+EOT
+}
+c = 3`
+
+	tests := map[string]struct {
+		old, new string
+		partial  bool
+	}{
+		"change attribute value": {
+			"a = 1", "a = 12345", true,
+		},
+		"rename attribute": {
+			"a = 1", "aa = 1", true,
+		},
+		"add lines inside nested attribute": {
+			"x = foo.bar[0]", "x = {\n      k = v\n    }", true,
+		},
+		"edit template": {
+			"hello ${name}", "hi ${name}!", true,
+		},
+		"edit single-line block": {
+			"y = 2", "y = 22", true,
+		},
+		"edit heredoc": {
+			"heredoc", "heredoc\nmore", true,
+		},
+		"edit last line without newline": {
+			"c = 3", "c = 300", true,
+		},
+		"rename block": {
+			"nested {", "renamed {", true,
+		},
+		"insert between items": {
+			"a = 1\n", "a = 1\nnew = true\n", false,
+		},
+		"duplicate attribute": {
+			"a = 1", "c = 1", false,
+		},
+		"split attribute": {
+			"a = 1", "a = 1\nd = 2", false,
+		},
+		"unbalanced brace": {
+			"inner = [1, 2, 3]", "inner = [1, 2, 3", false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prev, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			prevBody := prev.Body.(*Body)
+			otherBlock := prevBody.Blocks[1]
+
+			offset := strings.Index(src, test.old)
+			edit := TextEdit{Offset: offset, OldLength: len(test.old), NewText: []byte(test.new)}
+			got, gotDiags := ReparseConfig(prev, edit)
+			want, wantDiags := ParseConfig(edit.Apply([]byte(src)), "test.hcl", hcl.InitialPos)
+
+			if string(got.Bytes) != string(want.Bytes) {
+				t.Errorf("wrong bytes\ngot:  %q\nwant: %q", got.Bytes, want.Bytes)
+			}
+			if gotDiags.HasErrors() != wantDiags.HasErrors() {
+				t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", gotDiags.Error(), wantDiags.Error())
+			}
+			if got, want := Dump(got.Body.(*Body)), Dump(want.Body.(*Body)); got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+
+			if partial := got.Body.(*Body) == prevBody; partial != test.partial {
+				t.Errorf("wrong partial %t; want %t", partial, test.partial)
+			}
+			if test.partial && !strings.Contains(test.old, "other") && got.Body.(*Body).Blocks[1] != otherBlock {
+				t.Errorf("unaffected block was not reused")
+			}
+		})
+	}
+}
+
+func TestReparseConfigInvalidEdit(t *testing.T) {
+	prev, _ := ParseConfig([]byte("a = 1\n"), "test.hcl", hcl.InitialPos)
+	_, diags := ReparseConfig(prev, TextEdit{Offset: 4, OldLength: 10})
+	if !diags.HasErrors() {
+		t.Fatalf("expected an error for an out-of-bounds edit")
+	}
+	if got, want := diags[0].Summary, "Invalid edit"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// benchmarkConfig returns a configuration resembling a large real-world
+// Terraform module, built from n copies of a representative set of
+// variables, resources, and outputs.
+func benchmarkConfig(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, `# Network resources for zone %[1]d
+variable "subnet_cidr_%[1]d" {
+  type        = string
+  description = "CIDR block for subnet %[1]d"
+  default     = "10.%[1]d.0.0/16"
+}
+
+resource "aws_subnet" "zone_%[1]d" {
+  vpc_id            = aws_vpc.main.id
+  cidr_block        = var.subnet_cidr_%[1]d
+  availability_zone = data.aws_availability_zones.available.names[%[1]d %% 3]
+
+  tags = merge(local.common_tags, {
+    Name = "${var.name_prefix}-zone-%[1]d"
+    Tier = count.index > 1 ? "private" : "public"
+  })
+}
+
+resource "aws_instance" "web_%[1]d" {
+  count         = var.instance_count
+  ami           = data.aws_ami.ubuntu.id
+  instance_type = "t3.micro"
+  subnet_id     = aws_subnet.zone_%[1]d.id
+
+  vpc_security_group_ids = [for sg in aws_security_group.web : sg.id if sg.name != ""]
+
+  root_block_device {
+    volume_size = 20
+    encrypted   = true
+  }
+
+  user_data = <<-EOT
+    #!/bin/bash
+    echo "zone %[1]d" > /etc/zone
+    systemctl start ${var.service_name}
+  EOT
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = [tags["LastModified"]]
+  }
+}
+
+output "instance_ips_%[1]d" {
+  value = aws_instance.web_%[1]d[*].private_ip
+}
+
+`, i)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkLexConfigLarge(b *testing.B) {
+	src := benchmarkConfig(500)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var tokens Tokens
+	for i := 0; i < b.N; i++ {
+		tokens, _ = LexConfig(src, "main.tf", hcl.InitialPos)
+	}
+
+	T = tokens
+}
+
+// BenchmarkLexConfigHeredoc measures a source made mostly of a long heredoc,
+// which has far fewer tokens for its size than typical configuration.
+func BenchmarkLexConfigHeredoc(b *testing.B) {
+	src := []byte("doc = <<EOT\n" + strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n", 10000) + "EOT\n")
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var tokens Tokens
+	for i := 0; i < b.N; i++ {
+		tokens, _ = LexConfig(src, "main.tf", hcl.InitialPos)
+	}
+
+	T = tokens
+}
+
+// BenchmarkLexConfigRawStrings measures a source with many raw strings that
+// each contain a quote.
+func BenchmarkLexConfigRawStrings(b *testing.B) {
+	src := []byte(strings.Repeat("pattern = '^[a-z]+\"$' # match quoted\n", 5000))
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var tokens Tokens
+	for i := 0; i < b.N; i++ {
+		tokens, _ = LexConfig(src, "main.tf", hcl.InitialPos, OptRawStrings())
+	}
+
+	T = tokens
+}
+
+func BenchmarkParseConfigLarge(b *testing.B) {
+	src := benchmarkConfig(500)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, diags := ParseConfig(src, "main.tf", hcl.InitialPos)
+		if diags.HasErrors() {
+			b.Fatal(diags.Error())
+		}
+	}
+}
+
+func TestBenchmarkConfigValid(t *testing.T) {
+	_, diags := ParseConfig(benchmarkConfig(2), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+}
@@ -4219,16 +4219,21 @@ const hcltok_en_main int = 1459
 
 //line scan_tokens.rl:18
 
-func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []Token {
+func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode, opts scanOpts) []Token {
 	stripData := stripUTF8BOM(data)
 	start.Byte += len(data) - len(stripData)
 	data = stripData
 
+	// The scanner looks up transitions in a table that is built on first
+	// use, as described in scan_tokens_table.go.
+	hcltokTableOnce.Do(loadScanTable)
+
 	f := &tokenAccum{
 		Filename:  filename,
 		Bytes:     data,
 		Pos:       start,
 		StartByte: start.Byte,
+		Tokens:    opts.tokenBuffer(data),
 	}
 
 //line scan_tokens.rl:317
@@ -4267,8 +4272,17 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 	_ = act
 	_ = eof
 
+	// stopIfTooLong ends the scan after a token longer than the maximum
+	// length, since the input will be rejected and so there's no need to
+	// scan the rest of it.
+	stopIfTooLong := func() {
+		if opts.maxTokenLength > 0 && te-ts > opts.maxTokenLength {
+			p = pe - 1
+		}
+	}
 	token := func(ty TokenType) {
 		f.emitToken(ty, ts, te)
+		stopIfTooLong()
 	}
 	selfToken := func() {
 		b := data[ts:te]
@@ -4276,6 +4290,15 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 			// should never happen
 			panic("selfToken only works for single-character tokens")
 		}
+		if opts.rawStrings && (b[0] == '\'' || b[0] == '`') {
+			// Raw strings are not part of the native syntax, so the machine
+			// has no rule for them. Instead we consume the whole string here
+			// and the scan resumes after it.
+			te = ts + rawStringLen(data[ts:])
+			p = te - 1
+			token(TokenRawStringLit)
+			return
+		}
 		f.emitToken(TokenType(b[0]), ts, te)
 	}
 
@@ -4289,11 +4312,9 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 
 //line scan_tokens.go:4300
 	{
-		var _klen int
 		var _trans int
 		var _acts int
 		var _nacts uint
-		var _keys int
 		if p == pe {
 			goto _test_eof
 		}
@@ -4315,60 +4336,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 			}
 		}
 
-		_keys = int(_hcltok_key_offsets[cs])
-		_trans = int(_hcltok_index_offsets[cs])
-
-		_klen = int(_hcltok_single_lengths[cs])
-		if _klen > 0 {
-			_lower := int(_keys)
-			var _mid int
-			_upper := int(_keys + _klen - 1)
-			for {
-				if _upper < _lower {
-					break
-				}
-
-				_mid = _lower + ((_upper - _lower) >> 1)
-				switch {
-				case data[p] < _hcltok_trans_keys[_mid]:
-					_upper = _mid - 1
-				case data[p] > _hcltok_trans_keys[_mid]:
-					_lower = _mid + 1
-				default:
-					_trans += int(_mid - int(_keys))
-					goto _match
-				}
-			}
-			_keys += _klen
-			_trans += _klen
-		}
-
-		_klen = int(_hcltok_range_lengths[cs])
-		if _klen > 0 {
-			_lower := int(_keys)
-			var _mid int
-			_upper := int(_keys + (_klen << 1) - 2)
-			for {
-				if _upper < _lower {
-					break
-				}
-
-				_mid = _lower + (((_upper - _lower) >> 1) & ^1)
-				switch {
-				case data[p] < _hcltok_trans_keys[_mid]:
-					_upper = _mid - 2
-				case data[p] > _hcltok_trans_keys[_mid+1]:
-					_lower = _mid + 2
-				default:
-					_trans += int((_mid - int(_keys)) >> 1)
-					goto _match
-				}
-			}
-			_trans += _klen
-		}
-
-	_match:
-		_trans = int(_hcltok_indicies[_trans])
+		_trans = int(hcltokTrans[cs*hcltokClassCount+int(hcltokClasses[data[p]])])
 	_eof_trans:
 		cs = int(_hcltok_trans_targs[_trans])
 
@@ -5246,7 +5214,7 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
 		}
 	}
 
-//line scan_tokens.rl:375
+//line scan_tokens.rl:393
 
 	// If we fall out here without being in a final state then we've
 	// encountered something that the scanner can't match, which we'll
@@ -17,16 +17,21 @@ import (
   write data;
 }%%
 
-func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []Token {
+func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode, opts scanOpts) []Token {
     stripData := stripUTF8BOM(data)
     start.Byte += len(data) - len(stripData)
     data = stripData
 
+    // The scanner looks up transitions in a table that is built on first
+    // use, as described in scan_tokens_table.go.
+    hcltokTableOnce.Do(loadScanTable)
+
     f := &tokenAccum{
         Filename:  filename,
         Bytes:     data,
         Pos:       start,
         StartByte: start.Byte,
+        Tokens:    opts.tokenBuffer(data),
     }
 
     %%{
@@ -357,8 +362,17 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
     _ = act
     _ = eof
 
+    // stopIfTooLong ends the scan after a token longer than the maximum
+    // length, since the input will be rejected and so there's no need to
+    // scan the rest of it.
+    stopIfTooLong := func () {
+        if opts.maxTokenLength > 0 && te-ts > opts.maxTokenLength {
+            p = pe - 1
+        }
+    }
     token := func (ty TokenType) {
         f.emitToken(ty, ts, te)
+        stopIfTooLong()
     }
     selfToken := func () {
         b := data[ts:te]
@@ -366,6 +380,15 @@ func scanTokens(data []byte, filename string, start hcl.Pos, mode scanMode) []To
             // should never happen
             panic("selfToken only works for single-character tokens")
         }
+        if opts.rawStrings && (b[0] == '\'' || b[0] == '`') {
+            // Raw strings are not part of the native syntax, so the machine
+            // has no rule for them. Instead we consume the whole string here
+            // and the scan resumes after it.
+            te = ts + rawStringLen(data[ts:])
+            p = te - 1
+            token(TokenRawStringLit)
+            return
+        }
         f.emitToken(TokenType(b[0]), ts, te)
     }
 
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// This is a 'go generate'-oriented program that rewrites the scanner that
+// Ragel generates in scan_tokens.go to find the transition for each byte
+// using the dense table described in scan_tokens_table.go, rather than
+// using a binary search. It must run after Ragel and gofmt.
+
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+const filename = "scan_tokens.go"
+
+// The generated code we replace, which starts by finding the keys of the
+// current state and ends by translating the index of the matched key into
+// a transition.
+var (
+	searchStart = []byte("\t\t_keys = int(_hcltok_key_offsets[cs])\n")
+	searchEnd   = []byte("\t_match:\n\t\t_trans = int(_hcltok_indicies[_trans])\n")
+
+	lookup = []byte("\t\t_trans = int(hcltokTrans[cs*hcltokClassCount+int(hcltokClasses[data[p]])])\n")
+
+	// The variables that only the binary search used.
+	unusedVars = [][]byte{
+		[]byte("\t\tvar _klen int\n"),
+		[]byte("\t\tvar _keys int\n"),
+	}
+)
+
+func main() {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		os.Exit(1)
+	}
+
+	start := bytes.Index(src, searchStart)
+	end := bytes.Index(src, searchEnd)
+	if start < 0 || end < start {
+		fmt.Fprintf(os.Stderr, "%s does not contain the expected transition search; it must be regenerated by Ragel first\n", filename)
+		os.Exit(1)
+	}
+	end += len(searchEnd)
+
+	var buf bytes.Buffer
+	buf.Write(src[:start])
+	buf.Write(lookup)
+	buf.Write(src[end:])
+	src = buf.Bytes()
+	for _, v := range unusedVars {
+		src = bytes.Replace(src, v, nil, 1)
+	}
+
+	if err := os.WriteFile(filename, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", filename, err)
+		os.Exit(1)
+	}
+}
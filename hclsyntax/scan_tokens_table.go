@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"sync"
+)
+
+// The scanner generated by Ragel from scan_tokens.rl finds the transition
+// for each byte of input with a binary search of the keys of the current
+// state, which dominates the time taken to scan. Ragel's faster code styles
+// produce a very large amount of Go code for a machine of this size, so
+// instead we derive from Ragel's tables a dense table that gives the
+// transition for each state and byte directly, and scan_tokens_fast.go
+// rewrites the generated scanner to use it.
+//
+// To keep the table small, bytes are grouped into classes of bytes that
+// lead to the same transition in every state, and the table has a column
+// for each class rather than for each byte. Typical input needs very few
+// distinct rows and columns, so the part of the table in use fits easily
+// in the processor's caches.
+
+// hcltokTableOnce guards the building of the table, which happens on first
+// use so that programs that never scan don't pay for it.
+var hcltokTableOnce sync.Once
+
+// hcltokClasses gives the class of each byte.
+var hcltokClasses [256]byte
+
+// hcltokClassCount is the number of byte classes, and so the number of
+// entries in hcltokTrans for each state.
+var hcltokClassCount int
+
+// hcltokTrans gives the transition, as an index into the other tables,
+// for each state and byte class. The entry for state s and class c is at
+// s*hcltokClassCount+c.
+var hcltokTrans []int16
+
+func loadScanTable() {
+	hcltokClasses, hcltokClassCount, hcltokTrans = buildScanTable(
+		_hcltok_key_offsets,
+		_hcltok_trans_keys,
+		_hcltok_single_lengths,
+		_hcltok_range_lengths,
+		_hcltok_index_offsets,
+		_hcltok_indicies,
+	)
+}
+
+// buildScanTable produces a dense transition table, as described above,
+// from the tables that Ragel generates for its default "-T0" code style.
+func buildScanTable(keyOffsets []int16, transKeys []byte, singleLengths, rangeLengths []byte, indexOffsets []int16, indicies []int16) ([256]byte, int, []int16) {
+	states := len(keyOffsets)
+
+	// First we find the transition for each state and byte, in a column
+	// for each byte so that we can then compare the columns to find the
+	// byte classes.
+	var columns [256][]int16
+	for b := range columns {
+		columns[b] = make([]int16, states)
+	}
+	for s := 0; s < states; s++ {
+		keys := int(keyOffsets[s])
+		trans := int(indexOffsets[s])
+		singles := int(singleLengths[s])
+		ranges := int(rangeLengths[s])
+
+		var row [256]int
+		for b := range row {
+			// The transition taken when no key matches.
+			row[b] = trans + singles + ranges
+		}
+		// Ragel tries the single keys before the ranges, so single keys
+		// take priority over any range that includes them.
+		for i := 0; i < ranges; i++ {
+			lo := transKeys[keys+singles+2*i]
+			hi := transKeys[keys+singles+2*i+1]
+			for b := int(lo); b <= int(hi); b++ {
+				row[b] = trans + singles + i
+			}
+		}
+		for i := 0; i < singles; i++ {
+			row[transKeys[keys+i]] = trans + i
+		}
+
+		for b, idx := range row {
+			if idx < len(indicies) {
+				columns[b][s] = indicies[idx]
+			}
+		}
+	}
+
+	var classes [256]byte
+	var classColumns [][]int16
+	seen := make(map[string]byte)
+	for b, column := range columns {
+		key := columnKey(column)
+		class, ok := seen[key]
+		if !ok {
+			class = byte(len(classColumns))
+			seen[key] = class
+			classColumns = append(classColumns, column)
+		}
+		classes[b] = class
+	}
+
+	count := len(classColumns)
+	table := make([]int16, states*count)
+	for c, column := range classColumns {
+		for s, trans := range column {
+			table[s*count+c] = trans
+		}
+	}
+	return classes, count, table
+}
+
+func columnKey(column []int16) string {
+	buf := make([]byte, 2*len(column))
+	for i, v := range column {
+		buf[2*i] = byte(v)
+		buf[2*i+1] = byte(v >> 8)
+	}
+	return string(buf)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"testing"
+)
+
+// TestScanTable checks that the dense transition table gives the same
+// transition for every state and byte as the search that Ragel generates.
+func TestScanTable(t *testing.T) {
+	hcltokTableOnce.Do(loadScanTable)
+
+	for cs := range _hcltok_key_offsets {
+		for b := 0; b < 256; b++ {
+			want := ragelTransition(cs, byte(b))
+			got := int(hcltokTrans[cs*hcltokClassCount+int(hcltokClasses[b])])
+			if want >= 0 && got != want {
+				t.Fatalf("wrong transition for state %d and byte %#x: got %d, want %d", cs, b, got, want)
+			}
+		}
+	}
+}
+
+// ragelTransition is the transition search from the code that Ragel
+// generates, returning -1 if the state has no transition for the byte.
+func ragelTransition(cs int, c byte) int {
+	keys := int(_hcltok_key_offsets[cs])
+	trans := int(_hcltok_index_offsets[cs])
+
+	singles := int(_hcltok_single_lengths[cs])
+	for i := 0; i < singles; i++ {
+		if _hcltok_trans_keys[keys+i] == c {
+			return int(_hcltok_indicies[trans+i])
+		}
+	}
+	keys += singles
+	trans += singles
+
+	ranges := int(_hcltok_range_lengths[cs])
+	for i := 0; i < ranges; i++ {
+		if c >= _hcltok_trans_keys[keys+2*i] && c <= _hcltok_trans_keys[keys+2*i+1] {
+			return int(_hcltok_indicies[trans+i])
+		}
+	}
+	trans += ranges
+
+	if trans >= len(_hcltok_indicies) {
+		return -1
+	}
+	return int(_hcltok_indicies[trans])
+}
@@ -4,6 +4,7 @@
 package hclsyntax
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -2652,7 +2653,7 @@ EOF
 
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
-			got := scanTokens([]byte(test.input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1}, scanNormal)
+			got := scanTokens([]byte(test.input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1}, scanNormal, scanOpts{})
 
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("wrong result\n%s", diff)
@@ -2842,7 +2843,7 @@ func TestScanTokens_template(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
-			got := scanTokens([]byte(test.input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1}, scanTemplate)
+			got := scanTokens([]byte(test.input), "", hcl.Pos{Byte: 0, Line: 1, Column: 1}, scanTemplate, scanOpts{})
 
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("wrong result\n%s", diff)
@@ -2850,3 +2851,19 @@ func TestScanTokens_template(t *testing.T) {
 		})
 	}
 }
+
+func TestScanTokens_maxTokenLength(t *testing.T) {
+	src := "a = \"" + strings.Repeat("x", 100) + "\"\nb = 1\n"
+	got := scanTokens([]byte(src), "", hcl.InitialPos, scanNormal, scanOpts{maxTokenLength: 50})
+
+	// The scan stops after the long literal, so the tokens for b are never
+	// produced.
+	var types []TokenType
+	for _, tok := range got {
+		types = append(types, tok.Type)
+	}
+	want := []TokenType{TokenIdent, TokenEqual, TokenOQuote, TokenQuotedLit, TokenEOF}
+	if diff := cmp.Diff(want, types); diff != "" {
+		t.Errorf("wrong token types\n%s", diff)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+// Scanner reads a sequence of tokens one at a time, for applications that
+// parse their own dialects of the native syntax. Comments and whitespace are
+// skipped, except that the newline ending a single-line comment is returned
+// as a TokenNewline token.
+//
+// A Scanner can record its position with Mark and later return to it with
+// Reset, which allows speculative parsing: try one production, and if it
+// doesn't work out then reset and try another.
+//
+// A Scanner only reads tokens that were already lexed, so resetting it does
+// not lex another document. Programs that lex many documents and want to
+// reuse memory between them should instead pass a buffer to AppendLexConfig.
+type Scanner struct {
+	peeker *peeker
+}
+
+// ScannerMark is a checkpoint of the position of a Scanner, returned by
+// Scanner.Mark and accepted by Scanner.Reset.
+type ScannerMark struct {
+	mark peekerMark
+}
+
+// NewScanner returns a Scanner that reads the given tokens, such as those
+// returned by LexConfig. The tokens should end with a TokenEOF token, which
+// the Scanner then returns indefinitely once the others have been read.
+func NewScanner(tokens Tokens) *Scanner {
+	return &Scanner{
+		peeker: newPeeker(tokens, false),
+	}
+}
+
+// Peek returns the next token without consuming it.
+func (s *Scanner) Peek() Token {
+	return s.peeker.Peek()
+}
+
+// Read consumes and returns the next token.
+func (s *Scanner) Read() Token {
+	return s.peeker.Read()
+}
+
+// Mark returns a checkpoint of the scanner's current position, which can
+// later be passed to Reset to roll back to that position.
+func (s *Scanner) Mark() ScannerMark {
+	return ScannerMark{mark: s.peeker.Mark()}
+}
+
+// Reset rolls the scanner back to a checkpoint previously returned by Mark.
+//
+// A mark may be used for any number of calls to Reset, but only with the
+// same scanner that created it.
+func (s *Scanner) Reset(mark ScannerMark) {
+	s.peeker.Reset(mark.mark)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestScannerMarkReset(t *testing.T) {
+	tokens, diags := LexConfig([]byte("a b # note\nc\n"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	s := NewScanner(tokens)
+
+	if got, want := string(s.Read().Bytes), "a"; got != want {
+		t.Fatalf("wrong first token %q; want %q", got, want)
+	}
+	mark := s.Mark()
+
+	var got []TokenType
+	for {
+		tok := s.Read()
+		got = append(got, tok.Type)
+		if tok.Type == TokenEOF {
+			break
+		}
+	}
+	want := []TokenType{TokenIdent, TokenNewline, TokenIdent, TokenNewline, TokenEOF}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong types\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := s.Read().Type; got != TokenEOF {
+		t.Errorf("wrong type after end %s; want %s", got, TokenEOF)
+	}
+
+	s.Reset(mark)
+	if got, want := string(s.Peek().Bytes), "b"; got != want {
+		t.Errorf("wrong token after reset %q; want %q", got, want)
+	}
+}
@@ -69,9 +69,10 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 				}
 				suggestions = append(suggestions, attrS.Name)
 			}
-			suggestion := nameSuggestion(name, suggestions)
-			if suggestion != "" {
-				suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
+			suggested := nameSuggestion(name, suggestions)
+			suggestion := ""
+			if suggested != "" {
+				suggestion = fmt.Sprintf(" Did you mean %q?", suggested)
 			} else {
 				// Is there a block of the same name?
 				for _, blockS := range schema.Blocks {
@@ -87,6 +88,11 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 				Summary:  "Unsupported argument",
 				Detail:   fmt.Sprintf("An argument named %q is not expected here.%s", name, suggestion),
 				Subject:  &attr.NameRange,
+				Extra: &hcl.UnknownAttributeError{
+					Name:       name,
+					Suggestion: suggested,
+					Range:      attr.NameRange,
+				},
 			})
 		}
 	}
@@ -0,0 +1,333 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !tinygo
+// +build !tinygo
+
+package hclsyntax
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// The markers that renderTextTemplate inserts into the template text to
+// record where each part of the output came from. These are characters from
+// the Unicode private use area, which are very unlikely to appear in either
+// a configuration file or the data it is rendered with.
+const (
+	templateMarkerStart = "\uf8ff"
+	templateMarkerEnd   = "\uf8fe"
+)
+
+// templateSegment records that the output bytes starting at out were copied
+// from the len bytes of the template source starting at src. Any further
+// output before the next segment, which was produced by a template action,
+// is considered to come from the source offset src+len.
+type templateSegment struct {
+	out, src, len int
+}
+
+// templateMap maps positions in the output of a text/template template back
+// to positions in the template source.
+type templateMap struct {
+	src      []byte
+	start    hcl.Pos
+	outLen   int
+	segments []templateSegment
+
+	// lineStarts holds the offset of the start of each line in src, for
+	// converting offsets into positions.
+	lineStarts []int
+}
+
+type optTextTemplate struct {
+	data  interface{}
+	funcs template.FuncMap
+}
+
+// OptTextTemplate returns a ParseOption that causes the source code to be
+// executed as a Go text/template template with the given data, and the
+// given functions if funcs is non-nil, before it is scanned. This allows a
+// configuration file to be parameterized, for example by environment:
+//
+//	{{ range .Regions -}}
+//	region "{{ . }}" {
+//	  replicas = {{ $.Replicas }}
+//	}
+//	{{ end }}
+//
+// The source ranges of the resulting tokens, and so of the syntax tree and
+// any diagnostics, refer to the template source rather than to its output.
+// Text copied from the template maps to the position it was copied from,
+// and text produced by a template action maps to the position of that
+// action, so that errors point at the line of the template that produced
+// them. ParseConfig returns the template source as the Bytes of the
+// resulting file, so that diagnostic snippets show the template too.
+//
+// If the template cannot be parsed or executed then the result is a single
+// error diagnostic describing the problem.
+//
+// Because text/template relies heavily on reflection, this option is not
+// available in programs built with TinyGo.
+func OptTextTemplate(data interface{}, funcs template.FuncMap) ParseOption {
+	return &optTextTemplate{data, funcs}
+}
+
+// applyParseOption implements ParseOption.
+func (o *optTextTemplate) applyParseOption(opts *parseOpts) {
+	opts.textTemplate = o
+}
+
+// render executes the given source as a template with the receiver's data
+// and functions, as described for renderTextTemplate.
+func (o *optTextTemplate) render(src []byte, filename string, start hcl.Pos) ([]byte, *templateMap, hcl.Diagnostics) {
+	return renderTextTemplate(src, filename, start, o.data, o.funcs)
+}
+
+// renderTextTemplate executes the given source as a text/template template
+// with the given data and functions, returning the output along with a map
+// from positions in the output back to positions in the source.
+//
+// To build the map, the text of the template is instrumented before it is
+// executed with markers at the start of each line and before each action,
+// which are then removed from the output.
+func renderTextTemplate(src []byte, filename string, start hcl.Pos, data interface{}, funcs template.FuncMap) ([]byte, *templateMap, hcl.Diagnostics) {
+	tmpl := template.New(filename)
+	if funcs != nil {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	tmpl, err := tmpl.Parse(string(src))
+	if err != nil {
+		return nil, nil, templateErrorDiagnostics("Invalid template", err, src, filename, start)
+	}
+
+	var segments []templateSegment
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			instrumentTemplateList(t.Tree.Root, src, &segments)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, nil, templateErrorDiagnostics("Failed to render template", err, src, filename, start)
+	}
+
+	out, m := stripTemplateMarkers(buf.Bytes(), segments)
+	m.src = src
+	m.start = start
+	m.lineStarts = []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			m.lineStarts = append(m.lineStarts, i+1)
+		}
+	}
+	return out, m, nil
+}
+
+// instrumentTemplateList adds markers to the text nodes of the given list,
+// and inserts a marker before each action, recursing into nested lists.
+// Each marker refers to the entry it appends to segments, whose out field
+// is filled in later by stripTemplateMarkers.
+func instrumentTemplateList(list *parse.ListNode, src []byte, segments *[]templateSegment) {
+	marker := func(srcOfs, length int) []byte {
+		*segments = append(*segments, templateSegment{src: srcOfs, len: length})
+		return []byte(templateMarkerStart + strconv.Itoa(len(*segments)-1) + templateMarkerEnd)
+	}
+
+	nodes := make([]parse.Node, 0, len(list.Nodes))
+	for _, node := range list.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode:
+			pos := int(node.Pos)
+			var text []byte
+			for rest := node.Text; len(rest) > 0; {
+				line := rest
+				if nl := bytes.IndexByte(rest, '\n'); nl >= 0 {
+					line = rest[:nl+1]
+				}
+				text = append(text, marker(pos, len(line))...)
+				text = append(text, line...)
+				pos += len(line)
+				rest = rest[len(line):]
+			}
+			node.Text = text
+		case *parse.ActionNode, *parse.TemplateNode:
+			// The position of an action is that of its first token, so we
+			// look back for its opening delimiter.
+			pos := int(node.Position())
+			if i := bytes.LastIndex(src[:pos], []byte("{{")); i >= 0 {
+				pos = i
+			}
+			nodes = append(nodes, &parse.TextNode{
+				NodeType: parse.NodeText,
+				Pos:      node.Position(),
+				Text:     marker(pos, 0),
+			})
+		case *parse.IfNode:
+			instrumentTemplateBranch(&node.BranchNode, src, segments)
+		case *parse.RangeNode:
+			instrumentTemplateBranch(&node.BranchNode, src, segments)
+		case *parse.WithNode:
+			instrumentTemplateBranch(&node.BranchNode, src, segments)
+		}
+		nodes = append(nodes, node)
+	}
+	list.Nodes = nodes
+}
+
+func instrumentTemplateBranch(node *parse.BranchNode, src []byte, segments *[]templateSegment) {
+	if node.List != nil {
+		instrumentTemplateList(node.List, src, segments)
+	}
+	if node.ElseList != nil {
+		instrumentTemplateList(node.ElseList, src, segments)
+	}
+}
+
+// stripTemplateMarkers removes the markers added by instrumentTemplateList
+// from the given template output, returning the remaining output and a map
+// with a segment for each marker found.
+func stripTemplateMarkers(out []byte, segments []templateSegment) ([]byte, *templateMap) {
+	m := &templateMap{}
+	ret := make([]byte, 0, len(out))
+	for {
+		i := bytes.Index(out, []byte(templateMarkerStart))
+		if i < 0 {
+			break
+		}
+		ret = append(ret, out[:i]...)
+		out = out[i+len(templateMarkerStart):]
+
+		j := bytes.Index(out, []byte(templateMarkerEnd))
+		if j < 0 {
+			// Not one of our markers after all, so we'll keep it.
+			ret = append(ret, templateMarkerStart...)
+			continue
+		}
+		id, err := strconv.Atoi(string(out[:j]))
+		if err != nil || id < 0 || id >= len(segments) {
+			ret = append(ret, templateMarkerStart...)
+			continue
+		}
+		out = out[j+len(templateMarkerEnd):]
+
+		seg := segments[id]
+		seg.out = len(ret)
+		m.segments = append(m.segments, seg)
+	}
+	ret = append(ret, out...)
+	m.outLen = len(ret)
+	return ret, m
+}
+
+// srcOffset returns the offset in the template source corresponding to the
+// given offset in the output.
+func (m *templateMap) srcOffset(out int) int {
+	if out >= m.outLen {
+		return len(m.src)
+	}
+	i := sort.Search(len(m.segments), func(i int) bool {
+		return m.segments[i].out > out
+	}) - 1
+	if i < 0 {
+		return 0
+	}
+	seg := m.segments[i]
+	if delta := out - seg.out; delta < seg.len {
+		return seg.src + delta
+	}
+	return seg.src + seg.len
+}
+
+// pos returns the position of the given offset in the template source,
+// with its column measured in grapheme clusters.
+func (m *templateMap) pos(ofs int) hcl.Pos {
+	lineOfs, lineStart := m.lineStart(ofs)
+	return posAfter(lineStart, m.src[lineOfs:ofs])
+}
+
+// lineStart returns the offset and position of the start of the line
+// containing the given offset in the template source.
+func (m *templateMap) lineStart(ofs int) (int, hcl.Pos) {
+	line := sort.Search(len(m.lineStarts), func(i int) bool {
+		return m.lineStarts[i] > ofs
+	}) - 1
+	pos := hcl.Pos{
+		Byte:   m.start.Byte + m.lineStarts[line],
+		Line:   m.start.Line + line,
+		Column: 1,
+	}
+	if line == 0 {
+		pos.Column = m.start.Column
+	}
+	return m.lineStarts[line], pos
+}
+
+// remapTokens updates the ranges of the given tokens, which were scanned
+// from the template output, to refer to the template source.
+func (m *templateMap) remapTokens(tokens Tokens) {
+	for i := range tokens {
+		rng := &tokens[i].Range
+		startOfs := m.srcOffset(rng.Start.Byte - m.start.Byte)
+		endOfs := m.srcOffset(rng.End.Byte - m.start.Byte)
+		if endOfs < startOfs {
+			endOfs = startOfs
+		}
+		rng.Start = m.pos(startOfs)
+		rng.End = m.pos(endOfs)
+	}
+}
+
+// recomputeColumns is like the function of the same name, but works on
+// tokens that were remapped by remapTokens and so may not be in order.
+func (m *templateMap) recomputeColumns(tokens Tokens, tabWidth int, unit ColumnUnit) {
+	for i := range tokens {
+		lineOfs, lineStart := m.lineStart(tokens[i].Range.Start.Byte - m.start.Byte)
+		recomputeColumns(m.src[lineOfs:], tokens[i:i+1], lineStart, tabWidth, unit)
+	}
+}
+
+var templateErrorPattern = regexp.MustCompile(`^(\d+)(?::\d+)?: ?`)
+
+// templateErrorDiagnostics returns an error diagnostic for the given error
+// from text/template, whose message usually gives the line of the source
+// where the problem was found.
+func templateErrorDiagnostics(summary string, err error, src []byte, filename string, start hcl.Pos) hcl.Diagnostics {
+	msg := strings.TrimPrefix(err.Error(), "template: "+filename+":")
+	rng := hcl.Range{Filename: filename, Start: start, End: start}
+	if match := templateErrorPattern.FindStringSubmatch(msg); match != nil {
+		msg = msg[len(match[0]):]
+		line, _ := strconv.Atoi(match[1])
+		lines := bytes.SplitAfter(src, []byte("\n"))
+		if line >= 1 && line <= len(lines) {
+			ofs := 0
+			for _, l := range lines[:line-1] {
+				ofs += len(l)
+			}
+			lineStart := hcl.Pos{Byte: start.Byte + ofs, Line: start.Line + line - 1, Column: 1}
+			if line == 1 {
+				lineStart = start
+			}
+			rng.Start = lineStart
+			rng.End = posAfter(lineStart, bytes.TrimRight(lines[line-1], "\r\n"))
+		}
+	}
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Detail:   fmt.Sprintf("The configuration is a Go template, and it could not be rendered: %s.", strings.TrimSuffix(msg, ".")),
+			Subject:  &rng,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !tinygo
+// +build !tinygo
+
+package hclsyntax
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestParseConfigTextTemplate(t *testing.T) {
+	src := `name = "{{ .Name }}"
+{{ range .Regions -}}
+region "{{ . }}" {
+  replicas = {{ $.Replicas }}
+}
+{{ end -}}
+`
+	data := map[string]interface{}{
+		"Name":     "web",
+		"Regions":  []string{"east", "west"},
+		"Replicas": 3,
+	}
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.InitialPos, OptTextTemplate(data, nil))
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	if string(file.Bytes) != src {
+		t.Errorf("file bytes are not the template source:\n%s", file.Bytes)
+	}
+
+	body := file.Body.(*Body)
+	if got, want := len(body.Blocks), 2; got != want {
+		t.Fatalf("wrong number of blocks %d; want %d", got, want)
+	}
+	for i, label := range []string{"east", "west"} {
+		block := body.Blocks[i]
+		if block.Labels[0] != label {
+			t.Errorf("block %d has label %q; want %q", i, block.Labels[0], label)
+		}
+
+		// Each block comes from the same lines of the template.
+		if got, want := block.TypeRange, (hcl.Range{
+			Filename: "test.hcl",
+			Start:    hcl.Pos{Line: 3, Column: 1, Byte: 43},
+			End:      hcl.Pos{Line: 3, Column: 7, Byte: 49},
+		}); got != want {
+			t.Errorf("block %d has wrong type range %#v; want %#v", i, got, want)
+		}
+
+		// The replicas value comes from an action, so its range is that of
+		// the action.
+		val := block.Body.Attributes["replicas"].Expr.Range()
+		if got, want := val.Start, (hcl.Pos{Line: 4, Column: 14, Byte: 75}); got != want {
+			t.Errorf("block %d replicas value starts at %#v; want %#v", i, got, want)
+		}
+	}
+}
+
+func TestParseConfigTextTemplateErrors(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Src         string
+		Data        interface{}
+		Funcs       template.FuncMap
+		WantSummary string
+		WantLine    int
+	}{
+		{
+			"syntax error in output",
+			"a = 1\nb = {{ .B }}\n",
+			map[string]string{"B": "1 +"},
+			nil,
+			"Invalid expression",
+			2,
+		},
+		{
+			"invalid template",
+			"a = 1\nb = {{ .B\n",
+			nil,
+			nil,
+			"Invalid template",
+			3,
+		},
+		{
+			"execution failure",
+			"a = 1\n\nb = {{ fail }}\n",
+			nil,
+			template.FuncMap{
+				"fail": func() (string, error) { return "", errors.New("failed") },
+			},
+			"Failed to render template",
+			3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, diags := ParseConfig([]byte(test.Src), "test.hcl", hcl.InitialPos, OptTextTemplate(test.Data, test.Funcs))
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want error")
+			}
+			diag := diags[0]
+			if diag.Summary != test.WantSummary {
+				t.Errorf("wrong summary %q; want %q", diag.Summary, test.WantSummary)
+			}
+			if diag.Subject == nil || diag.Subject.Start.Line != test.WantLine {
+				t.Errorf("wrong subject %s; want line %d", diag.Subject, test.WantLine)
+			}
+			if strings.Contains(diag.Detail, "template: test.hcl") {
+				t.Errorf("detail includes the template location prefix: %s", diag.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build tinygo
+// +build tinygo
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// TinyGo's support for reflection is too limited for text/template, so
+// OptTextTemplate isn't available in programs built with it. These
+// placeholders satisfy the references from the lexer, which are never
+// reached because the option can't be set.
+
+type optTextTemplate struct{}
+
+func (o *optTextTemplate) render(src []byte, filename string, start hcl.Pos) ([]byte, *templateMap, hcl.Diagnostics) {
+	return src, nil, nil
+}
+
+type templateMap struct{}
+
+func (m *templateMap) remapTokens(tokens Tokens) {}
+
+func (m *templateMap) recomputeColumns(tokens Tokens, tabWidth int, unit ColumnUnit) {}
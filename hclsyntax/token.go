@@ -6,6 +6,7 @@ package hclsyntax
 import (
 	"bytes"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/apparentlymart/go-textseg/v15/textseg"
 	"github.com/hashicorp/hcl/v2"
@@ -13,6 +14,14 @@ import (
 
 // Token represents a sequence of bytes from some HCL code that has been
 // tagged with a type and its range within the source file.
+//
+// To avoid an allocation for each token, the Bytes of a token are usually a
+// sub-slice of the source buffer given to the lexer rather than a copy, and
+// so remain valid for as long as that buffer is. The exceptions are when
+// the source had to be transformed before scanning, such as to decode
+// UTF-16 or to render a text template, and the tokens that replace invalid
+// UTF-8 sequences, whose bytes refer to buffers shared between tokens.
+// Callers must therefore never modify the bytes of a token.
 type Token struct {
 	Type  TokenType
 	Bytes []byte
@@ -74,13 +83,18 @@ const (
 	TokenTemplateControl TokenType = 'λ'
 	TokenTemplateSeqEnd  TokenType = '∎'
 
-	TokenQuotedLit TokenType = 'Q' // might contain backslash escapes
-	TokenStringLit TokenType = 'S' // cannot contain backslash escapes
-	TokenNumberLit TokenType = 'N'
-	TokenIdent     TokenType = 'I'
+	TokenQuotedLit    TokenType = 'Q' // might contain backslash escapes
+	TokenStringLit    TokenType = 'S' // cannot contain backslash escapes
+	TokenRawStringLit TokenType = 'R' // includes its delimiters; see OptRawStrings
+	TokenNumberLit    TokenType = 'N'
+	TokenIdent        TokenType = 'I'
 
 	TokenComment TokenType = 'C'
 
+	// TokenWhitespace is produced only when OptWhitespaceTokens is used,
+	// for the spaces and tabs between other tokens.
+	TokenWhitespace TokenType = '␠'
+
 	TokenNewline TokenType = '\n'
 	TokenEOF     TokenType = '␄'
 
@@ -120,6 +134,32 @@ const (
 	scanIdentOnly
 )
 
+// scanOpts are the optional behaviors of scanTokens.
+type scanOpts struct {
+	// maxTokenLength, if greater than zero, causes the scan to stop after
+	// the first token longer than this many bytes.
+	maxTokenLength int
+
+	// rawStrings causes the scanner to produce a single TokenRawStringLit
+	// token for each raw string, as enabled by OptRawStrings.
+	rawStrings bool
+
+	// buf is a slice whose capacity the scanner may reuse to accumulate
+	// tokens, as for AppendLexConfig.
+	buf []Token
+}
+
+// tokenBuffer returns an empty slice in which to accumulate the tokens
+// scanned from the given data, reusing the capacity of buf if it is likely
+// to be enough.
+func (o scanOpts) tokenBuffer(data []byte) []Token {
+	n := estimateTokenCount(data)
+	if cap(o.buf) >= n {
+		return o.buf[:0]
+	}
+	return make([]Token, 0, n)
+}
+
 type tokenAccum struct {
 	Filename  string
 	Bytes     []byte
@@ -139,6 +179,25 @@ func (f *tokenAccum) emitToken(ty TokenType, startOfs, endOfs int) {
 	end := start
 	end.Byte = endOfs + f.StartByte
 	b := f.Bytes[startOfs:endOfs]
+	if isASCII(b) {
+		// Fast path: every ASCII character is a grapheme cluster of its
+		// own, except for CRLF, so we can count columns without the cost
+		// of full segmentation. Most tokens in typical input take this path.
+		for i := 0; i < len(b); i++ {
+			switch {
+			case b[i] == '\n':
+				end.Line++
+				end.Column = 1
+			case b[i] == '\r' && i+1 < len(b) && b[i+1] == '\n':
+				end.Line++
+				end.Column = 1
+				i++
+			default:
+				end.Column++
+			}
+		}
+		b = nil
+	}
 	for len(b) > 0 {
 		advance, seq, _ := textseg.ScanGraphemeClusters(b, true)
 		if (len(seq) == 1 && seq[0] == '\n') || (len(seq) == 2 && seq[0] == '\r' && seq[1] == '\n') {
@@ -152,15 +211,213 @@ func (f *tokenAccum) emitToken(ty TokenType, startOfs, endOfs int) {
 
 	f.Pos = end
 
-	f.Tokens = append(f.Tokens, Token{
-		Type:  ty,
-		Bytes: f.Bytes[startOfs:endOfs],
-		Range: hcl.Range{
-			Filename: f.Filename,
-			Start:    start,
-			End:      end,
-		},
-	})
+	// We fill in the new token in place, rather than appending a complete
+	// token, because copying each token into the slice is a significant
+	// part of the cost of scanning.
+	if n := len(f.Tokens); n < cap(f.Tokens) {
+		f.Tokens = f.Tokens[:n+1]
+	} else {
+		// The estimate was too low, so we double the capacity rather than
+		// leave it to append, which grows large slices much more slowly
+		// and so copies them many more times.
+		grown := make([]Token, n+1, 2*n+1)
+		copy(grown, f.Tokens)
+		f.Tokens = grown
+	}
+	tok := &f.Tokens[len(f.Tokens)-1]
+	tok.Type = ty
+	tok.Bytes = f.Bytes[startOfs:endOfs]
+	tok.Range.Filename = f.Filename
+	tok.Range.Start = start
+	tok.Range.End = end
+}
+
+// replacementCharBytes is the UTF-8 encoding of U+FFFD, the Unicode
+// replacement character.
+var replacementCharBytes = []byte("\uFFFD")
+
+// replaceBadUTF8 returns a copy of the given tokens with any TokenBadUTF8
+// tokens either converted into literal tokens whose bytes are the UTF-8
+// encoding of the replacement character U+FFFD, if they appear in a
+// context where a literal is expected, or else removed altogether.
+//
+// Warnings are returned to report that the replacements were made, with at
+// most one for replaced bytes and one for removed bytes. This implements OptReplaceInvalidUTF8, and so must run before checkInvalidTokens
+// to prevent it reporting these tokens as errors.
+func replaceBadUTF8(tokens Tokens, mode scanMode) (Tokens, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	toldReplaced, toldRemoved := false, false
+
+	// We track which literal token type is valid at each point as we
+	// enter and leave strings, heredocs, and template sequences, with
+	// TokenNil representing an expression context where no literal is
+	// allowed.
+	var literalType []TokenType
+	switch mode {
+	case scanTemplate:
+		literalType = append(literalType, TokenStringLit)
+	default:
+		literalType = append(literalType, TokenNil)
+	}
+
+	ret := make(Tokens, 0, len(tokens))
+	for _, tok := range tokens {
+		switch tok.Type {
+		case TokenOQuote:
+			literalType = append(literalType, TokenQuotedLit)
+		case TokenOHeredoc:
+			literalType = append(literalType, TokenStringLit)
+		case TokenTemplateInterp, TokenTemplateControl:
+			literalType = append(literalType, TokenNil)
+		case TokenCQuote, TokenCHeredoc, TokenTemplateSeqEnd:
+			if len(literalType) > 1 {
+				literalType = literalType[:len(literalType)-1]
+			}
+		case TokenBadUTF8:
+			rng := tok.Range
+			if ty := literalType[len(literalType)-1]; ty != TokenNil {
+				tok.Type = ty
+				tok.Bytes = replacementCharBytes
+				if !toldReplaced {
+					toldReplaced = true
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagWarning,
+						Summary:  "Invalid character encoding",
+						Detail:   "This file is not valid UTF-8. Invalid bytes in string literals have been replaced with the Unicode replacement character, U+FFFD.",
+						Subject:  &rng,
+						Extra:    errorCodeExtra(ErrIllegalUTF8),
+					})
+				}
+			} else {
+				if !toldRemoved {
+					toldRemoved = true
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagWarning,
+						Summary:  "Invalid character encoding",
+						Detail:   "This file is not valid UTF-8. Invalid bytes outside of string literals have been ignored.",
+						Subject:  &rng,
+						Extra:    errorCodeExtra(ErrIllegalUTF8),
+					})
+				}
+				continue
+			}
+		}
+		ret = append(ret, tok)
+	}
+
+	return ret, diags
+}
+
+// foldNegativeNumbers returns the given tokens with each TokenMinus that
+// is in a unary position and immediately followed by a TokenNumberLit
+// merged with that number into a single TokenNumberLit. This implements
+// OptFoldNegativeNumbers.
+//
+// The tokens are modified in place.
+func foldNegativeNumbers(tokens Tokens) Tokens {
+	ret := tokens[:0]
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type == TokenMinus && i+1 < len(tokens) {
+			next := tokens[i+1]
+			adjacent := next.Type == TokenNumberLit && next.Range.Start.Byte == tok.Range.End.Byte
+			if adjacent && (len(ret) == 0 || !tokenEndsOperand(ret[len(ret)-1])) {
+				// The two tokens are adjacent in the same source buffer, so
+				// we can just extend the minus token's slice to cover both.
+				ret = append(ret, Token{
+					Type:  TokenNumberLit,
+					Bytes: tok.Bytes[:len(tok.Bytes)+len(next.Bytes)],
+					Range: hcl.RangeBetween(tok.Range, next.Range),
+				})
+				i++
+				continue
+			}
+		}
+		ret = append(ret, tok)
+	}
+	return ret
+}
+
+// foldLineContinuations returns the given tokens with each backslash that
+// appears at the end of a line within a quoted string removed, along with the
+// following newline and any whitespace at the start of the next line. This
+// implements OptLineContinuation.
+//
+// The scanner produces a TokenInvalid for the backslash, since it does not
+// begin a valid escape sequence, followed by a TokenQuotedNewline. Because
+// TokenQuotedNewline appears only inside quoted strings, that pair is enough
+// to recognize a continuation without tracking the template nesting.
+//
+// The tokens are modified in place.
+func foldLineContinuations(tokens Tokens) Tokens {
+	ret := tokens[:0]
+	trimming := false
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type == TokenInvalid && bytes.Equal(tok.Bytes, []byte{'\\'}) && i+1 < len(tokens) && tokens[i+1].Type == TokenQuotedNewline {
+			trimming = true
+			i++
+			continue
+		}
+		if trimming {
+			switch tok.Type {
+			case TokenQuotedNewline:
+				continue
+			case TokenQuotedLit:
+				n := 0
+				for n < len(tok.Bytes) && (tok.Bytes[n] == ' ' || tok.Bytes[n] == '\t') {
+					n++
+				}
+				if n == len(tok.Bytes) {
+					continue
+				}
+				tok.Bytes = tok.Bytes[n:]
+				tok.Range.Start.Byte += n
+				tok.Range.Start.Column += n
+			}
+			trimming = false
+		}
+		ret = append(ret, tok)
+	}
+	return ret
+}
+
+// tokenEndsOperand returns true if the given token could be the last token
+// of the left operand of a binary operator, in which case a following minus
+// sign must be a subtraction rather than a negation.
+func tokenEndsOperand(tok Token) bool {
+	switch tok.Type {
+	case TokenIdent, TokenNumberLit, TokenCParen, TokenCBrack, TokenCBrace,
+		TokenCQuote, TokenCHeredoc:
+		return true
+	default:
+		return false
+	}
+}
+
+// isASCII returns true if the given buffer contains only ASCII characters.
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateTokenCount returns a guess at the number of tokens in the given
+// source buffer, used to size the token slice up front so that it need not
+// be repeatedly grown and copied while scanning large inputs. Typical
+// configuration averages a little over four bytes and five tokens per line,
+// but heredocs and comments hold a whole line in a single token, and so we
+// also limit the guess by the number of lines to avoid a token slice many
+// times larger than needed for sources made mostly of those.
+func estimateTokenCount(src []byte) int {
+	n := len(src)/4 + 1
+	if lines := bytes.Count(src, []byte{'\n'}) + 1; n > 6*lines {
+		n = 6 * lines
+	}
+	return n
 }
 
 type heredocInProgress struct {
@@ -194,7 +451,19 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 	toldTabs := 0
 	toldBadUTF8 := 0
 
-	for _, tok := range tokens {
+	// openQuotes tracks the quoted strings that are open at each token, so
+	// that an unclosed string can be reported once, along with where it
+	// began, rather than once for every line it swallows.
+	type openQuote struct {
+		rng  hcl.Range
+		told bool
+	}
+	var openQuotes []openQuote
+
+	for i := range tokens {
+		// We refer to each token in place, because copying every token
+		// is a significant part of the cost of this function.
+		tok := &tokens[i]
 		tokRange := func() *hcl.Range {
 			r := tok.Range
 			return &r
@@ -218,6 +487,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Unsupported operator",
 					Detail:   fmt.Sprintf("Bitwise operators are not supported.%s", suggestion),
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrUnsupportedOperator),
 				})
 				toldBitwise++
 			}
@@ -228,6 +498,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Unsupported operator",
 					Detail:   "\"**\" is not a supported operator. Exponentiation is not supported as an operator.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrUnsupportedOperator),
 				})
 
 				toldExponent++
@@ -241,6 +512,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "The \"`\" character is not valid. To create a multi-line string, use the \"heredoc\" syntax, like \"<<EOT\".",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalBacktick),
 				})
 			}
 			if toldBacktick <= 2 {
@@ -253,6 +525,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "Single quotes are not valid. Use double quotes (\") to enclose strings.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalSingleQuote),
 				}
 				diags = append(diags, newDiag)
 			}
@@ -266,6 +539,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "The \";\" character is not valid. Use newlines to separate arguments and blocks, and commas to separate items in collection values.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalSemicolon),
 				})
 
 				toldSemicolon++
@@ -277,6 +551,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "Tab characters may not be used. The recommended indentation style is two spaces per indent.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalTab),
 				})
 
 				toldTabs++
@@ -288,16 +563,41 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character encoding",
 					Detail:   "All input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalUTF8),
 				})
 
 				toldBadUTF8++
 			}
+		case TokenOQuote:
+			openQuotes = append(openQuotes, openQuote{rng: tok.Range})
+		case TokenCQuote:
+			if len(openQuotes) > 0 {
+				openQuotes = openQuotes[:len(openQuotes)-1]
+			}
 		case TokenQuotedNewline:
+			detail := "Quoted strings may not be split over multiple lines. To produce a multi-line string, either use the \\n escape to represent a newline character or use the \"heredoc\" multi-line template syntax."
+			var context *hcl.Range
+			extra := errorCodeExtra(ErrQuotedNewline)
+			if len(openQuotes) > 0 {
+				open := &openQuotes[len(openQuotes)-1]
+				if open.told {
+					continue
+				}
+				open.told = true
+				detail = fmt.Sprintf(
+					"The quoted string that begins at line %d, column %d has no closing quote mark on that line. If the string should end there, add a closing quote mark (\"). %s",
+					open.rng.Start.Line, open.rng.Start.Column, detail,
+				)
+				context = hcl.RangeBetween(open.rng, tok.Range).Ptr()
+				extra = quickFixExtra(ErrQuotedNewline, insertAt(tok.Range.Filename, tok.Range.Start, `"`))
+			}
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid multi-line string",
-				Detail:   "Quoted strings may not be split over multiple lines. To produce a multi-line string, either use the \\n escape to represent a newline character or use the \"heredoc\" multi-line template syntax.",
+				Detail:   detail,
 				Subject:  tokRange(),
+				Context:  context,
+				Extra:    extra,
 			})
 		case TokenInvalid:
 			chars := string(tok.Bytes)
@@ -308,6 +608,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "\"Curly quotes\" are not valid here. These can sometimes be inadvertently introduced when sharing code via documents or discussion forums. It might help to replace the character with a \"straight quote\".",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalCurlyQuote),
 				})
 			default:
 				diags = append(diags, &hcl.Diagnostic{
@@ -315,6 +616,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 					Summary:  "Invalid character",
 					Detail:   "This character is not used within the language.",
 					Subject:  tokRange(),
+					Extra:    errorCodeExtra(ErrIllegalCharacter),
 				})
 			}
 		}
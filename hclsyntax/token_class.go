@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// The methods in this file classify tokens in the same spirit as the
+// similarly-named methods in the standard library's go/token package, so
+// that callers working directly with the results of the Lex functions need
+// not write their own switch statements over token types.
+
+// IsLiteral returns true for token types that represent an identifier or
+// the literal content of a number or string, as with go/token.
+//
+// The delimiters of quoted strings and heredocs are not literals, and nor
+// are the tokens that appear only in invalid input.
+func (t TokenType) IsLiteral() bool {
+	switch t {
+	case TokenIdent, TokenNumberLit, TokenQuotedLit, TokenStringLit, TokenRawStringLit:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOperator returns true for token types that represent an operator or a
+// delimiter in expressions and bodies, such as "+", "==", "." or "{", as
+// with go/token.
+//
+// The delimiters of strings, heredocs and template sequences are not
+// operators, and nor are the tokens such as "&" that the scanner recognizes
+// only to report that they are not supported.
+func (t TokenType) IsOperator() bool {
+	switch t {
+	case TokenOBrace, TokenCBrace, TokenOBrack, TokenCBrack, TokenOParen, TokenCParen,
+		TokenStar, TokenSlash, TokenPlus, TokenMinus, TokenPercent,
+		TokenEqual, TokenEqualOp, TokenNotEqual,
+		TokenLessThan, TokenLessThanEq, TokenGreaterThan, TokenGreaterThanEq,
+		TokenAnd, TokenOr, TokenBang,
+		TokenDot, TokenComma, TokenDoubleColon, TokenEllipsis, TokenFatArrow,
+		TokenQuestion, TokenColon:
+		return true
+	default:
+		return false
+	}
+}
+
+// keywords are the identifiers that the parser interprets as literal values
+// rather than as references to variables.
+var keywords = map[string]cty.Value{
+	"true":  cty.True,
+	"false": cty.False,
+	"null":  cty.NullVal(cty.DynamicPseudoType),
+}
+
+// LookupKeyword returns the value of the keyword with the given name, along
+// with true, or cty.NilVal and false if the name is not a keyword.
+//
+// The keywords are "true", "false" and "null". The words that introduce
+// "for" expressions and template directives are significant only in certain
+// contexts and can otherwise be used as ordinary identifiers, so they are not
+// considered to be keywords.
+func LookupKeyword(name string) (cty.Value, bool) {
+	val, ok := keywords[name]
+	return val, ok
+}
+
+// IsKeyword returns true if the token is an identifier whose name is one of
+// the keywords recognized by LookupKeyword.
+func (t Token) IsKeyword() bool {
+	if t.Type != TokenIdent {
+		return false
+	}
+	_, ok := keywords[string(t.Bytes)]
+	return ok
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestCheckInvalidTokensTest(t *testing.T) {
@@ -61,3 +62,105 @@ func TestCheckInvalidTokensTest(t *testing.T) {
 		})
 	}
 }
+
+func TestEmitTokenPositions(t *testing.T) {
+	tests := []struct {
+		Input   string
+		WantEnd hcl.Pos
+	}{
+		{"abc", hcl.Pos{Line: 1, Column: 4, Byte: 3}},
+		{"a\nb", hcl.Pos{Line: 2, Column: 2, Byte: 3}},
+		{"a\r\nb", hcl.Pos{Line: 2, Column: 2, Byte: 4}},
+		{"a\rb", hcl.Pos{Line: 1, Column: 4, Byte: 3}},
+		{"ab\r", hcl.Pos{Line: 1, Column: 4, Byte: 3}},
+		{"héllo", hcl.Pos{Line: 1, Column: 6, Byte: 6}},
+		{"héllo", hcl.Pos{Line: 1, Column: 6, Byte: 7}}, // combining acute accent
+		{"a\r\né", hcl.Pos{Line: 2, Column: 2, Byte: 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			f := &tokenAccum{
+				Bytes: []byte(test.Input),
+				Pos:   hcl.InitialPos,
+			}
+			f.emitToken(TokenIdent, 0, len(test.Input))
+
+			if got := f.Tokens[0].Range.End; got != test.WantEnd {
+				t.Errorf("wrong end position %#v; want %#v", got, test.WantEnd)
+			}
+		})
+	}
+}
+
+func TestTokenTypeClasses(t *testing.T) {
+	tests := []struct {
+		Type     TokenType
+		Literal  bool
+		Operator bool
+	}{
+		{TokenIdent, true, false},
+		{TokenNumberLit, true, false},
+		{TokenQuotedLit, true, false},
+		{TokenStringLit, true, false},
+		{TokenRawStringLit, true, false},
+		{TokenOQuote, false, false},
+		{TokenOHeredoc, false, false},
+		{TokenTemplateInterp, false, false},
+		{TokenPlus, false, true},
+		{TokenEqualOp, false, true},
+		{TokenEqual, false, true},
+		{TokenOBrace, false, true},
+		{TokenFatArrow, false, true},
+		{TokenBitwiseAnd, false, false},
+		{TokenComment, false, false},
+		{TokenNewline, false, false},
+		{TokenEOF, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Type.String(), func(t *testing.T) {
+			if got := test.Type.IsLiteral(); got != test.Literal {
+				t.Errorf("wrong IsLiteral result %t; want %t", got, test.Literal)
+			}
+			if got := test.Type.IsOperator(); got != test.Operator {
+				t.Errorf("wrong IsOperator result %t; want %t", got, test.Operator)
+			}
+		})
+	}
+}
+
+func TestLookupKeyword(t *testing.T) {
+	tests := []struct {
+		Name string
+		Want cty.Value
+	}{
+		{"true", cty.True},
+		{"false", cty.False},
+		{"null", cty.NullVal(cty.DynamicPseudoType)},
+		{"for", cty.NilVal},
+		{"True", cty.NilVal},
+		{"foo", cty.NilVal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			got, ok := LookupKeyword(test.Name)
+			if ok != (test.Want != cty.NilVal) {
+				t.Fatalf("wrong ok %t", ok)
+			}
+			if ok && !got.RawEquals(test.Want) {
+				t.Errorf("wrong value %#v; want %#v", got, test.Want)
+			}
+
+			tok := Token{Type: TokenIdent, Bytes: []byte(test.Name)}
+			if got := tok.IsKeyword(); got != ok {
+				t.Errorf("wrong IsKeyword result %t; want %t", got, ok)
+			}
+		})
+	}
+
+	if (Token{Type: TokenQuotedLit, Bytes: []byte("true")}).IsKeyword() {
+		t.Errorf("quoted literal treated as keyword")
+	}
+}
@@ -5,7 +5,7 @@ package hclsyntax
 import "strconv"
 
 func _() {
-	// An "invalid array index" compiler error signifies that the constant values (55) have changed.
+	// An "invalid array index" compiler error signifies that the constant values (57) have changed.
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[TokenOBrace-123]
@@ -45,9 +45,11 @@ func _() {
 	_ = x[TokenTemplateSeqEnd-8718]
 	_ = x[TokenQuotedLit-81]
 	_ = x[TokenStringLit-83]
+	_ = x[TokenRawStringLit-82]
 	_ = x[TokenNumberLit-78]
 	_ = x[TokenIdent-73]
 	_ = x[TokenComment-67]
+	_ = x[TokenWhitespace-9248]
 	_ = x[TokenNewline-10]
 	_ = x[TokenEOF-9220]
 	_ = x[TokenBitwiseAnd-38]
@@ -65,7 +67,7 @@ func _() {
 	_ = x[TokenNil-0]
 }
 
-const _TokenType_name = "TokenNilTokenNewlineTokenBangTokenPercentTokenBitwiseAndTokenApostropheTokenOParenTokenCParenTokenStarTokenPlusTokenCommaTokenMinusTokenDotTokenSlashTokenColonTokenSemicolonTokenLessThanTokenEqualTokenGreaterThanTokenQuestionTokenCommentTokenOHeredocTokenIdentTokenNumberLitTokenQuotedLitTokenStringLitTokenOBrackTokenCBrackTokenBitwiseXorTokenBacktickTokenCHeredocTokenOBraceTokenBitwiseOrTokenCBraceTokenBitwiseNotTokenOQuoteTokenCQuoteTokenTemplateControlTokenEllipsisTokenFatArrowTokenTemplateSeqEndTokenAndTokenOrTokenTemplateInterpTokenEqualOpTokenNotEqualTokenLessThanEqTokenGreaterThanEqTokenEOFTokenTabsTokenQuotedNewlineTokenStarStarTokenDoubleColonTokenInvalidTokenBadUTF8"
+const _TokenType_name = "TokenNilTokenNewlineTokenBangTokenPercentTokenBitwiseAndTokenApostropheTokenOParenTokenCParenTokenStarTokenPlusTokenCommaTokenMinusTokenDotTokenSlashTokenColonTokenSemicolonTokenLessThanTokenEqualTokenGreaterThanTokenQuestionTokenCommentTokenOHeredocTokenIdentTokenNumberLitTokenQuotedLitTokenRawStringLitTokenStringLitTokenOBrackTokenCBrackTokenBitwiseXorTokenBacktickTokenCHeredocTokenOBraceTokenBitwiseOrTokenCBraceTokenBitwiseNotTokenOQuoteTokenCQuoteTokenTemplateControlTokenEllipsisTokenFatArrowTokenTemplateSeqEndTokenAndTokenOrTokenTemplateInterpTokenEqualOpTokenNotEqualTokenLessThanEqTokenGreaterThanEqTokenEOFTokenTabsTokenWhitespaceTokenQuotedNewlineTokenStarStarTokenDoubleColonTokenInvalidTokenBadUTF8"
 
 var _TokenType_map = map[TokenType]string{
 	0:      _TokenType_name[0:8],
@@ -93,36 +95,38 @@ var _TokenType_map = map[TokenType]string{
 	73:     _TokenType_name[250:260],
 	78:     _TokenType_name[260:274],
 	81:     _TokenType_name[274:288],
-	83:     _TokenType_name[288:302],
-	91:     _TokenType_name[302:313],
-	93:     _TokenType_name[313:324],
-	94:     _TokenType_name[324:339],
-	96:     _TokenType_name[339:352],
-	104:    _TokenType_name[352:365],
-	123:    _TokenType_name[365:376],
-	124:    _TokenType_name[376:390],
-	125:    _TokenType_name[390:401],
-	126:    _TokenType_name[401:416],
-	171:    _TokenType_name[416:427],
-	187:    _TokenType_name[427:438],
-	955:    _TokenType_name[438:458],
-	8230:   _TokenType_name[458:471],
-	8658:   _TokenType_name[471:484],
-	8718:   _TokenType_name[484:503],
-	8743:   _TokenType_name[503:511],
-	8744:   _TokenType_name[511:518],
-	8747:   _TokenType_name[518:537],
-	8788:   _TokenType_name[537:549],
-	8800:   _TokenType_name[549:562],
-	8804:   _TokenType_name[562:577],
-	8805:   _TokenType_name[577:595],
-	9220:   _TokenType_name[595:603],
-	9225:   _TokenType_name[603:612],
-	9252:   _TokenType_name[612:630],
-	10138:  _TokenType_name[630:643],
-	11820:  _TokenType_name[643:659],
-	65533:  _TokenType_name[659:671],
-	128169: _TokenType_name[671:683],
+	82:     _TokenType_name[288:305],
+	83:     _TokenType_name[305:319],
+	91:     _TokenType_name[319:330],
+	93:     _TokenType_name[330:341],
+	94:     _TokenType_name[341:356],
+	96:     _TokenType_name[356:369],
+	104:    _TokenType_name[369:382],
+	123:    _TokenType_name[382:393],
+	124:    _TokenType_name[393:407],
+	125:    _TokenType_name[407:418],
+	126:    _TokenType_name[418:433],
+	171:    _TokenType_name[433:444],
+	187:    _TokenType_name[444:455],
+	955:    _TokenType_name[455:475],
+	8230:   _TokenType_name[475:488],
+	8658:   _TokenType_name[488:501],
+	8718:   _TokenType_name[501:520],
+	8743:   _TokenType_name[520:528],
+	8744:   _TokenType_name[528:535],
+	8747:   _TokenType_name[535:554],
+	8788:   _TokenType_name[554:566],
+	8800:   _TokenType_name[566:579],
+	8804:   _TokenType_name[579:594],
+	8805:   _TokenType_name[594:612],
+	9220:   _TokenType_name[612:620],
+	9225:   _TokenType_name[620:629],
+	9248:   _TokenType_name[629:644],
+	9252:   _TokenType_name[644:662],
+	10138:  _TokenType_name[662:675],
+	11820:  _TokenType_name[675:691],
+	65533:  _TokenType_name[691:703],
+	128169: _TokenType_name[703:715],
 }
 
 func (i TokenType) String() string {
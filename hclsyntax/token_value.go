@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// The methods in this file interpret the literal value of a single token, for
+// callers working directly with the results of the Lex functions rather than
+// with parsed expressions.
+//
+// Number literals in the native syntax are always decimal, optionally with a
+// fractional part and an exponent, and a leading zero does not indicate an
+// octal number as it would for strconv.ParseInt with base zero. These methods
+// interpret them in the same way as the parser does, and report an error
+// rather than silently losing precision where the requested Go type cannot
+// represent a number.
+
+// BigFloat returns the exact value of a TokenNumberLit token, at the same
+// precision that HCL uses for all numbers.
+func (t Token) BigFloat() (*big.Float, hcl.Diagnostics) {
+	if t.Type != TokenNumberLit {
+		return nil, t.wrongTypeDiagnostics("a number literal")
+	}
+
+	val, err := cty.ParseNumberVal(string(t.Bytes))
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid number literal",
+				Detail:   "Failed to recognize the value of this number literal.",
+				Subject:  t.Range.Ptr(),
+			},
+		}
+	}
+	return val.AsBigFloat(), nil
+}
+
+// BigInt returns the value of a TokenNumberLit token as an integer, or
+// returns an error if the number has a fractional part.
+func (t Token) BigInt() (*big.Int, hcl.Diagnostics) {
+	f, diags := t.BigFloat()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if !f.IsInt() {
+		return nil, t.notIntegerDiagnostics()
+	}
+	i, _ := f.Int(nil)
+	return i, nil
+}
+
+// Int64 returns the value of a TokenNumberLit token as an int64, or returns
+// an error if the number has a fractional part or is out of range.
+func (t Token) Int64() (int64, hcl.Diagnostics) {
+	f, diags := t.BigFloat()
+	if diags.HasErrors() {
+		return 0, diags
+	}
+	if !f.IsInt() {
+		return 0, t.notIntegerDiagnostics()
+	}
+	i, acc := f.Int64()
+	if acc != big.Exact {
+		return 0, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Number out of range",
+				Detail:   fmt.Sprintf("This number must be between %d and %d.", int64(math.MinInt64), int64(math.MaxInt64)),
+				Subject:  t.Range.Ptr(),
+			},
+		}
+	}
+	return i, nil
+}
+
+// Float64 returns the value of a TokenNumberLit token as the nearest float64,
+// or returns an error if the number's magnitude is too large to represent.
+//
+// Unlike the other number methods, Float64 may lose precision. Use BigFloat
+// to obtain the exact value.
+func (t Token) Float64() (float64, hcl.Diagnostics) {
+	f, diags := t.BigFloat()
+	if diags.HasErrors() {
+		return 0, diags
+	}
+	ret, _ := f.Float64()
+	if math.IsInf(ret, 0) {
+		return 0, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Number out of range",
+				Detail:   "This number is too large to be represented as a 64-bit floating point number.",
+				Subject:  t.Range.Ptr(),
+			},
+		}
+	}
+	return ret, nil
+}
+
+// Value returns the value of a literal token as a Go value of the most
+// natural type:
+//
+//   - A TokenNumberLit that is an integer produces an int64 if it is in range,
+//     or a *big.Int otherwise.
+//   - A TokenNumberLit with a fractional part produces a float64 if it can be
+//     represented exactly, or a *big.Float otherwise.
+//   - A TokenQuotedLit or TokenStringLit produces a string, with any escape
+//     sequences resolved as for ParseStringLiteralToken.
+//   - A TokenRawStringLit produces its content as a string, without its
+//     delimiters.
+//   - A TokenIdent for one of the keywords true, false or null produces the
+//     corresponding bool value or nil, as in an expression. Any other
+//     TokenIdent produces its name as a string.
+//
+// Value returns an error for any other token type.
+func (t Token) Value() (interface{}, hcl.Diagnostics) {
+	switch t.Type {
+	case TokenNumberLit:
+		f, diags := t.BigFloat()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if f.IsInt() {
+			if i, acc := f.Int64(); acc == big.Exact {
+				return i, nil
+			}
+			i, _ := f.Int(nil)
+			return i, nil
+		}
+		if ret, acc := f.Float64(); acc == big.Exact {
+			return ret, nil
+		}
+		return f, nil
+	case TokenQuotedLit, TokenStringLit:
+		return ParseStringLiteralToken(t)
+	case TokenRawStringLit:
+		return string(rawStringContent(t)), nil
+	case TokenIdent:
+		switch name := string(t.Bytes); name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return name, nil
+		}
+	default:
+		return nil, t.wrongTypeDiagnostics("a literal")
+	}
+}
+
+func (t Token) notIntegerDiagnostics() hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid number literal",
+			Detail:   "A whole number is required.",
+			Subject:  t.Range.Ptr(),
+		},
+	}
+}
+
+func (t Token) wrongTypeDiagnostics(want string) hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Unexpected token",
+			Detail:   fmt.Sprintf("Expected %s, but found %s.", want, t.Type),
+			Subject:  t.Range.Ptr(),
+		},
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestTokenValue(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigFloat, _, _ := big.ParseFloat("0.1", 10, 512, big.ToNearestEven)
+
+	tests := []struct {
+		Type    TokenType
+		Src     string
+		Want    interface{}
+		WantErr bool
+	}{
+		{TokenNumberLit, "1", int64(1), false},
+		{TokenNumberLit, "010", int64(10), false},
+		{TokenNumberLit, "-5", int64(-5), false},
+		{TokenNumberLit, "1e3", int64(1000), false},
+		{TokenNumberLit, "2.5", float64(2.5), false},
+		{TokenNumberLit, "25E-1", float64(2.5), false},
+		{TokenNumberLit, "0.1", bigFloat, false},
+		{TokenNumberLit, "123456789012345678901234567890", bigInt, false},
+		{TokenNumberLit, "0x1F", nil, true},
+		{TokenQuotedLit, `a\nb`, "a\nb", false},
+		{TokenIdent, "foo", "foo", false},
+		{TokenIdent, "true", true, false},
+		{TokenIdent, "false", false, false},
+		{TokenIdent, "null", nil, false},
+		{TokenOBrace, "{", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			tok := Token{Type: test.Type, Bytes: []byte(test.Src)}
+			got, diags := tok.Value()
+			if test.WantErr {
+				if !diags.HasErrors() {
+					t.Fatalf("succeeded with %#v; want error", got)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			switch want := test.Want.(type) {
+			case *big.Int:
+				if g, ok := got.(*big.Int); !ok || g.Cmp(want) != 0 {
+					t.Fatalf("wrong result\ngot:  %#v\nwant: %s", got, want)
+				}
+			case *big.Float:
+				if g, ok := got.(*big.Float); !ok || g.Cmp(want) != 0 {
+					t.Fatalf("wrong result\ngot:  %#v\nwant: %s", got, want)
+				}
+			default:
+				if got != want {
+					t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenInt64(t *testing.T) {
+	tests := []struct {
+		Src     string
+		Want    int64
+		WantErr string
+	}{
+		{"9223372036854775807", 9223372036854775807, ""},
+		{"-9223372036854775808", -9223372036854775808, ""},
+		{"9223372036854775808", 0, "Number out of range"},
+		{"1.5", 0, "Invalid number literal"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			tok := Token{Type: TokenNumberLit, Bytes: []byte(test.Src)}
+			got, diags := tok.Int64()
+			checkTokenValueDiags(t, diags, test.WantErr)
+			if got != test.Want {
+				t.Errorf("wrong result %d; want %d", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestTokenFloat64(t *testing.T) {
+	tests := []struct {
+		Src     string
+		Want    float64
+		WantErr string
+	}{
+		{"1.5e2", 150, ""},
+		{"0.1", 0.1, ""},
+		{"1e400", 0, "Number out of range"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			tok := Token{Type: TokenNumberLit, Bytes: []byte(test.Src)}
+			got, diags := tok.Float64()
+			checkTokenValueDiags(t, diags, test.WantErr)
+			if got != test.Want {
+				t.Errorf("wrong result %g; want %g", got, test.Want)
+			}
+		})
+	}
+}
+
+func checkTokenValueDiags(t *testing.T, diags hcl.Diagnostics, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+		return
+	}
+	if len(diags) != 1 || diags[0].Summary != wantErr {
+		t.Fatalf("wrong diagnostics %s; want %q", diags.Error(), wantErr)
+	}
+}
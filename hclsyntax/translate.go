@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Translator returns a replacement summary and detail for the given
+// diagnostic, which carries the given error code. Either result may be empty
+// to keep the diagnostic's original text, which is in English.
+//
+// The diagnostic is given so that a translator can include information such
+// as source positions in its messages, but must not be modified.
+type Translator func(code ErrorCode, diag *hcl.Diagnostic) (summary, detail string)
+
+// Message is a translated summary and detail for diagnostics with a
+// particular error code.
+type Message struct {
+	Summary string
+	Detail  string
+}
+
+// MessageCatalog is a set of translated messages keyed by error code. Its
+// Translate method can be used as a Translator.
+type MessageCatalog map[ErrorCode]Message
+
+// Translate implements Translator, returning the catalog's message for the
+// given code, if any.
+func (c MessageCatalog) Translate(code ErrorCode, diag *hcl.Diagnostic) (summary, detail string) {
+	msg := c[code]
+	return msg.Summary, msg.Detail
+}
+
+type optTranslator struct {
+	translator Translator
+}
+
+// OptTranslator returns a ParseOption that causes the summary and detail of
+// each returned diagnostic that has an error code to be replaced with the
+// text returned by the given translator, allowing applications to present
+// parse errors in languages other than English.
+//
+// Use TranslateDiagnostics to translate diagnostics returned without this
+// option, such as those from a parser shared with other syntaxes.
+func OptTranslator(translator Translator) ParseOption {
+	return optTranslator{translator}
+}
+
+// applyParseOption implements ParseOption.
+func (o optTranslator) applyParseOption(opts *parseOpts) {
+	opts.translator = o.translator
+}
+
+// TranslateDiagnostics replaces the summary and detail of each of the given
+// diagnostics that has an error code with the text returned by the given
+// translator, as described for OptTranslator. The diagnostics are modified
+// in place and then returned.
+func TranslateDiagnostics(diags hcl.Diagnostics, translator Translator) hcl.Diagnostics {
+	for _, diag := range diags {
+		code := DiagnosticErrorCode(diag)
+		if code == ErrNone {
+			continue
+		}
+		summary, detail := translator(code, diag)
+		if summary != "" {
+			diag.Summary = summary
+		}
+		if detail != "" {
+			diag.Detail = detail
+		}
+	}
+	return diags
+}
+
+// finishDiagnostics prepares the diagnostics returned by one of the public
+// parsing or lexing functions.
+func (o parseOpts) finishDiagnostics(diags hcl.Diagnostics) hcl.Diagnostics {
+	if o.translator != nil {
+		diags = TranslateDiagnostics(diags, o.translator)
+	}
+	return markSyntaxErrors(diags)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestOptTranslator(t *testing.T) {
+	catalog := MessageCatalog{
+		ErrIllegalSemicolon: {
+			Summary: "Caractère invalide",
+			Detail:  "Le caractère « ; » n'est pas valide.",
+		},
+		ErrIllegalSingleQuote: {
+			Summary: "Caractère invalide",
+		},
+	}
+
+	_, diags := ParseConfig([]byte("a = 1;\nb = 'c'\nd = \n"), "test.hcl", hcl.InitialPos, OptTranslator(catalog.Translate))
+	if len(diags) < 3 {
+		t.Fatalf("wrong number of diagnostics %d; want at least 3\n%s", len(diags), diags)
+	}
+
+	got := diags[0]
+	if got.Summary != "Caractère invalide" || got.Detail != "Le caractère « ; » n'est pas valide." {
+		t.Errorf("semicolon not translated: %q, %q", got.Summary, got.Detail)
+	}
+
+	// A message with no detail keeps the original detail.
+	got = diags[1]
+	if got.Summary != "Caractère invalide" || got.Detail != "Single quotes are not valid. Use double quotes (\") to enclose strings." {
+		t.Errorf("single quote not translated: %q, %q", got.Summary, got.Detail)
+	}
+
+	// Diagnostics without codes are left alone.
+	for _, diag := range diags[2:] {
+		if diag.Summary == "Caractère invalide" {
+			t.Errorf("diagnostic without code was translated: %s", diag)
+		}
+	}
+}
+
+func TestTranslateDiagnostics(t *testing.T) {
+	_, diags := ParseConfig([]byte("a = \"b\\q\"\n"), "test.hcl", hcl.InitialPos)
+	diags = TranslateDiagnostics(diags, func(code ErrorCode, diag *hcl.Diagnostic) (string, string) {
+		return code.String(), fmt.Sprintf("at line %d", diag.Subject.Start.Line)
+	})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags)
+	}
+	if got, want := diags[0].Summary, "ErrInvalidEscape"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := diags[0].Detail, "at line 1"; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// insertWhitespace returns a copy of the given tokens with a TokenWhitespace
+// token added to cover each gap between the end of one token and the start
+// of the next, so that together the tokens span all of src. This is the
+// implementation of OptWhitespaceTokens.
+//
+// The scanner skips only spaces, tabs and a leading byte order mark, so the
+// gaps never include a newline and the new tokens can take their positions
+// from the neighbouring tokens.
+func insertWhitespace(src []byte, tokens Tokens, start hcl.Pos) Tokens {
+	ret := make(Tokens, 0, len(tokens)*2)
+	prev := start
+	for _, tok := range tokens {
+		if gap := tok.Range.Start.Byte - prev.Byte; gap > 0 {
+			ret = append(ret, Token{
+				Type:  TokenWhitespace,
+				Bytes: src[prev.Byte-start.Byte : tok.Range.Start.Byte-start.Byte],
+				Range: hcl.Range{
+					Filename: tok.Range.Filename,
+					Start:    prev,
+					End:      tok.Range.Start,
+				},
+			})
+		}
+		ret = append(ret, tok)
+		prev = tok.Range.End
+	}
+	return ret
+}
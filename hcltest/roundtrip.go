@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcltest
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CheckRoundTrip verifies that the printer in package hclwrite preserves the
+// meaning of the given native syntax source code, returning an error that
+// describes the first problem it finds, or nil if there are none.
+//
+// It checks that parsing the source with hclwrite.ParseConfig and then
+// printing the resulting file, and also formatting the source with
+// hclwrite.Format using the given options, each produce source code that
+// parses to the same syntax tree as the original, apart from source ranges.
+// It also checks that formatting is idempotent, so that formatting the
+// already-formatted source code changes nothing.
+//
+// Options that deliberately reorder items, such as hclwrite.OptSortBodies,
+// will cause differences to be reported, since the syntax trees are then
+// expected to differ.
+//
+// Source code that has syntax errors is not checked, because there is no
+// syntax tree to compare, and so CheckRoundTrip returns nil for it.
+func CheckRoundTrip(src []byte, opts ...hclwrite.FormatOption) error {
+	want, diags := hclsyntax.ParseConfig(src, "original.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	f, diags := hclwrite.ParseConfig(src, "original.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("hclwrite failed to parse source that hclsyntax accepts: %s", diags.Error())
+	}
+	if err := checkReparse("printing", want, src, f.Bytes()); err != nil {
+		return err
+	}
+
+	formatted := hclwrite.Format(src, opts...)
+	if err := checkReparse("formatting", want, src, formatted); err != nil {
+		return err
+	}
+	if again := hclwrite.Format(formatted, opts...); !bytes.Equal(again, formatted) {
+		return fmt.Errorf("formatting is not idempotent\nonce:  %q\ntwice: %q", formatted, again)
+	}
+	return nil
+}
+
+// checkReparse returns an error if the given output, produced from the given
+// source by the named process, doesn't parse to the same syntax tree as the
+// source did.
+func checkReparse(process string, want *hcl.File, src, out []byte) error {
+	got, diags := hclsyntax.ParseConfig(out, "output.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("%s produced invalid source: %s\noriginal: %q\noutput:   %q", process, diags.Error(), src, out)
+	}
+	if diff := cmp.Diff(want.Body, got.Body, syntaxTreeCmpOpts...); diff != "" {
+		return fmt.Errorf("%s changed the syntax tree\noriginal: %q\noutput:   %q\n%s", process, src, out, diff)
+	}
+	return nil
+}
+
+// syntaxTreeCmpOpts are the options for comparing hclsyntax syntax trees
+// while disregarding where in the source they came from.
+var syntaxTreeCmpOpts = []cmp.Option{
+	cmpopts.IgnoreTypes(hcl.Range{}, hcl.Pos{}),
+	cmpopts.EquateEmpty(),
+	cmpopts.IgnoreUnexported(
+		hclsyntax.Body{}, hclsyntax.AnonSymbolExpr{},
+		hcl.TraverseRoot{}, hcl.TraverseAttr{}, hcl.TraverseIndex{}, hcl.TraverseSplat{},
+	),
+	cmp.Comparer(func(a, b cty.Value) bool {
+		return a.RawEquals(b)
+	}),
+	cmp.Comparer(func(a, b cty.Type) bool {
+		return a.Equals(b)
+	}),
+	cmp.Comparer(func(a, b *hclsyntax.Operation) bool {
+		return a == b
+	}),
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hcltest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestCheckRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"attributes":   "a=1\nlong_name   =   \"x ${y}\"\n",
+		"blocks":       "b \"label\" {\nc = [1,2,3]\nd { e = f.g[0] }\n}\n",
+		"expressions":  "a = x ? -y : !z\nb = {for k, v in m : k => v... if v != null}\nc = f(a, b...)\n",
+		"heredoc":      "a = <<-EOT\n    hello\n      ${name}\n    EOT\n",
+		"comments":     "# lead\na = 1 # line\n/* block */\nb = 2\n",
+		"syntax error": "a = \n",
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := CheckRoundTrip([]byte(src)); err != nil {
+				t.Error(err)
+			}
+			if err := CheckRoundTrip([]byte(src), hclwrite.OptIndent("\t"), hclwrite.OptAlignEquals(false), hclwrite.OptExpandBlocks()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("reordering is reported", func(t *testing.T) {
+		err := CheckRoundTrip([]byte("b {}\na {}\n"), hclwrite.OptSortBodies())
+		if err == nil || !strings.Contains(err.Error(), "changed the syntax tree") {
+			t.Errorf("wrong error: %v", err)
+		}
+	})
+}
+
+// TestRoundTripCorpus checks the round trip for all of the native syntax
+// files used elsewhere in this repository's tests.
+func TestRoundTripCorpus(t *testing.T) {
+	var paths []string
+	for _, dir := range []string{"../specsuite/tests", "../hclwrite/fuzz/testdata", "../hclsyntax/fuzz/testdata"} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && strings.HasSuffix(path, ".hcl") {
+				paths = append(paths, path)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(paths) == 0 {
+		t.Fatal("found no files to check")
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			src, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckRoundTrip(src); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclvalue
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Collect evaluates every attribute in the given file, recursively through
+// any nested blocks, and returns the results along with their source
+// locations.
+//
+// The given evaluation context is used to evaluate each expression, and may
+// be nil. Any diagnostics produced during evaluation are returned, but
+// collection continues regardless so that the caller gets as complete a
+// result as possible; a value that could not be evaluated is reported as
+// cty.DynamicVal.
+func Collect(file *hcl.File, ctx *hcl.EvalContext) (*Document, hcl.Diagnostics) {
+	doc := &Document{
+		Filename: file.Body.MissingItemRange().Filename,
+	}
+
+	var diags hcl.Diagnostics
+	doc.Values, doc.Blocks, diags = collectBody(file.Body, ctx)
+	return doc, diags
+}
+
+func collectBody(body hcl.Body, ctx *hcl.EvalContext) ([]*DecodedValue, []*DecodedBlock, hcl.Diagnostics) {
+	if synBody, ok := body.(*hclsyntax.Body); ok {
+		return collectSyntaxBody(synBody, ctx)
+	}
+
+	// For any other body implementation we have no way to discover blocks
+	// without a schema, so we can only collect attributes.
+	attrs, diags := body.JustAttributes()
+	values, moreDiags := collectAttributes(attrs, ctx)
+	diags = append(diags, moreDiags...)
+	return values, nil, diags
+}
+
+func collectSyntaxBody(body *hclsyntax.Body, ctx *hcl.EvalContext) ([]*DecodedValue, []*DecodedBlock, hcl.Diagnostics) {
+	attrs := make(hcl.Attributes, len(body.Attributes))
+	for name, attr := range body.Attributes {
+		attrs[name] = attr.AsHCLAttribute()
+	}
+	values, diags := collectAttributes(attrs, ctx)
+
+	blocks := make([]*DecodedBlock, 0, len(body.Blocks))
+	for _, block := range body.Blocks {
+		decoded := &DecodedBlock{
+			Type:     block.Type,
+			Labels:   block.Labels,
+			Range:    block.Range(),
+			DefRange: block.DefRange(),
+		}
+		var blockDiags hcl.Diagnostics
+		decoded.Values, decoded.Blocks, blockDiags = collectSyntaxBody(block.Body, ctx)
+		diags = append(diags, blockDiags...)
+		blocks = append(blocks, decoded)
+	}
+
+	return values, blocks, diags
+}
+
+func collectAttributes(attrs hcl.Attributes, ctx *hcl.EvalContext) ([]*DecodedValue, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	values := make([]*DecodedValue, 0, len(attrs))
+	for name, attr := range attrs {
+		val, valDiags := attr.Expr.Value(ctx)
+		diags = append(diags, valDiags...)
+		values = append(values, collectValue(name, attr.Expr, val, ctx))
+	}
+
+	// Attributes come to us in a map, so we restore source order.
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Range.Start.Byte < values[j].Range.Start.Byte
+	})
+
+	return values, diags
+}
+
+// collectValue builds the DecodedValue for an expression that has already
+// been evaluated, recursing into the elements of object and tuple
+// constructor expressions.
+//
+// Evaluation errors for the elements are not reported, since any such error
+// would already have been reported when evaluating the containing
+// expression.
+func collectValue(name string, expr hcl.Expression, val cty.Value, ctx *hcl.EvalContext) *DecodedValue {
+	if val == cty.NilVal {
+		val = cty.DynamicVal
+	}
+	ret := &DecodedValue{
+		Name:  name,
+		Value: val,
+		Range: expr.Range(),
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsObjectType() || ty.IsMapType():
+		items, diags := hcl.ExprMap(expr)
+		if diags.HasErrors() {
+			break
+		}
+		for _, item := range items {
+			elemVal, _ := item.Value.Value(ctx)
+			ret.Elements = append(ret.Elements, collectValue(exprKeyName(item.Key, ctx), item.Value, elemVal, ctx))
+		}
+	case ty.IsTupleType() || ty.IsListType() || ty.IsSetType():
+		elems, diags := hcl.ExprList(expr)
+		if diags.HasErrors() {
+			break
+		}
+		for i, elem := range elems {
+			elemVal, _ := elem.Value(ctx)
+			ret.Elements = append(ret.Elements, collectValue(strconv.Itoa(i), elem, elemVal, ctx))
+		}
+	}
+
+	return ret
+}
+
+// exprKeyName returns the string value of an object key expression, or an
+// empty string if the key does not evaluate to a known string.
+func exprKeyName(expr hcl.Expression, ctx *hcl.EvalContext) string {
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return ""
+	}
+	val, err := convert.Convert(val, cty.String)
+	if err != nil || val.IsNull() || !val.IsKnown() {
+		return ""
+	}
+	val, _ = val.Unmark()
+	return val.AsString()
+}
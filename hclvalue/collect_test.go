@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclvalue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCollect(t *testing.T) {
+	src := `
+name = "web"
+
+service "http" {
+  port = 80
+  tags = {
+    env = "prod"
+  }
+  hosts = ["a", "b"]
+}
+
+addr = upstream
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	doc, diags := Collect(file, nil)
+	if len(diags) != 1 || diags[0].Summary != "Variables not allowed" {
+		t.Errorf("wrong diagnostics: %s", diags.Error())
+	}
+
+	if got, want := doc.Filename, "test.hcl"; got != want {
+		t.Errorf("wrong filename %q; want %q", got, want)
+	}
+
+	if got, want := len(doc.Values), 2; got != want {
+		t.Fatalf("wrong number of root values %d; want %d", got, want)
+	}
+	if got, want := doc.Values[0].Name, "name"; got != want {
+		t.Errorf("wrong first value name %q; want %q", got, want)
+	}
+	if got, want := doc.Values[0].Value, cty.StringVal("web"); !got.RawEquals(want) {
+		t.Errorf("wrong first value %#v; want %#v", got, want)
+	}
+	if got, want := doc.Values[1].Name, "addr"; got != want {
+		t.Errorf("wrong second value name %q; want %q", got, want)
+	}
+	if got := doc.Values[1].Value; got.IsKnown() {
+		t.Errorf("value with invalid reference is %#v; want unknown", got)
+	}
+
+	if got, want := len(doc.Blocks), 1; got != want {
+		t.Fatalf("wrong number of blocks %d; want %d", got, want)
+	}
+	block := doc.Blocks[0]
+	if block.Type != "service" || len(block.Labels) != 1 || block.Labels[0] != "http" {
+		t.Errorf("wrong block header %s %q", block.Type, block.Labels)
+	}
+	if got, want := block.DefRange.Start.Line, 4; got != want {
+		t.Errorf("wrong block start line %d; want %d", got, want)
+	}
+	if got, want := len(block.Values), 3; got != want {
+		t.Fatalf("wrong number of block values %d; want %d", got, want)
+	}
+
+	port := block.Values[0]
+	if port.Name != "port" || !port.Value.RawEquals(cty.NumberIntVal(80)) {
+		t.Errorf("wrong port value %s = %#v", port.Name, port.Value)
+	}
+	if got, want := port.Range.Start.Line, 5; got != want {
+		t.Errorf("wrong port line %d; want %d", got, want)
+	}
+
+	tags := block.Values[1]
+	if got, want := len(tags.Elements), 1; got != want {
+		t.Fatalf("wrong number of tags elements %d; want %d", got, want)
+	}
+	env := tags.Elements[0]
+	if env.Name != "env" || !env.Value.RawEquals(cty.StringVal("prod")) {
+		t.Errorf("wrong tags element %s = %#v", env.Name, env.Value)
+	}
+	if got, want := env.Range.Start.Line, 7; got != want {
+		t.Errorf("wrong tags element line %d; want %d", got, want)
+	}
+
+	hosts := block.Values[2]
+	if got, want := len(hosts.Elements), 2; got != want {
+		t.Fatalf("wrong number of hosts elements %d; want %d", got, want)
+	}
+	if got, want := hosts.Elements[1].Name, "1"; got != want {
+		t.Errorf("wrong hosts element name %q; want %q", got, want)
+	}
+	if got, want := hosts.Elements[1].Range.Start.Column, 17; got != want {
+		t.Errorf("wrong hosts element column %d; want %d", got, want)
+	}
+}
+
+func TestCollectJSON(t *testing.T) {
+	src := `{"name": "web", "service": {"port": 80}}`
+	file, diags := hcljson.Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	doc, diags := Collect(file, nil)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if got, want := len(doc.Values), 2; got != want {
+		t.Fatalf("wrong number of values %d; want %d", got, want)
+	}
+	if len(doc.Blocks) != 0 {
+		t.Errorf("unexpected blocks in JSON document")
+	}
+	service := doc.Values[1]
+	if service.Name != "service" || len(service.Elements) != 1 {
+		t.Fatalf("wrong service value %s with %d elements", service.Name, len(service.Elements))
+	}
+	port := service.Elements[0]
+	if port.Name != "port" || !port.Value.RawEquals(cty.NumberIntVal(80)) {
+		t.Errorf("wrong port value %s = %#v", port.Name, port.Value)
+	}
+	if got, want := port.Range.Start.Byte, 36; got != want {
+		t.Errorf("wrong port start byte %d; want %d", got, want)
+	}
+}
+
+func TestDecodedValueMarshalJSON(t *testing.T) {
+	rng := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 1, Column: 7, Byte: 6},
+		End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+	}
+	tests := map[string]struct {
+		val  cty.Value
+		want string
+	}{
+		"known": {
+			cty.StringVal("web"),
+			`{"name":"name","type":"string","value":"web","known":true,"range":{"filename":"test.hcl","start":{"line":1,"column":7,"byte":6},"end":{"line":1,"column":12,"byte":11}}}`,
+		},
+		"unknown": {
+			cty.UnknownVal(cty.Number),
+			`{"name":"name","type":"number","known":false,"range":{"filename":"test.hcl","start":{"line":1,"column":7,"byte":6},"end":{"line":1,"column":12,"byte":11}}}`,
+		},
+		"marked": {
+			cty.StringVal("secret").Mark("sensitive"),
+			`{"name":"name","type":"string","known":true,"marked":true,"range":{"filename":"test.hcl","start":{"line":1,"column":7,"byte":6},"end":{"line":1,"column":12,"byte":11}}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := json.Marshal(&DecodedValue{Name: "name", Value: test.val, Range: rng})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
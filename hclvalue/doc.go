@@ -0,0 +1,17 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclvalue collects all of the values in an HCL configuration file
+// into a single structure that retains, for each value, the location in the
+// source where it was defined.
+//
+// The result is intended for tools that present configuration to a user,
+// such as web-based configuration viewers, which need to link every value
+// they display back to the source line it came from. The collected values
+// can be marshaled directly to JSON for consumption by such tools.
+//
+// Unlike the "gohcl" and "hcldec" packages, this package does not need a
+// schema: it reflects whatever attributes and blocks are present in the
+// file. Because the JSON syntax cannot distinguish blocks from attributes
+// without a schema, JSON objects are always reported as attribute values.
+package hclvalue
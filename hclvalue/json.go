@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclvalue
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type posJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+type rangeJSON struct {
+	Filename string  `json:"filename"`
+	Start    posJSON `json:"start"`
+	End      posJSON `json:"end"`
+}
+
+func newRangeJSON(rng hcl.Range) rangeJSON {
+	return rangeJSON{
+		Filename: rng.Filename,
+		Start:    posJSON{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      posJSON{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
+
+type documentJSON struct {
+	Filename string          `json:"filename"`
+	Values   []*DecodedValue `json:"values"`
+	Blocks   []*DecodedBlock `json:"blocks"`
+}
+
+// MarshalJSON produces a JSON object with the properties "filename",
+// "values", and "blocks", where the latter two are arrays of the JSON
+// representations of DecodedValue and DecodedBlock respectively.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(documentJSON{
+		Filename: d.Filename,
+		Values:   nonNil(d.Values),
+		Blocks:   nonNil(d.Blocks),
+	})
+}
+
+type blockJSON struct {
+	Type     string          `json:"type"`
+	Labels   []string        `json:"labels"`
+	Range    rangeJSON       `json:"range"`
+	DefRange rangeJSON       `json:"def_range"`
+	Values   []*DecodedValue `json:"values"`
+	Blocks   []*DecodedBlock `json:"blocks"`
+}
+
+// MarshalJSON produces a JSON object with the properties "type", "labels",
+// "range", "def_range", "values", and "blocks".
+func (b *DecodedBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blockJSON{
+		Type:     b.Type,
+		Labels:   nonNil(b.Labels),
+		Range:    newRangeJSON(b.Range),
+		DefRange: newRangeJSON(b.DefRange),
+		Values:   nonNil(b.Values),
+		Blocks:   nonNil(b.Blocks),
+	})
+}
+
+type valueJSON struct {
+	Name     string          `json:"name,omitempty"`
+	Type     json.RawMessage `json:"type"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Known    bool            `json:"known"`
+	Marked   bool            `json:"marked,omitempty"`
+	Range    rangeJSON       `json:"range"`
+	Elements []*DecodedValue `json:"elements,omitempty"`
+}
+
+// MarshalJSON produces a JSON object with the properties "name", "type",
+// "value", "known", "range", and "elements".
+//
+// The type is serialized as in the go-cty JSON type representation, and
+// the value as in the go-cty JSON value representation for that type. If
+// the value is not wholly known then "value" is omitted and "known" is
+// false. If the value has marks then "value" is omitted and "marked" is
+// true, since marks often indicate values that should not be displayed.
+func (v *DecodedValue) MarshalJSON() ([]byte, error) {
+	ty := v.Type()
+	tyJSON, err := ctyjson.MarshalType(ty)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := valueJSON{
+		Name:     v.Name,
+		Type:     tyJSON,
+		Known:    v.Value.IsWhollyKnown(),
+		Marked:   v.Value.ContainsMarked(),
+		Range:    newRangeJSON(v.Range),
+		Elements: v.Elements,
+	}
+	if ret.Known && !ret.Marked {
+		ret.Value, err = ctyjson.Marshal(v.Value, ty)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(ret)
+}
+
+// nonNil ensures that empty collections are serialized as empty JSON arrays
+// rather than as null, for the convenience of consumers.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
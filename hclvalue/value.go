@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclvalue
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Document is the collection of values found in a single configuration file.
+type Document struct {
+	// Filename is the name of the file the values were collected from, as
+	// recorded in the source ranges of its contents.
+	Filename string
+
+	// Values are the attributes defined directly in the root body of the
+	// file, and Blocks are the blocks defined there, each in the order they
+	// appear in the source.
+	Values []*DecodedValue
+	Blocks []*DecodedBlock
+}
+
+// DecodedBlock is a block from a configuration file, along with the values
+// and nested blocks in its body.
+type DecodedBlock struct {
+	Type   string
+	Labels []string
+
+	// Range is the source range of the whole block, and DefRange is the
+	// range of just its header, as with hcl.Block.
+	Range    hcl.Range
+	DefRange hcl.Range
+
+	Values []*DecodedValue
+	Blocks []*DecodedBlock
+}
+
+// DecodedValue is a single value from a configuration file, along with the
+// source range of the expression that produced it.
+type DecodedValue struct {
+	// Name is the attribute name for a value assigned to an attribute, the
+	// key for an element of an object, or the decimal index for an element
+	// of a tuple. It is empty for an object element whose key could not be
+	// determined statically.
+	Name string
+
+	// Value is the result of evaluating the expression. If evaluation
+	// failed, this is cty.DynamicVal.
+	Value cty.Value
+
+	// Range is the source range of the expression that produced Value.
+	Range hcl.Range
+
+	// Elements are the individual elements of an object or tuple
+	// constructor expression, each with its own source range. It is empty
+	// for any other kind of expression, including object- or tuple-typed
+	// values that were not written out element by element in the source.
+	Elements []*DecodedValue
+}
+
+// Type returns the type of the value.
+func (v *DecodedValue) Type() cty.Type {
+	return v.Value.Type()
+}
+
+// Filename returns the name of the file the value was defined in.
+func (v *DecodedValue) Filename() string {
+	return v.Range.Filename
+}
@@ -0,0 +1,19 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclwatch monitors configuration files for changes, so that
+// long-running programs can reload their configuration without restarting.
+//
+// A Watcher polls a set of files and directories, and whenever any of the
+// configuration files among them changes it parses them again, decodes the
+// result with a function given by the caller, and delivers the outcome as an
+// Update either to a callback or over a channel. An update whose
+// diagnostics include errors has no configuration, so a program can keep
+// using its previous configuration until the problem is corrected.
+//
+// Watching works by periodically checking the modification times and sizes
+// of the files, so it requires no support from the operating system. Files
+// are parsed using an hclparse.Cache, so a file whose modification time
+// changes without its content changing is not parsed again, and a change
+// that leaves the content of all of the files as it was produces no update.
+package hclwatch
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwatch
+
+import (
+	"time"
+)
+
+// DefaultInterval is the time between checks for changes when no other
+// interval is given with OptInterval.
+const DefaultInterval = time.Second
+
+// WatchOption is implemented by values that can customize the behavior of a
+// Watcher. Use the functions in this package whose names begin with "Opt" to
+// produce watch options.
+type WatchOption interface {
+	applyWatchOption(*watchOpts)
+}
+
+type watchOpts struct {
+	interval time.Duration
+	suffixes []string
+}
+
+func newWatchOpts(opts []WatchOption) *watchOpts {
+	ret := &watchOpts{
+		interval: DefaultInterval,
+		suffixes: []string{".hcl", ".json"},
+	}
+	for _, opt := range opts {
+		opt.applyWatchOption(ret)
+	}
+	return ret
+}
+
+type optInterval time.Duration
+
+// OptInterval sets the time between checks for changes, which defaults to
+// DefaultInterval. A shorter interval detects changes sooner, at the cost
+// of examining the files more often.
+func OptInterval(d time.Duration) WatchOption {
+	return optInterval(d)
+}
+
+// applyWatchOption implements WatchOption.
+func (o optInterval) applyWatchOption(opts *watchOpts) {
+	if o > 0 {
+		opts.interval = time.Duration(o)
+	}
+}
+
+type optSuffixes []string
+
+// OptSuffixes sets the filename suffixes of the files that are loaded from
+// each watched directory, which default to ".hcl" and ".json". Files ending
+// in ".json" are parsed as JSON and all others as native syntax. Files that
+// are named directly in the watched paths are always loaded, whatever
+// their suffixes.
+func OptSuffixes(suffixes ...string) WatchOption {
+	return optSuffixes(suffixes)
+}
+
+// applyWatchOption implements WatchOption.
+func (o optSuffixes) applyWatchOption(opts *watchOpts) {
+	opts.suffixes = o
+}
@@ -0,0 +1,275 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// DecodeFunc decodes the merged body of all of the watched configuration
+// files into a configuration of type T.
+type DecodeFunc[T any] func(body hcl.Body) (T, hcl.Diagnostics)
+
+// GoDecoder returns a DecodeFunc that decodes the configuration into a new
+// value of type T using gohcl.DecodeBody, evaluating expressions in the
+// given context. T must therefore be a struct type with struct tags as
+// defined by gohcl.
+func GoDecoder[T any](ctx *hcl.EvalContext) DecodeFunc[T] {
+	return func(body hcl.Body) (T, hcl.Diagnostics) {
+		var ret T
+		diags := gohcl.DecodeBody(body, ctx, &ret)
+		return ret, diags
+	}
+}
+
+// Update is the outcome of loading the watched configuration.
+type Update[T any] struct {
+	// Config is the decoded configuration. It is the zero value of T if
+	// Diagnostics has errors.
+	Config T
+
+	// Files is the set of configuration files that were loaded, which can
+	// be used to print the diagnostics with contextual information.
+	Files map[string]*hcl.File
+
+	// Diagnostics describes any problems found while parsing or decoding
+	// the configuration.
+	Diagnostics hcl.Diagnostics
+}
+
+// Watcher loads configuration from a set of files and directories, and
+// loads it again whenever those files change.
+//
+// A Watcher must not be used by more than one goroutine at a time.
+type Watcher[T any] struct {
+	paths  []string
+	decode DecodeFunc[T]
+	opts   *watchOpts
+	cache  *hclparse.Cache
+
+	// stats and files describe the files as they were when the
+	// configuration was last loaded.
+	stats map[string]fileStat
+	files []*hcl.File
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a watcher for the given paths, each of which may name
+// either a configuration file or a directory containing configuration
+// files. Directories are not searched recursively. The configuration is
+// decoded from the bodies of all of the files merged together, as with
+// hcl.MergeFiles, in the order that the paths are given and with the files
+// in each directory in lexical order.
+func NewWatcher[T any](paths []string, decode DecodeFunc[T], opts ...WatchOption) *Watcher[T] {
+	return &Watcher[T]{
+		paths:  paths,
+		decode: decode,
+		opts:   newWatchOpts(opts),
+		cache:  hclparse.NewCache(),
+	}
+}
+
+// Load loads the configuration immediately, regardless of whether anything
+// changed since it was last loaded.
+func (w *Watcher[T]) Load() Update[T] {
+	filenames, stats, diags := w.scan()
+	w.stats = stats
+
+	files := make([]*hcl.File, 0, len(filenames))
+	update := Update[T]{
+		Files: make(map[string]*hcl.File, len(filenames)),
+	}
+	for _, filename := range filenames {
+		file, moreDiags := w.cache.ParseFile(filename)
+		diags = append(diags, moreDiags...)
+		if file != nil {
+			files = append(files, file)
+			update.Files[filename] = file
+		}
+	}
+	w.files = files
+
+	if !diags.HasErrors() {
+		config, moreDiags := w.decode(hcl.MergeFiles(files))
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			update.Config = config
+		}
+	}
+	update.Diagnostics = diags
+	return update
+}
+
+// Reload loads the configuration if any of the watched files were created,
+// changed or removed since it was last loaded, or if it hasn't yet been
+// loaded. It returns false if the configuration was not loaded, or if it
+// was loaded but the content of all of the files was the same as before.
+func (w *Watcher[T]) Reload() (Update[T], bool) {
+	if w.stats != nil {
+		_, stats, _ := w.scan()
+		if sameStats(stats, w.stats) {
+			return Update[T]{}, false
+		}
+	}
+
+	prev := w.files
+	update := w.Load()
+	if prev != nil && !update.Diagnostics.HasErrors() && sameFiles(prev, w.files) {
+		return Update[T]{}, false
+	}
+	return update, true
+}
+
+// Run loads the configuration and passes the result to the given function,
+// and then checks for changes at the configured interval, passing each new
+// result to the function, until the given context is canceled. It then
+// returns the context's error.
+func (w *Watcher[T]) Run(ctx context.Context, fn func(Update[T])) error {
+	fn(w.Load())
+
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if update, changed := w.Reload(); changed {
+				fn(update)
+			}
+		}
+	}
+}
+
+// Watch is like Run, but delivers the updates over the returned channel,
+// which is closed after the given context is canceled. The watcher waits
+// for the receiver to accept each update before checking for further
+// changes, so an update is never dropped.
+func (w *Watcher[T]) Watch(ctx context.Context) <-chan Update[T] {
+	ch := make(chan Update[T])
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(update Update[T]) {
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
+
+// scan finds the configuration files among the watched paths, returning
+// their names in the order they are to be merged along with their current
+// attributes.
+func (w *Watcher[T]) scan() ([]string, map[string]fileStat, hcl.Diagnostics) {
+	var filenames []string
+	var diags hcl.Diagnostics
+	stats := make(map[string]fileStat)
+	add := func(filename string, info os.FileInfo) {
+		if _, exists := stats[filename]; exists {
+			return
+		}
+		filenames = append(filenames, filename)
+		stats[filename] = fileStat{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+
+	for _, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read configuration",
+				Detail:   fmt.Sprintf("The configuration path %q could not be read.", path),
+			})
+			continue
+		}
+		if !info.IsDir() {
+			add(path, info)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read configuration directory",
+				Detail:   fmt.Sprintf("The configuration directory %q could not be read.", path),
+			})
+			continue
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() && w.hasSuffix(entry.Name()) {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			filename := filepath.Join(path, name)
+			info, err := os.Stat(filename)
+			if err != nil {
+				// Removed since the directory was read, so we'll see
+				// the change on the next check.
+				continue
+			}
+			add(filename, info)
+		}
+	}
+	return filenames, stats, diags
+}
+
+func (w *Watcher[T]) hasSuffix(name string) bool {
+	for _, suffix := range w.opts.suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameStats(a, b map[string]fileStat) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for filename, stat := range a {
+		other, ok := b[filename]
+		if !ok || !stat.modTime.Equal(other.modTime) || stat.size != other.size {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFiles returns true if the two slices contain the same files in the
+// same order. Because the files come from a cache, a file whose content is
+// unchanged is represented by the same object.
+func sameFiles(a, b []*hcl.File) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
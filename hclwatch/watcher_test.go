@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name     string   `hcl:"name,optional"`
+	Replicas int      `hcl:"replicas,optional"`
+	Services []string `hcl:"services,optional"`
+}
+
+func TestWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+	write := func(name, src string) {
+		t.Helper()
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Each write gets a distinct modification time, because the
+		// file system might not record times precisely enough to
+		// distinguish writes made in quick succession.
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a.hcl", "name = \"web\"\n")
+	write("b.hcl", "replicas = 2\n")
+	write("notes.txt", "not configuration")
+
+	w := NewWatcher([]string{dir}, GoDecoder[testConfig](nil))
+	update, changed := w.Reload()
+	if !changed {
+		t.Fatal("first reload reported no change")
+	}
+	if update.Diagnostics.HasErrors() {
+		t.Fatal(update.Diagnostics.Error())
+	}
+	if got, want := update.Config, (testConfig{Name: "web", Replicas: 2}); got.Name != want.Name || got.Replicas != want.Replicas {
+		t.Errorf("wrong config %#v; want %#v", got, want)
+	}
+	if got := len(update.Files); got != 2 {
+		t.Errorf("loaded %d files; want 2", got)
+	}
+
+	if _, changed := w.Reload(); changed {
+		t.Errorf("reload with no changes reported a change")
+	}
+
+	// Touching a file without changing its content isn't a change.
+	write("a.hcl", "name = \"web\"\n")
+	if _, changed := w.Reload(); changed {
+		t.Errorf("reload after touching a file reported a change")
+	}
+
+	write("b.hcl", "replicas = 3\n")
+	update, changed = w.Reload()
+	if !changed {
+		t.Fatal("reload after changing a file reported no change")
+	}
+	if got := update.Config.Replicas; got != 3 {
+		t.Errorf("wrong replicas %d; want 3", got)
+	}
+
+	// An error produces an update without configuration.
+	write("c.hcl", "replicas = \"many\"\n")
+	update, changed = w.Reload()
+	if !changed {
+		t.Fatal("reload after adding a file reported no change")
+	}
+	if !update.Diagnostics.HasErrors() {
+		t.Fatal("no errors for invalid configuration")
+	}
+	if update.Config.Name != "" {
+		t.Errorf("update with errors has configuration %#v", update.Config)
+	}
+
+	os.Remove(filepath.Join(dir, "c.hcl"))
+	update, changed = w.Reload()
+	if !changed {
+		t.Fatal("reload after removing a file reported no change")
+	}
+	if update.Diagnostics.HasErrors() {
+		t.Fatal(update.Diagnostics.Error())
+	}
+}
+
+func TestWatcherWatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.hcl")
+	if err := os.WriteFile(filename, []byte("name = \"a\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := NewWatcher([]string{filename}, GoDecoder[testConfig](nil), OptInterval(10*time.Millisecond))
+	ch := w.Watch(ctx)
+
+	update := <-ch
+	if update.Config.Name != "a" {
+		t.Fatalf("wrong initial name %q", update.Config.Name)
+	}
+
+	if err := os.WriteFile(filename, []byte("name = \"bb\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case update = <-ch:
+		if update.Config.Name != "bb" {
+			t.Errorf("wrong updated name %q", update.Config.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update after changing the file")
+	}
+
+	cancel()
+	for range ch {
+	}
+}
@@ -35,10 +35,12 @@ func (f *File) Body() *Body {
 // WriteTo writes the tokens underlying the receiving file to the given writer.
 //
 // The tokens first have a simple formatting pass applied that adjusts only
-// the spaces between them.
+// the spaces between them. Any lines added via the AST API are given the
+// same line endings as the rest of the file.
 func (f *File) WriteTo(wr io.Writer) (int64, error) {
 	tokens := f.inTree.children.BuildTokens(nil)
 	format(tokens)
+	normalizeLineEndings(tokens, dominantLineEndings(f.srcBytes))
 	return tokens.WriteTo(wr)
 }
 
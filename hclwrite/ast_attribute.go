@@ -14,6 +14,8 @@ type Attribute struct {
 	name         *node
 	expr         *node
 	lineComments *node
+
+	layout Layout
 }
 
 func newAttribute() *Attribute {
@@ -17,6 +17,8 @@ type Block struct {
 	open         *node
 	body         *node
 	close        *node
+
+	layout Layout
 }
 
 func newBlock() *Block {
@@ -144,14 +144,27 @@ func (b *Body) FirstMatchingBlock(typeName string, labels []string) *Block {
 func (b *Body) RemoveBlock(block *Block) bool {
 	for n := range b.items {
 		if n.content == block {
-			n.Detach()
-			b.items.Remove(n)
+			b.removeItemNode(n)
 			return true
 		}
 	}
 	return false
 }
 
+// removeItemNode detaches the given item node from the body.
+//
+// If the item was separated by blank lines from both of its neighbors then
+// the blank lines that followed it are also removed, so that the author's
+// chosen separation between the remaining items is preserved rather than
+// doubled.
+func (b *Body) removeItemNode(n *node) {
+	if isBlankLines(n.before) && isBlankLines(n.after) {
+		n.after.Detach()
+	}
+	n.Detach()
+	b.items.Remove(n)
+}
+
 // SetAttributeRaw either replaces the expression of an existing attribute
 // of the given name or adds a new attribute definition to the end of the block,
 // using the given tokens verbatim as the expression.
@@ -165,7 +178,7 @@ func (b *Body) SetAttributeRaw(name string, tokens Tokens) *Attribute {
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
@@ -186,7 +199,7 @@ func (b *Body) SetAttributeValue(name string, val cty.Value) *Attribute {
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
@@ -207,13 +220,45 @@ func (b *Body) SetAttributeTraversal(name string, traversal hcl.Traversal) *Attr
 	if attr != nil {
 		attr.expr = attr.expr.ReplaceWith(expr)
 	} else {
-		attr := newAttribute()
+		attr = newAttribute()
 		attr.init(name, expr)
 		b.appendItem(attr)
 	}
 	return attr
 }
 
+// SetAttributeSource either replaces the expression of an existing attribute
+// of the given name or adds a new attribute definition to the end of the body,
+// using an expression given as native syntax source code.
+//
+// Unlike SetAttributeRaw, the source code is parsed to make sure that it is
+// a single valid expression, and error diagnostics are returned without
+// changing the body if it is not. The expression's tokens, including any
+// newlines and comments within it, are otherwise used verbatim.
+//
+// The return value is the attribute that was either modified in-place or
+// created, or nil if the source code is not valid.
+func (b *Body) SetAttributeSource(name string, src []byte) (*Attribute, hcl.Diagnostics) {
+	_, diags := hclsyntax.ParseExpression(src, "<expression>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	tokens := lexConfig(src)
+	// Trim the EOF token, and any newlines at the end of the source that
+	// would otherwise end the attribute definition early.
+	end := len(tokens)
+	for end > 0 && (tokens[end-1].Type == hclsyntax.TokenEOF || tokens[end-1].Type == hclsyntax.TokenNewline) {
+		end--
+	}
+	tokens = tokens[:end]
+	if len(tokens) > 0 {
+		tokens[0].SpacesBefore = 1
+	}
+
+	return b.SetAttributeRaw(name, tokens), diags
+}
+
 // RemoveAttribute removes the attribute with the given name from the body.
 //
 // The return value is the attribute that was removed, or nil if there was
@@ -223,15 +268,25 @@ func (b *Body) RemoveAttribute(name string) *Attribute {
 	if node == nil {
 		return nil
 	}
-	node.Detach()
-	b.items.Remove(node)
+	b.removeItemNode(node)
 	return node.content.(*Attribute)
 }
 
 // AppendBlock appends an existing block (which must not be already attached
 // to a body) to the end of the receiving body.
+//
+// If the block's layout calls for blank lines before or after it then
+// AppendBlock will insert them, unless the body is empty or already ends
+// with a blank line.
 func (b *Body) AppendBlock(block *Block) *Block {
+	layout := block.Layout()
+	if layout.BlankLineBefore && b.children.last != nil && !isBlankLines(b.children.last) {
+		b.AppendNewline()
+	}
 	b.appendItem(block)
+	if layout.BlankLineAfter {
+		b.AppendNewline()
+	}
 	return block
 }
 
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// GroupAttributes moves the attributes of the receiving body whose names
+// begin with the given prefix and separator into a nested block whose type
+// name is the prefix, removing the prefix and separator from their names.
+//
+// For example, with the prefix "db" and the separator "_", the attributes
+// db_host and db_port become the attributes host and port of a block of type
+// "db". This is useful for migrating a flat configuration to a structured
+// one.
+//
+// If the body already contains an unlabelled block of the given type then
+// the attributes are moved into that block. Otherwise a new block is created
+// in place of the first matching attribute. Any attribute whose new name
+// would not be a valid identifier, or would conflict with an attribute
+// already present in the block, is left unchanged.
+//
+// The return value is the block that the attributes were moved into, or nil
+// if there were no matching attributes, in which case the call was a no-op.
+func (b *Body) GroupAttributes(prefix, sep string) *Block {
+	namePrefix := prefix + sep
+
+	var matches []*node
+	for n := b.children.first; n != nil; n = n.after {
+		attr, isAttr := n.content.(*Attribute)
+		if !isAttr || !b.items.Has(n) {
+			continue
+		}
+		name := attr.name.content.(*identifier).token.Bytes
+		if strings.HasPrefix(string(name), namePrefix) && hclsyntax.ValidIdentifier(string(name[len(namePrefix):])) {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	block := b.FirstMatchingBlock(prefix, nil)
+	if block == nil {
+		block = newBlock()
+		block.init(prefix, nil)
+		blockNode := newNode(block)
+		b.children.InsertNode(matches[0], blockNode)
+		b.items.Add(blockNode)
+	}
+	target := block.Body()
+
+	for _, n := range matches {
+		attr := n.content.(*Attribute)
+		name := string(attr.name.content.(*identifier).token.Bytes)[len(namePrefix):]
+		if target.GetAttribute(name) != nil {
+			continue
+		}
+
+		b.removeItemNode(n)
+		attr.setName(name)
+		target.appendItemNode(n)
+	}
+
+	return block
+}
+
+// FlattenBlock is the inverse of GroupAttributes: it replaces the given
+// block with its attributes, renamed to begin with the block's type name and
+// the given separator.
+//
+// For example, with the separator "_", a block of type "db" containing the
+// attributes host and port is replaced by the attributes db_host and
+// db_port, in the position the block occupied.
+//
+// Only an unlabelled block containing no nested blocks can be flattened. If
+// the given block is not in the receiving body, is not eligible, or if any
+// of the new attribute names would conflict with an attribute already in the
+// body, then this is a no-op.
+//
+// Returns true if the block was flattened, or false otherwise.
+func (b *Body) FlattenBlock(block *Block, sep string) bool {
+	var blockNode *node
+	for n := range b.items {
+		if n.content == block {
+			blockNode = n
+			break
+		}
+	}
+	if blockNode == nil || len(block.Labels()) != 0 {
+		return false
+	}
+
+	namePrefix := block.Type() + sep
+	body := block.Body()
+	var attrNodes []*node
+	for n := body.children.first; n != nil; n = n.after {
+		switch c := n.content.(type) {
+		case *Attribute:
+			name := string(c.name.content.(*identifier).token.Bytes)
+			if b.GetAttribute(namePrefix+name) != nil {
+				return false
+			}
+			attrNodes = append(attrNodes, n)
+		case *Block:
+			return false
+		}
+	}
+
+	for _, n := range attrNodes {
+		attr := n.content.(*Attribute)
+		name := string(attr.name.content.(*identifier).token.Bytes)
+
+		body.removeItemNode(n)
+		attr.setName(namePrefix + name)
+		attr.ensureTrailingNewline()
+		b.children.InsertNode(blockNode, n)
+		b.items.Add(n)
+	}
+	b.removeItemNode(blockNode)
+
+	return true
+}
+
+// ensureTrailingNewline appends a newline to the tokens of the receiving
+// attribute if it doesn't already end with one, as is the case for the
+// arguments of a single-line block.
+func (a *Attribute) ensureTrailingNewline() {
+	toks := a.BuildTokens(nil)
+	if len(toks) > 0 && toks[len(toks)-1].Type == hclsyntax.TokenNewline {
+		return
+	}
+	a.children.AppendUnstructuredTokens(Tokens{newNewlineToken()})
+}
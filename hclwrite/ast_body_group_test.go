@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestBodyGroupAttributes(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"new block": {
+			`name = "a"
+# The database host.
+db_host = "localhost"
+db_port = 5432
+other = true
+`,
+			`name = "a"
+db {
+  # The database host.
+  host = "localhost"
+  port = 5432
+}
+other = true
+`,
+		},
+		"first item": {
+			`db_host = "localhost"
+`,
+			`db {
+  host = "localhost"
+}
+`,
+		},
+		"existing block": {
+			`db_port = 5432
+db {
+  host = "localhost"
+}
+`,
+			`db {
+  host = "localhost"
+  port = 5432
+}
+`,
+		},
+		"conflict": {
+			`db_host = "a"
+db {
+  host = "b"
+}
+`,
+			`db_host = "a"
+db {
+  host = "b"
+}
+`,
+		},
+		"no matches": {
+			`dbhost = "a"
+db_    = "b"
+`,
+			`dbhost = "a"
+db_    = "b"
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			f.Body().GroupAttributes("db", "_")
+
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyFlattenBlock(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		want   string
+		wantOk bool
+	}{
+		"multi-line": {
+			`name = "a"
+db {
+  host = "localhost"
+  port = 5432
+}
+other = true
+`,
+			`name    = "a"
+db_host = "localhost"
+db_port = 5432
+other   = true
+`,
+			true,
+		},
+		"single-line": {
+			`db { host = "localhost" }
+`,
+			`db_host = "localhost"
+`,
+			true,
+		},
+		"nested block": {
+			`db {
+  host = "localhost"
+  tls {}
+}
+`,
+			`db {
+  host = "localhost"
+  tls {}
+}
+`,
+			false,
+		},
+		"conflict": {
+			`db_host = "a"
+db {
+  host = "b"
+}
+`,
+			`db_host = "a"
+db {
+  host = "b"
+}
+`,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			body := f.Body()
+			ok := body.FlattenBlock(body.FirstMatchingBlock("db", nil), "_")
+			if ok != test.wantOk {
+				t.Errorf("wrong result %t; want %t", ok, test.wantOk)
+			}
+
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -1036,6 +1036,80 @@ func TestBodySetAttributeValueInNestedBlock(t *testing.T) {
 	}
 }
 
+func TestBodySetAttributeSource(t *testing.T) {
+	tests := []struct {
+		src  string
+		name string
+		expr string
+		want string
+	}{
+		{
+			"",
+			"a",
+			`var.foo`,
+			"a = var.foo\n",
+		},
+		{
+			"# leading comment\na = 1 # trailing comment\n\nb = 2\n",
+			"a",
+			`upper("x")`,
+			"# leading comment\na = upper(\"x\") # trailing comment\n\nb = 2\n",
+		},
+		{
+			"a = 1\n",
+			"b",
+			"[\n  1, # one\n  2,\n]\n",
+			"a = 1\nb = [\n  1, # one\n  2,\n]\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s = %s in %s", test.name, test.expr, test.src), func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+
+			attr, diags := f.Body().SetAttributeSource(test.name, []byte(test.expr))
+			if len(diags) != 0 {
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+				t.Fatalf("unexpected diagnostics")
+			}
+			if attr == nil {
+				t.Fatalf("no attribute returned")
+			}
+			if got := f.Body().GetAttribute(test.name); got != attr {
+				t.Errorf("returned attribute is not the one in the body")
+			}
+
+			got := string(f.Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		src := "a = 1\n"
+		f, _ := ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+		attr, diags := f.Body().SetAttributeSource("a", []byte("1 +"))
+		if !diags.HasErrors() {
+			t.Fatalf("succeeded; want error")
+		}
+		if attr != nil {
+			t.Errorf("returned attribute for invalid expression")
+		}
+		if got := string(f.Bytes()); got != src {
+			t.Errorf("body was modified\ngot:\n%s\nwant:\n%s", got, src)
+		}
+	})
+}
+
 func TestBodyRemoveAttribute(t *testing.T) {
 	tests := []struct {
 		src  string
@@ -1754,3 +1828,197 @@ bar {}
 	}
 
 }
+
+func TestBodyItemLayout(t *testing.T) {
+	src := `a = 1
+b = 2
+
+# Foo
+foo { c = 3 }
+
+bar {
+  d = [
+    1,
+  ]
+}
+`
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	body := f.Body()
+
+	tests := map[string]struct {
+		got  Layout
+		want Layout
+	}{
+		"a": {
+			body.GetAttribute("a").Layout(),
+			Layout{SingleLine: true},
+		},
+		"b": {
+			body.GetAttribute("b").Layout(),
+			Layout{SingleLine: true, BlankLineAfter: true},
+		},
+		"foo": {
+			body.FirstMatchingBlock("foo", nil).Layout(),
+			Layout{SingleLine: true, BlankLineBefore: true, BlankLineAfter: true},
+		},
+		"bar": {
+			body.FirstMatchingBlock("bar", nil).Layout(),
+			Layout{BlankLineBefore: true},
+		},
+		"bar.d": {
+			body.FirstMatchingBlock("bar", nil).Body().GetAttribute("d").Layout(),
+			Layout{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.got != test.want {
+				t.Errorf("wrong layout\ngot:  %#v\nwant: %#v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyAppendBlockLayout(t *testing.T) {
+	f, diags := ParseConfig([]byte("a = 1\n"), "", hcl.InitialPos)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	body := f.Body()
+
+	block := NewBlock("foo", nil)
+	block.SetLayout(Layout{BlankLineBefore: true, BlankLineAfter: true})
+	body.AppendBlock(block)
+	body.SetAttributeValue("b", cty.NumberIntVal(2))
+
+	got := string(f.Bytes())
+	want := `a = 1
+
+foo {
+}
+
+b = 2
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBodyEditLineEndings(t *testing.T) {
+	src := "a = 1\r\n\r\nblk {\r\n}\r\n"
+	f, diags := ParseConfig([]byte(src), "", hcl.InitialPos)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	f.Body().SetAttributeValue("b", cty.True)
+	f.Body().FirstMatchingBlock("blk", nil).Body().SetAttributeValue("c", cty.NumberIntVal(2))
+	f.Body().AppendNewBlock("new", []string{"x"})
+
+	got := string(f.Bytes())
+	want := "a = 1\r\n\r\nblk {\r\n  c = 2\r\n}\r\nb = true\r\nnew \"x\" {\r\n}\r\n"
+	if got != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestBodyLayoutBlankLines(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		edit func(body *Body)
+		want string
+	}{
+		"remove attribute between blank lines": {
+			"a = 1\n\nb = 2\n\nc = 3\n",
+			func(body *Body) {
+				body.RemoveAttribute("b")
+			},
+			"a = 1\n\nc = 3\n",
+		},
+		"remove attribute after blank line": {
+			"a = 1\n\nb = 2\nc = 3\n",
+			func(body *Body) {
+				body.RemoveAttribute("b")
+			},
+			"a = 1\n\nc = 3\n",
+		},
+		"remove attribute before blank line": {
+			"a = 1\nb = 2\n\nc = 3\n",
+			func(body *Body) {
+				body.RemoveAttribute("b")
+			},
+			"a = 1\n\nc = 3\n",
+		},
+		"remove block between blank lines": {
+			"a = 1\n\nfoo {\n}\n\nb = 2\n",
+			func(body *Body) {
+				body.RemoveBlock(body.FirstMatchingBlock("foo", nil))
+			},
+			"a = 1\n\nb = 2\n",
+		},
+		"remove last block after blank line": {
+			"a = 1\n\nfoo {\n}\n",
+			func(body *Body) {
+				body.RemoveBlock(body.FirstMatchingBlock("foo", nil))
+			},
+			"a = 1\n\n",
+		},
+		"append block to empty body": {
+			"",
+			func(body *Body) {
+				block := NewBlock("foo", nil)
+				block.SetLayout(Layout{BlankLineBefore: true})
+				body.AppendBlock(block)
+			},
+			"foo {\n}\n",
+		},
+		"append block after blank line": {
+			"a = 1\n\n",
+			func(body *Body) {
+				block := NewBlock("foo", nil)
+				block.SetLayout(Layout{BlankLineBefore: true})
+				body.AppendBlock(block)
+			},
+			"a = 1\n\nfoo {\n}\n",
+		},
+		"append block with blank line after": {
+			"a = 1\n",
+			func(body *Body) {
+				block := NewBlock("foo", nil)
+				block.SetLayout(Layout{BlankLineAfter: true})
+				body.AppendBlock(block)
+			},
+			"a = 1\nfoo {\n}\n\n",
+		},
+		"append block moved from another body": {
+			"a = 1\n",
+			func(body *Body) {
+				other, diags := ParseConfig([]byte("b = 2\n\nfoo {\n}\n\nc = 3\n"), "", hcl.InitialPos)
+				if len(diags) != 0 {
+					t.Fatalf("unexpected diagnostics: %s", diags.Error())
+				}
+				block := other.Body().FirstMatchingBlock("foo", nil)
+				other.Body().RemoveBlock(block)
+				body.AppendBlock(block)
+			},
+			"a = 1\n\nfoo {\n}\n\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.InitialPos)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			test.edit(f.Body())
+
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
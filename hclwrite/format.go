@@ -157,11 +157,16 @@ func formatSpaces(lines []formatLine) {
 }
 
 func formatCells(lines []formatLine) {
+	// We'll deal with the "assign" cell first, since moving that will
+	// also impact the "comment" cell.
+	formatAssignCells(lines)
+	formatCommentCells(lines)
+}
+
+func formatAssignCells(lines []formatLine) {
 	chainStart := -1
 	maxColumns := 0
 
-	// We'll deal with the "assign" cell first, since moving that will
-	// also impact the "comment" cell.
 	closeAssignChain := func(i int) {
 		for _, chainLine := range lines[chainStart:i] {
 			columns := chainLine.lead.Columns()
@@ -189,8 +194,12 @@ func formatCells(lines []formatLine) {
 	if chainStart != -1 {
 		closeAssignChain(len(lines))
 	}
+}
+
+func formatCommentCells(lines []formatLine) {
+	chainStart := -1
+	maxColumns := 0
 
-	// Now we'll deal with the comments
 	closeCommentChain := func(i int) {
 		for _, chainLine := range lines[chainStart:i] {
 			columns := chainLine.lead.Columns() + chainLine.assign.Columns()
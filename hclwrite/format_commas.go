@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/internal/hclsyntaxutil"
+)
+
+// commaEdit is a change to the trailing comma of a collection constructor:
+// either the insertion of a comma at the given offset, or the removal of the
+// comma that begins there.
+type commaEdit struct {
+	offset int
+	remove bool
+}
+
+// applyTrailingCommas returns the given source code with the trailing commas
+// of its tuple and object constructors added or removed according to the
+// given policy. The source code is returned unchanged if it has syntax
+// errors.
+func applyTrailingCommas(src []byte, policy TrailingCommas) []byte {
+	f, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+
+	var edits []commaEdit
+	hclsyntax.VisitAll(f.Body.(*hclsyntax.Body), func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(hclsyntax.Expression)
+		if !ok {
+			return nil
+		}
+		tc, ok := hclsyntaxutil.FindTrailingComma(tokens, expr)
+		if !ok {
+			return nil
+		}
+		switch {
+		case policy == TrailingCommasStrip && tc.Comma != nil:
+			edits = append(edits, commaEdit{offset: tc.Comma.Range.Start.Byte, remove: true})
+		case policy == TrailingCommasAdd && tc.Comma == nil && tc.Separated && tc.OwnLine:
+			edits = append(edits, commaEdit{offset: tc.Last.End.Byte})
+		}
+		return nil
+	})
+	if len(edits) == 0 {
+		return src
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].offset < edits[j].offset
+	})
+	ret := make([]byte, 0, len(src)+len(edits))
+	pos := 0
+	for _, edit := range edits {
+		ret = append(ret, src[pos:edit.offset]...)
+		pos = edit.offset
+		if edit.remove {
+			pos++
+		} else {
+			ret = append(ret, ',')
+		}
+	}
+	return append(ret, src[pos:]...)
+}
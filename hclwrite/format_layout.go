@@ -0,0 +1,227 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// layoutEdits describes a set of changes to a flat token sequence, which we
+// use to change the layout of blocks without disturbing the AST that the
+// tokens belong to.
+type layoutEdits struct {
+	drop        map[*Token]bool
+	insertAfter map[*Token]Tokens
+}
+
+// formatBlockLayout returns the tokens of the given file with the layout of
+// its blocks adjusted as requested in the given options. The returned tokens
+// have not yet been formatted.
+func formatBlockLayout(f *File, opts *formatOpts) Tokens {
+	edits := layoutEdits{
+		drop:        make(map[*Token]bool),
+		insertAfter: make(map[*Token]Tokens),
+	}
+
+	var visit func(body *Body, depth int)
+	visit = func(body *Body, depth int) {
+		for _, block := range body.Blocks() {
+			visit(block.Body(), depth+1)
+
+			switch opts.blockLayout {
+			case blockLayoutCollapse:
+				edits.collapseBlock(block, depth, opts.maxWidth, opts.multiArgBlocks)
+			case blockLayoutExpand:
+				edits.expandBlock(block)
+			}
+		}
+	}
+	visit(f.Body(), 0)
+
+	tokens := f.BuildTokens(nil)
+	ret := make(Tokens, 0, len(tokens))
+	for _, tok := range tokens {
+		if !edits.drop[tok] {
+			ret = append(ret, tok)
+		}
+		ret = append(ret, edits.insertAfter[tok]...)
+	}
+	return ret
+}
+
+// collapseBlock arranges for the given block to be rewritten into the
+// single-line block syntax, if it is eligible and fits within the given
+// width when indented to the given depth. A block with more than one
+// argument is eligible only if multiArg is set.
+func (e layoutEdits) collapseBlock(block *Block, depth, maxWidth int, multiArg bool) {
+	header, interior, footer := blockTokens(block)
+	if interior == nil {
+		return
+	}
+
+	var attrs []*Attribute
+	body := block.Body()
+	for n := body.children.first; n != nil; n = n.after {
+		switch c := n.content.(type) {
+		case *Attribute:
+			attrs = append(attrs, c)
+		case Tokens:
+			if !isBlankLines(n) {
+				return
+			}
+		default:
+			return
+		}
+	}
+
+	if len(attrs) > 1 && !multiArg {
+		return
+	}
+
+	for _, tok := range interior {
+		switch tok.Type {
+		case hclsyntax.TokenComment, hclsyntax.TokenOHeredoc:
+			return
+		}
+	}
+
+	// Each attribute must occupy exactly one line, and so have only the
+	// newline token that terminates it.
+	attrToks := make([]Tokens, len(attrs))
+	for i, attr := range attrs {
+		toks := attr.BuildTokens(nil)
+		if len(toks) == 0 || countNewlines(toks) != 1 || toks[len(toks)-1].Type != hclsyntax.TokenNewline {
+			return
+		}
+		attrToks[i] = toks[:len(toks)-1]
+	}
+
+	// To measure the result we'll format a copy of the line it would
+	// produce, so we don't disturb the real tokens.
+	var line Tokens
+	line = appendTokenCopies(line, header)
+	for i, toks := range attrToks {
+		line = appendTokenCopies(line, toks)
+		if i < len(attrToks)-1 {
+			line = append(line, newCommaToken())
+		}
+	}
+	line = appendTokenCopies(line, footer[:1])
+	format(line)
+	if 2*depth+line.Columns() > maxWidth {
+		return
+	}
+
+	for _, tok := range interior {
+		if tok.Type == hclsyntax.TokenNewline {
+			e.drop[tok] = true
+		}
+	}
+	for i, toks := range attrToks {
+		if i == len(attrToks)-1 {
+			break
+		}
+		last := toks[len(toks)-1]
+		e.insertAfter[last] = append(e.insertAfter[last], newCommaToken())
+	}
+}
+
+// expandBlock arranges for the given block to be rewritten into the
+// multi-line block syntax if it is currently using the single-line syntax.
+func (e layoutEdits) expandBlock(block *Block) {
+	header, interior, _ := blockTokens(block)
+	if len(interior) == 0 || countNewlines(interior) != 0 {
+		return
+	}
+
+	open := header[len(header)-1]
+	e.insertAfter[open] = append(e.insertAfter[open], newNewlineToken())
+
+	body := block.Body()
+	for n := body.children.first; n != nil; n = n.after {
+		switch c := n.content.(type) {
+		case *Attribute:
+			toks := c.BuildTokens(nil)
+			if len(toks) == 0 {
+				continue
+			}
+			last := toks[len(toks)-1]
+			e.insertAfter[last] = append(e.insertAfter[last], newNewlineToken())
+		case Tokens:
+			// The commas separating the arguments are not part of the
+			// arguments themselves, so they appear between the items.
+			for _, tok := range c {
+				if tok.Type == hclsyntax.TokenComma {
+					e.drop[tok] = true
+				}
+			}
+		}
+	}
+}
+
+// blockTokens splits the tokens of the given block into the header, ending
+// with the opening brace, the interior between the braces, and the footer,
+// starting with the closing brace. The interior is nil if the braces cannot
+// be found.
+func blockTokens(block *Block) (header, interior, footer Tokens) {
+	if block.open == nil || block.close == nil {
+		return nil, nil, nil
+	}
+	openToks, ok := block.open.content.(Tokens)
+	if !ok || len(openToks) == 0 {
+		return nil, nil, nil
+	}
+	closeToks, ok := block.close.content.(Tokens)
+	if !ok || len(closeToks) == 0 {
+		return nil, nil, nil
+	}
+
+	toks := block.BuildTokens(nil)
+	openIdx, closeIdx := -1, -1
+	for i, tok := range toks {
+		switch tok {
+		case openToks[0]:
+			openIdx = i
+		case closeToks[0]:
+			closeIdx = i
+		}
+	}
+	if openIdx < 0 || closeIdx < openIdx {
+		return nil, nil, nil
+	}
+
+	return toks[:openIdx+1], toks[openIdx+1 : closeIdx : closeIdx], toks[closeIdx:]
+}
+
+func countNewlines(toks Tokens) int {
+	ret := 0
+	for _, tok := range toks {
+		if tok.Type == hclsyntax.TokenNewline {
+			ret++
+		}
+	}
+	return ret
+}
+
+func appendTokenCopies(to Tokens, toks Tokens) Tokens {
+	for _, tok := range toks {
+		cp := *tok
+		to = append(to, &cp)
+	}
+	return to
+}
+
+func newCommaToken() *Token {
+	return &Token{
+		Type:  hclsyntax.TokenComma,
+		Bytes: []byte{','},
+	}
+}
+
+func newNewlineToken() *Token {
+	return &Token{
+		Type:  hclsyntax.TokenNewline,
+		Bytes: []byte{'\n'},
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2/ext/redact"
+)
+
+// redactBody replaces the value of each attribute in the given body, and
+// recursively in the bodies of its nested blocks, whose path matches one of
+// the given patterns, as described for OptRedact. The given path is that
+// of the body itself.
+func redactBody(body *Body, path []string, paths redact.Paths) {
+	for name := range body.Attributes() {
+		if paths.Match(appendPath(path, name)) {
+			body.SetAttributeValue(name, cty.StringVal(redact.Placeholder))
+		}
+	}
+	for _, block := range body.Blocks() {
+		blockPath := appendPath(path, block.Type())
+		blockPath = append(blockPath, block.Labels()...)
+		redactBody(block.Body(), blockPath, paths)
+	}
+}
+
+// appendPath returns a new path with the given element appended, leaving
+// the given path unchanged.
+func appendPath(path []string, elem string) []string {
+	ret := make([]string, len(path), len(path)+1)
+	copy(ret, path)
+	return append(ret, elem)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// sortBody rearranges the items in the given body, and recursively in the
+// bodies of its nested blocks, into the canonical order used by
+// OptSortBodies.
+func sortBody(body *Body) {
+	type entry struct {
+		nodes []*node // any detached comments, followed by the item itself
+		name  string  // attribute name or block type
+		block bool
+	}
+
+	var header, pending []*node
+	var entries []entry
+	sortable := true
+	for n := body.children.first; n != nil; n = n.after {
+		switch c := n.content.(type) {
+		case *Attribute:
+			entries = append(entries, entry{
+				nodes: append(pending, n),
+				name:  string(c.name.content.(*identifier).token.Bytes),
+			})
+			pending = nil
+			sortable = sortable && endsWithNewline(n)
+		case *Block:
+			sortBody(c.Body())
+			entries = append(entries, entry{
+				nodes: append(pending, n),
+				name:  c.Type(),
+				block: true,
+			})
+			pending = nil
+			sortable = sortable && endsWithNewline(n)
+		default:
+			switch {
+			case len(entries) == 0:
+				// Anything before the first item, such as a file header
+				// comment or the newline after a block's opening brace,
+				// stays at the top.
+				header = append(header, n)
+			case isBlankLines(n):
+				// Blank lines are regenerated below.
+			default:
+				pending = append(pending, n)
+			}
+		}
+	}
+	if !sortable || len(entries) == 0 {
+		// Single-line blocks have no room for rearrangement.
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].block != entries[j].block {
+			return !entries[i].block
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	body.children.Clear()
+	for _, n := range header {
+		appendDetachedNode(body.children, n)
+	}
+	for i, e := range entries {
+		// Attributes form a single group, and each block is separated from
+		// whatever precedes it by a blank line.
+		if e.block && i > 0 {
+			body.children.AppendUnstructuredTokens(Tokens{
+				{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}},
+			})
+		}
+		for _, n := range e.nodes {
+			appendDetachedNode(body.children, n)
+		}
+	}
+	for _, n := range pending {
+		appendDetachedNode(body.children, n)
+	}
+}
+
+// appendDetachedNode appends the given node to the given list after
+// discarding its links to its former neighbors.
+func appendDetachedNode(list *nodes, n *node) {
+	n.list, n.before, n.after = nil, nil, nil
+	list.AppendNode(n)
+}
+
+// endsWithNewline returns true if the tokens of the given node end with a
+// newline, as do all items in a body other than those in a single-line
+// block.
+func endsWithNewline(n *node) bool {
+	toks := n.BuildTokens(nil)
+	return len(toks) > 0 && tokenIsNewline(toks[len(toks)-1])
+}
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// limitBlankLines returns the given tokens without any newline tokens that
+// would make a run of blank lines longer than the given maximum. The given
+// tokens have not yet been formatted.
+func limitBlankLines(tokens Tokens, max int) Tokens {
+	ret := make(Tokens, 0, len(tokens))
+	atLineStart := true
+	blanks := 0
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenNewline && atLineStart {
+			blanks++
+			if blanks > max {
+				continue
+			}
+		} else {
+			blanks = 0
+		}
+		atLineStart = tokenIsNewline(tok)
+		ret = append(ret, tok)
+	}
+	return ret
+}
+
+// unalignEquals undoes the vertical alignment of equals signs made by
+// format, leaving a single space before each, and then realigns any
+// trailing comments to suit.
+func unalignEquals(tokens Tokens) {
+	lines := linesForFormat(tokens)
+	for _, line := range lines {
+		if line.assign != nil {
+			line.assign[0].SpacesBefore = 1
+		}
+	}
+	formatCommentCells(lines)
+}
+
+// reindent replaces the two spaces per level of indentation chosen by
+// format with the given indent string. Since the Token type can represent
+// only spaces between tokens, the indentation is moved into the bytes of
+// the first token on each line, so the tokens must be used only for
+// writing afterwards.
+func reindent(tokens Tokens, indent string) {
+	for _, line := range linesForFormat(tokens) {
+		if len(line.lead) == 0 || line.lead[0].Type == hclsyntax.TokenNewline {
+			continue
+		}
+		tok := line.lead[0]
+		level := tok.SpacesBefore / 2
+		if level == 0 {
+			continue
+		}
+		newBytes := make([]byte, 0, level*len(indent)+len(tok.Bytes))
+		newBytes = append(newBytes, bytes.Repeat([]byte(indent), level)...)
+		newBytes = append(newBytes, tok.Bytes...)
+		tok.Bytes = newBytes
+		tok.SpacesBefore = 0
+	}
+}
+
+// applyTrailingNewline adjusts the line endings at the end of the given
+// formatted source according to the given policy, using the given style
+// for any line ending it adds.
+func applyTrailingNewline(src []byte, policy TrailingNewline, style LineEndings) []byte {
+	if policy == TrailingNewlinePreserve {
+		return src
+	}
+	trimmed := bytes.TrimRight(src, "\r\n")
+	if policy == TrailingNewlineNever || len(trimmed) == 0 {
+		return trimmed
+	}
+	if style == LineEndingsCRLF {
+		return append(trimmed, '\r', '\n')
+	}
+	return append(trimmed, '\n')
+}
@@ -650,6 +650,237 @@ module "x" {
 
 }
 
+func TestFormatBlockLayout(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		opts  []FormatOption
+		want  string
+	}{
+		"collapse": {
+			`
+limits {
+  cpu = 1
+
+  mem = 2
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80), OptMultiArgumentBlocks()},
+			`
+limits { cpu = 1, mem = 2 }
+`,
+		},
+		"collapse single argument only": {
+			`
+limits {
+  cpu = 1
+  mem = 2
+}
+other {
+  cpu = 1
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80)},
+			`
+limits {
+  cpu = 1
+  mem = 2
+}
+other { cpu = 1 }
+`,
+		},
+		"collapse nested": {
+			`
+job "a" {
+  limits {
+    cpu = 1
+  }
+  name = "a"
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80)},
+			`
+job "a" {
+  limits { cpu = 1 }
+  name = "a"
+}
+`,
+		},
+		"collapse empty": {
+			`
+empty {
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80)},
+			`
+empty {}
+`,
+		},
+		"collapse too wide": {
+			`
+limits {
+  cpu    = 1
+  memory = 2
+}
+`,
+			[]FormatOption{OptCollapseBlocks(20)},
+			`
+limits {
+  cpu    = 1
+  memory = 2
+}
+`,
+		},
+		"collapse with comment": {
+			`
+limits {
+  cpu = 1 # cores
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80)},
+			`
+limits {
+  cpu = 1 # cores
+}
+`,
+		},
+		"collapse with multi-line expression": {
+			`
+limits {
+  cpus = [
+    1,
+  ]
+}
+`,
+			[]FormatOption{OptCollapseBlocks(80)},
+			`
+limits {
+  cpus = [
+    1,
+  ]
+}
+`,
+		},
+		"expand": {
+			`
+job "a" {
+  limits { cpu = 1, mem = 2 }
+}
+`,
+			[]FormatOption{OptExpandBlocks(), OptMultiArgumentBlocks()},
+			`
+job "a" {
+  limits {
+    cpu = 1
+    mem = 2
+  }
+}
+`,
+		},
+		"expand single argument": {
+			`
+limits { cpu = 1 }
+`,
+			[]FormatOption{OptExpandBlocks()},
+			`
+limits {
+  cpu = 1
+}
+`,
+		},
+		"expand without multiple arguments": {
+			`
+limits { cpu = 1, mem = 2 }
+`,
+			[]FormatOption{OptExpandBlocks()},
+			`
+limits { cpu = 1, mem = 2 }
+`,
+		},
+		"expand empty": {
+			`
+empty {}
+`,
+			[]FormatOption{OptExpandBlocks()},
+			`
+empty {}
+`,
+		},
+		"syntax error": {
+			`
+limits { cpu = 1, mem = 2 }
+a = 
+`,
+			[]FormatOption{OptExpandBlocks()},
+			`
+limits { cpu = 1, mem = 2 }
+a =
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(Format([]byte(test.input), test.opts...))
+			if got != test.want {
+				t.Errorf("wrong result\ninput:\n%s\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatLineEndings(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		opts  []FormatOption
+		want  string
+	}{
+		"preserve lf": {
+			"a=1\nb = <<EOT\nhi\nEOT\n",
+			nil,
+			"a = 1\nb = <<EOT\nhi\nEOT\n",
+		},
+		"preserve crlf": {
+			"a=1\r\n# c\r\nb = <<EOT\r\nhi\r\nEOT\r\n",
+			nil,
+			"a = 1\r\n# c\r\nb = <<EOT\r\nhi\r\nEOT\r\n",
+		},
+		"preserve mixed": {
+			"a=1\r\nb=2\r\nc=3\n",
+			[]FormatOption{OptLineEndings(LineEndingsPreserve)},
+			"a = 1\r\nb = 2\r\nc = 3\r\n",
+		},
+		"preserve heredoc": {
+			"a=1\r\nb=2\r\nc = <<EOT\nx\nEOT\r\n",
+			nil,
+			"a = 1\r\nb = 2\r\nc = <<EOT\nx\nEOT\r\n",
+		},
+		"preserve expanded block": {
+			"limits { cpu = 1 }\r\n",
+			[]FormatOption{OptExpandBlocks()},
+			"limits {\r\n  cpu = 1\r\n}\r\n",
+		},
+		"crlf to lf": {
+			"a=1\r\n/* x\r\ny */\r\nb = <<EOT\r\nhi\r\nEOT\r\n",
+			[]FormatOption{OptLineEndings(LineEndingsLF)},
+			"a = 1\n/* x\r\ny */\nb = <<EOT\r\nhi\r\nEOT\n",
+		},
+		"lf to crlf": {
+			"a=1\n# c\nb = <<EOT\nhi\nEOT\n",
+			[]FormatOption{OptLineEndings(LineEndingsCRLF)},
+			"a = 1\r\n# c\r\nb = <<EOT\nhi\nEOT\r\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(Format([]byte(test.input), test.opts...))
+			if got != test.want {
+				t.Errorf("wrong result\ninput:\n%q\ngot:\n%q\nwant:\n%q", test.input, got, test.want)
+			}
+		})
+	}
+}
+
 func TestLinesForFormat(t *testing.T) {
 	tests := []struct {
 		tokens Tokens
@@ -798,3 +1029,297 @@ func TestLinesForFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatStyleOptions(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		opts  []FormatOption
+		want  string
+	}{
+		"tab indent": {
+			"a {\nb {\nc = 1\nlong_name = 2\n}\n}\n",
+			[]FormatOption{OptIndent("\t")},
+			"a {\n\tb {\n\t\tc         = 1\n\t\tlong_name = 2\n\t}\n}\n",
+		},
+		"four space indent": {
+			"a {\nb = [\n1,\n]\n}\n",
+			[]FormatOption{OptIndent("    ")},
+			"a {\n    b = [\n        1,\n    ]\n}\n",
+		},
+		"indent leaves heredoc alone": {
+			"a {\nb = <<EOT\n  hi\nEOT\n}\n",
+			[]FormatOption{OptIndent("\t")},
+			"a {\n\tb = <<EOT\n  hi\nEOT\n}\n",
+		},
+		"no equals alignment": {
+			"a = 1 # one\nlong_name = 2 # two\n",
+			[]FormatOption{OptAlignEquals(false)},
+			"a = 1         # one\nlong_name = 2 # two\n",
+		},
+		"equals alignment": {
+			"a = 1\nlong_name = 2\n",
+			[]FormatOption{OptAlignEquals(true)},
+			"a         = 1\nlong_name = 2\n",
+		},
+		"max one blank line": {
+			"a = 1\n\n\n\nb {\n\n\nc = <<EOT\n\n\nEOT\n}\n",
+			[]FormatOption{OptMaxBlankLines(1)},
+			"a = 1\n\nb {\n\n  c = <<EOT\n\n\nEOT\n}\n",
+		},
+		"no blank lines": {
+			"a = 1\n\nb = 2\n",
+			[]FormatOption{OptMaxBlankLines(0)},
+			"a = 1\nb = 2\n",
+		},
+		"add trailing newline": {
+			"a = 1",
+			[]FormatOption{OptTrailingNewline(TrailingNewlineAlways)},
+			"a = 1\n",
+		},
+		"trim trailing blank lines": {
+			"a = 1\r\n\r\n\r\n",
+			[]FormatOption{OptTrailingNewline(TrailingNewlineAlways)},
+			"a = 1\r\n",
+		},
+		"remove trailing newline": {
+			"a = 1\n\n",
+			[]FormatOption{OptTrailingNewline(TrailingNewlineNever)},
+			"a = 1",
+		},
+		"empty file": {
+			"",
+			[]FormatOption{OptTrailingNewline(TrailingNewlineAlways)},
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(Format([]byte(test.input), test.opts...))
+			if got != test.want {
+				t.Errorf("wrong result\ninput:\n%q\ngot:\n%q\nwant:\n%q", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatSortBodies(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"attributes and blocks": {
+			`# Header comment.
+
+zeta = 1
+# About alpha.
+alpha = 2 # trailing
+
+resource "b" {
+  y = 1
+
+  x = 2
+}
+
+variable "v" {}
+
+# About the second resource.
+resource "a" {
+  nested {
+    b = 1
+    a = 2
+  }
+  c = 3
+}
+mid = 3
+`,
+			`# Header comment.
+
+# About alpha.
+alpha = 2 # trailing
+mid   = 3
+zeta  = 1
+
+resource "b" {
+  x = 2
+  y = 1
+}
+
+# About the second resource.
+resource "a" {
+  c = 3
+
+  nested {
+    a = 2
+    b = 1
+  }
+}
+
+variable "v" {}
+`,
+		},
+		"single-line block": {
+			"b { z = 1 }\na = 1\n",
+			"a = 1\n\nb { z = 1 }\n",
+		},
+		"syntax error": {
+			"b = 1\na = \n",
+			"b = 1\na =\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(Format([]byte(test.input), OptSortBodies()))
+			if got != test.want {
+				t.Errorf("wrong result\ninput:\n%s\ngot:\n%s\nwant:\n%s", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatTrailingCommas(t *testing.T) {
+	input := `a = [1, 2,]
+b = [
+  1,
+  2 # last
+]
+c = [
+  [1, 2],
+  [3, 4],
+]
+d = {
+  x = 1
+  y = 2
+}
+e = {
+  x = 1,
+  y = 2
+}
+f = { x = 1, y = 2, }
+g = [for x in [1, 2, 3]: x]
+`
+	tests := map[TrailingCommas]string{
+		TrailingCommasPreserve: `a = [1, 2, ]
+b = [
+  1,
+  2 # last
+]
+c = [
+  [1, 2],
+  [3, 4],
+]
+d = {
+  x = 1
+  y = 2
+}
+e = {
+  x = 1,
+  y = 2
+}
+f = { x = 1, y = 2, }
+g = [for x in [1, 2, 3] : x]
+`,
+		TrailingCommasAdd: `a = [1, 2, ]
+b = [
+  1,
+  2, # last
+]
+c = [
+  [1, 2],
+  [3, 4],
+]
+d = {
+  x = 1
+  y = 2
+}
+e = {
+  x = 1,
+  y = 2,
+}
+f = { x = 1, y = 2, }
+g = [for x in [1, 2, 3] : x]
+`,
+		TrailingCommasStrip: `a = [1, 2]
+b = [
+  1,
+  2 # last
+]
+c = [
+  [1, 2],
+  [3, 4]
+]
+d = {
+  x = 1
+  y = 2
+}
+e = {
+  x = 1,
+  y = 2
+}
+f = { x = 1, y = 2 }
+g = [for x in [1, 2, 3] : x]
+`,
+	}
+
+	for policy, want := range tests {
+		t.Run(fmt.Sprint(policy), func(t *testing.T) {
+			got := string(Format([]byte(input), OptTrailingCommas(policy)))
+			if got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+
+	t.Run("syntax error", func(t *testing.T) {
+		input := "a = [1, 2,]\nb = \n"
+		want := "a = [1, 2, ]\nb =\n"
+		got := string(Format([]byte(input), OptTrailingCommas(TrailingCommasStrip)))
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
+
+func TestFormatRedact(t *testing.T) {
+	input := `password = "top-level"
+token = "abc" # api token
+
+database "main" {
+  user     = "admin"
+  password = "hunter2"
+}
+
+database "replica" {
+  password = var.replica_password
+  options {
+    password = "nested"
+  }
+}
+`
+	want := `password = "top-level"
+token    = "(sensitive)" # api token
+
+database "main" {
+  user     = "admin"
+  password = "(sensitive)"
+}
+
+database "replica" {
+  password = "(sensitive)"
+  options {
+    password = "nested"
+  }
+}
+`
+	got := string(Format([]byte(input), OptRedact("token", "database.*.password")))
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	// Source code with syntax errors can't be redacted, and so must not be
+	// returned at all.
+	invalid := "token = \"abc\"\nbroken {\n"
+	if got := Format([]byte(invalid), OptRedact("token")); got != nil {
+		t.Errorf("unexpected result for invalid source\n%s", got)
+	}
+}
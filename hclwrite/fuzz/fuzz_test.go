@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcltest"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
@@ -30,3 +31,16 @@ func FuzzParseConfig(f *testing.F) {
 		}
 	})
 }
+
+func FuzzFormatRoundTrip(f *testing.F) {
+	f.Add([]byte("a = 1\nlong_name = \"x ${y}\" # comment\n"))
+	f.Add([]byte("b \"label\" {\n  c = [1, 2, 3]\n  d { e = f.g[0] }\n}\n"))
+	f.Add([]byte("a = <<-EOT\n    hello\n    ${name}\n  EOT\n"))
+	f.Add([]byte("a = {for k, v in m : k => v... if v != null}\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := hcltest.CheckRoundTrip(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -68,28 +68,65 @@ func TokensForIdentifier(name string) Tokens {
 // TokensForTraversal to generate valid leaf expression values, or use
 // TokensForTuple, TokensForObject, and TokensForFunctionCall to
 // generate other nested compound expressions.
+//
+// If any of the elements spans multiple lines, such as an object constructor
+// generated by TokensForObject, then each element is placed on a line of its
+// own, followed by a comma.
 func TokensForTuple(elems []Tokens) Tokens {
-	var toks Tokens
+	toks := appendTokensForTuple(elems, nil)
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks
+}
+
+// appendTokensForTuple appends the tokens of a tuple constructor with the
+// given elements to the given tokens, placing each element on its own line
+// if any of them spans multiple lines.
+func appendTokensForTuple(elems []Tokens, toks Tokens) Tokens {
+	multiLine := false
+	for _, elem := range elems {
+		for _, tok := range elem {
+			if tok.Type == hclsyntax.TokenNewline {
+				multiLine = true
+				break
+			}
+		}
+	}
+
 	toks = append(toks, &Token{
 		Type:  hclsyntax.TokenOBrack,
 		Bytes: []byte{'['},
 	})
 	for index, elem := range elems {
-		if index > 0 {
+		if multiLine {
+			toks = append(toks, &Token{
+				Type:  hclsyntax.TokenNewline,
+				Bytes: []byte{'\n'},
+			})
+		} else if index > 0 {
 			toks = append(toks, &Token{
 				Type:  hclsyntax.TokenComma,
 				Bytes: []byte{','},
 			})
 		}
 		toks = append(toks, elem...)
+		if multiLine {
+			toks = append(toks, &Token{
+				Type:  hclsyntax.TokenComma,
+				Bytes: []byte{','},
+			})
+		}
+	}
+	if multiLine {
+		toks = append(toks, &Token{
+			Type:  hclsyntax.TokenNewline,
+			Bytes: []byte{'\n'},
+		})
 	}
 
 	toks = append(toks, &Token{
 		Type:  hclsyntax.TokenCBrack,
 		Bytes: []byte{']'},
 	})
-
-	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
 	return toks
 }
 
@@ -234,28 +271,12 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 		})
 
 	case val.Type().IsListType() || val.Type().IsSetType() || val.Type().IsTupleType():
-		toks = append(toks, &Token{
-			Type:  hclsyntax.TokenOBrack,
-			Bytes: []byte{'['},
-		})
-
-		i := 0
+		elems := make([]Tokens, 0, val.LengthInt())
 		for it := val.ElementIterator(); it.Next(); {
-			if i > 0 {
-				toks = append(toks, &Token{
-					Type:  hclsyntax.TokenComma,
-					Bytes: []byte{','},
-				})
-			}
 			_, eVal := it.Element()
-			toks = appendTokensForValue(eVal, toks)
-			i++
+			elems = append(elems, appendTokensForValue(eVal, nil))
 		}
-
-		toks = append(toks, &Token{
-			Type:  hclsyntax.TokenCBrack,
-			Bytes: []byte{']'},
-		})
+		toks = appendTokensForTuple(elems, toks)
 
 	case val.Type().IsMapType() || val.Type().IsObjectType():
 		toks = append(toks, &Token{
@@ -792,6 +792,12 @@ func TestTokenGenerateConsistency(t *testing.T) {
 					cty.StringVal("world"),
 				},
 			},
+			"object elements": {
+				[]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(80)}),
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(443)}),
+				},
+			},
 		}
 
 		for name, test := range tests {
@@ -883,3 +889,34 @@ func TestTokenGenerateConsistency(t *testing.T) {
 		}
 	})
 }
+
+func TestTokensForValueListOfObjects(t *testing.T) {
+	val := cty.TupleVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{
+			"port": cty.NumberIntVal(80),
+		}),
+		cty.ObjectVal(map[string]cty.Value{
+			"port":     cty.NumberIntVal(443),
+			"protocol": cty.StringVal("https"),
+		}),
+		cty.EmptyObjectVal,
+	})
+
+	f := NewEmptyFile()
+	f.Body().SetAttributeValue("rule", val)
+	got := string(f.Bytes())
+	want := `rule = [
+  {
+    port = 80
+  },
+  {
+    port     = 443
+    protocol = "https"
+  },
+  {},
+]
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Layout describes layout decisions made by the author of a body item, such
+// as an attribute or a block.
+//
+// When a file is parsed, the layout of each of its items is recorded so that
+// the edit API and the formatter can reproduce the author's choices rather
+// than imposing a fixed layout. Callers constructing new items can also set
+// a layout explicitly before adding the item to a body.
+type Layout struct {
+	// SingleLine is true if the item was written entirely on one line. For
+	// a block, this means that the opening and closing braces are on the
+	// same line, as in "limits { cpu = 1 }".
+	SingleLine bool
+
+	// BlankLineBefore is true if the item is separated from whatever
+	// precedes it in its body by at least one blank line.
+	BlankLineBefore bool
+
+	// BlankLineAfter is true if the item is separated from whatever
+	// follows it in its body by at least one blank line.
+	BlankLineAfter bool
+}
+
+// Layout returns the layout hints recorded for the receiving attribute.
+func (a *Attribute) Layout() Layout {
+	return a.layout
+}
+
+// SetLayout replaces the layout hints recorded for the receiving attribute.
+//
+// This does not immediately change the tokens of the attribute or its
+// surroundings. Instead, the hints are taken into account by subsequent
+// operations that must make layout decisions.
+func (a *Attribute) SetLayout(layout Layout) {
+	a.layout = layout
+}
+
+// Layout returns the layout hints recorded for the receiving block.
+func (b *Block) Layout() Layout {
+	return b.layout
+}
+
+// SetLayout replaces the layout hints recorded for the receiving block.
+//
+// This does not immediately change the tokens of the block or its
+// surroundings. Instead, the hints are taken into account by subsequent
+// operations that must make layout decisions, such as Body.AppendBlock.
+func (b *Block) SetLayout(layout Layout) {
+	b.layout = layout
+}
+
+// isBlankLines returns true if the given node is an unstructured sequence of
+// newline tokens, which is how blank lines between body items are
+// represented.
+func isBlankLines(n *node) bool {
+	if n == nil {
+		return false
+	}
+	toks, ok := n.content.(Tokens)
+	if !ok || len(toks) == 0 {
+		return false
+	}
+	for _, tok := range toks {
+		if tok.Type != hclsyntax.TokenNewline {
+			return false
+		}
+	}
+	return true
+}
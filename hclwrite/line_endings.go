@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var (
+	lf   = []byte("\n")
+	crlf = []byte("\r\n")
+)
+
+// normalizeLineEndings rewrites the line endings in the given tokens to use
+// the given style. The style must not be LineEndingsPreserve; use
+// dominantLineEndings to find the style of the original source code first.
+//
+// Only the line endings that separate items are rewritten: those of newline
+// tokens and the ones that end single-line comments, which the scanner
+// includes in the comment token. Line endings within heredocs, quoted
+// strings and multi-line comments are left untouched, because rewriting
+// them would change the values of templates. As with format, the tokens are
+// modified in place.
+func normalizeLineEndings(tokens Tokens, style LineEndings) {
+	want := lf
+	if style == LineEndingsCRLF {
+		want = crlf
+	}
+	for _, tok := range tokens {
+		switch {
+		case tok.Type == hclsyntax.TokenNewline:
+			tok.Bytes = want
+		case isLineComment(tok):
+			text := bytes.TrimSuffix(bytes.TrimSuffix(tok.Bytes, lf), []byte("\r"))
+			tok.Bytes = append(text[:len(text):len(text)], want...)
+		}
+	}
+}
+
+// isLineComment returns true if the given token is a single-line comment,
+// whose bytes end with the line ending that terminates it.
+func isLineComment(tok *Token) bool {
+	return tok.Type == hclsyntax.TokenComment && !bytes.HasPrefix(tok.Bytes, []byte("/*")) && bytes.HasSuffix(tok.Bytes, lf)
+}
+
+// dominantLineEndings returns LineEndingsCRLF if most of the line endings in
+// the given source code are CRLF sequences, or LineEndingsLF otherwise.
+func dominantLineEndings(src []byte) LineEndings {
+	crlfs := bytes.Count(src, crlf)
+	lfs := bytes.Count(src, lf) - crlfs
+	if crlfs > lfs {
+		return LineEndingsCRLF
+	}
+	return LineEndingsLF
+}
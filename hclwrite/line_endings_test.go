@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hcltest"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestLineEndingsRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"crlf with lf heredoc":  "a = 1\r\nb = 2\r\nc = <<EOT\nx\nEOT\r\n",
+		"lf with crlf heredoc":  "a = 1\nc = <<EOT\r\nx\r\nEOT\n",
+		"mixed template":        "a = \"${b}\"\r\nc = <<-EOT\n  %{ if d }x%{ endif }\r\n  y\n  EOT\n",
+		"crlf block comment":    "/* x\r\ny */\na = 1\r\n",
+		"mixed nested heredocs": "blk {\r\n  a = <<EOT\r\n${<<INNER\nx\nINNER\n}\r\nEOT\n}\n",
+	}
+	styles := map[string][]hclwrite.FormatOption{
+		"preserve": nil,
+		"lf":       {hclwrite.OptLineEndings(hclwrite.LineEndingsLF)},
+		"crlf":     {hclwrite.OptLineEndings(hclwrite.LineEndingsCRLF)},
+	}
+
+	for name, src := range tests {
+		for style, opts := range styles {
+			t.Run(name+"/"+style, func(t *testing.T) {
+				if err := hcltest.CheckRoundTrip([]byte(src), opts...); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Merge merges the body of the given override file into the body of the
+// receiving file. See Body.Merge for details.
+func (f *File) Merge(override *File) {
+	f.Body().Merge(override.Body())
+}
+
+// Merge merges the attributes and blocks of the given override body into
+// the receiving body, preserving the comments and formatting of the
+// receiving body wherever possible. The override body is not modified.
+//
+// An attribute in the override body replaces the expression of an attribute
+// of the same name in the receiving body, leaving that attribute's comments
+// in place. Attributes that don't already exist are inserted after the last
+// existing attribute, along with their comments from the override body.
+//
+// A block in the override body is merged recursively into the block of the
+// same type and labels in the receiving body. Where a body has more than one
+// block with the same type and labels, they are matched in the order they
+// appear. Blocks that have no counterpart are appended to the end of the
+// receiving body.
+//
+// This is similar to the way that some applications apply "override" files
+// to a base configuration, and so is useful for maintaining layered
+// configuration files while keeping the base file readable.
+func (b *Body) Merge(override *Body) {
+	used := map[*Block]bool{}
+	for n := override.children.first; n != nil; n = n.after {
+		switch item := n.content.(type) {
+		case *Attribute:
+			name := string(item.name.content.(*identifier).token.Bytes)
+			if attr := b.GetAttribute(name); attr != nil {
+				b.SetAttributeRaw(name, copyTokens(item.Expr().BuildTokens(nil)))
+				continue
+			}
+			clone := cloneBody(item.BuildTokens(nil))
+			if clone == nil {
+				continue
+			}
+			for cn := clone.children.first; cn != nil; cn = cn.after {
+				if _, ok := cn.content.(*Attribute); ok {
+					clone.removeItemNode(cn)
+					b.insertAttributeNode(cn)
+					break
+				}
+			}
+
+		case *Block:
+			if target := b.matchingBlock(item, used); target != nil {
+				used[target] = true
+				target.Body().Merge(item.Body())
+				continue
+			}
+			clone := cloneBody(item.BuildTokens(nil))
+			if clone == nil {
+				continue
+			}
+			for _, block := range clone.Blocks() {
+				clone.RemoveBlock(block)
+				used[block] = true
+				b.AppendBlock(block)
+			}
+		}
+	}
+}
+
+// matchingBlock returns the first block in the body with the same type and
+// labels as the given block that isn't already in the given set, or nil if
+// there is no such block.
+func (b *Body) matchingBlock(block *Block, used map[*Block]bool) *Block {
+	typeName, labels := block.Type(), block.Labels()
+	for _, candidate := range b.Blocks() {
+		if used[candidate] || candidate.Type() != typeName {
+			continue
+		}
+		candidateLabels := candidate.Labels()
+		if len(candidateLabels) != len(labels) {
+			continue
+		}
+		match := true
+		for i := range labels {
+			if labels[i] != candidateLabels[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// insertAttributeNode inserts the given detached attribute node after the
+// last attribute in the body, or before all of the other items in the body
+// if it has no attributes.
+func (b *Body) insertAttributeNode(nn *node) {
+	var last *node
+	for n := b.children.first; n != nil; n = n.after {
+		if _, ok := n.content.(*Attribute); ok {
+			last = n
+		}
+	}
+
+	var pos *node
+	if last != nil {
+		pos = last.after
+	} else {
+		for n := b.children.first; n != nil; n = n.after {
+			if b.items.Has(n) {
+				pos = n
+				break
+			}
+		}
+	}
+
+	if pos == nil {
+		b.children.AppendNode(nn)
+	} else {
+		b.children.InsertNode(pos, nn)
+	}
+	b.items.Add(nn)
+}
+
+// cloneBody parses the given tokens, which must have been built from one or
+// more complete body items, to produce a new body containing copies of those
+// items that are not attached to any other tree.
+func cloneBody(tokens Tokens) *Body {
+	f, diags := ParseConfig(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		// Tokens built from a valid tree should always parse, so this
+		// can only happen if the tree was built from invalid raw tokens.
+		return nil
+	}
+	return f.Body()
+}
+
+func copyTokens(tokens Tokens) Tokens {
+	ret := make(Tokens, len(tokens))
+	for i, tok := range tokens {
+		copied := *tok
+		ret[i] = &copied
+	}
+	return ret
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestFileMerge(t *testing.T) {
+	tests := map[string]struct {
+		base, override string
+		want           string
+	}{
+		"replace attribute": {
+			`# The region to deploy into.
+region = "us-east-1" # default
+
+size = 1
+`,
+			`region = "eu-west-1"
+`,
+			`# The region to deploy into.
+region = "eu-west-1" # default
+
+size = 1
+`,
+		},
+		"new attribute": {
+			`a = 1
+b = 2
+
+block {
+}
+`,
+			`# Added by override
+c = 3
+`,
+			`a = 1
+b = 2
+# Added by override
+c = 3
+
+block {
+}
+`,
+		},
+		"new attribute before blocks": {
+			`# Header
+
+block {
+}
+`,
+			`a = 1
+`,
+			`# Header
+
+a = 1
+block {
+}
+`,
+		},
+		"nested blocks": {
+			`service "web" {
+  # Listening port
+  port = 80
+
+  health {
+    path = "/"
+  }
+}
+`,
+			`service "web" {
+  port = 8080
+  health {
+    interval = 10
+  }
+}
+`,
+			`service "web" {
+  # Listening port
+  port = 8080
+
+  health {
+    path     = "/"
+    interval = 10
+  }
+}
+`,
+		},
+		"new block": {
+			`service "web" {
+  port = 80
+}
+`,
+			`# The database
+service "db" {
+  port = 5432
+}
+`,
+			`service "web" {
+  port = 80
+}
+# The database
+service "db" {
+  port = 5432
+}
+`,
+		},
+		"repeated blocks": {
+			`rule {
+  n = 1
+}
+rule {
+  n = 2
+}
+`,
+			`rule {
+  n = 10
+}
+rule {
+  n = 20
+}
+rule {
+  n = 30
+}
+`,
+			`rule {
+  n = 10
+}
+rule {
+  n = 20
+}
+rule {
+  n = 30
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			base, diags := ParseConfig([]byte(test.base), "base.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			override, diags := ParseConfig([]byte(test.override), "override.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			base.Merge(override)
+			if diff := cmp.Diff(test.want, string(base.Bytes())); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+			if got := string(override.Bytes()); got != test.override {
+				t.Errorf("override was modified\ngot:\n%s\nwant:\n%s", got, test.override)
+			}
+		})
+	}
+}
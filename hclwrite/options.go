@@ -0,0 +1,318 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclwrite
+
+import (
+	"github.com/hashicorp/hcl/v2/ext/redact"
+)
+
+// FormatOption is implemented by values that can customize the behavior of
+// Format. Use the functions in this package whose names begin with "Opt" to
+// produce format options.
+type FormatOption interface {
+	applyFormatOption(*formatOpts)
+}
+
+type formatOpts struct {
+	blockLayout     blockLayoutMode
+	maxWidth        int
+	multiArgBlocks  bool
+	lineEndings     LineEndings
+	indent          string
+	noAlignEquals   bool
+	limitBlankLines bool
+	maxBlankLines   int
+	trailingNewline TrailingNewline
+	sortBodies      bool
+	trailingCommas  TrailingCommas
+	redact          redact.Paths
+}
+
+func newFormatOpts(opts []FormatOption) *formatOpts {
+	ret := &formatOpts{}
+	for _, opt := range opts {
+		opt.applyFormatOption(ret)
+	}
+	return ret
+}
+
+type blockLayoutMode int
+
+const (
+	blockLayoutPreserve blockLayoutMode = iota
+	blockLayoutCollapse
+	blockLayoutExpand
+)
+
+type optCollapseBlocks struct {
+	maxWidth int
+}
+
+// OptCollapseBlocks causes Format to rewrite blocks containing only a
+// single-line argument into the compact single-line block syntax, like
+// "limits { cpu = 1 }", as long as the resulting line, including its
+// indentation, is no wider than the given number of columns. Blocks with
+// more than one argument are collapsed only if OptMultiArgumentBlocks is
+// also given.
+//
+// Blocks containing nested blocks, comments, or multi-line expressions are
+// never collapsed.
+func OptCollapseBlocks(maxWidth int) FormatOption {
+	return optCollapseBlocks{maxWidth}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optCollapseBlocks) applyFormatOption(opts *formatOpts) {
+	opts.blockLayout = blockLayoutCollapse
+	opts.maxWidth = o.maxWidth
+}
+
+type optExpandBlocks struct{}
+
+// OptExpandBlocks causes Format to rewrite any single-line blocks into the
+// multi-line block syntax, with each argument on a line of its own.
+func OptExpandBlocks() FormatOption {
+	return optExpandBlocks{}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optExpandBlocks) applyFormatOption(opts *formatOpts) {
+	opts.blockLayout = blockLayoutExpand
+}
+
+type optMultiArgumentBlocks struct{}
+
+// OptMultiArgumentBlocks causes Format to accept single-line blocks with
+// more than one argument, like "limits { cpu = 1, mem = 2 }", as
+// hclsyntax.OptMultiArgumentBlocks does for the parser, and allows
+// OptCollapseBlocks to produce them.
+//
+// Without this option such blocks are a syntax error, so OptExpandBlocks
+// cannot rewrite them.
+func OptMultiArgumentBlocks() FormatOption {
+	return optMultiArgumentBlocks{}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optMultiArgumentBlocks) applyFormatOption(opts *formatOpts) {
+	opts.multiArgBlocks = true
+}
+
+// LineEndings is a style of line ending, for use with OptLineEndings.
+type LineEndings int
+
+const (
+	// LineEndingsPreserve uses whichever line ending is most common in the
+	// source code, or a bare newline if the source has no line endings.
+	LineEndingsPreserve LineEndings = iota
+
+	// LineEndingsLF uses a bare newline character, as is conventional on
+	// Unix-like systems.
+	LineEndingsLF
+
+	// LineEndingsCRLF uses a carriage return followed by a newline, as is
+	// conventional on Windows.
+	LineEndingsCRLF
+)
+
+type optLineEndings struct {
+	style LineEndings
+}
+
+// OptLineEndings causes Format to write the line endings that separate items,
+// including those that end single-line comments, in the given style. Line
+// endings within heredocs, quoted strings and multi-line comments are part of
+// their content and are left as they are.
+//
+// Without this option Format behaves as if given LineEndingsPreserve, so a
+// file that consistently uses one style keeps that style even where
+// formatting introduces new lines.
+func OptLineEndings(style LineEndings) FormatOption {
+	return optLineEndings{style}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optLineEndings) applyFormatOption(opts *formatOpts) {
+	opts.lineEndings = o.style
+}
+
+type optIndent struct {
+	indent string
+}
+
+// OptIndent causes Format to indent each level of nesting with the given
+// string, such as a tab character or four spaces, rather than the default
+// of two spaces. An empty string selects the default.
+//
+// Lines whose equals signs or comments are aligned with one another are
+// normally at the same level of nesting, and so remain aligned.
+func OptIndent(indent string) FormatOption {
+	return optIndent{indent}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optIndent) applyFormatOption(opts *formatOpts) {
+	opts.indent = o.indent
+}
+
+type optAlignEquals struct {
+	align bool
+}
+
+// OptAlignEquals selects whether Format vertically aligns the equals signs
+// of consecutive argument definitions, as it does by default. When
+// alignment is disabled each equals sign is separated from the argument
+// name by a single space. Trailing comments on consecutive lines are still
+// aligned with one another either way.
+func OptAlignEquals(align bool) FormatOption {
+	return optAlignEquals{align}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optAlignEquals) applyFormatOption(opts *formatOpts) {
+	opts.noAlignEquals = !o.align
+}
+
+type optMaxBlankLines struct {
+	max int
+}
+
+// OptMaxBlankLines causes Format to remove blank lines from any run of
+// consecutive blank lines that is longer than the given maximum, so that
+// for example a maximum of one leaves at most one blank line between
+// blocks. A maximum of zero removes all blank lines. Blank lines within
+// heredoc templates are part of the template and are never removed.
+//
+// Without this option Format preserves all blank lines.
+func OptMaxBlankLines(max int) FormatOption {
+	return optMaxBlankLines{max}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optMaxBlankLines) applyFormatOption(opts *formatOpts) {
+	if o.max < 0 {
+		o.max = 0
+	}
+	opts.limitBlankLines = true
+	opts.maxBlankLines = o.max
+}
+
+// TrailingNewline is a policy for the line endings at the end of a file, for
+// use with OptTrailingNewline.
+type TrailingNewline int
+
+const (
+	// TrailingNewlinePreserve leaves the end of the file as it is.
+	TrailingNewlinePreserve TrailingNewline = iota
+
+	// TrailingNewlineAlways ensures that a non-empty file ends with exactly
+	// one line ending, removing any blank lines at the end of the file.
+	TrailingNewlineAlways
+
+	// TrailingNewlineNever removes all line endings from the end of the
+	// file.
+	TrailingNewlineNever
+)
+
+type optTrailingNewline struct {
+	policy TrailingNewline
+}
+
+// OptTrailingNewline causes Format to adjust the line endings at the end of
+// the file according to the given policy. Without this option Format
+// behaves as if given TrailingNewlinePreserve.
+func OptTrailingNewline(policy TrailingNewline) FormatOption {
+	return optTrailingNewline{policy}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optTrailingNewline) applyFormatOption(opts *formatOpts) {
+	opts.trailingNewline = o.policy
+}
+
+type optSortBodies struct{}
+
+// OptSortBodies causes Format to rearrange the items in each body into a
+// canonical order, for teams that want configuration files to be fully
+// deterministic. The arguments come first, sorted by name, and then the
+// nested blocks, grouped by type with the types in lexical order. Blocks of
+// the same type keep their original order relative to one another, since
+// the order of blocks is often significant.
+//
+// Comments attached to an item, and any other comments that appear between
+// it and the previous item, move with it. Blank lines are then regenerated
+// so that the arguments form a single group and each block is preceded by
+// one blank line. The items of single-line blocks are never rearranged.
+//
+// As with the options that change the layout of blocks, this option
+// applies only when the given source code is free of syntax errors.
+func OptSortBodies() FormatOption {
+	return optSortBodies{}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optSortBodies) applyFormatOption(opts *formatOpts) {
+	opts.sortBodies = true
+}
+
+// TrailingCommas is a policy for the commas that may follow the last element
+// of a tuple or object constructor, for use with OptTrailingCommas.
+type TrailingCommas int
+
+const (
+	// TrailingCommasPreserve leaves trailing commas as they are.
+	TrailingCommasPreserve TrailingCommas = iota
+
+	// TrailingCommasAdd adds a trailing comma to each tuple constructor
+	// whose closing bracket is on a line of its own. Object constructors
+	// get a trailing comma in the same situation only if their items are
+	// already separated by commas, since items on separate lines usually
+	// have none.
+	TrailingCommasAdd
+
+	// TrailingCommasStrip removes all trailing commas.
+	TrailingCommasStrip
+)
+
+type optTrailingCommas struct {
+	policy TrailingCommas
+}
+
+// OptTrailingCommas causes Format to add or remove the commas after the last
+// elements of tuple and object constructors according to the given policy.
+// Without this option Format behaves as if given TrailingCommasPreserve.
+//
+// As with the options that change the layout of blocks, this option
+// applies only when the given source code is free of syntax errors.
+func OptTrailingCommas(policy TrailingCommas) FormatOption {
+	return optTrailingCommas{policy}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optTrailingCommas) applyFormatOption(opts *formatOpts) {
+	opts.trailingCommas = o.policy
+}
+
+type optRedact struct {
+	paths redact.Paths
+}
+
+// OptRedact causes Format to replace the value of each attribute whose path
+// matches one of the given patterns with a string containing
+// redact.Placeholder, so that the result can be logged or displayed without
+// revealing secrets. The patterns are as described for redact.Paths, such
+// as "database.*.password" or "**.token". The result is still valid HCL,
+// but it no longer has the same meaning as the original.
+//
+// Redaction requires the given source code to be free of syntax errors.
+// Rather than return source code that might reveal secrets, Format returns
+// nil for source code with syntax errors when this option is given.
+func OptRedact(patterns ...string) FormatOption {
+	return optRedact{redact.Paths(patterns)}
+}
+
+// applyFormatOption implements FormatOption.
+func (o optRedact) applyFormatOption(opts *formatOpts) {
+	opts.redact = append(opts.redact, o.paths...)
+}
@@ -29,8 +29,8 @@ import (
 // If the parsing step produces any errors, the returned File is nil because
 // we can't reliably extract tokens from the partial AST produced by an
 // erroneous parse.
-func parse(src []byte, filename string, start hcl.Pos) (*File, hcl.Diagnostics) {
-	file, diags := hclsyntax.ParseConfig(src, filename, start)
+func parse(src []byte, filename string, start hcl.Pos, opts ...hclsyntax.ParseOption) (*File, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, start, opts...)
 	if diags.HasErrors() {
 		return nil, diags
 	}
@@ -201,17 +201,35 @@ func parseBody(nativeBody *hclsyntax.Body, from inputTokens) (inputTokens, *node
 		items:  newNodeSet(),
 	}
 
+	// We also record the layout of each item as we go, for which we need
+	// to track the extent of the previous item in order to notice blank
+	// lines between them.
+	var prevLayout *Layout
+	prevEndLine := nativeBody.SrcRange.Start.Line
+
 	remain := within
 	for _, nativeItem := range nativeItems {
-		beforeItem, item, afterItem := parseBodyItem(nativeItem, remain)
+		beforeItem, item, startLine, afterItem := parseBodyItem(nativeItem, remain)
 
 		if beforeItem.Len() > 0 {
 			body.AppendUnstructuredTokens(beforeItem.Tokens())
 		}
 		body.appendItemNode(item)
 
+		layout := itemLayout(item)
+		layout.SingleLine = nativeItem.Range().Start.Line == nativeItem.Range().End.Line
+		layout.BlankLineBefore = startLine-prevEndLine > 1
+		if prevLayout != nil {
+			prevLayout.BlankLineAfter = layout.BlankLineBefore
+		}
+		prevLayout = layout
+		prevEndLine = nativeItem.Range().End.Line
+
 		remain = afterItem
 	}
+	if prevLayout != nil {
+		prevLayout.BlankLineAfter = nativeBody.SrcRange.End.Line-prevEndLine > 1
+	}
 
 	if remain.Len() > 0 {
 		body.AppendUnstructuredTokens(remain.Tokens())
@@ -220,9 +238,17 @@ func parseBody(nativeBody *hclsyntax.Body, from inputTokens) (inputTokens, *node
 	return before, newNode(body), after
 }
 
-func parseBodyItem(nativeItem hclsyntax.Node, from inputTokens) (inputTokens, *node, inputTokens) {
+// parseBodyItem returns the node for the given item along with the line
+// where that item begins, including any lead comments that are attached to
+// it.
+func parseBodyItem(nativeItem hclsyntax.Node, from inputTokens) (inputTokens, *node, int, inputTokens) {
 	before, leadComments, within, lineComments, newline, after := from.PartitionBlockItem(nativeItem.Range())
 
+	startLine := nativeItem.Range().Start.Line
+	if leadComments.Len() > 0 {
+		startLine = leadComments.nativeTokens[0].Range.Start.Line
+	}
+
 	var item *node
 
 	switch tItem := nativeItem.(type) {
@@ -235,7 +261,21 @@ func parseBodyItem(nativeItem hclsyntax.Node, from inputTokens) (inputTokens, *n
 		panic("unsupported native item type")
 	}
 
-	return before, item, after
+	return before, item, startLine, after
+}
+
+// itemLayout returns a pointer to the layout hints of the given body item
+// node, which must contain either an attribute or a block.
+func itemLayout(item *node) *Layout {
+	switch tItem := item.content.(type) {
+	case *Attribute:
+		return &tItem.layout
+	case *Block:
+		return &tItem.layout
+	default:
+		// should never happen if caller is behaving
+		panic("unsupported item type")
+	}
 }
 
 func parseAttribute(nativeAttr *hclsyntax.Attribute, from, leadComments, lineComments, newline inputTokens) *node {
@@ -612,6 +652,11 @@ func partitionLineEndTokens(toks hclsyntax.Tokens) (afterComment, afterNewline i
 				// itself as our "newline" or else strange things will
 				// happen when we try to append new items.
 				return i, i
+			case hclsyntax.TokenComma:
+				// A comma separates the arguments of a single-line block,
+				// so it belongs to neither the preceding item nor its line
+				// trailers.
+				return i, i
 			default:
 				// If we have well-formed input here then nothing else should be
 				// possible. This path should never happen, because we only try
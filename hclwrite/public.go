@@ -7,6 +7,7 @@ import (
 	"bytes"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 // NewFile creates a new file object that is empty and ready to have constructs
@@ -38,10 +39,56 @@ func ParseConfig(src []byte, filename string, start hcl.Pos) (*File, hcl.Diagnos
 // changes will be made. It also ignores syntax errors and can thus be applied
 // to partial source code, although the result in that case may not be
 // desirable.
-func Format(src []byte) []byte {
+//
+// Options that change the layout of blocks, such as OptCollapseBlocks and
+// OptExpandBlocks, along with OptSortBodies and OptTrailingCommas, require
+// Format to construct an AST and so apply only when the given source code is
+// free of syntax errors. Otherwise, Format falls back to only adjusting
+// whitespace. OptRedact also requires an AST, but since falling back would
+// reveal the values that were to be redacted, Format instead returns nil
+// for source code with syntax errors when it is given.
+func Format(src []byte, opts ...FormatOption) []byte {
+	o := newFormatOpts(opts)
+
+	if o.trailingCommas != TrailingCommasPreserve {
+		src = applyTrailingCommas(src, o.trailingCommas)
+	}
 	tokens := lexConfig(src)
+	if o.blockLayout != blockLayoutPreserve || o.sortBodies || len(o.redact) > 0 {
+		var parseOpts []hclsyntax.ParseOption
+		if o.multiArgBlocks {
+			parseOpts = append(parseOpts, hclsyntax.OptMultiArgumentBlocks())
+		}
+		f, diags := parse(src, "", hcl.InitialPos, parseOpts...)
+		if diags.HasErrors() && len(o.redact) > 0 {
+			return nil
+		}
+		if !diags.HasErrors() {
+			if len(o.redact) > 0 {
+				redactBody(f.Body(), nil, o.redact)
+			}
+			if o.sortBodies {
+				sortBody(f.Body())
+			}
+			tokens = formatBlockLayout(f, o)
+		}
+	}
+	if o.limitBlankLines {
+		tokens = limitBlankLines(tokens, o.maxBlankLines)
+	}
 	format(tokens)
+	if o.noAlignEquals {
+		unalignEquals(tokens)
+	}
+	if o.indent != "" && o.indent != "  " {
+		reindent(tokens, o.indent)
+	}
+	lineEndings := o.lineEndings
+	if lineEndings == LineEndingsPreserve {
+		lineEndings = dominantLineEndings(src)
+	}
+	normalizeLineEndings(tokens, lineEndings)
 	buf := &bytes.Buffer{}
 	tokens.WriteTo(buf)
-	return buf.Bytes()
+	return applyTrailingNewline(buf.Bytes(), o.trailingNewline, lineEndings)
 }
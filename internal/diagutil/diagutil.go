@@ -0,0 +1,20 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package diagutil contains helpers for presenting diagnostics that are
+// shared by the packages in this module that write diagnostics in formats
+// for other tools, but are not part of the public API of package hcl.
+package diagutil
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Message returns a single message describing the given diagnostic, made
+// of its summary followed by its detail, if any.
+func Message(diag *hcl.Diagnostic) string {
+	if diag.Detail == "" {
+		return diag.Summary
+	}
+	return diag.Summary + ": " + diag.Detail
+}
@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntaxutil
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SortedAttributes returns the attributes of the given body in the order
+// they appear in the source.
+func SortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	ret := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		ret = append(ret, attr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].SrcRange.Start.Byte < ret[j].SrcRange.Start.Byte
+	})
+	return ret
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntaxutil
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// TrailingComma describes the end of a tuple or object constructor
+// expression, for deciding whether it ought to have a trailing comma.
+type TrailingComma struct {
+	// Last is the range of the last element.
+	Last hcl.Range
+
+	// Comma is the comma token after the last element, or nil if there is
+	// none.
+	Comma *hclsyntax.Token
+
+	// Separated is true if the elements are separated by commas. This is
+	// always true for a tuple, but the items of an object can instead be
+	// separated only by newlines.
+	Separated bool
+
+	// OwnLine is true if the closing bracket is not on the same line as the
+	// end of the last element.
+	OwnLine bool
+}
+
+// FindTrailingComma returns a description of the end of the given tuple or
+// object constructor expression, using the given tokens, which must have
+// been lexed from the same source code with the same start position as the
+// expression was parsed. It returns false if the expression is not a
+// constructor or has no elements.
+func FindTrailingComma(tokens hclsyntax.Tokens, expr hclsyntax.Expression) (TrailingComma, bool) {
+	var ret TrailingComma
+	switch expr := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		if len(expr.Exprs) == 0 {
+			return ret, false
+		}
+		ret.Last = expr.Exprs[len(expr.Exprs)-1].Range()
+		ret.Separated = true
+	case *hclsyntax.ObjectConsExpr:
+		if len(expr.Items) == 0 {
+			return ret, false
+		}
+		for i := 1; i < len(expr.Items) && !ret.Separated; i++ {
+			prev := expr.Items[i-1].ValueExpr.Range().End.Byte
+			next := expr.Items[i].KeyExpr.Range().Start.Byte
+			comma, _ := scanBetween(tokens, prev, next)
+			ret.Separated = comma != nil
+		}
+		ret.Last = expr.Items[len(expr.Items)-1].ValueExpr.Range()
+	default:
+		return ret, false
+	}
+
+	ret.Comma, ret.OwnLine = scanBetween(tokens, ret.Last.End.Byte, expr.Range().End.Byte-1)
+	return ret, true
+}
+
+// scanBetween returns the first comma token that begins between the given
+// byte offsets, if any, and whether there is also a line break between them.
+func scanBetween(tokens hclsyntax.Tokens, start, end int) (comma *hclsyntax.Token, newline bool) {
+	i := sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].Range.Start.Byte >= start
+	})
+	for ; i < len(tokens) && tokens[i].Range.Start.Byte < end; i++ {
+		tok := &tokens[i]
+		switch tok.Type {
+		case hclsyntax.TokenComma:
+			if comma == nil {
+				comma = tok
+			}
+		case hclsyntax.TokenNewline:
+			newline = true
+		case hclsyntax.TokenComment:
+			// A line comment includes its newline.
+			if b := tok.Bytes; len(b) > 0 && b[len(b)-1] == '\n' {
+				newline = true
+			}
+		}
+	}
+	return comma, newline
+}
@@ -0,0 +1,7 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hclsyntaxutil contains helpers for working with native syntax
+// tokens and syntax trees that are shared by several of the packages in this
+// module, but are not part of the public API of package hclsyntax.
+package hclsyntaxutil
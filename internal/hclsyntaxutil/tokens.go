@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hclsyntaxutil
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SignificantTokens returns the tokens of the given expression source code,
+// excluding comments, newlines and trailing commas, for comparing
+// expressions while ignoring differences that do not affect their meaning.
+func SignificantTokens(src []byte) hclsyntax.Tokens {
+	tokens, _ := hclsyntax.LexExpression(src, "", hcl.InitialPos)
+	ret := tokens[:0]
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenComment, hclsyntax.TokenNewline, hclsyntax.TokenEOF:
+			continue
+		}
+		ret = append(ret, tok)
+	}
+
+	trimmed := ret[:0]
+	for i, tok := range ret {
+		if tok.Type == hclsyntax.TokenComma && i+1 < len(ret) {
+			switch ret[i+1].Type {
+			case hclsyntax.TokenCBrack, hclsyntax.TokenCBrace, hclsyntax.TokenCParen:
+				continue
+			}
+		}
+		trimmed = append(trimmed, tok)
+	}
+	return trimmed
+}
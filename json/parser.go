@@ -4,6 +4,7 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -11,7 +12,16 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
 func parseFileContent(buf []byte, filename string, start hcl.Pos) (node, hcl.Diagnostics) {
+	// RFC 8259 permits parsers to ignore a leading byte order mark, which
+	// some editors on Windows add to UTF-8 files. The BOM is not counted as
+	// a column, but positions still give its bytes their true offsets.
+	if bytes.HasPrefix(buf, utf8BOM) {
+		buf = buf[len(utf8BOM):]
+		start.Byte += len(utf8BOM)
+	}
 	tokens := scan(buf, pos{Filename: filename, Pos: start})
 	p := newPeeker(tokens)
 	node, diags := parseValue(p)
@@ -23,7 +33,7 @@ func parseFileContent(buf []byte, filename string, start hcl.Pos) (node, hcl.Dia
 			Subject:  p.Peek().Range.Ptr(),
 		})
 	}
-	return node, diags
+	return node, hcl.MarkSyntaxErrors(diags)
 }
 
 func parseExpression(buf []byte, filename string, start hcl.Pos) (node, hcl.Diagnostics) {
@@ -38,12 +48,32 @@ func parseExpression(buf []byte, filename string, start hcl.Pos) (node, hcl.Diag
 			Subject:  p.Peek().Range.Ptr(),
 		})
 	}
-	return node, diags
+	return node, hcl.MarkSyntaxErrors(diags)
 }
 
+// maxNestingDepth is the deepest the parser will descend into nested arrays
+// and objects before giving up, so that adversarial input cannot exhaust
+// the stack.
+const maxNestingDepth = 1000
+
 func parseValue(p *peeker) (node, hcl.Diagnostics) {
 	tok := p.Peek()
 
+	if (tok.Type == tokenBraceO || tok.Type == tokenBrackO) && p.depth >= maxNestingDepth {
+		// Skip the rest of the input so that the enclosing arrays and
+		// objects don't each report that they are unclosed.
+		p.pos = len(p.tokens) - 1
+		p.tooDeep = true
+		return invalidVal{tok.Range}, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Nesting too deep",
+				Detail:   fmt.Sprintf("This value is nested more than %d levels deep, which exceeds the maximum nesting depth supported by the parser.", maxNestingDepth),
+				Subject:  &tok.Range,
+			},
+		}
+	}
+
 	wrapInvalid := func(n node, diags hcl.Diagnostics) (node, hcl.Diagnostics) {
 		if n != nil {
 			return n, diags
@@ -113,6 +143,9 @@ func tokenCanStartValue(tok token) bool {
 func parseObject(p *peeker) (node, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
+	p.depth++
+	defer func() { p.depth-- }()
+
 	open := p.Read()
 	attrs := []*objectAttr{}
 
@@ -198,7 +231,7 @@ Token:
 
 		valNode, valDiags := parseValue(p)
 		diags = diags.Extend(valDiags)
-		if valNode == nil {
+		if valNode == nil || p.tooDeep {
 			return nil, diags
 		}
 
@@ -264,6 +297,9 @@ Token:
 func parseArray(p *peeker) (node, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
+	p.depth++
+	defer func() { p.depth-- }()
+
 	open := p.Read()
 	vals := []node{}
 
@@ -298,7 +334,7 @@ Token:
 
 		valNode, valDiags := parseValue(p)
 		diags = diags.Extend(valDiags)
-		if valNode == nil {
+		if valNode == nil || p.tooDeep {
 			return nil, diags
 		}
 
@@ -408,51 +444,14 @@ func parseNumber(p *peeker) (node, hcl.Diagnostics) {
 
 func parseString(p *peeker) (node, hcl.Diagnostics) {
 	tok := p.Read()
-	var str string
-	err := json.Unmarshal(tok.Bytes, &str)
-
-	if err != nil {
-		var errRange hcl.Range
-		if serr, ok := err.(*json.SyntaxError); ok {
-			errOfs := serr.Offset
-			errPos := tok.Range.Start
-			errPos.Byte += int(errOfs)
-
-			// TODO: Use the byte offset to properly count unicode
-			// characters for the column, and mark the whole of the
-			// character that was wrong as part of our range.
-			errPos.Column += int(errOfs)
-
-			errEndPos := errPos
-			errEndPos.Byte++
-			errEndPos.Column++
-
-			errRange = hcl.Range{
-				Filename: tok.Range.Filename,
-				Start:    errPos,
-				End:      errEndPos,
+	str, diags := UnquoteString(tok.Bytes, tok.Range.Filename, tok.Range.Start)
+	if diags.HasErrors() {
+		for _, diag := range diags {
+			if diag.Subject != nil && *diag.Subject != tok.Range {
+				diag.Context = tok.Range.Ptr()
 			}
-		} else {
-			errRange = tok.Range
-		}
-
-		var contextRange *hcl.Range
-		if errRange != tok.Range {
-			contextRange = &tok.Range
-		}
-
-		// FIXME: Eventually we should parse strings directly here so
-		// we can produce a more useful error message in the face fo things
-		// such as invalid escapes, etc.
-		return nil, hcl.Diagnostics{
-			{
-				Severity: hcl.DiagError,
-				Summary:  "Invalid JSON string",
-				Detail:   fmt.Sprintf("There is a syntax error in the given JSON string."),
-				Subject:  &errRange,
-				Context:  contextRange,
-			},
 		}
+		return nil, diags
 	}
 
 	return &stringVal{
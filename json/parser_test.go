@@ -5,6 +5,7 @@ package json
 
 import (
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -668,3 +669,42 @@ func mustBigFloat(s string) *big.Float {
 	}
 	return f
 }
+
+func TestParseNestingDepth(t *testing.T) {
+	tests := map[string]string{
+		"arrays":   strings.Repeat("[", maxNestingDepth+10) + strings.Repeat("]", maxNestingDepth+10),
+		"objects":  strings.Repeat(`{"a":`, maxNestingDepth+10) + "1" + strings.Repeat("}", maxNestingDepth+10),
+		"unclosed": strings.Repeat("[", 100*maxNestingDepth),
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := parseFileContent([]byte(input), "", hcl.InitialPos)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Summary, "Nesting too deep"; got != want {
+				t.Errorf("wrong summary %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseByteOrderMark(t *testing.T) {
+	src := []byte("\xef\xbb\xbf{\"a\": 1}")
+	f, diags := Parse(src, "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	attrs, _ := f.Body.JustAttributes()
+	attr := attrs["a"]
+	if attr == nil {
+		t.Fatal("attribute a not found")
+	}
+	if got := string(attr.NameRange.SliceBytes(src)); got != `"a"` {
+		t.Errorf("name range covers %q", got)
+	}
+	if got := attr.NameRange.Start; got != (hcl.Pos{Line: 1, Column: 2, Byte: 4}) {
+		t.Errorf("wrong name position %#v", got)
+	}
+}
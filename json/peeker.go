@@ -6,6 +6,12 @@ package json
 type peeker struct {
 	tokens []token
 	pos    int
+
+	// depth is the number of arrays and objects currently being parsed,
+	// and tooDeep is set once that exceeds maxNestingDepth so that the
+	// enclosing parse functions know to abandon their work.
+	depth   int
+	tooDeep bool
 }
 
 func newPeeker(tokens []token) *peeker {
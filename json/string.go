@@ -0,0 +1,218 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/apparentlymart/go-textseg/v15/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// UnquoteString decodes the given JSON string literal, including its
+// surrounding quotes, and returns the string it represents.
+//
+// The given position is the position of the opening quote within the file
+// with the given name, and is used to produce diagnostics that point at the
+// specific escape sequence or character that is invalid, rather than at the
+// string as a whole.
+//
+// A string that is decoded successfully has the same value that
+// encoding/json would produce for it, including the replacement of invalid
+// UTF-8 sequences and unpaired surrogate escapes with the Unicode replacement
+// character. If error diagnostics are returned then the result contains the
+// remainder of the string with each invalid escape sequence left unchanged,
+// which may be useful for careful analysis.
+func UnquoteString(src []byte, filename string, start hcl.Pos) (string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	p := pos{Filename: filename, Pos: start}
+
+	if len(src) == 0 || src[0] != '"' {
+		return "", hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid JSON string",
+				Detail:   "A JSON string must begin with a double quote character.",
+				Subject:  p.Range(0, 0).Ptr(),
+			},
+		}
+	}
+	openRange := p.Range(1, 1)
+	p.Pos.Byte++
+	p.Pos.Column++
+
+	ret := make([]byte, 0, len(src))
+	buf := src[1:]
+	for len(buf) > 0 {
+		b := buf[0]
+		switch {
+		case b == '"':
+			if len(buf) > 1 {
+				// The scanner never produces a token like this, but a
+				// caller might give us more than just one string.
+				extraRange := p.Range(1, 1)
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid JSON string",
+					Detail:   "Unexpected characters after the closing quote of this string.",
+					Subject:  &extraRange,
+				})
+			}
+			return string(ret), diags
+
+		case b == '\\':
+			var seqLen int
+			var escDiags hcl.Diagnostics
+			ret, seqLen, escDiags = unquoteEscape(ret, buf, p)
+			diags = append(diags, escDiags...)
+			p.Pos.Byte += seqLen
+			p.Pos.Column += seqLen
+			buf = buf[seqLen:]
+
+		case b < 0x20:
+			ctrlRange := p.Range(1, 1)
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid JSON string",
+				Detail:   fmt.Sprintf("Control characters such as U+%04X must be written as escape sequences in JSON strings.", b),
+				Subject:  &ctrlRange,
+			})
+			ret = append(ret, b)
+			p.Pos.Byte++
+			p.Pos.Column++
+			buf = buf[1:]
+
+		default:
+			// Advance by one grapheme cluster, so that we consider each
+			// grapheme to be a "column" as the scanner does.
+			advance, _, _ := textseg.ScanGraphemeClusters(buf, true)
+			ret = appendValidUTF8(ret, buf[:advance])
+			p.Pos.Byte += advance
+			p.Pos.Column++
+			buf = buf[advance:]
+		}
+	}
+
+	return string(ret), append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unterminated string",
+		Detail:   "This string has no closing quote. JSON strings cannot span multiple lines.",
+		Subject:  &openRange,
+	})
+}
+
+// unquoteEscape decodes the escape sequence at the start of buf, appending
+// the result to ret. It returns the new buffer along with the number of
+// bytes of buf that were consumed, which is always at least one.
+func unquoteEscape(ret []byte, buf []byte, p pos) ([]byte, int, hcl.Diagnostics) {
+	if len(buf) < 2 {
+		return append(ret, buf[0]), 1, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid escape sequence",
+				Detail:   "Backslash must be followed by an escape sequence selector character.",
+				Subject:  p.Range(1, 1).Ptr(),
+			},
+		}
+	}
+
+	switch buf[1] {
+	case '"', '\\', '/':
+		return append(ret, buf[1]), 2, nil
+	case 'b':
+		return append(ret, '\b'), 2, nil
+	case 'f':
+		return append(ret, '\f'), 2, nil
+	case 'n':
+		return append(ret, '\n'), 2, nil
+	case 'r':
+		return append(ret, '\r'), 2, nil
+	case 't':
+		return append(ret, '\t'), 2, nil
+	case 'u':
+		r, ok := unquoteHex4(buf[2:])
+		if !ok {
+			n := 2
+			for n < len(buf) && n < 6 && isHexDigit(buf[n]) {
+				n++
+			}
+			return append(ret, buf[:n]...), n, hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid escape sequence",
+					Detail:   "The \\u escape sequence must be followed by four hexadecimal digits.",
+					Subject:  p.Range(n, n).Ptr(),
+				},
+			}
+		}
+		if utf16.IsSurrogate(r) {
+			if len(buf) >= 12 && buf[6] == '\\' && buf[7] == 'u' {
+				if r2, ok := unquoteHex4(buf[8:]); ok {
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						return utf8.AppendRune(ret, dec), 12, nil
+					}
+				}
+			}
+			// An unpaired surrogate is not an error in JSON, but it does
+			// not represent a character and so we replace it.
+			r = utf8.RuneError
+		}
+		return utf8.AppendRune(ret, r), 6, nil
+	default:
+		// Highlight the whole character following the backslash, even if
+		// it is a multi-byte sequence.
+		_, size := utf8.DecodeRune(buf[1:])
+		return append(ret, buf[1:1+size]...), 1 + size, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid escape sequence",
+				Detail:   fmt.Sprintf("The symbol %q is not a valid escape sequence selector.", buf[1:1+size]),
+				Subject:  p.Range(1+size, 2).Ptr(),
+			},
+		}
+	}
+}
+
+// unquoteHex4 decodes the four hexadecimal digits at the start of buf.
+func unquoteHex4(buf []byte) (rune, bool) {
+	if len(buf) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, b := range buf[:4] {
+		switch {
+		case b >= '0' && b <= '9':
+			b -= '0'
+		case b >= 'a' && b <= 'f':
+			b = b - 'a' + 10
+		case b >= 'A' && b <= 'F':
+			b = b - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(b)
+	}
+	return r, true
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+// appendValidUTF8 appends the given bytes to ret, replacing each invalid
+// UTF-8 sequence with the Unicode replacement character.
+func appendValidUTF8(ret []byte, buf []byte) []byte {
+	for len(buf) > 0 {
+		r, size := utf8.DecodeRune(buf)
+		if r == utf8.RuneError && size == 1 {
+			ret = utf8.AppendRune(ret, utf8.RuneError)
+		} else {
+			ret = append(ret, buf[:size]...)
+		}
+		buf = buf[size:]
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestUnquoteString(t *testing.T) {
+	tests := []string{
+		`""`,
+		`"hello"`,
+		`"a\"b\\c\/d"`,
+		`"\b\f\n\r\t"`,
+		`"café é"`,
+		`"😀"`,
+		`"\ud83d"`,
+		`"\ude00\ud83d x"`,
+		"\"caf\xc3\xa9\"",
+		"\"bad \xff byte\"",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			var want string
+			if err := json.Unmarshal([]byte(src), &want); err != nil {
+				t.Fatalf("invalid test case: %s", err)
+			}
+
+			got, diags := UnquoteString([]byte(src), "test.json", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if got != want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestUnquoteStringErrors(t *testing.T) {
+	tests := []struct {
+		Src         string
+		WantSummary string
+		WantStart   int
+		WantEnd     int
+		WantColumn  int
+	}{
+		{`"a\qb"`, "Invalid escape sequence", 2, 4, 3},
+		{`"héllo \x"`, "Invalid escape sequence", 8, 10, 8},
+		{`"\u12"`, "Invalid escape sequence", 1, 5, 2},
+		{`"\u12zz"`, "Invalid escape sequence", 1, 5, 2},
+		{`"abc`, "Unterminated string", 0, 1, 1},
+		{"\"a\tb\"", "Invalid JSON string", 2, 3, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			_, diags := UnquoteString([]byte(test.Src), "test.json", hcl.InitialPos)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			diag := diags[0]
+			if diag.Summary != test.WantSummary {
+				t.Errorf("wrong summary %q; want %q", diag.Summary, test.WantSummary)
+			}
+			if got := diag.Subject.Start.Byte; got != test.WantStart {
+				t.Errorf("wrong start byte %d; want %d", got, test.WantStart)
+			}
+			if got := diag.Subject.End.Byte; got != test.WantEnd {
+				t.Errorf("wrong end byte %d; want %d", got, test.WantEnd)
+			}
+			if got := diag.Subject.Start.Column; got != test.WantColumn {
+				t.Errorf("wrong start column %d; want %d", got, test.WantColumn)
+			}
+		})
+	}
+}
+
+func TestParseInvalidEscape(t *testing.T) {
+	src := `{"a": "x\qy"}`
+	_, diags := Parse([]byte(src), "test.json")
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	diag := diags[0]
+	if got, want := diag.Subject.Start.Byte, 8; got != want {
+		t.Errorf("wrong subject start byte %d; want %d", got, want)
+	}
+	if diag.Context == nil || diag.Context.Start.Byte != 6 {
+		t.Errorf("wrong context %#v; want the whole string", diag.Context)
+	}
+}
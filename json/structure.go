@@ -5,6 +5,7 @@ package json
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -21,6 +22,11 @@ type body struct {
 	// be treated as non-existing. This is used when Body.PartialContent is
 	// called, to produce the "remaining content" Body.
 	hiddenAttrs map[string]struct{}
+
+	// If non-nil, the schema of the block whose content this body is. This
+	// is used to explain errors likely to be caused by nesting the content
+	// of the block at the wrong depth for its number of labels.
+	header *hcl.BlockHeaderSchema
 }
 
 // expression is the implementation of "Expression" used for files processed
@@ -59,17 +65,23 @@ func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 		}
 
 		if _, ok := hiddenAttrs[k]; !ok {
-			suggestion := nameSuggestion(k, nameSuggestions)
-			if suggestion != "" {
-				suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
+			suggested := nameSuggestion(k, nameSuggestions)
+			suggestion := ""
+			if suggested != "" {
+				suggestion = fmt.Sprintf(" Did you mean %q?", suggested)
 			}
 
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Extraneous JSON object property",
-				Detail:   fmt.Sprintf("No argument or block type is named %q.%s", k, suggestion),
+				Detail:   fmt.Sprintf("No argument or block type is named %q.%s%s", k, suggestion, b.extraLabelHint(attr)),
 				Subject:  &attr.NameRange,
 				Context:  attr.Range().Ptr(),
+				Extra: &hcl.UnknownAttributeError{
+					Name:       k,
+					Suggestion: suggested,
+					Range:      attr.NameRange,
+				},
 			})
 		}
 	}
@@ -121,6 +133,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 					Detail:   fmt.Sprintf("The argument %q was already set at %s.", attrName, existing.Range),
 					Subject:  &jsonAttr.NameRange,
 					Context:  jsonAttr.Range().Ptr(),
+					Extra:    hcl.NewDuplicateDiagExtra(existing.NameRange),
 				})
 				continue
 			}
@@ -135,7 +148,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 
 		} else if blockS, defined := blockSchemas[attrName]; defined {
 			bv := jsonAttr.Value
-			blockDiags := b.unpackBlock(bv, blockS.Type, &jsonAttr.NameRange, blockS.LabelNames, nil, nil, &content.Blocks)
+			blockDiags := b.unpackBlock(bv, &blockS, &jsonAttr.NameRange, blockS.LabelNames, nil, nil, &content.Blocks)
 			diags = append(diags, blockDiags...)
 			usedNames[attrName] = struct{}{}
 		}
@@ -162,6 +175,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 	unusedBody := &body{
 		val:         b.val,
 		hiddenAttrs: usedNames,
+		header:      b.header,
 	}
 
 	return content, unusedBody, diags
@@ -202,6 +216,7 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 				Summary:  "Duplicate attribute definition",
 				Detail:   fmt.Sprintf("The argument %q was already set at %s.", name, existing.Range),
 				Subject:  &jsonAttr.NameRange,
+				Extra:    hcl.NewDuplicateDiagExtra(existing.NameRange),
 			})
 			continue
 		}
@@ -232,7 +247,8 @@ func (b *body) MissingItemRange() hcl.Range {
 	}
 }
 
-func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labelsLeft []string, labelsUsed []string, labelRanges []hcl.Range, blocks *hcl.Blocks) (diags hcl.Diagnostics) {
+func (b *body) unpackBlock(v node, blockS *hcl.BlockHeaderSchema, typeRange *hcl.Range, labelsLeft []string, labelsUsed []string, labelRanges []hcl.Range, blocks *hcl.Blocks) (diags hcl.Diagnostics) {
+	typeName := blockS.Type
 	if len(labelsLeft) > 0 {
 		labelName := labelsLeft[0]
 		jsonAttrs, attrDiags := b.collectDeepAttrs(v, &labelName)
@@ -253,7 +269,7 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 			pk := p.Name
 			labelsUsed[len(labelsUsed)-1] = pk
 			labelRanges[len(labelRanges)-1] = p.NameRange
-			diags = append(diags, b.unpackBlock(p.Value, typeName, typeRange, labelsLeft[1:], labelsUsed, labelRanges, blocks)...)
+			diags = append(diags, b.unpackBlock(p.Value, blockS, typeRange, labelsLeft[1:], labelsUsed, labelRanges, blocks)...)
 		}
 		return
 	}
@@ -278,7 +294,8 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 			Type:   typeName,
 			Labels: labels,
 			Body: &body{
-				val: tv,
+				val:    tv,
+				header: blockS,
 			},
 
 			DefRange:    tv.OpenRange,
@@ -292,7 +309,8 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 				Type:   typeName,
 				Labels: labels,
 				Body: &body{
-					val: av, // might be mistyped; we'll find out when content is requested for this body
+					val:    av, // might be mistyped; we'll find out when content is requested for this body
+					header: blockS,
 				},
 
 				DefRange:    tv.OpenRange,
@@ -304,13 +322,50 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Incorrect JSON value type",
-			Detail:   fmt.Sprintf("Either a JSON object or a JSON array is required, representing the contents of one or more %q blocks.", typeName),
+			Detail:   fmt.Sprintf("Either a JSON object or a JSON array is required, representing the contents of one or more %q blocks.%s", typeName, missingLabelHint(blockS)),
 			Subject:  v.StartRange().Ptr(),
 		})
 	}
 	return
 }
 
+// missingLabelHint returns a sentence to append to the detail of an error
+// about a block whose content isn't an object, explaining the labels that
+// the block requires, since a value that isn't an object often means that
+// the content was given with too few levels of labels.
+func missingLabelHint(blockS *hcl.BlockHeaderSchema) string {
+	if len(blockS.LabelNames) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Each %s block must have %s, each given as the name of a nested object property.", blockS.Type, labelCount(blockS.LabelNames))
+}
+
+// extraLabelHint returns a sentence to append to the detail of an error
+// about an extraneous property in the content of a block, for a property
+// whose value is an object and so which may have been intended as an
+// additional block label.
+func (b *body) extraLabelHint(attr *objectAttr) string {
+	if b.header == nil {
+		return ""
+	}
+	if _, isObj := attr.Value.(*objectVal); !isObj {
+		return ""
+	}
+	return fmt.Sprintf(" If this property was intended as a block label, note that %s blocks have %s.", b.header.Type, labelCount(b.header.LabelNames))
+}
+
+// labelCount describes the given label names, as in "2 labels (type, name)".
+func labelCount(names []string) string {
+	switch len(names) {
+	case 0:
+		return "no labels"
+	case 1:
+		return fmt.Sprintf("1 label (%s)", names[0])
+	default:
+		return fmt.Sprintf("%d labels (%s)", len(names), strings.Join(names, ", "))
+	}
+}
+
 // collectDeepAttrs takes either a single object or an array of objects and
 // flattens it into a list of object attributes, collecting attributes from
 // all of the objects in a given array.
@@ -492,6 +547,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 					Subject:     &jsonAttr.NameRange,
 					Expression:  e,
 					EvalContext: ctx,
+					Extra:       hcl.NewDuplicateDiagExtra(attrRanges[nameStr]),
 				})
 				continue
 			}
@@ -1532,3 +1532,84 @@ func TestExpressionValue_Diags(t *testing.T) {
 	}
 
 }
+
+func TestDuplicateAttributeRanges(t *testing.T) {
+	file, diags := Parse([]byte(`{"a": 1, "a": 2, "o": {"b": 1, "b": 2}}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	check := func(diags hcl.Diagnostics, wantPrev, wantSubject int) {
+		t.Helper()
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		extra, ok := hcl.DiagnosticExtra[hcl.DuplicateDiagExtra](diags[0])
+		if !ok {
+			t.Fatalf("diagnostic has no DuplicateDiagExtra")
+		}
+		if got := extra.PreviousDefinitionRange().Start.Byte; got != wantPrev {
+			t.Errorf("wrong previous definition byte %d; want %d", got, wantPrev)
+		}
+		if got := diags[0].Subject.Start.Byte; got != wantSubject {
+			t.Errorf("wrong subject byte %d; want %d", got, wantSubject)
+		}
+	}
+
+	_, diags = file.Body.JustAttributes()
+	check(diags, 1, 9)
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "a"}, {Name: "o"}},
+	})
+	check(diags, 1, 9)
+
+	attrs, _ := file.Body.JustAttributes()
+	_, diags = attrs["o"].Expr.Value(nil)
+	check(diags, 23, 31)
+}
+
+func TestBlockLabelCountHints(t *testing.T) {
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"type", "name"}},
+		},
+	}
+	innerSchema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "a"}},
+	}
+
+	tests := map[string]struct {
+		src        string
+		wantDetail string
+	}{
+		"too few labels": {
+			`{"resource": {"a": {"a": "x"}}}`,
+			`Either a JSON object or a JSON array is required, representing the contents of one or more "resource" blocks. Each resource block must have 2 labels (type, name), each given as the name of a nested object property.`,
+		},
+		"too many labels": {
+			`{"resource": {"a": {"b": {"web": {"a": "x"}}}}}`,
+			`No argument or block type is named "web". If this property was intended as a block label, note that resource blocks have 2 labels (type, name).`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := Parse([]byte(test.src), "test.json")
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			content, diags := file.Body.Content(schema)
+			for _, block := range content.Blocks {
+				_, moreDiags := block.Body.Content(innerSchema)
+				diags = append(diags, moreDiags...)
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Detail; got != test.wantDetail {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.wantDetail)
+			}
+		})
+	}
+}
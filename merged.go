@@ -118,6 +118,7 @@ func (mb mergedBodies) JustAttributes() (Attributes, Diagnostics) {
 							name, existing.NameRange.String(),
 						),
 						Subject: &attr.NameRange,
+						Extra:   NewDuplicateDiagExtra(existing.NameRange),
 					})
 					continue
 				}
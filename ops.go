@@ -280,12 +280,17 @@ func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagno
 	switch {
 	case ty.IsObjectType():
 		if !ty.HasAttribute(attrName) {
+			err := &UnknownAttributeError{Name: attrName}
+			if srcRange != nil {
+				err.Range = *srcRange
+			}
 			return cty.DynamicVal, Diagnostics{
 				{
 					Severity: DiagError,
 					Summary:  unsupportedAttr,
 					Detail:   fmt.Sprintf("This object does not have an attribute named %q.", attrName),
 					Subject:  srcRange,
+					Extra:    err,
 				},
 			}
 		}
@@ -58,6 +58,27 @@ func NewRangeScannerFragment(b []byte, filename string, start Pos, cb bufio.Spli
 	}
 }
 
+// Reset prepares the scanner to scan the given buffer from its beginning,
+// producing ranges for the given filename, as if it had been newly created
+// by NewRangeScanner with the same SplitFunc. This allows a program that
+// scans many small buffers to reuse one scanner rather than allocating a
+// new one for each buffer. Programs lexing the native syntax can similarly
+// reuse their token buffers with hclsyntax.AppendLexConfig.
+func (sc *RangeScanner) Reset(b []byte, filename string) {
+	sc.ResetFragment(b, filename, InitialPos)
+}
+
+// ResetFragment is like Reset, but offsets the ranges that the scanner
+// produces by the given starting position, as for NewRangeScannerFragment.
+func (sc *RangeScanner) ResetFragment(b []byte, filename string, start Pos) {
+	*sc = RangeScanner{
+		filename: filename,
+		b:        b,
+		cb:       sc.cb,
+		pos:      start,
+	}
+}
+
 func (sc *RangeScanner) Scan() bool {
 	if sc.pos.Byte >= len(sc.b) || sc.err != nil {
 		// All done
@@ -144,6 +165,14 @@ func (sc *RangeScanner) Range() Range {
 
 // Bytes returns the slice of the input buffer that is covered by the range
 // that would be returned by Range.
+//
+// The result is the token returned by the SplitFunc. For split functions
+// that return sub-slices of their input, as those in package bufio do, it is
+// therefore a sub-slice of the buffer given when creating the scanner rather
+// than a copy, and so it remains valid after later calls to Scan.
+// Callers that need the token as a string can convert it themselves, but
+// those that only inspect each token can avoid an allocation per token by
+// working with the bytes directly.
 func (sc *RangeScanner) Bytes() []byte {
 	return sc.tok
 }
@@ -194,3 +194,52 @@ func TestPosScanner(t *testing.T) {
 		})
 	}
 }
+
+func TestRangeScannerBytesShareBuffer(t *testing.T) {
+	src := []byte("hello\nworld\n")
+	sc := NewRangeScanner(src, "", bufio.ScanLines)
+	var toks [][]byte
+	for sc.Scan() {
+		toks = append(toks, sc.Bytes())
+	}
+	if len(toks) != 2 {
+		t.Fatalf("wrong number of tokens %d; want 2", len(toks))
+	}
+
+	// The tokens are still valid after scanning is complete, and refer to
+	// the original buffer.
+	src[0] = 'j'
+	if got, want := string(toks[0]), "jello"; got != want {
+		t.Errorf("first token is %q; want %q", got, want)
+	}
+	if got, want := string(toks[1]), "world"; got != want {
+		t.Errorf("second token is %q; want %q", got, want)
+	}
+}
+
+func TestRangeScannerReset(t *testing.T) {
+	sc := NewRangeScanner([]byte("a\nb\n"), "first.txt", bufio.ScanLines)
+	for sc.Scan() {
+	}
+
+	sc.Reset([]byte("c\nd"), "second.txt")
+	var got []Range
+	for sc.Scan() {
+		got = append(got, sc.Range())
+	}
+	want := []Range{
+		{
+			Filename: "second.txt",
+			Start:    Pos{Byte: 0, Line: 1, Column: 1},
+			End:      Pos{Byte: 1, Line: 1, Column: 2},
+		},
+		{
+			Filename: "second.txt",
+			Start:    Pos{Byte: 2, Line: 2, Column: 1},
+			End:      Pos{Byte: 3, Line: 2, Column: 2},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong ranges after reset\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// Config is the decoded content of a body of Terraform-like configuration,
+// such as a single file or a set of files merged with hcl.MergeFiles.
+//
+// Each slice is in the order that its items appear in the body.
+type Config struct {
+	Resources     []*Resource
+	DataResources []*Resource
+	Variables     []*Variable
+	Outputs       []*Output
+	Providers     []*Provider
+	Modules       []*Module
+	Locals        []*Local
+
+	// Remain is the rest of the body, containing any other blocks and
+	// arguments, such as "terraform" blocks, for the application to decode.
+	Remain hcl.Body
+}
+
+// Local is a single named value declared in a "locals" block.
+type Local struct {
+	Name string
+	Expr hcl.Expression
+
+	DeclRange hcl.Range
+}
+
+var configSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// Decode decodes the resources, variables, outputs, providers, module calls
+// and local values in the given body.
+//
+// Blocks of other types are left in the Remain body of the result. If the
+// returned diagnostics contain errors then the result may be incomplete, but
+// it is never nil and contains all of the items that could be decoded.
+func Decode(body hcl.Body) (*Config, hcl.Diagnostics) {
+	content, remain, diags := body.PartialContent(configSchema)
+	config := &Config{
+		Remain: remain,
+	}
+
+	resources := map[string]hcl.Range{}
+	variables := map[string]hcl.Range{}
+	outputs := map[string]hcl.Range{}
+	providers := map[string]hcl.Range{}
+	modules := map[string]hcl.Range{}
+	locals := map[string]hcl.Range{}
+
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "resource", "data":
+			r, moreDiags := decodeResource(block)
+			diags = append(diags, moreDiags...)
+			diags = append(diags, checkUnique(resources, "resource", r.Addr(), r.DeclRange)...)
+			if r.Mode == DataResourceMode {
+				config.DataResources = append(config.DataResources, r)
+			} else {
+				config.Resources = append(config.Resources, r)
+			}
+
+		case "variable":
+			v, moreDiags := decodeVariable(block)
+			diags = append(diags, moreDiags...)
+			diags = append(diags, checkUnique(variables, "variable", v.Name, v.DeclRange)...)
+			config.Variables = append(config.Variables, v)
+
+		case "output":
+			o, moreDiags := decodeOutput(block)
+			diags = append(diags, moreDiags...)
+			diags = append(diags, checkUnique(outputs, "output value", o.Name, o.DeclRange)...)
+			config.Outputs = append(config.Outputs, o)
+
+		case "provider":
+			p, moreDiags := decodeProvider(block)
+			diags = append(diags, moreDiags...)
+			diags = append(diags, checkUnique(providers, "provider configuration", p.Addr(), p.DeclRange)...)
+			config.Providers = append(config.Providers, p)
+
+		case "module":
+			m, moreDiags := decodeModule(block)
+			diags = append(diags, moreDiags...)
+			diags = append(diags, checkUnique(modules, "module call", m.Name, m.DeclRange)...)
+			config.Modules = append(config.Modules, m)
+
+		case "locals":
+			attrs, moreDiags := block.Body.JustAttributes()
+			diags = append(diags, moreDiags...)
+			for _, attr := range sortedAttributes(attrs) {
+				diags = append(diags, checkUnique(locals, "local value", attr.Name, attr.Range)...)
+				config.Locals = append(config.Locals, &Local{
+					Name:      attr.Name,
+					Expr:      attr.Expr,
+					DeclRange: attr.Range,
+				})
+			}
+		}
+	}
+
+	return config, diags
+}
+
+// checkUnique records the given name in the given map of names already
+// declared, returning an error if it was already there.
+func checkUnique(seen map[string]hcl.Range, kind, name string, rng hcl.Range) hcl.Diagnostics {
+	if prev, exists := seen[name]; exists {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate %s", kind),
+				Detail:   fmt.Sprintf("A %s named %q was already declared at %s. Each %s must have a unique name.", kind, name, prev, kind),
+				Subject:  rng.Ptr(),
+			},
+		}
+	}
+	seen[name] = rng
+	return nil
+}
+
+// decodeString decodes the value of the given attribute as a constant
+// string, returning an empty string if the attribute is nil.
+func decodeString(attr *hcl.Attribute) (string, hcl.Diagnostics) {
+	var ret string
+	if attr == nil {
+		return ret, nil
+	}
+	diags := gohcl.DecodeExpression(attr.Expr, nil, &ret)
+	return ret, diags
+}
+
+// decodeBool decodes the value of the given attribute as a constant bool,
+// returning the given default if the attribute is nil.
+func decodeBool(attr *hcl.Attribute, def bool) (bool, hcl.Diagnostics) {
+	if attr == nil {
+		return def, nil
+	}
+	var ret bool
+	diags := gohcl.DecodeExpression(attr.Expr, nil, &ret)
+	return ret, diags
+}
+
+// decodeDependsOn decodes the value of the given "depends_on" attribute as
+// a list of references, returning nil if the attribute is nil.
+func decodeDependsOn(attr *hcl.Attribute) ([]hcl.Traversal, hcl.Diagnostics) {
+	if attr == nil {
+		return nil, nil
+	}
+	exprs, diags := hcl.ExprList(attr.Expr)
+	var ret []hcl.Traversal
+	for _, expr := range exprs {
+		traversal, moreDiags := hcl.AbsTraversalForExpr(expr)
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			ret = append(ret, traversal)
+		}
+	}
+	return ret, diags
+}
+
+// optionalExpr returns the expression of the given attribute, or nil if the
+// attribute is nil.
+func optionalExpr(attr *hcl.Attribute) hcl.Expression {
+	if attr == nil {
+		return nil
+	}
+	return attr.Expr
+}
+
+// sortedAttributes returns the given attributes in the order they appear in
+// the source.
+func sortedAttributes(attrs hcl.Attributes) []*hcl.Attribute {
+	ret := make([]*hcl.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		ret = append(ret, attr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		ri, rj := ret[i].Range, ret[j].Range
+		if ri.Filename != rj.Filename {
+			return ri.Filename < rj.Filename
+		}
+		return ri.Start.Byte < rj.Start.Byte
+	})
+	return ret
+}
@@ -0,0 +1,272 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const testConfig = `
+terraform {
+  required_version = ">= 1.0"
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+variable "instance_count" {
+  type        = number
+  default     = "2"
+  description = "How many instances to create."
+
+  validation {
+    condition     = var.instance_count > 0
+    error_message = "Must be positive."
+  }
+}
+
+variable "tags" {
+  type = object({
+    env   = string
+    owner = optional(string, "platform")
+  })
+  sensitive = true
+}
+
+resource "aws_instance" "web" {
+  count         = var.instance_count
+  provider      = aws.west
+  ami           = data.aws_ami.base.id
+  instance_type = "t3.micro"
+  depends_on    = [aws_security_group.web]
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+data "aws_ami" "base" {
+  most_recent = true
+}
+
+module "network" {
+  source  = "./network"
+  version = "1.2.0"
+  cidr    = "10.0.0.0/16"
+}
+
+locals {
+  name   = "web"
+  region = "us-east-1"
+}
+
+output "ids" {
+  value     = aws_instance.web[*].id
+  sensitive = true
+}
+`
+
+func TestDecode(t *testing.T) {
+	file, diags := hclsyntax.ParseConfig([]byte(testConfig), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	config, diags := Decode(file.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if got, want := len(config.Providers), 2; got != want {
+		t.Fatalf("wrong number of providers %d; want %d", got, want)
+	}
+	if got, want := config.Providers[1].Addr(), "aws.west"; got != want {
+		t.Errorf("wrong provider address %q; want %q", got, want)
+	}
+	attrs, _ := config.Providers[1].Config.JustAttributes()
+	if _, exists := attrs["alias"]; exists || len(attrs) != 1 {
+		t.Errorf("wrong provider config attributes %#v", attrs)
+	}
+
+	if got, want := len(config.Variables), 2; got != want {
+		t.Fatalf("wrong number of variables %d; want %d", got, want)
+	}
+	count := config.Variables[0]
+	if !count.Type.Equals(cty.Number) || !count.Default.RawEquals(cty.NumberIntVal(2)) {
+		t.Errorf("wrong type %#v or default %#v for instance_count", count.Type, count.Default)
+	}
+	if count.Description != "How many instances to create." || count.Required() || !count.Nullable {
+		t.Errorf("wrong instance_count variable %#v", count)
+	}
+	if len(count.Validations) != 1 || count.Validations[0].Condition == nil {
+		t.Errorf("wrong validations %#v", count.Validations)
+	}
+	tags := config.Variables[1]
+	if !tags.Required() || !tags.Sensitive || tags.TypeDefaults == nil {
+		t.Errorf("wrong tags variable %#v", tags)
+	}
+
+	if got, want := len(config.Resources), 1; got != want {
+		t.Fatalf("wrong number of resources %d; want %d", got, want)
+	}
+	web := config.Resources[0]
+	if got, want := web.Addr(), "aws_instance.web"; got != want {
+		t.Errorf("wrong resource address %q; want %q", got, want)
+	}
+	if web.Count == nil || web.ForEach != nil || web.Lifecycle == nil {
+		t.Errorf("wrong meta-arguments %#v", web)
+	}
+	if got := web.Provider.RootName(); got != "aws" || len(web.Provider) != 2 {
+		t.Errorf("wrong provider reference %#v", web.Provider)
+	}
+	if len(web.DependsOn) != 1 || web.DependsOn[0].RootName() != "aws_security_group" {
+		t.Errorf("wrong depends_on %#v", web.DependsOn)
+	}
+	_, diags = web.Config.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "ami"}, {Name: "instance_type"}},
+	})
+	if diags.HasErrors() {
+		t.Errorf("wrong resource config: %s", diags.Error())
+	}
+
+	if got, want := len(config.DataResources), 1; got != want {
+		t.Fatalf("wrong number of data resources %d; want %d", got, want)
+	}
+	if got, want := config.DataResources[0].Addr(), "data.aws_ami.base"; got != want {
+		t.Errorf("wrong data resource address %q; want %q", got, want)
+	}
+
+	if got, want := len(config.Modules), 1; got != want {
+		t.Fatalf("wrong number of modules %d; want %d", got, want)
+	}
+	if m := config.Modules[0]; m.Source != "./network" || m.Version != "1.2.0" {
+		t.Errorf("wrong module %#v", m)
+	}
+
+	if got, want := len(config.Locals), 2; got != want {
+		t.Fatalf("wrong number of locals %d; want %d", got, want)
+	}
+	if got, want := config.Locals[0].Name, "name"; got != want {
+		t.Errorf("wrong first local %q; want %q", got, want)
+	}
+
+	if got, want := len(config.Outputs), 1; got != want {
+		t.Fatalf("wrong number of outputs %d; want %d", got, want)
+	}
+	if o := config.Outputs[0]; o.Expr == nil || !o.Sensitive {
+		t.Errorf("wrong output %#v", o)
+	}
+
+	content, diags := config.Remain.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}},
+	})
+	if diags.HasErrors() || len(content.Blocks) != 1 {
+		t.Errorf("wrong remaining content: %s", diags.Error())
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"duplicate resource": {
+			`
+resource "a" "b" {}
+resource "a" "b" {}
+`,
+			`main.tf:3,1-17: Duplicate resource; A resource named "a.b" was already declared at main.tf:2,1-17. Each resource must have a unique name.`,
+		},
+		"resource and data with same name": {
+			`
+resource "a" "b" {}
+data "a" "b" {}
+`,
+			``,
+		},
+		"duplicate provider alias": {
+			`
+provider "a" { alias = "x" }
+provider "a" { alias = "x" }
+provider "a" {}
+`,
+			`main.tf:3,1-13: Duplicate provider configuration; A provider configuration named "a.x" was already declared at main.tf:2,1-13. Each provider configuration must have a unique name.`,
+		},
+		"duplicate local": {
+			`
+locals { a = 1 }
+locals { a = 2 }
+`,
+			`main.tf:3,10-15: Duplicate local value; A local value named "a" was already declared at main.tf:2,10-15. Each local value must have a unique name.`,
+		},
+		"count and for_each": {
+			`
+resource "a" "b" {
+  count    = 1
+  for_each = {}
+}
+`,
+			`main.tf:4,14-16: Invalid combination of "count" and "for_each"; The "count" and "for_each" meta-arguments are mutually-exclusive, only one should be used.`,
+		},
+		"bad default": {
+			`
+variable "a" {
+  type    = number
+  default = "x"
+}
+`,
+			`main.tf:4,13-16: Invalid default value for variable; This default value is not compatible with the variable's type constraint: a number is required.`,
+		},
+		"non-nullable null default": {
+			`
+variable "a" {
+  default  = null
+  nullable = false
+}
+`,
+			`main.tf:3,14-18: Invalid default value for variable; A null default value is not valid when nullable = false.`,
+		},
+		"bad alias": {
+			`
+provider "a" { alias = "1x" }
+`,
+			`main.tf:2,24-28: Invalid provider configuration alias; An alias must be a valid name. A name must start with a letter or underscore and may contain only letters, digits, underscores and dashes.`,
+		},
+		"module without source": {
+			`
+module "a" {}
+`,
+			`main.tf:2,12-12: Missing required argument; The argument "source" is required, but no definition was found.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "main.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			config, diags := Decode(file.Body)
+			if config == nil {
+				t.Fatal("result is nil")
+			}
+			got := ""
+			if len(diags) > 0 {
+				got = diags[0].Error()
+			}
+			if got != test.want {
+				t.Errorf("wrong diagnostic\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package tf decodes the most common top-level constructs of Terraform-like
+// configuration languages -- resources, data resources, input variables,
+// output values, provider configurations, module calls and local values --
+// into convenient structs, for applications and tools that work with such
+// configuration without needing all of the machinery of Terraform itself.
+//
+// The decoded structs retain the labels and source ranges of their blocks,
+// decode the arguments whose meaning is the same everywhere, such as the
+// type and default value of a variable, and keep the remaining content as an
+// hcl.Body or hcl.Expression for the application to decode further. Meta-
+// arguments such as "count" and "depends_on" are separated from the rest of
+// the content, so the body of a resource can be decoded against the schema
+// of its resource type.
+//
+// This package follows the conventions of the Terraform language, but it is
+// not a complete or authoritative implementation of it, and in particular it
+// does not validate resource arguments, resolve references or evaluate
+// anything other than the constant values of a few arguments.
+package tf
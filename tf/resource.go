@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ResourceMode distinguishes managed resources, declared with "resource"
+// blocks, from data resources, declared with "data" blocks.
+type ResourceMode int
+
+const (
+	ManagedResourceMode ResourceMode = iota
+	DataResourceMode
+)
+
+// Resource is a decoded "resource" or "data" block.
+type Resource struct {
+	Mode ResourceMode
+	Type string
+	Name string
+
+	// Count and ForEach are the expressions of the "count" and "for_each"
+	// meta-arguments, or nil if they are not set.
+	Count   hcl.Expression
+	ForEach hcl.Expression
+
+	// Provider is the reference given by the "provider" meta-argument, such
+	// as aws.west, or nil if it is not set.
+	Provider hcl.Traversal
+
+	// DependsOn are the references given by the "depends_on" meta-argument.
+	DependsOn []hcl.Traversal
+
+	// Lifecycle is the body of the "lifecycle" block, or nil if there is
+	// none.
+	Lifecycle hcl.Body
+
+	// Config is the body of the block without the meta-arguments and the
+	// "lifecycle" block, for decoding with the schema of the resource type.
+	Config hcl.Body
+
+	DeclRange hcl.Range
+	TypeRange hcl.Range
+}
+
+// Addr returns the address of the resource in the form that Terraform
+// uses to refer to it, such as "aws_instance.web" or "data.aws_ami.base".
+func (r *Resource) Addr() string {
+	if r.Mode == DataResourceMode {
+		return fmt.Sprintf("data.%s.%s", r.Type, r.Name)
+	}
+	return fmt.Sprintf("%s.%s", r.Type, r.Name)
+}
+
+var resourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "count"},
+		{Name: "for_each"},
+		{Name: "provider"},
+		{Name: "depends_on"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "lifecycle"},
+	},
+}
+
+func decodeResource(block *hcl.Block) (*Resource, hcl.Diagnostics) {
+	r := &Resource{
+		Type:      block.Labels[0],
+		Name:      block.Labels[1],
+		DeclRange: block.DefRange,
+		TypeRange: block.LabelRanges[0],
+	}
+	if block.Type == "data" {
+		r.Mode = DataResourceMode
+	}
+
+	content, remain, diags := block.Body.PartialContent(resourceSchema)
+	r.Config = remain
+	r.Count = optionalExpr(content.Attributes["count"])
+	r.ForEach = optionalExpr(content.Attributes["for_each"])
+
+	if r.Count != nil && r.ForEach != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  `Invalid combination of "count" and "for_each"`,
+			Detail:   `The "count" and "for_each" meta-arguments are mutually-exclusive, only one should be used.`,
+			Subject:  r.ForEach.Range().Ptr(),
+		})
+	}
+
+	if attr, exists := content.Attributes["provider"]; exists {
+		traversal, moreDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		diags = append(diags, moreDiags...)
+		r.Provider = traversal
+	}
+
+	dependsOn, moreDiags := decodeDependsOn(content.Attributes["depends_on"])
+	diags = append(diags, moreDiags...)
+	r.DependsOn = dependsOn
+
+	for _, block := range content.Blocks {
+		if r.Lifecycle != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  `Duplicate "lifecycle" block`,
+				Detail:   "A resource may have only one lifecycle block.",
+				Subject:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		r.Lifecycle = block.Body
+	}
+
+	return r, diags
+}
+
+// Provider is a decoded "provider" block.
+type Provider struct {
+	Name string
+
+	// Alias is the value of the "alias" argument, or an empty string if it
+	// is not set.
+	Alias string
+
+	// Config is the body of the block without the "alias" argument, for
+	// decoding with the schema of the provider.
+	Config hcl.Body
+
+	DeclRange hcl.Range
+}
+
+// Addr returns the name that resources use to refer to the provider
+// configuration, such as "aws" or "aws.west".
+func (p *Provider) Addr() string {
+	if p.Alias == "" {
+		return p.Name
+	}
+	return p.Name + "." + p.Alias
+}
+
+var providerSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "alias"},
+	},
+}
+
+func decodeProvider(block *hcl.Block) (*Provider, hcl.Diagnostics) {
+	p := &Provider{
+		Name:      block.Labels[0],
+		DeclRange: block.DefRange,
+	}
+
+	content, remain, diags := block.Body.PartialContent(providerSchema)
+	p.Config = remain
+
+	alias, moreDiags := decodeString(content.Attributes["alias"])
+	diags = append(diags, moreDiags...)
+	if !moreDiags.HasErrors() && alias != "" && !hclsyntax.ValidIdentifier(alias) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid provider configuration alias",
+			Detail:   "An alias must be a valid name. A name must start with a letter or underscore and may contain only letters, digits, underscores and dashes.",
+			Subject:  content.Attributes["alias"].Expr.Range().Ptr(),
+		})
+	}
+	p.Alias = alias
+
+	return p, diags
+}
+
+// Module is a decoded "module" block, which calls a child module.
+type Module struct {
+	Name string
+
+	// Source and Version are the values of the "source" and "version"
+	// arguments. Version is an empty string if it is not set.
+	Source  string
+	Version string
+
+	// Count and ForEach are the expressions of the "count" and "for_each"
+	// meta-arguments, or nil if they are not set.
+	Count   hcl.Expression
+	ForEach hcl.Expression
+
+	// DependsOn are the references given by the "depends_on" meta-argument.
+	DependsOn []hcl.Traversal
+
+	// Config is the body of the block without the arguments described
+	// above, which contains the values of the child module's variables.
+	Config hcl.Body
+
+	DeclRange   hcl.Range
+	SourceRange hcl.Range
+}
+
+var moduleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "source", Required: true},
+		{Name: "version"},
+		{Name: "count"},
+		{Name: "for_each"},
+		{Name: "depends_on"},
+	},
+}
+
+func decodeModule(block *hcl.Block) (*Module, hcl.Diagnostics) {
+	m := &Module{
+		Name:      block.Labels[0],
+		DeclRange: block.DefRange,
+	}
+
+	content, remain, diags := block.Body.PartialContent(moduleSchema)
+	m.Config = remain
+	m.Count = optionalExpr(content.Attributes["count"])
+	m.ForEach = optionalExpr(content.Attributes["for_each"])
+
+	if attr, exists := content.Attributes["source"]; exists {
+		m.SourceRange = attr.Expr.Range()
+	}
+	source, moreDiags := decodeString(content.Attributes["source"])
+	diags = append(diags, moreDiags...)
+	m.Source = source
+
+	version, moreDiags := decodeString(content.Attributes["version"])
+	diags = append(diags, moreDiags...)
+	m.Version = version
+
+	dependsOn, moreDiags := decodeDependsOn(content.Attributes["depends_on"])
+	diags = append(diags, moreDiags...)
+	m.DependsOn = dependsOn
+
+	return m, diags
+}
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Variable is a decoded "variable" block, which declares an input variable.
+type Variable struct {
+	Name        string
+	Description string
+
+	// Type is the type constraint given by the "type" argument, or
+	// cty.DynamicPseudoType if it is not set. TypeDefaults are the default
+	// values for any optional object attributes in the type constraint, or
+	// nil if there are none.
+	Type         cty.Type
+	TypeDefaults *typeexpr.Defaults
+
+	// Default is the value of the "default" argument, converted to Type
+	// with TypeDefaults applied, or cty.NilVal if there is no default and
+	// so the variable is required.
+	Default cty.Value
+
+	Sensitive bool
+	Nullable  bool
+
+	Validations []*VariableValidation
+
+	DeclRange hcl.Range
+}
+
+// Required returns true if the variable has no default value, and so must
+// be given a value by the caller of the module.
+func (v *Variable) Required() bool {
+	return v.Default == cty.NilVal
+}
+
+// VariableValidation is a decoded "validation" block within a "variable"
+// block.
+type VariableValidation struct {
+	Condition    hcl.Expression
+	ErrorMessage hcl.Expression
+
+	DeclRange hcl.Range
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "type"},
+		{Name: "default"},
+		{Name: "sensitive"},
+		{Name: "nullable"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "validation"},
+	},
+}
+
+var validationSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "condition", Required: true},
+		{Name: "error_message", Required: true},
+	},
+}
+
+func decodeVariable(block *hcl.Block) (*Variable, hcl.Diagnostics) {
+	v := &Variable{
+		Name:      block.Labels[0],
+		Type:      cty.DynamicPseudoType,
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(variableSchema)
+
+	description, moreDiags := decodeString(content.Attributes["description"])
+	diags = append(diags, moreDiags...)
+	v.Description = description
+
+	sensitive, moreDiags := decodeBool(content.Attributes["sensitive"], false)
+	diags = append(diags, moreDiags...)
+	v.Sensitive = sensitive
+
+	nullable, moreDiags := decodeBool(content.Attributes["nullable"], true)
+	diags = append(diags, moreDiags...)
+	v.Nullable = nullable
+
+	if attr, exists := content.Attributes["type"]; exists {
+		ty, defaults, moreDiags := typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			v.Type = ty
+			v.TypeDefaults = defaults
+		}
+	}
+
+	if attr, exists := content.Attributes["default"]; exists {
+		val, moreDiags := attr.Expr.Value(nil)
+		diags = append(diags, moreDiags...)
+		if !moreDiags.HasErrors() {
+			if v.TypeDefaults != nil {
+				val = v.TypeDefaults.Apply(val)
+			}
+			converted, err := convert.Convert(val, v.Type)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for variable",
+					Detail:   fmt.Sprintf("This default value is not compatible with the variable's type constraint: %s.", err),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			} else {
+				val = converted
+			}
+			if val.IsNull() && !v.Nullable {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for variable",
+					Detail:   "A null default value is not valid when nullable = false.",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+			v.Default = val
+		}
+	}
+
+	for _, block := range content.Blocks {
+		content, moreDiags := block.Body.Content(validationSchema)
+		diags = append(diags, moreDiags...)
+		v.Validations = append(v.Validations, &VariableValidation{
+			Condition:    optionalExpr(content.Attributes["condition"]),
+			ErrorMessage: optionalExpr(content.Attributes["error_message"]),
+			DeclRange:    block.DefRange,
+		})
+	}
+
+	return v, diags
+}
+
+// Output is a decoded "output" block, which declares an output value.
+type Output struct {
+	Name        string
+	Description string
+
+	// Expr is the expression of the "value" argument.
+	Expr hcl.Expression
+
+	Sensitive bool
+
+	// DependsOn are the references given by the "depends_on" meta-argument.
+	DependsOn []hcl.Traversal
+
+	DeclRange hcl.Range
+}
+
+var outputSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "value", Required: true},
+		{Name: "sensitive"},
+		{Name: "depends_on"},
+	},
+}
+
+func decodeOutput(block *hcl.Block) (*Output, hcl.Diagnostics) {
+	o := &Output{
+		Name:      block.Labels[0],
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(outputSchema)
+	o.Expr = optionalExpr(content.Attributes["value"])
+
+	description, moreDiags := decodeString(content.Attributes["description"])
+	diags = append(diags, moreDiags...)
+	o.Description = description
+
+	sensitive, moreDiags := decodeBool(content.Attributes["sensitive"], false)
+	diags = append(diags, moreDiags...)
+	o.Sensitive = sensitive
+
+	dependsOn, moreDiags := decodeDependsOn(content.Attributes["depends_on"])
+	diags = append(diags, moreDiags...)
+	o.DependsOn = dependsOn
+
+	return o, diags
+}